@@ -8,8 +8,11 @@ package ast
 import (
 	"bytes"
 	"fmt"
+	goast "go/ast"
+	"io"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Pos represents a position in a source file.
@@ -30,9 +33,23 @@ func (p Pos) String() string {
 
 // Grammar is the top-level node of the AST for the PEG grammar.
 type Grammar struct {
-	p     Pos
-	Init  *CodeBlock
-	Rules []*Rule
+	p        Pos
+	Init     *CodeBlock
+	Rules    []*Rule
+	Charsets []*Charset
+
+	// Package is the name of the package of the generated parser set by
+	// the @package directive, if any.
+	Package *Identifier
+	// GoImports are the import paths set by the @import_go directives, as
+	// quoted string literals.
+	GoImports []*StringLit
+
+	// cache of the rules by name and of their index, see RuleByName and
+	// RuleIndex
+	ruleByName map[string]*Rule
+	ruleIndex  map[string]int
+	ruleCount  int
 }
 
 // NewGrammar creates a new grammar at the specified position.
@@ -56,6 +73,29 @@ func (g *Grammar) String() string {
 	return buf.String()
 }
 
+// Charset is a named character set of the grammar, defined with the
+// @charset keyword. It can be referenced by name in place of an expression
+// and in other character classes, e.g. [<Name>-], see ExpandCharsets.
+type Charset struct {
+	p     Pos
+	Name  *Identifier
+	Class *CharClassMatcher
+}
+
+// NewCharset creates a named character set at the specified position and
+// with the specified name as identifier.
+func NewCharset(p Pos, name *Identifier) *Charset {
+	return &Charset{p: p, Name: name}
+}
+
+// Pos returns the starting position of the node.
+func (c *Charset) Pos() Pos { return c.p }
+
+// String returns the textual representation of a node.
+func (c *Charset) String() string {
+	return fmt.Sprintf("%s: %T{Name: %v, Class: %v}", c.p, c, c.Name, c.Class)
+}
+
 // Rule represents a rule in the PEG grammar. It has a name, an optional
 // display name to be used in error messages, and an expression.
 type Rule struct {
@@ -63,6 +103,15 @@ type Rule struct {
 	Name        *Identifier
 	DisplayName *StringLit
 	Expr        Expression
+
+	// Longest is true if the rule is annotated with @longest, in which case
+	// the choice expressions of the rule are in longest-match mode.
+	Longest bool
+
+	// ResultType is the Go type of the value of the rule, as declared by a
+	// "// type: T" comment at the end of the rule. It is empty if the type
+	// is not declared.
+	ResultType string
 }
 
 // NewRule creates a rule with at the specified position and with the
@@ -92,6 +141,11 @@ type ChoiceExpr struct {
 	p            Pos
 	Alternatives []Expression
 	Opt          optFlags
+
+	// Longest is true if the parser tries all the alternatives and keeps
+	// the one that consumes the most input, the first one in case of a tie,
+	// instead of the first one that matches.
+	Longest bool
 }
 
 // NewChoiceExpr creates a choice expression at the specified position.
@@ -155,6 +209,16 @@ type ActionExpr struct {
 	Expr   Expression
 	Code   *CodeBlock
 	FuncIx int
+
+	// ReturnType is the Go type of the value returned by the code block,
+	// either declared or inferred by InferReturnTypes. It is empty if the
+	// type is unknown.
+	ReturnType string
+
+	// cache of ParsedCode, for the code block parsedSrc
+	parsedSrc  string
+	parsedCode *goast.FuncLit
+	parsedErr  error
 }
 
 // NewActionExpr creates a new action expression at the specified position.
@@ -280,6 +344,30 @@ func (n *NotExpr) String() string {
 	return fmt.Sprintf("%s: %T{Expr: %v}", n.p, n, n.Expr)
 }
 
+// LookbehindExpr is a zero-length matcher that is considered a match if
+// the expression it contains matches the input that ends at the current
+// position, or if it does not match when Negate is true. The expression
+// must match a fixed number of characters, see LookbehindLength.
+type LookbehindExpr struct {
+	p      Pos
+	Expr   Expression
+	Negate bool
+}
+
+// NewLookbehindExpr creates a new lookbehind (<& or <!) expression at the
+// specified position.
+func NewLookbehindExpr(p Pos, negate bool) *LookbehindExpr {
+	return &LookbehindExpr{p: p, Negate: negate}
+}
+
+// Pos returns the starting position of the node.
+func (l *LookbehindExpr) Pos() Pos { return l.p }
+
+// String returns the textual representation of a node.
+func (l *LookbehindExpr) String() string {
+	return fmt.Sprintf("%s: %T{Expr: %v, Negate: %t}", l.p, l, l.Expr, l.Negate)
+}
+
 // ZeroOrOneExpr is an expression that can be matched zero or one time.
 type ZeroOrOneExpr struct {
 	p    Pos
@@ -461,6 +549,11 @@ type CharClassMatcher struct {
 	Chars          []rune
 	Ranges         []rune // pairs of low/high range
 	UnicodeClasses []string
+
+	// Charsets is the list of the names of the charsets referenced by the
+	// class, e.g. [<Name>], that are not yet merged in the class by
+	// ExpandCharsets.
+	Charsets []string
 }
 
 // NewCharClassMatcher creates a new character class matcher at the specified
@@ -546,7 +639,26 @@ outer:
 				rn, _, _ := r.ReadRune()
 				buf.WriteRune(rn)
 			}
-			rn, _, _, _ = strconv.UnquoteChar("\\"+buf.String(), 0)
+			switch rn {
+			case 'x', 'u', 'U':
+				// the hexadecimal escapes are decoded directly so that any
+				// 21-bit value is supported, including the surrogate halves
+				// that UnquoteChar rejects.
+				v, _ := strconv.ParseUint(buf.String()[1:], 16, 32)
+				rn = rune(v)
+			default:
+				rn, _, _, _ = strconv.UnquoteChar("\\"+buf.String(), 0)
+			}
+			chars = append(chars, rn)
+
+		case '<':
+			rest := raw[len(raw)-r.Len():]
+			if name := charsetRef(rest); name != "" {
+				c.Charsets = append(c.Charsets, name)
+				chars = append(chars, charsetMark)
+				r.Seek(int64(len(raw)-r.Len()+len(name)+1), io.SeekStart)
+				continue
+			}
 			chars = append(chars, rn)
 
 		default:
@@ -563,8 +675,13 @@ outer:
 			wasRange = true
 			continue
 		}
+		if r == charsetMark {
+			// a charset cannot be the bound of a range
+			wasRange = true
+			continue
+		}
 
-		if r == '-' && !wasRange && len(c.Chars) > 0 && i < len(chars)-1 {
+		if r == '-' && !wasRange && len(c.Chars) > 0 && i < len(chars)-1 && chars[i+1] != charsetMark {
 			inRange = true
 			wasRange = false
 			// start of range is the last Char added
@@ -577,6 +694,31 @@ outer:
 	}
 }
 
+// charsetMark marks the position of a charset reference in the
+// characters of a class.
+const charsetMark = -1
+
+// charsetRef returns the name of the charset referenced at the start of
+// s, which follows a '<', or an empty string if it doesn't start with a
+// valid identifier followed by a '>'.
+func charsetRef(s string) string {
+	i := strings.IndexByte(s, '>')
+	if i <= 0 || !isIdentifier(s[:i]) {
+		return ""
+	}
+	return s[:i]
+}
+
+// isIdentifier returns true if s is a valid identifier of the grammar.
+func isIdentifier(s string) bool {
+	for i, rn := range s {
+		if rn != '_' && !unicode.IsLetter(rn) && (i == 0 || !unicode.Is(unicode.Nd, rn)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
 // Pos returns the starting position of the node.
 func (c *CharClassMatcher) Pos() Pos { return c.p }
 
@@ -605,6 +747,97 @@ func (a *AnyMatcher) String() string {
 	return fmt.Sprintf("%s: %T{Val: %q}", a.p, a, a.Val)
 }
 
+// BOLMatcher is a matcher that matches the beginning of a line without
+// consuming any input, that is the start of the input or the position
+// that follows a newline character.
+type BOLMatcher struct {
+	posValue
+}
+
+// NewBOLMatcher creates a new beginning-of-line matcher at the specified
+// position. The value is provided for completeness' sake, but it is always
+// the caret.
+func NewBOLMatcher(p Pos, v string) *BOLMatcher {
+	return &BOLMatcher{posValue{p, v}}
+}
+
+// Pos returns the starting position of the node.
+func (b *BOLMatcher) Pos() Pos { return b.p }
+
+// String returns the textual representation of a node.
+func (b *BOLMatcher) String() string {
+	return fmt.Sprintf("%s: %T{Val: %q}", b.p, b, b.Val)
+}
+
+// RegexpMatcher is a matcher that matches the input at the current
+// position with a regular expression, in the syntax of the regexp
+// package. The regular expression is matched at once, with the
+// leftmost-first semantics of the regexp package: the matched text is
+// never backtracked by the parser, e.g. @regex "a*" followed by "a" never
+// matches.
+type RegexpMatcher struct {
+	p    Pos
+	Expr string
+}
+
+// NewRegexpMatcher creates a new regexp matcher at the specified position
+// and with the specified regular expression.
+func NewRegexpMatcher(p Pos, expr string) *RegexpMatcher {
+	return &RegexpMatcher{p: p, Expr: expr}
+}
+
+// Pos returns the starting position of the node.
+func (r *RegexpMatcher) Pos() Pos { return r.p }
+
+// String returns the textual representation of a node.
+func (r *RegexpMatcher) String() string {
+	return fmt.Sprintf("%s: %T{Expr: %q}", r.p, r, r.Expr)
+}
+
+// KeywordMatcher is a matcher that matches the identifier at the current
+// position if it is one of a set of keywords. The identifier is the
+// longest sequence of letters, decimal digits and underscores that does
+// not start with a digit, so that a keyword never matches the prefix of a
+// longer identifier. If Keywords is empty, the set of keywords is provided
+// when parsing, with the Keywords option of the generated parser.
+type KeywordMatcher struct {
+	p        Pos
+	Keywords []string
+}
+
+// NewKeywordMatcher creates a new keyword matcher at the specified
+// position and with the specified keywords.
+func NewKeywordMatcher(p Pos, keywords []string) *KeywordMatcher {
+	return &KeywordMatcher{p: p, Keywords: keywords}
+}
+
+// Pos returns the starting position of the node.
+func (k *KeywordMatcher) Pos() Pos { return k.p }
+
+// String returns the textual representation of a node.
+func (k *KeywordMatcher) String() string {
+	return fmt.Sprintf("%s: %T{Keywords: %q}", k.p, k, k.Keywords)
+}
+
+// IsKeyword returns true if s is an identifier that may be matched by a
+// keyword matcher, i.e. a non-empty sequence of letters, decimal digits
+// and underscores that does not start with a digit.
+func IsKeyword(s string) bool {
+	for i, rn := range s {
+		if !isKeywordRune(rn, i > 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isKeywordRune returns true if rn may be part of an identifier matched by
+// a keyword matcher, after the first rune of the identifier if inner is
+// true.
+func isKeywordRune(rn rune, inner bool) bool {
+	return rn == '_' || unicode.IsLetter(rn) || (inner && unicode.IsDigit(rn))
+}
+
 // CodeBlock represents a code block.
 type CodeBlock struct {
 	posValue
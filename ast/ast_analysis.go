@@ -0,0 +1,678 @@
+package ast
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// grammarAnalyzer holds the per-rule information shared by the static
+// analyses of a grammar.
+type grammarAnalyzer struct {
+	rules    map[string]*Rule
+	nullable map[string]bool
+}
+
+func newGrammarAnalyzer(g *Grammar) *grammarAnalyzer {
+	a := &grammarAnalyzer{
+		rules:    make(map[string]*Rule, len(g.Rules)),
+		nullable: make(map[string]bool, len(g.Rules)),
+	}
+	for _, r := range g.Rules {
+		a.rules[r.Name.Val] = r
+	}
+
+	// compute the nullable rules as a fixed point: a rule is nullable
+	// if its expression is nullable given the rules known to be nullable
+	// so far.
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if a.nullable[r.Name.Val] {
+				continue
+			}
+			if a.isNullable(r.Expr) {
+				a.nullable[r.Name.Val] = true
+				changed = true
+			}
+		}
+	}
+	return a
+}
+
+// isNullable returns true if expr may succeed without consuming any input.
+func (a *grammarAnalyzer) isNullable(expr Expression) bool {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return a.isNullable(expr.Expr)
+	case *AndCodeExpr, *AndExpr, *BOLMatcher, *LookbehindExpr, *NotCodeExpr, *NotExpr, *StateCodeExpr:
+		return true
+	case *AnyMatcher, *CharClassMatcher, *KeywordMatcher, *ThrowExpr:
+		return false
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if a.isNullable(alt) {
+				return true
+			}
+		}
+		return false
+	case *LabeledExpr:
+		return a.isNullable(expr.Expr)
+	case *LitMatcher:
+		return expr.Val == ""
+	case *OneOrMoreExpr:
+		return a.isNullable(expr.Expr)
+	case *RecoveryExpr:
+		return a.isNullable(expr.Expr) || a.isNullable(expr.RecoverExpr)
+	case *RegexpMatcher:
+		return regexpNullable(expr.Expr)
+	case *Rule:
+		return a.isNullable(expr.Expr)
+	case *RuleRefExpr:
+		return a.nullable[expr.Name.Val]
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			if !a.isNullable(e) {
+				return false
+			}
+		}
+		return true
+	case *ZeroOrMoreExpr, *ZeroOrOneExpr:
+		return true
+	}
+	return false
+}
+
+// first returns the FIRST set of expr, that is the set of terminals that
+// may be matched first by expr. The terminals are identified by their
+// textual representation (see terminalKey).
+func (a *grammarAnalyzer) first(expr Expression) map[string]struct{} {
+	set := make(map[string]struct{})
+	a.addFirst(set, expr, make(map[string]bool))
+	return set
+}
+
+func (a *grammarAnalyzer) addFirst(set map[string]struct{}, expr Expression, visiting map[string]bool) {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		a.addFirst(set, expr.Expr, visiting)
+	case *AnyMatcher, *CharClassMatcher, *KeywordMatcher, *RegexpMatcher:
+		set[terminalKey(expr)] = struct{}{}
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			a.addFirst(set, alt, visiting)
+		}
+	case *LabeledExpr:
+		a.addFirst(set, expr.Expr, visiting)
+	case *LitMatcher:
+		if expr.Val != "" {
+			set[terminalKey(expr)] = struct{}{}
+		}
+	case *OneOrMoreExpr:
+		a.addFirst(set, expr.Expr, visiting)
+	case *RecoveryExpr:
+		a.addFirst(set, expr.Expr, visiting)
+		a.addFirst(set, expr.RecoverExpr, visiting)
+	case *Rule:
+		a.addFirst(set, expr.Expr, visiting)
+	case *RuleRefExpr:
+		r, ok := a.rules[expr.Name.Val]
+		if !ok || visiting[expr.Name.Val] {
+			return
+		}
+		visiting[expr.Name.Val] = true
+		a.addFirst(set, r.Expr, visiting)
+		delete(visiting, expr.Name.Val)
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			a.addFirst(set, e, visiting)
+			if !a.isNullable(e) {
+				break
+			}
+		}
+	case *ZeroOrMoreExpr:
+		a.addFirst(set, expr.Expr, visiting)
+	case *ZeroOrOneExpr:
+		a.addFirst(set, expr.Expr, visiting)
+	}
+}
+
+// terminalKey returns the textual representation of a terminal, in the
+// same form as used in the "expected" list of the generated parser's errors.
+func terminalKey(expr Expression) string {
+	switch expr := expr.(type) {
+	case *AnyMatcher:
+		return "."
+	case *CharClassMatcher:
+		return expr.Val
+	case *KeywordMatcher:
+		return "keyword"
+	case *LitMatcher:
+		if expr.IgnoreCase {
+			return strconv.Quote(expr.Val) + "i"
+		}
+		return strconv.Quote(expr.Val)
+	case *RegexpMatcher:
+		return "/" + expr.Expr + "/"
+	}
+	return ""
+}
+
+// regexpNullable returns true if the regular expression expr matches the
+// empty input. An invalid regular expression is not nullable, it is
+// reported when the grammar is parsed.
+func regexpNullable(expr string) bool {
+	re, err := regexp.Compile(`\A(?:` + expr + `)`)
+	return err == nil && re.MatchString("")
+}
+
+// ChoiceConflict records two alternatives of a ChoiceExpr that may match
+// the same input. Because the first matching alternative wins in a PEG,
+// the dominated alternative may be unreachable for that input.
+type ChoiceConflict struct {
+	Choice     *ChoiceExpr
+	Dominating int
+	Dominated  int
+}
+
+// CheckForConflicts returns the conflicting alternatives of the choice
+// expressions of the grammar. Two alternatives are reported as a conflict
+// if their FIRST sets share a terminal and neither of them is nullable.
+// This is a heuristic: terminals are compared by their textual
+// representation, and alternatives that diverge after the common leading
+// terminal are reported too.
+func (g *Grammar) CheckForConflicts() []ChoiceConflict {
+	a := newGrammarAnalyzer(g)
+
+	var conflicts []ChoiceConflict
+	Inspect(g, func(expr Expression) bool {
+		ch, ok := expr.(*ChoiceExpr)
+		if !ok {
+			return true
+		}
+
+		firsts := make([]map[string]struct{}, len(ch.Alternatives))
+		for i, alt := range ch.Alternatives {
+			if !a.isNullable(alt) {
+				firsts[i] = a.first(alt)
+			}
+		}
+		for i := range ch.Alternatives {
+			for j := i + 1; j < len(ch.Alternatives); j++ {
+				if firsts[i] == nil || firsts[j] == nil {
+					continue
+				}
+				for k := range firsts[j] {
+					if _, ok := firsts[i][k]; ok {
+						conflicts = append(conflicts, ChoiceConflict{Choice: ch, Dominating: i, Dominated: j})
+						break
+					}
+				}
+			}
+		}
+		return true
+	})
+	return conflicts
+}
+
+// NonTerminatingRule is a rule that cannot terminate: all of its
+// alternatives reference, directly or indirectly, a rule that cannot
+// terminate, so parsing it recurses forever.
+type NonTerminatingRule struct {
+	Name string
+	// Cycle is the path of references from the rule to the first rule
+	// that repeats, following a non-terminating rule at each step, e.g.
+	// ["A", "B", "A"].
+	Cycle []string
+}
+
+// CheckTermination returns the rules of the grammar that cannot
+// terminate, in the order of the grammar. A rule terminates if one of its
+// alternatives can be parsed without referencing a rule that doesn't
+// terminate. Unlike left recursion, a recursive rule with a non-recursive
+// alternative, e.g. A = A "x" / "y", terminates. The references to
+// undefined rules are assumed to terminate.
+func (g *Grammar) CheckTermination() []NonTerminatingRule {
+	a := newGrammarAnalyzer(g)
+	terminating := make(map[string]bool, len(g.Rules))
+
+	// compute the terminating rules as a fixed point, like the nullable
+	// rules.
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if terminating[r.Name.Val] {
+				continue
+			}
+			if a.terminates(r.Expr, terminating) {
+				terminating[r.Name.Val] = true
+				changed = true
+			}
+		}
+	}
+
+	var list []NonTerminatingRule
+	for _, r := range g.Rules {
+		if terminating[r.Name.Val] {
+			continue
+		}
+		ntr := NonTerminatingRule{Name: r.Name.Val}
+		seen := make(map[string]bool)
+		nm := r.Name.Val
+		for !seen[nm] {
+			seen[nm] = true
+			ntr.Cycle = append(ntr.Cycle, nm)
+			nm = a.nonTerminatingRef(a.rules[nm].Expr, terminating)
+		}
+		ntr.Cycle = append(ntr.Cycle, nm)
+		list = append(list, ntr)
+	}
+	return list
+}
+
+// terminates returns true if expr can be parsed without referencing a
+// rule that is not in terminating.
+func (a *grammarAnalyzer) terminates(expr Expression, terminating map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return a.terminates(expr.Expr, terminating)
+	case *AndExpr:
+		return a.terminates(expr.Expr, terminating)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if a.terminates(alt, terminating) {
+				return true
+			}
+		}
+		return false
+	case *LabeledExpr:
+		return a.terminates(expr.Expr, terminating)
+	case *NotExpr:
+		return a.terminates(expr.Expr, terminating)
+	case *OneOrMoreExpr:
+		return a.terminates(expr.Expr, terminating)
+	case *RecoveryExpr:
+		return a.terminates(expr.Expr, terminating) || a.terminates(expr.RecoverExpr, terminating)
+	case *RuleRefExpr:
+		if _, ok := a.rules[expr.Name.Val]; !ok {
+			return true
+		}
+		return terminating[expr.Name.Val]
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			if !a.terminates(e, terminating) {
+				return false
+			}
+		}
+		return true
+	case *ZeroOrMoreExpr, *ZeroOrOneExpr:
+		return true
+	}
+	// matchers, code blocks and throw expressions
+	return true
+}
+
+// nonTerminatingRef returns the name of the first rule referenced by expr
+// that is defined and not in terminating, or an empty string.
+func (a *grammarAnalyzer) nonTerminatingRef(expr Expression, terminating map[string]bool) string {
+	var name string
+	Inspect(expr, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok && name == "" {
+			if _, ok := a.rules[ref.Name.Val]; ok && !terminating[ref.Name.Val] {
+				name = ref.Name.Val
+			}
+		}
+		return name == ""
+	})
+	return name
+}
+
+// ReorderByPriority returns a new ChoiceExpr with the alternatives of c
+// sorted by decreasing score, e.g. so that the most likely alternative is
+// tried first. The alternatives that are not in scores have a score of 0,
+// and the alternatives with the same score keep their original order.
+// The alternatives themselves are shared with c, which is not modified.
+//
+// Note that reordering the alternatives changes the language matched by
+// the choice if some of them match the same input, see CheckForConflicts.
+func (c *ChoiceExpr) ReorderByPriority(scores map[Expression]float64) *ChoiceExpr {
+	ch := *c
+	ch.Alternatives = append([]Expression(nil), c.Alternatives...)
+	sort.SliceStable(ch.Alternatives, func(i, j int) bool {
+		return scores[ch.Alternatives[i]] > scores[ch.Alternatives[j]]
+	})
+	return &ch
+}
+
+// CountByType returns the number of expressions of each type in the tree
+// rooted at expr, keyed by the name of the type without the package
+// qualifier (e.g. "LitMatcher", "SeqExpr").
+func CountByType(expr Expression) map[string]int {
+	counts := make(map[string]int)
+	Inspect(expr, func(expr Expression) bool {
+		if expr != nil {
+			counts[typeName(expr)]++
+		}
+		return true
+	})
+	return counts
+}
+
+// AllLiterals returns the sorted list of the distinct values of the
+// literal matchers of the grammar, e.g. to build a list of keywords.
+func (g *Grammar) AllLiterals() []string {
+	counts := g.AllLiteralsWithCounts()
+	lits := make([]string, 0, len(counts))
+	for lit := range counts {
+		lits = append(lits, lit)
+	}
+	sort.Strings(lits)
+	return lits
+}
+
+// AllLiteralsWithCounts returns the number of literal matchers of the
+// grammar for each distinct value, as returned by AllLiterals.
+func (g *Grammar) AllLiteralsWithCounts() map[string]int {
+	counts := make(map[string]int)
+	Inspect(g, func(expr Expression) bool {
+		if lit, ok := expr.(*LitMatcher); ok {
+			counts[lit.Val]++
+		}
+		return true
+	})
+	return counts
+}
+
+// AllCharClasses returns the distinct character class matchers of the
+// grammar, in the order of their first occurrence. The matchers that are
+// Equal are returned once, as the first of them.
+func (g *Grammar) AllCharClasses() []*CharClassMatcher {
+	var classes []*CharClassMatcher
+	seen := make(map[*CharClassMatcher]bool)
+	Inspect(g, func(expr Expression) bool {
+		cl, ok := expr.(*CharClassMatcher)
+		if !ok || seen[cl] {
+			return true
+		}
+		seen[cl] = true
+		for _, c := range classes {
+			if Equal(c, cl) {
+				return true
+			}
+		}
+		classes = append(classes, cl)
+		return true
+	})
+	return classes
+}
+
+// unbounded is the lookahead of expressions that may examine an unbounded
+// number of characters.
+const unbounded = int(^uint(0) >> 1)
+
+// MaxLookahead estimates the maximum number of characters that the parser
+// may examine when matching rule, starting from the position where the
+// rule is tried. Characters examined by the AndExpr and NotExpr predicates
+// count towards the lookahead even if they are not consumed, and the
+// lookahead of a ChoiceExpr is the maximum lookahead of its alternatives.
+//
+// It returns the maximum int value if the lookahead is unbounded, i.e. if
+// rule may repeat an expression that consumes input or if it is
+// (directly or indirectly) recursive.
+func MaxLookahead(rule *Rule, g *Grammar) int {
+	a := newGrammarAnalyzer(g)
+	_, look := a.lookahead(rule.Expr, map[string]bool{rule.Name.Val: true})
+	return look
+}
+
+// lookahead returns the maximum number of characters consumed by expr and
+// the maximum number of characters examined by expr.
+func (a *grammarAnalyzer) lookahead(expr Expression, visiting map[string]bool) (consumed, look int) {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return a.lookahead(expr.Expr, visiting)
+	case *AndExpr:
+		_, look := a.lookahead(expr.Expr, visiting)
+		return 0, look
+	case *AnyMatcher, *CharClassMatcher:
+		return 1, 1
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			c, l := a.lookahead(alt, visiting)
+			consumed, look = maxInt(consumed, c), maxInt(look, l)
+		}
+		return consumed, look
+	case *KeywordMatcher:
+		// the whole identifier is read, even if it is not a keyword
+		if len(expr.Keywords) == 0 {
+			return unbounded, unbounded
+		}
+		var n int
+		for _, kw := range expr.Keywords {
+			n = maxInt(n, utf8.RuneCountInString(kw))
+		}
+		return n, unbounded
+	case *LabeledExpr:
+		return a.lookahead(expr.Expr, visiting)
+	case *LitMatcher:
+		n := utf8.RuneCountInString(expr.Val)
+		return n, n
+	case *NotExpr:
+		_, look := a.lookahead(expr.Expr, visiting)
+		return 0, look
+	case *OneOrMoreExpr:
+		return a.repeatLookahead(expr.Expr, visiting)
+	case *RecoveryExpr:
+		c1, l1 := a.lookahead(expr.Expr, visiting)
+		c2, l2 := a.lookahead(expr.RecoverExpr, visiting)
+		return maxInt(c1, c2), maxInt(l1, l2)
+	case *RegexpMatcher:
+		return unbounded, unbounded
+	case *Rule:
+		return a.lookahead(expr.Expr, visiting)
+	case *RuleRefExpr:
+		r, ok := a.rules[expr.Name.Val]
+		if !ok {
+			return 0, 0
+		}
+		if visiting[expr.Name.Val] {
+			return unbounded, unbounded
+		}
+		visiting[expr.Name.Val] = true
+		consumed, look = a.lookahead(r.Expr, visiting)
+		delete(visiting, expr.Name.Val)
+		return consumed, look
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			c, l := a.lookahead(e, visiting)
+			look = maxInt(look, addInt(consumed, l))
+			consumed = addInt(consumed, c)
+		}
+		return consumed, look
+	case *ZeroOrMoreExpr:
+		return a.repeatLookahead(expr.Expr, visiting)
+	case *ZeroOrOneExpr:
+		return a.lookahead(expr.Expr, visiting)
+	}
+	return 0, 0
+}
+
+// MaxMatchLength returns the maximum number of bytes of input that the
+// rule may consume, and true, or 0 and false if it is unbounded, i.e. if
+// the rule contains a repetition (ZeroOrMoreExpr or OneOrMoreExpr) or a
+// regexp matcher, or if it is (directly or indirectly) recursive.
+//
+// The rule references are resolved in the grammar g, and a reference to a
+// rule that is not defined in g, or any reference if g is nil, is
+// considered unbounded.
+func (r *Rule) MaxMatchLength(g *Grammar) (int, bool) {
+	a := &grammarAnalyzer{}
+	if g != nil {
+		a = newGrammarAnalyzer(g)
+	}
+	n := a.maxLength(r.Expr, map[string]bool{r.Name.Val: true})
+	if n == unbounded {
+		return 0, false
+	}
+	return n, true
+}
+
+// maxLength returns the maximum number of bytes consumed by expr, or
+// unbounded.
+func (a *grammarAnalyzer) maxLength(expr Expression, visiting map[string]bool) int {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return a.maxLength(expr.Expr, visiting)
+	case *AnyMatcher:
+		return utf8.UTFMax
+	case *CharClassMatcher:
+		return charClassMaxLength(expr)
+	case *ChoiceExpr:
+		var n int
+		for _, alt := range expr.Alternatives {
+			n = maxInt(n, a.maxLength(alt, visiting))
+		}
+		return n
+	case *KeywordMatcher:
+		if len(expr.Keywords) == 0 {
+			return unbounded
+		}
+		var n int
+		for _, kw := range expr.Keywords {
+			n = maxInt(n, len(kw))
+		}
+		return n
+	case *LabeledExpr:
+		return a.maxLength(expr.Expr, visiting)
+	case *LitMatcher:
+		if !expr.IgnoreCase {
+			return len(expr.Val)
+		}
+		var n int
+		for _, rn := range expr.Val {
+			n += foldMaxLength(rn)
+		}
+		return n
+	case *OneOrMoreExpr, *RegexpMatcher, *ZeroOrMoreExpr:
+		return unbounded
+	case *RecoveryExpr:
+		return maxInt(a.maxLength(expr.Expr, visiting), a.maxLength(expr.RecoverExpr, visiting))
+	case *Rule:
+		return a.maxLength(expr.Expr, visiting)
+	case *RuleRefExpr:
+		r, ok := a.rules[expr.Name.Val]
+		if !ok || visiting[expr.Name.Val] {
+			return unbounded
+		}
+		visiting[expr.Name.Val] = true
+		n := a.maxLength(r.Expr, visiting)
+		delete(visiting, expr.Name.Val)
+		return n
+	case *SeqExpr:
+		var n int
+		for _, e := range expr.Exprs {
+			n = addInt(n, a.maxLength(e, visiting))
+		}
+		return n
+	case *ZeroOrOneExpr:
+		return a.maxLength(expr.Expr, visiting)
+	}
+	// the predicates and the code blocks don't consume any input
+	return 0
+}
+
+// LookbehindLength returns the number of characters matched by the
+// expression of the lookbehind l, and true, or 0 and false if it may match
+// a variable number of characters. The expression must be made of
+// literals, character classes and any matchers, combined in sequences and
+// in choices whose alternatives all match the same number of characters.
+func LookbehindLength(l *LookbehindExpr) (int, bool) {
+	return fixedLength(l.Expr)
+}
+
+// fixedLength returns the number of characters matched by expr, and true,
+// or 0 and false if it is not fixed.
+func fixedLength(expr Expression) (int, bool) {
+	switch expr := expr.(type) {
+	case *AnyMatcher, *CharClassMatcher:
+		return 1, true
+	case *ChoiceExpr:
+		n := -1
+		for _, alt := range expr.Alternatives {
+			m, ok := fixedLength(alt)
+			if !ok || n >= 0 && m != n {
+				return 0, false
+			}
+			n = m
+		}
+		return n, n >= 0
+	case *LitMatcher:
+		// case folding maps a character to a single character
+		return utf8.RuneCountInString(expr.Val), true
+	case *SeqExpr:
+		var n int
+		for _, e := range expr.Exprs {
+			m, ok := fixedLength(e)
+			if !ok {
+				return 0, false
+			}
+			n += m
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// charClassMaxLength returns the maximum number of bytes of the
+// characters matched by the class c.
+func charClassMaxLength(c *CharClassMatcher) int {
+	if c.Inverted || c.IgnoreCase || len(c.UnicodeClasses) > 0 || len(c.Charsets) > 0 {
+		return utf8.UTFMax
+	}
+	var n int
+	for _, rn := range c.Chars {
+		n = maxInt(n, utf8.RuneLen(rn))
+	}
+	// the high rune of a range is the longest
+	for i := 1; i < len(c.Ranges); i += 2 {
+		n = maxInt(n, utf8.RuneLen(c.Ranges[i]))
+	}
+	return n
+}
+
+// foldMaxLength returns the maximum number of bytes of the runes that are
+// equal to rn under simple case folding, e.g. 3 for 'k', which matches the
+// Kelvin sign.
+func foldMaxLength(rn rune) int {
+	n := utf8.RuneLen(rn)
+	for f := unicode.SimpleFold(rn); f != rn; f = unicode.SimpleFold(f) {
+		n = maxInt(n, utf8.RuneLen(f))
+	}
+	return n
+}
+
+// repeatLookahead returns the lookahead of the repetition of expr.
+func (a *grammarAnalyzer) repeatLookahead(expr Expression, visiting map[string]bool) (consumed, look int) {
+	consumed, look = a.lookahead(expr, visiting)
+	if consumed > 0 {
+		return unbounded, unbounded
+	}
+	return consumed, look
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// addInt returns a+b for non-negative a and b, saturating at unbounded.
+func addInt(a, b int) int {
+	if a > unbounded-b {
+		return unbounded
+	}
+	return a + b
+}
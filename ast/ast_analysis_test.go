@@ -0,0 +1,288 @@
+package ast_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/bootstrap"
+)
+
+func parseGrammar(t *testing.T, src string) *ast.Grammar {
+	t.Helper()
+	g, err := bootstrap.NewParser().Parse("", strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestCheckForConflicts(t *testing.T) {
+	cases := []struct {
+		src  string
+		want [][2]int
+	}{
+		{src: `A = "a" / "b"`},
+		{src: `A = "a" "b" / "a" "c" / "d"`, want: [][2]int{{0, 1}}},
+		{src: `A = "x"? "y" / "x"`, want: [][2]int{{0, 1}}},
+		{src: `A = "" / "a" / "a"`, want: [][2]int{{1, 2}}},
+		{src: `A = &"a" B / C
+B = [a-z]
+C = [a-z] / "b"`, want: [][2]int{{0, 1}}},
+		{src: `A = B / "b"
+B = B "x" / "b"`, want: [][2]int{{0, 1}, {0, 1}}},
+	}
+
+	for _, tc := range cases {
+		g := parseGrammar(t, tc.src)
+		got := g.CheckForConflicts()
+		if len(got) != len(tc.want) {
+			t.Errorf("%q: want %d conflicts, got %d", tc.src, len(tc.want), len(got))
+			continue
+		}
+		for i, c := range got {
+			if c.Dominating != tc.want[i][0] || c.Dominated != tc.want[i][1] {
+				t.Errorf("%q: want conflict %v, got [%d %d]", tc.src, tc.want[i], c.Dominating, c.Dominated)
+			}
+		}
+	}
+}
+
+func TestCheckTermination(t *testing.T) {
+	g := parseGrammar(t, `
+Start = A / B / C / D / E
+A = A "x" / "y"
+B = "b" C
+C = D "c"
+D = "d" C / &B
+E = ( "e" E )* F
+F = Undefined
+G = !G
+`)
+	got := g.CheckTermination()
+	want := []ast.NonTerminatingRule{
+		{Name: "B", Cycle: []string{"B", "C", "D", "C"}},
+		{Name: "C", Cycle: []string{"C", "D", "C"}},
+		{Name: "D", Cycle: []string{"D", "C", "D"}},
+		{Name: "G", Cycle: []string{"G", "G"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	g = parseGrammar(t, "A = A \"x\" / \"y\"\n")
+	if got := g.CheckTermination(); len(got) != 0 {
+		t.Errorf("want no non-terminating rule, got %v", got)
+	}
+}
+
+func TestReorderByPriority(t *testing.T) {
+	a, b, c, d := ast.Lit("a"), ast.Lit("b"), ast.Lit("c"), ast.Lit("d")
+	ch := ast.Choice(a, b, c, d)
+	ch.Longest = true
+
+	got := ch.ReorderByPriority(map[ast.Expression]float64{c: 2, b: 0.5, d: 0.5, ast.Lit("x"): 10})
+	want := []ast.Expression{c, b, d, a}
+	for i, alt := range got.Alternatives {
+		if alt != want[i] {
+			t.Errorf("%d: want %v, got %v", i, want[i], alt)
+		}
+	}
+	if !got.Longest || got.Pos() != ch.Pos() {
+		t.Errorf("want the fields of the choice preserved, got %v", got)
+	}
+
+	// the original is not modified
+	if got == ch {
+		t.Fatal("want a new choice expression")
+	}
+	for i, alt := range []ast.Expression{a, b, c, d} {
+		if ch.Alternatives[i] != alt {
+			t.Errorf("%d: want original alternative %v, got %v", i, alt, ch.Alternatives[i])
+		}
+	}
+
+	// stable without scores
+	got = ch.ReorderByPriority(nil)
+	for i, alt := range ch.Alternatives {
+		if got.Alternatives[i] != alt {
+			t.Errorf("%d: want %v, got %v", i, alt, got.Alternatives[i])
+		}
+	}
+}
+
+func TestCountByType(t *testing.T) {
+	f, err := os.Open("../grammar/bootstrap.peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := bootstrap.NewParser().Parse("bootstrap.peg", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := ast.CountByType(g)
+	if counts["Grammar"] != 1 {
+		t.Errorf("want 1 Grammar, got %d", counts["Grammar"])
+	}
+	if counts["Rule"] != len(g.Rules) {
+		t.Errorf("want %d Rule, got %d", len(g.Rules), counts["Rule"])
+	}
+
+	var total int
+	for _, n := range counts {
+		total += n
+	}
+	if want := ast.NewExpressionStats(g).Expressions; total != want {
+		t.Errorf("want %d expressions in total, got %d", want, total)
+	}
+
+	counts = ast.CountByType(ast.NewLitMatcher(ast.Pos{}, "a"))
+	if len(counts) != 1 || counts["LitMatcher"] != 1 {
+		t.Errorf("want 1 LitMatcher, got %v", counts)
+	}
+}
+
+func TestAllLiterals(t *testing.T) {
+	g := parseGrammar(t, `
+Stmt = "if" _ Expr _ "then" _ Stmt / "print" _ Expr
+Expr = "true" / "false" / "(" Expr ")" / "if"
+_ = " "*`)
+	want := []string{" ", "(", ")", "false", "if", "print", "then", "true"}
+	if got := g.AllLiterals(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	counts := map[string]int{" ": 1, "(": 1, ")": 1, "false": 1, "if": 2, "print": 1, "then": 1, "true": 1}
+	if got := g.AllLiteralsWithCounts(); !reflect.DeepEqual(got, counts) {
+		t.Errorf("want %v, got %v", counts, got)
+	}
+
+	g = parseGrammar(t, `A = [a-z] .`)
+	if got := g.AllLiterals(); len(got) != 0 {
+		t.Errorf("want no literals, got %q", got)
+	}
+}
+
+func TestAllCharClasses(t *testing.T) {
+	g := parseGrammar(t, `
+Ident = [a-z] [a-z0-9]* / "_" [a-z]
+Num = [0-9]+ [a-z]?`)
+	// shared instance
+	first := g.Rules[0].Expr.(*ast.ChoiceExpr).Alternatives[0].(*ast.SeqExpr).Exprs[0]
+	num := g.Rules[1].Expr.(*ast.SeqExpr)
+	num.Exprs = append(num.Exprs, first)
+
+	var got []string
+	for _, c := range g.AllCharClasses() {
+		got = append(got, c.Val)
+	}
+	want := []string{"[a-z]", "[a-z0-9]", "[0-9]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	g = parseGrammar(t, `A = "a" .`)
+	if got := g.AllCharClasses(); len(got) != 0 {
+		t.Errorf("want no char classes, got %v", got)
+	}
+}
+
+func TestMaxLookahead(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+
+	cases := []struct {
+		src  string
+		want int
+	}{
+		{src: `A = !("foo") "bar"`, want: 3},
+		{src: `A = "a" &("bcd") "b"`, want: 4},
+		{src: `A = "ab" / "cde" / .`, want: 3},
+		{src: `A = "a"? [b-c] B
+B = "é" { return nil, nil }`, want: 3},
+		{src: `A = "a" ("b" / "c")*`, want: maxInt},
+		{src: `A = (!"a")* "b"`, want: 1},
+		{src: `A = "a" B
+B = "b" A / "c"`, want: maxInt},
+		{src: `A = "a" B`, want: 1},
+	}
+
+	for _, tc := range cases {
+		g := parseGrammar(t, tc.src)
+		if got := ast.MaxLookahead(g.Rules[0], g); got != tc.want {
+			t.Errorf("%q: want %d, got %d", tc.src, tc.want, got)
+		}
+	}
+}
+
+func TestMaxMatchLength(t *testing.T) {
+	cases := []struct {
+		src  string
+		want int
+		ok   bool
+	}{
+		{src: `A = !("foo") "bar"`, want: 3, ok: true},
+		{src: `A = "ab" / "cde" / .`, want: 4, ok: true},
+		{src: `A = "a"? [b-c] B
+B = "é" { return nil, nil }`, want: 4, ok: true},
+		{src: `A = [a-zé] [\pL]`, want: 6, ok: true},
+		{src: `A = "ak"i`, want: 4, ok: true},
+		{src: `A = "a" ("b" / "c")*`},
+		{src: `A = "a" [0-9]+`},
+		{src: `A = "a" B
+B = "b" A / "c"`},
+		{src: `A = B
+B = C? "b"
+C = B`},
+		{src: `A = "a" B`},
+	}
+
+	for _, tc := range cases {
+		g := parseGrammar(t, tc.src)
+		got, ok := g.Rules[0].MaxMatchLength(g)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%q: want %d, %t, got %d, %t", tc.src, tc.want, tc.ok, got, ok)
+		}
+	}
+
+	// without grammar, the references are unbounded
+	g := parseGrammar(t, `A = "a" B
+B = "b"`)
+	if got, ok := g.Rules[1].MaxMatchLength(nil); got != 1 || !ok {
+		t.Errorf("want 1, true, got %d, %t", got, ok)
+	}
+	if got, ok := g.Rules[0].MaxMatchLength(nil); got != 0 || ok {
+		t.Errorf("want 0, false, got %d, %t", got, ok)
+	}
+	if got, ok := g.Rules[0].MaxMatchLength(g); got != 2 || !ok {
+		t.Errorf("want 2, true, got %d, %t", got, ok)
+	}
+}
+
+func TestCheckDuplicateLabels(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{src: `A = a:'a' b:'b' { return nil, nil }`},
+		{src: `A = (a:'a' / a:'b') { return nil, nil }`},
+		{src: `A = a:'a' b:(a:'b' { return nil, nil }) { return nil, nil }`},
+		{src: `A = a:'a' a:'b' { return nil, nil }`, want: "1:11 (10): duplicate label: a, first defined at 1:5 (4)"},
+		{src: `A = a:'a' ('b' a:'c')* { return nil, nil }`, want: "1:16 (15): duplicate label: a, first defined at 1:5 (4)"},
+		{src: `A = a:'a' (a:'b' / 'c') { return nil, nil }`, want: "1:12 (11): duplicate label: a, first defined at 1:5 (4)"},
+		{src: `A = a:'a' a:'b'`},
+	}
+	for _, tc := range cases {
+		g := parseGrammar(t, tc.src)
+		err := ast.CheckDuplicateLabels(g)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.want {
+			t.Errorf("%q: want error %q, got %q", tc.src, tc.want, got)
+		}
+	}
+}
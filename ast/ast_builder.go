@@ -0,0 +1,179 @@
+package ast
+
+// GrammarBuilder builds a grammar programmatically, e.g.:
+//
+//	b := NewGrammarBuilder()
+//	b.AddRule("Start").Seq(Ref("Digits"), Not(Any()))
+//	b.AddRule("Digits").Expr(OneOrMore(Class([2]rune{'0', '9'}))).Action("{ return string(c.text), nil }")
+//	g := b.Grammar()
+//
+// All the nodes are created at the zero position.
+type GrammarBuilder struct {
+	g *Grammar
+}
+
+// NewGrammarBuilder creates a builder of an empty grammar.
+func NewGrammarBuilder() *GrammarBuilder {
+	return &GrammarBuilder{g: NewGrammar(Pos{})}
+}
+
+// Init sets the initializer of the grammar to the code block code, which
+// must include the enclosing braces.
+func (b *GrammarBuilder) Init(code string) *GrammarBuilder {
+	b.g.Init = NewCodeBlock(Pos{}, code)
+	return b
+}
+
+// AddRule adds a rule named name to the grammar, after the previous
+// rules, and returns its builder.
+func (b *GrammarBuilder) AddRule(name string) *RuleBuilder {
+	r := NewRule(Pos{}, NewIdentifier(Pos{}, name))
+	b.g.Rules = append(b.g.Rules, r)
+	return &RuleBuilder{r: r}
+}
+
+// Grammar returns the grammar built so far.
+func (b *GrammarBuilder) Grammar() *Grammar {
+	return b.g
+}
+
+// RuleBuilder builds a rule of a grammar, as returned by
+// GrammarBuilder.AddRule.
+type RuleBuilder struct {
+	r *Rule
+}
+
+// DisplayName sets the display name of the rule.
+func (rb *RuleBuilder) DisplayName(name string) *RuleBuilder {
+	rb.r.DisplayName = NewStringLit(Pos{}, name)
+	return rb
+}
+
+// Expr sets the expression of the rule to expr.
+func (rb *RuleBuilder) Expr(expr Expression) *RuleBuilder {
+	rb.r.Expr = expr
+	return rb
+}
+
+// Seq sets the expression of the rule to a sequence of exprs.
+func (rb *RuleBuilder) Seq(exprs ...Expression) *RuleBuilder {
+	return rb.Expr(Seq(exprs...))
+}
+
+// Choice sets the expression of the rule to a choice between the
+// alternatives exprs.
+func (rb *RuleBuilder) Choice(exprs ...Expression) *RuleBuilder {
+	return rb.Expr(Choice(exprs...))
+}
+
+// Action wraps the expression of the rule in an action with the code
+// block code, which must include the enclosing braces.
+func (rb *RuleBuilder) Action(code string) *RuleBuilder {
+	return rb.Expr(Action(rb.r.Expr, code))
+}
+
+// Rule returns the rule built so far.
+func (rb *RuleBuilder) Rule() *Rule {
+	return rb.r
+}
+
+// Lit creates a literal matcher of the string s.
+func Lit(s string) *LitMatcher {
+	return NewLitMatcher(Pos{}, s)
+}
+
+// LitI creates a case-insensitive literal matcher of the string s.
+func LitI(s string) *LitMatcher {
+	m := NewLitMatcher(Pos{}, s)
+	m.IgnoreCase = true
+	return m
+}
+
+// Class creates a character class matcher of the ranges of runes, each
+// range being the pair of its low and high runes, inclusive.
+func Class(ranges ...[2]rune) *CharClassMatcher {
+	rs := make([]rune, 0, 2*len(ranges))
+	for _, r := range ranges {
+		rs = append(rs, r[0], r[1])
+	}
+	return NewCharClassMatcher(Pos{}, charClassFromRanges(rs, false))
+}
+
+// Any creates a matcher of any character.
+func Any() *AnyMatcher {
+	return NewAnyMatcher(Pos{}, ".")
+}
+
+// Ref creates a reference to the rule named name.
+func Ref(name string) *RuleRefExpr {
+	ref := NewRuleRefExpr(Pos{})
+	ref.Name = NewIdentifier(Pos{}, name)
+	return ref
+}
+
+// Seq creates a sequence of exprs.
+func Seq(exprs ...Expression) *SeqExpr {
+	seq := NewSeqExpr(Pos{})
+	seq.Exprs = exprs
+	return seq
+}
+
+// Choice creates a choice between the alternatives exprs.
+func Choice(exprs ...Expression) *ChoiceExpr {
+	ch := NewChoiceExpr(Pos{})
+	ch.Alternatives = exprs
+	return ch
+}
+
+// Label creates a labeled expression of expr with the label name.
+func Label(name string, expr Expression) *LabeledExpr {
+	lbl := NewLabeledExpr(Pos{})
+	lbl.Label = NewIdentifier(Pos{}, name)
+	lbl.Expr = expr
+	return lbl
+}
+
+// Action creates an action of expr with the code block code, which must
+// include the enclosing braces.
+func Action(expr Expression, code string) *ActionExpr {
+	act := NewActionExpr(Pos{})
+	act.Expr = expr
+	act.Code = NewCodeBlock(Pos{}, code)
+	return act
+}
+
+// ZeroOrMore creates a repetition of expr, that matches zero or more
+// times.
+func ZeroOrMore(expr Expression) *ZeroOrMoreExpr {
+	e := NewZeroOrMoreExpr(Pos{})
+	e.Expr = expr
+	return e
+}
+
+// OneOrMore creates a repetition of expr, that matches one or more times.
+func OneOrMore(expr Expression) *OneOrMoreExpr {
+	e := NewOneOrMoreExpr(Pos{})
+	e.Expr = expr
+	return e
+}
+
+// Optional creates an optional expr, that matches zero or one time.
+func Optional(expr Expression) *ZeroOrOneExpr {
+	e := NewZeroOrOneExpr(Pos{})
+	e.Expr = expr
+	return e
+}
+
+// And creates a positive predicate on expr.
+func And(expr Expression) *AndExpr {
+	e := NewAndExpr(Pos{})
+	e.Expr = expr
+	return e
+}
+
+// Not creates a negative predicate on expr.
+func Not(expr Expression) *NotExpr {
+	e := NewNotExpr(Pos{})
+	e.Expr = expr
+	return e
+}
@@ -0,0 +1,58 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestGrammarBuilder(t *testing.T) {
+	b := ast.NewGrammarBuilder().Init("{ package p }")
+	b.AddRule("Start").Seq(ast.Label("n", ast.Ref("Number")), ast.Not(ast.Any()))
+	b.AddRule("Number").DisplayName("number").
+		Choice(ast.Seq(ast.Optional(ast.Lit("-")), ast.OneOrMore(ast.Class([2]rune{'0', '9'}))), ast.LitI("nan")).
+		Action("{ return string(c.text), nil }")
+	b.AddRule("Space").Expr(ast.ZeroOrMore(ast.Class([2]rune{'a', 'z'}, [2]rune{'_', '_'})))
+	b.AddRule("Ahead").Expr(ast.And(ast.Lit("a")))
+	got := b.Grammar()
+
+	// the same grammar, written with struct literals
+	ident := func(s string) *ast.Identifier { return ast.NewIdentifier(ast.Pos{}, s) }
+	digits := ast.NewCharClassMatcher(ast.Pos{}, "[0-9]")
+	nan := ast.NewLitMatcher(ast.Pos{}, "nan")
+	nan.IgnoreCase = true
+	want := ast.NewGrammar(ast.Pos{})
+	want.Init = ast.NewCodeBlock(ast.Pos{}, "{ package p }")
+	want.Rules = []*ast.Rule{
+		{Name: ident("Start"), Expr: &ast.SeqExpr{Exprs: []ast.Expression{
+			&ast.LabeledExpr{Label: ident("n"), Expr: &ast.RuleRefExpr{Name: ident("Number")}},
+			&ast.NotExpr{Expr: ast.NewAnyMatcher(ast.Pos{}, ".")},
+		}}},
+		{Name: ident("Number"), DisplayName: ast.NewStringLit(ast.Pos{}, "number"), Expr: &ast.ActionExpr{
+			Expr: &ast.ChoiceExpr{Alternatives: []ast.Expression{
+				&ast.SeqExpr{Exprs: []ast.Expression{
+					&ast.ZeroOrOneExpr{Expr: ast.NewLitMatcher(ast.Pos{}, "-")},
+					&ast.OneOrMoreExpr{Expr: digits},
+				}},
+				nan,
+			}},
+			Code: ast.NewCodeBlock(ast.Pos{}, "{ return string(c.text), nil }"),
+		}},
+		{Name: ident("Space"), Expr: &ast.ZeroOrMoreExpr{Expr: ast.NewCharClassMatcher(ast.Pos{}, "[a-z_]")}},
+		{Name: ident("Ahead"), Expr: &ast.AndExpr{Expr: ast.NewLitMatcher(ast.Pos{}, "a")}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// and parsed from source
+	parsed := parseGrammar(t, `{ package p }
+Start = n:Number !.
+Number "number" = ( '-'? [0-9]+ / "nan"i ) { return string(c.text), nil }
+Space = [a-z_]*
+Ahead = &"a"`)
+	if ast.Hash(got) != ast.Hash(parsed) {
+		t.Errorf("want %v, got %v", parsed, got)
+	}
+}
@@ -0,0 +1,159 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpandCharsets merges the named character sets of the grammar in the
+// character classes that reference them, e.g. [<Name>-], and replaces
+// each reference to a charset in place of an expression, e.g. Name*, by
+// a character class matcher of the charset. A charset may reference
+// other charsets. The Val of an expanded class is rewritten with the
+// content of the referenced charsets, e.g. "[a-z_-]" for "[<Name>-]" if
+// Name is defined as [a-z_], so that it can be used in the error messages
+// of the generated parser.
+//
+// It returns an error for each charset that is defined more than once,
+// that has the same name as a rule or that is part of a cycle of
+// references, for each reference to an undefined charset, and for each
+// reference to an inverted or case-insensitive charset in a class, as
+// those cannot be merged. The grammar is modified in place, and expanding
+// it again is a no-op.
+func ExpandCharsets(g *Grammar) error {
+	errs := new(errList)
+	rules := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = true
+	}
+
+	e := &charsetExpander{
+		sets:  make(map[string]*Charset, len(g.Charsets)),
+		state: make(map[string]charsetState, len(g.Charsets)),
+		errs:  errs,
+	}
+	for _, cs := range g.Charsets {
+		nm := cs.Name.Val
+		if first, ok := e.sets[nm]; ok {
+			errs.add(cs.Pos(), fmt.Errorf("charset %s already defined at %s", nm, first.Pos()))
+			continue
+		}
+		if rules[nm] {
+			errs.add(cs.Pos(), fmt.Errorf("charset %s has the same name as a rule", nm))
+			continue
+		}
+		e.sets[nm] = cs
+	}
+	for _, cs := range g.Charsets {
+		if e.sets[cs.Name.Val] == cs {
+			e.expandCharset(cs)
+		}
+	}
+
+	Walk(e, g)
+	return errs.err()
+}
+
+type charsetState int
+
+const (
+	charsetVisiting charsetState = iota + 1
+	charsetExpanded
+	charsetInvalid
+)
+
+// charsetExpander expands the charsets and the classes that reference
+// them, and replaces the references to the charsets in place of an
+// expression while it walks the grammar.
+type charsetExpander struct {
+	sets  map[string]*Charset
+	state map[string]charsetState
+	path  []string
+	errs  *errList
+}
+
+func (e *charsetExpander) Visit(expr Expression, br Backref) Visitor {
+	switch expr := expr.(type) {
+	case *CharClassMatcher:
+		e.expandClass(expr)
+	case *RuleRefExpr:
+		cs := e.sets[expr.Name.Val]
+		if cs != nil && e.expandCharset(cs) {
+			cc := cloneExpr(cs.Class).(*CharClassMatcher)
+			cc.p = expr.Pos()
+			br.replacer(cc)
+		}
+	}
+	return e
+}
+
+// expandCharset expands the class of the charset cs, and returns true if
+// it is valid.
+func (e *charsetExpander) expandCharset(cs *Charset) bool {
+	nm := cs.Name.Val
+	switch e.state[nm] {
+	case charsetExpanded:
+		return true
+	case charsetInvalid:
+		return false
+	case charsetVisiting:
+		start := len(e.path) - 1
+		for e.path[start] != nm {
+			start--
+		}
+		cycle := append(append([]string(nil), e.path[start:]...), nm)
+		e.errs.add(cs.Pos(), fmt.Errorf("charset cycle: %s", strings.Join(cycle, " -> ")))
+		return false
+	}
+
+	e.state[nm] = charsetVisiting
+	e.path = append(e.path, nm)
+	ok := e.expandClass(cs.Class)
+	e.path = e.path[:len(e.path)-1]
+	if !ok {
+		e.state[nm] = charsetInvalid
+		return false
+	}
+	e.state[nm] = charsetExpanded
+	return true
+}
+
+// expandClass merges the charsets referenced by the class cc in it, and
+// returns true if they are all valid.
+func (e *charsetExpander) expandClass(cc *CharClassMatcher) bool {
+	if len(cc.Charsets) == 0 {
+		return true
+	}
+
+	ok := true
+	val := cc.Val
+	chars, ranges, classes := cc.Chars, cc.Ranges, cc.UnicodeClasses
+	for _, nm := range cc.Charsets {
+		cs := e.sets[nm]
+		if cs == nil {
+			e.errs.add(cc.Pos(), fmt.Errorf("undefined charset: %s", nm))
+			ok = false
+			continue
+		}
+		if !e.expandCharset(cs) {
+			ok = false
+			continue
+		}
+		ref := cs.Class
+		if ref.Inverted || ref.IgnoreCase {
+			e.errs.add(cc.Pos(), fmt.Errorf("charset %s cannot be merged in a class: it is inverted or case-insensitive", nm))
+			ok = false
+			continue
+		}
+		chars = append(chars[:len(chars):len(chars)], ref.Chars...)
+		ranges = append(ranges[:len(ranges):len(ranges)], ref.Ranges...)
+		classes = append(classes[:len(classes):len(classes)], ref.UnicodeClasses...)
+		val = strings.Replace(val, "<"+nm+">", ref.Val[1:len(ref.Val)-1], 1)
+	}
+	if ok {
+		cc.Val = val
+		cc.Chars, cc.Ranges, cc.UnicodeClasses = chars, ranges, classes
+		cc.Charsets = nil
+	}
+	return ok
+}
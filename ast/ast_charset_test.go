@@ -0,0 +1,107 @@
+package ast_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func charset(name, class string) *ast.Charset {
+	cs := ast.NewCharset(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, name))
+	cs.Class = ast.NewCharClassMatcher(ast.Pos{}, class)
+	return cs
+}
+
+func TestCharClassCharsetRefs(t *testing.T) {
+	cc := ast.NewCharClassMatcher(ast.Pos{}, "[<Ident>-<x1>a-c<>< b>]")
+	if want := []string{"Ident", "x1"}; !reflect.DeepEqual(cc.Charsets, want) {
+		t.Errorf("want charsets %v, got %v", want, cc.Charsets)
+	}
+	if want := []rune{'-', '<', '>', '<', ' ', 'b', '>'}; !reflect.DeepEqual(cc.Chars, want) {
+		t.Errorf("want chars %q, got %q", want, cc.Chars)
+	}
+	if want := []rune{'a', 'c'}; !reflect.DeepEqual(cc.Ranges, want) {
+		t.Errorf("want ranges %q, got %q", want, cc.Ranges)
+	}
+}
+
+func TestExpandCharsets(t *testing.T) {
+	g := parseGrammar(t, "A = [<Ident>-] Ident !Digit\n")
+	g.Charsets = []*ast.Charset{
+		charset("Ident", `[a-z_<Digit>]`),
+		charset("Digit", `[0-9\pN]`),
+	}
+	if err := ast.ExpandCharsets(g); err != nil {
+		t.Fatal(err)
+	}
+
+	seq := g.Rules[0].Expr.(*ast.SeqExpr)
+	cc := seq.Exprs[0].(*ast.CharClassMatcher)
+	if cc.Val != `[a-z_0-9\pN-]` {
+		t.Errorf("want expanded class %q, got %q", `[a-z_0-9\pN-]`, cc.Val)
+	}
+	if len(cc.Charsets) != 0 {
+		t.Errorf("want no charset left, got %v", cc.Charsets)
+	}
+	if want := []rune{'-', '_'}; !reflect.DeepEqual(cc.Chars, want) {
+		t.Errorf("want chars %q, got %q", want, cc.Chars)
+	}
+	if want := []rune{'a', 'z', '0', '9'}; !reflect.DeepEqual(cc.Ranges, want) {
+		t.Errorf("want ranges %q, got %q", want, cc.Ranges)
+	}
+	if want := []string{"N"}; !reflect.DeepEqual(cc.UnicodeClasses, want) {
+		t.Errorf("want Unicode classes %q, got %q", want, cc.UnicodeClasses)
+	}
+
+	ref, ok := seq.Exprs[1].(*ast.CharClassMatcher)
+	if !ok || ref.Val != `[a-z_0-9\pN]` {
+		t.Fatalf("want charset reference replaced by its class, got %v", seq.Exprs[1])
+	}
+	if ref == g.Charsets[0].Class {
+		t.Error("want a copy of the class of the charset")
+	}
+	not := seq.Exprs[2].(*ast.NotExpr)
+	if cc, ok := not.Expr.(*ast.CharClassMatcher); !ok || cc.Val != `[0-9\pN]` {
+		t.Errorf("want charset reference replaced by its class, got %v", not.Expr)
+	}
+
+	before := ast.Hash(g)
+	if err := ast.ExpandCharsets(g); err != nil {
+		t.Fatal(err)
+	}
+	if ast.Hash(g) != before {
+		t.Error("want expanding twice to be a no-op")
+	}
+}
+
+func TestExpandCharsetsErrors(t *testing.T) {
+	g := parseGrammar(t, "A = [<Undef>] [<Not>] B\nB = 'b'\n")
+	g.Charsets = []*ast.Charset{
+		charset("X", `[<Y>]`),
+		charset("Y", `[a<Z>]`),
+		charset("Z", `[<X>]`),
+		charset("Not", `[^a]`),
+		charset("Not", `[a]`),
+		charset("B", `[b]`),
+	}
+	err := ast.ExpandCharsets(g)
+	if err == nil {
+		t.Fatal("want error")
+	}
+	for _, want := range []string{
+		"charset Not already defined at",
+		"charset B has the same name as a rule",
+		"charset cycle: X -> Y -> Z -> X",
+		"undefined charset: Undef",
+		"charset Not cannot be merged in a class",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("want error %q in:\n%v", want, err)
+		}
+	}
+	if n := strings.Count(err.Error(), "cycle"); n != 1 {
+		t.Errorf("want the cycle reported once, got %d", n)
+	}
+}
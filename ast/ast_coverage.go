@@ -0,0 +1,123 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CoverageKey is the key of the parser state under which the parsers
+// generated from a grammar instrumented by InstrumentForCoverage record
+// the number of times each rule is executed, as a map[string]int.
+const CoverageKey = "pigeon.coverage"
+
+// coverageLabel is the label of the original expression of an
+// instrumented rule.
+const coverageLabel = "pigeonCoverage"
+
+// InstrumentForCoverage transforms the grammar so that the generated
+// parser records the number of times each rule is executed. A
+// StateCodeExpr that increments the count of the rule is inserted at the
+// start of the expression of each rule, and the rule's value is preserved.
+// The counts are stored in the parser state, under CoverageKey, in a
+// map[string]int that is shared by all the state snapshots so that the
+// executions of the rules that eventually fail are recorded too. The map
+// is created by the first executed rule if it is not provided with the
+// InitState option, e.g.:
+//
+//	hits := make(map[string]int)
+//	Parse(filename, b, InitState(ast.CoverageKey, hits))
+//	cov := ast.CoverageReport(map[string]interface{}{ast.CoverageKey: hits})
+//
+// Note that the results of memoized rules are not counted again when they
+// are read from the cache. The grammar is modified in place and returned.
+func InstrumentForCoverage(g *Grammar) *Grammar {
+	for _, r := range g.Rules {
+		if r.Expr == nil {
+			continue
+		}
+		pos := r.Expr.Pos()
+
+		state := NewStateCodeExpr(pos)
+		state.Code = NewCodeBlock(pos, fmt.Sprintf(`{
+	hits, _ := c.state[%q].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state[%[1]q] = hits
+	}
+	hits[%s]++
+	return nil
+}`, CoverageKey, strconv.Quote(r.Name.Val)))
+
+		lbl := NewLabeledExpr(pos)
+		lbl.Label = NewIdentifier(pos, coverageLabel)
+		lbl.Expr = r.Expr
+
+		seq := NewSeqExpr(pos)
+		seq.Exprs = []Expression{state, lbl}
+
+		act := NewActionExpr(pos)
+		act.Expr = seq
+		act.Code = NewCodeBlock(pos, "{ return "+coverageLabel+", nil }")
+		r.Expr = act
+	}
+	return g
+}
+
+// GrammarCoverage is the rule coverage of a parser generated from a
+// grammar instrumented by InstrumentForCoverage, as returned by
+// CoverageReport.
+type GrammarCoverage struct {
+	// Hits is the number of times each rule was executed, by rule name.
+	// The rules that were never executed are not part of the map.
+	Hits map[string]int
+}
+
+// CoverageReport returns the rule coverage recorded in the parser state,
+// under CoverageKey. The coverage is empty if the state has no such
+// record.
+func CoverageReport(state map[string]interface{}) GrammarCoverage {
+	gc := GrammarCoverage{Hits: make(map[string]int)}
+	hits, _ := state[CoverageKey].(map[string]int)
+	for nm, n := range hits {
+		gc.Hits[nm] = n
+	}
+	return gc
+}
+
+// Uncovered returns the names of the rules of g that were never executed,
+// in the order of the grammar.
+func (gc GrammarCoverage) Uncovered(g *Grammar) []string {
+	var names []string
+	for _, r := range g.Rules {
+		if gc.Hits[r.Name.Val] == 0 {
+			names = append(names, r.Name.Val)
+		}
+	}
+	return names
+}
+
+// Ratio returns the ratio of the rules of g that were executed at least
+// once, between 0 and 1. It returns 1 if g has no rule.
+func (gc GrammarCoverage) Ratio(g *Grammar) float64 {
+	if len(g.Rules) == 0 {
+		return 1
+	}
+	return float64(len(g.Rules)-len(gc.Uncovered(g))) / float64(len(g.Rules))
+}
+
+// Rules returns the names of the executed rules, sorted by decreasing
+// number of hits, then by name.
+func (gc GrammarCoverage) Rules() []string {
+	names := make([]string, 0, len(gc.Hits))
+	for nm := range gc.Hits {
+		names = append(names, nm)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if hi, hj := gc.Hits[names[i]], gc.Hits[names[j]]; hi != hj {
+			return hi > hj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
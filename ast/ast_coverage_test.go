@@ -0,0 +1,61 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestInstrumentForCoverage(t *testing.T) {
+	g := parseGrammar(t, `A = B "a"
+B = "b" { return nil, nil }
+C = "c"`)
+	if got := ast.InstrumentForCoverage(g); got != g {
+		t.Fatalf("want the grammar to be instrumented in place")
+	}
+	if err := ast.CheckDuplicateLabels(g); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range g.Rules {
+		act, ok := r.Expr.(*ast.ActionExpr)
+		if !ok {
+			t.Fatalf("%s: want *ast.ActionExpr, got %T", r.Name.Val, r.Expr)
+		}
+		seq := act.Expr.(*ast.SeqExpr)
+		if len(seq.Exprs) != 2 {
+			t.Fatalf("%s: want 2 expressions, got %d", r.Name.Val, len(seq.Exprs))
+		}
+		if _, ok := seq.Exprs[0].(*ast.StateCodeExpr); !ok {
+			t.Errorf("%s: want *ast.StateCodeExpr, got %T", r.Name.Val, seq.Exprs[0])
+		}
+		if _, ok := seq.Exprs[1].(*ast.LabeledExpr); !ok {
+			t.Errorf("%s: want *ast.LabeledExpr, got %T", r.Name.Val, seq.Exprs[1])
+		}
+	}
+}
+
+func TestCoverageReport(t *testing.T) {
+	g := parseGrammar(t, `A = B "a"
+B = "b"
+C = "c"`)
+
+	cov := ast.CoverageReport(map[string]interface{}{
+		ast.CoverageKey: map[string]int{"A": 1, "B": 3},
+	})
+	if got, want := cov.Uncovered(g), []string{"C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want uncovered %v, got %v", want, got)
+	}
+	if got, want := cov.Rules(), []string{"B", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want rules %v, got %v", want, got)
+	}
+	if got := cov.Ratio(g); got != 2.0/3 {
+		t.Errorf("want ratio %f, got %f", 2.0/3, got)
+	}
+
+	cov = ast.CoverageReport(nil)
+	if len(cov.Hits) != 0 || cov.Ratio(g) != 0 {
+		t.Errorf("want empty coverage, got %v", cov.Hits)
+	}
+}
@@ -0,0 +1,138 @@
+package ast
+
+import (
+	"strconv"
+)
+
+// DuplicateRulePrefix is the prefix of the names of the rules created by
+// ExtractDuplicates, followed by a sequence number, e.g. _dup1.
+const DuplicateRulePrefix = "_dup"
+
+// ExtractDuplicates moves the sub-expressions of the rules that appear at
+// least minCount times in the grammar, as defined by Equal, to new rules
+// appended to the grammar, and replaces each of their occurrences with a
+// reference to the new rule. This reduces the size of the generated parser
+// and lets the occurrences share the memoized results. The largest
+// duplicates are extracted first, and the new rules are named with
+// DuplicateRulePrefix and the next sequence number that is not the name of
+// a rule yet. It returns the names of the new rules, in order of creation.
+//
+// Only the sub-expressions made of several expressions are extracted, and
+// not those that contain an action, a label, a code block or a throw or
+// recovery expression, as moving them to another rule could change their
+// meaning. The expressions of the rules themselves are never replaced. A
+// minCount below 2 is treated as 2.
+//
+// It should be called after Optimize, which would inline the new rules.
+func ExtractDuplicates(g *Grammar, minCount int) []string {
+	if minCount < 2 {
+		minCount = 2
+	}
+
+	var names []string
+	seq := 0
+	for {
+		dup := findDuplicate(g, minCount)
+		if dup == nil {
+			return names
+		}
+
+		var name string
+		for {
+			seq++
+			name = DuplicateRulePrefix + strconv.Itoa(seq)
+			if _, ok := g.RuleByName(name); !ok {
+				break
+			}
+		}
+		rule := NewRule(dup.Pos(), NewIdentifier(dup.Pos(), name))
+		rule.Expr = cloneExpr(dup)
+		Walk(dupReplacer{dup: dup, name: name}, g)
+		g.AddRule(rule)
+		names = append(names, name)
+	}
+}
+
+// dupGroup is a group of equal sub-expressions of a grammar.
+type dupGroup struct {
+	expr  Expression
+	count int
+	size  int
+}
+
+// findDuplicate returns the largest sub-expression that may be extracted
+// and that appears at least minCount times in the grammar, the first one
+// in the grammar if there are more than one, or nil if there is none.
+func findDuplicate(g *Grammar, minCount int) Expression {
+	groups := make(map[[32]byte]*dupGroup)
+	var order []*dupGroup
+	for _, r := range g.Rules {
+		Inspect(r.Expr, func(expr Expression) bool {
+			if expr == nil || expr == r.Expr || !extractable(expr) {
+				return expr != nil
+			}
+			h := Hash(expr)
+			grp := groups[h]
+			if grp == nil {
+				grp = &dupGroup{expr: expr, size: ExpressionSize(expr)}
+				groups[h] = grp
+				order = append(order, grp)
+			}
+			if Equal(grp.expr, expr) {
+				grp.count++
+			}
+			return true
+		})
+	}
+
+	var best *dupGroup
+	for _, grp := range order {
+		if grp.count >= minCount && (best == nil || grp.size > best.size) {
+			best = grp
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.expr
+}
+
+// extractable returns true if expr is made of several expressions and
+// may be moved to another rule without changing its meaning.
+func extractable(expr Expression) bool {
+	switch expr.(type) {
+	case *AndExpr, *ChoiceExpr, *LookbehindExpr, *NotExpr, *OneOrMoreExpr, *SeqExpr, *ZeroOrMoreExpr, *ZeroOrOneExpr:
+	default:
+		return false
+	}
+	ok := true
+	Inspect(expr, func(expr Expression) bool {
+		switch expr.(type) {
+		case *ActionExpr, *AndCodeExpr, *LabeledExpr, *NotCodeExpr, *RecoveryExpr, *StateCodeExpr, *ThrowExpr:
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
+
+// dupReplacer replaces the occurrences of dup in the rules by references
+// to the rule name.
+type dupReplacer struct {
+	dup  Expression
+	name string
+}
+
+func (v dupReplacer) Visit(expr Expression, br Backref) Visitor {
+	switch br.parent.(type) {
+	case nil, *Grammar, *Rule:
+		return v
+	}
+	if Equal(expr, v.dup) {
+		ref := NewRuleRefExpr(expr.Pos())
+		ref.Name = NewIdentifier(expr.Pos(), v.name)
+		br.Replace(ref)
+		return nil
+	}
+	return v
+}
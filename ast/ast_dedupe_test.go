@@ -0,0 +1,58 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestExtractDuplicates(t *testing.T) {
+	g := parseGrammar(t, `
+Start = ( "a" [0-9]+ / "b" ) "," ( "a" [0-9]+ / "b" ) !.
+List = "[" ( "a" [0-9]+ / "b" ) ( "," [0-9]+ )* "]"
+Pair = [0-9]+ ":" x:( "a" "b" ) ( "a" "b" ) { return x, nil }
+Whole = "a" [0-9]+
+Digits = [0-9]+
+`)
+	names := ast.ExtractDuplicates(g, 3)
+	if want := []string{"_dup1", "_dup2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want rules %v, got %v", want, names)
+	}
+	want := `Start = _dup1 "," _dup1 /* !<any character> */ .
+List = "[" _dup1 { "," _dup2 } "]" .
+Pair = _dup2 ":" "a" "b" "a" "b" .
+Whole = "a" _dup2 .
+Digits = "0" … "9" { "0" … "9" } .
+_dup1 = "a" _dup2 | "b" .
+_dup2 = "0" … "9" { "0" … "9" } .
+`
+	if got := g.ToEBNF(); got != want {
+		t.Errorf("want grammar:\n%s\ngot:\n%s", want, got)
+	}
+	if names := ast.ExtractDuplicates(g, 3); len(names) != 0 {
+		t.Errorf("want no more rules, got %v", names)
+	}
+}
+
+func TestExtractDuplicatesNames(t *testing.T) {
+	g := parseGrammar(t, `
+A = ( "x" / "y" ) ( "x" / "y" )
+_dup1 = "z"
+`)
+	names := ast.ExtractDuplicates(g, 0)
+	if want := []string{"_dup2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want rules %v, got %v", want, names)
+	}
+	if r, ok := g.RuleByName("_dup2"); !ok || r != g.Rules[2] {
+		t.Errorf("want rule _dup2 appended to the grammar, got %v", g.Rules)
+	}
+
+	// the expressions that contain labels or actions are never extracted
+	g = parseGrammar(t, `
+A = ( x:"x" / "y" ) ( x:"x" / "y" ) ( "z" { return nil, nil } / "w" ) ( "z" { return nil, nil } / "w" )
+`)
+	if names := ast.ExtractDuplicates(g, 2); len(names) != 0 {
+		t.Errorf("want no rules, got %v", names)
+	}
+}
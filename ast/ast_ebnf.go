@@ -0,0 +1,162 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Precedence levels of the EBNF expressions, used to add the parentheses
+// required by the context of an expression.
+const (
+	ebnfChoice = iota
+	ebnfSeq
+	ebnfPrimary
+)
+
+// ToEBNF returns the grammar in the EBNF notation of the Go specification,
+// with one production per rule, e.g.:
+//
+//	Expr = Term { ( "+" | "-" ) Term } .
+//
+// A sequence is written as its juxtaposed expressions, a choice as its
+// alternatives separated by "|", e* as { e }, e? as [ e ] and e+ as
+// e { e }. A character class is written as the alternatives of its
+// characters and ranges, e.g. ( "_" | "a" … "z" ). The constructs that
+// EBNF has no equivalent for, i.e. the predicates, the code blocks, the
+// any and beginning of line matchers, and the character classes that are
+// inverted, case-insensitive or that use Unicode classes, are written as
+// comments. The action and labeled expressions are written as the
+// expression they wrap.
+func (g *Grammar) ToEBNF() string {
+	var buf bytes.Buffer
+	for _, r := range g.Rules {
+		fmt.Fprintf(&buf, "%s = %s .\n", r.Name.Val, toEBNF(r.Expr, ebnfChoice))
+	}
+	return buf.String()
+}
+
+// toEBNF returns the EBNF notation of expr, in parentheses if its
+// precedence is lower than prec.
+func toEBNF(expr Expression, prec int) string {
+	s, p := ebnfExpr(expr)
+	if p < prec {
+		return "( " + s + " )"
+	}
+	return s
+}
+
+// ebnfExpr returns the EBNF notation of expr and its precedence.
+func ebnfExpr(expr Expression) (string, int) {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return ebnfExpr(expr.Expr)
+	case *AndCodeExpr:
+		return ebnfComment("&{...}"), ebnfPrimary
+	case *AndExpr:
+		return ebnfComment("&" + toEBNF(expr.Expr, ebnfPrimary)), ebnfPrimary
+	case *AnyMatcher:
+		return ebnfComment("any character"), ebnfPrimary
+	case *BOLMatcher:
+		return ebnfComment("beginning of line"), ebnfPrimary
+	case *CharClassMatcher:
+		return ebnfCharClass(expr)
+	case *ChoiceExpr:
+		if len(expr.Alternatives) == 1 {
+			return ebnfExpr(expr.Alternatives[0])
+		}
+		alts := make([]string, 0, len(expr.Alternatives))
+		for _, alt := range expr.Alternatives {
+			alts = append(alts, toEBNF(alt, ebnfSeq))
+		}
+		return strings.Join(alts, " | "), ebnfChoice
+	case *KeywordMatcher:
+		if len(expr.Keywords) == 0 {
+			return ebnfComment("keyword"), ebnfPrimary
+		}
+		kws := make([]string, 0, len(expr.Keywords))
+		for _, kw := range expr.Keywords {
+			kws = append(kws, strconv.Quote(kw))
+		}
+		if len(kws) == 1 {
+			return kws[0], ebnfPrimary
+		}
+		return "( " + strings.Join(kws, " | ") + " )", ebnfPrimary
+	case *LabeledExpr:
+		return ebnfExpr(expr.Expr)
+	case *LitMatcher:
+		if expr.IgnoreCase {
+			return strconv.Quote(expr.Val) + " " + ebnfComment("ignore case"), ebnfSeq
+		}
+		return strconv.Quote(expr.Val), ebnfPrimary
+	case *LookbehindExpr:
+		op := "<&"
+		if expr.Negate {
+			op = "<!"
+		}
+		return ebnfComment(op + toEBNF(expr.Expr, ebnfPrimary)), ebnfPrimary
+	case *NotCodeExpr:
+		return ebnfComment("!{...}"), ebnfPrimary
+	case *NotExpr:
+		return ebnfComment("!" + toEBNF(expr.Expr, ebnfPrimary)), ebnfPrimary
+	case *OneOrMoreExpr:
+		s := toEBNF(expr.Expr, ebnfSeq)
+		return s + " { " + toEBNF(expr.Expr, ebnfChoice) + " }", ebnfSeq
+	case *RecoveryExpr:
+		return toEBNF(expr.Expr, ebnfSeq) + " " + ebnfComment("recovered by "+toEBNF(expr.RecoverExpr, ebnfPrimary)), ebnfSeq
+	case *RegexpMatcher:
+		return ebnfComment("@regex " + strconv.Quote(expr.Expr)), ebnfPrimary
+	case *RuleRefExpr:
+		return expr.Name.Val, ebnfPrimary
+	case *SeqExpr:
+		if len(expr.Exprs) == 0 {
+			return `""`, ebnfPrimary
+		}
+		exprs := make([]string, 0, len(expr.Exprs))
+		for _, e := range expr.Exprs {
+			exprs = append(exprs, toEBNF(e, ebnfSeq))
+		}
+		return strings.Join(exprs, " "), ebnfSeq
+	case *StateCodeExpr:
+		return ebnfComment("#{...}"), ebnfPrimary
+	case *ThrowExpr:
+		return ebnfComment("%{" + expr.Label + "}"), ebnfPrimary
+	case *ZeroOrMoreExpr:
+		return "{ " + toEBNF(expr.Expr, ebnfChoice) + " }", ebnfPrimary
+	case *ZeroOrOneExpr:
+		return "[ " + toEBNF(expr.Expr, ebnfChoice) + " ]", ebnfPrimary
+	}
+	return ebnfComment(typeName(expr)), ebnfPrimary
+}
+
+// ebnfCharClass returns the EBNF notation of the character class c and
+// its precedence.
+func ebnfCharClass(c *CharClassMatcher) (string, int) {
+	if c.Inverted || c.IgnoreCase || len(c.UnicodeClasses) > 0 || len(c.Charsets) > 0 ||
+		len(c.Chars)+len(c.Ranges) == 0 {
+		return ebnfComment("any character in " + c.Val), ebnfPrimary
+	}
+
+	alts := make([]string, 0, len(c.Chars)+len(c.Ranges)/2)
+	for _, rn := range c.Chars {
+		alts = append(alts, strconv.Quote(string(rn)))
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		alts = append(alts, strconv.Quote(string(c.Ranges[i]))+" … "+strconv.Quote(string(c.Ranges[i+1])))
+	}
+	if len(alts) == 1 {
+		return alts[0], ebnfPrimary
+	}
+	return strings.Join(alts, " | "), ebnfChoice
+}
+
+// ebnfCommentReplacer rewrites the comments nested in a comment, e.g. in
+// /* !<any character> */, and breaks up the other end of comment
+// sequences.
+var ebnfCommentReplacer = strings.NewReplacer("/* ", "<", " */", ">", "*/", "* /")
+
+// ebnfComment returns s as an EBNF comment.
+func ebnfComment(s string) string {
+	return "/* " + ebnfCommentReplacer.Replace(s) + " */"
+}
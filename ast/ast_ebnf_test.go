@@ -0,0 +1,26 @@
+package ast_test
+
+import (
+	"testing"
+)
+
+func TestToEBNF(t *testing.T) {
+	g := parseGrammar(t, `
+Start = e:Expr !. { return e, nil }
+Expr = Term ( ( "+" / "-" ) Term )*
+Term = Factor ( '*' Factor )+ / &'(' Group / [0-9]+ ( '.' [0-9] )?
+Group = "(" Expr ")"
+Ident = [a-z_] [A-Z0-9_]* "let"i
+Other = [^a-z] [\pL] . !"*/" "*/"
+`)
+	want := `Start = Expr /* !<any character> */ .
+Expr = Term { ( "+" | "-" ) Term } .
+Term = Factor "*" Factor { "*" Factor } | /* &"(" */ Group | "0" … "9" { "0" … "9" } [ "." "0" … "9" ] .
+Group = "(" Expr ")" .
+Ident = ( "_" | "a" … "z" ) { "_" | "A" … "Z" | "0" … "9" } "let" /* ignore case */ .
+Other = /* any character in [^a-z] */ /* any character in [\pL] */ /* any character */ /* !"* /" */ "*/" .
+`
+	if got := g.ToEBNF(); got != want {
+		t.Errorf("want EBNF:\n%s\ngot:\n%s", want, got)
+	}
+}
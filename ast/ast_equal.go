@@ -0,0 +1,214 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Equal returns true if the expressions a and b have the same structure,
+// as defined by Hash: they are of the same type, with the same values and
+// equal sub-expressions. The positions are ignored, and the code blocks
+// are compared verbatim.
+func Equal(a, b Expression) bool {
+	return equal(a, b, false)
+}
+
+// Equals returns true if the grammar g is the same as other, including
+// the positions of all the nodes: the global code block, the ordered list
+// of rules and the charsets must be equal, as defined by Equal, and at the
+// same positions.
+func (g *Grammar) Equals(other *Grammar) bool {
+	return equal(g, other, true)
+}
+
+// EqualsIgnorePositions is like Equals, except that the positions of the
+// nodes are ignored.
+func (g *Grammar) EqualsIgnorePositions(other *Grammar) bool {
+	return equal(g, other, false)
+}
+
+// equal implements Equal, and compares the positions of the nodes too if
+// pos is true.
+func equal(a, b Expression, pos bool) bool {
+	if isNil(a) || isNil(b) {
+		return isNil(a) == isNil(b)
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if pos && a.Pos() != b.Pos() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *ActionExpr:
+		b := b.(*ActionExpr)
+		return equalCode(a.Code, b.Code, pos) && equal(a.Expr, b.Expr, pos)
+	case *AndCodeExpr:
+		return equalCode(a.Code, b.(*AndCodeExpr).Code, pos)
+	case *AndExpr:
+		return equal(a.Expr, b.(*AndExpr).Expr, pos)
+	case *AnyMatcher, *BOLMatcher:
+		return true
+	case *CharClassMatcher:
+		return a.Val == b.(*CharClassMatcher).Val
+	case *Charset:
+		b := b.(*Charset)
+		return equalIdent(a.Name, b.Name, pos) && equal(a.Class, b.Class, pos)
+	case *ChoiceExpr:
+		b := b.(*ChoiceExpr)
+		return a.Longest == b.Longest && equalExprs(a.Alternatives, b.Alternatives, pos)
+	case *Grammar:
+		b := b.(*Grammar)
+		if !equalCode(a.Init, b.Init, pos) || len(a.Rules) != len(b.Rules) {
+			return false
+		}
+		if !equalIdent(a.Package, b.Package, pos) || len(a.GoImports) != len(b.GoImports) {
+			return false
+		}
+		for i, imp := range a.GoImports {
+			if imp.Val != b.GoImports[i].Val || pos && imp.Pos() != b.GoImports[i].Pos() {
+				return false
+			}
+		}
+		if len(a.Charsets) != len(b.Charsets) {
+			return false
+		}
+		for i, cs := range a.Charsets {
+			if !equal(cs, b.Charsets[i], pos) {
+				return false
+			}
+		}
+		for i, r := range a.Rules {
+			if !equal(r, b.Rules[i], pos) {
+				return false
+			}
+		}
+		return true
+	case *LabeledExpr:
+		b := b.(*LabeledExpr)
+		return equalIdent(a.Label, b.Label, pos) && equal(a.Expr, b.Expr, pos)
+	case *LitMatcher:
+		b := b.(*LitMatcher)
+		return a.Val == b.Val && a.IgnoreCase == b.IgnoreCase
+	case *LookbehindExpr:
+		b := b.(*LookbehindExpr)
+		return a.Negate == b.Negate && equal(a.Expr, b.Expr, pos)
+	case *NotCodeExpr:
+		return equalCode(a.Code, b.(*NotCodeExpr).Code, pos)
+	case *NotExpr:
+		return equal(a.Expr, b.(*NotExpr).Expr, pos)
+	case *OneOrMoreExpr:
+		return equal(a.Expr, b.(*OneOrMoreExpr).Expr, pos)
+	case *PatternVar:
+		return a.Name == b.(*PatternVar).Name
+	case *RecoveryExpr:
+		b := b.(*RecoveryExpr)
+		return reflect.DeepEqual(a.Labels, b.Labels) && equal(a.Expr, b.Expr, pos) && equal(a.RecoverExpr, b.RecoverExpr, pos)
+	case *KeywordMatcher:
+		return equalStrings(a.Keywords, b.(*KeywordMatcher).Keywords)
+	case *RegexpMatcher:
+		return a.Expr == b.(*RegexpMatcher).Expr
+	case *Rule:
+		b := b.(*Rule)
+		if (a.DisplayName == nil) != (b.DisplayName == nil) {
+			return false
+		}
+		if a.DisplayName != nil && (a.DisplayName.Val != b.DisplayName.Val ||
+			pos && a.DisplayName.Pos() != b.DisplayName.Pos()) {
+			return false
+		}
+		return equalIdent(a.Name, b.Name, pos) && a.Longest == b.Longest &&
+			a.ResultType == b.ResultType && equal(a.Expr, b.Expr, pos)
+	case *RuleRefExpr:
+		return equalIdent(a.Name, b.(*RuleRefExpr).Name, pos)
+	case *SeqExpr:
+		return equalExprs(a.Exprs, b.(*SeqExpr).Exprs, pos)
+	case *StateCodeExpr:
+		return equalCode(a.Code, b.(*StateCodeExpr).Code, pos)
+	case *ThrowExpr:
+		return a.Label == b.(*ThrowExpr).Label
+	case *ZeroOrMoreExpr:
+		return equal(a.Expr, b.(*ZeroOrMoreExpr).Expr, pos)
+	case *ZeroOrOneExpr:
+		return equal(a.Expr, b.(*ZeroOrOneExpr).Expr, pos)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", a))
+	}
+}
+
+// isNil returns true if expr is nil or a nil pointer.
+func isNil(expr Expression) bool {
+	return expr == nil || reflect.ValueOf(expr).IsNil()
+}
+
+func equalExprs(a, b []Expression, pos bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, e := range a {
+		if !equal(e, b[i], pos) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, s := range a {
+		if s != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalCode(a, b *CodeBlock, pos bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Val == b.Val && (!pos || a.Pos() == b.Pos())
+}
+
+func equalIdent(a, b *Identifier, pos bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Val == b.Val && (!pos || a.Pos() == b.Pos())
+}
+
+// IndexOf returns the index of the first expression of the sequence that
+// is Equal to expr, or -1 if there is none.
+func (s *SeqExpr) IndexOf(expr Expression) int {
+	return indexOf(s.Exprs, expr)
+}
+
+// Contains returns true if the sequence has an expression that is Equal to
+// expr.
+func (s *SeqExpr) Contains(expr Expression) bool {
+	return s.IndexOf(expr) >= 0
+}
+
+// IndexOf returns the index of the first alternative of the choice that is
+// Equal to expr, or -1 if there is none.
+func (c *ChoiceExpr) IndexOf(expr Expression) int {
+	return indexOf(c.Alternatives, expr)
+}
+
+// Contains returns true if the choice has an alternative that is Equal to
+// expr.
+func (c *ChoiceExpr) Contains(expr Expression) bool {
+	return c.IndexOf(expr) >= 0
+}
+
+func indexOf(exprs []Expression, expr Expression) int {
+	for i, e := range exprs {
+		if Equal(e, expr) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,126 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestEqual(t *testing.T) {
+	g1 := parseGrammar(t, `A = "x"i b:[a-z]+ { return b, nil } / B
+B = !"y" .`)
+	g2 := parseGrammar(t, `A   =   "x"i   b:[a-z]+ { return b, nil }   /   B
+
+B = !"y"  .`)
+	if !ast.Equal(g1, g2) {
+		t.Error("want equal grammars")
+	}
+	if !ast.Equal(g1.Rules[1].Expr, g2.Rules[1].Expr) {
+		t.Error("want equal rule expressions")
+	}
+	if ast.Equal(g1.Rules[0], g1.Rules[1]) {
+		t.Error("want different rules")
+	}
+
+	cases := []struct {
+		a, b ast.Expression
+		want bool
+	}{
+		{ast.Lit("a"), ast.Lit("a"), true},
+		{ast.Lit("a"), ast.LitI("a"), false},
+		{ast.Lit("a"), ast.Lit("b"), false},
+		{ast.Ref("a"), ast.Ref("a"), true},
+		{ast.Ref("a"), ast.Lit("a"), false},
+		{ast.Seq(ast.Any(), ast.Ref("a")), ast.Seq(ast.Any(), ast.Ref("a")), true},
+		{ast.Seq(ast.Any(), ast.Ref("a")), ast.Seq(ast.Any()), false},
+		{ast.Label("x", ast.Any()), ast.Label("y", ast.Any()), false},
+		{ast.Action(ast.Any(), "{ return 1, nil }"), ast.Action(ast.Any(), "{ return 1, nil }"), true},
+		{ast.Action(ast.Any(), "{ return 1, nil }"), ast.Action(ast.Any(), "{ return 2, nil }"), false},
+		{nil, nil, true},
+		{nil, ast.Any(), false},
+		{(*ast.SeqExpr)(nil), nil, true},
+	}
+	for i, c := range cases {
+		if got := ast.Equal(c.a, c.b); got != c.want {
+			t.Errorf("%d: want %t, got %t", i, c.want, got)
+		}
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	seq := ast.Seq(ast.Lit("a"), ast.Ref("b"), ast.Optional(ast.Lit("c")), ast.Ref("b"))
+	cases := []struct {
+		expr ast.Expression
+		want int
+	}{
+		{ast.Lit("a"), 0},
+		{ast.Ref("b"), 1},
+		{ast.Optional(ast.Lit("c")), 2},
+		{ast.Lit("c"), -1},
+		{ast.LitI("a"), -1},
+	}
+	for _, c := range cases {
+		if got := seq.IndexOf(c.expr); got != c.want {
+			t.Errorf("seq %v: want %d, got %d", c.expr, c.want, got)
+		}
+		if got := seq.Contains(c.expr); got != (c.want >= 0) {
+			t.Errorf("seq %v: want contains %t, got %t", c.expr, c.want >= 0, got)
+		}
+
+		ch := ast.Choice(seq.Exprs...)
+		if got := ch.IndexOf(c.expr); got != c.want {
+			t.Errorf("choice %v: want %d, got %d", c.expr, c.want, got)
+		}
+		if got := ch.Contains(c.expr); got != (c.want >= 0) {
+			t.Errorf("choice %v: want contains %t, got %t", c.expr, c.want >= 0, got)
+		}
+	}
+}
+
+func TestGrammarEquals(t *testing.T) {
+	const src = `{
+package p
+}
+
+A = "x"i b:[a-z]+ { return b, nil } / B
+B = !"y" .`
+
+	g1, g2 := parseGrammar(t, src), parseGrammar(t, src)
+	if !g1.Equals(g2) {
+		t.Error("want equal grammars")
+	}
+	if !g1.EqualsIgnorePositions(g2) {
+		t.Error("want equal grammars ignoring positions")
+	}
+
+	// same grammar at different positions
+	moved := parseGrammar(t, "\n"+src)
+	if g1.Equals(moved) {
+		t.Error("want grammars at different positions to be different")
+	}
+	if !g1.EqualsIgnorePositions(moved) {
+		t.Error("want equal grammars at different positions ignoring positions")
+	}
+
+	// different global code block
+	other := parseGrammar(t, strings.Replace(src, "package p", "package q", 1))
+	if g1.Equals(other) || g1.EqualsIgnorePositions(other) {
+		t.Error("want grammars with different init code to be different")
+	}
+
+	// different charsets
+	withCharset := parseGrammar(t, src)
+	cs := ast.NewCharset(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "Digit"))
+	cs.Class = ast.NewCharClassMatcher(ast.Pos{}, "[0-9]")
+	withCharset.Charsets = append(withCharset.Charsets, cs)
+	if ast.Equal(g1, withCharset) || g1.Equals(withCharset) || g1.EqualsIgnorePositions(withCharset) {
+		t.Error("want grammars with different charsets to be different")
+	}
+
+	// reordered rules
+	g2.Rules[0], g2.Rules[1] = g2.Rules[1], g2.Rules[0]
+	if g1.Equals(g2) || g1.EqualsIgnorePositions(g2) {
+		t.Error("want grammars with reordered rules to be different")
+	}
+}
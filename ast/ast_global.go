@@ -0,0 +1,105 @@
+package ast
+
+import (
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// GlobalCode returns the Go source of the global code of the generated
+// parser, in order: the package clause, the imports of the @import_go
+// directives and the top-level declarations of the initializer, each with
+// its doc comment. As in the generated code, the package clause of the
+// initializer is replaced by the @package directive if there is one. The
+// comments that are not attached to a declaration are dropped, and if the
+// initializer is not valid Go code, its content is returned as a single
+// element after the package clause and the imports.
+func (g *Grammar) GlobalCode() []string {
+	var body string
+	if g.Init != nil {
+		body = strings.TrimSpace(g.Init.Val)
+		body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	}
+
+	var code []string
+	clause, decls, ok := splitGlobalCode(body)
+	if g.Package != nil {
+		clause = "package " + g.Package.Val
+	}
+	if clause != "" {
+		code = append(code, clause)
+	}
+	for _, imp := range g.GoImports {
+		code = append(code, "import "+imp.Val)
+	}
+	if !ok {
+		if body = strings.TrimSpace(body); body != "" {
+			code = append(code, body)
+		}
+		return code
+	}
+	return append(code, decls...)
+}
+
+// splitGlobalCode splits the Go source src into its package clause, if
+// any, and its top-level declarations. It returns false if src is not
+// valid Go code.
+func splitGlobalCode(src string) (clause string, decls []string, ok bool) {
+	// the package clause is optional, as it may be set by @package
+	const prefix = "package p;"
+	fset := token.NewFileSet()
+	shift := 0
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		shift = len(prefix)
+		if f, err = parser.ParseFile(fset, "", prefix+src, parser.ParseComments); err != nil {
+			return "", nil, false
+		}
+	}
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset - shift
+	}
+
+	if shift == 0 {
+		start := f.Package
+		if f.Doc != nil {
+			start = f.Doc.Pos()
+		}
+		clause = src[offset(start):offset(f.Name.End())]
+	}
+	for _, d := range f.Decls {
+		start := d.Pos()
+		switch d := d.(type) {
+		case *goast.GenDecl:
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+		case *goast.FuncDecl:
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+		}
+		decls = append(decls, src[offset(start):offset(d.End())])
+	}
+	return clause, decls, true
+}
+
+// AddGlobalCode appends the Go source code to the initializer of the
+// grammar, creating it if there is none, so that it is part of the global
+// code of the generated parser.
+func (g *Grammar) AddGlobalCode(code string) {
+	if g.Init == nil {
+		g.Init = NewCodeBlock(g.p, "{\n"+code+"\n}")
+		return
+	}
+	val := strings.TrimRight(g.Init.Val, " \t\r\n")
+	val = strings.TrimSuffix(val, "}")
+	g.Init = NewCodeBlock(g.Init.Pos(), val+"\n"+code+"\n}")
+}
+
+// SetPackage sets the name of the package of the generated parser, as
+// done by the @package directive.
+func (g *Grammar) SetPackage(name string) {
+	g.Package = NewIdentifier(g.p, name)
+}
@@ -0,0 +1,74 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestGlobalCode(t *testing.T) {
+	g := parseGrammar(t, `{
+package main
+
+import "strconv"
+
+// max is the maximum value.
+const max = 10
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+}
+
+A = "a"`)
+	want := []string{
+		"package main",
+		`import "strconv"`,
+		"// max is the maximum value.\nconst max = 10",
+		"func atoi(s string) int {\n\tn, _ := strconv.Atoi(s)\n\treturn n\n}",
+	}
+	if got := g.GlobalCode(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	g.SetPackage("calc")
+	g.GoImports = append(g.GoImports, ast.NewStringLit(ast.Pos{}, `"math/big"`))
+	g.AddGlobalCode("var zero = big.NewInt(0)")
+	want = append([]string{"package calc", `import "math/big"`}, want[1:]...)
+	want = append(want, "var zero = big.NewInt(0)")
+	if got := g.GlobalCode(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestAddGlobalCode(t *testing.T) {
+	g := parseGrammar(t, `A = "a"`)
+	if got := g.GlobalCode(); len(got) != 0 {
+		t.Errorf("want no global code, got %q", got)
+	}
+
+	g.AddGlobalCode("package main")
+	g.AddGlobalCode("var x = 1")
+	want := []string{"package main", "var x = 1"}
+	if got := g.GlobalCode(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want := "{\npackage main\n\nvar x = 1\n}"; g.Init.Val != want {
+		t.Errorf("want initializer %q, got %q", want, g.Init.Val)
+	}
+
+	// without package clause
+	g = parseGrammar(t, `A = "a"`)
+	g.AddGlobalCode("var x = 1")
+	if got, want := g.GlobalCode(), []string{"var x = 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	// invalid code
+	g.AddGlobalCode("var = ")
+	if got, want := g.GlobalCode(), []string{"var x = 1\n\nvar ="}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,157 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ToAdjacencyList returns the rule-level call graph of the grammar: each
+// key is the name of a rule and its value is the list of the names of the
+// rules it references directly, in order of first reference.
+func (g *Grammar) ToAdjacencyList() map[string][]string {
+	adj := make(map[string][]string, len(g.Rules))
+	for _, r := range g.Rules {
+		var refs []string
+		seen := make(map[string]bool)
+		Inspect(r, func(expr Expression) bool {
+			if ref, ok := expr.(*RuleRefExpr); ok && !seen[ref.Name.Val] {
+				seen[ref.Name.Val] = true
+				refs = append(refs, ref.Name.Val)
+			}
+			return true
+		})
+		adj[r.Name.Val] = refs
+	}
+	return adj
+}
+
+// Colors of the nodes in the output of ToDOT.
+const (
+	dotEntryColor     = "lightblue"
+	dotRecursiveColor = "salmon"
+	dotTerminalColor  = "lightgrey"
+)
+
+// ToDOT returns the rule-level call graph of the grammar in the DOT format
+// of Graphviz. The entry rule (the first rule of the grammar), the recursive
+// rules and the rules that reference no other rule (terminal-only rules)
+// are filled with a distinct color, in that order of precedence.
+func (g *Grammar) ToDOT() string {
+	adj := g.ToAdjacencyList()
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph grammar {\n")
+	for i, r := range g.Rules {
+		name := r.Name.Val
+		var color string
+		switch {
+		case i == 0:
+			color = dotEntryColor
+		case isRecursive(name, adj):
+			color = dotRecursiveColor
+		case len(adj[name]) == 0:
+			color = dotTerminalColor
+		}
+		if color == "" {
+			fmt.Fprintf(&buf, "\t%s;\n", strconv.Quote(name))
+			continue
+		}
+		fmt.Fprintf(&buf, "\t%s [style=filled, fillcolor=%s];\n", strconv.Quote(name), color)
+	}
+	for _, r := range g.Rules {
+		for _, ref := range adj[r.Name.Val] {
+			fmt.Fprintf(&buf, "\t%s -> %s;\n", strconv.Quote(r.Name.Val), strconv.Quote(ref))
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// isRecursive returns true if the rule name can reach itself in the call
+// graph adj.
+func isRecursive(name string, adj map[string][]string) bool {
+	seen := make(map[string]bool)
+	stack := append([]string(nil), adj[name]...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == name {
+			return true
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		stack = append(stack, adj[n]...)
+	}
+	return false
+}
+
+// SortRules sorts the rules of the grammar in place according to less,
+// which reports whether rule a must be sorted before rule b. The sort is
+// stable, and the first rule, which is the entry rule of the grammar, is
+// kept first so that sorting doesn't change the semantics of the grammar.
+func (g *Grammar) SortRules(less func(a, b *Rule) bool) {
+	if len(g.Rules) < 2 {
+		return
+	}
+	rules := g.Rules[1:]
+	sort.SliceStable(rules, func(i, j int) bool {
+		return less(rules[i], rules[j])
+	})
+	g.invalidateRuleByName()
+}
+
+// AlphaOrder is a SortRules comparator that sorts the rules by name.
+func AlphaOrder(a, b *Rule) bool {
+	return a.Name.Val < b.Name.Val
+}
+
+// DefinitionOrder is a SortRules comparator that sorts the rules in the
+// order they are defined in the source of the grammar, using their
+// position.
+func DefinitionOrder(a, b *Rule) bool {
+	pa, pb := a.Pos(), b.Pos()
+	if pa.Filename != pb.Filename {
+		return pa.Filename < pb.Filename
+	}
+	return pa.Off < pb.Off
+}
+
+// DependencyOrder returns a SortRules comparator that sorts the rules of
+// g in dependency order: a rule is sorted before the rules it references,
+// in the depth-first order of their first reference from the entry rule.
+// The rules that are not reachable from the entry rule are sorted last,
+// in their current order.
+func DependencyOrder(g *Grammar) func(a, b *Rule) bool {
+	adj := g.ToAdjacencyList()
+	rank := make(map[string]int, len(g.Rules))
+	var visit func(name string)
+	visit = func(name string) {
+		if _, ok := rank[name]; ok {
+			return
+		}
+		if _, ok := adj[name]; !ok {
+			// undefined rule
+			return
+		}
+		rank[name] = len(rank)
+		for _, ref := range adj[name] {
+			visit(ref)
+		}
+	}
+	if len(g.Rules) > 0 {
+		visit(g.Rules[0].Name.Val)
+	}
+	for _, r := range g.Rules {
+		if _, ok := rank[r.Name.Val]; !ok {
+			rank[r.Name.Val] = len(g.Rules) + len(rank)
+		}
+	}
+
+	return func(a, b *Rule) bool {
+		return rank[a.Name.Val] < rank[b.Name.Val]
+	}
+}
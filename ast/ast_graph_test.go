@@ -0,0 +1,82 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+const graphGrammar = `Start = Expr EOF
+Expr = Term ("+" Term)*
+Term = "(" Expr ")" / Num / Num
+Num = [0-9]+
+EOF = !.`
+
+func TestToAdjacencyList(t *testing.T) {
+	g := parseGrammar(t, graphGrammar)
+	want := map[string][]string{
+		"Start": {"Expr", "EOF"},
+		"Expr":  {"Term"},
+		"Term":  {"Expr", "Num"},
+		"Num":   nil,
+		"EOF":   nil,
+	}
+	if got := g.ToAdjacencyList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	g := parseGrammar(t, graphGrammar)
+	want := `digraph grammar {
+	"Start" [style=filled, fillcolor=lightblue];
+	"Expr" [style=filled, fillcolor=salmon];
+	"Term" [style=filled, fillcolor=salmon];
+	"Num" [style=filled, fillcolor=lightgrey];
+	"EOF" [style=filled, fillcolor=lightgrey];
+	"Start" -> "Expr";
+	"Start" -> "EOF";
+	"Expr" -> "Term";
+	"Term" -> "Expr";
+	"Term" -> "Num";
+}
+`
+	if got := g.ToDOT(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestSortRules(t *testing.T) {
+	src := "Start = Num Expr\nZed = 'z'\n" + graphGrammar[len("Start = Expr EOF\n"):]
+	ruleNames := func(g *ast.Grammar) []string {
+		var names []string
+		for _, r := range g.Rules {
+			names = append(names, r.Name.Val)
+		}
+		return names
+	}
+
+	cases := []struct {
+		name string
+		less func(g *ast.Grammar) func(a, b *ast.Rule) bool
+		want []string
+	}{
+		{"alpha", func(*ast.Grammar) func(a, b *ast.Rule) bool { return ast.AlphaOrder }, []string{"Start", "EOF", "Expr", "Num", "Term", "Zed"}},
+		{"dependency", ast.DependencyOrder, []string{"Start", "Num", "Expr", "Term", "Zed", "EOF"}},
+		{"definition", func(*ast.Grammar) func(a, b *ast.Rule) bool { return ast.DefinitionOrder }, []string{"Start", "Zed", "Expr", "Term", "Num", "EOF"}},
+	}
+	for _, tc := range cases {
+		g := parseGrammar(t, src)
+		g.SortRules(tc.less(g))
+		if got := ruleNames(g); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.want, got)
+		}
+
+		// sorting back in definition order restores the grammar
+		g.SortRules(ast.DefinitionOrder)
+		if got, want := ruleNames(g), ruleNames(parseGrammar(t, src)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: want %v after sorting in definition order, got %v", tc.name, want, got)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package ast
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// Hash returns a SHA-256 hash of the structure of expr and of its
+// sub-expressions. The positions are ignored, so that the same expression
+// parsed from sources that differ only by whitespace and comments outside
+// the code blocks has the same hash. The code blocks are hashed verbatim.
+func Hash(expr Expression) [32]byte {
+	h := hasher{h: sha256.New()}
+	h.expr(expr)
+
+	var sum [32]byte
+	copy(sum[:], h.h.Sum(nil))
+	return sum
+}
+
+// Fingerprint returns a SHA-256 hash of the semantic content of the
+// grammar, as computed by Hash. It can be used to detect whether the
+// grammar has changed, e.g. to decide if the parser must be generated
+// again.
+func (g *Grammar) Fingerprint() [32]byte {
+	return Hash(g)
+}
+
+// hasher writes the structure of expressions to a hash.
+type hasher struct {
+	h   hash.Hash
+	buf [binary.MaxVarintLen64]byte
+}
+
+// int writes the integer n.
+func (h *hasher) int(n int) {
+	l := binary.PutUvarint(h.buf[:], uint64(n))
+	h.h.Write(h.buf[:l])
+}
+
+// str writes the string s, prefixed with its length so that the
+// concatenation of strings is not ambiguous.
+func (h *hasher) str(s string) {
+	h.int(len(s))
+	h.h.Write([]byte(s))
+}
+
+// bool writes the boolean b.
+func (h *hasher) bool(b bool) {
+	if b {
+		h.int(1)
+		return
+	}
+	h.int(0)
+}
+
+// code writes the code block c, which may be nil.
+func (h *hasher) code(c *CodeBlock) {
+	if c == nil {
+		h.bool(false)
+		return
+	}
+	h.bool(true)
+	h.str(c.Val)
+}
+
+// exprs writes the list of expressions exprs.
+func (h *hasher) exprs(exprs []Expression) {
+	h.int(len(exprs))
+	for _, e := range exprs {
+		h.expr(e)
+	}
+}
+
+// expr writes the type and the content of expr, followed by its
+// sub-expressions.
+func (h *hasher) expr(expr Expression) {
+	if expr == nil {
+		h.str("")
+		return
+	}
+	h.str(typeName(expr))
+
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		h.code(expr.Code)
+		h.expr(expr.Expr)
+	case *AndCodeExpr:
+		h.code(expr.Code)
+	case *AndExpr:
+		h.expr(expr.Expr)
+	case *AnyMatcher:
+		// Nothing to do
+	case *BOLMatcher:
+		// Nothing to do
+	case *CharClassMatcher:
+		h.str(expr.Val)
+	case *ChoiceExpr:
+		h.bool(expr.Longest)
+		h.exprs(expr.Alternatives)
+	case *Grammar:
+		h.code(expr.Init)
+		h.int(len(expr.Rules))
+		for _, r := range expr.Rules {
+			h.expr(r)
+		}
+		// the directives are only written if there are any, so that the
+		// fingerprint of a grammar without directives is not affected.
+		if expr.Package != nil || len(expr.GoImports) > 0 {
+			h.bool(expr.Package != nil)
+			if expr.Package != nil {
+				h.str(expr.Package.Val)
+			}
+			h.int(len(expr.GoImports))
+			for _, imp := range expr.GoImports {
+				h.str(imp.Val)
+			}
+		}
+	case *LabeledExpr:
+		if expr.Label != nil {
+			h.str(expr.Label.Val)
+		} else {
+			h.str("")
+		}
+		h.expr(expr.Expr)
+	case *LitMatcher:
+		h.str(expr.Val)
+		h.bool(expr.IgnoreCase)
+	case *LookbehindExpr:
+		h.bool(expr.Negate)
+		h.expr(expr.Expr)
+	case *NotCodeExpr:
+		h.code(expr.Code)
+	case *NotExpr:
+		h.expr(expr.Expr)
+	case *OneOrMoreExpr:
+		h.expr(expr.Expr)
+	case *PatternVar:
+		h.str(expr.Name)
+	case *RecoveryExpr:
+		h.int(len(expr.Labels))
+		for _, l := range expr.Labels {
+			h.str(string(l))
+		}
+		h.expr(expr.Expr)
+		h.expr(expr.RecoverExpr)
+	case *KeywordMatcher:
+		h.int(len(expr.Keywords))
+		for _, kw := range expr.Keywords {
+			h.str(kw)
+		}
+	case *RegexpMatcher:
+		h.str(expr.Expr)
+	case *Rule:
+		h.str(expr.Name.Val)
+		if expr.DisplayName != nil {
+			h.bool(true)
+			h.str(expr.DisplayName.Val)
+		} else {
+			h.bool(false)
+		}
+		h.bool(expr.Longest)
+		// only hashed if declared, so that the hash of the other rules is
+		// unchanged
+		if expr.ResultType != "" {
+			h.str(expr.ResultType)
+		}
+		h.expr(expr.Expr)
+	case *RuleRefExpr:
+		h.str(expr.Name.Val)
+	case *SeqExpr:
+		h.exprs(expr.Exprs)
+	case *StateCodeExpr:
+		h.code(expr.Code)
+	case *ThrowExpr:
+		h.str(expr.Label)
+	case *ZeroOrMoreExpr:
+		h.expr(expr.Expr)
+	case *ZeroOrOneExpr:
+		h.expr(expr.Expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+}
@@ -0,0 +1,75 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestGrammarFingerprint(t *testing.T) {
+	src := `{ package p }
+Start = a:Expr !. { return a, nil }
+Expr = "x"i / [a-z]+ / Expr2
+Expr2 = !"y" .`
+	g := parseGrammar(t, src)
+	fp := g.Fingerprint()
+
+	same := []string{
+		src,
+		// whitespace outside code blocks is ignored
+		`{ package p }
+
+Start	=	a:Expr !. { return a, nil }
+
+Expr   =   "x"i  /  [a-z]+	/	Expr2
+Expr2 = !"y" .`,
+	}
+	for _, s := range same {
+		if got := parseGrammar(t, s).Fingerprint(); got != fp {
+			t.Errorf("want same fingerprint for:\n%s", s)
+		}
+	}
+
+	different := []string{
+		`{ package q }
+Start = a:Expr !. { return a, nil }
+Expr = "x"i / [a-z]+ / Expr2
+Expr2 = !"y" .`,
+		`{ package p }
+Start = b:Expr !. { return b, nil }
+Expr = "x"i / [a-z]+ / Expr2
+Expr2 = !"y" .`,
+		`{ package p }
+Start = a:Expr !. { return a, nil }
+Expr = "x" / [a-z]+ / Expr2
+Expr2 = !"y" .`,
+		`{ package p }
+Start = a:Expr !. { return a, nil }
+Expr = [a-z]+ / "x"i / Expr2
+Expr2 = !"y" .`,
+		`{ package p }
+Start = a:Expr !. { return a, nil }
+Expr2 = !"y" .
+Expr = "x"i / [a-z]+ / Expr2`,
+		`{ package p }
+Start = a:Expr !. { return a, nil }
+Expr "expression" = "x"i / [a-z]+ / Expr2
+Expr2 = !"y" .`,
+	}
+	for _, s := range different {
+		if got := parseGrammar(t, s).Fingerprint(); got == fp {
+			t.Errorf("want different fingerprint for:\n%s", s)
+		}
+	}
+
+	// the fingerprint is the hash of the grammar
+	if got := ast.Hash(g); got != fp {
+		t.Errorf("want Hash(g) == g.Fingerprint()")
+	}
+	// the hash of an expression does not depend on its position
+	a := ast.NewLitMatcher(ast.Pos{Line: 1}, "a")
+	b := ast.NewLitMatcher(ast.Pos{Line: 2}, "a")
+	if ast.Hash(a) != ast.Hash(b) {
+		t.Errorf("want same hash for expressions at different positions")
+	}
+}
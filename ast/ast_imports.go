@@ -0,0 +1,124 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/imports"
+)
+
+// RequiredImports returns the sorted import paths of the packages used by
+// the code blocks of the actions, predicates and state changes of the
+// grammar, as resolved by goimports. The imports of the initializer are
+// used to resolve the packages that it imports explicitly, e.g. the
+// packages that are not part of the standard library, but only the ones
+// that are actually used by the code blocks are returned.
+//
+// The code blocks are assumed to use the default receiver name "c", and
+// the code blocks that are not valid Go code are ignored.
+func (g *Grammar) RequiredImports() []string {
+	var blocks []string
+	for _, r := range g.Rules {
+		var labels, codes []string
+		seen := make(map[string]bool)
+		Inspect(r, func(expr Expression) bool {
+			var code *CodeBlock
+			switch expr := expr.(type) {
+			case *ActionExpr:
+				code = expr.Code
+			case *AndCodeExpr:
+				code = expr.Code
+			case *NotCodeExpr:
+				code = expr.Code
+			case *StateCodeExpr:
+				code = expr.Code
+			case *LabeledExpr:
+				if expr.Label != nil && expr.Label.Val != "" && !seen[expr.Label.Val] {
+					seen[expr.Label.Val] = true
+					labels = append(labels, expr.Label.Val)
+				}
+			}
+			if code != nil {
+				codes = append(codes, code.Val)
+			}
+			return true
+		})
+		// the labels of the rule are declared as arguments of the functions
+		// of its code blocks, so that goimports doesn't mistake them for
+		// packages.
+		for _, code := range codes {
+			blocks = append(blocks, funcSrc(labels, code))
+		}
+	}
+
+	head := "package p\n"
+	if g.Init != nil {
+		head = g.Init.Val[1:len(g.Init.Val)-1] + "\n"
+	}
+
+	paths := make(map[string]bool)
+	if !addImports(paths, head, blocks) {
+		// process the code blocks one at a time to ignore the invalid ones
+		for _, b := range blocks {
+			if !addImports(paths, head, []string{b}) {
+				addImports(paths, "package p\n", []string{b})
+			}
+		}
+	}
+
+	list := make([]string, 0, len(paths))
+	for p := range paths {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// funcSrc returns the source of a function with the code block as body
+// and the labels as arguments.
+func funcSrc(labels []string, code string) string {
+	var buf bytes.Buffer
+	buf.WriteString("func (c *current) _(")
+	for i, l := range labels {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(l)
+	}
+	if len(labels) > 0 {
+		buf.WriteString(" interface{}")
+	}
+	fmt.Fprintf(&buf, ") %s\n", code)
+	return buf.String()
+}
+
+// addImports adds to paths the imports of the file made of head and of the
+// functions funcs, once processed by goimports. It returns false if the
+// file is not valid Go code.
+func addImports(paths map[string]bool, head string, funcs []string) bool {
+	var buf bytes.Buffer
+	buf.WriteString(head)
+	buf.WriteString("\ntype current struct{}\n")
+	for _, f := range funcs {
+		buf.WriteString(f)
+	}
+
+	src, err := imports.Process("", buf.Bytes(), &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+	if err != nil {
+		return false
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+			paths[p] = true
+		}
+	}
+	return true
+}
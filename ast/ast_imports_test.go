@@ -0,0 +1,58 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestRequiredImports(t *testing.T) {
+	g := parseGrammar(t, `{
+package p
+
+import (
+	"os"
+
+	"example.com/custom"
+	str "example.com/strutil"
+)
+}
+
+A = n:Number s:Sign { return fmt.Sprint(n, s), nil }
+Number = [0-9]+ { return custom.Parse(c.text) }
+Sign = "+" / "-"
+`)
+	// the bootstrap parser doesn't support the code predicates and the
+	// state code blocks
+	and := ast.NewAndCodeExpr(ast.Pos{})
+	and.Code = ast.NewCodeBlock(ast.Pos{}, `{ _, err := strconv.Atoi(string(c.text)); return err == nil, nil }`)
+	not := ast.NewNotCodeExpr(ast.Pos{})
+	not.Code = ast.NewCodeBlock(ast.Pos{}, `{ return n.Neg, nil }`)
+	seq := g.Rules[0].Expr.(*ast.ActionExpr).Expr.(*ast.SeqExpr)
+	seq.Exprs = append(seq.Exprs, and, not)
+
+	state := ast.NewStateCodeExpr(ast.Pos{})
+	state.Code = ast.NewCodeBlock(ast.Pos{}, `{ c.state["s"] = str.Upper("+"); return nil }`)
+	seq = ast.NewSeqExpr(ast.Pos{})
+	seq.Exprs = []ast.Expression{g.Rules[2].Expr, state}
+	g.Rules[2].Expr = seq
+
+	want := []string{"example.com/custom", "example.com/strutil", "fmt", "strconv"}
+	if got := g.RequiredImports(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// the invalid code blocks are ignored
+	g = parseGrammar(t, `A = "a" { return fmt.Sprint(c.text), nil } / "b" { return x y z }`)
+	want = []string{"fmt"}
+	if got := g.RequiredImports(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// labels are not packages
+	g = parseGrammar(t, `A = x:"a" { return x.Val, nil }`)
+	if got := g.RequiredImports(); len(got) != 0 {
+		t.Errorf("want no import, got %v", got)
+	}
+}
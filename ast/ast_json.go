@@ -0,0 +1,619 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSONMarshaler encodes an AST to JSON. Each node is encoded as an object
+// with a "type" field that holds the name of the node's type (e.g.
+// "SeqExpr") and a field for each of the node's values and children.
+// The information computed by the optimizer and the builder (e.g. the Opt
+// flags) is not encoded.
+//
+// The MarshalJSON method of the nodes is equivalent to a JSONMarshaler
+// with Pos set to true.
+type JSONMarshaler struct {
+	// Pos indicates if the source positions are encoded.
+	Pos bool
+}
+
+// Marshal returns the JSON encoding of the AST rooted at expr.
+func (m JSONMarshaler) Marshal(expr Expression) ([]byte, error) {
+	n, err := m.node(expr)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(n)
+}
+
+// UnmarshalExpression decodes the JSON encoding of an AST, as returned by
+// JSONMarshaler, and returns its root node. Positions that are not part of
+// the encoding are set to the zero value.
+func UnmarshalExpression(b []byte) (Expression, error) {
+	var n jsonNode
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	return n.expr()
+}
+
+// jsonNode is the JSON encoding of any node of the AST.
+type jsonNode struct {
+	Type        string      `json:"type"`
+	Pos         *jsonPos    `json:"pos,omitempty"`
+	Name        *jsonValue  `json:"name,omitempty"`
+	DisplayName *jsonValue  `json:"displayName,omitempty"`
+	Label       *jsonValue  `json:"label,omitempty"`
+	Val         string      `json:"val,omitempty"`
+	IgnoreCase  bool        `json:"ignoreCase,omitempty"`
+	Longest     bool        `json:"longest,omitempty"`
+	Negate      bool        `json:"negate,omitempty"`
+	Code        *jsonValue  `json:"code,omitempty"`
+	ReturnType  string      `json:"returnType,omitempty"`
+	ResultType  string      `json:"resultType,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Keywords    []string    `json:"keywords,omitempty"`
+	Expr        *jsonNode   `json:"expr,omitempty"`
+	RecoverExpr *jsonNode   `json:"recoverExpr,omitempty"`
+	Exprs       []*jsonNode `json:"exprs,omitempty"`
+	Rules       []*jsonNode `json:"rules,omitempty"`
+	Package     *jsonValue  `json:"package,omitempty"`
+	GoImports   []jsonValue `json:"goImports,omitempty"`
+}
+
+// jsonValue is the JSON encoding of the identifiers, string literals and
+// code blocks.
+type jsonValue struct {
+	Val string   `json:"val"`
+	Pos *jsonPos `json:"pos,omitempty"`
+}
+
+type jsonPos struct {
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Off      int    `json:"off"`
+}
+
+func (m JSONMarshaler) pos(p Pos) *jsonPos {
+	if !m.Pos {
+		return nil
+	}
+	return &jsonPos{Filename: p.Filename, Line: p.Line, Col: p.Col, Off: p.Off}
+}
+
+func (p *jsonPos) pos() Pos {
+	if p == nil {
+		return Pos{}
+	}
+	return Pos{Filename: p.Filename, Line: p.Line, Col: p.Col, Off: p.Off}
+}
+
+func (m JSONMarshaler) value(p Pos, val string) *jsonValue {
+	return &jsonValue{Val: val, Pos: m.pos(p)}
+}
+
+func (m JSONMarshaler) node(expr Expression) (*jsonNode, error) {
+	if expr == nil || reflect.ValueOf(expr).IsNil() {
+		return nil, nil
+	}
+
+	n := &jsonNode{Type: typeName(expr), Pos: m.pos(expr.Pos())}
+	var err error
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		if expr.Code != nil {
+			n.Code = m.value(expr.Code.Pos(), expr.Code.Val)
+		}
+		n.ReturnType = expr.ReturnType
+		n.Expr, err = m.node(expr.Expr)
+	case *AndCodeExpr:
+		if expr.Code != nil {
+			n.Code = m.value(expr.Code.Pos(), expr.Code.Val)
+		}
+	case *AndExpr:
+		n.Expr, err = m.node(expr.Expr)
+	case *AnyMatcher:
+		n.Val = expr.Val
+	case *BOLMatcher:
+		n.Val = expr.Val
+	case *CharClassMatcher:
+		n.Val = expr.Val
+	case *ChoiceExpr:
+		n.Longest = expr.Longest
+		n.Exprs, err = m.nodes(expr.Alternatives)
+	case *Grammar:
+		if expr.Init != nil {
+			n.Code = m.value(expr.Init.Pos(), expr.Init.Val)
+		}
+		if expr.Package != nil {
+			n.Package = m.value(expr.Package.Pos(), expr.Package.Val)
+		}
+		for _, imp := range expr.GoImports {
+			n.GoImports = append(n.GoImports, *m.value(imp.Pos(), imp.Val))
+		}
+		for _, r := range expr.Rules {
+			var rn *jsonNode
+			if rn, err = m.node(r); err != nil {
+				break
+			}
+			n.Rules = append(n.Rules, rn)
+		}
+	case *LabeledExpr:
+		if expr.Label != nil {
+			n.Label = m.value(expr.Label.Pos(), expr.Label.Val)
+		}
+		n.Expr, err = m.node(expr.Expr)
+	case *LitMatcher:
+		n.Val = expr.Val
+		n.IgnoreCase = expr.IgnoreCase
+	case *LookbehindExpr:
+		n.Negate = expr.Negate
+		n.Expr, err = m.node(expr.Expr)
+	case *NotCodeExpr:
+		if expr.Code != nil {
+			n.Code = m.value(expr.Code.Pos(), expr.Code.Val)
+		}
+	case *NotExpr:
+		n.Expr, err = m.node(expr.Expr)
+	case *OneOrMoreExpr:
+		n.Expr, err = m.node(expr.Expr)
+	case *RecoveryExpr:
+		for _, l := range expr.Labels {
+			n.Labels = append(n.Labels, string(l))
+		}
+		if n.Expr, err = m.node(expr.Expr); err == nil {
+			n.RecoverExpr, err = m.node(expr.RecoverExpr)
+		}
+	case *KeywordMatcher:
+		n.Keywords = expr.Keywords
+	case *RegexpMatcher:
+		n.Val = expr.Expr
+	case *Rule:
+		if expr.Name != nil {
+			n.Name = m.value(expr.Name.Pos(), expr.Name.Val)
+		}
+		if expr.DisplayName != nil {
+			n.DisplayName = m.value(expr.DisplayName.Pos(), expr.DisplayName.Val)
+		}
+		n.Longest = expr.Longest
+		n.ResultType = expr.ResultType
+		n.Expr, err = m.node(expr.Expr)
+	case *RuleRefExpr:
+		if expr.Name != nil {
+			n.Name = m.value(expr.Name.Pos(), expr.Name.Val)
+		}
+	case *SeqExpr:
+		n.Exprs, err = m.nodes(expr.Exprs)
+	case *StateCodeExpr:
+		if expr.Code != nil {
+			n.Code = m.value(expr.Code.Pos(), expr.Code.Val)
+		}
+	case *ThrowExpr:
+		n.Label = &jsonValue{Val: expr.Label}
+	case *ZeroOrMoreExpr:
+		n.Expr, err = m.node(expr.Expr)
+	case *ZeroOrOneExpr:
+		n.Expr, err = m.node(expr.Expr)
+	default:
+		return nil, fmt.Errorf("unknown expression type %T", expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (m JSONMarshaler) nodes(exprs []Expression) ([]*jsonNode, error) {
+	ns := make([]*jsonNode, 0, len(exprs))
+	for _, e := range exprs {
+		n, err := m.node(e)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+func (v *jsonValue) identifier() *Identifier {
+	if v == nil {
+		return nil
+	}
+	return NewIdentifier(v.Pos.pos(), v.Val)
+}
+
+func (v *jsonValue) codeBlock() *CodeBlock {
+	if v == nil {
+		return nil
+	}
+	return NewCodeBlock(v.Pos.pos(), v.Val)
+}
+
+func (n *jsonNode) expr() (Expression, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	p := n.Pos.pos()
+	var err error
+	switch n.Type {
+	case "ActionExpr":
+		e := NewActionExpr(p)
+		e.Code = n.Code.codeBlock()
+		e.ReturnType = n.ReturnType
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "AndCodeExpr":
+		e := NewAndCodeExpr(p)
+		e.Code = n.Code.codeBlock()
+		return e, nil
+	case "AndExpr":
+		e := NewAndExpr(p)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "AnyMatcher":
+		return NewAnyMatcher(p, n.Val), nil
+	case "BOLMatcher":
+		return NewBOLMatcher(p, n.Val), nil
+	case "CharClassMatcher":
+		return NewCharClassMatcher(p, n.Val), nil
+	case "ChoiceExpr":
+		e := NewChoiceExpr(p)
+		e.Longest = n.Longest
+		e.Alternatives, err = exprs(n.Exprs)
+		return e, err
+	case "Grammar":
+		e := NewGrammar(p)
+		e.Init = n.Code.codeBlock()
+		e.Package = n.Package.identifier()
+		for _, v := range n.GoImports {
+			e.GoImports = append(e.GoImports, NewStringLit(v.Pos.pos(), v.Val))
+		}
+		for _, rn := range n.Rules {
+			r, err := rn.expr()
+			if err != nil {
+				return nil, err
+			}
+			rule, ok := r.(*Rule)
+			if !ok {
+				return nil, fmt.Errorf("invalid rule type %T", r)
+			}
+			e.Rules = append(e.Rules, rule)
+		}
+		return e, nil
+	case "LabeledExpr":
+		e := NewLabeledExpr(p)
+		e.Label = n.Label.identifier()
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "LitMatcher":
+		e := NewLitMatcher(p, n.Val)
+		e.IgnoreCase = n.IgnoreCase
+		return e, nil
+	case "LookbehindExpr":
+		e := NewLookbehindExpr(p, n.Negate)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "NotCodeExpr":
+		e := NewNotCodeExpr(p)
+		e.Code = n.Code.codeBlock()
+		return e, nil
+	case "NotExpr":
+		e := NewNotExpr(p)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "OneOrMoreExpr":
+		e := NewOneOrMoreExpr(p)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "RecoveryExpr":
+		e := NewRecoveryExpr(p)
+		for _, l := range n.Labels {
+			e.Labels = append(e.Labels, FailureLabel(l))
+		}
+		if e.Expr, err = n.Expr.expr(); err != nil {
+			return nil, err
+		}
+		e.RecoverExpr, err = n.RecoverExpr.expr()
+		return e, err
+	case "KeywordMatcher":
+		return NewKeywordMatcher(p, n.Keywords), nil
+	case "RegexpMatcher":
+		return NewRegexpMatcher(p, n.Val), nil
+	case "Rule":
+		e := NewRule(p, n.Name.identifier())
+		if n.DisplayName != nil {
+			e.DisplayName = NewStringLit(n.DisplayName.Pos.pos(), n.DisplayName.Val)
+		}
+		e.Longest = n.Longest
+		e.ResultType = n.ResultType
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "RuleRefExpr":
+		e := NewRuleRefExpr(p)
+		e.Name = n.Name.identifier()
+		return e, nil
+	case "SeqExpr":
+		e := NewSeqExpr(p)
+		e.Exprs, err = exprs(n.Exprs)
+		return e, err
+	case "StateCodeExpr":
+		e := NewStateCodeExpr(p)
+		e.Code = n.Code.codeBlock()
+		return e, nil
+	case "ThrowExpr":
+		e := NewThrowExpr(p)
+		if n.Label != nil {
+			e.Label = n.Label.Val
+		}
+		return e, nil
+	case "ZeroOrMoreExpr":
+		e := NewZeroOrMoreExpr(p)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	case "ZeroOrOneExpr":
+		e := NewZeroOrOneExpr(p)
+		e.Expr, err = n.Expr.expr()
+		return e, err
+	}
+	return nil, fmt.Errorf("unknown expression type %q", n.Type)
+}
+
+func exprs(ns []*jsonNode) ([]Expression, error) {
+	es := make([]Expression, 0, len(ns))
+	for _, n := range ns {
+		e, err := n.expr()
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
+
+// unmarshalJSONInto decodes b into dst, which must be a pointer to a node
+// of the same type as the encoded one.
+func unmarshalJSONInto(b []byte, dst Expression) error {
+	e, err := UnmarshalExpression(b)
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(e) != reflect.TypeOf(dst) {
+		return fmt.Errorf("cannot unmarshal %s into %T", typeName(e), dst)
+	}
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(e).Elem())
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (a *ActionExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(a)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (a *ActionExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, a)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (a *AndCodeExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(a)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (a *AndCodeExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, a)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (a *AndExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(a)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (a *AndExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, a)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (a *AnyMatcher) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(a)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (a *AnyMatcher) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, a)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (b *BOLMatcher) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(b)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (b *BOLMatcher) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto(data, b)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (c *CharClassMatcher) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(c)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (c *CharClassMatcher) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, c)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (c *ChoiceExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(c)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (c *ChoiceExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, c)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (g *Grammar) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(g)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (g *Grammar) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, g)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (k *KeywordMatcher) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(k)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (k *KeywordMatcher) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, k)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (l *LabeledExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(l)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (l *LabeledExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, l)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (l *LitMatcher) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(l)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (l *LitMatcher) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, l)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (l *LookbehindExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(l)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (l *LookbehindExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, l)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (n *NotCodeExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(n)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (n *NotCodeExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, n)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (n *NotExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(n)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (n *NotExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, n)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (o *OneOrMoreExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (o *OneOrMoreExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, o)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (r *RecoveryExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(r)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (r *RecoveryExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, r)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (r *Rule) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(r)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (r *Rule) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, r)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (r *RuleRefExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(r)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (r *RuleRefExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, r)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (s *SeqExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (s *SeqExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, s)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (s *StateCodeExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (s *StateCodeExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, s)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (t *ThrowExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(t)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (t *ThrowExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, t)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (z *ZeroOrMoreExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(z)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (z *ZeroOrMoreExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, z)
+}
+
+// MarshalJSON implements json.Marshaler, see JSONMarshaler.
+func (z *ZeroOrOneExpr) MarshalJSON() ([]byte, error) {
+	return JSONMarshaler{Pos: true}.Marshal(z)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see UnmarshalExpression.
+func (z *ZeroOrOneExpr) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONInto(b, z)
+}
@@ -0,0 +1,106 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/bootstrap"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	f, err := os.Open("../grammar/bootstrap.peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := bootstrap.NewParser().Parse("bootstrap.peg", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ast.Grammar
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, &got) {
+		t.Errorf("want round-tripped grammar to be equal")
+	}
+
+	// without positions, the encoding is stable
+	m := ast.JSONMarshaler{}
+	b, err = m.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), `"pos"`) {
+		t.Errorf("want no position in encoding")
+	}
+	e, err := ast.UnmarshalExpression(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := m.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(b2) {
+		t.Errorf("want same encoding, got\n%s\n%s", b, b2)
+	}
+}
+
+func TestJSONRecoveryExpr(t *testing.T) {
+	throw := ast.NewThrowExpr(ast.Pos{Line: 1, Col: 1})
+	throw.Label = "err"
+	rec := ast.NewRecoveryExpr(ast.Pos{Line: 1, Col: 5})
+	rec.Expr = throw
+	rec.RecoverExpr = ast.NewAnyMatcher(ast.Pos{Line: 1, Col: 15}, ".")
+	rec.Labels = []ast.FailureLabel{"err", "other"}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ast.RecoveryExpr
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rec, &got) {
+		t.Errorf("want %v, got %v", rec, &got)
+	}
+
+	var lit ast.LitMatcher
+	if err := json.Unmarshal(b, &lit); err == nil {
+		t.Errorf("want error when unmarshaling RecoveryExpr into LitMatcher")
+	}
+	if _, err := ast.UnmarshalExpression([]byte(`{"type": "FooExpr"}`)); err == nil {
+		t.Errorf("want error for unknown type")
+	}
+}
+
+func TestJSONBOLMatcher(t *testing.T) {
+	seq := ast.NewSeqExpr(ast.Pos{Line: 1, Col: 1})
+	seq.Exprs = []ast.Expression{
+		ast.NewBOLMatcher(ast.Pos{Line: 1, Col: 1}, "^"),
+		ast.NewLitMatcher(ast.Pos{Line: 1, Col: 3}, "#"),
+	}
+
+	b, err := json.Marshal(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ast.SeqExpr
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seq, &got) {
+		t.Errorf("want %v, got %v", seq, &got)
+	}
+}
@@ -0,0 +1,442 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The severities of the lint issues.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// LintIssue is an issue reported by a LintRule.
+type LintIssue struct {
+	// Severity is the severity of the issue, one of LintError, LintWarning
+	// or LintInfo.
+	Severity string
+	// Message describes the issue.
+	Message string
+	// Node is the expression that causes the issue.
+	Node Expression
+}
+
+// String returns the textual representation of the issue, prefixed with
+// the position of its node.
+func (li LintIssue) String() string {
+	if li.Node == nil {
+		return fmt.Sprintf("%s: %s", li.Severity, li.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", li.Node.Pos(), li.Severity, li.Message)
+}
+
+// LintRule is a check of a grammar that reports the issues it finds.
+type LintRule interface {
+	Check(g *Grammar) []LintIssue
+}
+
+// DefaultLintRules returns the built-in lint rules, with their default
+// configuration.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		DuplicateRules{},
+		UndefinedRules{},
+		UnusedRules{},
+		DuplicateLabels{},
+		LeftRecursion{},
+		NullableRepetition{},
+		UnreachableAlternatives{},
+		DeepNesting{},
+		PredicateOnAny{},
+	}
+}
+
+// Lint runs the lint rules on the grammar and returns the issues they
+// report, sorted by position of their node, then by decreasing severity
+// and by message. The identical issues reported by more than one rule are
+// returned only once.
+func Lint(g *Grammar, rules ...LintRule) []LintIssue {
+	var issues []LintIssue
+	for _, r := range rules {
+		issues = append(issues, r.Check(g)...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issuePos(issues[i]), issuePos(issues[j])
+		if pi != pj {
+			if pi.Filename != pj.Filename {
+				return pi.Filename < pj.Filename
+			}
+			return pi.Off < pj.Off
+		}
+		if si, sj := severityRanks[issues[i].Severity], severityRanks[issues[j].Severity]; si != sj {
+			return si < sj
+		}
+		return issues[i].Message < issues[j].Message
+	})
+
+	uniq := issues[:0]
+	for i, li := range issues {
+		if i > 0 && li.String() == issues[i-1].String() {
+			continue
+		}
+		uniq = append(uniq, li)
+	}
+	return uniq
+}
+
+// severityRanks is the sort order of the severities, the most severe first.
+var severityRanks = map[string]int{LintError: 0, LintWarning: 1, LintInfo: 2}
+
+func issuePos(li LintIssue) Pos {
+	if li.Node == nil {
+		return Pos{}
+	}
+	return li.Node.Pos()
+}
+
+// DuplicateRules is a LintRule that reports an error for each rule that
+// has the same name as a previous rule.
+type DuplicateRules struct{}
+
+// Check implements LintRule.
+func (DuplicateRules) Check(g *Grammar) []LintIssue {
+	var issues []LintIssue
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		if first, ok := rules[r.Name.Val]; ok {
+			issues = append(issues, LintIssue{
+				Severity: LintError,
+				Message:  fmt.Sprintf("duplicate rule: %s, first defined at %s", r.Name.Val, first.Pos()),
+				Node:     r,
+			})
+			continue
+		}
+		rules[r.Name.Val] = r
+	}
+	return issues
+}
+
+// UndefinedRules is a LintRule that reports an error for each reference to
+// a rule that is not defined in the grammar.
+type UndefinedRules struct{}
+
+// Check implements LintRule.
+func (UndefinedRules) Check(g *Grammar) []LintIssue {
+	rules := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = true
+	}
+
+	var issues []LintIssue
+	Inspect(g, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok && !rules[ref.Name.Val] {
+			issues = append(issues, LintIssue{
+				Severity: LintError,
+				Message:  fmt.Sprintf("undefined rule: %s", ref.Name.Val),
+				Node:     ref,
+			})
+		}
+		return true
+	})
+	return issues
+}
+
+// UnusedRules is a LintRule that reports a warning for each rule that
+// cannot be reached from the start rule, the first rule of the grammar.
+// Note that the rules used as alternate entrypoints are reported too.
+type UnusedRules struct{}
+
+// Check implements LintRule.
+func (UnusedRules) Check(g *Grammar) []LintIssue {
+	if len(g.Rules) == 0 {
+		return nil
+	}
+	adj := g.ToAdjacencyList()
+	start := g.Rules[0].Name.Val
+	reached := map[string]bool{start: true}
+	for stack := []string{start}; len(stack) > 0; {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, ref := range adj[name] {
+			if !reached[ref] {
+				reached[ref] = true
+				stack = append(stack, ref)
+			}
+		}
+	}
+
+	var issues []LintIssue
+	for _, r := range g.Rules {
+		if !reached[r.Name.Val] {
+			issues = append(issues, LintIssue{
+				Severity: LintWarning,
+				Message:  fmt.Sprintf("unused rule: %s is not reachable from the start rule %s", r.Name.Val, start),
+				Node:     r,
+			})
+		}
+	}
+	return issues
+}
+
+// DuplicateLabels is a LintRule that reports an error for each label that
+// has the same name as a previous label in the scope of the same
+// ActionExpr, as CheckDuplicateLabels does.
+type DuplicateLabels struct{}
+
+// Check implements LintRule.
+func (DuplicateLabels) Check(g *Grammar) []LintIssue {
+	var issues []LintIssue
+	Inspect(g, func(expr Expression) bool {
+		if act, ok := expr.(*ActionExpr); ok {
+			checkLabels(act.Expr, make(map[string]*LabeledExpr), func(dup, first *LabeledExpr) {
+				issues = append(issues, LintIssue{
+					Severity: LintError,
+					Message:  fmt.Sprintf("duplicate label: %s, first defined at %s", dup.Label.Val, first.Pos()),
+					Node:     dup,
+				})
+			})
+		}
+		return true
+	})
+	return issues
+}
+
+// LeftRecursion is a LintRule that reports an error for each rule that may
+// (directly or indirectly) invoke itself without consuming any input,
+// which makes the generated parser recurse until the stack overflows.
+type LeftRecursion struct{}
+
+// Check implements LintRule.
+func (LeftRecursion) Check(g *Grammar) []LintIssue {
+	names := make(map[string]bool)
+	for _, nm := range g.Stats().LeftRecursiveRules {
+		names[nm] = true
+	}
+
+	var issues []LintIssue
+	for _, r := range g.Rules {
+		if names[r.Name.Val] {
+			issues = append(issues, LintIssue{
+				Severity: LintError,
+				Message:  fmt.Sprintf("left recursive rule: %s may invoke itself without consuming any input", r.Name.Val),
+				Node:     r,
+			})
+		}
+	}
+	return issues
+}
+
+// NullableRepetition is a LintRule that reports an error for each
+// repetition (ZeroOrMoreExpr or OneOrMoreExpr) of an expression that may
+// succeed without consuming any input, e.g. `("a"?)*`, which makes the
+// generated parser loop forever.
+type NullableRepetition struct{}
+
+// Check implements LintRule.
+func (NullableRepetition) Check(g *Grammar) []LintIssue {
+	a := newGrammarAnalyzer(g)
+
+	var issues []LintIssue
+	Inspect(g, func(expr Expression) bool {
+		var sub Expression
+		switch expr := expr.(type) {
+		case *ZeroOrMoreExpr:
+			sub = expr.Expr
+		case *OneOrMoreExpr:
+			sub = expr.Expr
+		default:
+			return true
+		}
+		if a.isNullable(sub) {
+			issues = append(issues, LintIssue{
+				Severity: LintError,
+				Message:  "repetition of an expression that may match the empty input never ends",
+				Node:     expr,
+			})
+		}
+		return true
+	})
+	return issues
+}
+
+// UnreachableAlternatives is a LintRule that reports a warning for each
+// alternative of a choice expression that can never be tried, or never
+// match, because of a previous alternative. That is the case of the
+// alternatives that follow an alternative that never fails (e.g. `"a"*`),
+// and of the alternatives that start with a literal of which a previous
+// alternative made of a single literal is a prefix (e.g. the second
+// alternative of `"a" / "ab"`).
+type UnreachableAlternatives struct{}
+
+// Check implements LintRule.
+func (UnreachableAlternatives) Check(g *Grammar) []LintIssue {
+	a := newGrammarAnalyzer(g)
+
+	var issues []LintIssue
+	Inspect(g, func(expr Expression) bool {
+		ch, ok := expr.(*ChoiceExpr)
+		if !ok {
+			return true
+		}
+		for i, alt := range ch.Alternatives {
+			if a.neverFails(alt, make(map[string]bool)) {
+				for _, next := range ch.Alternatives[i+1:] {
+					issues = append(issues, LintIssue{
+						Severity: LintWarning,
+						Message:  fmt.Sprintf("unreachable alternative: the alternative at %s never fails", alt.Pos()),
+						Node:     next,
+					})
+				}
+				break
+			}
+
+			lit, ok := alt.(*LitMatcher)
+			if !ok || lit.IgnoreCase || lit.Val == "" {
+				continue
+			}
+			for _, next := range ch.Alternatives[i+1:] {
+				if nlit := leadingLit(next); nlit != nil && !nlit.IgnoreCase && strings.HasPrefix(nlit.Val, lit.Val) {
+					issues = append(issues, LintIssue{
+						Severity: LintWarning,
+						Message:  fmt.Sprintf("unreachable alternative: the literal %q at %s matches first", lit.Val, lit.Pos()),
+						Node:     next,
+					})
+				}
+			}
+		}
+		return true
+	})
+	return issues
+}
+
+// neverFails returns true if expr always succeeds, whatever the input.
+func (a *grammarAnalyzer) neverFails(expr Expression, visiting map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return a.neverFails(expr.Expr, visiting)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if a.neverFails(alt, visiting) {
+				return true
+			}
+		}
+	case *LabeledExpr:
+		return a.neverFails(expr.Expr, visiting)
+	case *LitMatcher:
+		return expr.Val == ""
+	case *RuleRefExpr:
+		r, ok := a.rules[expr.Name.Val]
+		if !ok || visiting[expr.Name.Val] {
+			return false
+		}
+		visiting[expr.Name.Val] = true
+		defer delete(visiting, expr.Name.Val)
+		return a.neverFails(r.Expr, visiting)
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			if !a.neverFails(e, visiting) {
+				return false
+			}
+		}
+		return true
+	case *ZeroOrMoreExpr, *ZeroOrOneExpr:
+		return true
+	}
+	return false
+}
+
+// leadingLit returns the literal that expr must start with, if any.
+func leadingLit(expr Expression) *LitMatcher {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		return leadingLit(expr.Expr)
+	case *LabeledExpr:
+		return leadingLit(expr.Expr)
+	case *LitMatcher:
+		return expr
+	case *OneOrMoreExpr:
+		return leadingLit(expr.Expr)
+	case *SeqExpr:
+		if len(expr.Exprs) > 0 {
+			return leadingLit(expr.Exprs[0])
+		}
+	}
+	return nil
+}
+
+// DefaultMaxNesting is the maximum nesting depth used by DeepNesting if
+// its MaxDepth is not set.
+const DefaultMaxNesting = 12
+
+// DeepNesting is a LintRule that reports a warning for each rule whose
+// expression is nested deeper than MaxDepth, which makes the rule hard to
+// read and is usually better split in several rules. The depth of the
+// expression of a rule is 1 if it has no sub-expression. If MaxDepth is 0,
+// DefaultMaxNesting is used.
+type DeepNesting struct {
+	MaxDepth int
+}
+
+// Check implements LintRule.
+func (d DeepNesting) Check(g *Grammar) []LintIssue {
+	max := d.MaxDepth
+	if max <= 0 {
+		max = DefaultMaxNesting
+	}
+
+	var issues []LintIssue
+	for _, r := range g.Rules {
+		if r.Expr == nil {
+			continue
+		}
+		if depth := NewExpressionStats(r.Expr).MaxDepth; depth > max {
+			issues = append(issues, LintIssue{
+				Severity: LintWarning,
+				Message:  fmt.Sprintf("rule %s: nesting depth %d exceeds %d", r.Name.Val, depth, max),
+				Node:     r,
+			})
+		}
+	}
+	return issues
+}
+
+// PredicateOnAny is a LintRule that reports an informational issue for
+// each AndExpr or NotExpr applied to an AnyMatcher, which only tests for
+// the end of the input and is clearer as a named rule (e.g. `EOF = !.`).
+// The expression of a rule that is only such a predicate is not reported.
+type PredicateOnAny struct{}
+
+// Check implements LintRule.
+func (PredicateOnAny) Check(g *Grammar) []LintIssue {
+	named := make(map[Expression]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		named[r.Expr] = true
+	}
+
+	var issues []LintIssue
+	Inspect(g, func(expr Expression) bool {
+		var msg string
+		switch expr := expr.(type) {
+		case *AndExpr:
+			if _, ok := expr.Expr.(*AnyMatcher); ok {
+				msg = "&. only tests that the input is not at its end"
+			}
+		case *NotExpr:
+			if _, ok := expr.Expr.(*AnyMatcher); ok {
+				msg = "!. only tests that the input is at its end"
+			}
+		}
+		if msg != "" && !named[expr] {
+			issues = append(issues, LintIssue{
+				Severity: LintInfo,
+				Message:  msg + ", consider a named rule",
+				Node:     expr,
+			})
+		}
+		return true
+	})
+	return issues
+}
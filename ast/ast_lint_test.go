@@ -0,0 +1,84 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestLint(t *testing.T) {
+	g := parseGrammar(t, `
+Start = A B C D !.
+A = "a" / "ab" / x:"abc" "d" / "b"
+B = "x"* / "y"
+C = &. "c" / "c"
+D = "d"
+D = !((&"e"+)?)*
+`)
+	issues := ast.Lint(g, ast.DefaultLintRules()...)
+
+	want := []string{
+		`2:17 (17): info: !. only tests that the input is at its end, consider a named rule`,
+		`3:11 (30): warning: unreachable alternative: the literal "a" at 3:5 (24) matches first`,
+		`3:18 (37): warning: unreachable alternative: the literal "a" at 3:5 (24) matches first`,
+		`3:18 (37): warning: unreachable alternative: the literal "ab" at 3:11 (30) matches first`,
+		`4:12 (66): warning: unreachable alternative: the alternative at 4:5 (59) never fails`,
+		`5:5 (74): info: &. only tests that the input is not at its end, consider a named rule`,
+		`7:1 (95): error: duplicate rule: D, first defined at 6:1 (87)`,
+		`7:8 (102): error: repetition of an expression that may match the empty input never ends`,
+	}
+	var got []string
+	for _, li := range issues {
+		got = append(got, li.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want issues:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// the expression of the last D rule has a depth of 6
+	if issues := ast.Lint(g, ast.DeepNesting{MaxDepth: 5}); len(issues) != 1 || issues[0].Node != g.Rules[5] {
+		t.Errorf("want a single deep nesting issue for the last rule, got %v", issues)
+	}
+	if issues := ast.Lint(g, ast.DeepNesting{MaxDepth: 6}); len(issues) != 0 {
+		t.Errorf("want no deep nesting issue, got %v", issues)
+	}
+
+	// a rule that is only a predicate on any is not reported
+	g = parseGrammar(t, `
+Start = "a" EOF
+EOF = !.
+`)
+	if issues := ast.Lint(g, ast.PredicateOnAny{}); len(issues) != 0 {
+		t.Errorf("want no issue, got %v", issues)
+	}
+}
+
+func TestLintGrammarErrors(t *testing.T) {
+	g := parseGrammar(t, `
+Start = A x:"a" x:B { return nil, nil }
+A = A "a" / "b"
+B = C
+D = "d"
+`)
+	issues := ast.Lint(g, ast.DefaultLintRules()...)
+
+	want := []string{
+		`2:17 (17): error: duplicate label: x, first defined at 2:11 (11)`,
+		`3:1 (41): error: left recursive rule: A may invoke itself without consuming any input`,
+		`4:5 (61): error: undefined rule: C`,
+		`5:1 (63): warning: unused rule: D is not reachable from the start rule Start`,
+	}
+	var got []string
+	for _, li := range issues {
+		got = append(got, li.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want issues:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// the identical issues reported by more than one rule are deduplicated
+	if issues := ast.Lint(g, ast.UndefinedRules{}, ast.UndefinedRules{}); len(issues) != 1 {
+		t.Errorf("want 1 issue, got %v", issues)
+	}
+}
@@ -0,0 +1,90 @@
+package ast
+
+// FlattenSeq returns a sequence equivalent to expr, where the sequences
+// that are direct children of expr are recursively replaced by their
+// expressions, e.g. ("a" ("b" "c")) "d" becomes "a" "b" "c" "d". The
+// expressions are shared with expr, which is not modified. Note that the
+// value of the flattened sequence is a flat slice of the values of the
+// expressions, not a slice of nested slices.
+func FlattenSeq(expr *SeqExpr) *SeqExpr {
+	seq := NewSeqExpr(expr.p)
+	seq.Exprs = appendFlatSeq(nil, expr)
+	return seq
+}
+
+func appendFlatSeq(exprs []Expression, seq *SeqExpr) []Expression {
+	for _, e := range seq.Exprs {
+		if s, ok := e.(*SeqExpr); ok {
+			exprs = appendFlatSeq(exprs, s)
+			continue
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs
+}
+
+// FlattenChoice returns a choice equivalent to expr, where the choices
+// that are direct children of expr are recursively replaced by their
+// alternatives, e.g. "a" / ("b" / "c") becomes "a" / "b" / "c". The
+// choices in a different longest-match mode than expr are kept as is. The
+// alternatives are shared with expr, which is not modified.
+func FlattenChoice(expr *ChoiceExpr) *ChoiceExpr {
+	ch := NewChoiceExpr(expr.p)
+	ch.Longest = expr.Longest
+	ch.Alternatives = appendFlatChoice(nil, expr)
+	return ch
+}
+
+func appendFlatChoice(alts []Expression, ch *ChoiceExpr) []Expression {
+	for _, e := range ch.Alternatives {
+		if c, ok := e.(*ChoiceExpr); ok && c.Longest == ch.Longest {
+			alts = appendFlatChoice(alts, c)
+			continue
+		}
+		alts = append(alts, e)
+	}
+	return alts
+}
+
+// Normalize rewrites the grammar g in a normal form that is easier to
+// analyze, where the nested sequences and choices are flattened by
+// FlattenSeq and FlattenChoice.
+func Normalize(g *Grammar) {
+	WalkReplacing(normalizer{}, g, 1)
+}
+
+// normalizer is the Visitor of Normalize.
+type normalizer struct{}
+
+func (v normalizer) Visit(expr Expression, br Backref) Visitor {
+	switch expr := expr.(type) {
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			if _, ok := e.(*SeqExpr); ok {
+				br.Replace(FlattenSeq(expr))
+				break
+			}
+		}
+	case *ChoiceExpr:
+		for _, e := range expr.Alternatives {
+			if c, ok := e.(*ChoiceExpr); ok && c.Longest == expr.Longest {
+				br.Replace(FlattenChoice(expr))
+				break
+			}
+		}
+	}
+	return v
+}
+
+// ToSeq returns the explicit form of the repetition e+, that is the
+// sequence e e*, with a copy of the expression of o in each part. It
+// matches the same input as o, but its value is the value of the first
+// match followed by the slice of the values of the next matches, instead of
+// the slice of the values of all the matches.
+func (o *OneOrMoreExpr) ToSeq() *SeqExpr {
+	zero := NewZeroOrMoreExpr(o.p)
+	zero.Expr = cloneExpr(o.Expr)
+	seq := NewSeqExpr(o.p)
+	seq.Exprs = []Expression{cloneExpr(o.Expr), zero}
+	return seq
+}
@@ -0,0 +1,119 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlattenSeq(t *testing.T) {
+	a, b, c, d := Lit("a"), Lit("b"), Lit("c"), Lit("d")
+	opt := Optional(Seq(c, d))
+	seq := Seq(Seq(a, Seq(b)), opt, Seq())
+	got := FlattenSeq(seq)
+	if want := Seq(a, b, opt); !Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if len(seq.Exprs) != 3 {
+		t.Errorf("want original sequence unchanged, got %v", seq)
+	}
+	if got.Exprs[0] != a || got.Exprs[2] != opt {
+		t.Error("want shared expressions")
+	}
+}
+
+func TestFlattenChoice(t *testing.T) {
+	a, b, c, d := Lit("a"), Lit("b"), Lit("c"), Lit("d")
+	longest := Choice(c, d)
+	longest.Longest = true
+	ch := Choice(a, Choice(b, Choice(c)), longest)
+	got := FlattenChoice(ch)
+	if want := Choice(a, b, c, longest); !Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if len(ch.Alternatives) != 3 {
+		t.Errorf("want original choice unchanged, got %v", ch)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	b := NewGrammarBuilder()
+	b.AddRule("A").Choice(
+		Seq(Lit("a"), Seq(Lit("b"), Choice(Lit("c"), Choice(Lit("d"), Lit("e"))))),
+		Choice(Action(Seq(Seq(Lit("f")), Lit("g")), "{ return nil, nil }"), Lit("h")),
+	)
+	g := b.Grammar()
+	Normalize(g)
+
+	want := Choice(
+		Seq(Lit("a"), Lit("b"), Choice(Lit("c"), Lit("d"), Lit("e"))),
+		Action(Seq(Lit("f"), Lit("g")), "{ return nil, nil }"),
+		Lit("h"),
+	)
+	if got := g.Rules[0].Expr; !Equal(got, want) {
+		t.Errorf("want\n%v\ngot\n%v", want, got)
+	}
+}
+
+// match returns the length of the match of expr at the start of s, for the
+// subset of the expressions needed by the tests.
+func match(expr Expression, s string) (int, bool) {
+	switch expr := expr.(type) {
+	case *LitMatcher:
+		if strings.HasPrefix(s, expr.Val) {
+			return len(expr.Val), true
+		}
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if n, ok := match(alt, s); ok {
+				return n, true
+			}
+		}
+	case *SeqExpr:
+		var n int
+		for _, e := range expr.Exprs {
+			m, ok := match(e, s[n:])
+			if !ok {
+				return 0, false
+			}
+			n += m
+		}
+		return n, true
+	case *ZeroOrMoreExpr:
+		var n int
+		for {
+			m, ok := match(expr.Expr, s[n:])
+			if !ok || m == 0 {
+				return n, true
+			}
+			n += m
+		}
+	case *OneOrMoreExpr:
+		n, ok := match(expr.Expr, s)
+		if !ok {
+			return 0, false
+		}
+		m, _ := match(ZeroOrMore(expr.Expr), s[n:])
+		return n + m, true
+	}
+	return 0, false
+}
+
+func TestOneOrMoreToSeq(t *testing.T) {
+	one := OneOrMore(Choice(Lit("ab"), Seq(Lit("c"), Lit("d"))))
+	seq := one.ToSeq()
+	if want := Seq(one.Expr, ZeroOrMore(one.Expr)); !Equal(seq, want) {
+		t.Fatalf("want %v, got %v", want, seq)
+	}
+	if seq.Exprs[0] == one.Expr || seq.Exprs[1].(*ZeroOrMoreExpr).Expr == one.Expr ||
+		seq.Exprs[0] == seq.Exprs[1].(*ZeroOrMoreExpr).Expr {
+		t.Error("want copies of the expression")
+	}
+
+	for _, in := range []string{"", "a", "ab", "abcd", "cdab!", "abab", "x", "cx", "abcdcdab"} {
+		n1, ok1 := match(one, in)
+		n2, ok2 := match(seq, in)
+		if n1 != n2 || ok1 != ok2 {
+			t.Errorf("%q: want %d, %t, got %d, %t", in, n1, ok1, n2, ok2)
+		}
+	}
+}
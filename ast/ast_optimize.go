@@ -196,7 +196,7 @@ func (r *grammarOptimizer) optChoiceExpr(expr *ChoiceExpr, br Backref) {
 
 	// Optimize choice nested in choice
 	for i := 0; i < len(expr.Alternatives); i++ {
-		if choice, ok := expr.Alternatives[i].(*ChoiceExpr); ok {
+		if choice, ok := expr.Alternatives[i].(*ChoiceExpr); ok && choice.Longest == expr.Longest {
 			r.optimized = true
 			if i+1 < len(expr.Alternatives) {
 				expr.Alternatives = append(expr.Alternatives[:i], append(choice.Alternatives, expr.Alternatives[i+1:]...)...)
@@ -370,6 +370,14 @@ func (r *grammarOptimizer) optSeqExpr(expr *SeqExpr, br Backref) {
 		}
 	}
 
+	// Remove the and predicates that are guaranteed by the next expression
+	for i := 0; i+1 < len(expr.Exprs); i++ {
+		if and, ok := expr.Exprs[i].(*AndExpr); ok && subsumes(expr.Exprs[i+1], and.Expr) {
+			r.optimized = true
+			expr.Exprs = append(expr.Exprs[:i], expr.Exprs[i+1:]...)
+		}
+	}
+
 	allSimple := true
 	for _, e := range expr.Exprs {
 		if !r.isSimple(e) {
@@ -383,6 +391,32 @@ func (r *grammarOptimizer) optSeqExpr(expr *SeqExpr, br Backref) {
 	}
 }
 
+// subsumes returns true if expr only matches if the lookahead expression
+// look matches at the same position, so that the and predicate of look is
+// redundant before expr. Only the matchers are considered as lookahead
+// expressions, so that the labeled expressions and the code blocks are
+// left alone.
+func subsumes(expr, look Expression) bool {
+	if lbl, ok := expr.(*LabeledExpr); ok {
+		expr = lbl.Expr
+	}
+	switch look := look.(type) {
+	case *AnyMatcher:
+		switch expr := expr.(type) {
+		case *AnyMatcher, *CharClassMatcher:
+			return true
+		case *LitMatcher:
+			return expr.Val != ""
+		}
+	case *CharClassMatcher:
+		return Equal(expr, look)
+	case *LitMatcher:
+		lit, ok := expr.(*LitMatcher)
+		return ok && lit.IgnoreCase == look.IgnoreCase && strings.HasPrefix(lit.Val, look.Val)
+	}
+	return false
+}
+
 func (r *grammarOptimizer) optOneOrMoreExpr(expr *OneOrMoreExpr) {
 	if r.isSimple(expr.Expr) {
 		expr.Opt.SkipVals = true
@@ -407,10 +441,11 @@ func cloneExpr(expr Expression) Expression {
 	switch expr := expr.(type) {
 	case *ActionExpr:
 		return &ActionExpr{
-			Code:   expr.Code,
-			Expr:   cloneExpr(expr.Expr),
-			FuncIx: expr.FuncIx,
-			p:      expr.p,
+			Code:       expr.Code,
+			Expr:       cloneExpr(expr.Expr),
+			FuncIx:     expr.FuncIx,
+			ReturnType: expr.ReturnType,
+			p:          expr.p,
 		}
 	case *AndExpr:
 		return &AndExpr{
@@ -426,6 +461,7 @@ func cloneExpr(expr Expression) Expression {
 	case *CharClassMatcher:
 		return &CharClassMatcher{
 			Chars:          append([]rune{}, expr.Chars...),
+			Charsets:       append([]string(nil), expr.Charsets...),
 			IgnoreCase:     expr.IgnoreCase,
 			Inverted:       expr.Inverted,
 			posValue:       expr.posValue,
@@ -439,6 +475,7 @@ func cloneExpr(expr Expression) Expression {
 		}
 		return &ChoiceExpr{
 			Alternatives: alts,
+			Longest:      expr.Longest,
 			p:            expr.p,
 		}
 	case *LabeledExpr:
@@ -447,6 +484,12 @@ func cloneExpr(expr Expression) Expression {
 			Label: expr.Label,
 			p:     expr.p,
 		}
+	case *LookbehindExpr:
+		return &LookbehindExpr{
+			Expr:   cloneExpr(expr.Expr),
+			Negate: expr.Negate,
+			p:      expr.p,
+		}
 	case *NotExpr:
 		return &NotExpr{
 			Expr: cloneExpr(expr.Expr),
@@ -495,6 +538,37 @@ func cloneExpr(expr Expression) Expression {
 			IgnoreCase: expr.IgnoreCase,
 			invert:     expr.invert,
 		}
+	case *AnyMatcher:
+		return &AnyMatcher{posValue: expr.posValue}
+	case *BOLMatcher:
+		return &BOLMatcher{posValue: expr.posValue}
+	case *RecoveryExpr:
+		return &RecoveryExpr{
+			Expr:        cloneExpr(expr.Expr),
+			RecoverExpr: cloneExpr(expr.RecoverExpr),
+			Labels:      append([]FailureLabel(nil), expr.Labels...),
+			p:           expr.p,
+		}
+	case *KeywordMatcher:
+		return &KeywordMatcher{
+			Keywords: append([]string(nil), expr.Keywords...),
+			p:        expr.p,
+		}
+	case *RegexpMatcher:
+		return &RegexpMatcher{
+			Expr: expr.Expr,
+			p:    expr.p,
+		}
+	case *RuleRefExpr:
+		return &RuleRefExpr{
+			Name: expr.Name,
+			p:    expr.p,
+		}
+	case *ThrowExpr:
+		return &ThrowExpr{
+			Label: expr.Label,
+			p:     expr.p,
+		}
 	}
 	return expr
 }
@@ -594,6 +668,8 @@ func escapeRune(r rune) string {
 // * resolve sequence expressions with only one element
 // * combine character class matcher and literal matcher, where possible
 // * combine not and character class matcher, where possible
+// * remove the and predicates of matchers that precede an expression that
+//   only matches if the predicate matches, e.g. &[0-9] [0-9]
 func Optimize(g *Grammar, alternateEntrypoints ...string) {
 	entrypoints := alternateEntrypoints
 	if len(g.Rules) > 0 {
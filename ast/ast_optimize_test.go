@@ -364,3 +364,35 @@ func DisableTestOptimize(t *testing.T) {
 		}
 	}
 }
+
+func TestOptimizeRedundantAndExpr(t *testing.T) {
+	digit := func() *CharClassMatcher { return Class([2]rune{'0', '9'}) }
+
+	b := NewGrammarBuilder()
+	b.AddRule("A").Choice(
+		Action(Seq(
+			And(digit()), Label("d", digit()),
+			And(Lit("ab")), Lit("abc"),
+			And(Label("x", Lit("a"))), Lit("a"),
+		), "{ return d, nil }"),
+		Seq(And(Any()), digit()),
+		Seq(And(Lit("ab")), Lit("a")),
+		Seq(And(digit()), Class([2]rune{'0', '8'})),
+	)
+	g := b.Grammar()
+	Optimize(g)
+
+	want := Choice(
+		Action(Seq(
+			Label("d", digit()),
+			Lit("abc"),
+			And(Label("x", Lit("a"))), Lit("a"),
+		), "{ return d, nil }"),
+		digit(),
+		Seq(And(Lit("ab")), Lit("a")),
+		Seq(And(digit()), Class([2]rune{'0', '8'})),
+	)
+	if got := g.Rules[0].Expr; !Equal(got, want) {
+		t.Errorf("want\n%v\ngot\n%v", want, got)
+	}
+}
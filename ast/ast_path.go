@@ -0,0 +1,322 @@
+package ast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type parentMapper map[Expression]Expression
+
+func (m parentMapper) Visit(expr Expression, br Backref) Visitor {
+	if br.parent != nil {
+		m[expr] = br.parent
+	}
+	return m
+}
+
+// BuildParentMap returns a map of every expression of the grammar to its
+// parent expression. The grammar itself is not part of the map.
+func BuildParentMap(g *Grammar) map[Expression]Expression {
+	m := make(parentMapper)
+	Walk(m, g)
+	return m
+}
+
+// AnnotateWithIDs assigns a unique integer ID to every expression of the
+// grammar, including the grammar itself (ID 0) and its rules, and returns
+// the mapping. The IDs are consecutive and assigned in depth-first,
+// left-to-right order, so that processing the same grammar twice assigns
+// the same IDs. An expression that appears more than once in the tree
+// keeps the ID of its first occurrence.
+func AnnotateWithIDs(g *Grammar) map[Expression]int {
+	ids := make(map[Expression]int)
+	Inspect(g, func(expr Expression) bool {
+		if _, ok := ids[expr]; !ok {
+			ids[expr] = len(ids)
+		}
+		return true
+	})
+	return ids
+}
+
+// PathString returns the path from the grammar to expr, in a form
+// similar to a CSS selector, e.g.:
+//
+//	Grammar > Rule("start") > SeqExpr[0] > LabeledExpr("left") > LitMatcher("+")
+//
+// Each step is the type name of the expression, followed by its name
+// (for Rule and RuleRefExpr), label (for LabeledExpr and ThrowExpr) or
+// value (for LitMatcher and CharClassMatcher) in parentheses. The
+// expressions with more than one child (ChoiceExpr, RecoveryExpr and
+// SeqExpr) are followed by the index of the child that the path goes
+// through. It returns an empty string if expr is not part of g.
+//
+// ParsePath is the inverse of PathString.
+func PathString(expr Expression, g *Grammar) string {
+	parents := BuildParentMap(g)
+
+	steps := []Expression{expr}
+	for expr != Expression(g) {
+		parent, ok := parents[expr]
+		if !ok {
+			return ""
+		}
+		steps = append(steps, parent)
+		expr = parent
+	}
+
+	var buf bytes.Buffer
+	for i := len(steps) - 1; i >= 0; i-- {
+		e := steps[i]
+		buf.WriteString(typeName(e))
+		if name, ok := pathName(e); ok {
+			buf.WriteString("(" + strconv.Quote(name) + ")")
+		}
+		if i > 0 {
+			if ix := childIndex(e, steps[i-1]); ix >= 0 {
+				fmt.Fprintf(&buf, "[%d]", ix)
+			}
+			buf.WriteString(" > ")
+		}
+	}
+	return buf.String()
+}
+
+// ParsePath returns the expression of g identified by the path s, as
+// returned by PathString.
+func ParsePath(s string, g *Grammar) (Expression, error) {
+	var cur Expression
+	ix := -1
+	for i := 0; ; i++ {
+		st, rest, err := parsePathStep(s)
+		if err != nil {
+			return nil, err
+		}
+
+		var e Expression
+		switch {
+		case i == 0:
+			if st.index >= 0 {
+				return nil, fmt.Errorf("invalid path step %q: unexpected index", st.raw)
+			}
+			e = g
+		case cur == Expression(g):
+			for _, r := range g.Rules {
+				if name, ok := st.name(); ok && r.Name.Val == name {
+					e = r
+					break
+				}
+			}
+			if e == nil {
+				return nil, fmt.Errorf("invalid path step %q: no such rule", st.raw)
+			}
+		default:
+			e = pathChild(cur, ix)
+			if e == nil {
+				return nil, fmt.Errorf("invalid path step %q: no such child", st.raw)
+			}
+		}
+
+		if typeName(e) != st.typ {
+			return nil, fmt.Errorf("invalid path step %q: expected %s", st.raw, typeName(e))
+		}
+		name, ok := pathName(e)
+		if sname, sok := st.name(); ok != sok || name != sname {
+			return nil, fmt.Errorf("invalid path step %q: expected name %q", st.raw, name)
+		}
+		cur, ix = e, st.index
+
+		if rest == "" {
+			if st.index >= 0 {
+				return nil, fmt.Errorf("invalid path step %q: index without child", st.raw)
+			}
+			return cur, nil
+		}
+		if !strings.HasPrefix(rest, " > ") {
+			return nil, fmt.Errorf("invalid path: expected \" > \" before %q", rest)
+		}
+		s = rest[3:]
+	}
+}
+
+// pathStep is a step of a path, as parsed by parsePathStep.
+type pathStep struct {
+	raw   string
+	typ   string
+	quote string
+	index int
+}
+
+func (st pathStep) name() (string, bool) {
+	if st.quote == "" {
+		return "", false
+	}
+	name, _ := strconv.Unquote(st.quote)
+	return name, true
+}
+
+// parsePathStep parses the step at the start of s and returns it along with
+// the rest of s.
+func parsePathStep(s string) (pathStep, string, error) {
+	st := pathStep{index: -1}
+
+	i := 0
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+		i++
+	}
+	if i == 0 {
+		return st, "", fmt.Errorf("invalid path: expected type name at %q", s)
+	}
+	st.typ = s[:i]
+
+	if i < len(s) && s[i] == '(' {
+		j := i + 1
+		if j >= len(s) || s[j] != '"' {
+			return st, "", fmt.Errorf("invalid path: expected quoted name at %q", s[j:])
+		}
+		for j++; j < len(s) && s[j] != '"'; j++ {
+			if s[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(s) {
+			return st, "", errors.New("invalid path: unterminated quoted name")
+		}
+		st.quote = s[i+1 : j+1]
+		if _, err := strconv.Unquote(st.quote); err != nil {
+			return st, "", fmt.Errorf("invalid path: invalid quoted name %s: %v", st.quote, err)
+		}
+		if j+1 >= len(s) || s[j+1] != ')' {
+			return st, "", fmt.Errorf("invalid path: expected ')' after %s", st.quote)
+		}
+		i = j + 2
+	}
+
+	if i < len(s) && s[i] == '[' {
+		j := strings.IndexByte(s[i:], ']')
+		if j < 0 {
+			return st, "", errors.New("invalid path: unterminated index")
+		}
+		ix, err := strconv.Atoi(s[i+1 : i+j])
+		if err != nil || ix < 0 {
+			return st, "", fmt.Errorf("invalid path: invalid index %q", s[i+1:i+j])
+		}
+		st.index = ix
+		i += j + 1
+	}
+
+	st.raw = s[:i]
+	return st, s[i:], nil
+}
+
+// typeName returns the name of the type of expr, without the package
+// qualifier.
+func typeName(expr Expression) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", expr), "*ast.")
+}
+
+// pathName returns the name that identifies expr in a path, if any.
+func pathName(expr Expression) (string, bool) {
+	switch expr := expr.(type) {
+	case *CharClassMatcher:
+		return expr.Val, true
+	case *LabeledExpr:
+		return expr.Label.Val, true
+	case *LitMatcher:
+		return expr.Val, true
+	case *Rule:
+		return expr.Name.Val, true
+	case *RuleRefExpr:
+		return expr.Name.Val, true
+	case *ThrowExpr:
+		return expr.Label, true
+	}
+	return "", false
+}
+
+// childIndex returns the index of child in parent if parent has more than
+// one child, -1 otherwise.
+func childIndex(parent, child Expression) int {
+	switch parent := parent.(type) {
+	case *ChoiceExpr:
+		for i, e := range parent.Alternatives {
+			if e == child {
+				return i
+			}
+		}
+	case *RecoveryExpr:
+		if parent.Expr == child {
+			return 0
+		}
+		return 1
+	case *SeqExpr:
+		for i, e := range parent.Exprs {
+			if e == child {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// pathChild returns the child of parent at index ix, or the single child of
+// parent if ix is -1. It returns nil if there is no such child.
+func pathChild(parent Expression, ix int) Expression {
+	switch parent := parent.(type) {
+	case *ChoiceExpr:
+		if ix >= 0 && ix < len(parent.Alternatives) {
+			return parent.Alternatives[ix]
+		}
+	case *RecoveryExpr:
+		switch ix {
+		case 0:
+			return parent.Expr
+		case 1:
+			return parent.RecoverExpr
+		}
+	case *SeqExpr:
+		if ix >= 0 && ix < len(parent.Exprs) {
+			return parent.Exprs[ix]
+		}
+	case *ActionExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *AndExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *LabeledExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *LookbehindExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *NotExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *OneOrMoreExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *Rule:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *ZeroOrMoreExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	case *ZeroOrOneExpr:
+		if ix < 0 {
+			return parent.Expr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestPathString(t *testing.T) {
+	g := parseGrammar(t, `start = left:"a" "+" right:B / "b"
+B = (!"x" [a-z]i)+ ("y" / .)* { return nil, nil }`)
+
+	seq := g.Rules[0].Expr.(*ast.ChoiceExpr).Alternatives[0].(*ast.SeqExpr)
+	want := `Grammar > Rule("start") > ChoiceExpr[0] > SeqExpr[0] > LabeledExpr("left") > LitMatcher("a")`
+	if got := ast.PathString(seq.Exprs[0].(*ast.LabeledExpr).Expr, g); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	if got := ast.PathString(ast.NewLitMatcher(ast.Pos{}, "a"), g); got != "" {
+		t.Errorf("want empty path for expression not in grammar, got %s", got)
+	}
+
+	// every expression round-trips through ParsePath
+	var n int
+	ast.Inspect(g, func(expr ast.Expression) bool {
+		if expr == nil {
+			return true
+		}
+		n++
+		path := ast.PathString(expr, g)
+		got, err := ast.ParsePath(path, g)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		} else if got != expr {
+			t.Errorf("%s: want %v, got %v", path, expr, got)
+		}
+		return true
+	})
+	if n != 22 {
+		t.Errorf("want 22 expressions, got %d", n)
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	g := parseGrammar(t, `A = "a" B
+B = "b"`)
+
+	cases := []string{
+		``,
+		`Rule("A")`,
+		`Grammar[0]`,
+		`Grammar > Rule("C")`,
+		`Grammar > Rule("A") > SeqExpr[2]`,
+		`Grammar > Rule("A") > SeqExpr[0]`,
+		`Grammar > Rule("A") > SeqExpr[0] > LitMatcher("b")`,
+		`Grammar > Rule("A") > SeqExpr[0] > RuleRefExpr("a")`,
+		`Grammar > Rule("A") > SeqExpr[0] > LitMatcher("a`,
+		`Grammar > Rule("A") > SeqExpr[1] > RuleRefExpr("B") > Rule("B")`,
+		`Grammar > Rule("A")>SeqExpr[0]`,
+	}
+	for _, c := range cases {
+		if _, err := ast.ParsePath(c, g); err == nil {
+			t.Errorf("%q: want error, got nil", c)
+		}
+	}
+}
+
+func TestAnnotateWithIDs(t *testing.T) {
+	src := `start = left:"a" "+" right:B / "b"
+B = (!"x" [a-z]i)+ ("y" / .)* { return nil, nil }`
+	g := parseGrammar(t, src)
+	ids := ast.AnnotateWithIDs(g)
+
+	var nodes []ast.Expression
+	ast.Inspect(g, func(expr ast.Expression) bool {
+		nodes = append(nodes, expr)
+		return true
+	})
+	if len(ids) != len(nodes) {
+		t.Fatalf("want %d IDs, got %d", len(nodes), len(ids))
+	}
+	// every node has an ID, and the IDs are unique and in depth-first order
+	for i, n := range nodes {
+		if id, ok := ids[n]; !ok || id != i {
+			t.Errorf("%s: want ID %d, got %d (%t)", n, i, id, ok)
+		}
+	}
+
+	// the same grammar processed twice gets the same IDs
+	g2 := parseGrammar(t, src)
+	ids2 := ast.AnnotateWithIDs(g2)
+	for _, n := range nodes {
+		p := ast.PathString(n, g)
+		n2, err := ast.ParsePath(p, g2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids[n] != ids2[n2] {
+			t.Errorf("%s: want ID %d, got %d", p, ids[n], ids2[n2])
+		}
+	}
+}
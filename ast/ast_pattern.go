@@ -0,0 +1,267 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ExpressionPattern is an expression used as a structural pattern by
+// MatchPattern and ReplacePattern. A nil expression in the pattern (e.g.
+// a nil element of SeqExpr.Exprs or a nil LabeledExpr.Expr) is a wildcard
+// that matches any expression, and a *PatternVar matches any expression
+// and captures it under its name. The zero-valued fields of the pattern
+// nodes also match any value, e.g. a LitMatcher with an empty Val matches
+// any literal and a SeqExpr with nil Exprs matches any sequence.
+type ExpressionPattern = Expression
+
+// ExpressionTemplate is an expression used as a replacement by
+// ReplacePattern. Each *PatternVar of the template is replaced by the
+// expression captured under its name by the pattern.
+type ExpressionTemplate = Expression
+
+// PatternVar is an expression of an ExpressionPattern that matches any
+// expression and captures it under Name, or an expression of an
+// ExpressionTemplate that is replaced by the expression captured under
+// Name.
+type PatternVar struct {
+	Name string
+}
+
+// Pos returns the starting position of the node, which is always the
+// zero value as a PatternVar is never part of a parsed grammar.
+func (v *PatternVar) Pos() Pos { return Pos{} }
+
+// String returns the textual representation of a node.
+func (v *PatternVar) String() string {
+	return fmt.Sprintf("%T{Name: %s}", v, v.Name)
+}
+
+// MatchPattern returns true if expr matches the pattern, along with the
+// expressions captured by the PatternVars of the pattern, by name. If the
+// same name is used more than once in the pattern, the last capture wins.
+func MatchPattern(pattern, expr Expression) (bool, map[string]Expression) {
+	vars := make(map[string]Expression)
+	if !matchPattern(pattern, expr, vars) {
+		return false, nil
+	}
+	return true, vars
+}
+
+func matchPattern(pattern, expr Expression, vars map[string]Expression) bool {
+	if pattern == nil {
+		return true
+	}
+	if v, ok := pattern.(*PatternVar); ok {
+		vars[v.Name] = expr
+		return true
+	}
+	if expr == nil || reflect.TypeOf(pattern) != reflect.TypeOf(expr) {
+		return false
+	}
+
+	switch pat := pattern.(type) {
+	case *ActionExpr:
+		expr := expr.(*ActionExpr)
+		return matchCode(pat.Code, expr.Code) && matchPattern(pat.Expr, expr.Expr, vars)
+	case *AndCodeExpr:
+		return matchCode(pat.Code, expr.(*AndCodeExpr).Code)
+	case *AndExpr:
+		return matchPattern(pat.Expr, expr.(*AndExpr).Expr, vars)
+	case *AnyMatcher, *BOLMatcher:
+		return true
+	case *CharClassMatcher:
+		return pat.Val == "" || pat.Val == expr.(*CharClassMatcher).Val
+	case *ChoiceExpr:
+		return matchPatterns(pat.Alternatives, expr.(*ChoiceExpr).Alternatives, vars)
+	case *LabeledExpr:
+		expr := expr.(*LabeledExpr)
+		if pat.Label != nil && pat.Label.Val != "" && (expr.Label == nil || pat.Label.Val != expr.Label.Val) {
+			return false
+		}
+		return matchPattern(pat.Expr, expr.Expr, vars)
+	case *LitMatcher:
+		expr := expr.(*LitMatcher)
+		return pat.Val == "" || (pat.Val == expr.Val && pat.IgnoreCase == expr.IgnoreCase)
+	case *LookbehindExpr:
+		expr := expr.(*LookbehindExpr)
+		return pat.Negate == expr.Negate && matchPattern(pat.Expr, expr.Expr, vars)
+	case *NotCodeExpr:
+		return matchCode(pat.Code, expr.(*NotCodeExpr).Code)
+	case *NotExpr:
+		return matchPattern(pat.Expr, expr.(*NotExpr).Expr, vars)
+	case *OneOrMoreExpr:
+		return matchPattern(pat.Expr, expr.(*OneOrMoreExpr).Expr, vars)
+	case *RecoveryExpr:
+		expr := expr.(*RecoveryExpr)
+		if pat.Labels != nil && !reflect.DeepEqual(pat.Labels, expr.Labels) {
+			return false
+		}
+		return matchPattern(pat.Expr, expr.Expr, vars) && matchPattern(pat.RecoverExpr, expr.RecoverExpr, vars)
+	case *KeywordMatcher:
+		return len(pat.Keywords) == 0 || equalStrings(pat.Keywords, expr.(*KeywordMatcher).Keywords)
+	case *RegexpMatcher:
+		return pat.Expr == "" || pat.Expr == expr.(*RegexpMatcher).Expr
+	case *RuleRefExpr:
+		expr := expr.(*RuleRefExpr)
+		return pat.Name == nil || pat.Name.Val == "" || (expr.Name != nil && pat.Name.Val == expr.Name.Val)
+	case *SeqExpr:
+		return matchPatterns(pat.Exprs, expr.(*SeqExpr).Exprs, vars)
+	case *StateCodeExpr:
+		return matchCode(pat.Code, expr.(*StateCodeExpr).Code)
+	case *ThrowExpr:
+		return pat.Label == "" || pat.Label == expr.(*ThrowExpr).Label
+	case *ZeroOrMoreExpr:
+		return matchPattern(pat.Expr, expr.(*ZeroOrMoreExpr).Expr, vars)
+	case *ZeroOrOneExpr:
+		return matchPattern(pat.Expr, expr.(*ZeroOrOneExpr).Expr, vars)
+	}
+	return false
+}
+
+// matchPatterns returns true if each expression of exprs matches the
+// pattern at the same index. A nil patterns slice matches any exprs.
+func matchPatterns(patterns, exprs []Expression, vars map[string]Expression) bool {
+	if patterns == nil {
+		return true
+	}
+	if len(patterns) != len(exprs) {
+		return false
+	}
+	for i, pat := range patterns {
+		if !matchPattern(pat, exprs[i], vars) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCode returns true if the code block matches the pattern code block.
+func matchCode(pattern, code *CodeBlock) bool {
+	return pattern == nil || pattern.Val == "" || (code != nil && pattern.Val == code.Val)
+}
+
+// ReplacePattern replaces each expression of the grammar that matches the
+// pattern with a new instance of the replacement template, in which each
+// PatternVar is replaced by a copy of the expression captured under its
+// name. The grammar is transformed in place and returned. The grammar and
+// the rules themselves are never replaced, and the replacements are not
+// matched again against the pattern.
+//
+// The nodes of the replacement that have no position get the position of
+// the replaced expression. ReplacePattern panics if the replacement uses a
+// PatternVar that is not captured by the pattern.
+func ReplacePattern(g *Grammar, pattern ExpressionPattern, replacement ExpressionTemplate) *Grammar {
+	Walk(&patternReplacer{pattern: pattern, replacement: replacement}, g)
+	return g
+}
+
+// patternReplacer is the Visitor of ReplacePattern.
+type patternReplacer struct {
+	pattern     ExpressionPattern
+	replacement ExpressionTemplate
+}
+
+func (r *patternReplacer) Visit(expr Expression, br Backref) Visitor {
+	switch expr.(type) {
+	case *Grammar, *Rule:
+		return r
+	}
+	if ok, vars := MatchPattern(r.pattern, expr); ok {
+		br.replacer(instantiate(r.replacement, vars, expr.Pos()))
+		return nil
+	}
+	return r
+}
+
+// instantiate returns a new instance of the template tpl, with the
+// PatternVars replaced by copies of the expressions in vars. The nodes
+// that have no position are set at position p. It also copies the captured
+// expressions, which have no PatternVar.
+func instantiate(tpl ExpressionTemplate, vars map[string]Expression, p Pos) Expression {
+	pos := func(tp Pos) Pos {
+		if tp == (Pos{}) {
+			return p
+		}
+		return tp
+	}
+	inst := func(tpl Expression) Expression {
+		if tpl == nil {
+			return nil
+		}
+		return instantiate(tpl, vars, p)
+	}
+	insts := func(tpls []Expression) []Expression {
+		if tpls == nil {
+			return nil
+		}
+		exprs := make([]Expression, len(tpls))
+		for i, tpl := range tpls {
+			exprs[i] = inst(tpl)
+		}
+		return exprs
+	}
+
+	switch tpl := tpl.(type) {
+	case *PatternVar:
+		expr, ok := vars[tpl.Name]
+		if !ok {
+			panic(fmt.Sprintf("pattern variable %s is not captured by the pattern", tpl.Name))
+		}
+		if expr == nil {
+			return nil
+		}
+		return instantiate(expr, nil, p)
+	case *ActionExpr:
+		return &ActionExpr{p: pos(tpl.p), Expr: inst(tpl.Expr), Code: tpl.Code}
+	case *AndCodeExpr:
+		return &AndCodeExpr{p: pos(tpl.p), Code: tpl.Code}
+	case *AndExpr:
+		return &AndExpr{p: pos(tpl.p), Expr: inst(tpl.Expr)}
+	case *AnyMatcher:
+		return &AnyMatcher{posValue{p: pos(tpl.p), Val: tpl.Val}}
+	case *BOLMatcher:
+		return &BOLMatcher{posValue{p: pos(tpl.p), Val: tpl.Val}}
+	case *CharClassMatcher:
+		cc := cloneExpr(tpl).(*CharClassMatcher)
+		cc.p = pos(tpl.p)
+		return cc
+	case *ChoiceExpr:
+		return &ChoiceExpr{p: pos(tpl.p), Alternatives: insts(tpl.Alternatives)}
+	case *LabeledExpr:
+		return &LabeledExpr{p: pos(tpl.p), Label: tpl.Label, Expr: inst(tpl.Expr)}
+	case *LitMatcher:
+		return &LitMatcher{posValue: posValue{p: pos(tpl.p), Val: tpl.Val}, IgnoreCase: tpl.IgnoreCase}
+	case *LookbehindExpr:
+		return &LookbehindExpr{p: pos(tpl.p), Expr: inst(tpl.Expr), Negate: tpl.Negate}
+	case *NotCodeExpr:
+		return &NotCodeExpr{p: pos(tpl.p), Code: tpl.Code}
+	case *NotExpr:
+		return &NotExpr{p: pos(tpl.p), Expr: inst(tpl.Expr)}
+	case *OneOrMoreExpr:
+		return &OneOrMoreExpr{p: pos(tpl.p), Expr: inst(tpl.Expr)}
+	case *RecoveryExpr:
+		return &RecoveryExpr{
+			p:           pos(tpl.p),
+			Expr:        inst(tpl.Expr),
+			RecoverExpr: inst(tpl.RecoverExpr),
+			Labels:      append([]FailureLabel(nil), tpl.Labels...),
+		}
+	case *KeywordMatcher:
+		return &KeywordMatcher{p: pos(tpl.p), Keywords: append([]string(nil), tpl.Keywords...)}
+	case *RegexpMatcher:
+		return &RegexpMatcher{p: pos(tpl.p), Expr: tpl.Expr}
+	case *RuleRefExpr:
+		return &RuleRefExpr{p: pos(tpl.p), Name: tpl.Name}
+	case *SeqExpr:
+		return &SeqExpr{p: pos(tpl.p), Exprs: insts(tpl.Exprs)}
+	case *StateCodeExpr:
+		return &StateCodeExpr{p: pos(tpl.p), Code: tpl.Code}
+	case *ThrowExpr:
+		return &ThrowExpr{p: pos(tpl.p), Label: tpl.Label}
+	case *ZeroOrMoreExpr:
+		return &ZeroOrMoreExpr{p: pos(tpl.p), Expr: inst(tpl.Expr)}
+	case *ZeroOrOneExpr:
+		return &ZeroOrOneExpr{p: pos(tpl.p), Expr: inst(tpl.Expr)}
+	}
+	panic(fmt.Sprintf("unknown expression type %T", tpl))
+}
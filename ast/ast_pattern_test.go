@@ -0,0 +1,81 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestMatchPattern(t *testing.T) {
+	g := parseGrammar(t, `A = x:B ';' 'c'i`)
+	seq := g.Rules[0].Expr.(*ast.SeqExpr)
+
+	semi := ast.NewLitMatcher(ast.Pos{}, ";")
+	cases := []struct {
+		pattern ast.ExpressionPattern
+		match   bool
+		vars    map[string]string
+	}{
+		{pattern: nil, match: true},
+		{pattern: &ast.SeqExpr{}, match: true},
+		{pattern: &ast.SeqExpr{Exprs: []ast.Expression{nil, semi, nil}}, match: true},
+		{pattern: &ast.SeqExpr{Exprs: []ast.Expression{nil, semi}}, match: false},
+		{pattern: &ast.SeqExpr{Exprs: []ast.Expression{nil, nil, semi}}, match: false},
+		{pattern: &ast.SeqExpr{Exprs: []ast.Expression{
+			&ast.LabeledExpr{Label: ast.NewIdentifier(ast.Pos{}, "x"), Expr: &ast.PatternVar{Name: "ref"}},
+			&ast.PatternVar{Name: "semi"},
+			ast.NewLitMatcher(ast.Pos{}, ""),
+		}}, match: true, vars: map[string]string{"ref": "B", "semi": `";"`}},
+		{pattern: &ast.SeqExpr{Exprs: []ast.Expression{
+			&ast.LabeledExpr{Label: ast.NewIdentifier(ast.Pos{}, "y")}, nil, nil,
+		}}, match: false},
+		{pattern: &ast.ChoiceExpr{}, match: false},
+	}
+	for i, tc := range cases {
+		ok, vars := ast.MatchPattern(tc.pattern, seq)
+		if ok != tc.match {
+			t.Errorf("%d: want match %t, got %t", i, tc.match, ok)
+			continue
+		}
+		for nm, want := range tc.vars {
+			if got := describe(vars[nm]); got != want {
+				t.Errorf("%d: want %s captured as %s, got %s", i, nm, want, got)
+			}
+		}
+	}
+}
+
+func TestReplacePattern(t *testing.T) {
+	g := parseGrammar(t, `
+A = B ';' / (C ';')*
+B = 'b' ','
+`)
+	// replace each "X ';'" sequence with "X ';'?"
+	pattern := &ast.SeqExpr{Exprs: []ast.Expression{
+		&ast.PatternVar{Name: "x"},
+		ast.NewLitMatcher(ast.Pos{}, ";"),
+	}}
+	replacement := &ast.SeqExpr{Exprs: []ast.Expression{
+		&ast.PatternVar{Name: "x"},
+		&ast.ZeroOrOneExpr{Expr: ast.NewLitMatcher(ast.Pos{}, ";")},
+	}}
+	got := ast.ReplacePattern(g, pattern, replacement)
+	if got != g {
+		t.Fatal("want the grammar to be transformed in place")
+	}
+
+	want := []string{
+		`((B ";"?) / (C ";"?)*)`,
+		`("b" ",")`,
+	}
+	for i, r := range g.Rules {
+		if got := describe(r.Expr); got != want[i] {
+			t.Errorf("%s: want %s, got %s", r.Name.Val, want[i], got)
+		}
+	}
+	// the replacement is located at the position of the replaced expression
+	seq := g.Rules[0].Expr.(*ast.ChoiceExpr).Alternatives[0].(*ast.SeqExpr)
+	if p := seq.Exprs[1].Pos(); p.Line != 2 || p.Col != 5 {
+		t.Errorf("want replacement at 2:5, got %s", p)
+	}
+}
@@ -0,0 +1,101 @@
+package ast
+
+import (
+	"go/token"
+	"sort"
+)
+
+// Positions returns the source positions of all the nodes of the grammar,
+// including its identifiers, string literals and code blocks, sorted by
+// byte offset. The nodes that start at the same offset, e.g. a rule and
+// its name, are kept in tree order. The second slice is parallel to the
+// first and holds the name of the rule of each position, or an empty
+// string for the positions outside of the rules (the grammar and its
+// initializer).
+func (g *Grammar) Positions() ([]token.Position, []string) {
+	var (
+		poss  []token.Position
+		rules []string
+	)
+	add := func(rule string, p Pos) {
+		poss = append(poss, token.Position{
+			Filename: p.Filename,
+			Offset:   p.Off,
+			Line:     p.Line,
+			Column:   p.Col,
+		})
+		rules = append(rules, rule)
+	}
+
+	add("", g.p)
+	if g.Init != nil {
+		add("", g.Init.Pos())
+	}
+	for _, r := range g.Rules {
+		var rule string
+		if r.Name != nil {
+			rule = r.Name.Val
+		}
+		Inspect(r, func(expr Expression) bool {
+			add(rule, expr.Pos())
+			for _, p := range valuePositions(expr) {
+				add(rule, p)
+			}
+			return true
+		})
+	}
+
+	ix := make([]int, len(poss))
+	for i := range ix {
+		ix[i] = i
+	}
+	sort.SliceStable(ix, func(i, j int) bool {
+		return poss[ix[i]].Offset < poss[ix[j]].Offset
+	})
+	sortedPoss := make([]token.Position, len(ix))
+	sortedRules := make([]string, len(ix))
+	for i, k := range ix {
+		sortedPoss[i], sortedRules[i] = poss[k], rules[k]
+	}
+	return sortedPoss, sortedRules
+}
+
+// valuePositions returns the positions of the identifiers, string literals
+// and code blocks of expr, in source order.
+func valuePositions(expr Expression) []Pos {
+	var ps []Pos
+	switch expr := expr.(type) {
+	case *Rule:
+		if expr.Name != nil {
+			ps = append(ps, expr.Name.Pos())
+		}
+		if expr.DisplayName != nil {
+			ps = append(ps, expr.DisplayName.Pos())
+		}
+	case *ActionExpr:
+		if expr.Code != nil {
+			ps = append(ps, expr.Code.Pos())
+		}
+	case *AndCodeExpr:
+		if expr.Code != nil {
+			ps = append(ps, expr.Code.Pos())
+		}
+	case *LabeledExpr:
+		if expr.Label != nil {
+			ps = append(ps, expr.Label.Pos())
+		}
+	case *NotCodeExpr:
+		if expr.Code != nil {
+			ps = append(ps, expr.Code.Pos())
+		}
+	case *RuleRefExpr:
+		if expr.Name != nil {
+			ps = append(ps, expr.Name.Pos())
+		}
+	case *StateCodeExpr:
+		if expr.Code != nil {
+			ps = append(ps, expr.Code.Pos())
+		}
+	}
+	return ps
+}
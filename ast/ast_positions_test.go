@@ -0,0 +1,42 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrammarPositions(t *testing.T) {
+	src := "{ init }\nA \"a\" = x:B { act }\nB = 'b' / C\nC = .\n"
+	g := parseGrammar(t, src)
+
+	poss, rules := g.Positions()
+	if len(poss) != len(rules) {
+		t.Fatalf("want parallel slices, got %d positions and %d rules", len(poss), len(rules))
+	}
+
+	var offs []int
+	for i, p := range poss {
+		if i > 0 && p.Offset < poss[i-1].Offset {
+			t.Errorf("%d: want sorted offsets, got %d after %d", i, p.Offset, poss[i-1].Offset)
+		}
+		offs = append(offs, p.Offset)
+	}
+	want := []int{
+		0, 0, // grammar, initializer
+		9, 9, 11, 17, 17, 17, 19, 19, 21, // A, "a", action, x:, B, { act }
+		29, 29, 33, 33, 39, 39, // B, choice, 'b', C
+		41, 41, 45, // C, .
+	}
+	if !reflect.DeepEqual(offs, want) {
+		t.Errorf("want offsets %v, got %v", want, offs)
+	}
+
+	wantRules := []string{"", "", "A", "A", "A", "A", "A", "A", "A", "A", "A",
+		"B", "B", "B", "B", "B", "B", "C", "C", "C"}
+	if !reflect.DeepEqual(rules, wantRules) {
+		t.Errorf("want rules %q, got %q", wantRules, rules)
+	}
+	if poss[2].Line != 2 || poss[2].Column != 1 {
+		t.Errorf("want rule A at 2:1, got %d:%d", poss[2].Line, poss[2].Column)
+	}
+}
@@ -0,0 +1,231 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ToRegex returns the Go regexp syntax equivalent to the character class,
+// e.g. "[a-zA-Z0-9_]" or "[^a-z]". Characters outside of the printable
+// ASCII range are written using the \x{NNNN} notation, and a case
+// insensitive class is prefixed with the (?i) flag.
+//
+// An empty class, which never matches, is converted to a class of all
+// runes inverted, and an inverted empty class, which matches any rune, to
+// the class of all runes.
+func (c *CharClassMatcher) ToRegex() string {
+	var buf bytes.Buffer
+	if c.IgnoreCase {
+		buf.WriteString("(?i)")
+	}
+
+	buf.WriteByte('[')
+	if len(c.Chars) == 0 && len(c.Ranges) == 0 && len(c.UnicodeClasses) == 0 {
+		if !c.Inverted {
+			buf.WriteByte('^')
+		}
+		fmt.Fprintf(&buf, `\x00-\x{%X}]`, utf8.MaxRune)
+		return buf.String()
+	}
+
+	if c.Inverted {
+		buf.WriteByte('^')
+	}
+	for _, rn := range c.Chars {
+		writeRegexRune(&buf, rn)
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		writeRegexRune(&buf, c.Ranges[i])
+		buf.WriteByte('-')
+		writeRegexRune(&buf, c.Ranges[i+1])
+	}
+	for _, cl := range c.UnicodeClasses {
+		buf.WriteString(`\p{` + cl + `}`)
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+// writeRegexRune writes rn to buf as a character of a regexp character
+// class.
+func writeRegexRune(buf *bytes.Buffer, rn rune) {
+	switch {
+	case rn == '\\' || rn == '[' || rn == ']' || rn == '^' || rn == '-':
+		buf.WriteByte('\\')
+		buf.WriteRune(rn)
+	case rn > ' ' && rn <= '~':
+		buf.WriteRune(rn)
+	default:
+		fmt.Fprintf(buf, `\x{%X}`, rn)
+	}
+}
+
+// NewCharClassMatcherFromRegex creates a character class matcher equivalent
+// to the Go regexp pattern, which must be a single character class such as
+// "[a-zA-Z_\d]", "[^a-z]" or "\pL", optionally prefixed with the (?i) flag.
+// It returns an error if the pattern is invalid or is not a pure character
+// class (e.g. if it contains an alternation, an anchor or a repetition).
+//
+// The pattern is interpreted by the regexp/syntax package, so the Unicode
+// classes and the case folding are expanded to the equivalent ranges of
+// characters in the returned matcher.
+func NewCharClassMatcherFromRegex(pattern string) (*CharClassMatcher, error) {
+	if !isRegexCharClass(strings.TrimPrefix(pattern, "(?i)")) {
+		return nil, fmt.Errorf("%q is not a character class", pattern)
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid character class %q: %v", pattern, err)
+	}
+
+	var ranges []rune
+	switch re.Op {
+	case syntax.OpCharClass:
+		ranges = re.Rune
+	case syntax.OpLiteral:
+		if len(re.Rune) != 1 {
+			return nil, fmt.Errorf("%q is not a character class", pattern)
+		}
+		rn := re.Rune[0]
+		ranges = []rune{rn, rn}
+		if re.Flags&syntax.FoldCase != 0 {
+			for f := unicode.SimpleFold(rn); f != rn; f = unicode.SimpleFold(f) {
+				ranges = append(ranges, f, f)
+			}
+		}
+	case syntax.OpAnyCharNotNL:
+		ranges = []rune{0, '\n' - 1, '\n' + 1, utf8.MaxRune}
+	case syntax.OpAnyChar:
+		ranges = []rune{0, utf8.MaxRune}
+	case syntax.OpNoMatch:
+	default:
+		return nil, fmt.Errorf("%q is not a character class", pattern)
+	}
+
+	// a class that spans the whole range of runes is most likely the
+	// complement of a smaller class, e.g. [^a-z].
+	var inverted bool
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == utf8.MaxRune {
+		inverted = true
+		var gaps []rune
+		for i := 1; i+1 < len(ranges); i += 2 {
+			gaps = append(gaps, ranges[i]+1, ranges[i+1]-1)
+		}
+		ranges = gaps
+	}
+	return NewCharClassMatcher(Pos{}, charClassFromRanges(ranges, inverted)), nil
+}
+
+// isRegexCharClass returns true if s is a single character class token of
+// the Go regexp syntax, either bracketed, a Perl class such as \d or a
+// Unicode class such as \pL or \p{Greek}. The dot is also accepted.
+func isRegexCharClass(s string) bool {
+	switch {
+	case s == ".":
+		return true
+
+	case strings.HasPrefix(s, `\p`), strings.HasPrefix(s, `\P`):
+		name := s[2:]
+		if strings.HasPrefix(name, "{") {
+			return strings.Index(name, "}") == len(name)-1
+		}
+		return utf8.RuneCountInString(name) == 1
+
+	case strings.HasPrefix(s, `\`):
+		return len(s) == 2 && strings.ContainsRune("dDsSwW", rune(s[1]))
+
+	case strings.HasPrefix(s, "["):
+		i := 1
+		if strings.HasPrefix(s[i:], "^") {
+			i++
+		}
+		if strings.HasPrefix(s[i:], "]") {
+			i++
+		}
+		for i < len(s) {
+			switch {
+			case s[i] == '\\':
+				i += 2
+			case strings.HasPrefix(s[i:], "[:"):
+				end := strings.Index(s[i+2:], ":]")
+				if end < 0 {
+					return false
+				}
+				i += end + 4
+			case s[i] == ']':
+				return i == len(s)-1
+			default:
+				i++
+			}
+		}
+	}
+	return false
+}
+
+// charClassFromRanges returns the raw pigeon character class that matches
+// the pairs of low and high runes in ranges.
+func charClassFromRanges(ranges []rune, inverted bool) string {
+	var buf bytes.Buffer
+	var dash bool
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		// the dash is only written as the first character of the class, so
+		// that it is never mistaken for a range.
+		if lo <= '-' && '-' <= hi {
+			dash = true
+			if lo < '-' {
+				writeCharClassRange(&buf, lo, '-'-1)
+			}
+			if hi > '-' {
+				writeCharClassRange(&buf, '-'+1, hi)
+			}
+			continue
+		}
+		writeCharClassRange(&buf, lo, hi)
+	}
+
+	var prefix string
+	if inverted {
+		prefix = "^"
+	}
+	if dash {
+		prefix += "-"
+	}
+	return "[" + prefix + buf.String() + "]"
+}
+
+// writeCharClassRange writes the range lo-hi to buf as part of a pigeon
+// character class.
+func writeCharClassRange(buf *bytes.Buffer, lo, hi rune) {
+	writeCharClassRune(buf, lo)
+	if hi == lo {
+		return
+	}
+	if hi > lo+1 {
+		buf.WriteByte('-')
+	}
+	writeCharClassRune(buf, hi)
+}
+
+// writeCharClassRune writes rn to buf as a character of a pigeon character
+// class.
+func writeCharClassRune(buf *bytes.Buffer, rn rune) {
+	switch {
+	case rn == ']' || rn == '\\':
+		buf.WriteByte('\\')
+		buf.WriteRune(rn)
+	case rn == '^':
+		// escaped so that it is never mistaken for an inverted class
+		buf.WriteString(`\x5e`)
+	case rn > ' ' && rn <= '~':
+		buf.WriteRune(rn)
+	case rn <= 0xFFFF:
+		fmt.Fprintf(buf, `\u%04x`, rn)
+	default:
+		fmt.Fprintf(buf, `\U%08x`, rn)
+	}
+}
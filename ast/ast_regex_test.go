@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCharClassMatcherToRegex(t *testing.T) {
+	cases := []struct {
+		in    string
+		want  string
+		match string
+		fail  string
+	}{
+		{in: "[a-zA-Z0-9_]", want: "[_a-zA-Z0-9]", match: "Q", fail: "-"},
+		{in: "[^a-z]", want: "[^a-z]", match: "A", fail: "q"},
+		{in: "[a]", want: "[a]", match: "a", fail: "b"},
+		{in: "[a-z]i", want: "(?i)[a-z]", match: "Q", fail: "0"},
+		{in: `[\]\\^-]`, want: `[\]\\\^\-]`, match: "^", fail: "a"},
+		{in: `[\pL\p{Greek}]`, want: `[\p{L}\p{Greek}]`, match: "λ", fail: "1"},
+		{in: "[é -ÿ\t]", want: `[\x{E9}\x{9}\x{A0}-\x{FF}]`, match: "\t", fail: " "},
+		{in: "[\U0001F600-\U0001F64F]", want: `[\x{1F600}-\x{1F64F}]`, match: "\U0001F601", fail: "a"},
+		{in: "[]", want: `[^\x00-\x{10FFFF}]`, fail: "a"},
+		{in: "[^]", want: `[\x00-\x{10FFFF}]`, match: "\n"},
+	}
+
+	for _, tc := range cases {
+		got := NewCharClassMatcher(Pos{}, tc.in).ToRegex()
+		if got != tc.want {
+			t.Errorf("%q: want %q, got %q", tc.in, tc.want, got)
+			continue
+		}
+		re, err := regexp.Compile("^" + got + "$")
+		if err != nil {
+			t.Errorf("%q: invalid regexp %q: %v", tc.in, got, err)
+			continue
+		}
+		if tc.match != "" && !re.MatchString(tc.match) {
+			t.Errorf("%q: want %q to match", tc.in, tc.match)
+		}
+		if tc.fail != "" && re.MatchString(tc.fail) {
+			t.Errorf("%q: want %q to not match", tc.in, tc.fail)
+		}
+	}
+}
+
+func TestNewCharClassMatcherFromRegex(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "[a-z]", want: "[a-z]"},
+		{in: "[^a-z]", want: "[^a-z]"},
+		{in: "[a]", want: "[a]"},
+		{in: "(?i)[k]", want: `[Kk\u212a]`},
+		{in: `[a-zA-Z_\d]`, want: "[0-9A-Z_a-z]"},
+		{in: `[\-\]\\^]`, want: `[-\\-\x5e]`},
+		{in: `[!-/]`, want: `[-!-,./]`},
+		{in: `[\x{1F600}-\x{1F64F}\t]`, want: `[\u0009\U0001f600-\U0001f64f]`},
+		{in: ".", want: `[^\u000a]`},
+		{in: `[^\x00-\x{10FFFF}]`, want: "[]"},
+		{in: `[\x00-\x{10FFFF}]`, want: "[^]"},
+	}
+	for _, tc := range cases {
+		c, err := NewCharClassMatcherFromRegex(tc.in)
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc.in, err)
+			continue
+		}
+		if c.Val != tc.want {
+			t.Errorf("%q: want %q, got %q", tc.in, tc.want, c.Val)
+		}
+
+		// the matcher must be equivalent to the pattern
+		want := regexp.MustCompile("^(?:" + tc.in + ")$")
+		got := regexp.MustCompile("^" + c.ToRegex() + "$")
+		for rn := rune(0); rn < 0x2200; rn++ {
+			if s := string(rn); want.MatchString(s) != got.MatchString(s) {
+				t.Errorf("%q: %q does not match like %q", tc.in, s, c.ToRegex())
+				break
+			}
+		}
+	}
+
+	for _, in := range []string{"", "a", "ab", "a|b", "^[a]", "[a]$", "[a]+", "[a][b]", `\b`, "(?i)", "(?s)[a]", "[a", "[z-a]"} {
+		if _, err := NewCharClassMatcherFromRegex(in); err == nil {
+			t.Errorf("%q: want error, got nil", in)
+		}
+	}
+}
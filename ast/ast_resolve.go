@@ -0,0 +1,167 @@
+package ast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// errList cumulates the errors found while analyzing a grammar.
+type errList []error
+
+func (e *errList) add(p Pos, err error) {
+	*e = append(*e, fmt.Errorf("%s: %v", p, err))
+}
+
+func (e *errList) err() error {
+	if len(*e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *errList) Error() string {
+	switch len(*e) {
+	case 0:
+		return ""
+	case 1:
+		return (*e)[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range *e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// Resolve walks the grammar and binds every RuleRefExpr to the Rule it
+// references, so that tools can follow a reference without scanning
+// g.Rules each time. References to undefined rules are not part of the
+// returned map and are reported in the returned error, one per reference.
+func Resolve(g *Grammar) (map[*RuleRefExpr]*Rule, error) {
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+	}
+
+	errs := new(errList)
+	refs := make(map[*RuleRefExpr]*Rule)
+	Inspect(g, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok {
+			if r, ok := rules[ref.Name.Val]; ok {
+				refs[ref] = r
+			} else {
+				errs.add(ref.Pos(), fmt.Errorf("undefined rule: %s", ref.Name.Val))
+			}
+		}
+		return true
+	})
+	return refs, errs.err()
+}
+
+// CheckUndefinedRules returns an error for each reference to a rule that
+// is not defined in the grammar, at the position of the reference.
+func CheckUndefinedRules(g *Grammar) error {
+	_, err := Resolve(g)
+	return err
+}
+
+// CheckDuplicateRules returns an error for each rule of the grammar that
+// has the same name as a previous rule, citing the positions of both
+// definitions.
+func CheckDuplicateRules(g *Grammar) error {
+	errs := new(errList)
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		if first, ok := rules[r.Name.Val]; ok {
+			errs.add(r.Pos(), fmt.Errorf("duplicate rule: %s, first defined at %s", r.Name.Val, first.Pos()))
+			continue
+		}
+		rules[r.Name.Val] = r
+	}
+	return errs.err()
+}
+
+// CheckDuplicateLabels returns an error for each label that has the same
+// name as a previous label in the scope of the same ActionExpr, citing
+// the positions of both labels. The scope of an ActionExpr is its
+// sub-expression tree, excluding nested ActionExprs, and the alternatives
+// of a ChoiceExpr are checked independently of each other since only one
+// of them may match.
+func CheckDuplicateLabels(g *Grammar) error {
+	errs := new(errList)
+	Inspect(g, func(expr Expression) bool {
+		if act, ok := expr.(*ActionExpr); ok {
+			checkLabels(act.Expr, make(map[string]*LabeledExpr), func(dup, first *LabeledExpr) {
+				errs.add(dup.Pos(), fmt.Errorf("duplicate label: %s, first defined at %s", dup.Label.Val, first.Pos()))
+			})
+		}
+		return true
+	})
+	return errs.err()
+}
+
+// CheckLookbehinds returns an error for each lookbehind expression whose
+// expression may match a variable number of characters, at the position
+// of the lookbehind, see LookbehindLength.
+func CheckLookbehinds(g *Grammar) error {
+	errs := new(errList)
+	Inspect(g, func(expr Expression) bool {
+		if lb, ok := expr.(*LookbehindExpr); ok {
+			if _, ok := LookbehindLength(lb); !ok {
+				errs.add(lb.Pos(), errors.New("lookbehind must match a fixed number of characters"))
+			}
+		}
+		return true
+	})
+	return errs.err()
+}
+
+// checkLabels calls report for each label of expr that is already defined
+// in scope, with the labeled expressions of the duplicate and of the first
+// definition.
+func checkLabels(expr Expression, scope map[string]*LabeledExpr, report func(dup, first *LabeledExpr)) {
+	switch expr := expr.(type) {
+	case *AndExpr:
+		checkLabels(expr.Expr, scope, report)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			altScope := make(map[string]*LabeledExpr, len(scope))
+			for k, v := range scope {
+				altScope[k] = v
+			}
+			checkLabels(alt, altScope, report)
+		}
+	case *LabeledExpr:
+		if expr.Label != nil && expr.Label.Val != "" {
+			if first, ok := scope[expr.Label.Val]; ok {
+				report(expr, first)
+			} else {
+				scope[expr.Label.Val] = expr
+			}
+		}
+		checkLabels(expr.Expr, scope, report)
+	case *LookbehindExpr:
+		checkLabels(expr.Expr, scope, report)
+	case *NotExpr:
+		checkLabels(expr.Expr, scope, report)
+	case *OneOrMoreExpr:
+		checkLabels(expr.Expr, scope, report)
+	case *RecoveryExpr:
+		checkLabels(expr.Expr, scope, report)
+		checkLabels(expr.RecoverExpr, scope, report)
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			checkLabels(e, scope, report)
+		}
+	case *ZeroOrMoreExpr:
+		checkLabels(expr.Expr, scope, report)
+	case *ZeroOrOneExpr:
+		checkLabels(expr.Expr, scope, report)
+	}
+}
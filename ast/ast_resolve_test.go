@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	a := NewRuleRefExpr(Pos{Line: 1, Col: 5})
+	a.Name = NewIdentifier(Pos{}, "B")
+	b := NewRuleRefExpr(Pos{Line: 2, Col: 5})
+	b.Name = NewIdentifier(Pos{}, "C")
+	missing := NewRuleRefExpr(Pos{Line: 2, Col: 7})
+	missing.Name = NewIdentifier(Pos{}, "D")
+
+	seq := NewSeqExpr(Pos{})
+	seq.Exprs = []Expression{b, missing}
+
+	ruleA := NewRule(Pos{Line: 1}, NewIdentifier(Pos{}, "A"))
+	ruleA.Expr = a
+	ruleB := NewRule(Pos{Line: 2}, NewIdentifier(Pos{}, "B"))
+	ruleB.Expr = seq
+	ruleC := NewRule(Pos{Line: 3}, NewIdentifier(Pos{}, "C"))
+	ruleC.Expr = NewLitMatcher(Pos{}, "c")
+
+	g := NewGrammar(Pos{})
+	g.Rules = []*Rule{ruleA, ruleB, ruleC}
+
+	refs, err := Resolve(g)
+	if err == nil {
+		t.Fatal("want error for undefined rule, got nil")
+	}
+	if !strings.Contains(err.Error(), "2:7 (0): undefined rule: D") {
+		t.Errorf("want undefined rule error for D, got %v", err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("want 2 resolved references, got %d", len(refs))
+	}
+	if refs[a] != ruleB {
+		t.Errorf("want reference to B resolved to rule B, got %v", refs[a])
+	}
+	if refs[b] != ruleC {
+		t.Errorf("want reference to C resolved to rule C, got %v", refs[b])
+	}
+	if _, ok := refs[missing]; ok {
+		t.Errorf("want reference to D unresolved")
+	}
+
+	seq.Exprs = seq.Exprs[:1]
+	if _, err := Resolve(g); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+func TestCheckDuplicateRules(t *testing.T) {
+	ruleA := NewRule(Pos{Line: 1, Col: 1}, NewIdentifier(Pos{}, "Foo"))
+	ruleA.Expr = NewLitMatcher(Pos{}, "a")
+	ruleB := NewRule(Pos{Line: 2, Col: 1}, NewIdentifier(Pos{}, "Bar"))
+	ruleB.Expr = NewLitMatcher(Pos{}, "b")
+
+	g := NewGrammar(Pos{})
+	g.Rules = []*Rule{ruleA, ruleB}
+	if err := CheckDuplicateRules(g); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+
+	ruleC := NewRule(Pos{Line: 3, Col: 1, Off: 20}, NewIdentifier(Pos{}, "Foo"))
+	ruleC.Expr = NewLitMatcher(Pos{}, "c")
+	g.Rules = append(g.Rules, ruleC)
+	err := CheckDuplicateRules(g)
+	if err == nil {
+		t.Fatal("want error for duplicate rule, got nil")
+	}
+	if want := "3:1 (20): duplicate rule: Foo, first defined at 1:1 (0)"; err.Error() != want {
+		t.Errorf("want %q, got %q", want, err)
+	}
+}
+
+func TestCheckUndefinedRules(t *testing.T) {
+	self := NewRuleRefExpr(Pos{Line: 1, Col: 7, Off: 6})
+	self.Name = NewIdentifier(Pos{}, "Foo")
+	missing := NewRuleRefExpr(Pos{Line: 1, Col: 11, Off: 10})
+	missing.Name = NewIdentifier(Pos{}, "Bar")
+
+	seq := NewSeqExpr(Pos{})
+	seq.Exprs = []Expression{self, missing}
+	ruleA := NewRule(Pos{Line: 1, Col: 1}, NewIdentifier(Pos{}, "Foo"))
+	ruleA.Expr = seq
+
+	g := NewGrammar(Pos{})
+	g.Rules = []*Rule{ruleA}
+	err := CheckUndefinedRules(g)
+	if err == nil {
+		t.Fatal("want error for undefined rule, got nil")
+	}
+	if want := "1:11 (10): undefined rule: Bar"; err.Error() != want {
+		t.Errorf("want %q, got %q", want, err)
+	}
+
+	ruleB := NewRule(Pos{Line: 2, Col: 1}, NewIdentifier(Pos{}, "Bar"))
+	ruleB.Expr = NewLitMatcher(Pos{}, "b")
+	g.Rules = append(g.Rules, ruleB)
+	if err := CheckUndefinedRules(g); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+func TestCheckLookbehinds(t *testing.T) {
+	cases := []struct {
+		expr Expression
+		n    int
+		ok   bool
+	}{
+		{Lit("ab"), 2, true},
+		{Lit("ça"), 2, true},
+		{LitI("ab"), 2, true},
+		{Lit(""), 0, true},
+		{Any(), 1, true},
+		{Class([2]rune{'a', 'z'}), 1, true},
+		{Seq(Lit("a"), Any(), Lit("bc")), 4, true},
+		{Choice(Lit("ab"), Seq(Any(), Lit("é"))), 2, true},
+		{Choice(Lit("ab"), Lit("a")), 0, false},
+		{Optional(Lit("a")), 0, false},
+		{OneOrMore(Lit("a")), 0, false},
+		{Ref("A"), 0, false},
+		{Seq(Lit("a"), Label("x", Lit("b"))), 0, false},
+	}
+	for i, c := range cases {
+		lb := NewLookbehindExpr(Pos{Line: 1, Col: 5, Off: 4}, true)
+		lb.Expr = c.expr
+		n, ok := LookbehindLength(lb)
+		if n != c.n || ok != c.ok {
+			t.Errorf("%d: want %d, %t, got %d, %t", i, c.n, c.ok, n, ok)
+		}
+
+		rule := NewRule(Pos{Line: 1, Col: 1}, NewIdentifier(Pos{}, "A"))
+		rule.Expr = Seq(lb, Lit("c"))
+		g := NewGrammar(Pos{})
+		g.Rules = []*Rule{rule}
+		err := CheckLookbehinds(g)
+		if c.ok {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+			continue
+		}
+		if want := "1:5 (4): lookbehind must match a fixed number of characters"; err == nil || err.Error() != want {
+			t.Errorf("%d: want %q, got %v", i, want, err)
+		}
+	}
+}
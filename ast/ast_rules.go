@@ -0,0 +1,248 @@
+package ast
+
+import "fmt"
+
+// RuleByName returns the rule of the grammar named name, and false if
+// there is no such rule. The rules are looked up in a map that is built on
+// the first call and invalidated by AddRule, RemoveRule, RenameRule,
+// SwapRules and SortRules. If there are duplicate rules, the first one is
+// returned.
+//
+// The map is also rebuilt if the number of rules changed since it was
+// built, or if the rule found was renamed, so that it tolerates the
+// direct modifications of Rules by e.g. the optimizer, but the other direct
+// modifications of Rules, such as replacing a rule by another, are not
+// detected.
+func (g *Grammar) RuleByName(name string) (*Rule, bool) {
+	if g.ruleByName == nil || g.ruleCount != len(g.Rules) {
+		g.buildRuleByName()
+	}
+	r, ok := g.ruleByName[name]
+	if ok && (r.Name == nil || r.Name.Val != name) {
+		g.buildRuleByName()
+		r, ok = g.ruleByName[name]
+	}
+	return r, ok
+}
+
+// RuleIndex returns the index in Rules of each rule of the grammar, by
+// name, e.g. to locate a rule before replacing or moving it. If there are
+// duplicate rules, the index of the first one is returned. The index is
+// cached and invalidated like the map of RuleByName: it is rebuilt if the
+// number of rules changed or if a rule was renamed or moved by a direct
+// modification of Rules. The returned map is a copy of the cached index.
+func (g *Grammar) RuleIndex() map[string]int {
+	if g.ruleIndex == nil || g.ruleCount != len(g.Rules) || !g.validRuleIndex() {
+		g.buildRuleByName()
+	}
+	idx := make(map[string]int, len(g.ruleIndex))
+	for name, i := range g.ruleIndex {
+		idx[name] = i
+	}
+	return idx
+}
+
+// validRuleIndex returns true if each rule of the cached index is still
+// at its index in Rules under the same name.
+func (g *Grammar) validRuleIndex() bool {
+	for name, i := range g.ruleIndex {
+		if r := g.Rules[i]; r.Name == nil || r.Name.Val != name {
+			return false
+		}
+	}
+	return true
+}
+
+// SwapRules swaps the rules at the indexes i and j of Rules. Note that
+// swapping the first rule changes the entry rule of the grammar.
+func (g *Grammar) SwapRules(i, j int) {
+	g.Rules[i], g.Rules[j] = g.Rules[j], g.Rules[i]
+	g.invalidateRuleByName()
+}
+
+// buildRuleByName builds the maps of the rules and of their index by name.
+func (g *Grammar) buildRuleByName() {
+	g.ruleByName = make(map[string]*Rule, len(g.Rules))
+	g.ruleIndex = make(map[string]int, len(g.Rules))
+	g.ruleCount = len(g.Rules)
+	for i, r := range g.Rules {
+		if r.Name == nil {
+			continue
+		}
+		if _, ok := g.ruleByName[r.Name.Val]; !ok {
+			g.ruleByName[r.Name.Val] = r
+			g.ruleIndex[r.Name.Val] = i
+		}
+	}
+}
+
+// invalidateRuleByName invalidates the maps of the rules by name.
+func (g *Grammar) invalidateRuleByName() {
+	g.ruleByName = nil
+	g.ruleIndex = nil
+}
+
+// AddRule adds the rule r to the grammar, after the existing rules. It
+// returns an error if the grammar already has a rule with the same name.
+func (g *Grammar) AddRule(r *Rule) error {
+	if _, ok := g.RuleByName(r.Name.Val); ok {
+		return fmt.Errorf("%s: rule %q already defined", r.Pos(), r.Name.Val)
+	}
+	g.Rules = append(g.Rules, r)
+	g.invalidateRuleByName()
+	return nil
+}
+
+// RemoveRule removes the rule named name from the grammar and returns it,
+// or returns nil if there is no such rule. The references to the rule are
+// left untouched.
+func (g *Grammar) RemoveRule(name string) *Rule {
+	for i, r := range g.Rules {
+		if r.Name != nil && r.Name.Val == name {
+			g.Rules = append(g.Rules[:i], g.Rules[i+1:]...)
+			g.invalidateRuleByName()
+			return r
+		}
+	}
+	return nil
+}
+
+// RenameRule renames the rule named from to the name to, and updates the
+// references to the rule accordingly. It returns an error if there is no
+// rule named from or if there is already a rule named to.
+func (g *Grammar) RenameRule(from, to string) error {
+	r, ok := g.RuleByName(from)
+	if !ok {
+		return fmt.Errorf("rule %q not defined", from)
+	}
+	if from == to {
+		return nil
+	}
+	if _, ok := g.RuleByName(to); ok {
+		return fmt.Errorf("rule %q already defined", to)
+	}
+
+	r.Name = NewIdentifier(r.Name.Pos(), to)
+	Inspect(g, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok && ref.Name != nil && ref.Name.Val == from {
+			ref.Name = NewIdentifier(ref.Name.Pos(), to)
+		}
+		return true
+	})
+	g.invalidateRuleByName()
+	return nil
+}
+
+// SubGrammar returns a new grammar with the rule named entryRule, as its
+// first rule, and the rules that are reachable from it through rule
+// references, in the order of the grammar. The rules are deep copies, so
+// that the grammar g is not affected by the modifications of the returned
+// grammar, but the initializer and the charsets are shared. It returns an
+// error if there is no rule named entryRule.
+func (g *Grammar) SubGrammar(entryRule string) (*Grammar, error) {
+	entry, ok := g.RuleByName(entryRule)
+	if !ok {
+		return nil, fmt.Errorf("rule %q not defined", entryRule)
+	}
+
+	adj := g.ToAdjacencyList()
+	reachable := map[string]bool{entryRule: true}
+	queue := []string{entryRule}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, ref := range adj[name] {
+			if !reachable[ref] {
+				reachable[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	sub := NewGrammar(g.p)
+	sub.Init = g.Init
+	sub.Charsets = g.Charsets
+	sub.Rules = append(sub.Rules, cloneRule(entry))
+	// only the first of duplicate rules is kept
+	reachable[entryRule] = false
+	for _, r := range g.Rules {
+		if r.Name != nil && reachable[r.Name.Val] {
+			sub.Rules = append(sub.Rules, cloneRule(r))
+			reachable[r.Name.Val] = false
+		}
+	}
+	return sub, nil
+}
+
+// cloneRule returns a deep copy of the rule r.
+func cloneRule(r *Rule) *Rule {
+	return &Rule{
+		p:           r.p,
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Expr:        cloneExpr(r.Expr),
+		Longest:     r.Longest,
+		ResultType:  r.ResultType,
+	}
+}
+
+// Clone returns a deep copy of expr, which may be a rule but not a
+// grammar, so that the copy may be modified without changing expr.
+func Clone(expr Expression) Expression {
+	if r, ok := expr.(*Rule); ok {
+		return cloneRule(r)
+	}
+	return cloneExpr(expr)
+}
+
+// Copy returns a deep copy of the rule r named newName, e.g. to derive a
+// variant of the rule without changing it. The references of the rule to
+// itself are renamed too, so that the copy references the copy.
+func (r *Rule) Copy(newName string) *Rule {
+	cp := cloneRule(r)
+	cp.Name = NewIdentifier(r.Name.Pos(), newName)
+	Inspect(cp.Expr, func(expr Expression) bool {
+		if ref, ok := expr.(*RuleRefExpr); ok && ref.Name.Val == r.Name.Val {
+			ref.Name = NewIdentifier(ref.Name.Pos(), newName)
+		}
+		return true
+	})
+	return cp
+}
+
+// Inline replaces each reference to the rule named name by a copy of the
+// expression of the rule, including its actions, and removes the rule from
+// the grammar. It returns an error if there is no such rule, if it is the
+// entry rule of the grammar or if it is recursive. Note that the labels of
+// the inlined expression are then in the scope of the code blocks of the
+// rules where it is inlined (see CheckDuplicateLabels).
+func (g *Grammar) Inline(name string) error {
+	r, ok := g.RuleByName(name)
+	if !ok {
+		return fmt.Errorf("rule %q not defined", name)
+	}
+	if r == g.Rules[0] {
+		return fmt.Errorf("%s: cannot inline the entry rule %q", r.Pos(), name)
+	}
+	if isRecursive(name, g.ToAdjacencyList()) {
+		return fmt.Errorf("%s: cannot inline the recursive rule %q", r.Pos(), name)
+	}
+
+	g.RemoveRule(name)
+	Walk(&ruleInliner{name: name, expr: r.Expr}, g)
+	return nil
+}
+
+// ruleInliner is the Visitor of Inline.
+type ruleInliner struct {
+	name string
+	expr Expression
+}
+
+func (v *ruleInliner) Visit(expr Expression, br Backref) Visitor {
+	if ref, ok := expr.(*RuleRefExpr); ok && ref.Name != nil && ref.Name.Val == v.name {
+		br.Replace(cloneExpr(v.expr))
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,294 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestRuleByName(t *testing.T) {
+	var zero ast.Grammar
+	if r, ok := zero.RuleByName("A"); ok || r != nil {
+		t.Errorf("zero grammar: want no rule, got %v", r)
+	}
+
+	g := parseGrammar(t, graphGrammar)
+	r, ok := g.RuleByName("Term")
+	if !ok || r != g.Rules[2] {
+		t.Fatalf("want rule Term, got %v", r)
+	}
+	if _, ok := g.RuleByName("Nope"); ok {
+		t.Error("want no rule Nope")
+	}
+
+	// direct modification of the rules
+	g.Rules = g.Rules[:4]
+	if _, ok := g.RuleByName("EOF"); ok {
+		t.Error("want no rule EOF after truncation")
+	}
+}
+
+func TestRuleIndex(t *testing.T) {
+	var zero ast.Grammar
+	if idx := zero.RuleIndex(); len(idx) != 0 {
+		t.Errorf("zero grammar: want no index, got %v", idx)
+	}
+
+	g := parseGrammar(t, graphGrammar)
+	want := map[string]int{"Start": 0, "Expr": 1, "Term": 2, "Num": 3, "EOF": 4}
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want index %v, got %v", want, got)
+	}
+
+	g.SwapRules(1, 3)
+	if r, ok := g.RuleByName("Num"); !ok || r != g.Rules[1] {
+		t.Errorf("want rule Num at index 1, got %v", g.Rules[1])
+	}
+	want["Expr"], want["Num"] = 3, 1
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after swap: want index %v, got %v", want, got)
+	}
+
+	g.SortRules(ast.AlphaOrder)
+	want = map[string]int{"Start": 0, "EOF": 1, "Expr": 2, "Num": 3, "Term": 4}
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after sort: want index %v, got %v", want, got)
+	}
+
+	g.RemoveRule("EOF")
+	want = map[string]int{"Start": 0, "Expr": 1, "Num": 2, "Term": 3}
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after remove: want index %v, got %v", want, got)
+	}
+
+	// direct modifications of the rules
+	g.Rules[0].Name = ast.NewIdentifier(g.Rules[0].Name.Pos(), "Begin")
+	want = map[string]int{"Begin": 0, "Expr": 1, "Num": 2, "Term": 3}
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after rename: want index %v, got %v", want, got)
+	}
+	g.Rules[1], g.Rules[2] = g.Rules[2], g.Rules[1]
+	want["Expr"], want["Num"] = 2, 1
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after move: want index %v, got %v", want, got)
+	}
+
+	// the returned map is a copy
+	g.RuleIndex()["Begin"] = 3
+	if got := g.RuleIndex(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after modification of the map: want index %v, got %v", want, got)
+	}
+}
+
+func TestAddRemoveRule(t *testing.T) {
+	var g ast.Grammar
+	a := ast.NewRule(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "A"))
+	if err := g.AddRule(a); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := g.RuleByName("A"); !ok || r != a {
+		t.Fatalf("want rule A, got %v", r)
+	}
+	if err := g.AddRule(ast.NewRule(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "A"))); err == nil {
+		t.Error("want error for duplicate rule")
+	}
+
+	if r := g.RemoveRule("A"); r != a {
+		t.Errorf("want removed rule A, got %v", r)
+	}
+	if _, ok := g.RuleByName("A"); ok {
+		t.Error("want no rule A after removal")
+	}
+	if r := g.RemoveRule("A"); r != nil {
+		t.Errorf("want no removed rule, got %v", r)
+	}
+}
+
+func TestRenameRule(t *testing.T) {
+	g := parseGrammar(t, graphGrammar)
+	if _, ok := g.RuleByName("Term"); !ok {
+		t.Fatal("want rule Term")
+	}
+	if err := g.RenameRule("Term", "Factor"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.RuleByName("Term"); ok {
+		t.Error("want no rule Term after rename")
+	}
+	if r, ok := g.RuleByName("Factor"); !ok || r != g.Rules[2] {
+		t.Errorf("want rule Factor, got %v", r)
+	}
+	want := map[string][]string{
+		"Start":  {"Expr", "EOF"},
+		"Expr":   {"Factor"},
+		"Factor": {"Expr", "Num"},
+		"Num":    nil,
+		"EOF":    nil,
+	}
+	if got := g.ToAdjacencyList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	if err := g.RenameRule("Nope", "X"); err == nil {
+		t.Error("want error for undefined rule")
+	}
+	if err := g.RenameRule("Num", "Expr"); err == nil {
+		t.Error("want error for existing rule")
+	}
+}
+
+func TestSubGrammar(t *testing.T) {
+	g := parseGrammar(t, graphGrammar)
+	cases := []struct {
+		entry string
+		want  []string
+	}{
+		{"Start", []string{"Start", "Expr", "Term", "Num", "EOF"}},
+		{"Term", []string{"Term", "Expr", "Num"}},
+		{"Num", []string{"Num"}},
+		{"EOF", []string{"EOF"}},
+	}
+	for _, c := range cases {
+		sub, err := g.SubGrammar(c.entry)
+		if err != nil {
+			t.Errorf("%s: %v", c.entry, err)
+			continue
+		}
+		var got []string
+		for _, r := range sub.Rules {
+			got = append(got, r.Name.Val)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: want rules %v, got %v", c.entry, c.want, got)
+		}
+	}
+
+	if _, err := g.SubGrammar("Nope"); err == nil {
+		t.Error("want error for undefined rule")
+	}
+}
+
+func TestSubGrammarClone(t *testing.T) {
+	g := parseGrammar(t, graphGrammar)
+	want := g.String()
+
+	sub, err := g.SubGrammar("Expr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ast.Equal(sub.Rules[0], g.Rules[1]) {
+		t.Errorf("want rule Expr to be copied, got %v", sub.Rules[0])
+	}
+	for i, r := range sub.Rules {
+		for _, orig := range g.Rules {
+			if r == orig || r.Expr == orig.Expr {
+				t.Fatalf("%d: want a copy of rule %s", i, r.Name.Val)
+			}
+		}
+	}
+
+	if err := sub.RenameRule("Term", "Factor"); err != nil {
+		t.Fatal(err)
+	}
+	sub.Rules[0].Expr.(*ast.SeqExpr).Exprs[0] = ast.Lit("x")
+	if got := g.String(); got != want {
+		t.Errorf("want original grammar\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestInline(t *testing.T) {
+	g := parseGrammar(t, `Start = Expr EOF
+Expr = Term ("+" Term)*
+Term = n:Num { return n, nil } / "(" Expr ")"
+Num = [0-9]+ { return string(c.text), nil }
+EOF = !.`)
+	if err := g.Inline("Num"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.RuleByName("Num"); ok {
+		t.Error("want no rule Num after inlining")
+	}
+	want := parseGrammar(t, `Start = Expr EOF
+Expr = Term ("+" Term)*
+Term = n:([0-9]+ { return string(c.text), nil }) { return n, nil } / "(" Expr ")"
+EOF = !.`)
+	if !g.EqualsIgnorePositions(want) {
+		t.Errorf("want\n%s\ngot\n%s", want, g)
+	}
+
+	// Term is recursive through Expr
+	if err := g.Inline("Term"); err == nil {
+		t.Error("want error for recursive rule")
+	}
+	if _, ok := g.RuleByName("Term"); !ok {
+		t.Error("want rule Term to be kept")
+	}
+
+	if err := g.Inline("Nope"); err == nil {
+		t.Error("want error for undefined rule")
+	}
+	if err := g.Inline("Start"); err == nil {
+		t.Error("want error for the entry rule")
+	}
+
+	// each reference has its own copy
+	g = parseGrammar(t, `A = B "," B
+B = "b" { return 1, nil }`)
+	if err := g.Inline("B"); err != nil {
+		t.Fatal(err)
+	}
+	seq := g.Rules[0].Expr.(*ast.SeqExpr)
+	if first, last := seq.Exprs[0], seq.Exprs[2]; first == last || !ast.Equal(first, last) {
+		t.Errorf("want equal copies, got %v and %v", first, last)
+	}
+	if len(g.Rules) != 1 {
+		t.Errorf("want 1 rule, got %d", len(g.Rules))
+	}
+}
+
+func TestRuleCopy(t *testing.T) {
+	g := parseGrammar(t, `
+Expr = "(" Expr ")" / e:Term { return e, nil }
+Term = "x"
+`)
+	want := g.String()
+
+	cp := g.Rules[0].Copy("Paren")
+	if err := g.AddRule(cp); err != nil {
+		t.Fatal(err)
+	}
+	var refs []string
+	ast.Inspect(cp, func(expr ast.Expression) bool {
+		if ref, ok := expr.(*ast.RuleRefExpr); ok {
+			refs = append(refs, ref.Name.Val)
+		}
+		return true
+	})
+	if wantRefs := []string{"Paren", "Term"}; cp.Name.Val != "Paren" || !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("want rule Paren with references %v, got %s with %v", wantRefs, cp.Name.Val, refs)
+	}
+
+	g.RemoveRule("Paren")
+	if got := g.String(); got != want {
+		t.Errorf("want original grammar\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	expr := ast.Seq(ast.Lit("a"), ast.ZeroOrMore(ast.Choice(ast.Ref("B"), ast.Class([2]rune{'0', '9'}))))
+	cp := ast.Clone(expr).(*ast.SeqExpr)
+	if cp == expr || !ast.Equal(cp, expr) {
+		t.Fatalf("want an equal copy, got %v", cp)
+	}
+	cp.Exprs[1].(*ast.ZeroOrMoreExpr).Expr.(*ast.ChoiceExpr).Alternatives[0] = ast.Lit("b")
+	if ast.Equal(cp, expr) {
+		t.Errorf("want the original expression unchanged, got %v", expr)
+	}
+
+	r := ast.NewRule(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "A"))
+	r.Expr = expr
+	if cr := ast.Clone(r).(*ast.Rule); cr == r || cr.Expr == r.Expr || !ast.Equal(cr, r) {
+		t.Errorf("want a copy of the rule, got %v", cr)
+	}
+}
@@ -0,0 +1,118 @@
+package ast
+
+import "fmt"
+
+// InsertSpacing transforms the grammar so that the rule named spacing,
+// typically a rule such as `_ = [ \t\r\n]*`, is automatically matched
+// after every token of the token-skipping rules. The tokens are the
+// LitMatcher, CharClassMatcher and AnyMatcher expressions and the
+// references to lexical rules, optionally labeled. All rules except the
+// spacing rule and the lexical rules are token-skipping rules.
+//
+// The lexical rules are left untouched: they are meant for the tokens
+// where whitespace is significant, e.g. `Number = [0-9]+`, and the spacing
+// is matched after them where they are referenced instead. Whitespace that
+// precedes the first token of the input is not skipped, so the start rule
+// should begin with an explicit reference to the spacing rule.
+//
+// The spacing rule is inserted after a token that is an element of a
+// SeqExpr, and a token that is not is replaced with a SeqExpr of the token
+// and the spacing rule, so that the value of a labeled token is preserved.
+// Note that this changes the number of elements of the SeqExpr values.
+//
+// It returns an error if the spacing rule or one of the lexical rules is
+// not defined in the grammar.
+func InsertSpacing(g *Grammar, spacing string, lexical ...string) error {
+	s := &spacer{
+		name:    spacing,
+		lexical: make(map[string]bool, len(lexical)+1),
+	}
+	defined := make(map[string]bool, len(g.Rules))
+	for _, r := range g.Rules {
+		defined[r.Name.Val] = true
+	}
+	for _, nm := range append([]string{spacing}, lexical...) {
+		if !defined[nm] {
+			return fmt.Errorf("undefined rule: %s", nm)
+		}
+		s.lexical[nm] = true
+	}
+
+	for _, r := range g.Rules {
+		if s.lexical[r.Name.Val] {
+			continue
+		}
+		r.Expr = s.expr(r.Expr)
+	}
+	return nil
+}
+
+// spacer inserts the references to the spacing rule.
+type spacer struct {
+	name    string
+	lexical map[string]bool
+}
+
+// isToken returns true if expr is a token that must be followed by the
+// spacing rule.
+func (s *spacer) isToken(expr Expression) bool {
+	switch expr := expr.(type) {
+	case *AnyMatcher, *CharClassMatcher, *KeywordMatcher, *LitMatcher, *RegexpMatcher:
+		return true
+	case *LabeledExpr:
+		return s.isToken(expr.Expr)
+	case *RuleRefExpr:
+		return expr.Name.Val != s.name && s.lexical[expr.Name.Val]
+	}
+	return false
+}
+
+// ref returns a new reference to the spacing rule, at the position of
+// the token expr.
+func (s *spacer) ref(expr Expression) *RuleRefExpr {
+	ref := NewRuleRefExpr(expr.Pos())
+	ref.Name = NewIdentifier(expr.Pos(), s.name)
+	return ref
+}
+
+// expr returns expr with the spacing rule inserted after its tokens.
+func (s *spacer) expr(expr Expression) Expression {
+	if s.isToken(expr) {
+		seq := NewSeqExpr(expr.Pos())
+		seq.Exprs = []Expression{expr, s.ref(expr)}
+		return seq
+	}
+
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		expr.Expr = s.expr(expr.Expr)
+	case *ChoiceExpr:
+		for i, alt := range expr.Alternatives {
+			expr.Alternatives[i] = s.expr(alt)
+		}
+	case *LabeledExpr:
+		expr.Expr = s.expr(expr.Expr)
+	case *OneOrMoreExpr:
+		expr.Expr = s.expr(expr.Expr)
+	case *RecoveryExpr:
+		expr.Expr = s.expr(expr.Expr)
+		expr.RecoverExpr = s.expr(expr.RecoverExpr)
+	case *SeqExpr:
+		exprs := make([]Expression, 0, len(expr.Exprs))
+		for _, sub := range expr.Exprs {
+			if s.isToken(sub) {
+				exprs = append(exprs, sub, s.ref(sub))
+				continue
+			}
+			exprs = append(exprs, s.expr(sub))
+		}
+		expr.Exprs = exprs
+	case *ZeroOrMoreExpr:
+		expr.Expr = s.expr(expr.Expr)
+	case *ZeroOrOneExpr:
+		expr.Expr = s.expr(expr.Expr)
+	}
+	// the predicates do not consume any input, and the other expressions
+	// have no sub-expression.
+	return expr
+}
@@ -0,0 +1,72 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+// describe returns a compact representation of the structure of expr.
+func describe(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.AnyMatcher:
+		return expr.Val
+	case *ast.CharClassMatcher:
+		return expr.Val
+	case *ast.ChoiceExpr:
+		var alts []string
+		for _, alt := range expr.Alternatives {
+			alts = append(alts, describe(alt))
+		}
+		return "(" + strings.Join(alts, " / ") + ")"
+	case *ast.LabeledExpr:
+		return expr.Label.Val + ":" + describe(expr.Expr)
+	case *ast.LitMatcher:
+		return fmt.Sprintf("%q", expr.Val)
+	case *ast.NotExpr:
+		return "!" + describe(expr.Expr)
+	case *ast.RuleRefExpr:
+		return expr.Name.Val
+	case *ast.SeqExpr:
+		var exprs []string
+		for _, e := range expr.Exprs {
+			exprs = append(exprs, describe(e))
+		}
+		return "(" + strings.Join(exprs, " ") + ")"
+	case *ast.ZeroOrMoreExpr:
+		return describe(expr.Expr) + "*"
+	case *ast.ZeroOrOneExpr:
+		return describe(expr.Expr) + "?"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func TestInsertSpacing(t *testing.T) {
+	g := parseGrammar(t, `
+A = _ x:'a' B ('b' / [c])* !. / 'd'
+B = 'b' 'b'
+_ = [ ]*
+`)
+	if err := ast.InsertSpacing(g, "_", "B"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`((_ x:"a" _ B _ (("b" _) / ([c] _))* !.) / ("d" _))`,
+		`("b" "b")`,
+		`[ ]*`,
+	}
+	for i, r := range g.Rules {
+		if got := describe(r.Expr); got != want[i] {
+			t.Errorf("%s: want %s, got %s", r.Name.Val, want[i], got)
+		}
+	}
+
+	for _, args := range [][]string{{"S"}, {"_", "C"}} {
+		err := ast.InsertSpacing(g, args[0], args[1:]...)
+		if err == nil || !strings.HasPrefix(err.Error(), "undefined rule: ") {
+			t.Errorf("%v: want undefined rule error, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExpressionStats holds statistics about a tree of expressions.
+type ExpressionStats struct {
+	// Expressions is the total number of expressions in the tree,
+	// including its root.
+	Expressions int
+	// ByType is the number of expressions of each type, as returned by
+	// CountByType.
+	ByType map[string]int
+	// MaxDepth is the maximum nesting depth of the tree, 1 for a tree
+	// made of only its root.
+	MaxDepth int
+}
+
+// NewExpressionStats returns the statistics of the tree of expressions
+// rooted at expr.
+func NewExpressionStats(expr Expression) ExpressionStats {
+	s := ExpressionStats{ByType: CountByType(expr)}
+	for _, n := range s.ByType {
+		s.Expressions += n
+	}
+	Walk(depthVisitor{depth: 1, max: &s.MaxDepth}, expr)
+	return s
+}
+
+// depthVisitor records the maximum depth of the visited expressions.
+type depthVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v depthVisitor) Visit(expr Expression, br Backref) Visitor {
+	if v.depth > *v.max {
+		*v.max = v.depth
+	}
+	return depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+// DepthHistogram returns the number of expressions of the grammar at each
+// nesting depth, from the rules, at depth 1, to the deepest expressions.
+// The grammar itself is not counted.
+func DepthHistogram(g *Grammar) map[int]int {
+	hist := make(map[int]int)
+	var visitAt func(depth int) Visitor
+	visitAt = func(depth int) Visitor {
+		return visitorFunc(func(expr Expression, br Backref) Visitor {
+			if depth > 0 {
+				hist[depth]++
+			}
+			return visitAt(depth + 1)
+		})
+	}
+	Walk(visitAt(0), g)
+	return hist
+}
+
+// visitorFunc is a function that implements Visitor.
+type visitorFunc func(expr Expression, br Backref) Visitor
+
+func (f visitorFunc) Visit(expr Expression, br Backref) Visitor {
+	return f(expr, br)
+}
+
+// InstrumentedVisitor counts the calls to the Visit method of a Visitor
+// by expression type, e.g. to find the types of expressions that dominate
+// the traversal of a large grammar. The types are named as in
+// CountByType. The final calls with a nil expression are not counted.
+type InstrumentedVisitor struct {
+	counts map[string]int
+}
+
+// NewInstrumentedVisitor wraps inner so that its calls to Visit are
+// counted. It returns the InstrumentedVisitor that holds the counts and
+// the Visitor to use for the walk, which also wraps the visitors returned
+// by inner for the children.
+func NewInstrumentedVisitor(inner Visitor) (*InstrumentedVisitor, Visitor) {
+	iv := &InstrumentedVisitor{counts: make(map[string]int)}
+	return iv, countingVisitor{inner: inner, iv: iv}
+}
+
+// Counts returns the number of calls to Visit by expression type.
+func (iv *InstrumentedVisitor) Counts() map[string]int {
+	counts := make(map[string]int, len(iv.counts))
+	for k, v := range iv.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// countingVisitor counts the calls to the Visit method of inner in iv.
+type countingVisitor struct {
+	inner Visitor
+	iv    *InstrumentedVisitor
+}
+
+func (v countingVisitor) Visit(expr Expression, br Backref) Visitor {
+	if expr != nil {
+		v.iv.counts[typeName(expr)]++
+	}
+	w := v.inner.Visit(expr, br)
+	if w == nil {
+		return nil
+	}
+	return countingVisitor{inner: w, iv: v.iv}
+}
+
+// The estimated number of lines of generated code of the expressions, see
+// ExpressionSize.
+const (
+	// leafSize is the size of a matcher or of a rule reference.
+	leafSize = 1
+	// nodeSize is the overhead of an expression that has sub-expressions.
+	nodeSize = 2
+	// codeSize is the overhead of the function and the method generated
+	// for a code block, in addition to the lines of the code block.
+	codeSize = 8
+)
+
+// ExpressionSize returns an estimate of the number of lines of Go code
+// generated for expr and its sub-expressions. It is a fast approximation
+// meant to compare expressions, e.g. to decide whether an expression is
+// worth inlining, not a precise count: the matchers and rule references
+// count for 1 line, the other expressions add a small overhead to the sum
+// of the sizes of their sub-expressions, and the code blocks count for
+// the lines of their code in addition to the generated functions.
+func ExpressionSize(expr Expression) int {
+	switch expr := expr.(type) {
+	case nil:
+		return 0
+	case *ActionExpr:
+		return nodeSize + codeBlockSize(expr.Code) + ExpressionSize(expr.Expr)
+	case *AndCodeExpr:
+		return nodeSize + codeBlockSize(expr.Code)
+	case *AndExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *AnyMatcher, *BOLMatcher, *CharClassMatcher, *KeywordMatcher, *LitMatcher, *RegexpMatcher, *RuleRefExpr, *ThrowExpr:
+		return leafSize
+	case *ChoiceExpr:
+		return nodeSize + expressionsSize(expr.Alternatives)
+	case *Grammar:
+		n := 0
+		for _, r := range expr.Rules {
+			n += ExpressionSize(r)
+		}
+		return n
+	case *LabeledExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *LookbehindExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *NotCodeExpr:
+		return nodeSize + codeBlockSize(expr.Code)
+	case *NotExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *OneOrMoreExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *RecoveryExpr:
+		return nodeSize + len(expr.Labels) + ExpressionSize(expr.Expr) + ExpressionSize(expr.RecoverExpr)
+	case *Rule:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *SeqExpr:
+		return nodeSize + expressionsSize(expr.Exprs)
+	case *StateCodeExpr:
+		return nodeSize + codeBlockSize(expr.Code)
+	case *ZeroOrMoreExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	case *ZeroOrOneExpr:
+		return nodeSize + ExpressionSize(expr.Expr)
+	}
+	return leafSize
+}
+
+// expressionsSize returns the sum of the sizes of exprs.
+func expressionsSize(exprs []Expression) int {
+	n := 0
+	for _, e := range exprs {
+		n += ExpressionSize(e)
+	}
+	return n
+}
+
+// codeBlockSize returns the size of the code generated for the code block.
+func codeBlockSize(code *CodeBlock) int {
+	if code == nil {
+		return codeSize
+	}
+	return codeSize + strings.Count(strings.TrimSpace(code.Val), "\n") + 1
+}
+
+// GrammarStats holds statistics about a grammar, to help decide whether
+// the generated parser should be built with memoization (packrat parsing).
+type GrammarStats struct {
+	ExpressionStats
+
+	// Rules is the number of rules of the grammar.
+	Rules int
+	// LeftRecursiveRules is the sorted list of the rules that may
+	// (directly or indirectly) invoke themselves without consuming any
+	// input.
+	LeftRecursiveRules []string
+	// MemoizationCandidates is the sorted list of the rules that may be
+	// invoked at the same position by more than one alternative of a
+	// choice expression, and would thus be parsed again on backtracking
+	// without memoization.
+	MemoizationCandidates []string
+	// EstimatedStates is an estimate of the number of parser states, that
+	// is the number of expressions of the rules that the parser may have to
+	// try at each position of the input. It is an upper bound of the number
+	// of entries per position in the memoization table.
+	EstimatedStates int
+}
+
+// Stats returns the statistics of the grammar.
+func (g *Grammar) Stats() GrammarStats {
+	s := GrammarStats{
+		ExpressionStats: NewExpressionStats(g),
+		Rules:           len(g.Rules),
+	}
+	s.EstimatedStates = s.Expressions - s.ByType["Grammar"] - s.ByType["Rule"]
+
+	a := newGrammarAnalyzer(g)
+	left := make(map[string][]string, len(g.Rules))
+	for _, r := range g.Rules {
+		left[r.Name.Val] = setKeys(a.leftRefs(r.Expr))
+	}
+	for _, r := range g.Rules {
+		if isRecursive(r.Name.Val, left) {
+			s.LeftRecursiveRules = append(s.LeftRecursiveRules, r.Name.Val)
+		}
+	}
+	sort.Strings(s.LeftRecursiveRules)
+
+	candidates := make(map[string]bool)
+	Inspect(g, func(expr Expression) bool {
+		ch, ok := expr.(*ChoiceExpr)
+		if !ok {
+			return true
+		}
+		seen := make(map[string]bool)
+		for _, alt := range ch.Alternatives {
+			for name := range a.reachableLeftRefs(alt, left) {
+				if seen[name] {
+					candidates[name] = true
+				}
+				seen[name] = true
+			}
+		}
+		return true
+	})
+	s.MemoizationCandidates = setKeys(candidates)
+	return s
+}
+
+// HasDirectLeftRecursion returns the names of the rules that are directly
+// left-recursive, in the order of the grammar: the rules whose expression,
+// or an alternative of its choice, is a reference to the rule itself or a
+// sequence that starts with such a reference. The labels and actions
+// around these expressions are ignored.
+//
+// It is a fast check, linear in the size of the rules, that doesn't detect
+// the indirect left recursion nor the left recursion through nullable
+// expressions, see GrammarStats.LeftRecursiveRules for the full detection.
+func (g *Grammar) HasDirectLeftRecursion() []string {
+	var names []string
+	for _, r := range g.Rules {
+		alts := []Expression{unwrapValue(r.Expr)}
+		if ch, ok := alts[0].(*ChoiceExpr); ok {
+			alts = ch.Alternatives
+		}
+		for _, alt := range alts {
+			alt = unwrapValue(alt)
+			if seq, ok := alt.(*SeqExpr); ok && len(seq.Exprs) > 0 {
+				alt = unwrapValue(seq.Exprs[0])
+			}
+			if ref, ok := alt.(*RuleRefExpr); ok && ref.Name.Val == r.Name.Val {
+				names = append(names, r.Name.Val)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// unwrapValue returns the expression of expr if it is an action or a
+// labeled expression, recursively, or expr otherwise.
+func unwrapValue(expr Expression) Expression {
+	for {
+		switch e := expr.(type) {
+		case *ActionExpr:
+			expr = e.Expr
+		case *LabeledExpr:
+			expr = e.Expr
+		default:
+			return expr
+		}
+	}
+}
+
+// leftRefs returns the set of the names of the rules that expr may invoke
+// before consuming any input.
+func (a *grammarAnalyzer) leftRefs(expr Expression) map[string]bool {
+	set := make(map[string]bool)
+	a.addLeftRefs(set, expr)
+	return set
+}
+
+func (a *grammarAnalyzer) addLeftRefs(set map[string]bool, expr Expression) {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *AndExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			a.addLeftRefs(set, alt)
+		}
+	case *LabeledExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *NotExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *OneOrMoreExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *RecoveryExpr:
+		a.addLeftRefs(set, expr.Expr)
+		a.addLeftRefs(set, expr.RecoverExpr)
+	case *Rule:
+		a.addLeftRefs(set, expr.Expr)
+	case *RuleRefExpr:
+		set[expr.Name.Val] = true
+	case *SeqExpr:
+		for _, e := range expr.Exprs {
+			a.addLeftRefs(set, e)
+			if !a.isNullable(e) {
+				break
+			}
+		}
+	case *ZeroOrMoreExpr:
+		a.addLeftRefs(set, expr.Expr)
+	case *ZeroOrOneExpr:
+		a.addLeftRefs(set, expr.Expr)
+	}
+}
+
+// reachableLeftRefs returns the set of the names of the rules that expr
+// may invoke, directly or through other rules, before consuming any input.
+// The left references of each rule are provided in left.
+func (a *grammarAnalyzer) reachableLeftRefs(expr Expression, left map[string][]string) map[string]bool {
+	set := a.leftRefs(expr)
+	stack := setKeys(set)
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, ref := range left[name] {
+			if !set[ref] {
+				set[ref] = true
+				stack = append(stack, ref)
+			}
+		}
+	}
+	return set
+}
+
+// setKeys returns the sorted keys of set.
+func setKeys(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
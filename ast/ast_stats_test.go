@@ -0,0 +1,186 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestGrammarStats(t *testing.T) {
+	g := parseGrammar(t, `
+Start = Expr !.
+Expr = Expr '+' Term / Term
+Term = Factor '*' Term / Factor
+Factor = [0-9]+ / '(' Expr ')'
+`)
+	s := g.Stats()
+	if s.Rules != 4 {
+		t.Errorf("want 4 rules, got %d", s.Rules)
+	}
+	if s.MaxDepth != 5 {
+		t.Errorf("want max depth 5, got %d", s.MaxDepth)
+	}
+	if want := ast.CountByType(g); !reflect.DeepEqual(s.ByType, want) {
+		t.Errorf("want counts %v, got %v", want, s.ByType)
+	}
+	if want := s.Expressions - 1 - s.Rules; s.EstimatedStates != want {
+		t.Errorf("want %d states, got %d", want, s.EstimatedStates)
+	}
+	if want := []string{"Expr"}; !reflect.DeepEqual(s.LeftRecursiveRules, want) {
+		t.Errorf("want left-recursive rules %v, got %v", want, s.LeftRecursiveRules)
+	}
+	if want := []string{"Factor", "Term"}; !reflect.DeepEqual(s.MemoizationCandidates, want) {
+		t.Errorf("want memoization candidates %v, got %v", want, s.MemoizationCandidates)
+	}
+
+	g = parseGrammar(t, `
+Start = 'a' Start / 'b'
+`)
+	s = g.Stats()
+	if s.LeftRecursiveRules != nil || s.MemoizationCandidates != nil {
+		t.Errorf("want no left-recursive rules and candidates, got %v and %v",
+			s.LeftRecursiveRules, s.MemoizationCandidates)
+	}
+}
+
+func TestHasDirectLeftRecursion(t *testing.T) {
+	g := parseGrammar(t, `
+Start = Expr !.
+Expr = l:Expr '+' Term { return l, nil } / Term
+Term = Factor '*' Term / Factor
+Factor = Num / Factor2
+Factor2 = Factor2
+Num = n:(Num [0-9]) / [0-9]
+Indirect = Other 'x'
+Other = Indirect
+Nullable = 'a'? Nullable
+`)
+	want := []string{"Expr", "Factor2", "Num"}
+	if got := g.HasDirectLeftRecursion(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	// the full detection finds the indirect and nullable cases as well
+	full := g.Stats().LeftRecursiveRules
+	for _, name := range []string{"Indirect", "Nullable"} {
+		var found bool
+		for _, n := range full {
+			found = found || n == name
+		}
+		if !found {
+			t.Errorf("want %s in %v", name, full)
+		}
+	}
+}
+
+func TestDepthHistogram(t *testing.T) {
+	g := parseGrammar(t, `
+A = "a"
+B = [0-9]
+C = .
+`)
+	if got, want := ast.DepthHistogram(g), map[int]int{1: 3, 2: 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want histogram %v, got %v", want, got)
+	}
+
+	g = parseGrammar(t, `
+Start = Expr !.
+Expr = "(" Expr ")" / "x"
+`)
+	hist := ast.DepthHistogram(g)
+	want := map[int]int{1: 2, 2: 2, 3: 4, 4: 4}
+	if !reflect.DeepEqual(hist, want) {
+		t.Errorf("want histogram %v, got %v", want, hist)
+	}
+	var total int
+	for _, n := range hist {
+		total += n
+	}
+	if s := g.Stats(); total != s.Expressions-1 || len(hist) != s.MaxDepth-1 {
+		t.Errorf("want %d expressions at %d depths, got %d at %d", s.Expressions-1, s.MaxDepth-1, total, len(hist))
+	}
+
+	if got := ast.DepthHistogram(&ast.Grammar{}); len(got) != 0 {
+		t.Errorf("want empty histogram, got %v", got)
+	}
+}
+
+// actionsPruner is a Visitor that does not visit the children of the
+// action expressions if prune is true.
+type actionsPruner struct {
+	prune bool
+}
+
+func (v actionsPruner) Visit(expr ast.Expression, br ast.Backref) ast.Visitor {
+	if _, ok := expr.(*ast.ActionExpr); ok && v.prune {
+		return nil
+	}
+	return v
+}
+
+func TestInstrumentedVisitor(t *testing.T) {
+	g := parseGrammar(t, `
+Start = Expr !.
+Expr = "(" Expr ")" / x:"x" { return x, nil }
+`)
+	iv, v := ast.NewInstrumentedVisitor(actionsPruner{prune: true})
+	ast.Walk(v, g)
+	want := map[string]int{
+		"Grammar":     1,
+		"Rule":        2,
+		"SeqExpr":     2,
+		"RuleRefExpr": 2,
+		"NotExpr":     1,
+		"AnyMatcher":  1,
+		"ChoiceExpr":  1,
+		"LitMatcher":  2,
+		"ActionExpr":  1,
+	}
+	if got := iv.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want counts %v, got %v", want, got)
+	}
+
+	// without pruning, every expression is visited once
+	iv, v = ast.NewInstrumentedVisitor(actionsPruner{})
+	ast.Walk(v, g)
+	if got, want := iv.Counts(), ast.CountByType(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("want counts %v, got %v", want, got)
+	}
+}
+
+func TestExpressionSize(t *testing.T) {
+	g := parseGrammar(t, `
+A = "a" / [b-c] / .
+B = x:"a"+ "b"* &"c"
+C = "a" {
+	return nil, nil
+}
+`)
+	cases := []struct {
+		expr ast.Expression
+		want int
+	}{
+		{g.Rules[0].Expr, 5},
+		{g.Rules[1].Expr, 13},
+		{g.Rules[2].Expr, 14},
+		{g.Rules[0], 7},
+		{g, 7 + 15 + 16},
+	}
+	for _, c := range cases {
+		if got := ast.ExpressionSize(c.expr); got != c.want {
+			t.Errorf("%s: want %d, got %d", c.expr, c.want, got)
+		}
+	}
+
+	// a composite expression is bigger than its sub-expressions
+	ast.Inspect(g, func(expr ast.Expression) bool {
+		n := ast.ExpressionSize(expr)
+		ast.Inspect(expr, func(sub ast.Expression) bool {
+			if sub != expr && ast.ExpressionSize(sub) >= n {
+				t.Errorf("%s: want size > %d of %s", expr, ast.ExpressionSize(sub), sub)
+			}
+			return true
+		})
+		return true
+	})
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"unicode/utf8"
@@ -105,3 +106,27 @@ func TestCharClassParse(t *testing.T) {
 		}
 	}
 }
+
+func TestCharClassParseAstral(t *testing.T) {
+	cases := []struct {
+		in     string
+		chars  []rune
+		ranges []rune
+	}{
+		{in: `[\U0001F600-\U0001F64F]`, ranges: []rune{0x1F600, 0x1F64F}},
+		{in: `[😀-🙏]`, ranges: []rune{0x1F600, 0x1F64F}},
+		{in: `[\U00020000-\U0002A6DF]`, ranges: []rune{0x20000, 0x2A6DF}},
+		{in: `[＀-\U00010100]`, ranges: []rune{0xFF00, 0x10100}},
+		{in: `[\x41é\U0010FFFF]`, chars: []rune{'A', 'é', 0x10FFFF}},
+		{in: `[\uD800-\uDFFF]`, ranges: []rune{0xD800, 0xDFFF}},
+	}
+	for _, c := range cases {
+		m := NewCharClassMatcher(Pos{}, c.in)
+		if (len(m.Chars) > 0 || len(c.chars) > 0) && !reflect.DeepEqual(m.Chars, c.chars) {
+			t.Errorf("%s: want chars %U, got %U", c.in, c.chars, m.Chars)
+		}
+		if (len(m.Ranges) > 0 || len(c.ranges) > 0) && !reflect.DeepEqual(m.Ranges, c.ranges) {
+			t.Errorf("%s: want ranges %U, got %U", c.in, c.ranges, m.Ranges)
+		}
+	}
+}
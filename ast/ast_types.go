@@ -0,0 +1,430 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// actionFuncPrefix is the signature of the function literal of the code
+// block of an action, as returned by ParsedCode.
+const actionFuncPrefix = "func(c *current) (interface{}, error) "
+
+// ParsedCode returns the code block of the action as a Go function
+// literal, with the signature of the generated method of the action,
+// "func(c *current) (interface{}, error)", so that it can be analyzed with
+// the go/ast package, e.g. to find the unused labels. The labels are not
+// declared as arguments of the function, they are free identifiers of its
+// body. The positions of the returned nodes are relative to that function
+// literal, whose body starts at offset len("func(c *current) (interface{},
+// error) ").
+//
+// The result is cached, and computed again only if the code block
+// changes. It returns an error if the action has no code block or if it
+// is not valid Go code, at the position of the error in the grammar.
+func (a *ActionExpr) ParsedCode() (*goast.FuncLit, error) {
+	if a.Code == nil {
+		return nil, &actionError{pos: a.p, msg: "action without code block"}
+	}
+	if a.parsedCode != nil || a.parsedErr != nil {
+		if a.parsedSrc == a.Code.Val {
+			return a.parsedCode, a.parsedErr
+		}
+	}
+
+	a.parsedSrc, a.parsedCode, a.parsedErr = a.Code.Val, nil, nil
+	expr, err := parser.ParseExpr(actionFuncPrefix + a.Code.Val)
+	if err != nil {
+		a.parsedErr = codeError(a.Code, err)
+		return nil, a.parsedErr
+	}
+	fn, ok := expr.(*goast.FuncLit)
+	if !ok {
+		a.parsedErr = &actionError{pos: a.Code.Pos(), msg: "invalid action code: not a code block"}
+		return nil, a.parsedErr
+	}
+	a.parsedCode = fn
+	return fn, nil
+}
+
+// ParsedActions returns the code blocks of the actions of the rule as Go
+// function literals, as returned by ParsedCode, in the order of the
+// actions in the rule. As opposed to ParsedCode, each error includes the
+// name of the rule, and the errors of all the actions are returned.
+func (r *Rule) ParsedActions() ([]*goast.FuncLit, error) {
+	var fns []*goast.FuncLit
+	errs := new(errList)
+	Inspect(r.Expr, func(expr Expression) bool {
+		act, ok := expr.(*ActionExpr)
+		if !ok {
+			return true
+		}
+		fn, err := act.ParsedCode()
+		if err != nil {
+			e := err.(*actionError)
+			errs.add(e.pos, fmt.Errorf("rule %s: %s", r.Name.Val, e.msg))
+			return true
+		}
+		fns = append(fns, fn)
+		return true
+	})
+	return fns, errs.err()
+}
+
+// actionError is an error returned by ParsedCode, at the position pos in
+// the grammar.
+type actionError struct {
+	pos Pos
+	msg string
+}
+
+func (e *actionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.pos, e.msg)
+}
+
+// codeError returns the error err of the parsing of the function literal
+// of the code block code, with the position of the first error translated
+// to its position in the grammar.
+func codeError(code *CodeBlock, err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		return &actionError{pos: code.Pos(), msg: fmt.Sprintf("invalid action code: %v", err)}
+	}
+
+	first := list[0]
+	pos := code.Pos()
+	if first.Pos.Line > 1 {
+		pos.Line += first.Pos.Line - 1
+		pos.Col = first.Pos.Column
+	} else {
+		pos.Col += first.Pos.Column - 1 - len(actionFuncPrefix)
+	}
+	pos.Off += first.Pos.Offset - len(actionFuncPrefix)
+
+	msg := first.Msg
+	if len(list) > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", len(list)-1)
+	}
+	return &actionError{pos: pos, msg: "invalid action code: " + msg}
+}
+
+// InferReturnTypes sets the ReturnType of each ActionExpr of the grammar
+// to the Go type of the value returned by its code block, as inferred
+// from the first result of its last return statement. The return
+// statements of a nil value or of the results of a function call are
+// skipped. The actions that already have a ReturnType (a declared type)
+// are left untouched.
+//
+// The type is inferred syntactically, without type-checking: it is known
+// for the literals (e.g. "string" for a string literal), the composite
+// literals and their address (e.g. "*Node" for &Node{...}), the
+// conversions to a predeclared type (e.g. "string" for string(c.text)),
+// the new and make built-ins, and the comparison and logical operators.
+// The ReturnType is left empty if the type is unknown, e.g. for the
+// result of a function call, and the code generators should then assume
+// interface{}.
+//
+// It returns an error for each code block that is not valid Go code, that
+// has no return statement or a return statement that doesn't return a
+// value and an error, or that has return statements of different known
+// types.
+func InferReturnTypes(g *Grammar) error {
+	errs := new(errList)
+	Inspect(g, func(expr Expression) bool {
+		act, ok := expr.(*ActionExpr)
+		if !ok || act.Code == nil || act.ReturnType != "" {
+			return true
+		}
+		typ, err := inferReturnType(act.Code.Val)
+		if err != nil {
+			errs.add(act.Code.Pos(), err)
+			return true
+		}
+		act.ReturnType = typ
+		return true
+	})
+	return errs.err()
+}
+
+// inferReturnType returns the type of the value returned by the code
+// block of an action.
+func inferReturnType(code string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\nfunc _() (interface{}, error) "+code, 0)
+	if err != nil {
+		return "", fmt.Errorf("invalid action code: %v", err)
+	}
+	body := f.Decls[0].(*goast.FuncDecl).Body
+
+	// collect the return statements of the code block, not of the
+	// function literals that it may contain.
+	var rets []*goast.ReturnStmt
+	goast.Inspect(body, func(n goast.Node) bool {
+		switch n := n.(type) {
+		case *goast.FuncLit:
+			return false
+		case *goast.ReturnStmt:
+			rets = append(rets, n)
+		}
+		return true
+	})
+	if len(rets) == 0 {
+		return "", errors.New("no return statement in action code")
+	}
+
+	var typ string
+	var known bool
+	for i := len(rets) - 1; i >= 0; i-- {
+		ret := rets[i]
+		if len(ret.Results) == 1 {
+			if _, ok := ret.Results[0].(*goast.CallExpr); ok {
+				// returns the results of a function call
+				continue
+			}
+		}
+		if len(ret.Results) != 2 {
+			return "", fmt.Errorf("return statement at %s: want a value and an error, got %d results",
+				fset.Position(ret.Pos()), len(ret.Results))
+		}
+		if id, ok := ret.Results[0].(*goast.Ident); ok && id.Name == "nil" {
+			continue
+		}
+
+		t := exprType(ret.Results[0])
+		if !known {
+			typ, known = t, true
+			continue
+		}
+		if typ == "" || t == "" || t == typ {
+			continue
+		}
+		return "", fmt.Errorf("ambiguous return type: %s and %s", typ, t)
+	}
+	return typ, nil
+}
+
+// exprType returns the type of the Go expression e, or an empty string
+// if it cannot be known without type-checking.
+func exprType(e goast.Expr) string {
+	switch e := e.(type) {
+	case *goast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "float64"
+		case token.IMAG:
+			return "complex128"
+		case token.CHAR:
+			return "rune"
+		case token.STRING:
+			return "string"
+		}
+	case *goast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return "bool"
+		}
+	case *goast.CompositeLit:
+		if e.Type != nil {
+			return types.ExprString(e.Type)
+		}
+	case *goast.ParenExpr:
+		return exprType(e.X)
+	case *goast.UnaryExpr:
+		switch e.Op {
+		case token.AND:
+			if t := exprType(e.X); t != "" {
+				return "*" + t
+			}
+		case token.NOT:
+			return "bool"
+		case token.SUB, token.ADD, token.XOR:
+			return exprType(e.X)
+		}
+	case *goast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return "bool"
+		case token.SHL, token.SHR:
+			return exprType(e.X)
+		}
+		if t := exprType(e.X); t != "" {
+			return t
+		}
+		return exprType(e.Y)
+	case *goast.CallExpr:
+		return callType(e)
+	}
+	return ""
+}
+
+// callType returns the type of the result of the call expression e if it
+// is a conversion to a predeclared or composite type, or a call to a
+// built-in function of known type.
+func callType(e *goast.CallExpr) string {
+	switch fn := e.Fun.(type) {
+	case *goast.Ident:
+		switch fn.Name {
+		case "new":
+			if len(e.Args) == 1 {
+				return "*" + types.ExprString(e.Args[0])
+			}
+		case "make":
+			if len(e.Args) > 0 {
+				return types.ExprString(e.Args[0])
+			}
+		case "len", "cap", "copy":
+			return "int"
+		case "real", "imag":
+			return "float64"
+		case "complex":
+			return "complex128"
+		default:
+			if obj := types.Universe.Lookup(fn.Name); obj != nil {
+				if _, ok := obj.(*types.TypeName); ok {
+					return fn.Name
+				}
+			}
+		}
+	case *goast.ArrayType, *goast.MapType, *goast.ChanType, *goast.FuncType, *goast.InterfaceType:
+		return types.ExprString(fn)
+	case *goast.ParenExpr:
+		return types.ExprString(fn.X)
+	}
+	return ""
+}
+
+// CheckResultTypes checks the result types declared by the rules of the
+// grammar (see Rule.ResultType). It returns an error for each declared
+// type that is not a valid Go type, and for each result of a rule that is
+// not assignable to the declared type of the rule. The results of a rule
+// are its action, or the alternatives of its choice expression that are
+// actions or references to rules, and their type is the return type of
+// the action, declared or inferred (see InferReturnTypes), or the declared
+// type of the referenced rule.
+//
+// It also returns an error if the entry rule, i.e. the first rule, has no
+// declared type and its choice expression has references to rules that
+// declare incompatible types.
+//
+// As the types are not type-checked, a result is considered assignable
+// unless both types are known, different and not interfaces, e.g. string
+// and *Node, but not *Node and Expr (which may be an interface).
+func CheckResultTypes(g *Grammar) error {
+	errs := new(errList)
+	rules := make(map[string]*Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+		if r.ResultType == "" {
+			continue
+		}
+		if _, err := parser.ParseExpr(r.ResultType); err != nil {
+			errs.add(r.Pos(), fmt.Errorf("rule %s: invalid result type %q", r.Name.Val, r.ResultType))
+			r.ResultType = ""
+		}
+	}
+
+	for i, r := range g.Rules {
+		if r.ResultType == "" && i > 0 {
+			continue
+		}
+
+		var first Expression
+		var firstType string
+		for _, res := range ruleResults(r.Expr) {
+			typ := resultType(res, rules)
+			_, isRef := res.(*RuleRefExpr)
+			switch {
+			case typ == "" || r.ResultType == "" && !isRef:
+				continue
+			case r.ResultType != "":
+				if !assignableType(typ, r.ResultType) {
+					errs.add(res.Pos(), fmt.Errorf("rule %s: result of type %s not assignable to %s",
+						r.Name.Val, typ, r.ResultType))
+				}
+			case first == nil:
+				first, firstType = res, typ
+			case !assignableType(typ, firstType):
+				errs.add(res.Pos(), fmt.Errorf("rule %s: result of type %s incompatible with the result of type %s at %s",
+					r.Name.Val, typ, firstType, first.Pos()))
+			}
+		}
+	}
+	return errs.err()
+}
+
+// ruleResults returns the expressions whose value may be the value of a
+// rule of expression expr.
+func ruleResults(expr Expression) []Expression {
+	if ch, ok := expr.(*ChoiceExpr); ok {
+		return ch.Alternatives
+	}
+	return []Expression{expr}
+}
+
+// resultType returns the Go type of the value of expr, if it is an action
+// or a reference to a rule with a declared type, or an empty string.
+func resultType(expr Expression, rules map[string]*Rule) string {
+	switch expr := expr.(type) {
+	case *ActionExpr:
+		if expr.ReturnType != "" || expr.Code == nil {
+			return expr.ReturnType
+		}
+		typ, _ := inferReturnType(expr.Code.Val)
+		return typ
+	case *RuleRefExpr:
+		if r, ok := rules[expr.Name.Val]; ok {
+			return r.ResultType
+		}
+	}
+	return ""
+}
+
+// typeAliases are the predeclared aliases of the types.
+var typeAliases = map[string]string{
+	"any":  "interface{}",
+	"byte": "uint8",
+	"rune": "int32",
+}
+
+// concreteTypes are the predeclared types that are not interfaces.
+var concreteTypes = map[string]bool{
+	"bool": true, "string": true, "uint8": true, "int32": true,
+	"int": true, "int8": true, "int16": true, "int64": true,
+	"uint": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// assignableType returns false if a value of type from is known not to be
+// assignable to the type to.
+func assignableType(from, to string) bool {
+	from, to = normalizeType(from), normalizeType(to)
+	return from == to || !isConcreteType(from) || !isConcreteType(to)
+}
+
+func normalizeType(typ string) string {
+	typ = strings.Join(strings.Fields(typ), "")
+	if alias, ok := typeAliases[typ]; ok {
+		return alias
+	}
+	return typ
+}
+
+// isConcreteType returns true if the normalized type typ is known not to
+// be an interface.
+func isConcreteType(typ string) bool {
+	if concreteTypes[typ] {
+		return true
+	}
+	for _, prefix := range []string{"*", "[", "map[", "chan", "func(", "struct{"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
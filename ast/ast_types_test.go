@@ -0,0 +1,217 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestInferReturnTypes(t *testing.T) {
+	cases := []struct {
+		code string
+		want string
+		err  string
+	}{
+		{code: `{ return "a", nil }`, want: "string"},
+		{code: `{ return 1, nil }`, want: "int"},
+		{code: `{ return 1.5, nil }`, want: "float64"},
+		{code: `{ return 'a', nil }`, want: "rune"},
+		{code: `{ return true, nil }`, want: "bool"},
+		{code: `{ return a == b, nil }`, want: "bool"},
+		{code: `{ return -(1 + x), nil }`, want: "int"},
+		{code: `{ return string(c.text), nil }`, want: "string"},
+		{code: `{ return []byte(s), nil }`, want: "[]byte"},
+		{code: `{ return &Node{Val: 1}, nil }`, want: "*Node"},
+		{code: `{ return ast.Pos{}, nil }`, want: "ast.Pos"},
+		{code: `{ return map[string]int{}, nil }`, want: "map[string]int"},
+		{code: `{ return new(Node), nil }`, want: "*Node"},
+		{code: `{ return make([]string, 0), nil }`, want: "[]string"},
+		{code: `{ return (*Node)(p), nil }`, want: "*Node"},
+		{code: `{ return f(x), nil }`, want: ""},
+		{code: `{ return x, nil }`, want: ""},
+		{code: `{ return f() }`, want: ""},
+		{code: `{
+	if x == nil {
+		return nil, errors.New("x")
+	}
+	return "x", nil
+}`, want: "string"},
+		{code: `{
+	fn := func() int { return 1 }
+	return fn() > 0, nil
+}`, want: "bool"},
+		{code: `{
+	if x {
+		return 1, nil
+	}
+	return "a", nil
+}`, err: "ambiguous return type: string and int"},
+		{code: `{ return 1, 2, nil }`, err: "want a value and an error, got 3 results"},
+		{code: `{ return }`, err: "want a value and an error, got 0 results"},
+		{code: `{ x := 1 }`, err: "no return statement"},
+		{code: `{ return "a" nil }`, err: "invalid action code"},
+	}
+	for _, tc := range cases {
+		g := parseGrammar(t, "A = 'a' "+tc.code)
+		act := g.Rules[0].Expr.(*ast.ActionExpr)
+		err := ast.InferReturnTypes(g)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: want error %q, got %v", tc.code, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: want no error, got %v", tc.code, err)
+			continue
+		}
+		if act.ReturnType != tc.want {
+			t.Errorf("%s: want return type %q, got %q", tc.code, tc.want, act.ReturnType)
+		}
+	}
+}
+
+func TestInferReturnTypesDeclared(t *testing.T) {
+	g := parseGrammar(t, `A = 'a' { return 1, 2, 3 } / 'b' { return "b", nil }`)
+	alts := g.Rules[0].Expr.(*ast.ChoiceExpr).Alternatives
+	alts[0].(*ast.ActionExpr).ReturnType = "Node"
+
+	if err := ast.InferReturnTypes(g); err != nil {
+		t.Fatal(err)
+	}
+	if got := alts[0].(*ast.ActionExpr).ReturnType; got != "Node" {
+		t.Errorf("want declared return type Node, got %q", got)
+	}
+	if got := alts[1].(*ast.ActionExpr).ReturnType; got != "string" {
+		t.Errorf("want inferred return type string, got %q", got)
+	}
+}
+
+func TestActionExprParsedCode(t *testing.T) {
+	g := parseGrammar(t, `A = x:'a' {
+	if x == nil {
+		return nil, nil
+	}
+	return string(c.text), nil
+}
+B = 'b' { return 1 +, nil }`)
+
+	act := g.Rules[0].Expr.(*ast.ActionExpr)
+	fn, err := act.ParsedCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fn.Body.List); n != 2 {
+		t.Errorf("want 2 statements, got %d", n)
+	}
+	if n := len(fn.Type.Results.List); n != 2 {
+		t.Errorf("want 2 results, got %d", n)
+	}
+	if fn2, _ := act.ParsedCode(); fn2 != fn {
+		t.Errorf("want the cached function literal")
+	}
+
+	// the cache is invalidated when the code changes
+	act.Code = ast.NewCodeBlock(act.Code.Pos(), `{ return x, nil }`)
+	if fn2, err := act.ParsedCode(); err != nil || fn2 == fn || len(fn2.Body.List) != 1 {
+		t.Errorf("want a new function literal, got %v, %v", fn2, err)
+	}
+
+	act = g.Rules[1].Expr.(*ast.ActionExpr)
+	want := `7:21 (98): invalid action code: expected operand, found ','`
+	if _, err := act.ParsedCode(); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func TestRuleParsedActions(t *testing.T) {
+	g := parseGrammar(t, `A = 'a' { return 1, nil } / 'b' { return 2, nil }
+B = 'b' { return 1 +, nil } / 'c' { return 3, nil }`)
+
+	fns, err := g.Rules[0].ParsedActions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fns) != 2 {
+		t.Errorf("want 2 function literals, got %d", len(fns))
+	}
+
+	want := `2:21 (70): rule B: invalid action code: expected operand, found ','`
+	if _, err := g.Rules[1].ParsedActions(); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func TestCheckResultTypes(t *testing.T) {
+	cases := []struct {
+		src   string
+		types map[string]string
+		want  string
+	}{
+		{
+			src: `A = "a" { return &Node{}, nil } / B
+B = "b" { return "b", nil }`,
+			types: map[string]string{"A": "Expr", "B": "string"},
+		},
+		{
+			src: `A = "a" { return &Node{}, nil } / B
+B = "b" { return "b", nil }`,
+			types: map[string]string{"A": "*Node", "B": "string"},
+			want:  "rule A: result of type string not assignable to *Node",
+		},
+		{
+			src:   `A = "a" { return "a", nil }`,
+			types: map[string]string{"A": "[]byte"},
+			want:  "rule A: result of type string not assignable to []byte",
+		},
+		{
+			src:   `A = "a" { return 'a', nil }`,
+			types: map[string]string{"A": "int32"},
+		},
+		{
+			src:   `A = "a"`,
+			types: map[string]string{"A": "map[string"},
+			want:  `rule A: invalid result type "map[string"`,
+		},
+		{
+			// the untyped entry rule has incompatible alternatives
+			src: `A = B / C
+B = "b"
+C = "c"`,
+			types: map[string]string{"B": "*Node", "C": "string"},
+			want:  "rule A: result of type string incompatible with the result of type *Node at 1:5 (4)",
+		},
+		{
+			src: `A = B / C / "x" { return 1, nil }
+B = "b"
+C = "c"`,
+			types: map[string]string{"B": "*Node", "C": "Expr"},
+		},
+		{
+			// only the entry rule is checked if untyped
+			src: `A = D
+D = B / C
+B = "b"
+C = "c"`,
+			types: map[string]string{"B": "*Node", "C": "string"},
+		},
+	}
+
+	for _, c := range cases {
+		g := parseGrammar(t, c.src)
+		for _, r := range g.Rules {
+			r.ResultType = c.types[r.Name.Val]
+		}
+		err := ast.CheckResultTypes(g)
+		if c.want == "" {
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", c.src, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: want error containing %q, got %v", c.src, c.want, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ast
+
+import (
+	"fmt"
+	"sync"
+	"unicode"
+)
+
+// unicodeCategories caches the character class matchers created by
+// UnicodeCategory, by category name, as the tables are large.
+var unicodeCategories = struct {
+	sync.Mutex
+	m map[string]*CharClassMatcher
+}{m: make(map[string]*CharClassMatcher)}
+
+// UnicodeCategory creates a character class matcher with the full set of
+// ranges of the named Unicode category (e.g. "L" or "Nd") or script (e.g.
+// "Greek"), as defined by the tables of the unicode package. As opposed
+// to a `\p{Nd}` class, the returned matcher lists the ranges explicitly.
+// It returns an error if there is no such category or script.
+//
+// The matchers are cached, a copy of the cached matcher is returned on
+// each call so that it can be modified freely.
+func UnicodeCategory(category string) (*CharClassMatcher, error) {
+	unicodeCategories.Lock()
+	defer unicodeCategories.Unlock()
+
+	if c, ok := unicodeCategories.m[category]; ok {
+		return cloneExpr(c).(*CharClassMatcher), nil
+	}
+
+	table, ok := unicode.Categories[category]
+	if !ok {
+		if table, ok = unicode.Scripts[category]; !ok {
+			return nil, fmt.Errorf("unknown Unicode category: %s", category)
+		}
+	}
+
+	var ranges []rune
+	addRange := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, lo, hi)
+			return
+		}
+		for rn := lo; rn <= hi; rn += stride {
+			ranges = append(ranges, rn, rn)
+		}
+	}
+	for _, r := range table.R16 {
+		addRange(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		addRange(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	c := NewCharClassMatcher(Pos{}, charClassFromRanges(ranges, false))
+	unicodeCategories.m[category] = c
+	return cloneExpr(c).(*CharClassMatcher), nil
+}
+
+// ContainsRune returns true if the rune r is accepted by the character
+// class, as it would be by the generated parser: r is accepted if it is
+// one of the Chars, in one of the Ranges or in one of the UnicodeClasses,
+// or if it is in none of them for an Inverted class. If the class is
+// IgnoreCase, the lower case of r is compared with the lower case of the
+// characters and range bounds. The charsets that are not yet expanded by
+// ExpandCharsets are ignored.
+func (c *CharClassMatcher) ContainsRune(r rune) bool {
+	return c.containsRune(r) != c.Inverted
+}
+
+func (c *CharClassMatcher) containsRune(r rune) bool {
+	lower := func(rn rune) rune { return rn }
+	if c.IgnoreCase {
+		lower = unicode.ToLower
+	}
+	r = lower(r)
+
+	for _, rn := range c.Chars {
+		if lower(rn) == r {
+			return true
+		}
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		if r >= lower(c.Ranges[i]) && r <= lower(c.Ranges[i+1]) {
+			return true
+		}
+	}
+	for _, cl := range c.UnicodeClasses {
+		if table, ok := unicodeTable(cl); ok && unicode.Is(table, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// unicodeTable returns the table of the Unicode class named class, that
+// is a category, a property or a script, as in the generated parser.
+func unicodeTable(class string) (*unicode.RangeTable, bool) {
+	if table, ok := unicode.Categories[class]; ok {
+		return table, true
+	}
+	if table, ok := unicode.Properties[class]; ok {
+		return table, true
+	}
+	table, ok := unicode.Scripts[class]
+	return table, ok
+}
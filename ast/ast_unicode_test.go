@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"testing"
+	"unicode"
+)
+
+// matchesRune returns true if the non-inverted character class c matches rn.
+func matchesRune(c *CharClassMatcher, rn rune) bool {
+	for _, ch := range c.Chars {
+		if ch == rn {
+			return true
+		}
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		if c.Ranges[i] <= rn && rn <= c.Ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUnicodeCategory(t *testing.T) {
+	cases := map[string]*unicode.RangeTable{
+		"L":     unicode.L,
+		"Lu":    unicode.Lu,
+		"Nd":    unicode.Nd,
+		"Pd":    unicode.Pd,
+		"Greek": unicode.Greek,
+	}
+	for nm, table := range cases {
+		c, err := UnicodeCategory(nm)
+		if err != nil {
+			t.Errorf("%s: want no error, got %v", nm, err)
+			continue
+		}
+		if c.Inverted || c.IgnoreCase || len(c.UnicodeClasses) > 0 {
+			t.Errorf("%s: want explicit ranges, got %s", nm, c)
+		}
+		for rn := rune(0); rn <= unicode.MaxRune; rn++ {
+			if matchesRune(c, rn) != unicode.Is(table, rn) {
+				t.Errorf("%s: want %t for %#U", nm, unicode.Is(table, rn), rn)
+				break
+			}
+		}
+	}
+
+	// the cached matcher is not shared
+	c1, _ := UnicodeCategory("Nd")
+	c1.Ranges[0] = 'x'
+	if c2, _ := UnicodeCategory("Nd"); c2.Ranges[0] != '0' {
+		t.Errorf("want a copy of the cached matcher, got %q", c2.Ranges[0])
+	}
+
+	if _, err := UnicodeCategory("Nope"); err == nil {
+		t.Error("want error for unknown category, got nil")
+	}
+}
+
+func TestContainsRune(t *testing.T) {
+	cases := []struct {
+		class string
+		in    string
+		out   string
+	}{
+		{"[a-z]", "amz", "AZ0-"},
+		{"[^a-z]", "AZ0-", "amz"},
+		{"[abc]", "abc", "dA"},
+		{"[a-c]i", "abcABC", "dD"},
+		{"[XY]i", "xyXY", "zZ"},
+		{`[\pN_]`, "05_٣", "a-"},
+		{`[\p{Greek}x]`, "αΩx", "aX"},
+		{`[^\p{Lu}]`, "a1", "AÉ"},
+		{"[]", "", "a"},
+		{"[^]", "a", ""},
+	}
+	for _, c := range cases {
+		cc := NewCharClassMatcher(Pos{}, c.class)
+		for _, rn := range c.in {
+			if !cc.ContainsRune(rn) {
+				t.Errorf("%s: want %q accepted", c.class, rn)
+			}
+		}
+		for _, rn := range c.out {
+			if cc.ContainsRune(rn) {
+				t.Errorf("%s: want %q rejected", c.class, rn)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"reflect"
 )
 
 // Backref holds a reference to the parent of the current expression being visited
@@ -10,9 +11,54 @@ import (
 // optimized expression.
 type Backref struct {
 	parent   Expression
+	index    int
 	replacer func(Expression)
 }
 
+// Replace replaces the current expression by expr in its parent. It has
+// no effect on the root expression of the walk, which has no parent. The
+// children that are visited next are still those of the replaced
+// expression, unless the walk was started by WalkReplacing.
+func (br Backref) Replace(expr Expression) {
+	br.replacer(expr)
+}
+
+// Index returns the index of the current expression in its parent, e.g.
+// the index of an alternative in a ChoiceExpr. It is 0 for the parents
+// that have a single child, and for the root expression of the walk. The
+// RecoverExpr of a RecoveryExpr has index 1.
+func (br Backref) Index() int {
+	return br.index
+}
+
+// PrevSiblings returns the expressions that precede the current expression
+// in its parent, if the parent is a SeqExpr or a ChoiceExpr. It returns nil
+// otherwise. The returned slice shares its elements with the parent and
+// must not be modified.
+func (br Backref) PrevSiblings() []Expression {
+	switch parent := br.parent.(type) {
+	case *ChoiceExpr:
+		return parent.Alternatives[:br.index:br.index]
+	case *SeqExpr:
+		return parent.Exprs[:br.index:br.index]
+	}
+	return nil
+}
+
+// NextSiblings returns the expressions that follow the current expression
+// in its parent, if the parent is a SeqExpr or a ChoiceExpr. It returns nil
+// otherwise. The returned slice shares its elements with the parent and
+// must not be modified.
+func (br Backref) NextSiblings() []Expression {
+	switch parent := br.parent.(type) {
+	case *ChoiceExpr:
+		return parent.Alternatives[br.index+1:]
+	case *SeqExpr:
+		return parent.Exprs[br.index+1:]
+	}
+	return nil
+}
+
 // A Visitor implements a Visit method, which is invoked for each Expression
 // encountered by Walk.
 // If the result visitor w is not nil, Walk visits each of the children
@@ -29,10 +75,31 @@ type Visitor interface {
 // w for each of the non-nil children of Expression, followed by a call of
 // w.Visit(nil).
 func Walk(v Visitor, expr Expression) {
-	walk0(v, expr, nil, 0)
+	var w walker
+	w.walk(v, expr, nil, 0)
+}
+
+// WalkReplacing is like Walk, except that when v.Visit(expr) replaces expr
+// by calling Backref.Replace, the replacement is visited immediately with
+// the same visitor v, instead of the children of expr, so that it may in
+// turn be replaced. A given position of the tree is re-visited at most
+// maxReentries times; after that, the walk continues with the children of
+// the last replacement. It returns the root expression of the walk, which
+// is the last replacement of expr if the root itself was replaced.
+func WalkReplacing(v Visitor, expr Expression, maxReentries int) Expression {
+	w := walker{replacing: true, maxReentries: maxReentries}
+	return w.walk(v, expr, nil, 0)
+}
+
+// walker implements Walk and WalkReplacing.
+type walker struct {
+	replacing    bool
+	maxReentries int
 }
 
-func walk0(v Visitor, expr, parent0 Expression, index int) {
+// walk visits expr, which is the child at index of parent0, and its
+// children. It returns the expression that ends up at that position.
+func (w walker) walk(v Visitor, expr, parent0 Expression, index int) Expression {
 	var replacer func(Expression)
 
 	switch parent := parent0.(type) {
@@ -58,6 +125,10 @@ func walk0(v Visitor, expr, parent0 Expression, index int) {
 		replacer = func(expr Expression) {
 			parent.Expr = expr
 		}
+	case *LookbehindExpr:
+		replacer = func(expr Expression) {
+			parent.Expr = expr
+		}
 	case *NotExpr:
 		replacer = func(expr Expression) {
 			parent.Expr = expr
@@ -66,6 +137,14 @@ func walk0(v Visitor, expr, parent0 Expression, index int) {
 		replacer = func(expr Expression) {
 			parent.Expr = expr
 		}
+	case *RecoveryExpr:
+		replacer = func(expr Expression) {
+			if index == 0 {
+				parent.Expr = expr
+			} else {
+				parent.RecoverExpr = expr
+			}
+		}
 	case *Rule:
 		replacer = func(expr Expression) {
 			parent.Expr = expr
@@ -85,60 +164,92 @@ func walk0(v Visitor, expr, parent0 Expression, index int) {
 		}
 	}
 
-	if v = v.Visit(expr, Backref{
+	var replaced Expression
+	if w.replacing {
+		replace := replacer
+		replacer = func(expr Expression) {
+			replace(expr)
+			replaced = expr
+		}
+	}
+
+	br := Backref{
 		parent:   parent0,
+		index:    index,
 		replacer: replacer,
-	}); v == nil {
-		return
+	}
+	vv := v.Visit(expr, br)
+	for n := 0; replaced != nil; n++ {
+		expr, replaced = replaced, nil
+		if n >= w.maxReentries {
+			break
+		}
+		vv = v.Visit(expr, br)
+	}
+	if v = vv; v == nil {
+		return expr
 	}
 
 	switch expr := expr.(type) {
 	case *ActionExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *AndCodeExpr:
 		// Nothing to do
 	case *AndExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *AnyMatcher:
 		// Nothing to do
+	case *BOLMatcher:
+		// Nothing to do
 	case *CharClassMatcher:
 		// Nothing to do
 	case *ChoiceExpr:
 		for i, e := range expr.Alternatives {
-			walk0(v, e, expr, i)
+			w.walk(v, e, expr, i)
 		}
 	case *Grammar:
 		for i, e := range expr.Rules {
-			walk0(v, e, expr, i)
+			w.walk(v, e, expr, i)
 		}
 	case *LabeledExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *LitMatcher:
 		// Nothing to do
+	case *LookbehindExpr:
+		w.walk(v, expr.Expr, expr, 0)
 	case *NotCodeExpr:
 		// Nothing to do
 	case *NotExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *OneOrMoreExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
+	case *RecoveryExpr:
+		w.walk(v, expr.Expr, expr, 0)
+		w.walk(v, expr.RecoverExpr, expr, 1)
+	case *KeywordMatcher, *RegexpMatcher:
+		// Nothing to do
 	case *Rule:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *RuleRefExpr:
 		// Nothing to do
 	case *SeqExpr:
 		for i, e := range expr.Exprs {
-			walk0(v, e, expr, i)
+			w.walk(v, e, expr, i)
 		}
 	case *StateCodeExpr:
 		// Nothing to do
+	case *ThrowExpr:
+		// Nothing to do
 	case *ZeroOrMoreExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	case *ZeroOrOneExpr:
-		walk0(v, expr.Expr, expr, 0)
+		w.walk(v, expr.Expr, expr, 0)
 	default:
-		panic(fmt.Sprintf("unknown expression type %T", expr))
+		// a new expression type must be added to both type switches of walk,
+		// the one above if it has children.
+		panic(fmt.Sprintf("ast: Walk: unhandled expression type %T", expr))
 	}
-
+	return expr
 }
 
 type inspector func(Expression) bool
@@ -157,3 +268,119 @@ func (f inspector) Visit(expr Expression, br Backref) Visitor {
 func Inspect(expr Expression, f func(Expression) bool) {
 	Walk(inspector(f), expr)
 }
+
+// pathWalker is the Visitor of WalkPath, for the expressions at depth in
+// the path.
+type pathWalker struct {
+	path  *[]Expression
+	depth int
+	f     func(path []Expression) bool
+}
+
+func (w pathWalker) Visit(expr Expression, br Backref) Visitor {
+	*w.path = append((*w.path)[:w.depth], expr)
+	if !w.f(*w.path) {
+		return nil
+	}
+	return pathWalker{path: w.path, depth: w.depth + 1, f: w.f}
+}
+
+// WalkPath traverses an AST in depth-first order like Inspect, except that
+// f is called with the path from expr to the current expression, so that
+// path[0] is expr and path[len(path)-1] is the current expression. If f
+// returns false, the children of the current expression are not visited.
+// Unlike Inspect, f is never called with a nil expression. The path is
+// reused between the calls and must not be retained or modified by f.
+func WalkPath(expr Expression, f func(path []Expression) bool) {
+	var path []Expression
+	Walk(pathWalker{path: &path, f: f}, expr)
+}
+
+// WalkType traverses an AST in depth-first order like Inspect and calls f
+// for each expression that has one of the types, e.g.
+// reflect.TypeOf((*ActionExpr)(nil)). The other expressions are not
+// passed to f, but their children are still visited.
+func WalkType(expr Expression, types []reflect.Type, f func(Expression)) {
+	Inspect(expr, func(expr Expression) bool {
+		t := reflect.TypeOf(expr)
+		for _, typ := range types {
+			if t == typ {
+				f(expr)
+				break
+			}
+		}
+		return true
+	})
+}
+
+// leafVisitor is the Visitor of WalkLeaves.
+type leafVisitor struct {
+	v Visitor
+}
+
+func (l leafVisitor) Visit(expr Expression, br Backref) Visitor {
+	switch expr.(type) {
+	case nil:
+		return nil
+	case *AndCodeExpr, *AnyMatcher, *BOLMatcher, *CharClassMatcher, *KeywordMatcher, *LitMatcher,
+		*NotCodeExpr, *RegexpMatcher, *RuleRefExpr, *StateCodeExpr, *ThrowExpr:
+		l.v.Visit(expr, br)
+		return nil
+	}
+	return l
+}
+
+// WalkLeaves traverses an AST in depth-first order like Walk, but only
+// calls v.Visit for the leaves, i.e. the expressions that have no
+// children: the matchers, the rule references, the code blocks of the
+// predicates and state changes, and the throw expressions. The visitor
+// returned by v.Visit is ignored, and v.Visit is never called with a nil
+// expression.
+func WalkLeaves(v Visitor, expr Expression) {
+	Walk(leafVisitor{v: v}, expr)
+}
+
+// childrenLister lists the direct children of the expression that it
+// visits first.
+type childrenLister struct {
+	started  bool
+	children []Expression
+}
+
+func (l *childrenLister) Visit(expr Expression, br Backref) Visitor {
+	if !l.started {
+		l.started = true
+		return l
+	}
+	l.children = append(l.children, expr)
+	return nil
+}
+
+// InspectBFS traverses an AST in breadth-first order: It starts by calling
+// f(expr, 0); expr must not be nil. The expressions are then visited level
+// by level, in the order of their parents, and f is called with their
+// depth, i.e. their distance from expr. If f returns false for an
+// expression, its children are not visited. Unlike Inspect, f is never
+// called with a nil expression, and an explicit queue is used instead of
+// recursion.
+func InspectBFS(expr Expression, f func(expr Expression, depth int) bool) {
+	type item struct {
+		expr  Expression
+		depth int
+	}
+
+	queue := []item{{expr, 0}}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		if !f(it.expr, it.depth) {
+			continue
+		}
+
+		var l childrenLister
+		Walk(&l, it.expr)
+		for _, child := range l.children {
+			queue = append(queue, item{child, it.depth + 1})
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type siblingsVisitor func(Expression, Backref)
+
+func (f siblingsVisitor) Visit(expr Expression, br Backref) Visitor {
+	f(expr, br)
+	return f
+}
+
+type walkRecorder func(Expression, Backref)
+
+func (f walkRecorder) Visit(expr Expression, br Backref) Visitor {
+	f(expr, br)
+	return f
+}
+
+func TestWalkRecoveryExpr(t *testing.T) {
+	lit := NewLitMatcher(Pos{}, "a")
+	throw := NewThrowExpr(Pos{})
+	throw.Label = "x"
+	rec := NewRecoveryExpr(Pos{})
+	rec.Expr = lit
+	rec.RecoverExpr = throw
+
+	var got []Expression
+	repl := NewLitMatcher(Pos{}, "b")
+	Walk(walkRecorder(func(expr Expression, br Backref) {
+		if expr == nil {
+			return
+		}
+		got = append(got, expr)
+		if expr == throw {
+			br.replacer(repl)
+		}
+	}), rec)
+	if want := []Expression{rec, lit, throw}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if rec.Expr != lit || rec.RecoverExpr != repl {
+		t.Errorf("want the recover expression replaced, got %v", rec)
+	}
+}
+
+func TestBackrefSiblings(t *testing.T) {
+	a, b, c := NewLitMatcher(Pos{}, "a"), NewLitMatcher(Pos{}, "b"), NewLitMatcher(Pos{}, "c")
+	seq := NewSeqExpr(Pos{})
+	seq.Exprs = []Expression{a, b, c}
+	d := NewLitMatcher(Pos{}, "d")
+	ch := NewChoiceExpr(Pos{})
+	ch.Alternatives = []Expression{seq, d}
+	rule := NewRule(Pos{}, NewIdentifier(Pos{}, "A"))
+	rule.Expr = ch
+
+	want := map[Expression][2][]Expression{
+		rule: {nil, nil},
+		ch:   {nil, nil},
+		seq:  {{}, {d}},
+		a:    {{}, {b, c}},
+		b:    {{a}, {c}},
+		c:    {{a, b}, {}},
+		d:    {{seq}, {}},
+	}
+	var n int
+	Walk(siblingsVisitor(func(expr Expression, br Backref) {
+		n++
+		w := want[expr]
+		if prev := br.PrevSiblings(); !reflect.DeepEqual(prev, w[0]) {
+			t.Errorf("%s: want previous siblings %v, got %v", expr, w[0], prev)
+		}
+		if next := br.NextSiblings(); !reflect.DeepEqual(next, w[1]) {
+			t.Errorf("%s: want next siblings %v, got %v", expr, w[1], next)
+		}
+		if len(w[0]) != br.Index() {
+			t.Errorf("%s: want index %d, got %d", expr, len(w[0]), br.Index())
+		}
+	}), rule)
+	if n != len(want) {
+		t.Errorf("want %d visits, got %d", len(want), n)
+	}
+}
+
+func TestWalkPath(t *testing.T) {
+	a, b, c := Lit("a"), Lit("b"), Lit("c")
+	seq := Seq(a, Optional(b))
+	ch := Choice(seq, c)
+	rule := NewRule(Pos{}, NewIdentifier(Pos{}, "A"))
+	rule.Expr = ch
+
+	var got [][]Expression
+	WalkPath(rule, func(path []Expression) bool {
+		got = append(got, append([]Expression(nil), path...))
+		// prune the optional expression
+		_, ok := path[len(path)-1].(*ZeroOrOneExpr)
+		return !ok
+	})
+	want := [][]Expression{
+		{rule},
+		{rule, ch},
+		{rule, ch, seq},
+		{rule, ch, seq, a},
+		{rule, ch, seq, seq.Exprs[1]},
+		{rule, ch, c},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want paths %v, got %v", want, got)
+	}
+}
+
+func TestWalkType(t *testing.T) {
+	a, b, c := Lit("a"), Lit("b"), Class([2]rune{'0', '9'})
+	act := Action(Seq(a, Optional(b)), "return nil, nil")
+	ch := Choice(act, c)
+
+	var got []Expression
+	types := []reflect.Type{reflect.TypeOf((*LitMatcher)(nil)), reflect.TypeOf((*CharClassMatcher)(nil))}
+	WalkType(ch, types, func(expr Expression) {
+		got = append(got, expr)
+	})
+	if want := []Expression{a, b, c}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	got = nil
+	WalkType(ch, []reflect.Type{reflect.TypeOf((*ActionExpr)(nil))}, func(expr Expression) {
+		got = append(got, expr)
+	})
+	if want := []Expression{act}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	WalkType(ch, nil, func(expr Expression) {
+		t.Errorf("want no call, got %v", expr)
+	})
+}
+
+func TestWalkLeaves(t *testing.T) {
+	a, b, c, d := Lit("a"), Ref("B"), Class([2]rune{'0', '9'}), Any()
+	code := NewAndCodeExpr(Pos{})
+	act := Action(Seq(a, Optional(b), And(c)), "return nil, nil")
+	ch := Choice(act, Seq(OneOrMore(d), code))
+
+	var got []Expression
+	WalkLeaves(siblingsVisitor(func(expr Expression, br Backref) {
+		got = append(got, expr)
+	}), ch)
+	if want := []Expression{a, b, c, d, code}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// the leaves may be replaced
+	e := Lit("e")
+	WalkLeaves(siblingsVisitor(func(expr Expression, br Backref) {
+		if expr == a {
+			br.Replace(e)
+		}
+	}), ch)
+	if seq := act.Expr.(*SeqExpr); seq.Exprs[0] != e {
+		t.Errorf("want %v replaced by %v, got %v", a, e, seq.Exprs[0])
+	}
+
+	got = nil
+	WalkLeaves(siblingsVisitor(func(expr Expression, br Backref) {
+		got = append(got, expr)
+	}), b)
+	if want := []Expression{b}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestInspectBFS(t *testing.T) {
+	b := NewGrammarBuilder()
+	b.AddRule("A").Seq(Ref("B"), OneOrMore(Lit("a")))
+	b.AddRule("B").Choice(Lit("b"), Label("x", Not(Any())))
+	g := b.Grammar()
+
+	var got []string
+	InspectBFS(g, func(expr Expression, depth int) bool {
+		got = append(got, strings.Repeat(" ", depth)+typeName(expr))
+		return true
+	})
+	want := []string{
+		"Grammar",
+		" Rule",
+		" Rule",
+		"  SeqExpr",
+		"  ChoiceExpr",
+		"   RuleRefExpr",
+		"   OneOrMoreExpr",
+		"   LitMatcher",
+		"   LabeledExpr",
+		"    LitMatcher",
+		"    NotExpr",
+		"     AnyMatcher",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	// the children of the rules are not visited
+	got = got[:0]
+	InspectBFS(g, func(expr Expression, depth int) bool {
+		got = append(got, typeName(expr))
+		return depth < 1
+	})
+	if want := []string{"Grammar", "Rule", "Rule"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+// unwrapper replaces the sequences and choices of a single expression by
+// that expression.
+type unwrapper struct{ visits int }
+
+func (u *unwrapper) Visit(expr Expression, br Backref) Visitor {
+	u.visits++
+	switch expr := expr.(type) {
+	case *SeqExpr:
+		if len(expr.Exprs) == 1 {
+			br.Replace(expr.Exprs[0])
+		}
+	case *ChoiceExpr:
+		if len(expr.Alternatives) == 1 {
+			br.Replace(expr.Alternatives[0])
+		}
+	}
+	return u
+}
+
+func TestWalkReplacing(t *testing.T) {
+	nested := func() *Rule {
+		b := NewGrammarBuilder()
+		b.AddRule("A").Expr(Seq(Choice(Seq(OneOrMore(Seq(Lit("a")))))))
+		return b.Grammar().Rules[0]
+	}
+
+	// Walk visits the children of the replaced expression
+	r := nested()
+	Walk(&unwrapper{}, r)
+	if _, ok := r.Expr.(*ChoiceExpr); !ok {
+		t.Errorf("Walk: want a ChoiceExpr, got %T", r.Expr)
+	}
+
+	r = nested()
+	var u unwrapper
+	if got := WalkReplacing(&u, r, 10); got != r {
+		t.Errorf("want the rule to be returned, got %v", got)
+	}
+	one, ok := r.Expr.(*OneOrMoreExpr)
+	if !ok {
+		t.Fatalf("want a OneOrMoreExpr, got %T", r.Expr)
+	}
+	if lit, ok := one.Expr.(*LitMatcher); !ok || lit.Val != "a" {
+		t.Errorf("want a LitMatcher, got %v", one.Expr)
+	}
+	// rule, seq, choice, seq, one or more, seq, lit
+	if u.visits != 7 {
+		t.Errorf("want 7 visits, got %d", u.visits)
+	}
+
+	// the root is replaced too
+	u = unwrapper{}
+	if got := WalkReplacing(&u, Seq(Seq(Any())), 10); typeName(got) != "AnyMatcher" {
+		t.Errorf("want an AnyMatcher root, got %v", got)
+	}
+
+	// the re-entries are limited
+	r = nested()
+	u = unwrapper{}
+	WalkReplacing(&u, r, 1)
+	if _, ok := r.Expr.(*SeqExpr); !ok {
+		t.Errorf("want a SeqExpr after a single re-entry, got %T", r.Expr)
+	}
+}
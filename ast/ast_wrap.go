@@ -0,0 +1,64 @@
+package ast
+
+import "strings"
+
+// Wrap returns a copy of the action whose code block is the code before,
+// followed by the statements of the code block of a, followed by the code
+// after, each on its own line inside the braces of the code block, e.g.
+// to instrument the actions of a grammar for tracing or profiling. As the
+// code block of an action usually ends with a return statement, the
+// teardown code is typically deferred in before rather than added in
+// after. The expression of the action is shared with a, which is not
+// modified.
+func (a *ActionExpr) Wrap(before, after string) *ActionExpr {
+	var body string
+	if a.Code != nil {
+		body = strings.TrimSpace(a.Code.Val)
+		body = strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")
+	}
+
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	for _, s := range []string{before, body, after} {
+		if s = strings.Trim(s, "\n"); strings.TrimSpace(s) != "" {
+			buf.WriteString(s)
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString("}")
+
+	act := &ActionExpr{
+		p:          a.p,
+		Expr:       a.Expr,
+		FuncIx:     a.FuncIx,
+		ReturnType: a.ReturnType,
+	}
+	pos := a.p
+	if a.Code != nil {
+		pos = a.Code.Pos()
+	}
+	act.Code = NewCodeBlock(pos, buf.String())
+	return act
+}
+
+// WrapAllActions returns a copy of the grammar where the code block of
+// each action is wrapped with the code before and after, as done by
+// ActionExpr.Wrap. The rules are deep copies, so that the grammar g is not
+// modified, but the initializer and the charsets are shared.
+func WrapAllActions(g *Grammar, before, after string) *Grammar {
+	wrapped := NewGrammar(g.p)
+	wrapped.Init = g.Init
+	wrapped.Charsets = g.Charsets
+	wrapped.Package = g.Package
+	wrapped.GoImports = g.GoImports
+	for _, r := range g.Rules {
+		wrapped.Rules = append(wrapped.Rules, cloneRule(r))
+	}
+	Inspect(wrapped, func(expr Expression) bool {
+		if act, ok := expr.(*ActionExpr); ok {
+			*act = *act.Wrap(before, after)
+		}
+		return true
+	})
+	return wrapped
+}
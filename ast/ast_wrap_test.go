@@ -0,0 +1,59 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+)
+
+func TestWrap(t *testing.T) {
+	act := ast.Action(ast.Lit("a"), "{ return string(c.text), nil }")
+	act.FuncIx = 3
+	got := act.Wrap("defer trace()()", "")
+	want := "{\ndefer trace()()\n return string(c.text), nil \n}"
+	if got.Code.Val != want {
+		t.Errorf("want code %q, got %q", want, got.Code.Val)
+	}
+	if got == act || got.Expr != act.Expr || got.FuncIx != act.FuncIx {
+		t.Errorf("want a copy of the action, got %v", got)
+	}
+	if act.Code.Val != "{ return string(c.text), nil }" {
+		t.Errorf("want original action unchanged, got %q", act.Code.Val)
+	}
+	if _, err := got.ParsedCode(); err != nil {
+		t.Errorf("want valid code, got %v", err)
+	}
+
+	got = act.Wrap("start()", "stop()")
+	want = "{\nstart()\n return string(c.text), nil \nstop()\n}"
+	if got.Code.Val != want {
+		t.Errorf("want code %q, got %q", want, got.Code.Val)
+	}
+}
+
+func TestWrapAllActions(t *testing.T) {
+	g := parseGrammar(t, `A = x:B { return x, nil } / "y"
+B = "b" { return 1, nil }`)
+	orig := g.String()
+
+	wrapped := ast.WrapAllActions(g, "defer trace()()", "")
+	if got := g.String(); got != orig {
+		t.Errorf("want original grammar\n%s\ngot\n%s", orig, got)
+	}
+	var n int
+	ast.Inspect(wrapped, func(expr ast.Expression) bool {
+		if act, ok := expr.(*ast.ActionExpr); ok {
+			n++
+			if _, err := act.ParsedCode(); err != nil {
+				t.Errorf("want valid code, got %v", err)
+			}
+			if want := "{\ndefer trace()()\n"; act.Code.Val[:len(want)] != want {
+				t.Errorf("want wrapped code, got %q", act.Code.Val)
+			}
+		}
+		return true
+	})
+	if n != 2 {
+		t.Errorf("want 2 actions, got %d", n)
+	}
+}
@@ -0,0 +1,2840 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        (unknown)
+// source: ast.proto
+
+package astpb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// Pos is a position in a source file.
+type Pos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Line     int64  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Col      int64  `protobuf:"varint,3,opt,name=col,proto3" json:"col,omitempty"`
+	Off      int64  `protobuf:"varint,4,opt,name=off,proto3" json:"off,omitempty"`
+}
+
+func (x *Pos) Reset() {
+	*x = Pos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pos) ProtoMessage() {}
+
+func (x *Pos) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pos.ProtoReflect.Descriptor instead.
+func (*Pos) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Pos) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Pos) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Pos) GetCol() int64 {
+	if x != nil {
+		return x.Col
+	}
+	return 0
+}
+
+func (x *Pos) GetOff() int64 {
+	if x != nil {
+		return x.Off
+	}
+	return 0
+}
+
+// Value is an identifier, a string literal or a code block.
+type Value struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Val string `protobuf:"bytes,1,opt,name=val,proto3" json:"val,omitempty"`
+	Pos *Pos   `protobuf:"bytes,2,opt,name=pos,proto3" json:"pos,omitempty"`
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Value) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+func (x *Value) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+// Grammar is the top-level node of the AST.
+type Grammar struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// schema_version is the version of this schema used to encode the
+	// grammar, see SchemaVersion.
+	SchemaVersion uint32     `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Pos           *Pos       `protobuf:"bytes,2,opt,name=pos,proto3" json:"pos,omitempty"`
+	Init          *Value     `protobuf:"bytes,3,opt,name=init,proto3" json:"init,omitempty"`
+	Rules         []*Rule    `protobuf:"bytes,4,rep,name=rules,proto3" json:"rules,omitempty"`
+	Charsets      []*Charset `protobuf:"bytes,5,rep,name=charsets,proto3" json:"charsets,omitempty"`
+	// package is the name set by the @package directive, if any.
+	Package *Value `protobuf:"bytes,6,opt,name=package,proto3" json:"package,omitempty"`
+	// go_imports are the quoted import paths set by the @import_go
+	// directives.
+	GoImports []*Value `protobuf:"bytes,7,rep,name=go_imports,json=goImports,proto3" json:"go_imports,omitempty"`
+}
+
+func (x *Grammar) Reset() {
+	*x = Grammar{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Grammar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grammar) ProtoMessage() {}
+
+func (x *Grammar) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grammar.ProtoReflect.Descriptor instead.
+func (*Grammar) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Grammar) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Grammar) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *Grammar) GetInit() *Value {
+	if x != nil {
+		return x.Init
+	}
+	return nil
+}
+
+func (x *Grammar) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Grammar) GetCharsets() []*Charset {
+	if x != nil {
+		return x.Charsets
+	}
+	return nil
+}
+
+func (x *Grammar) GetPackage() *Value {
+	if x != nil {
+		return x.Package
+	}
+	return nil
+}
+
+func (x *Grammar) GetGoImports() []*Value {
+	if x != nil {
+		return x.GoImports
+	}
+	return nil
+}
+
+// Charset is a named character set defined with @charset.
+type Charset struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos   *Pos              `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Name  *Value            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Class *CharClassMatcher `protobuf:"bytes,3,opt,name=class,proto3" json:"class,omitempty"`
+}
+
+func (x *Charset) Reset() {
+	*x = Charset{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Charset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Charset) ProtoMessage() {}
+
+func (x *Charset) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Charset.ProtoReflect.Descriptor instead.
+func (*Charset) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Charset) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *Charset) GetName() *Value {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+func (x *Charset) GetClass() *CharClassMatcher {
+	if x != nil {
+		return x.Class
+	}
+	return nil
+}
+
+type Rule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos         *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Name        *Value      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName *Value      `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Expr        *Expression `protobuf:"bytes,4,opt,name=expr,proto3" json:"expr,omitempty"`
+	Longest     bool        `protobuf:"varint,5,opt,name=longest,proto3" json:"longest,omitempty"`
+	ResultType  string      `protobuf:"bytes,6,opt,name=result_type,json=resultType,proto3" json:"result_type,omitempty"`
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Rule) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *Rule) GetName() *Value {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+func (x *Rule) GetDisplayName() *Value {
+	if x != nil {
+		return x.DisplayName
+	}
+	return nil
+}
+
+func (x *Rule) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+func (x *Rule) GetLongest() bool {
+	if x != nil {
+		return x.Longest
+	}
+	return false
+}
+
+func (x *Rule) GetResultType() string {
+	if x != nil {
+		return x.ResultType
+	}
+	return ""
+}
+
+// Expression is any of the expressions of the AST.
+type Expression struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Expr:
+	//	*Expression_Action
+	//	*Expression_AndCode
+	//	*Expression_And
+	//	*Expression_Any
+	//	*Expression_CharClass
+	//	*Expression_Choice
+	//	*Expression_Labeled
+	//	*Expression_Lit
+	//	*Expression_NotCode
+	//	*Expression_Not
+	//	*Expression_OneOrMore
+	//	*Expression_Recovery
+	//	*Expression_RuleRef
+	//	*Expression_Seq
+	//	*Expression_StateCode
+	//	*Expression_Throw
+	//	*Expression_ZeroOrMore
+	//	*Expression_ZeroOrOne
+	//	*Expression_Bol
+	//	*Expression_Keyword
+	//	*Expression_Lookbehind
+	//	*Expression_Regexp
+	Expr isExpression_Expr `protobuf_oneof:"expr"`
+}
+
+func (x *Expression) Reset() {
+	*x = Expression{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Expression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expression) ProtoMessage() {}
+
+func (x *Expression) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expression.ProtoReflect.Descriptor instead.
+func (*Expression) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{5}
+}
+
+func (m *Expression) GetExpr() isExpression_Expr {
+	if m != nil {
+		return m.Expr
+	}
+	return nil
+}
+
+func (x *Expression) GetAction() *ActionExpr {
+	if x, ok := x.GetExpr().(*Expression_Action); ok {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *Expression) GetAndCode() *AndCodeExpr {
+	if x, ok := x.GetExpr().(*Expression_AndCode); ok {
+		return x.AndCode
+	}
+	return nil
+}
+
+func (x *Expression) GetAnd() *AndExpr {
+	if x, ok := x.GetExpr().(*Expression_And); ok {
+		return x.And
+	}
+	return nil
+}
+
+func (x *Expression) GetAny() *AnyMatcher {
+	if x, ok := x.GetExpr().(*Expression_Any); ok {
+		return x.Any
+	}
+	return nil
+}
+
+func (x *Expression) GetCharClass() *CharClassMatcher {
+	if x, ok := x.GetExpr().(*Expression_CharClass); ok {
+		return x.CharClass
+	}
+	return nil
+}
+
+func (x *Expression) GetChoice() *ChoiceExpr {
+	if x, ok := x.GetExpr().(*Expression_Choice); ok {
+		return x.Choice
+	}
+	return nil
+}
+
+func (x *Expression) GetLabeled() *LabeledExpr {
+	if x, ok := x.GetExpr().(*Expression_Labeled); ok {
+		return x.Labeled
+	}
+	return nil
+}
+
+func (x *Expression) GetLit() *LitMatcher {
+	if x, ok := x.GetExpr().(*Expression_Lit); ok {
+		return x.Lit
+	}
+	return nil
+}
+
+func (x *Expression) GetNotCode() *NotCodeExpr {
+	if x, ok := x.GetExpr().(*Expression_NotCode); ok {
+		return x.NotCode
+	}
+	return nil
+}
+
+func (x *Expression) GetNot() *NotExpr {
+	if x, ok := x.GetExpr().(*Expression_Not); ok {
+		return x.Not
+	}
+	return nil
+}
+
+func (x *Expression) GetOneOrMore() *OneOrMoreExpr {
+	if x, ok := x.GetExpr().(*Expression_OneOrMore); ok {
+		return x.OneOrMore
+	}
+	return nil
+}
+
+func (x *Expression) GetRecovery() *RecoveryExpr {
+	if x, ok := x.GetExpr().(*Expression_Recovery); ok {
+		return x.Recovery
+	}
+	return nil
+}
+
+func (x *Expression) GetRuleRef() *RuleRefExpr {
+	if x, ok := x.GetExpr().(*Expression_RuleRef); ok {
+		return x.RuleRef
+	}
+	return nil
+}
+
+func (x *Expression) GetSeq() *SeqExpr {
+	if x, ok := x.GetExpr().(*Expression_Seq); ok {
+		return x.Seq
+	}
+	return nil
+}
+
+func (x *Expression) GetStateCode() *StateCodeExpr {
+	if x, ok := x.GetExpr().(*Expression_StateCode); ok {
+		return x.StateCode
+	}
+	return nil
+}
+
+func (x *Expression) GetThrow() *ThrowExpr {
+	if x, ok := x.GetExpr().(*Expression_Throw); ok {
+		return x.Throw
+	}
+	return nil
+}
+
+func (x *Expression) GetZeroOrMore() *ZeroOrMoreExpr {
+	if x, ok := x.GetExpr().(*Expression_ZeroOrMore); ok {
+		return x.ZeroOrMore
+	}
+	return nil
+}
+
+func (x *Expression) GetZeroOrOne() *ZeroOrOneExpr {
+	if x, ok := x.GetExpr().(*Expression_ZeroOrOne); ok {
+		return x.ZeroOrOne
+	}
+	return nil
+}
+
+func (x *Expression) GetBol() *BOLMatcher {
+	if x, ok := x.GetExpr().(*Expression_Bol); ok {
+		return x.Bol
+	}
+	return nil
+}
+
+func (x *Expression) GetKeyword() *KeywordMatcher {
+	if x, ok := x.GetExpr().(*Expression_Keyword); ok {
+		return x.Keyword
+	}
+	return nil
+}
+
+func (x *Expression) GetLookbehind() *LookbehindExpr {
+	if x, ok := x.GetExpr().(*Expression_Lookbehind); ok {
+		return x.Lookbehind
+	}
+	return nil
+}
+
+func (x *Expression) GetRegexp() *RegexpMatcher {
+	if x, ok := x.GetExpr().(*Expression_Regexp); ok {
+		return x.Regexp
+	}
+	return nil
+}
+
+type isExpression_Expr interface {
+	isExpression_Expr()
+}
+
+type Expression_Action struct {
+	Action *ActionExpr `protobuf:"bytes,1,opt,name=action,proto3,oneof"`
+}
+
+type Expression_AndCode struct {
+	AndCode *AndCodeExpr `protobuf:"bytes,2,opt,name=and_code,json=andCode,proto3,oneof"`
+}
+
+type Expression_And struct {
+	And *AndExpr `protobuf:"bytes,3,opt,name=and,proto3,oneof"`
+}
+
+type Expression_Any struct {
+	Any *AnyMatcher `protobuf:"bytes,4,opt,name=any,proto3,oneof"`
+}
+
+type Expression_CharClass struct {
+	CharClass *CharClassMatcher `protobuf:"bytes,5,opt,name=char_class,json=charClass,proto3,oneof"`
+}
+
+type Expression_Choice struct {
+	Choice *ChoiceExpr `protobuf:"bytes,6,opt,name=choice,proto3,oneof"`
+}
+
+type Expression_Labeled struct {
+	Labeled *LabeledExpr `protobuf:"bytes,7,opt,name=labeled,proto3,oneof"`
+}
+
+type Expression_Lit struct {
+	Lit *LitMatcher `protobuf:"bytes,8,opt,name=lit,proto3,oneof"`
+}
+
+type Expression_NotCode struct {
+	NotCode *NotCodeExpr `protobuf:"bytes,9,opt,name=not_code,json=notCode,proto3,oneof"`
+}
+
+type Expression_Not struct {
+	Not *NotExpr `protobuf:"bytes,10,opt,name=not,proto3,oneof"`
+}
+
+type Expression_OneOrMore struct {
+	OneOrMore *OneOrMoreExpr `protobuf:"bytes,11,opt,name=one_or_more,json=oneOrMore,proto3,oneof"`
+}
+
+type Expression_Recovery struct {
+	Recovery *RecoveryExpr `protobuf:"bytes,12,opt,name=recovery,proto3,oneof"`
+}
+
+type Expression_RuleRef struct {
+	RuleRef *RuleRefExpr `protobuf:"bytes,13,opt,name=rule_ref,json=ruleRef,proto3,oneof"`
+}
+
+type Expression_Seq struct {
+	Seq *SeqExpr `protobuf:"bytes,14,opt,name=seq,proto3,oneof"`
+}
+
+type Expression_StateCode struct {
+	StateCode *StateCodeExpr `protobuf:"bytes,15,opt,name=state_code,json=stateCode,proto3,oneof"`
+}
+
+type Expression_Throw struct {
+	Throw *ThrowExpr `protobuf:"bytes,16,opt,name=throw,proto3,oneof"`
+}
+
+type Expression_ZeroOrMore struct {
+	ZeroOrMore *ZeroOrMoreExpr `protobuf:"bytes,17,opt,name=zero_or_more,json=zeroOrMore,proto3,oneof"`
+}
+
+type Expression_ZeroOrOne struct {
+	ZeroOrOne *ZeroOrOneExpr `protobuf:"bytes,18,opt,name=zero_or_one,json=zeroOrOne,proto3,oneof"`
+}
+
+type Expression_Bol struct {
+	Bol *BOLMatcher `protobuf:"bytes,19,opt,name=bol,proto3,oneof"`
+}
+
+type Expression_Keyword struct {
+	Keyword *KeywordMatcher `protobuf:"bytes,20,opt,name=keyword,proto3,oneof"`
+}
+
+type Expression_Lookbehind struct {
+	Lookbehind *LookbehindExpr `protobuf:"bytes,21,opt,name=lookbehind,proto3,oneof"`
+}
+
+type Expression_Regexp struct {
+	Regexp *RegexpMatcher `protobuf:"bytes,22,opt,name=regexp,proto3,oneof"`
+}
+
+func (*Expression_Action) isExpression_Expr() {}
+
+func (*Expression_AndCode) isExpression_Expr() {}
+
+func (*Expression_And) isExpression_Expr() {}
+
+func (*Expression_Any) isExpression_Expr() {}
+
+func (*Expression_CharClass) isExpression_Expr() {}
+
+func (*Expression_Choice) isExpression_Expr() {}
+
+func (*Expression_Labeled) isExpression_Expr() {}
+
+func (*Expression_Lit) isExpression_Expr() {}
+
+func (*Expression_NotCode) isExpression_Expr() {}
+
+func (*Expression_Not) isExpression_Expr() {}
+
+func (*Expression_OneOrMore) isExpression_Expr() {}
+
+func (*Expression_Recovery) isExpression_Expr() {}
+
+func (*Expression_RuleRef) isExpression_Expr() {}
+
+func (*Expression_Seq) isExpression_Expr() {}
+
+func (*Expression_StateCode) isExpression_Expr() {}
+
+func (*Expression_Throw) isExpression_Expr() {}
+
+func (*Expression_ZeroOrMore) isExpression_Expr() {}
+
+func (*Expression_ZeroOrOne) isExpression_Expr() {}
+
+func (*Expression_Bol) isExpression_Expr() {}
+
+func (*Expression_Keyword) isExpression_Expr() {}
+
+func (*Expression_Lookbehind) isExpression_Expr() {}
+
+func (*Expression_Regexp) isExpression_Expr() {}
+
+type ActionExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos        *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Code       *Value      `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Expr       *Expression `protobuf:"bytes,3,opt,name=expr,proto3" json:"expr,omitempty"`
+	ReturnType string      `protobuf:"bytes,4,opt,name=return_type,json=returnType,proto3" json:"return_type,omitempty"`
+}
+
+func (x *ActionExpr) Reset() {
+	*x = ActionExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionExpr) ProtoMessage() {}
+
+func (x *ActionExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionExpr.ProtoReflect.Descriptor instead.
+func (*ActionExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ActionExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ActionExpr) GetCode() *Value {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+func (x *ActionExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+func (x *ActionExpr) GetReturnType() string {
+	if x != nil {
+		return x.ReturnType
+	}
+	return ""
+}
+
+type AndCodeExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Code *Value `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *AndCodeExpr) Reset() {
+	*x = AndCodeExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AndCodeExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AndCodeExpr) ProtoMessage() {}
+
+func (x *AndCodeExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AndCodeExpr.ProtoReflect.Descriptor instead.
+func (*AndCodeExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AndCodeExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *AndCodeExpr) GetCode() *Value {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+type AndExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *AndExpr) Reset() {
+	*x = AndExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AndExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AndExpr) ProtoMessage() {}
+
+func (x *AndExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AndExpr.ProtoReflect.Descriptor instead.
+func (*AndExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AndExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *AndExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+type AnyMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Val string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+}
+
+func (x *AnyMatcher) Reset() {
+	*x = AnyMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnyMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnyMatcher) ProtoMessage() {}
+
+func (x *AnyMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnyMatcher.ProtoReflect.Descriptor instead.
+func (*AnyMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnyMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *AnyMatcher) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+type BOLMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Val string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+}
+
+func (x *BOLMatcher) Reset() {
+	*x = BOLMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BOLMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BOLMatcher) ProtoMessage() {}
+
+func (x *BOLMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BOLMatcher.ProtoReflect.Descriptor instead.
+func (*BOLMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BOLMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *BOLMatcher) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+type CharClassMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos *Pos `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	// val is the raw character class, e.g. "[a-z]i".
+	Val string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	// charsets are the names of the charsets referenced by the class and
+	// not yet expanded.
+	Charsets []string `protobuf:"bytes,3,rep,name=charsets,proto3" json:"charsets,omitempty"`
+}
+
+func (x *CharClassMatcher) Reset() {
+	*x = CharClassMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CharClassMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CharClassMatcher) ProtoMessage() {}
+
+func (x *CharClassMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CharClassMatcher.ProtoReflect.Descriptor instead.
+func (*CharClassMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CharClassMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *CharClassMatcher) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+func (x *CharClassMatcher) GetCharsets() []string {
+	if x != nil {
+		return x.Charsets
+	}
+	return nil
+}
+
+type ChoiceExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos          *Pos          `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Alternatives []*Expression `protobuf:"bytes,2,rep,name=alternatives,proto3" json:"alternatives,omitempty"`
+	Longest      bool          `protobuf:"varint,3,opt,name=longest,proto3" json:"longest,omitempty"`
+}
+
+func (x *ChoiceExpr) Reset() {
+	*x = ChoiceExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChoiceExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChoiceExpr) ProtoMessage() {}
+
+func (x *ChoiceExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChoiceExpr.ProtoReflect.Descriptor instead.
+func (*ChoiceExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChoiceExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ChoiceExpr) GetAlternatives() []*Expression {
+	if x != nil {
+		return x.Alternatives
+	}
+	return nil
+}
+
+func (x *ChoiceExpr) GetLongest() bool {
+	if x != nil {
+		return x.Longest
+	}
+	return false
+}
+
+type KeywordMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos      *Pos     `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Keywords []string `protobuf:"bytes,2,rep,name=keywords,proto3" json:"keywords,omitempty"`
+}
+
+func (x *KeywordMatcher) Reset() {
+	*x = KeywordMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeywordMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeywordMatcher) ProtoMessage() {}
+
+func (x *KeywordMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeywordMatcher.ProtoReflect.Descriptor instead.
+func (*KeywordMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *KeywordMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *KeywordMatcher) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+type LabeledExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos   *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Label *Value      `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Expr  *Expression `protobuf:"bytes,3,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *LabeledExpr) Reset() {
+	*x = LabeledExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LabeledExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabeledExpr) ProtoMessage() {}
+
+func (x *LabeledExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabeledExpr.ProtoReflect.Descriptor instead.
+func (*LabeledExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LabeledExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *LabeledExpr) GetLabel() *Value {
+	if x != nil {
+		return x.Label
+	}
+	return nil
+}
+
+func (x *LabeledExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+type LitMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos        *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Val        string `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	IgnoreCase bool   `protobuf:"varint,3,opt,name=ignore_case,json=ignoreCase,proto3" json:"ignore_case,omitempty"`
+}
+
+func (x *LitMatcher) Reset() {
+	*x = LitMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LitMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LitMatcher) ProtoMessage() {}
+
+func (x *LitMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LitMatcher.ProtoReflect.Descriptor instead.
+func (*LitMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LitMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *LitMatcher) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+func (x *LitMatcher) GetIgnoreCase() bool {
+	if x != nil {
+		return x.IgnoreCase
+	}
+	return false
+}
+
+type LookbehindExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos    *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr   *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+	Negate bool        `protobuf:"varint,3,opt,name=negate,proto3" json:"negate,omitempty"`
+}
+
+func (x *LookbehindExpr) Reset() {
+	*x = LookbehindExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LookbehindExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookbehindExpr) ProtoMessage() {}
+
+func (x *LookbehindExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookbehindExpr.ProtoReflect.Descriptor instead.
+func (*LookbehindExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LookbehindExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *LookbehindExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+func (x *LookbehindExpr) GetNegate() bool {
+	if x != nil {
+		return x.Negate
+	}
+	return false
+}
+
+type NotCodeExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Code *Value `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *NotCodeExpr) Reset() {
+	*x = NotCodeExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NotCodeExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotCodeExpr) ProtoMessage() {}
+
+func (x *NotCodeExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotCodeExpr.ProtoReflect.Descriptor instead.
+func (*NotCodeExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NotCodeExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *NotCodeExpr) GetCode() *Value {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+type NotExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *NotExpr) Reset() {
+	*x = NotExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NotExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotExpr) ProtoMessage() {}
+
+func (x *NotExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotExpr.ProtoReflect.Descriptor instead.
+func (*NotExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *NotExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *NotExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+type OneOrMoreExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *OneOrMoreExpr) Reset() {
+	*x = OneOrMoreExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OneOrMoreExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OneOrMoreExpr) ProtoMessage() {}
+
+func (x *OneOrMoreExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OneOrMoreExpr.ProtoReflect.Descriptor instead.
+func (*OneOrMoreExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OneOrMoreExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *OneOrMoreExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+type RecoveryExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos         *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr        *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+	RecoverExpr *Expression `protobuf:"bytes,3,opt,name=recover_expr,json=recoverExpr,proto3" json:"recover_expr,omitempty"`
+	Labels      []string    `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty"`
+}
+
+func (x *RecoveryExpr) Reset() {
+	*x = RecoveryExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecoveryExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecoveryExpr) ProtoMessage() {}
+
+func (x *RecoveryExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecoveryExpr.ProtoReflect.Descriptor instead.
+func (*RecoveryExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RecoveryExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *RecoveryExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+func (x *RecoveryExpr) GetRecoverExpr() *Expression {
+	if x != nil {
+		return x.RecoverExpr
+	}
+	return nil
+}
+
+func (x *RecoveryExpr) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type RegexpMatcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr string `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *RegexpMatcher) Reset() {
+	*x = RegexpMatcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegexpMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegexpMatcher) ProtoMessage() {}
+
+func (x *RegexpMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegexpMatcher.ProtoReflect.Descriptor instead.
+func (*RegexpMatcher) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RegexpMatcher) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *RegexpMatcher) GetExpr() string {
+	if x != nil {
+		return x.Expr
+	}
+	return ""
+}
+
+type RuleRefExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Name *Value `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *RuleRefExpr) Reset() {
+	*x = RuleRefExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleRefExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleRefExpr) ProtoMessage() {}
+
+func (x *RuleRefExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleRefExpr.ProtoReflect.Descriptor instead.
+func (*RuleRefExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RuleRefExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *RuleRefExpr) GetName() *Value {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+type SeqExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos   *Pos          `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Exprs []*Expression `protobuf:"bytes,2,rep,name=exprs,proto3" json:"exprs,omitempty"`
+}
+
+func (x *SeqExpr) Reset() {
+	*x = SeqExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SeqExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeqExpr) ProtoMessage() {}
+
+func (x *SeqExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeqExpr.ProtoReflect.Descriptor instead.
+func (*SeqExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SeqExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *SeqExpr) GetExprs() []*Expression {
+	if x != nil {
+		return x.Exprs
+	}
+	return nil
+}
+
+type StateCodeExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Code *Value `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *StateCodeExpr) Reset() {
+	*x = StateCodeExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StateCodeExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateCodeExpr) ProtoMessage() {}
+
+func (x *StateCodeExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateCodeExpr.ProtoReflect.Descriptor instead.
+func (*StateCodeExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *StateCodeExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *StateCodeExpr) GetCode() *Value {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+type ThrowExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos   *Pos   `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Label string `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+}
+
+func (x *ThrowExpr) Reset() {
+	*x = ThrowExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThrowExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThrowExpr) ProtoMessage() {}
+
+func (x *ThrowExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThrowExpr.ProtoReflect.Descriptor instead.
+func (*ThrowExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ThrowExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ThrowExpr) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type ZeroOrMoreExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *ZeroOrMoreExpr) Reset() {
+	*x = ZeroOrMoreExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZeroOrMoreExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZeroOrMoreExpr) ProtoMessage() {}
+
+func (x *ZeroOrMoreExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZeroOrMoreExpr.ProtoReflect.Descriptor instead.
+func (*ZeroOrMoreExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ZeroOrMoreExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ZeroOrMoreExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+type ZeroOrOneExpr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pos  *Pos        `protobuf:"bytes,1,opt,name=pos,proto3" json:"pos,omitempty"`
+	Expr *Expression `protobuf:"bytes,2,opt,name=expr,proto3" json:"expr,omitempty"`
+}
+
+func (x *ZeroOrOneExpr) Reset() {
+	*x = ZeroOrOneExpr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ast_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZeroOrOneExpr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZeroOrOneExpr) ProtoMessage() {}
+
+func (x *ZeroOrOneExpr) ProtoReflect() protoreflect.Message {
+	mi := &file_ast_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZeroOrOneExpr.ProtoReflect.Descriptor instead.
+func (*ZeroOrOneExpr) Descriptor() ([]byte, []int) {
+	return file_ast_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ZeroOrOneExpr) GetPos() *Pos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ZeroOrOneExpr) GetExpr() *Expression {
+	if x != nil {
+		return x.Expr
+	}
+	return nil
+}
+
+var File_ast_proto protoreflect.FileDescriptor
+
+var file_ast_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x61, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x22, 0x59, 0x0a, 0x03, 0x50, 0x6f, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69,
+	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x63, 0x6f, 0x6c,
+	0x12, 0x10, 0x0a, 0x03, 0x6f, 0x66, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6f,
+	0x66, 0x66, 0x22, 0x3c, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x76,
+	0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x12, 0x21, 0x0a,
+	0x03, 0x70, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x22, 0xb2, 0x02, 0x0a, 0x07, 0x47, 0x72, 0x61, 0x6d, 0x6d, 0x61, 0x72, 0x12, 0x25, 0x0a, 0x0e,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f,
+	0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x69, 0x6e, 0x69, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x69, 0x6e, 0x69, 0x74, 0x12, 0x26, 0x0a,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70,
+	0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05,
+	0x72, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x2f, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x43, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74, 0x52, 0x08, 0x63, 0x68,
+	0x61, 0x72, 0x73, 0x65, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b,
+	0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x0a, 0x67, 0x6f, 0x5f, 0x69, 0x6d, 0x70, 0x6f, 0x72, 0x74,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x09, 0x67, 0x6f, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x72, 0x73, 0x65,
+	0x74, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52,
+	0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x05, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x43, 0x68, 0x61, 0x72, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x22,
+	0xed, 0x01, 0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61,
+	0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65,
+	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x34, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f,
+	0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0b, 0x64, 0x69, 0x73,
+	0x70, 0x6c, 0x61, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e,
+	0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04,
+	0x65, 0x78, 0x70, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1f,
+	0x0a, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x54, 0x79, 0x70, 0x65, 0x22,
+	0x95, 0x09, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x30,
+	0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x34, 0x0a, 0x08, 0x61, 0x6e, 0x64, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e,
+	0x41, 0x6e, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x07, 0x61,
+	0x6e, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x27, 0x0a, 0x03, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x41, 0x6e, 0x64, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x03, 0x61, 0x6e, 0x64, 0x12,
+	0x2a, 0x0a, 0x03, 0x61, 0x6e, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70,
+	0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52, 0x03, 0x61, 0x6e, 0x79, 0x12, 0x3d, 0x0a, 0x0a, 0x63,
+	0x68, 0x61, 0x72, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x43, 0x68, 0x61,
+	0x72, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52,
+	0x09, 0x63, 0x68, 0x61, 0x72, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x30, 0x0a, 0x06, 0x63, 0x68,
+	0x6f, 0x69, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x45, 0x78,
+	0x70, 0x72, 0x48, 0x00, 0x52, 0x06, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x33, 0x0a, 0x07,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x65, 0x64, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x07, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x65,
+	0x64, 0x12, 0x2a, 0x0a, 0x03, 0x6c, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4c, 0x69, 0x74, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x69, 0x74, 0x12, 0x34, 0x0a,
+	0x08, 0x6e, 0x6f, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4e, 0x6f, 0x74,
+	0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x6f, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x27, 0x0a, 0x03, 0x6e, 0x6f, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4e, 0x6f,
+	0x74, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x03, 0x6e, 0x6f, 0x74, 0x12, 0x3b, 0x0a, 0x0b,
+	0x6f, 0x6e, 0x65, 0x5f, 0x6f, 0x72, 0x5f, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4f,
+	0x6e, 0x65, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x09,
+	0x6f, 0x6e, 0x65, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x72, 0x65, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x69,
+	0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x79, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x79, 0x12, 0x34, 0x0a, 0x08, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x66, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x07,
+	0x72, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x12, 0x27, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x74, 0x2e, 0x53, 0x65, 0x71, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x03, 0x73, 0x65, 0x71,
+	0x12, 0x3a, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x74, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48,
+	0x00, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x2d, 0x0a, 0x05,
+	0x74, 0x68, 0x72, 0x6f, 0x77, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x69,
+	0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x54, 0x68, 0x72, 0x6f, 0x77, 0x45, 0x78,
+	0x70, 0x72, 0x48, 0x00, 0x52, 0x05, 0x74, 0x68, 0x72, 0x6f, 0x77, 0x12, 0x3e, 0x0a, 0x0c, 0x7a,
+	0x65, 0x72, 0x6f, 0x5f, 0x6f, 0x72, 0x5f, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x5a,
+	0x65, 0x72, 0x6f, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52,
+	0x0a, 0x7a, 0x65, 0x72, 0x6f, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x7a,
+	0x65, 0x72, 0x6f, 0x5f, 0x6f, 0x72, 0x5f, 0x6f, 0x6e, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x5a, 0x65,
+	0x72, 0x6f, 0x4f, 0x72, 0x4f, 0x6e, 0x65, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x09, 0x7a,
+	0x65, 0x72, 0x6f, 0x4f, 0x72, 0x4f, 0x6e, 0x65, 0x12, 0x2a, 0x0a, 0x03, 0x62, 0x6f, 0x6c, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61,
+	0x73, 0x74, 0x2e, 0x42, 0x4f, 0x4c, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52,
+	0x03, 0x62, 0x6f, 0x6c, 0x12, 0x36, 0x0a, 0x07, 0x6b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61,
+	0x73, 0x74, 0x2e, 0x4b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x72, 0x48, 0x00, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x3c, 0x0a, 0x0a,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x62, 0x65, 0x68, 0x69, 0x6e, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x62, 0x65, 0x68, 0x69, 0x6e, 0x64, 0x45, 0x78, 0x70, 0x72, 0x48, 0x00, 0x52, 0x0a,
+	0x6c, 0x6f, 0x6f, 0x6b, 0x62, 0x65, 0x68, 0x69, 0x6e, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x72, 0x65,
+	0x67, 0x65, 0x78, 0x70, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x67, 0x65, 0x78, 0x70, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x67, 0x65, 0x78, 0x70, 0x42,
+	0x06, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0xa3, 0x01, 0x0a, 0x0a, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65,
+	0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x12, 0x1f, 0x0a, 0x0b,
+	0x72, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x72, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x22, 0x57, 0x0a,
+	0x0b, 0x41, 0x6e, 0x64, 0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03,
+	0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65,
+	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12,
+	0x25, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x58, 0x0a, 0x07, 0x41, 0x6e, 0x64, 0x45, 0x78, 0x70,
+	0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52,
+	0x03, 0x70, 0x6f, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e,
+	0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72,
+	0x22, 0x41, 0x0a, 0x0a, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x21,
+	0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69,
+	0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x76, 0x61, 0x6c, 0x22, 0x41, 0x0a, 0x0a, 0x42, 0x4f, 0x4c, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52,
+	0x03, 0x70, 0x6f, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x22, 0x63, 0x0a, 0x10, 0x43, 0x68, 0x61, 0x72, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x10, 0x0a,
+	0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x08, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x0a,
+	0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x3a, 0x0a,
+	0x0c, 0x61, 0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x61, 0x6c, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x6e,
+	0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x6f, 0x6e, 0x67,
+	0x65, 0x73, 0x74, 0x22, 0x4f, 0x0a, 0x0e, 0x4b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e,
+	0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x77,
+	0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6b, 0x65, 0x79, 0x77,
+	0x6f, 0x72, 0x64, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x65, 0x64,
+	0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50,
+	0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x27, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e,
+	0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0x62, 0x0a, 0x0a,
+	0x4c, 0x69, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x10, 0x0a,
+	0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x12,
+	0x1f, 0x0a, 0x0b, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x5f, 0x63, 0x61, 0x73, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x43, 0x61, 0x73, 0x65,
+	0x22, 0x77, 0x0a, 0x0e, 0x4c, 0x6f, 0x6f, 0x6b, 0x62, 0x65, 0x68, 0x69, 0x6e, 0x64, 0x45, 0x78,
+	0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73,
+	0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x65, 0x22, 0x57, 0x0a, 0x0b, 0x4e, 0x6f, 0x74,
+	0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61,
+	0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65,
+	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x22, 0x58, 0x0a, 0x07, 0x4e, 0x6f, 0x74, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a,
+	0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0x5e, 0x0a, 0x0d,
+	0x4f, 0x6e, 0x65, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a,
+	0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0xb0, 0x01, 0x0a,
+	0x0c, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a,
+	0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x12, 0x39, 0x0a, 0x0c,
+	0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e,
+	0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x45, 0x78, 0x70, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x22,
+	0x46, 0x0a, 0x0d, 0x52, 0x65, 0x67, 0x65, 0x78, 0x70, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72,
+	0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03,
+	0x70, 0x6f, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0x57, 0x0a, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x52,
+	0x65, 0x66, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74,
+	0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x22, 0x5a, 0x0a, 0x07, 0x53, 0x65, 0x71, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70,
+	0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f,
+	0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x2c,
+	0x0a, 0x05, 0x65, 0x78, 0x70, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x65, 0x78, 0x70, 0x72, 0x73, 0x22, 0x59, 0x0a, 0x0d,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a,
+	0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67,
+	0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73,
+	0x12, 0x25, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x44, 0x0a, 0x09, 0x54, 0x68, 0x72, 0x6f, 0x77,
+	0x45, 0x78, 0x70, 0x72, 0x12, 0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50,
+	0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x22, 0x5f, 0x0a,
+	0x0e, 0x5a, 0x65, 0x72, 0x6f, 0x4f, 0x72, 0x4d, 0x6f, 0x72, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12,
+	0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70,
+	0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70,
+	0x6f, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22, 0x5e,
+	0x0a, 0x0d, 0x5a, 0x65, 0x72, 0x6f, 0x4f, 0x72, 0x4f, 0x6e, 0x65, 0x45, 0x78, 0x70, 0x72, 0x12,
+	0x21, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70,
+	0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70,
+	0x6f, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x74, 0x2e, 0x45, 0x78,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x78, 0x70, 0x72, 0x42, 0x21,
+	0x5a, 0x1f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x6e, 0x61,
+	0x2f, 0x70, 0x69, 0x67, 0x65, 0x6f, 0x6e, 0x2f, 0x61, 0x73, 0x74, 0x2f, 0x61, 0x73, 0x74, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ast_proto_rawDescOnce sync.Once
+	file_ast_proto_rawDescData = file_ast_proto_rawDesc
+)
+
+func file_ast_proto_rawDescGZIP() []byte {
+	file_ast_proto_rawDescOnce.Do(func() {
+		file_ast_proto_rawDescData = protoimpl.X.CompressGZIP(file_ast_proto_rawDescData)
+	})
+	return file_ast_proto_rawDescData
+}
+
+var file_ast_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_ast_proto_goTypes = []interface{}{
+	(*Pos)(nil),              // 0: pigeon.ast.Pos
+	(*Value)(nil),            // 1: pigeon.ast.Value
+	(*Grammar)(nil),          // 2: pigeon.ast.Grammar
+	(*Charset)(nil),          // 3: pigeon.ast.Charset
+	(*Rule)(nil),             // 4: pigeon.ast.Rule
+	(*Expression)(nil),       // 5: pigeon.ast.Expression
+	(*ActionExpr)(nil),       // 6: pigeon.ast.ActionExpr
+	(*AndCodeExpr)(nil),      // 7: pigeon.ast.AndCodeExpr
+	(*AndExpr)(nil),          // 8: pigeon.ast.AndExpr
+	(*AnyMatcher)(nil),       // 9: pigeon.ast.AnyMatcher
+	(*BOLMatcher)(nil),       // 10: pigeon.ast.BOLMatcher
+	(*CharClassMatcher)(nil), // 11: pigeon.ast.CharClassMatcher
+	(*ChoiceExpr)(nil),       // 12: pigeon.ast.ChoiceExpr
+	(*KeywordMatcher)(nil),   // 13: pigeon.ast.KeywordMatcher
+	(*LabeledExpr)(nil),      // 14: pigeon.ast.LabeledExpr
+	(*LitMatcher)(nil),       // 15: pigeon.ast.LitMatcher
+	(*LookbehindExpr)(nil),   // 16: pigeon.ast.LookbehindExpr
+	(*NotCodeExpr)(nil),      // 17: pigeon.ast.NotCodeExpr
+	(*NotExpr)(nil),          // 18: pigeon.ast.NotExpr
+	(*OneOrMoreExpr)(nil),    // 19: pigeon.ast.OneOrMoreExpr
+	(*RecoveryExpr)(nil),     // 20: pigeon.ast.RecoveryExpr
+	(*RegexpMatcher)(nil),    // 21: pigeon.ast.RegexpMatcher
+	(*RuleRefExpr)(nil),      // 22: pigeon.ast.RuleRefExpr
+	(*SeqExpr)(nil),          // 23: pigeon.ast.SeqExpr
+	(*StateCodeExpr)(nil),    // 24: pigeon.ast.StateCodeExpr
+	(*ThrowExpr)(nil),        // 25: pigeon.ast.ThrowExpr
+	(*ZeroOrMoreExpr)(nil),   // 26: pigeon.ast.ZeroOrMoreExpr
+	(*ZeroOrOneExpr)(nil),    // 27: pigeon.ast.ZeroOrOneExpr
+}
+var file_ast_proto_depIdxs = []int32{
+	0,  // 0: pigeon.ast.Value.pos:type_name -> pigeon.ast.Pos
+	0,  // 1: pigeon.ast.Grammar.pos:type_name -> pigeon.ast.Pos
+	1,  // 2: pigeon.ast.Grammar.init:type_name -> pigeon.ast.Value
+	4,  // 3: pigeon.ast.Grammar.rules:type_name -> pigeon.ast.Rule
+	3,  // 4: pigeon.ast.Grammar.charsets:type_name -> pigeon.ast.Charset
+	1,  // 5: pigeon.ast.Grammar.package:type_name -> pigeon.ast.Value
+	1,  // 6: pigeon.ast.Grammar.go_imports:type_name -> pigeon.ast.Value
+	0,  // 7: pigeon.ast.Charset.pos:type_name -> pigeon.ast.Pos
+	1,  // 8: pigeon.ast.Charset.name:type_name -> pigeon.ast.Value
+	11, // 9: pigeon.ast.Charset.class:type_name -> pigeon.ast.CharClassMatcher
+	0,  // 10: pigeon.ast.Rule.pos:type_name -> pigeon.ast.Pos
+	1,  // 11: pigeon.ast.Rule.name:type_name -> pigeon.ast.Value
+	1,  // 12: pigeon.ast.Rule.display_name:type_name -> pigeon.ast.Value
+	5,  // 13: pigeon.ast.Rule.expr:type_name -> pigeon.ast.Expression
+	6,  // 14: pigeon.ast.Expression.action:type_name -> pigeon.ast.ActionExpr
+	7,  // 15: pigeon.ast.Expression.and_code:type_name -> pigeon.ast.AndCodeExpr
+	8,  // 16: pigeon.ast.Expression.and:type_name -> pigeon.ast.AndExpr
+	9,  // 17: pigeon.ast.Expression.any:type_name -> pigeon.ast.AnyMatcher
+	11, // 18: pigeon.ast.Expression.char_class:type_name -> pigeon.ast.CharClassMatcher
+	12, // 19: pigeon.ast.Expression.choice:type_name -> pigeon.ast.ChoiceExpr
+	14, // 20: pigeon.ast.Expression.labeled:type_name -> pigeon.ast.LabeledExpr
+	15, // 21: pigeon.ast.Expression.lit:type_name -> pigeon.ast.LitMatcher
+	17, // 22: pigeon.ast.Expression.not_code:type_name -> pigeon.ast.NotCodeExpr
+	18, // 23: pigeon.ast.Expression.not:type_name -> pigeon.ast.NotExpr
+	19, // 24: pigeon.ast.Expression.one_or_more:type_name -> pigeon.ast.OneOrMoreExpr
+	20, // 25: pigeon.ast.Expression.recovery:type_name -> pigeon.ast.RecoveryExpr
+	22, // 26: pigeon.ast.Expression.rule_ref:type_name -> pigeon.ast.RuleRefExpr
+	23, // 27: pigeon.ast.Expression.seq:type_name -> pigeon.ast.SeqExpr
+	24, // 28: pigeon.ast.Expression.state_code:type_name -> pigeon.ast.StateCodeExpr
+	25, // 29: pigeon.ast.Expression.throw:type_name -> pigeon.ast.ThrowExpr
+	26, // 30: pigeon.ast.Expression.zero_or_more:type_name -> pigeon.ast.ZeroOrMoreExpr
+	27, // 31: pigeon.ast.Expression.zero_or_one:type_name -> pigeon.ast.ZeroOrOneExpr
+	10, // 32: pigeon.ast.Expression.bol:type_name -> pigeon.ast.BOLMatcher
+	13, // 33: pigeon.ast.Expression.keyword:type_name -> pigeon.ast.KeywordMatcher
+	16, // 34: pigeon.ast.Expression.lookbehind:type_name -> pigeon.ast.LookbehindExpr
+	21, // 35: pigeon.ast.Expression.regexp:type_name -> pigeon.ast.RegexpMatcher
+	0,  // 36: pigeon.ast.ActionExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 37: pigeon.ast.ActionExpr.code:type_name -> pigeon.ast.Value
+	5,  // 38: pigeon.ast.ActionExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 39: pigeon.ast.AndCodeExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 40: pigeon.ast.AndCodeExpr.code:type_name -> pigeon.ast.Value
+	0,  // 41: pigeon.ast.AndExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 42: pigeon.ast.AndExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 43: pigeon.ast.AnyMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 44: pigeon.ast.BOLMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 45: pigeon.ast.CharClassMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 46: pigeon.ast.ChoiceExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 47: pigeon.ast.ChoiceExpr.alternatives:type_name -> pigeon.ast.Expression
+	0,  // 48: pigeon.ast.KeywordMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 49: pigeon.ast.LabeledExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 50: pigeon.ast.LabeledExpr.label:type_name -> pigeon.ast.Value
+	5,  // 51: pigeon.ast.LabeledExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 52: pigeon.ast.LitMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 53: pigeon.ast.LookbehindExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 54: pigeon.ast.LookbehindExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 55: pigeon.ast.NotCodeExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 56: pigeon.ast.NotCodeExpr.code:type_name -> pigeon.ast.Value
+	0,  // 57: pigeon.ast.NotExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 58: pigeon.ast.NotExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 59: pigeon.ast.OneOrMoreExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 60: pigeon.ast.OneOrMoreExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 61: pigeon.ast.RecoveryExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 62: pigeon.ast.RecoveryExpr.expr:type_name -> pigeon.ast.Expression
+	5,  // 63: pigeon.ast.RecoveryExpr.recover_expr:type_name -> pigeon.ast.Expression
+	0,  // 64: pigeon.ast.RegexpMatcher.pos:type_name -> pigeon.ast.Pos
+	0,  // 65: pigeon.ast.RuleRefExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 66: pigeon.ast.RuleRefExpr.name:type_name -> pigeon.ast.Value
+	0,  // 67: pigeon.ast.SeqExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 68: pigeon.ast.SeqExpr.exprs:type_name -> pigeon.ast.Expression
+	0,  // 69: pigeon.ast.StateCodeExpr.pos:type_name -> pigeon.ast.Pos
+	1,  // 70: pigeon.ast.StateCodeExpr.code:type_name -> pigeon.ast.Value
+	0,  // 71: pigeon.ast.ThrowExpr.pos:type_name -> pigeon.ast.Pos
+	0,  // 72: pigeon.ast.ZeroOrMoreExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 73: pigeon.ast.ZeroOrMoreExpr.expr:type_name -> pigeon.ast.Expression
+	0,  // 74: pigeon.ast.ZeroOrOneExpr.pos:type_name -> pigeon.ast.Pos
+	5,  // 75: pigeon.ast.ZeroOrOneExpr.expr:type_name -> pigeon.ast.Expression
+	76, // [76:76] is the sub-list for method output_type
+	76, // [76:76] is the sub-list for method input_type
+	76, // [76:76] is the sub-list for extension type_name
+	76, // [76:76] is the sub-list for extension extendee
+	0,  // [0:76] is the sub-list for field type_name
+}
+
+func init() { file_ast_proto_init() }
+func file_ast_proto_init() {
+	if File_ast_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ast_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Pos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Value); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Grammar); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Charset); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expression); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActionExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AndCodeExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AndExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnyMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BOLMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CharClassMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChoiceExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeywordMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LabeledExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LitMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LookbehindExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NotCodeExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NotExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OneOrMoreExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecoveryExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegexpMatcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleRefExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SeqExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StateCodeExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThrowExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZeroOrMoreExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ast_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZeroOrOneExpr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_ast_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*Expression_Action)(nil),
+		(*Expression_AndCode)(nil),
+		(*Expression_And)(nil),
+		(*Expression_Any)(nil),
+		(*Expression_CharClass)(nil),
+		(*Expression_Choice)(nil),
+		(*Expression_Labeled)(nil),
+		(*Expression_Lit)(nil),
+		(*Expression_NotCode)(nil),
+		(*Expression_Not)(nil),
+		(*Expression_OneOrMore)(nil),
+		(*Expression_Recovery)(nil),
+		(*Expression_RuleRef)(nil),
+		(*Expression_Seq)(nil),
+		(*Expression_StateCode)(nil),
+		(*Expression_Throw)(nil),
+		(*Expression_ZeroOrMore)(nil),
+		(*Expression_ZeroOrOne)(nil),
+		(*Expression_Bol)(nil),
+		(*Expression_Keyword)(nil),
+		(*Expression_Lookbehind)(nil),
+		(*Expression_Regexp)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ast_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   28,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ast_proto_goTypes,
+		DependencyIndexes: file_ast_proto_depIdxs,
+		MessageInfos:      file_ast_proto_msgTypes,
+	}.Build()
+	File_ast_proto = out.File
+	file_ast_proto_rawDesc = nil
+	file_ast_proto_goTypes = nil
+	file_ast_proto_depIdxs = nil
+}
@@ -0,0 +1,453 @@
+// Package astpb implements the Protocol Buffer encoding of the grammar AST
+// of package ast. The messages are defined in ast.proto, and ast.pb.go is
+// generated from it by protoc-gen-go.
+//
+// The information computed by the optimizer and the builder (e.g. the Opt
+// flags) is not encoded.
+package astpb
+
+//go:generate protoc --go_out=paths=source_relative:. ast.proto
+
+import (
+	"fmt"
+
+	"github.com/mna/pigeon/ast"
+	"google.golang.org/protobuf/proto"
+)
+
+// SchemaVersion is the version of the schema defined in ast.proto. It is
+// stored in the encoded grammars, so that a grammar encoded with a newer,
+// unsupported schema is rejected.
+const SchemaVersion = 2
+
+// Marshal returns the Protocol Buffer encoding of g. It returns an error if
+// g holds an expression that can't be encoded.
+func Marshal(g *ast.Grammar) ([]byte, error) {
+	m, err := FromAST(g)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes the Protocol Buffer encoding of a grammar, as returned
+// by Marshal.
+func Unmarshal(b []byte) (*ast.Grammar, error) {
+	var m Grammar
+	if err := proto.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return ToAST(&m)
+}
+
+// FromAST converts g to its Protocol Buffer message. It returns an error if
+// g holds an expression that can't be encoded.
+func FromAST(g *ast.Grammar) (*Grammar, error) {
+	m := &Grammar{
+		SchemaVersion: SchemaVersion,
+		Pos:           fromPos(g.Pos()),
+		Package:       fromIdentifier(g.Package),
+	}
+	if g.Init != nil {
+		m.Init = fromValue(g.Init.Pos(), g.Init.Val)
+	}
+	for _, cs := range g.Charsets {
+		mc := &Charset{Pos: fromPos(cs.Pos()), Name: fromIdentifier(cs.Name)}
+		if cs.Class != nil {
+			mc.Class = fromCharClass(cs.Class)
+		}
+		m.Charsets = append(m.Charsets, mc)
+	}
+	for _, imp := range g.GoImports {
+		m.GoImports = append(m.GoImports, fromValue(imp.Pos(), imp.Val))
+	}
+	for _, r := range g.Rules {
+		mr, err := fromRule(r)
+		if err != nil {
+			return nil, err
+		}
+		m.Rules = append(m.Rules, mr)
+	}
+	return m, nil
+}
+
+// ToAST converts the Protocol Buffer message m to a grammar. It returns an
+// error if m was encoded with an unsupported schema version or if it holds
+// an invalid expression.
+func ToAST(m *Grammar) (*ast.Grammar, error) {
+	if m.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d", m.SchemaVersion)
+	}
+
+	g := ast.NewGrammar(toPos(m.Pos))
+	if m.Init != nil {
+		g.Init = ast.NewCodeBlock(toPos(m.Init.Pos), m.Init.Val)
+	}
+	g.Package = toIdentifier(m.Package)
+	for _, mc := range m.Charsets {
+		cs := ast.NewCharset(toPos(mc.Pos), toIdentifier(mc.Name))
+		if mc.Class != nil {
+			cs.Class = toCharClass(mc.Class)
+		}
+		g.Charsets = append(g.Charsets, cs)
+	}
+	for _, imp := range m.GoImports {
+		g.GoImports = append(g.GoImports, ast.NewStringLit(toPos(imp.Pos), imp.Val))
+	}
+	for _, mr := range m.Rules {
+		r := ast.NewRule(toPos(mr.Pos), toIdentifier(mr.Name))
+		if mr.DisplayName != nil {
+			r.DisplayName = ast.NewStringLit(toPos(mr.DisplayName.Pos), mr.DisplayName.Val)
+		}
+		r.Longest = mr.Longest
+		r.ResultType = mr.ResultType
+		expr, err := toExpr(mr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		r.Expr = expr
+		g.Rules = append(g.Rules, r)
+	}
+	return g, nil
+}
+
+func fromPos(p ast.Pos) *Pos {
+	return &Pos{Filename: p.Filename, Line: int64(p.Line), Col: int64(p.Col), Off: int64(p.Off)}
+}
+
+func toPos(p *Pos) ast.Pos {
+	if p == nil {
+		return ast.Pos{}
+	}
+	return ast.Pos{Filename: p.Filename, Line: int(p.Line), Col: int(p.Col), Off: int(p.Off)}
+}
+
+func fromValue(p ast.Pos, val string) *Value {
+	return &Value{Val: val, Pos: fromPos(p)}
+}
+
+func fromIdentifier(id *ast.Identifier) *Value {
+	if id == nil {
+		return nil
+	}
+	return fromValue(id.Pos(), id.Val)
+}
+
+func toIdentifier(v *Value) *ast.Identifier {
+	if v == nil {
+		return nil
+	}
+	return ast.NewIdentifier(toPos(v.Pos), v.Val)
+}
+
+func fromCodeBlock(code *ast.CodeBlock) *Value {
+	if code == nil {
+		return nil
+	}
+	return fromValue(code.Pos(), code.Val)
+}
+
+func toCodeBlock(v *Value) *ast.CodeBlock {
+	if v == nil {
+		return nil
+	}
+	return ast.NewCodeBlock(toPos(v.Pos), v.Val)
+}
+
+func fromCharClass(c *ast.CharClassMatcher) *CharClassMatcher {
+	return &CharClassMatcher{
+		Pos:      fromPos(c.Pos()),
+		Val:      c.Val,
+		Charsets: c.Charsets,
+	}
+}
+
+func toCharClass(m *CharClassMatcher) *ast.CharClassMatcher {
+	c := ast.NewCharClassMatcher(toPos(m.Pos), m.Val)
+	c.Charsets = m.Charsets
+	return c
+}
+
+func fromRule(r *ast.Rule) (*Rule, error) {
+	expr, err := fromExpr(r.Expr)
+	if err != nil {
+		return nil, err
+	}
+	m := &Rule{
+		Pos:        fromPos(r.Pos()),
+		Name:       fromIdentifier(r.Name),
+		Expr:       expr,
+		Longest:    r.Longest,
+		ResultType: r.ResultType,
+	}
+	if r.DisplayName != nil {
+		m.DisplayName = fromValue(r.DisplayName.Pos(), r.DisplayName.Val)
+	}
+	return m, nil
+}
+
+func fromExprs(exprs []ast.Expression) ([]*Expression, error) {
+	ms := make([]*Expression, 0, len(exprs))
+	for _, e := range exprs {
+		m, err := fromExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+func fromExpr(expr ast.Expression) (*Expression, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_Action{Action: &ActionExpr{
+			Pos:        fromPos(expr.Pos()),
+			Code:       fromCodeBlock(expr.Code),
+			Expr:       sub,
+			ReturnType: expr.ReturnType,
+		}}}, err
+	case *ast.AndCodeExpr:
+		return &Expression{Expr: &Expression_AndCode{AndCode: &AndCodeExpr{
+			Pos:  fromPos(expr.Pos()),
+			Code: fromCodeBlock(expr.Code),
+		}}}, nil
+	case *ast.AndExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_And{And: &AndExpr{
+			Pos:  fromPos(expr.Pos()),
+			Expr: sub,
+		}}}, err
+	case *ast.AnyMatcher:
+		return &Expression{Expr: &Expression_Any{Any: &AnyMatcher{
+			Pos: fromPos(expr.Pos()),
+			Val: expr.Val,
+		}}}, nil
+	case *ast.BOLMatcher:
+		return &Expression{Expr: &Expression_Bol{Bol: &BOLMatcher{
+			Pos: fromPos(expr.Pos()),
+			Val: expr.Val,
+		}}}, nil
+	case *ast.CharClassMatcher:
+		return &Expression{Expr: &Expression_CharClass{CharClass: fromCharClass(expr)}}, nil
+	case *ast.ChoiceExpr:
+		alts, err := fromExprs(expr.Alternatives)
+		return &Expression{Expr: &Expression_Choice{Choice: &ChoiceExpr{
+			Pos:          fromPos(expr.Pos()),
+			Alternatives: alts,
+			Longest:      expr.Longest,
+		}}}, err
+	case *ast.KeywordMatcher:
+		return &Expression{Expr: &Expression_Keyword{Keyword: &KeywordMatcher{
+			Pos:      fromPos(expr.Pos()),
+			Keywords: expr.Keywords,
+		}}}, nil
+	case *ast.LabeledExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_Labeled{Labeled: &LabeledExpr{
+			Pos:   fromPos(expr.Pos()),
+			Label: fromIdentifier(expr.Label),
+			Expr:  sub,
+		}}}, err
+	case *ast.LitMatcher:
+		return &Expression{Expr: &Expression_Lit{Lit: &LitMatcher{
+			Pos:        fromPos(expr.Pos()),
+			Val:        expr.Val,
+			IgnoreCase: expr.IgnoreCase,
+		}}}, nil
+	case *ast.LookbehindExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_Lookbehind{Lookbehind: &LookbehindExpr{
+			Pos:    fromPos(expr.Pos()),
+			Expr:   sub,
+			Negate: expr.Negate,
+		}}}, err
+	case *ast.NotCodeExpr:
+		return &Expression{Expr: &Expression_NotCode{NotCode: &NotCodeExpr{
+			Pos:  fromPos(expr.Pos()),
+			Code: fromCodeBlock(expr.Code),
+		}}}, nil
+	case *ast.NotExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_Not{Not: &NotExpr{
+			Pos:  fromPos(expr.Pos()),
+			Expr: sub,
+		}}}, err
+	case *ast.OneOrMoreExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_OneOrMore{OneOrMore: &OneOrMoreExpr{
+			Pos:  fromPos(expr.Pos()),
+			Expr: sub,
+		}}}, err
+	case *ast.RecoveryExpr:
+		sub, err := fromExpr(expr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		rec, err := fromExpr(expr.RecoverExpr)
+		m := &RecoveryExpr{
+			Pos:         fromPos(expr.Pos()),
+			Expr:        sub,
+			RecoverExpr: rec,
+		}
+		for _, l := range expr.Labels {
+			m.Labels = append(m.Labels, string(l))
+		}
+		return &Expression{Expr: &Expression_Recovery{Recovery: m}}, err
+	case *ast.RegexpMatcher:
+		return &Expression{Expr: &Expression_Regexp{Regexp: &RegexpMatcher{
+			Pos:  fromPos(expr.Pos()),
+			Expr: expr.Expr,
+		}}}, nil
+	case *ast.RuleRefExpr:
+		return &Expression{Expr: &Expression_RuleRef{RuleRef: &RuleRefExpr{
+			Pos:  fromPos(expr.Pos()),
+			Name: fromIdentifier(expr.Name),
+		}}}, nil
+	case *ast.SeqExpr:
+		exprs, err := fromExprs(expr.Exprs)
+		return &Expression{Expr: &Expression_Seq{Seq: &SeqExpr{
+			Pos:   fromPos(expr.Pos()),
+			Exprs: exprs,
+		}}}, err
+	case *ast.StateCodeExpr:
+		return &Expression{Expr: &Expression_StateCode{StateCode: &StateCodeExpr{
+			Pos:  fromPos(expr.Pos()),
+			Code: fromCodeBlock(expr.Code),
+		}}}, nil
+	case *ast.ThrowExpr:
+		return &Expression{Expr: &Expression_Throw{Throw: &ThrowExpr{
+			Pos:   fromPos(expr.Pos()),
+			Label: expr.Label,
+		}}}, nil
+	case *ast.ZeroOrMoreExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_ZeroOrMore{ZeroOrMore: &ZeroOrMoreExpr{
+			Pos:  fromPos(expr.Pos()),
+			Expr: sub,
+		}}}, err
+	case *ast.ZeroOrOneExpr:
+		sub, err := fromExpr(expr.Expr)
+		return &Expression{Expr: &Expression_ZeroOrOne{ZeroOrOne: &ZeroOrOneExpr{
+			Pos:  fromPos(expr.Pos()),
+			Expr: sub,
+		}}}, err
+	}
+	return nil, fmt.Errorf("%s: unsupported expression type %T", expr.Pos(), expr)
+}
+
+func toExprs(ms []*Expression) ([]ast.Expression, error) {
+	exprs := make([]ast.Expression, 0, len(ms))
+	for _, m := range ms {
+		e, err := toExpr(m)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
+
+func toExpr(m *Expression) (ast.Expression, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	var err error
+	switch m := m.Expr.(type) {
+	case *Expression_Action:
+		e := ast.NewActionExpr(toPos(m.Action.Pos))
+		e.Code = toCodeBlock(m.Action.Code)
+		e.ReturnType = m.Action.ReturnType
+		e.Expr, err = toExpr(m.Action.Expr)
+		return e, err
+	case *Expression_AndCode:
+		e := ast.NewAndCodeExpr(toPos(m.AndCode.Pos))
+		e.Code = toCodeBlock(m.AndCode.Code)
+		return e, nil
+	case *Expression_And:
+		e := ast.NewAndExpr(toPos(m.And.Pos))
+		e.Expr, err = toExpr(m.And.Expr)
+		return e, err
+	case *Expression_Any:
+		return ast.NewAnyMatcher(toPos(m.Any.Pos), m.Any.Val), nil
+	case *Expression_Bol:
+		return ast.NewBOLMatcher(toPos(m.Bol.Pos), m.Bol.Val), nil
+	case *Expression_CharClass:
+		return toCharClass(m.CharClass), nil
+	case *Expression_Choice:
+		e := ast.NewChoiceExpr(toPos(m.Choice.Pos))
+		e.Longest = m.Choice.Longest
+		e.Alternatives, err = toExprs(m.Choice.Alternatives)
+		return e, err
+	case *Expression_Keyword:
+		return ast.NewKeywordMatcher(toPos(m.Keyword.Pos), m.Keyword.Keywords), nil
+	case *Expression_Labeled:
+		e := ast.NewLabeledExpr(toPos(m.Labeled.Pos))
+		e.Label = toIdentifier(m.Labeled.Label)
+		e.Expr, err = toExpr(m.Labeled.Expr)
+		return e, err
+	case *Expression_Lit:
+		e := ast.NewLitMatcher(toPos(m.Lit.Pos), m.Lit.Val)
+		e.IgnoreCase = m.Lit.IgnoreCase
+		return e, nil
+	case *Expression_Lookbehind:
+		e := ast.NewLookbehindExpr(toPos(m.Lookbehind.Pos), m.Lookbehind.Negate)
+		e.Expr, err = toExpr(m.Lookbehind.Expr)
+		return e, err
+	case *Expression_NotCode:
+		e := ast.NewNotCodeExpr(toPos(m.NotCode.Pos))
+		e.Code = toCodeBlock(m.NotCode.Code)
+		return e, nil
+	case *Expression_Not:
+		e := ast.NewNotExpr(toPos(m.Not.Pos))
+		e.Expr, err = toExpr(m.Not.Expr)
+		return e, err
+	case *Expression_OneOrMore:
+		e := ast.NewOneOrMoreExpr(toPos(m.OneOrMore.Pos))
+		e.Expr, err = toExpr(m.OneOrMore.Expr)
+		return e, err
+	case *Expression_Recovery:
+		e := ast.NewRecoveryExpr(toPos(m.Recovery.Pos))
+		for _, l := range m.Recovery.Labels {
+			e.Labels = append(e.Labels, ast.FailureLabel(l))
+		}
+		if e.Expr, err = toExpr(m.Recovery.Expr); err != nil {
+			return nil, err
+		}
+		e.RecoverExpr, err = toExpr(m.Recovery.RecoverExpr)
+		return e, err
+	case *Expression_Regexp:
+		return ast.NewRegexpMatcher(toPos(m.Regexp.Pos), m.Regexp.Expr), nil
+	case *Expression_RuleRef:
+		e := ast.NewRuleRefExpr(toPos(m.RuleRef.Pos))
+		e.Name = toIdentifier(m.RuleRef.Name)
+		return e, nil
+	case *Expression_Seq:
+		e := ast.NewSeqExpr(toPos(m.Seq.Pos))
+		e.Exprs, err = toExprs(m.Seq.Exprs)
+		return e, err
+	case *Expression_StateCode:
+		e := ast.NewStateCodeExpr(toPos(m.StateCode.Pos))
+		e.Code = toCodeBlock(m.StateCode.Code)
+		return e, nil
+	case *Expression_Throw:
+		e := ast.NewThrowExpr(toPos(m.Throw.Pos))
+		e.Label = m.Throw.Label
+		return e, nil
+	case *Expression_ZeroOrMore:
+		e := ast.NewZeroOrMoreExpr(toPos(m.ZeroOrMore.Pos))
+		e.Expr, err = toExpr(m.ZeroOrMore.Expr)
+		return e, err
+	case *Expression_ZeroOrOne:
+		e := ast.NewZeroOrOneExpr(toPos(m.ZeroOrOne.Pos))
+		e.Expr, err = toExpr(m.ZeroOrOne.Expr)
+		return e, err
+	}
+	return nil, fmt.Errorf("invalid expression %v", m)
+}
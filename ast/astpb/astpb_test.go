@@ -0,0 +1,129 @@
+package astpb
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/bootstrap"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRoundTrip(t *testing.T) {
+	f, err := os.Open("../../grammar/bootstrap.peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := bootstrap.NewParser().Parse("bootstrap.peg", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bootstrap grammar has no failure labels, add them.
+	throw := ast.NewThrowExpr(ast.Pos{Line: 1000, Col: 1, Off: 10000})
+	throw.Label = "err"
+	rec := ast.NewRecoveryExpr(ast.Pos{Line: 1000, Col: 5, Off: 10004})
+	rec.Expr = throw
+	rec.RecoverExpr = ast.NewAnyMatcher(ast.Pos{Line: 1000, Col: 15, Off: 10014}, ".")
+	rec.Labels = []ast.FailureLabel{"err", "other"}
+	r := ast.NewRule(ast.Pos{Line: 1000}, ast.NewIdentifier(ast.Pos{Line: 1000}, "Recovery"))
+	r.Expr = rec
+	g.Rules = append(g.Rules, r)
+
+	// nor predicates and state code blocks.
+	and := ast.NewAndExpr(ast.Pos{Line: 1001, Col: 5, Off: 10020})
+	and.Expr = ast.NewLitMatcher(ast.Pos{Line: 1001, Col: 6, Off: 10021}, "a")
+	andCode := ast.NewAndCodeExpr(ast.Pos{Line: 1001, Col: 10, Off: 10025})
+	andCode.Code = ast.NewCodeBlock(ast.Pos{Line: 1001, Col: 11, Off: 10026}, "{ return true, nil }")
+	notCode := ast.NewNotCodeExpr(ast.Pos{Line: 1001, Col: 32, Off: 10047})
+	notCode.Code = ast.NewCodeBlock(ast.Pos{Line: 1001, Col: 33, Off: 10048}, "{ return false, nil }")
+	stateCode := ast.NewStateCodeExpr(ast.Pos{Line: 1001, Col: 55, Off: 10070})
+	stateCode.Code = ast.NewCodeBlock(ast.Pos{Line: 1001, Col: 56, Off: 10071}, "{ return nil }")
+	seq := ast.NewSeqExpr(ast.Pos{Line: 1001, Col: 5, Off: 10020})
+	seq.Exprs = []ast.Expression{and, andCode, notCode, stateCode}
+	r = ast.NewRule(ast.Pos{Line: 1001}, ast.NewIdentifier(ast.Pos{Line: 1001}, "Predicates"))
+	r.DisplayName = ast.NewStringLit(ast.Pos{Line: 1001, Col: 12}, "predicates")
+	r.Expr = seq
+	g.Rules = append(g.Rules, r)
+
+	// nor the expressions and annotations added after it.
+	bol := ast.NewBOLMatcher(ast.Pos{Line: 1002, Col: 5, Off: 10080}, "^")
+	re := ast.NewRegexpMatcher(ast.Pos{Line: 1002, Col: 7, Off: 10082}, "[0-9]+")
+	kw := ast.NewKeywordMatcher(ast.Pos{Line: 1002, Col: 22, Off: 10097}, []string{"if", "else"})
+	behind := ast.NewLookbehindExpr(ast.Pos{Line: 1002, Col: 40, Off: 10115}, true)
+	behind.Expr = ast.NewLitMatcher(ast.Pos{Line: 1002, Col: 42, Off: 10117}, "a")
+	class := ast.NewCharClassMatcher(ast.Pos{Line: 1002, Col: 46, Off: 10121}, "[<Digit>x]")
+	choice := ast.NewChoiceExpr(ast.Pos{Line: 1002, Col: 5, Off: 10080})
+	choice.Longest = true
+	choice.Alternatives = []ast.Expression{bol, re, kw, behind, class}
+	action := ast.NewActionExpr(ast.Pos{Line: 1002, Col: 5, Off: 10080})
+	action.Expr = choice
+	action.Code = ast.NewCodeBlock(ast.Pos{Line: 1002, Col: 60, Off: 10135}, "{ return \"\", nil }")
+	action.ReturnType = "string"
+	r = ast.NewRule(ast.Pos{Line: 1002}, ast.NewIdentifier(ast.Pos{Line: 1002}, "Extensions"))
+	r.Longest = true
+	r.ResultType = "string"
+	r.Expr = action
+	g.Rules = append(g.Rules, r)
+
+	cs := ast.NewCharset(ast.Pos{Line: 1003}, ast.NewIdentifier(ast.Pos{Line: 1003, Col: 10}, "Digit"))
+	cs.Class = ast.NewCharClassMatcher(ast.Pos{Line: 1003, Col: 18}, "[0-9]")
+	g.Charsets = append(g.Charsets, cs)
+	g.Package = ast.NewIdentifier(ast.Pos{Line: 1004, Col: 10}, "bootstrap")
+	g.GoImports = append(g.GoImports, ast.NewStringLit(ast.Pos{Line: 1005, Col: 12}, `"strings"`))
+
+	b, err := Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, got) {
+		t.Errorf("want round-tripped grammar to be equal")
+	}
+
+	// every expression type is covered
+	counts := ast.CountByType(g)
+	for _, typ := range []string{
+		"ActionExpr", "AndCodeExpr", "AndExpr", "AnyMatcher", "CharClassMatcher",
+		"ChoiceExpr", "LabeledExpr", "LitMatcher", "NotCodeExpr", "NotExpr",
+		"OneOrMoreExpr", "RecoveryExpr", "RuleRefExpr", "SeqExpr", "StateCodeExpr",
+		"ThrowExpr", "ZeroOrMoreExpr", "ZeroOrOneExpr", "BOLMatcher", "RegexpMatcher",
+		"KeywordMatcher", "LookbehindExpr",
+	} {
+		if counts[typ] == 0 {
+			t.Errorf("want at least one %s in the test grammar", typ)
+		}
+	}
+}
+
+// unknownExpr is an expression type that has no message in the schema.
+type unknownExpr struct{}
+
+func (unknownExpr) Pos() ast.Pos { return ast.Pos{Line: 1, Col: 2, Off: 3} }
+
+func TestMarshalUnknownExpr(t *testing.T) {
+	seq := ast.NewSeqExpr(ast.Pos{Line: 1, Col: 1})
+	seq.Exprs = []ast.Expression{ast.NewAnyMatcher(ast.Pos{Line: 1, Col: 1}, "."), unknownExpr{}}
+	r := ast.NewRule(ast.Pos{Line: 1}, ast.NewIdentifier(ast.Pos{Line: 1}, "A"))
+	r.Expr = seq
+	g := ast.NewGrammar(ast.Pos{})
+	g.Rules = append(g.Rules, r)
+
+	if _, err := Marshal(g); err == nil {
+		t.Errorf("want error for unknown expression type")
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	b, err := proto.Marshal(&Grammar{SchemaVersion: SchemaVersion + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(b); err == nil {
+		t.Errorf("want error for unsupported schema version")
+	}
+}
@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mna/pigeon/ast"
+)
+
+const benchmarkHelper = `
+// benchmarkRule parses the sample input of the rule, in the testdata
+// directory, with the rule as entrypoint. A pooled parser is used to
+// measure the steady-state cost of the parsing.
+func benchmarkRule(b *testing.B, rule string) {
+	input, err := ioutil.ReadFile(filepath.Join("testdata", rule+".txt"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var pool ParserPool
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pool.Parse(rule+".txt", input, Entrypoint(rule)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+`
+
+// BuildBenchmarks writes to w the source of a test file with a benchmark
+// for each rule of the grammar that has a sample input in the directory
+// dir, in a file named after the rule with a ".txt" extension. The
+// benchmark of a rule is named Benchmark<Rule> and parses the sample input
+// of the rule with the rule as entrypoint. The test file is in the package
+// of the parser generated from the grammar, as declared by its
+// initializer, and expects the sample inputs in its testdata directory,
+// so dir is typically the testdata directory of the test file.
+func BuildBenchmarks(w io.Writer, g *ast.Grammar, dir string) error {
+	pkg, err := packageName(g)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%spackage %s\n", codeGeneratedComment, pkg)
+	buf.WriteString(`
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+`)
+
+	names := make(map[string]bool)
+	for _, r := range g.Rules {
+		if _, err := os.Stat(filepath.Join(dir, r.Name.Val+".txt")); err != nil {
+			continue
+		}
+		nm := benchmarkName(r.Name.Val)
+		for i := 2; names[nm]; i++ {
+			nm = fmt.Sprintf("%s_%d", benchmarkName(r.Name.Val), i)
+		}
+		names[nm] = true
+		fmt.Fprintf(&buf, "\nfunc %s(b *testing.B) {\n\tbenchmarkRule(b, %q)\n}\n", nm, r.Name.Val)
+	}
+	buf.WriteString(benchmarkHelper)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// benchmarkName returns the name of the benchmark of the rule named rule,
+// with its first letter in upper case so that it is run by go test.
+func benchmarkName(rule string) string {
+	rn, sz := utf8.DecodeRuneInString(rule)
+	return "Benchmark" + string(unicode.ToUpper(rn)) + rule[sz:]
+}
+
+// packageName returns the name of the package declared by the initializer
+// of the grammar.
+func packageName(g *ast.Grammar) (string, error) {
+	if g.Init == nil {
+		return "", fmt.Errorf("builder: no initializer to declare the package")
+	}
+	src := strings.TrimSpace(g.Init.Val)
+	src = src[1 : len(src)-1]
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly)
+	if err != nil {
+		return "", fmt.Errorf("builder: %s: invalid package clause in initializer: %v", g.Init.Pos(), err)
+	}
+	return f.Name.Name, nil
+}
@@ -1,15 +1,23 @@
 // Package builder generates the parser code for a given grammar. It makes
-// no attempt to verify the correctness of the grammar.
+// no attempt to verify the correctness of the grammar, except for the
+// checks that would otherwise result in an invalid parser (e.g. duplicate
+// rules).
 package builder
 
 import (
 	"bytes"
 	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
+	"unicode/utf8"
 
 	"regexp"
 
@@ -58,7 +66,9 @@ type Option func(*builder) Option
 
 // ReceiverName returns an option that specifies the receiver name to
 // use for the current struct (which is the struct on which all code blocks
-// except the initializer are generated).
+// except the initializer are generated). It must be a valid Go identifier
+// that is not used as a label in the code blocks' expressions, otherwise
+// BuildParser returns an error.
 func ReceiverName(nm string) Option {
 	return func(b *builder) Option {
 		prev := b.recvName
@@ -101,11 +111,197 @@ func BasicLatinLookupTable(basicLatinLookupTable bool) Option {
 	}
 }
 
+// Visitor returns an option that specifies the visitor option
+// If visitor is true, a ResultNode interface and a WalkResult function
+// to traverse the values returned by the code blocks are generated.
+func Visitor(visitor bool) Option {
+	return func(b *builder) Option {
+		prev := b.visitor
+		b.visitor = visitor
+		return Visitor(prev)
+	}
+}
+
+// CST returns an option that specifies the cst option
+// If cst is true, the actions are not run, and the parser returns a
+// concrete syntax tree of *CSTNode instead, with a node for each match of
+// a rule, from which the input can be reconstructed byte for byte.
+func CST(cst bool) Option {
+	return func(b *builder) Option {
+		prev := b.cst
+		b.cst = cst
+		return CST(prev)
+	}
+}
+
+// Package returns an option that specifies the package option
+// If pkg is not empty, it is the name of the package of the generated
+// parser, and it overrides the @package directive of the grammar and the
+// package clause of the initializer.
+func Package(pkg string) Option {
+	return func(b *builder) Option {
+		prev := b.pkg
+		b.pkg = pkg
+		return Package(prev)
+	}
+}
+
+// Lib returns an option that specifies the lib option
+// If lib is true, the parser is generated to be embedded as a library:
+// the Debug option and the code that prints the debugging information
+// while parsing are removed from the resulting parser.
+func Lib(lib bool) Option {
+	return func(b *builder) Option {
+		prev := b.lib
+		b.lib = lib
+		return Lib(prev)
+	}
+}
+
+// Iface returns an option that specifies the iface option
+// If iface is true, a Parser interface that declares the Parse* functions
+// as methods, and a DefaultParser type that implements it by calling
+// them, are generated, so that the code using the parser may depend on
+// the interface and use a stub in its tests.
+func Iface(iface bool) Option {
+	return func(b *builder) Option {
+		prev := b.iface
+		b.iface = iface
+		return Iface(prev)
+	}
+}
+
+// MemoizeIf returns an option that specifies the rules whose results are
+// memoized when the generated parser is run with its MemoizeRules option.
+// The decision is made for each rule when the parser is generated and
+// written as a constant, so that it has no cost when parsing. If predicate
+// is nil, the rules referenced from at least two places in the grammar are
+// memoized.
+func MemoizeIf(predicate func(ruleName string) bool) Option {
+	return func(b *builder) Option {
+		prev := b.memoizeIf
+		b.memoizeIf = predicate
+		return MemoizeIf(prev)
+	}
+}
+
+// AllowDuplicateLabels returns an option that specifies the
+// allowDuplicateLabels option
+// If allowDuplicateLabels is false, BuildParser returns an error if a label
+// shadows a previous label in the scope of the same code block (see
+// ast.CheckDuplicateLabels). If it is true, only the last binding of such
+// labels is visible in the code block.
+func AllowDuplicateLabels(allowDuplicateLabels bool) Option {
+	return func(b *builder) Option {
+		prev := b.allowDuplicateLabels
+		b.allowDuplicateLabels = allowDuplicateLabels
+		return AllowDuplicateLabels(prev)
+	}
+}
+
+// Spacing returns an option that specifies the spacing rule
+// If spacing is not empty, the rule with that name is automatically
+// matched after each token of the grammar, except in the lexical rules
+// (see ast.InsertSpacing). The grammar is transformed in place.
+func Spacing(spacing string, lexical ...string) Option {
+	return func(b *builder) Option {
+		prev, prevLexical := b.spacing, b.lexicalRules
+		b.spacing, b.lexicalRules = spacing, lexical
+		return Spacing(prev, prevLexical...)
+	}
+}
+
+// OnCodeBlock returns an option that specifies a hook to rewrite the code
+// blocks of the actions, predicates and state changes. If fn is not nil,
+// it is called for each such code block with the name of the rule it
+// belongs to, before the code block is written to the generated parser,
+// and the code block is replaced by the returned text, which must include
+// the enclosing braces. The code block itself is not modified, so its
+// position is still the one in the grammar. It is not called for the
+// initializer.
+func OnCodeBlock(fn func(ruleName string, cb *ast.CodeBlock) string) Option {
+	return func(b *builder) Option {
+		prev := b.onCodeBlock
+		b.onCodeBlock = fn
+		return OnCodeBlock(prev)
+	}
+}
+
+// Tokens returns an option that specifies the name of a rule of the shape
+// Token*, optionally followed by the end of input, e.g. !. or a reference
+// to a rule that matches it. If rule is not empty, the generated parser
+// has a Tokens function that parses the input lazily, one match of the
+// repeated rule (Token) at a time, so that the consumers of the tokens
+// don't need to keep the whole result in memory:
+//
+//	func Tokens(filename string, b []byte, opts ...Option) (func() (interface{}, error), error)
+//
+// The returned function returns the value of the next match on each call,
+// and io.EOF at the end of the input.
+func Tokens(rule string) Option {
+	return func(b *builder) Option {
+		prev := b.tokens
+		b.tokens = rule
+		return Tokens(prev)
+	}
+}
+
 // BuildParser builds the PEG parser using the provider grammar. The code is
 // written to the specified w.
+//
+// The code only depends on the grammar and the options: the rules and the
+// functions of their code blocks are written in the order of the grammar,
+// and the helpers in a fixed order, so that building the same grammar
+// twice produces byte-identical code.
 func BuildParser(w io.Writer, g *ast.Grammar, opts ...Option) error {
 	b := &builder{w: w, recvName: "c"}
 	b.setOptions(opts)
+	if !token.IsIdentifier(b.recvName) {
+		return fmt.Errorf("builder: invalid receiver name %q", b.recvName)
+	}
+	if b.pkg != "" && !token.IsIdentifier(b.pkg) {
+		return fmt.Errorf("builder: invalid package name %q", b.pkg)
+	}
+	for _, imp := range g.GoImports {
+		if p, err := strconv.Unquote(imp.Val); err != nil || p == "" {
+			return fmt.Errorf("%s: builder: invalid import path %s", imp.Pos(), imp.Val)
+		}
+	}
+	if err := ast.ExpandCharsets(g); err != nil {
+		return err
+	}
+	if err := ast.CheckDuplicateRules(g); err != nil {
+		return err
+	}
+	if err := ast.CheckUndefinedRules(g); err != nil {
+		return err
+	}
+	if err := ast.CheckResultTypes(g); err != nil {
+		return err
+	}
+	if err := ast.CheckLookbehinds(g); err != nil {
+		return err
+	}
+	if err := checkRuleNames(g); err != nil {
+		return err
+	}
+	if !b.allowDuplicateLabels {
+		if err := ast.CheckDuplicateLabels(g); err != nil {
+			return err
+		}
+	}
+	if b.spacing != "" {
+		if err := ast.InsertSpacing(g, b.spacing, b.lexicalRules...); err != nil {
+			return fmt.Errorf("builder: %v", err)
+		}
+	}
+	if b.tokens != "" {
+		tok, err := repeatedRule(g, b.tokens)
+		if err != nil {
+			return fmt.Errorf("builder: %v", err)
+		}
+		b.tokenRule = tok
+	}
 	return b.buildParser(g)
 }
 
@@ -115,16 +311,48 @@ type builder struct {
 
 	// options
 	recvName              string
+	pkg                   string
 	optimize              bool
 	basicLatinLookupTable bool
 	globalState           bool
 	nolint                bool
+	visitor               bool
+	cst                   bool
+	lib                   bool
+	iface                 bool
+	memoizeIf             func(string) bool
+	allowDuplicateLabels  bool
+	spacing               string
+	lexicalRules          []string
+	onCodeBlock           func(string, *ast.CodeBlock) string
+	tokens                string
+
+	// name of the rule repeated by the tokens rule
+	tokenRule string
 
 	ruleName  string
 	exprIndex int
 	argsStack [][]string
 
+	// number of references to each rule, by rule name
+	ruleRefs map[string]int
+
 	rangeTable bool
+
+	// number of sequences that reuse a values buffer of the parser
+	seqValsCnt int
+
+	// regular expressions of the regexp matchers, in order of declaration
+	// of their package-level variables
+	regexps []string
+
+	// keywords of the keyword matchers that declare them, in order of
+	// declaration of their package-level variables
+	keywordSets [][]string
+	// true if the grammar has a keyword matcher
+	keyword bool
+	// true if the grammar has a lookbehind expression
+	lookbehind bool
 }
 
 func (b *builder) setOptions(opts []Option) {
@@ -134,25 +362,92 @@ func (b *builder) setOptions(opts []Option) {
 }
 
 func (b *builder) buildParser(g *ast.Grammar) error {
-	b.writeInit(g.Init)
+	b.ruleRefs = countRuleRefs(g)
+
+	b.writeInit(g)
 	b.writeGrammar(g)
+	b.writeRuleNames(g)
 
 	for _, rule := range g.Rules {
 		b.writeRuleCode(rule)
 	}
+	if b.tokenRule != "" {
+		b.writeTokens()
+	}
 	b.writeStaticCode()
 
 	return b.err
 }
 
-func (b *builder) writeInit(init *ast.CodeBlock) {
-	if init == nil {
+func (b *builder) writeInit(g *ast.Grammar) {
+	var code string
+	if g.Init != nil {
+		// remove opening and closing braces
+		code = g.Init.Val[1 : len(g.Init.Val)-1]
+	}
+
+	pkg := b.pkg
+	if pkg == "" && g.Package != nil {
+		pkg = g.Package.Val
+	}
+	if pkg != "" || len(g.GoImports) > 0 {
+		code = withPackage(code, pkg, g.GoImports)
+	} else if g.Init == nil {
 		return
 	}
+	b.writelnf("%s", codeGeneratedComment+code)
+}
+
+// withPackage returns the code of the initializer with its package clause
+// replaced by the package pkg, or prefixed by it if it has none, and with
+// the imports of paths after the package clause. If pkg is empty, the
+// package clause of the initializer is kept.
+func withPackage(code, pkg string, paths []*ast.StringLit) string {
+	start, end, name := packageClause(code)
+	if pkg == "" {
+		pkg = name
+	}
 
-	// remove opening and closing braces
-	val := codeGeneratedComment + init.Val[1:len(init.Val)-1]
-	b.writelnf("%s", val)
+	var buf bytes.Buffer
+	if start >= 0 {
+		buf.WriteString(code[:start])
+	}
+	if pkg != "" {
+		fmt.Fprintf(&buf, "package %s\n", pkg)
+	}
+	for _, p := range paths {
+		fmt.Fprintf(&buf, "import %s\n", p.Val)
+	}
+	if start >= 0 {
+		code = code[end:]
+	}
+	buf.WriteString(code)
+	return buf.String()
+}
+
+// packageClause returns the offsets of the start and the end of the
+// package clause of the Go source src, including its explicit semicolon if
+// any, and the package name, or -1, -1 and an empty name if src doesn't
+// start with a package clause.
+func packageClause(src string) (start, end int, name string) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	pos, tok, _ := s.Scan()
+	if tok != token.PACKAGE {
+		return -1, -1, ""
+	}
+	namePos, tok, lit := s.Scan()
+	if tok != token.IDENT {
+		return -1, -1, ""
+	}
+	start, end, name = file.Offset(pos), file.Offset(namePos)+len(lit), lit
+	if semiPos, tok, lit := s.Scan(); tok == token.SEMICOLON && lit == ";" {
+		end = file.Offset(semiPos) + 1
+	}
+	return start, end, name
 }
 
 func (b *builder) writeGrammar(g *ast.Grammar) {
@@ -165,6 +460,129 @@ func (b *builder) writeGrammar(g *ast.Grammar) {
 	}
 	b.writelnf("\t},")
 	b.writelnf("}")
+	b.writeRegexps()
+	b.writeKeywordSets()
+}
+
+// checkRuleNames returns an error if the exported constant of the name of
+// a rule or the ResultTypes map, written by writeRuleNames, is already
+// declared at the package level by the initializer, as the generated
+// parser would not compile. The map is not named after the rules, so that
+// it can't clash with the constant of a rule.
+func checkRuleNames(g *ast.Grammar) error {
+	decls := initDecls(g)
+	for _, r := range g.Rules {
+		if nm := "Rule" + r.Name.Val; decls[nm] {
+			return fmt.Errorf("%s: builder: the constant %s of the rule %s is already declared by the initializer", r.Pos(), nm, r.Name.Val)
+		}
+		if r.ResultType != "" && decls["ResultTypes"] {
+			return fmt.Errorf("%s: builder: the variable ResultTypes is already declared by the initializer", g.Init.Pos())
+		}
+	}
+	return nil
+}
+
+// initDecls returns the names declared at the package level by the
+// initializer of the grammar. It returns nil if the grammar has no
+// initializer or if it is not valid Go code, which is reported when the
+// generated parser is compiled.
+func initDecls(g *ast.Grammar) map[string]bool {
+	if g.Init == nil {
+		return nil
+	}
+	code := g.Init.Val[1 : len(g.Init.Val)-1]
+	if start, _, _ := packageClause(code); start < 0 {
+		code = "package p\n" + code
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		return nil
+	}
+
+	decls := make(map[string]bool)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *goast.FuncDecl:
+			if decl.Recv == nil {
+				decls[decl.Name.Name] = true
+			}
+		case *goast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *goast.TypeSpec:
+					decls[spec.Name.Name] = true
+				case *goast.ValueSpec:
+					for _, id := range spec.Names {
+						decls[id.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// writeRuleNames writes the exported constants of the names of the rules,
+// e.g. RuleExpr for the rule Expr, to use with the Entrypoint option, and
+// the ResultTypes map of the declared result types of the rules, if any.
+func (b *builder) writeRuleNames(g *ast.Grammar) {
+	if len(g.Rules) == 0 {
+		return
+	}
+	b.writelnf("\n// The names of the rules of the grammar, e.g. for the Entrypoint option.")
+	b.writelnf("const (")
+	for _, r := range g.Rules {
+		b.writelnf("\tRule%s = %q", r.Name.Val, r.Name.Val)
+	}
+	b.writelnf(")")
+
+	var typed []*ast.Rule
+	for _, r := range g.Rules {
+		if r.ResultType != "" {
+			typed = append(typed, r)
+		}
+	}
+	if len(typed) == 0 {
+		return
+	}
+	b.writelnf("\n// ResultTypes is the Go type of the value of each rule that declares it.")
+	b.writelnf("var ResultTypes = map[string]string{")
+	for _, r := range typed {
+		b.writelnf("\t%q: %q,", r.Name.Val, r.ResultType)
+	}
+	b.writelnf("}")
+}
+
+// writeRegexps writes the package-level variables of the regular
+// expressions of the regexp matchers, so that they are compiled once.
+// The regular expressions are anchored at the start of the input.
+func (b *builder) writeRegexps() {
+	if len(b.regexps) == 0 {
+		return
+	}
+	b.writelnf("\nvar (")
+	for i, expr := range b.regexps {
+		b.writelnf("\t%s = regexp.MustCompile(%q)", regexpName(i), `\A(?:`+expr+`)`)
+	}
+	b.writelnf(")")
+}
+
+// writeKeywordSets writes the package-level variables of the sets of
+// keywords of the keyword matchers, so that a keyword is looked up in a
+// map instead of being tried as a sequence of alternatives.
+func (b *builder) writeKeywordSets() {
+	if len(b.keywordSets) == 0 {
+		return
+	}
+	b.writelnf("\nvar (")
+	for i, set := range b.keywordSets {
+		b.writelnf("\t%s = map[string]bool{", keywordSetName(i))
+		for _, kw := range set {
+			b.writelnf("\t\t%q: true,", kw)
+		}
+		b.writelnf("\t}")
+	}
+	b.writelnf(")")
 }
 
 func (b *builder) writeRule(r *ast.Rule) {
@@ -184,9 +602,34 @@ func (b *builder) writeRule(r *ast.Rule) {
 	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
 	b.writef("\texpr: ")
 	b.writeExpr(r.Expr)
+	if !b.optimize && b.memoizeRule(r.Name.Val) {
+		b.writelnf("\tmemoize: true,")
+	}
 	b.writelnf("},")
 }
 
+// memoizeRule returns true if the results of the rule are memoized with
+// the MemoizeRules option of the generated parser.
+func (b *builder) memoizeRule(name string) bool {
+	if b.memoizeIf != nil {
+		return b.memoizeIf(name)
+	}
+	return b.ruleRefs[name] >= 2
+}
+
+// countRuleRefs returns the number of references to each rule of the
+// grammar, by rule name.
+func countRuleRefs(g *ast.Grammar) map[string]int {
+	refs := make(map[string]int)
+	ast.Inspect(g, func(expr ast.Expression) bool {
+		if ref, ok := expr.(*ast.RuleRefExpr); ok {
+			refs[ref.Name.Val]++
+		}
+		return true
+	})
+	return refs
+}
+
 func (b *builder) writeExpr(expr ast.Expression) {
 	b.exprIndex++
 	switch expr := expr.(type) {
@@ -198,6 +641,8 @@ func (b *builder) writeExpr(expr ast.Expression) {
 		b.writeAndExpr(expr)
 	case *ast.AnyMatcher:
 		b.writeAnyMatcher(expr)
+	case *ast.BOLMatcher:
+		b.writeBOLMatcher(expr)
 	case *ast.CharClassMatcher:
 		b.writeCharClassMatcher(expr)
 	case *ast.ChoiceExpr:
@@ -206,6 +651,8 @@ func (b *builder) writeExpr(expr ast.Expression) {
 		b.writeLabeledExpr(expr)
 	case *ast.LitMatcher:
 		b.writeLitMatcher(expr)
+	case *ast.LookbehindExpr:
+		b.writeLookbehindExpr(expr)
 	case *ast.NotCodeExpr:
 		b.writeNotCodeExpr(expr)
 	case *ast.NotExpr:
@@ -214,6 +661,10 @@ func (b *builder) writeExpr(expr ast.Expression) {
 		b.writeOneOrMoreExpr(expr)
 	case *ast.RecoveryExpr:
 		b.writeRecoveryExpr(expr)
+	case *ast.RegexpMatcher:
+		b.writeRegexpMatcher(expr)
+	case *ast.KeywordMatcher:
+		b.writeKeywordMatcher(expr)
 	case *ast.RuleRefExpr:
 		b.writeRuleRefExpr(expr)
 	case *ast.SeqExpr:
@@ -287,6 +738,17 @@ func (b *builder) writeAnyMatcher(any *ast.AnyMatcher) {
 	b.writelnf("},")
 }
 
+func (b *builder) writeBOLMatcher(bol *ast.BOLMatcher) {
+	if bol == nil {
+		b.writelnf("nil,")
+		return
+	}
+	b.writelnf("&bolMatcher{")
+	pos := bol.Pos()
+	b.writelnf("\tline: %d, col: %d, offset: %d,", pos.Line, pos.Col, pos.Off)
+	b.writelnf("},")
+}
+
 func (b *builder) writeCharClassMatcher(ch *ast.CharClassMatcher) {
 	if ch == nil {
 		b.writelnf("nil,")
@@ -300,9 +762,9 @@ func (b *builder) writeCharClassMatcher(ch *ast.CharClassMatcher) {
 		b.writef("\tchars: []rune{")
 		for _, rn := range ch.Chars {
 			if ch.IgnoreCase {
-				b.writef("%q,", unicode.ToLower(rn))
+				b.writef("%s,", runeLiteral(unicode.ToLower(rn)))
 			} else {
-				b.writef("%q,", rn)
+				b.writef("%s,", runeLiteral(rn))
 			}
 		}
 		b.writelnf("},")
@@ -311,9 +773,9 @@ func (b *builder) writeCharClassMatcher(ch *ast.CharClassMatcher) {
 		b.writef("\tranges: []rune{")
 		for _, rn := range ch.Ranges {
 			if ch.IgnoreCase {
-				b.writef("%q,", unicode.ToLower(rn))
+				b.writef("%s,", runeLiteral(unicode.ToLower(rn)))
 			} else {
-				b.writef("%q,", rn)
+				b.writef("%s,", runeLiteral(rn))
 			}
 		}
 		b.writelnf("},")
@@ -334,6 +796,18 @@ func (b *builder) writeCharClassMatcher(ch *ast.CharClassMatcher) {
 	b.writelnf("},")
 }
 
+// runeLiteral returns the Go literal of the rune rn. The full 21-bit code
+// points are supported, but the runes that are not valid code points (e.g.
+// the surrogate halves, which are part of the Cs category of the unicode
+// tables) are written as integers, as %q would replace them with
+// utf8.RuneError.
+func runeLiteral(rn rune) string {
+	if utf8.ValidRune(rn) {
+		return fmt.Sprintf("%q", rn)
+	}
+	return fmt.Sprintf("%#x", rn)
+}
+
 // BasicLatinLookup calculates the decision results for the first 256 characters of the UTF-8 character
 // set for a given set of chars, ranges and unicodeClasses to speedup the CharClassMatcher.
 func BasicLatinLookup(chars, ranges []rune, unicodeClasses []string, ignoreCase bool) (basicLatinChars [128]bool) {
@@ -392,6 +866,9 @@ func (b *builder) writeChoiceExpr(ch *ast.ChoiceExpr) {
 	if ch.Opt.SkipVals {
 		b.writelnf("\tskipVals: true,")
 	}
+	if ch.Longest {
+		b.writelnf("\tlongest: true,")
+	}
 	b.writelnf("},")
 }
 
@@ -419,11 +896,7 @@ func (b *builder) writeLitMatcher(lit *ast.LitMatcher) {
 	b.writelnf("&litMatcher{")
 	pos := lit.Pos()
 	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
-	if lit.IgnoreCase {
-		b.writelnf("\tval: %q,", strings.ToLower(lit.Val))
-	} else {
-		b.writelnf("\tval: %q,", lit.Val)
-	}
+	b.writelnf("\tval: %q,", lit.Val)
 	b.writelnf("\tignoreCase: %t,", lit.IgnoreCase)
 	ignoreCaseFlag := ""
 	if lit.IgnoreCase {
@@ -461,6 +934,26 @@ func (b *builder) writeNotExpr(not *ast.NotExpr) {
 	b.writelnf("},")
 }
 
+func (b *builder) writeLookbehindExpr(lb *ast.LookbehindExpr) {
+	if lb == nil {
+		b.writelnf("nil,")
+		return
+	}
+	// the length is checked by BuildParser
+	n, _ := ast.LookbehindLength(lb)
+	b.lookbehind = true
+	b.writelnf("&lookbehindExpr{")
+	pos := lb.Pos()
+	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+	b.writef("\texpr: ")
+	b.writeExpr(lb.Expr)
+	b.writelnf("\tn: %d,", n)
+	if lb.Negate {
+		b.writelnf("\tnegate: true,")
+	}
+	b.writelnf("},")
+}
+
 func (b *builder) writeOneOrMoreExpr(one *ast.OneOrMoreExpr) {
 	if one == nil {
 		b.writelnf("nil,")
@@ -495,6 +988,82 @@ func (b *builder) writeRecoveryExpr(recover *ast.RecoveryExpr) {
 	b.writelnf("},")
 }
 
+func (b *builder) writeRegexpMatcher(re *ast.RegexpMatcher) {
+	if re == nil {
+		b.writelnf("nil,")
+		return
+	}
+	ix := -1
+	for i, expr := range b.regexps {
+		if expr == re.Expr {
+			ix = i
+			break
+		}
+	}
+	if ix < 0 {
+		ix = len(b.regexps)
+		b.regexps = append(b.regexps, re.Expr)
+	}
+	b.writelnf("&regexpMatcher{")
+	pos := re.Pos()
+	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+	b.writelnf("\tre: %s,", regexpName(ix))
+	b.writelnf("\twant: %q,", "/"+re.Expr+"/")
+	b.writelnf("},")
+}
+
+// regexpName returns the name of the package-level variable of the
+// regular expression at index ix.
+func regexpName(ix int) string {
+	return "regexp" + strconv.Itoa(ix)
+}
+
+func (b *builder) writeKeywordMatcher(kw *ast.KeywordMatcher) {
+	if kw == nil {
+		b.writelnf("nil,")
+		return
+	}
+	b.keyword = true
+	b.writelnf("&keywordMatcher{")
+	pos := kw.Pos()
+	b.writelnf("\tpos: position{line: %d, col: %d, offset: %d},", pos.Line, pos.Col, pos.Off)
+	if len(kw.Keywords) > 0 {
+		b.writelnf("\tkeywords: %s,", keywordSetName(b.keywordSet(kw.Keywords)))
+	}
+	b.writelnf("\twant: %q,", "keyword")
+	b.writelnf("},")
+}
+
+// keywordSet returns the index of the package-level variable of the set
+// of keywords, adding it if it does not exist yet. The keywords are
+// sorted and deduplicated.
+func (b *builder) keywordSet(keywords []string) int {
+	set := append([]string(nil), keywords...)
+	sort.Strings(set)
+	n := 0
+	for i, kw := range set {
+		if i == 0 || kw != set[i-1] {
+			set[n] = kw
+			n++
+		}
+	}
+	set = set[:n]
+
+	for i, other := range b.keywordSets {
+		if strings.Join(other, "\x00") == strings.Join(set, "\x00") {
+			return i
+		}
+	}
+	b.keywordSets = append(b.keywordSets, set)
+	return len(b.keywordSets) - 1
+}
+
+// keywordSetName returns the name of the package-level variable of the
+// set of keywords at index ix.
+func keywordSetName(ix int) string {
+	return "keywords" + strconv.Itoa(ix)
+}
+
 func (b *builder) writeRuleRefExpr(ref *ast.RuleRefExpr) {
 	if ref == nil {
 		b.writelnf("nil,")
@@ -524,7 +1093,8 @@ func (b *builder) writeSeqExpr(seq *ast.SeqExpr) {
 		}
 		b.writelnf("\t},")
 		if seq.Opt.ReuseVals {
-			b.writelnf("vals: make([]interface{}, %d),", len(seq.Exprs))
+			b.seqValsCnt++
+			b.writelnf("\tvalsIx: %d,", b.seqValsCnt)
 		}
 	}
 	b.writelnf("},")
@@ -613,6 +1183,12 @@ func (b *builder) addArg(arg *ast.Identifier) {
 		return
 	}
 	ix := len(b.argsStack) - 1
+	for _, prev := range b.argsStack[ix] {
+		if prev == arg.Val {
+			// duplicate labels share the same value in the stack
+			return
+		}
+	}
 	b.argsStack[ix] = append(b.argsStack[ix], arg.Val)
 }
 
@@ -728,17 +1304,33 @@ func (b *builder) writeFunc(funcIx int, code *ast.CodeBlock, callTpl, funcTpl st
 	if code == nil {
 		return
 	}
-	val := strings.TrimSpace(code.Val)[1 : len(code.Val)-1]
+	src := code.Val
+	if b.onCodeBlock != nil {
+		src = strings.TrimSpace(b.onCodeBlock(b.ruleName, code))
+		if len(src) < 2 || src[0] != '{' || src[len(src)-1] != '}' {
+			if b.err == nil {
+				b.err = fmt.Errorf("builder: %s: the code block returned by OnCodeBlock must be enclosed in braces", code.Pos())
+			}
+			return
+		}
+	}
+	val := strings.TrimSpace(src)[1 : len(src)-1]
 	if len(val) > 0 && val[0] == '\n' {
 		val = val[1:]
 	}
 	if len(val) > 0 && val[len(val)-1] == '\n' {
 		val = val[:len(val)-1]
 	}
+	if funcTpl != onStateFuncTemplate {
+		val = returnBareExpr(val)
+	}
 	var args bytes.Buffer
 	ix := len(b.argsStack) - 1
 	if ix >= 0 {
 		for i, arg := range b.argsStack[ix] {
+			if arg == b.recvName && b.err == nil {
+				b.err = fmt.Errorf("builder: %s: label %s conflicts with the receiver name", code.Pos(), arg)
+			}
 			if i > 0 {
 				args.WriteString(", ")
 			}
@@ -764,6 +1356,103 @@ func (b *builder) writeFunc(funcIx int, code *ast.CodeBlock, callTpl, funcTpl st
 	b.writelnf(callTpl, fnNm, args.String())
 }
 
+// returnBareExpr returns the body of an action or predicate code block
+// with its last statement replaced by "return <expr>, nil" if it is a bare
+// expression, so that the code blocks that cannot fail don't have to
+// return a nil error explicitly, e.g. { string(c.text) }. As such a code
+// block doesn't compile otherwise, the explicit return statements are not
+// affected. A call to panic is left untouched, as it terminates the
+// function, and so is a body that is not valid Go code.
+func returnBareExpr(body string) string {
+	const prefix = "package p\nfunc _() {\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", prefix+body+"\n}", 0)
+	if err != nil {
+		return body
+	}
+	stmts := f.Decls[0].(*goast.FuncDecl).Body.List
+	if len(stmts) == 0 {
+		return body
+	}
+	es, ok := stmts[len(stmts)-1].(*goast.ExprStmt)
+	if !ok {
+		return body
+	}
+	if call, ok := es.X.(*goast.CallExpr); ok {
+		if id, ok := call.Fun.(*goast.Ident); ok && id.Name == "panic" {
+			return body
+		}
+	}
+	start := fset.Position(es.Pos()).Offset - len(prefix)
+	end := fset.Position(es.End()).Offset - len(prefix)
+	return body[:start] + "return " + body[start:end] + ", nil" + body[end:]
+}
+
+// repeatedRule returns the name of the rule repeated by the rule named
+// name, which must be of the shape Token*, optionally followed by the end
+// of input.
+func repeatedRule(g *ast.Grammar, name string) (string, error) {
+	rules := make(map[string]*ast.Rule, len(g.Rules))
+	for _, r := range g.Rules {
+		rules[r.Name.Val] = r
+	}
+	r := rules[name]
+	if r == nil {
+		return "", fmt.Errorf("tokens rule %q is undefined", name)
+	}
+
+	expr := r.Expr
+	for {
+		switch e := expr.(type) {
+		case *ast.ActionExpr:
+			expr = e.Expr
+			continue
+		case *ast.LabeledExpr:
+			expr = e.Expr
+			continue
+		case *ast.SeqExpr:
+			if len(e.Exprs) == 2 && isEOF(e.Exprs[1], rules) {
+				expr = e.Exprs[0]
+				continue
+			}
+		}
+		break
+	}
+	if rep, ok := expr.(*ast.ZeroOrMoreExpr); ok {
+		if ref, ok := rep.Expr.(*ast.RuleRefExpr); ok {
+			return ref.Name.Val, nil
+		}
+	}
+	return "", fmt.Errorf("%s: tokens rule %q must be of the shape Token*", r.Pos(), name)
+}
+
+// isEOF returns true if expr matches only the end of input, i.e. it is !.
+// or a reference to a rule that is !.
+func isEOF(expr ast.Expression, rules map[string]*ast.Rule) bool {
+	if ref, ok := expr.(*ast.RuleRefExpr); ok {
+		if r := rules[ref.Name.Val]; r != nil {
+			expr = r.Expr
+		}
+	}
+	not, ok := expr.(*ast.NotExpr)
+	if !ok {
+		return false
+	}
+	_, ok = not.Expr.(*ast.AnyMatcher)
+	return ok
+}
+
+func (b *builder) writeTokens() {
+	b.writelnf("// Tokens parses the data from b using filename as information in the")
+	b.writelnf("// error messages, one match of the %s rule at a time. It returns a", b.tokenRule)
+	b.writelnf("// function that parses and returns the value of the next match on each")
+	b.writelnf("// call, and io.EOF at the end of the data. The parser, including its")
+	b.writelnf("// memoized results, is kept between the calls.")
+	b.writelnf("func Tokens(filename string, b []byte, opts ...Option) (func() (interface{}, error), error) {")
+	b.writelnf("\treturn newParser(filename, b, opts...).tokens(g, %q)", b.tokenRule)
+	b.writelnf("}\n")
+}
+
 func (b *builder) writeStaticCode() {
 	buffer := bytes.NewBufferString("")
 	params := struct {
@@ -771,11 +1460,27 @@ func (b *builder) writeStaticCode() {
 		BasicLatinLookupTable bool
 		GlobalState           bool
 		Nolint                bool
+		Visitor               bool
+		CST                   bool
+		Lib                   bool
+		Tokens                bool
+		Regexp                bool
+		Keyword               bool
+		Lookbehind            bool
+		Iface                 bool
 	}{
 		Optimize:              b.optimize,
 		BasicLatinLookupTable: b.basicLatinLookupTable,
 		GlobalState:           b.globalState,
 		Nolint:                b.nolint,
+		Visitor:               b.visitor,
+		CST:                   b.cst,
+		Lib:                   b.lib,
+		Tokens:                b.tokenRule != "",
+		Regexp:                len(b.regexps) > 0,
+		Keyword:               b.keyword,
+		Lookbehind:            b.lookbehind,
+		Iface:                 b.iface,
 	}
 	t := template.Must(template.New("static_code").Parse(staticCode))
 
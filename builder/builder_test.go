@@ -1,11 +1,22 @@
 package builder
 
 import (
+	"bytes"
+	goast "go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/mna/pigeon/ast"
 	"github.com/mna/pigeon/bootstrap"
+	"golang.org/x/tools/imports"
 )
 
 var grammar = `
@@ -38,3 +49,569 @@ func TestBuildParser(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestBuildParserReceiverName(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := BuildParser(ioutil.Discard, g, ReceiverName("ctx")); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	for _, nm := range []string{"", "1c", "c-c", "func"} {
+		if err := BuildParser(ioutil.Discard, g, ReceiverName(nm)); err == nil {
+			t.Errorf("%q: want error, got nil", nm)
+		}
+	}
+
+	g, err = p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = BuildParser(ioutil.Discard, g, ReceiverName("left"))
+	if err == nil || !strings.Contains(err.Error(), "label left conflicts with the receiver name") {
+		t.Errorf("want label conflict error, got %v", err)
+	}
+}
+
+func TestBuildParserDuplicateRules(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar+"\nFoo = 'a'\nFoo = 'b'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = BuildParser(ioutil.Discard, g)
+	if err == nil || !strings.Contains(err.Error(), "duplicate rule: Foo") {
+		t.Errorf("want duplicate rule error, got %v", err)
+	}
+}
+
+func TestBuildParserUndefinedRules(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar+"\nFoo = Bar\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = BuildParser(ioutil.Discard, g)
+	if err == nil || !strings.Contains(err.Error(), "undefined rule: Bar") {
+		t.Errorf("want undefined rule error, got %v", err)
+	}
+}
+
+func TestBuildParserDuplicateLabels(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar+"\nFoo = a:'a' a:'b' { return a, nil }\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = BuildParser(ioutil.Discard, g)
+	if err == nil || !strings.Contains(err.Error(), "duplicate label: a") {
+		t.Errorf("want duplicate label error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, AllowDuplicateLabels(true)); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "onFoo1(a interface{})") {
+		t.Errorf("want a single argument for the duplicate label")
+	}
+}
+
+func TestBuildParserLookbehind(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar+"\nFoo = 'a'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo := g.Rules[len(g.Rules)-1]
+	lb := ast.NewLookbehindExpr(foo.Pos(), true)
+	lb.Expr = ast.ZeroOrMore(ast.Lit("b"))
+	foo.Expr = ast.Seq(lb, foo.Expr)
+
+	err = BuildParser(ioutil.Discard, g)
+	if err == nil || !strings.Contains(err.Error(), "lookbehind must match a fixed number of characters") {
+		t.Errorf("want lookbehind length error, got %v", err)
+	}
+
+	lb.Expr = ast.Choice(ast.Lit("bc"), ast.Seq(ast.Any(), ast.Lit("é")))
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "n: 2,") {
+		t.Errorf("want a lookbehind of 2 characters")
+	}
+}
+
+func TestBuildParserIface(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "type Parser interface") {
+		t.Errorf("want no Parser interface without the iface option")
+	}
+
+	buf.Reset()
+	if err := BuildParser(&buf, g, Iface(true)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"type Parser interface", "type DefaultParser struct{}", "func (DefaultParser) ParseRuneReader("} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in the generated parser", want)
+		}
+	}
+}
+
+func TestBuildParserMemoizeIf(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		// additive, multiplicative, primary and space are referenced at least twice
+		{name: "default", want: 4},
+		{name: "predicate", opts: []Option{MemoizeIf(func(ruleName string) bool { return ruleName == "integer" })}, want: 1},
+		{name: "optimize", opts: []Option{Optimize(true)}, want: 0},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		if err := BuildParser(&buf, g, tc.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Count(buf.String(), "\tmemoize: true,"); got != tc.want {
+			t.Errorf("%s: want %d memoized rules, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBuildParserSpacing(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = BuildParser(ioutil.Discard, g, Spacing("_"))
+	if err == nil || !strings.Contains(err.Error(), "undefined rule: _") {
+		t.Errorf("want undefined rule error, got %v", err)
+	}
+	if err := BuildParser(ioutil.Discard, g, Spacing("space", "integer", "eof")); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+func TestBuildParserLib(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, Lib(true)); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"func Debug(", "p.debug", "func (p *parser) print("} {
+		if strings.Contains(buf.String(), s) {
+			t.Errorf("want no %q in lib mode", s)
+		}
+	}
+	if !strings.Contains(buf.String(), "func Memoize(") {
+		t.Error("want Memoize option in lib mode")
+	}
+}
+
+func TestBuildParserAstralRanges(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(`A = [\U0001F600-\U0001F64F一-\U0002A6DF]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the surrogate halves of the Cs category are not valid runes
+	cs, err := ast.UnicodeCategory("Cs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Rules = append(g.Rules, ast.NewRule(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "B")))
+	g.Rules[1].Expr = cs
+
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`ranges: []rune{'😀','🙏','一','𪛟',}`,
+		`ranges: []rune{0xd800,0xdfff,}`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("want %s in generated parser", s)
+		}
+	}
+}
+
+func TestReturnBareExpr(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"string(c.text)", "return string(c.text), nil"},
+		{"\tx := 1\n\tx + 1 // sum", "\tx := 1\n\treturn x + 1, nil // sum"},
+		{"if x {\n\treturn 1, nil\n}\n2", "if x {\n\treturn 1, nil\n}\nreturn 2, nil"},
+		{"return string(c.text), nil", "return string(c.text), nil"},
+		{"return x, errors.New(\"x\")", "return x, errors.New(\"x\")"},
+		{"panic(\"x\")", "panic(\"x\")"},
+		{"x := 1", "x := 1"},
+		{"", ""},
+		{"x +", "x +"},
+	}
+	for _, c := range cases {
+		if got := returnBareExpr(c.in); got != c.out {
+			t.Errorf("%q: want %q, got %q", c.in, c.out, got)
+		}
+	}
+}
+
+func TestBuildParserOnCodeBlock(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(`A = 'a' { return "a", nil } / B
+B = 'b' { return "b", nil }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rules []string
+	trace := OnCodeBlock(func(ruleName string, cb *ast.CodeBlock) string {
+		rules = append(rules, ruleName)
+		return "{\n\ttrace(" + strconv.Quote(ruleName) + ")\n" + cb.Val[1:]
+	})
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g, trace); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"A", "B"}; strings.Join(rules, ",") != strings.Join(want, ",") {
+		t.Errorf("want hook called for rules %v, got %v", want, rules)
+	}
+	for _, s := range []string{
+		"trace(\"A\")\n return \"a\", nil",
+		"trace(\"B\")\n return \"b\", nil",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("want %q in generated parser", s)
+		}
+	}
+	if g.Rules[1].Expr.(*ast.ActionExpr).Code.Val != `{ return "b", nil }` {
+		t.Errorf("want the code block unchanged")
+	}
+
+	invalid := OnCodeBlock(func(string, *ast.CodeBlock) string { return "return nil, nil" })
+	if err := BuildParser(&bytes.Buffer{}, g, invalid); err == nil {
+		t.Error("want error for code block without braces")
+	}
+}
+
+func TestBuildBenchmarks(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader("{\npackage calc\n}\nA = 'a' B\nB = 'b'\nb = 'c'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "pigeon-bench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, nm := range []string{"A.txt", "B.txt", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, nm), []byte("ab"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "A.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := BuildBenchmarks(&buf, g, dir); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, s := range []string{
+		"package calc\n",
+		"func BenchmarkB(b *testing.B) {\n\tbenchmarkRule(b, \"B\")\n}",
+		"func BenchmarkB_2(b *testing.B) {\n\tbenchmarkRule(b, \"b\")\n}",
+	} {
+		if !strings.Contains(src, s) {
+			t.Errorf("want %q in generated benchmarks", s)
+		}
+	}
+	if strings.Contains(src, "BenchmarkA") {
+		t.Errorf("want no benchmark for rule without sample input")
+	}
+
+	g.Init = nil
+	if err := BuildBenchmarks(&bytes.Buffer{}, g, dir); err == nil {
+		t.Error("want error for grammar without initializer")
+	}
+}
+
+func TestBuildParserTokens(t *testing.T) {
+	cases := map[string]string{
+		"A = B*\nB = 'b'":                        "",
+		"A = l:B* !. { return l, nil }\nB = 'b'": "",
+		"A = B* EOF\nB = 'b'\nEOF = !.":          "",
+		"A = B+\nB = 'b'":                        "must be of the shape Token*",
+		"A = B* 'c'\nB = 'b'":                    "must be of the shape Token*",
+		"A = 'b'*":                               "must be of the shape Token*",
+		"B = 'b'":                                "tokens rule \"A\" is undefined",
+	}
+	for src, want := range cases {
+		p := bootstrap.NewParser()
+		g, err := p.Parse("", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		err = BuildParser(&buf, g, Tokens("A"))
+		if want == "" {
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", src, err)
+			} else if !strings.Contains(buf.String(), "tokens(g, \"B\")") {
+				t.Errorf("%q: want Tokens function over rule B", src)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: want error containing %q, got %v", src, want, err)
+		}
+	}
+}
+
+func TestWithPackage(t *testing.T) {
+	imports := []*ast.StringLit{
+		ast.NewStringLit(ast.Pos{}, `"a/b"`),
+		ast.NewStringLit(ast.Pos{}, "`c`"),
+	}
+	cases := []struct {
+		code    string
+		pkg     string
+		imports []*ast.StringLit
+		want    string
+	}{
+		{"", "p", nil, "package p\n"},
+		{"\npackage q\n\nvar x int\n", "", imports, "\npackage q\nimport \"a/b\"\nimport `c`\n\n\nvar x int\n"},
+		{"\npackage q\n\nvar x int\n", "p", nil, "\npackage p\n\n\nvar x int\n"},
+		{"\n// Package q doc.\npackage q;var x int\n", "p", imports, "\n// Package q doc.\npackage p\nimport \"a/b\"\nimport `c`\nvar x int\n"},
+		{"\nvar x int\n", "p", imports, "package p\nimport \"a/b\"\nimport `c`\n\nvar x int\n"},
+	}
+	for _, c := range cases {
+		if got := withPackage(c.code, c.pkg, c.imports); got != c.want {
+			t.Errorf("%q: want\n%q\ngot\n%q", c.code, c.want, got)
+		}
+	}
+}
+
+func TestBuildParserPackage(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader("{\npackage q\n}\nA = 'a'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Package = ast.NewIdentifier(ast.Pos{}, "dir")
+	g.GoImports = []*ast.StringLit{ast.NewStringLit(ast.Pos{}, `"unicode/utf16"`)}
+
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	want := codeGeneratedComment + "\npackage dir\nimport \"unicode/utf16\"\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("want prefix %q, got %q", want, buf.String()[:len(want)])
+	}
+
+	// the option overrides the directive
+	buf.Reset()
+	if err := BuildParser(&buf, g, Package("opt")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\npackage opt\n") || strings.Contains(buf.String(), "package dir") {
+		t.Errorf("want package opt, got %q", buf.String()[:100])
+	}
+
+	if err := BuildParser(ioutil.Discard, g, Package("a-b")); err == nil {
+		t.Error("want error for invalid package name")
+	}
+	g.GoImports = []*ast.StringLit{ast.NewStringLit(ast.Pos{}, `"a`)}
+	if err := BuildParser(ioutil.Discard, g); err == nil {
+		t.Error("want error for invalid import path")
+	}
+}
+
+func TestBuildParserRuleNames(t *testing.T) {
+	cases := []struct {
+		init string
+		err  string
+	}{
+		{init: "package p; var RuleB = 1"},
+		{init: "package p; const RuleA = \"x\"", err: "2:1 (31): builder: the constant RuleA of the rule A is already declared by the initializer"},
+		{init: "var x, RuleA int", err: "constant RuleA of the rule A"},
+		{init: "func RuleA() {}", err: "constant RuleA of the rule A"},
+		{init: "type RuleA struct{}", err: "constant RuleA of the rule A"},
+		// methods are not declared at the package level
+		{init: "type T int; func (T) RuleA() {}"},
+	}
+	for _, tc := range cases {
+		p := bootstrap.NewParser()
+		g, err := p.Parse("", strings.NewReader("{"+tc.init+"}\nA = 'a'"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = BuildParser(ioutil.Discard, g)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", tc.init, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: want error containing %q, got %v", tc.init, tc.err, err)
+		}
+	}
+}
+
+func TestBuildParserResultTypes(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader("A = B / C\nB = 'b'\nC = 'c'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Rules[1].ResultType = "[]byte"
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	want := "var ResultTypes = map[string]string{\n\t\"B\": \"[]byte\",\n}"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("want generated code to contain %q", want)
+	}
+
+	g.Rules[2].ResultType = "string"
+	err = BuildParser(&buf, g)
+	if want := "rule A: result of type string incompatible"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("want error containing %q, got %v", want, err)
+	}
+}
+
+func TestBuildParserResultTypesRule(t *testing.T) {
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader("{\npackage p\n}\nStart = Types !.\nTypes = 'a'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Rules[1].ResultType = "[]byte"
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	// the constant of the rule Types doesn't clash with the map
+	f, err := parser.ParseFile(token.NewFileSet(), "", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*goast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*goast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, id := range vs.Names {
+				if id.Name != "_" && seen[id.Name] {
+					t.Errorf("%s redeclared", id.Name)
+				}
+				seen[id.Name] = true
+			}
+		}
+	}
+	for _, nm := range []string{"RuleTypes", "ResultTypes"} {
+		if !seen[nm] {
+			t.Errorf("want %s to be declared", nm)
+		}
+	}
+}
+
+func TestBuildParserTypeCheck(t *testing.T) {
+	// the constants of the rules don't clash with the exported identifiers
+	// of the static code, e.g. RuleStat with a ChoiceStat type.
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader("{\npackage p\n}\nStart = Stat Types !.\nStat = 'a'\nTypes = 'b'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Rules[2].ResultType = "[]byte"
+	var buf bytes.Buffer
+	if err := BuildParser(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	// the imports are added by goimports, as done by the pigeon command
+	src, err := imports.Process("", buf.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("p", fset, []*goast.File{f}, nil); err != nil {
+		t.Errorf("want the generated parser to type-check, got %v", err)
+	}
+}
+
+func TestBuildParserDeterministic(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "examples", "json", "json.peg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(optimize bool) []byte {
+		g, err := bootstrap.NewParser().Parse("", bytes.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if optimize {
+			ast.Optimize(g)
+		}
+		var buf bytes.Buffer
+		if err := BuildParser(&buf, g, Optimize(optimize), BasicLatinLookupTable(optimize)); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	for _, optimize := range []bool{false, true} {
+		want := build(optimize)
+		for i := 0; i < 5; i++ {
+			if got := build(optimize); !bytes.Equal(got, want) {
+				t.Fatalf("optimize %t: want identical output on build %d", optimize, i+1)
+			}
+		}
+	}
+}
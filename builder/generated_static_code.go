@@ -17,9 +17,56 @@ var (
 
 	// errMaxExprCnt is used to signal that the maximum number of
 	// expressions have been parsed.
-	errMaxExprCnt = errors.New("max number of expresssions parsed")
+	errMaxExprCnt = &LimitError{Kind: LimitExpressions, msg: "max number of expresssions parsed"}
+
+	// errMaxSteps is used to signal that the maximum number of attempts
+	// to match an input with a matcher has been exceeded.
+	errMaxSteps = &LimitError{Kind: LimitSteps, msg: "max number of matcher steps exceeded"}
+
+	// errMaxDepth is used to signal that the maximum depth of nested
+	// rules has been exceeded.
+	errMaxDepth = &LimitError{Kind: LimitDepth, msg: "max depth of nested rules exceeded"}
+)
+
+// LimitKind is the kind of limit that stopped the parsing, see LimitError.
+type LimitKind int
+
+// List of the kinds of limits.
+const (
+	// LimitExpressions is the limit set by the MaxExpressions option.
+	LimitExpressions LimitKind = iota + 1
+	// LimitDepth is the limit set by the MaxDepth option.
+	LimitDepth
+	// LimitSteps is the limit set by the MaxSteps option.
+	LimitSteps
 )
 
+func (k LimitKind) String() string {
+	switch k {
+	case LimitExpressions:
+		return "expressions"
+	case LimitDepth:
+		return "depth"
+	case LimitSteps:
+		return "steps"
+	}
+	return "LimitKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// LimitError is the error that stops the parsing when one of the limits
+// set by the MaxExpressions, MaxDepth and MaxSteps options is exceeded, so
+// that this resource-limit abort can be told apart from a syntax error,
+// e.g. with errors.As on the error returned by the Parse* functions.
+type LimitError struct {
+	Kind LimitKind
+	msg  string
+}
+
+// Error returns the error message.
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
 // Option is a function that can set an option on the parser. It returns
 // the previous setting as an Option.
 type Option func(*parser) Option
@@ -37,6 +84,86 @@ func MaxExpressions(maxExprCnt uint64) Option {
 	}
 }
 
+// MaxSteps creates an Option to stop parsing with an error once the
+// matchers (literal, character class, any and beginning of line matchers)
+// have been tried more than n times in total. Unlike MaxExpressions, which
+// limits the number of expressions parsed, it accounts for every attempt
+// to match the input, including the ones that fail and the ones repeated
+// after a backtrack, so it bounds the work done on adversarial inputs
+// that force exponential backtracking. If n <= 0, the number of steps is
+// not limited.
+//
+// The default for n is 0.
+func MaxSteps(n int) Option {
+	return func(p *parser) Option {
+		oldMaxSteps := p.maxSteps
+		p.maxSteps = n
+		return MaxSteps(oldMaxSteps)
+	}
+}
+
+// MaxDepth creates an Option to stop parsing with an error once more than
+// n rules are nested, e.g. to bound the recursion on deeply nested inputs.
+// If n <= 0, the depth is not limited.
+//
+// The default for n is 0.
+func MaxDepth(n int) Option {
+	return func(p *parser) Option {
+		oldMaxDepth := p.maxDepth
+		p.maxDepth = n
+		return MaxDepth(oldMaxDepth)
+	}
+}
+
+// OnProgress creates an Option to set a function that is called as the
+// parser advances through the input, e.g. to render a progress bar. It
+// is called with the furthest offset reached in the input, in bytes,
+// since the parser may backtrack, and with the size of the input, or -1
+// while it is unknown when parsing from an io.RuneReader. It is called
+// each time the furthest offset advances by at least the interval set by
+// the ProgressInterval option, and once more when the parsing completes.
+//
+// The default is to not report the progress.
+func OnProgress(f func(offset, total int)) Option {
+	return func(p *parser) Option {
+		oldOnProgress := p.onProgress
+		p.onProgress = f
+		return OnProgress(oldOnProgress)
+	}
+}
+
+// ProgressInterval creates an Option to set the number of bytes by which
+// the furthest offset reached in the input must advance between two calls
+// of the function set by the OnProgress option, so that its overhead
+// stays negligible. If n <= 0, it is called each time the furthest
+// offset advances.
+//
+// The default for n is 4096.
+func ProgressInterval(n int) Option {
+	return func(p *parser) Option {
+		oldInterval := p.progressInterval
+		p.progressInterval = n
+		return ProgressInterval(oldInterval)
+	}
+}
+
+// ==template== {{ if .Keyword }}
+// Keywords creates an Option to set the keywords matched by the keyword
+// matchers of the grammar that do not declare their own keywords, i.e.
+// the ones written as @keyword alone. A keyword matcher matches the
+// identifier at the current position only if it is a key of keywords
+// with a true value.
+//
+// The default is to match no keyword.
+func Keywords(keywords map[string]bool) Option {
+	return func(p *parser) Option {
+		oldKeywords := p.keywords
+		p.keywords = keywords
+		return Keywords(oldKeywords)
+	}
+}
+
+// {{ end }} ==template==
 // Entrypoint creates an Option to set the rule name to use as entrypoint.
 // The rule name must have been specified in the -alternate-entrypoints
 // if generating the parser with the -optimize-grammar flag, otherwise
@@ -55,6 +182,16 @@ func Entrypoint(ruleName string) Option {
 	}
 }
 
+// ValidEntrypoints returns the names of the rules that may be used as
+// entrypoint with the Entrypoint option, in the order of the grammar.
+func ValidEntrypoints() []string {
+	names := make([]string, 0, len(g.rules))
+	for _, r := range g.rules {
+		names = append(names, r.name)
+	}
+	return names
+}
+
 // ==template== {{ if not .Optimize }}
 // Statistics adds a user provided Stats struct to the parser to allow
 // the user to process the results after the parsing has finished.
@@ -87,6 +224,7 @@ func Statistics(stats *Stats, choiceNoMatch string) Option {
 	}
 }
 
+// ==template== {{ if not .Lib }}
 // Debug creates an Option to set the debug flag to b. When set to true,
 // debugging information is printed to stdout while parsing.
 //
@@ -99,17 +237,41 @@ func Debug(b bool) Option {
 	}
 }
 
+// {{ end }} ==template==
+
 // Memoize creates an Option to set the memoize flag to b. When set to true,
 // the parser will cache all results so each expression is evaluated only
 // once. This guarantees linear parsing time even for pathological cases,
 // at the expense of more memory and slower times for typical cases.
 //
+// The memoized results are never shared across calls to the Parse*
+// functions: the memoization table is empty when the parsing starts, so
+// the results obtained with an entrypoint (see Entrypoint) can't be used
+// when parsing with another one, even if the same input is parsed.
+//
 // The default is false.
 func Memoize(b bool) Option {
+	return memoize(b, false)
+}
+
+// MemoizeRules creates an Option to memoize only the results of the rules
+// selected when the parser was generated. As opposed to Memoize, the
+// results of the other rules and of the expressions are not cached, which
+// trades the linear parsing time guarantee for less memory.
+//
+// By default, the rules that are referenced from at least two places in
+// the grammar are selected, as the rules referenced once never benefit
+// from memoization.
+func MemoizeRules(b bool) Option {
+	return memoize(b, b)
+}
+
+func memoize(b, rules bool) Option {
 	return func(p *parser) Option {
-		old := p.memoize
+		old, oldRules := p.memoize, p.memoizeRules
 		p.memoize = b
-		return Memoize(old)
+		p.memoizeRules = rules
+		return memoize(old, oldRules)
 	}
 }
 
@@ -129,6 +291,22 @@ func AllowInvalidUTF8(b bool) Option {
 	}
 }
 
+// StripBOM creates an Option to skip the UTF-8 byte order mark (U+FEFF)
+// at the start of the input, if any, e.g. for the files saved by some
+// Windows editors. The reported positions are then relative to the content
+// that follows the byte order mark, and it is not part of the matched
+// text. If it is not set, the byte order mark is parsed like any other
+// rune, so that the offsets match the raw bytes of the input.
+//
+// The default is false.
+func StripBOM(b bool) Option {
+	return func(p *parser) Option {
+		old := p.stripBOM
+		p.stripBOM = b
+		return StripBOM(old)
+	}
+}
+
 // Recover creates an Option to set the recover flag to b. When set to
 // true, this causes the parser to recover from panics and convert it
 // to an error. Setting it to false can be useful while debugging to
@@ -143,6 +321,40 @@ func Recover(b bool) Option {
 	}
 }
 
+// PartialResult creates an Option to set the partialResult flag to b.
+// When set to true, the parser returns a partial result along with the
+// error when the entry rule fails, instead of a nil value. The partial
+// result is the list of the values of the elements that matched in the
+// sequence of the entry rule that went the furthest in the input before
+// failing, e.g. the values of the statements that were parsed before the
+// failing one. It is not the value of an action, as the action code is
+// not executed for a failed match.
+//
+// The partial result is best-effort: it is nil if the entry rule fails
+// before any element of a sequence matched, if the entry rule has no
+// sequence, or if the parsing fails because of a panic.
+//
+// The default is false.
+func PartialResult(b bool) Option {
+	return func(p *parser) Option {
+		old := p.partialResult
+		p.partialResult = b
+		return PartialResult(old)
+	}
+}
+
+// Diagnostics creates an Option to collect the diagnostics recorded by the
+// code blocks with current.Warn and current.Diag in diags. The diagnostics
+// are appended to diags as they are recorded, and they do not fail the
+// parse. If this option is not set, the diagnostics are discarded.
+func Diagnostics(diags *[]Diagnostic) Option {
+	return func(p *parser) Option {
+		old := p.diags
+		p.diags = diags
+		return Diagnostics(old)
+	}
+}
+
 // GlobalStore creates an Option to set a key to a certain value in
 // the globalStore.
 func GlobalStore(key string, value interface{}) Option {
@@ -153,6 +365,24 @@ func GlobalStore(key string, value interface{}) Option {
 	}
 }
 
+// TransactionalStore creates an Option to tie the values stored under
+// the provided keys in the globalStore to the backtracking of the parser.
+// When an alternative of a choice expression is tried, the values of
+// those keys are saved and, if the alternative does not match, they are
+// restored before the next alternative is tried. Values that implement
+// the Cloner interface are cloned when saved, other values are copied
+// as-is. Only the listed keys are saved, as copying the whole globalStore
+// for every alternative would be expensive.
+//
+// The default is to not restore any key of the globalStore.
+func TransactionalStore(keys ...string) Option {
+	return func(p *parser) Option {
+		old := p.txKeys
+		p.txKeys = keys
+		return TransactionalStore(old...)
+	}
+}
+
 // ==template== {{ if or .GlobalState (not .Optimize) }}
 
 // InitState creates an Option to set a key to a certain value in
@@ -198,6 +428,200 @@ func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
 	return newParser(filename, b, opts...).parse(g)
 }
 
+// ParseRuneReader parses the runes read from r using filename as information
+// in the error messages. The runes are consumed as the parser advances,
+// so any decoder that produces runes (e.g. from UTF-16 or another encoding)
+// can be used without a separate transcoding pass. The runes read so far
+// are buffered to allow backtracking, and the offsets reported in positions
+// and errors are byte offsets in the UTF-8 encoding of those runes.
+func ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error) { //{{ if .Nolint }} nolint: deadcode {{else}} ==template== {{ end }}
+	p := newParser(filename, nil, opts...)
+	p.rr = r
+	return p.parse(g)
+}
+
+// ==template== {{ if .Iface }}
+// Parser is the interface of the Parse* functions, so that the code that
+// uses the parser may depend on it and be tested with a stub instead.
+// DefaultParser implements it with the Parse* functions.
+type Parser interface {
+	Parse(filename string, b []byte, opts ...Option) (interface{}, error)
+	ParseFile(filename string, opts ...Option) (interface{}, error)
+	ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error)
+	ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error)
+}
+
+// DefaultParser is the Parser that calls the Parse* functions. The zero
+// value is ready to use.
+type DefaultParser struct{}
+
+var _ Parser = DefaultParser{}
+
+// Parse parses the data from b like the Parse function.
+func (DefaultParser) Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return Parse(filename, b, opts...)
+}
+
+// ParseFile parses the file identified by filename like the ParseFile
+// function.
+func (DefaultParser) ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return ParseFile(filename, opts...)
+}
+
+// ParseReader parses the data from r like the ParseReader function.
+func (DefaultParser) ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return ParseReader(filename, r, opts...)
+}
+
+// ParseRuneReader parses the runes read from r like the ParseRuneReader
+// function.
+func (DefaultParser) ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error) {
+	return ParseRuneReader(filename, r, opts...)
+}
+
+// {{ end }} ==template==
+
+// ParserPool is a pool of parsers that reuses the memory allocated by a
+// parser for the following parses, which is useful when parsing a lot of
+// small inputs. Each parse starts with a parser that is reset, so that no
+// state, error or memoized result is shared between parses. The zero
+// value is ready to use, and a ParserPool is safe for concurrent use.
+type ParserPool struct {
+	pool sync.Pool
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages, like Parse, with a parser taken from the pool.
+func (pp *ParserPool) Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	p := pp.get(filename, b, opts...)
+	defer pp.put(p)
+	return p.parse(g)
+}
+
+// get returns a parser of the pool, reset to parse the data from b using
+// filename as information in the error messages and configured with the
+// options. The parser must be returned to the pool with put once the
+// parse is done.
+func (pp *ParserPool) get(filename string, b []byte, opts ...Option) *parser {
+	p, ok := pp.pool.Get().(*parser)
+	if !ok {
+		return newParser(filename, b, opts...)
+	}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// put returns the parser p to the pool. It must not be used after that.
+func (pp *ParserPool) put(p *parser) {
+	// do not keep the input and the results alive while in the pool.
+	p.Reset("", nil)
+	pp.pool.Put(p)
+}
+
+// ==template== {{ if .Visitor }}
+
+// ResultNode is the interface implemented by the values returned by the
+// code blocks that have children, so that they can be traversed by
+// WalkResult.
+type ResultNode interface {
+	Children() []interface{}
+}
+
+// WalkResult traverses the result tree rooted at v in depth-first order:
+// it starts by calling fn(v) and, if fn returns true, it walks each of the
+// children of v. The children of a ResultNode are those returned by its
+// Children method, and the children of a []interface{} (e.g. the value of
+// a sequence or a repetition expression) are its elements. Nil values are
+// not visited.
+func WalkResult(v interface{}, fn func(interface{}) bool) {
+	if v == nil || !fn(v) {
+		return
+	}
+	switch v := v.(type) {
+	case ResultNode:
+		for _, child := range v.Children() {
+			WalkResult(child, fn)
+		}
+	case []interface{}:
+		for _, child := range v {
+			WalkResult(child, fn)
+		}
+	}
+}
+
+// {{ end }} ==template==
+
+// ==template== {{ if .CST }}
+// CSTNode is a node of the concrete syntax tree returned by the parser.
+// There is a node for each match of a rule, and its children are the nodes
+// of the rules matched in it, interleaved with leaves for the text between
+// them, e.g. the literals and the whitespace matched by the rule itself.
+// The leaves are the nodes without children, so the text of a node is the
+// concatenation of the text of its leaves. The leaves that are not the
+// match of a rule have an empty Rule.
+type CSTNode struct {
+	Rule     string
+	Span     Span
+	Children []*CSTNode
+
+	text []byte
+}
+
+// Text returns the text of the input matched by the node.
+func (n *CSTNode) Text() string {
+	return string(n.text)
+}
+
+// cstNode returns the node of the match of rule from start to the current
+// position, val being the value of the expression of the rule.
+func (p *parser) cstNode(rule *rule, start savepoint, val interface{}) *CSTNode {
+	node := &CSTNode{
+		Rule: rule.name,
+		Span: Span{Start: start.position, End: p.pt.position},
+		text: p.sliceFrom(start),
+	}
+	children := cstChildren(val, nil)
+	if len(children) == 0 {
+		return node
+	}
+
+	node.Children = make([]*CSTNode, 0, 2*len(children)+1)
+	pos := start.position
+	leaf := func(end position) {
+		if end.offset > pos.offset {
+			node.Children = append(node.Children, &CSTNode{
+				Span: Span{Start: pos, End: end},
+				text: p.data[pos.offset:end.offset],
+			})
+		}
+	}
+	for _, child := range children {
+		leaf(child.Span.Start)
+		node.Children = append(node.Children, child)
+		pos = child.Span.End
+	}
+	leaf(node.Span.End)
+	return node
+}
+
+// cstChildren appends the nodes in val, the value of an expression, to
+// nodes. As the actions are not run, the nodes of the rules matched by an
+// expression are in its value, in order.
+func cstChildren(val interface{}, nodes []*CSTNode) []*CSTNode {
+	switch val := val.(type) {
+	case *CSTNode:
+		nodes = append(nodes, val)
+	case []interface{}:
+		for _, v := range val {
+			nodes = cstChildren(v, nodes)
+		}
+	}
+	return nodes
+}
+
+// {{ end }} ==template==
+
 // position records a position in the text.
 type position struct {
 	line, col, offset int
@@ -207,6 +631,32 @@ func (p position) String() string {
 	return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col) + " [" + strconv.Itoa(p.offset) + "]"
 }
 
+// Span is the range of the input text matched by an expression, e.g. to
+// underline it in error messages. Start is the position of the first rune
+// of the match and End is the position right after the last rune, the
+// columns are counted in runes so multibyte characters count as one column.
+type Span struct {
+	Start position
+	End   position
+}
+
+func (s Span) String() string {
+	return s.Start.String() + "-" + s.End.String()
+}
+
+// Diagnostic is a message recorded by a code block with current.Warn or
+// current.Diag, e.g. to report the use of a deprecated syntax, without
+// failing the parse.
+type Diagnostic struct {
+	Pos      position
+	Severity string
+	Msg      string
+}
+
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Severity + ": " + d.Msg
+}
+
 // savepoint stores all state required to go back to this point in the
 // parser.
 type savepoint struct {
@@ -217,8 +667,12 @@ type savepoint struct {
 
 type current struct {
 	pos  position // start position of the match
+	end  position // end position of the match
 	text []byte   // raw text of the match
 
+	// ruleName is the name of the innermost rule being parsed.
+	ruleName string
+
 	// ==template== {{ if or .GlobalState (not .Optimize) }}
 
 	// state is a store for arbitrary key,value pairs that the user wants to be
@@ -234,8 +688,198 @@ type current struct {
 	// rolled back by the parser. It is always up to the user to keep this in a
 	// consistent state.
 	globalStore storeDict
+
+	// parser is the parser that runs the code blocks, used by the helpers
+	// that inspect the input.
+	parser *parser
+}
+
+// Span returns the span of the current match. It is only meaningful in
+// action code blocks.
+func (c *current) Span() Span {
+	return Span{Start: c.pos, End: c.end}
+}
+
+// TextUnsafe returns the raw text of the current match as a subslice of
+// the input, like c.text, but even if c.text was modified by the code
+// block. Neither is a copy of the input: the returned slice must be
+// treated as read-only, and it must not be retained after the parse if
+// the input may be modified or reused. It is only meaningful in action
+// code blocks.
+func (c *current) TextUnsafe() []byte {
+	return c.parser.data[c.pos.offset:c.end.offset]
+}
+
+// Warn records a diagnostic of severity "warning" with the message msg,
+// as Diag does.
+func (c *current) Warn(msg string) {
+	c.Diag("warning", msg)
+}
+
+// Diag records a diagnostic of the given severity with the message msg at
+// the start position of the current match, which is only meaningful in
+// action code blocks. Unlike an error returned by a code block, it does not
+// fail the parse. The diagnostics are collected by the Diagnostics option,
+// and they are not removed if the parser backtracks after the code block
+// ran.
+func (c *current) Diag(severity, msg string) {
+	p := c.parser
+	if p.diags == nil {
+		return
+	}
+	*p.diags = append(*p.diags, Diagnostic{Pos: c.pos, Severity: severity, Msg: msg})
+}
+
+// Mark is a checkpoint of the parser, as returned by c.Mark, that a code
+// block may restore later with c.Restore.
+type Mark struct {
+	parser *parser
+	pt     savepoint
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	state storeDict
+	// {{ end }} ==template==
+}
+
+// Offset returns the byte offset of the position of the mark.
+func (m Mark) Offset() int {
+	return m.pt.offset
+}
+
+// Mark returns a checkpoint of the current position of the parser and of
+// the state store, e.g. to implement a custom lookahead or commit logic
+// not expressible in the grammar. In an action code block, the position is
+// the end of the match.
+func (c *current) Mark() Mark {
+	p := c.parser
+	m := Mark{parser: p, pt: p.pt}
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	m.state = p.cloneState()
+	// {{ end }} ==template==
+	return m
+}
+
+// Restore moves the parser back (or forward) to the position of the mark
+// m, and restores the state store as it was when the mark was created. The
+// parser then continues from that position after the code block returns.
+//
+// The mark must have been created during the same parse. It returns an
+// error if it was created by another parser or beyond the input read so
+// far, but the marks of a previous parse of a parser reused by a
+// ParserPool are not detected. As the changes to the state store made by
+// action and predicate code blocks are always rolled back, the state is
+// only restored by a state change code block. Note that with memoization, the result of
+// a rule whose code blocks call Restore is memoized with the position
+// where the parser ended up, and the code blocks are not run again when
+// the memoized result is used.
+func (c *current) Restore(m Mark) error {
+	p := c.parser
+	if m.parser != p {
+		return errors.New("invalid mark: not created by this parser")
+	}
+	if m.pt.offset > len(p.data) {
+		return errors.New("invalid mark: beyond the input read so far")
+	}
+	p.restoreMark(m)
+	return nil
+}
+
+// restoreMark restores the parser to the mark m.
+func (p *parser) restoreMark(m Mark) {
+	p.restore(m.pt)
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	p.cur.state.Discard()
+	// keep the state of the mark intact so that it may be restored again
+	p.cur.state = m.state
+	p.cur.state = p.cloneState()
+	// {{ end }} ==template==
+}
+
+// LineIndent returns the width of the leading whitespace of the line of
+// the current position of the parser, where each space and each tab counts
+// for one column. It may be used in predicate and state change code blocks
+// of off-side rule grammars, even before the leading whitespace is matched.
+func (c *current) LineIndent() int {
+	p := c.parser
+	start := p.pt.offset
+	for start > 0 && p.data[start-1] != '\n' {
+		start--
+	}
+	n := 0
+	for {
+		if start+n >= len(p.data) {
+			if p.rr == nil {
+				break
+			}
+			// read ahead from the rune reader, without moving the parser.
+			p.readRune()
+			continue
+		}
+		if b := p.data[start+n]; b != ' ' && b != '\t' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// ==template== {{ if or .GlobalState (not .Optimize) }}
+
+// indentKey is the key of the indentation stack in the state store.
+const indentKey = "pigeon.indent"
+
+// indentStack returns the indentation stack stored in the state.
+func (c *current) indentStack() []int {
+	stack, _ := c.state[indentKey].([]int)
+	return stack
+}
+
+// PushIndent pushes the indentation level n on the indentation stack. As
+// the stack is kept in the state store, it must be called from a state
+// change code block, and it is rolled back if the rule fails.
+func (c *current) PushIndent(n int) {
+	stack := c.indentStack()
+	// copy the stack so that the saved states are not modified.
+	c.state[indentKey] = append(stack[:len(stack):len(stack)], n)
+}
+
+// PopIndent pops the indentation level at the top of the indentation
+// stack and returns it, or returns 0 if the stack is empty. Like
+// PushIndent, it must be called from a state change code block.
+func (c *current) PopIndent() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	c.state[indentKey] = stack[: len(stack)-1 : len(stack)-1]
+	return stack[len(stack)-1]
 }
 
+// IndentLevel returns the indentation level at the top of the
+// indentation stack, or 0 if the stack is empty.
+func (c *current) IndentLevel() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	return stack[len(stack)-1]
+}
+
+// SameIndent returns true if the line of the current position is indented
+// at the level at the top of the indentation stack, e.g. for a predicate
+// such as &{ return c.SameIndent(), nil }.
+func (c *current) SameIndent() bool {
+	return c.LineIndent() == c.IndentLevel()
+}
+
+// MoreIndented returns true if the line of the current position is
+// indented more than the level at the top of the indentation stack, i.e.
+// if it starts a new indented block.
+func (c *current) MoreIndented() bool {
+	return c.LineIndent() > c.IndentLevel()
+}
+
+// {{ end }} ==template==
+
 type storeDict map[string]interface{}
 
 // the AST types...
@@ -252,13 +896,19 @@ type rule struct {
 	name        string
 	displayName string
 	expr        interface{}
+	// ==template== {{ if not .Optimize }}
+	// memoize is set if the results of the rule are memoized with the
+	// MemoizeRules option.
+	memoize bool
+	// {{ end }} ==template==
 }
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 type choiceExpr struct {
 	pos          position
 	alternatives []interface{}
-	skipVals 	 bool
+	skipVals     bool
+	longest      bool
 }
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -280,7 +930,9 @@ type recoveryExpr struct {
 type seqExpr struct {
 	pos   position
 	exprs []interface{}
-	vals  []interface{}
+	// 1-based index of the values buffer of the parser that is reused
+	// for the values of the sequence, 0 if the buffer is not reused.
+	valsIx int
 }
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -298,8 +950,8 @@ type labeledExpr struct {
 
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 type expr struct {
-	pos  position
-	expr interface{}
+	pos      position
+	expr     interface{}
 	skipVals bool
 }
 
@@ -343,10 +995,9 @@ type litMatcher struct {
 	val        string
 	ignoreCase bool
 	want       string
-	invert 	   bool
+	invert     bool
 }
 
-
 //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 type charClassMatcher struct {
 	pos             position
@@ -359,8 +1010,40 @@ type charClassMatcher struct {
 	inverted        bool
 }
 
+// ==template== {{ if .Regexp }}
+//{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type regexpMatcher struct {
+	pos  position
+	re   *regexp.Regexp
+	want string
+}
+
+// {{ end }} ==template==
+// ==template== {{ if .Keyword }}
+//{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type keywordMatcher struct {
+	pos position
+	// if nil, the keywords of the Keywords option are used
+	keywords map[string]bool
+	want     string
+}
+
+// {{ end }} ==template==
+// ==template== {{ if .Lookbehind }}
+//{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type lookbehindExpr struct {
+	pos  position
+	expr interface{}
+	// number of characters matched by expr
+	n      int
+	negate bool
+}
+
+// {{ end }} ==template==
 type anyMatcher position //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 
+type bolMatcher position //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+
 // errList cumulates the errors found by the parser.
 type errList []error
 
@@ -388,6 +1071,19 @@ func (e *errList) dedupe() {
 	*e = cleaned
 }
 
+// FurthestPos returns the furthest position where the parser failed to
+// match, among the errors of the list, e.g. to show where the parsing gave
+// up.
+func (e errList) FurthestPos() position {
+	var pos position
+	for _, err := range e {
+		if pe, ok := err.(*parserError); ok && pe.furthest.offset >= pos.offset {
+			pos = pe.furthest
+		}
+	}
+	return pos
+}
+
 func (e errList) Error() string {
 	switch len(e) {
 	case 0:
@@ -407,11 +1103,24 @@ func (e errList) Error() string {
 	}
 }
 
+// As finds the first error in the list that matches target, as defined by
+// errors.As, and sets target to that error.
+func (e errList) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // parserError wraps an error with a prefix indicating the rule in which
 // the error occurred. The original error is stored in the Inner field.
 type parserError struct {
 	Inner    error
 	pos      position
+	span     Span
+	furthest position
 	prefix   string
 	expected []string
 }
@@ -421,37 +1130,90 @@ func (p *parserError) Error() string {
 	return p.prefix + ": " + p.Inner.Error()
 }
 
+// Unwrap returns the original error.
+func (p *parserError) Unwrap() error {
+	return p.Inner
+}
+
+// Span returns the span of the input text that caused the error. For
+// errors returned by action code blocks, it is the span matched by the
+// action's expression, and for a failed match it is the rune where the
+// parser failed to match (empty at the end of the input).
+func (p *parserError) Span() Span {
+	return p.span
+}
+
+// FurthestPos returns the furthest position where the parser failed to
+// match when the error occurred. For the errors that occur while a
+// failure is recovered, it is the furthest position when the failure was
+// thrown.
+func (p *parserError) FurthestPos() position {
+	return p.furthest
+}
+
 // newParser creates a parser with the specified input source and options.
 func newParser(filename string, b []byte, opts ...Option) *parser {
-	stats := Stats{
-		ChoiceAltCnt: make(map[string]map[string]int),
+	p := &parser{}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// Reset resets the parser so that it parses the data from b using
+// filename as information in the error messages, as if it was newly
+// created without any option. The errors, the statistics, the state,
+// the global store and the memoization table of the previous parse are
+// all cleared, but the memory allocated for them is reused when it is
+// safe to do so. The memoization table is always rebuilt by parse.
+func (p *parser) Reset(filename string, b []byte) {
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	state := p.cur.state
+	if state == nil {
+		state = make(storeDict)
+	}
+	for k := range state {
+		delete(state, k)
+	}
+	// {{ end }} ==template==
+	globalStore := p.cur.globalStore
+	if globalStore == nil {
+		globalStore = make(storeDict)
+	}
+	for k := range globalStore {
+		delete(globalStore, k)
 	}
 
-	p := &parser{
+	*p = parser{
 		filename: filename,
-		errs:     new(errList),
-		data:     b,
-		pt:       savepoint{position: position{line: 1}},
-		recover:  true,
+		// the errors are returned to the caller, so they are never reused.
+		errs: new(errList),
+		data: b,
+		pt:   savepoint{position: position{line: 1}},
 		cur: current{
 			// ==template== {{ if or .GlobalState (not .Optimize) }}
-			state: make(storeDict),
+			state: state,
 			// {{ end }} ==template==
-			globalStore: make(storeDict),
+			globalStore: globalStore,
+		},
+		recover:          true,
+		vstack:           p.vstack[:0],
+		rstack:           p.rstack[:0],
+		maxFailPos:       position{col: 1, line: 1},
+		maxFailExpected:  p.maxFailExpected[:0],
+		maxExprCnt:       math.MaxUint64,
+		progressInterval: 4096,
+		Stats: &Stats{
+			ChoiceAltCnt: make(map[string]map[string]int),
 		},
-		maxFailPos:      position{col: 1, line: 1},
-		maxFailExpected: make([]string, 0, 20),
-		Stats:           &stats,
 		// start rule is rule [0] unless an alternate entrypoint is specified
-		entrypoint: g.rules[0].name,
+		entrypoint:      g.rules[0].name,
+		recoveryStack:   p.recoveryStack[:0],
+		recoveryFailPos: p.recoveryFailPos[:0],
 	}
-	p.setOptions(opts)
-
-	if p.maxExprCnt == 0 {
-		p.maxExprCnt = math.MaxUint64
+	p.cur.parser = p
+	if p.maxFailExpected == nil {
+		p.maxFailExpected = make([]string, 0, 20)
 	}
-
-	return p
 }
 
 // setOptions applies the options to the parser.
@@ -459,6 +1221,10 @@ func (p *parser) setOptions(opts []Option) {
 	for _, opt := range opts {
 		opt(p)
 	}
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
 }
 
 //{{ if .Nolint }} nolint: structcheck,deadcode {{else}} ==template== {{ end }}
@@ -478,6 +1244,11 @@ type Stats struct {
 	// (set by the MaxExpressions option).
 	ExprCnt uint64
 
+	// Steps counts the number of attempts to match the input with a
+	// matcher, including the failed ones. This value is compared to the
+	// maximum number of steps allowed (set by the MaxSteps option).
+	Steps uint64
+
 	// ChoiceAltCnt is used to count for each ordered choice expression,
 	// which alternative is used how may times.
 	// These numbers allow to optimize the order of the ordered choice expression
@@ -493,6 +1264,40 @@ type Stats struct {
 	ChoiceAltCnt map[string]map[string]int
 }
 
+// ChoiceStat is the number of matches of an alternative of an ordered
+// choice expression, as counted in Stats.ChoiceAltCnt.
+type ChoiceStat struct {
+	// Choice is the outer key of ChoiceAltCnt, composed of the name of the
+	// rule and the line and column of the ordered choice.
+	Choice string
+	// Alternative is the inner key of ChoiceAltCnt, the number (one-based)
+	// of the alternative or the name of the "no match" counter.
+	Alternative string
+	// Count is the number of matches of the alternative.
+	Count int
+}
+
+// SortedRules returns the entries of ChoiceAltCnt in a deterministic order:
+// sorted by choice, then by descending count and then by alternative.
+func (s *Stats) SortedRules() []ChoiceStat {
+	var stats []ChoiceStat
+	for choice, alts := range s.ChoiceAltCnt {
+		for alt, cnt := range alts {
+			stats = append(stats, ChoiceStat{Choice: choice, Alternative: alt, Count: cnt})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Choice != stats[j].Choice {
+			return stats[i].Choice < stats[j].Choice
+		}
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Alternative < stats[j].Alternative
+	})
+	return stats
+}
+
 //{{ if .Nolint }} nolint: structcheck,maligned {{else}} ==template== {{ end }}
 type parser struct {
 	filename string
@@ -502,12 +1307,22 @@ type parser struct {
 	data []byte
 	errs *errList
 
+	// rune source for ParseRuneReader, the runes read from it are
+	// appended to data as UTF-8 so that the parser can backtrack.
+	// It is set to nil once it is exhausted.
+	rr io.RuneReader
+
 	depth   int
 	recover bool
 	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not .Lib }}
 	debug bool
+	// {{ end }} ==template==
 
 	memoize bool
+	// if set, only the results of the rules with the memoize field set
+	// are memoized
+	memoizeRules bool
 	// memoization table for the packrat algorithm:
 	// map[offset in source] map[expression or rule] {value, match}
 	memo map[int]map[interface{}]resultTuple
@@ -525,18 +1340,54 @@ type parser struct {
 	maxFailExpected       []string
 	maxFailInvertExpected bool
 
+	// keys of the globalStore tied to the backtracking of the parser
+	txKeys []string
+
 	// max number of expressions to be parsed
 	maxExprCnt uint64
+	// max number of attempts to match with a matcher, if > 0
+	maxSteps int
+	// max number of nested rules, if > 0
+	maxDepth int
+
+	// if set, called as the furthest offset reached advances
+	onProgress func(offset, total int)
+	// min advance of the furthest offset between two calls of onProgress
+	progressInterval int
+	// furthest offset reached, and offset from which onProgress is called
+	progressOffset int
+	progressNext   int
+	// ==template== {{ if .Keyword }}
+	// keywords matched by the keyword matchers that do not declare them
+	keywords map[string]bool
+	// {{ end }} ==template==
 	// entrypoint for the parser
 	entrypoint string
 
 	allowInvalidUTF8 bool
+	// if set, a leading byte order mark is skipped
+	stripBOM bool
+
+	// if set, the diagnostics recorded by the code blocks are appended to it
+	diags *[]Diagnostic
+
+	// if set, the partial result is returned when the parsing fails
+	partialResult bool
+	// values of the failed sequence of the entry rule that matched the
+	// furthest, and the offset where its last matched element ends
+	partial    []interface{}
+	partialEnd int
 
 	*Stats
 
 	choiceNoMatch string
 	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
 	recoveryStack []map[string]interface{}
+	// furthest failure positions of the failures being recovered
+	recoveryFailPos []position
+	// values buffers reused by the sequences, by index, so that they are
+	// not shared between parsers
+	seqVals [][]interface{}
 }
 
 // push a variable set on the vstack.
@@ -596,7 +1447,7 @@ func (p *parser) popRecovery() {
 	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
 }
 
-// ==template== {{ if not .Optimize }}
+// ==template== {{ if not (or .Optimize .Lib) }}
 func (p *parser) print(prefix, s string) string {
 	if !p.debug {
 		return s
@@ -624,6 +1475,11 @@ func (p *parser) addErr(err error) {
 }
 
 func (p *parser) addErrAt(err error, pos position, expected []string) {
+	p.addErrSpan(err, Span{Start: pos, End: pos}, expected)
+}
+
+func (p *parser) addErrSpan(err error, span Span, expected []string) {
+	pos := span.Start
 	var buf bytes.Buffer
 	if p.filename != "" {
 		buf.WriteString(p.filename)
@@ -643,7 +1499,11 @@ func (p *parser) addErrAt(err error, pos position, expected []string) {
 			buf.WriteString("rule " + rule.name)
 		}
 	}
-	pe := &parserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	furthest := p.maxFailPos
+	if n := len(p.recoveryFailPos); n > 0 {
+		furthest = p.recoveryFailPos[n-1]
+	}
+	pe := &parserError{Inner: err, pos: pos, span: span, furthest: furthest, prefix: buf.String(), expected: expected}
 	p.errs.add(pe)
 }
 
@@ -669,9 +1529,37 @@ func (p *parser) failAt(fail bool, pos position, want string) {
 	}
 }
 
+// ==template== {{ if not .Optimize }}
+// failRule reports the failure of the rule that started at pos using its
+// display name, instead of the expected values of its expression, if the
+// rule did not match past its starting position. The failPos and failLen
+// are the farthest failure position and the number of expected values
+// when the rule started.
+func (p *parser) failRule(rule *rule, pos, failPos position, failLen int) {
+	if p.maxFailInvertExpected || p.maxFailPos.offset > pos.offset {
+		return
+	}
+	if p.maxFailPos.offset == pos.offset {
+		if failPos.offset != pos.offset {
+			failLen = 0
+		}
+		p.maxFailExpected = p.maxFailExpected[:failLen]
+	}
+	// the display name is the raw string literal of the grammar
+	p.failAt(false, pos, rule.displayName[1:len(rule.displayName)-1])
+}
+
+// {{ end }} ==template==
+
 // read advances the parser to the next rune.
 func (p *parser) read() {
 	p.pt.offset += p.pt.w
+	if p.rr != nil && p.pt.offset >= len(p.data) {
+		p.readRune()
+	}
+	if p.onProgress != nil && p.pt.offset > p.progressOffset {
+		p.progress()
+	}
 	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
 	p.pt.rn = rn
 	p.pt.w = n
@@ -688,9 +1576,55 @@ func (p *parser) read() {
 	}
 }
 
+// progress records the current offset as the furthest offset reached,
+// and calls onProgress if it advanced enough since the previous call.
+func (p *parser) progress() {
+	p.progressOffset = p.pt.offset
+	if p.progressOffset >= p.progressNext {
+		p.onProgress(p.progressOffset, p.progressTotal())
+		p.progressNext = p.progressOffset + p.progressInterval
+	}
+}
+
+// progressTotal returns the size of the input, or -1 if it is not known
+// yet.
+func (p *parser) progressTotal() int {
+	if p.rr != nil {
+		return -1
+	}
+	return len(p.data)
+}
+
+// readRune reads the next rune from the rune reader and appends it to
+// the buffered data.
+func (p *parser) readRune() {
+	rn, _, err := p.rr.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			p.addErr(err)
+		}
+		p.rr = nil
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], rn)
+	p.data = append(p.data, buf[:n]...)
+}
+
+// skipBOM removes the UTF-8 byte order mark at the start of the input, if
+// any, so that the positions are relative to the content that follows it.
+func (p *parser) skipBOM() {
+	if p.rr != nil && len(p.data) == 0 {
+		p.readRune()
+	}
+	if rn, n := utf8.DecodeRune(p.data); rn == '\uFEFF' {
+		p.data = p.data[n:]
+	}
+}
+
 // restore parser position to the savepoint pt.
 func (p *parser) restore(pt savepoint) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("restore"))
 	}
@@ -701,19 +1635,49 @@ func (p *parser) restore(pt savepoint) {
 	p.pt = pt
 }
 
-// ==template== {{ if or .GlobalState (not .Optimize) }}
-
 // Cloner is implemented by any value that has a Clone method, which returns a
 // copy of the value. This is mainly used for types which are not passed by
 // value (e.g map, slice, chan) or structs that contain such types.
 //
-// This is used in conjunction with the global state feature to create proper
-// copies of the state to allow the parser to properly restore the state in
-// the case of backtracking.
+// This is used in conjunction with the global state feature and the
+// TransactionalStore option to create proper copies of the state to allow
+// the parser to properly restore the state in the case of backtracking.
 type Cloner interface {
 	Clone() interface{}
 }
 
+// snapshotGlobalStore returns a copy of the transactional keys of the
+// globalStore.
+func (p *parser) snapshotGlobalStore() storeDict {
+	snapshot := make(storeDict, len(p.txKeys))
+	for _, k := range p.txKeys {
+		v, ok := p.cur.globalStore[k]
+		if !ok {
+			continue
+		}
+		if c, ok := v.(Cloner); ok {
+			snapshot[k] = c.Clone()
+		} else {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// restoreGlobalStore restores the transactional keys of the globalStore
+// to the values of the snapshot.
+func (p *parser) restoreGlobalStore(snapshot storeDict) {
+	for _, k := range p.txKeys {
+		if v, ok := snapshot[k]; ok {
+			p.cur.globalStore[k] = v
+		} else {
+			delete(p.cur.globalStore, k)
+		}
+	}
+}
+
+// ==template== {{ if or .GlobalState (not .Optimize) }}
+
 var statePool = &sync.Pool{
 	New: func() interface{} { return make(storeDict) },
 }
@@ -727,7 +1691,7 @@ func (sd storeDict) Discard() {
 
 // clone and return parser current state.
 func (p *parser) cloneState() storeDict {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("cloneState"))
 	}
@@ -747,7 +1711,7 @@ func (p *parser) cloneState() storeDict {
 // restore parser current state to the state storeDict.
 // every restoreState should applied only one time for every cloned state
 func (p *parser) restoreState(state storeDict) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("restoreState"))
 	}
@@ -758,6 +1722,18 @@ func (p *parser) restoreState(state storeDict) {
 
 // {{ end }} ==template==
 
+// runeSpan returns the span of the rune at pos, or an empty span at pos
+// if it is at the end of the input.
+func (p *parser) runeSpan(pos position) Span {
+	end := pos
+	if pos.offset < len(p.data) {
+		_, n := utf8.DecodeRune(p.data[pos.offset:])
+		end.offset += n
+		end.col++
+	}
+	return Span{Start: pos, End: end}
+}
+
 // get the slice of bytes from the savepoint start to the current position.
 func (p *parser) sliceFrom(start savepoint) []byte {
 	return p.data[start.position.offset:p.pt.position.offset]
@@ -807,12 +1783,19 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 	// TODO : not super critical but this could be generated
 	p.buildRulesTable(g)
 
+	// ==template== {{ if not .Optimize }}
+	// the memoized results depend on the entrypoint, as the rules may
+	// behave differently depending on the state set by the entry rule,
+	// so they are never reused across parses.
+	p.memo = nil
+	// {{ end }} ==template==
+
 	if p.recover {
 		// panic can be used in action code to stop parsing immediately
 		// and return the panic as an error.
 		defer func() {
 			if e := recover(); e != nil {
-				// ==template== {{ if not .Optimize }}
+				// ==template== {{ if not (or .Optimize .Lib) }}
 				if p.debug {
 					defer p.out(p.in("panic handler"))
 				}
@@ -835,37 +1818,140 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 		return nil, p.errs.err()
 	}
 
+	if p.stripBOM {
+		p.skipBOM()
+	}
 	p.read() // advance to first rune
 	val, ok = p.parseRule(startRule)
+	if p.onProgress != nil {
+		p.onProgress(p.progressOffset, p.progressTotal())
+	}
 	if !ok {
 		if len(*p.errs) == 0 {
 			// If parsing fails, but no errors have been recorded, the expected values
 			// for the farthest parser position are returned as error.
-			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
-			for _, v := range p.maxFailExpected {
-				maxFailExpectedMap[v] = struct{}{}
-			}
-			expected := make([]string, 0, len(maxFailExpectedMap))
-			eof := false
-			if _, ok := maxFailExpectedMap["!."]; ok {
-				delete(maxFailExpectedMap, "!.")
-				eof = true
-			}
-			for k := range maxFailExpectedMap {
-				expected = append(expected, k)
-			}
-			sort.Strings(expected)
-			if eof {
-				expected = append(expected, "EOF")
-			}
-			p.addErrAt(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.maxFailPos, expected)
+			p.addNoMatchErr()
 		}
 
+		if p.partial != nil {
+			return p.partial, p.errs.err()
+		}
 		return nil, p.errs.err()
 	}
 	return val, p.errs.err()
 }
 
+// addNoMatchErr adds the "no match found" error, with the values expected
+// at the farthest position where the parser failed.
+func (p *parser) addNoMatchErr() {
+	maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+	for _, v := range p.maxFailExpected {
+		maxFailExpectedMap[v] = struct{}{}
+	}
+	expected := make([]string, 0, len(maxFailExpectedMap))
+	eof := false
+	if _, ok := maxFailExpectedMap["!."]; ok {
+		delete(maxFailExpectedMap, "!.")
+		eof = true
+	}
+	for k := range maxFailExpectedMap {
+		expected = append(expected, k)
+	}
+	sort.Strings(expected)
+	if eof {
+		expected = append(expected, "EOF")
+	}
+	p.addErrSpan(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.runeSpan(p.maxFailPos), expected)
+}
+
+// ==template== {{ if .Tokens }}
+
+// tokens returns an iterator over the successive matches of the rule
+// named name, starting at the beginning of the data. Each call of the
+// iterator parses the next match and returns its value, or io.EOF once
+// all the data is consumed. Once the iterator returns an error, it
+// returns that same error on each subsequent call. The parser, including
+// its memoized results, is kept between the calls.
+func (p *parser) tokens(g *grammar, name string) (func() (interface{}, error), error) {
+	if len(g.rules) == 0 {
+		p.addErr(errNoRule)
+		return nil, p.errs.err()
+	}
+	p.buildRulesTable(g)
+	// ==template== {{ if not .Optimize }}
+	p.memo = nil
+	// {{ end }} ==template==
+
+	rule, ok := p.rules[name]
+	if !ok {
+		p.addErr(errInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	if p.stripBOM {
+		p.skipBOM()
+	}
+	p.read() // advance to first rune
+	var done error
+	return func() (val interface{}, err error) {
+		if done != nil {
+			return nil, done
+		}
+		defer func() {
+			if err != nil {
+				done = err
+			}
+		}()
+		if p.recover {
+			// panic can be used in action code to stop parsing immediately
+			// and return the panic as an error.
+			defer func() {
+				if e := recover(); e != nil {
+					val = nil
+					switch e := e.(type) {
+					case error:
+						p.addErr(e)
+					default:
+						p.addErr(fmt.Errorf("%v", e))
+					}
+					err = p.errs.err()
+				}
+			}()
+		}
+
+		if len(*p.errs) == 0 && p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+			// EOF - see utf8.DecodeRune
+			return nil, io.EOF
+		}
+		start := p.pt.offset
+		v, ok := p.parseRule(rule)
+		if (!ok || p.pt.offset == start) && len(*p.errs) == 0 {
+			// a match that consumes no input would repeat forever, it ends
+			// the repetition before the end of the data like a failure. The
+			// end of the data is expected as well as another match.
+			p.failAt(false, p.pt.position, "!.")
+			p.addNoMatchErr()
+		}
+		if err := p.errs.err(); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}, nil
+}
+
+// {{ end }} ==template==
+
+// setPartial records the values of the elements of a sequence of the
+// entry rule that matched before the sequence failed, if it matched
+// further than the previously recorded ones.
+func (p *parser) setPartial(vals []interface{}) {
+	if len(p.rstack) != 1 || p.pt.offset <= p.partialEnd {
+		return
+	}
+	p.partial = append([]interface{}(nil), vals...)
+	p.partialEnd = p.pt.offset
+}
+
 func listJoin(list []string, sep string, lastSep string) string {
 	switch len(list) {
 	case 0:
@@ -879,11 +1965,14 @@ func listJoin(list []string, sep string, lastSep string) string {
 
 func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not .Lib }}
 	if p.debug {
 		defer p.out(p.in("parseRule " + rule.name))
 	}
+	// {{ end }} ==template==
 
-	if p.memoize {
+	memoize := p.memoize && (!p.memoizeRules || rule.memoize)
+	if memoize {
 		res, ok := p.getMemoized(rule)
 		if ok {
 			p.restore(res.end)
@@ -892,18 +1981,39 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 	}
 
 	start := p.pt
+	failPos, failLen := p.maxFailPos, len(p.maxFailExpected)
+	// {{ end }} ==template==
+	// ==template== {{ if .CST }}
+	cstStart := p.pt
 	// {{ end }} ==template==
 	p.rstack = append(p.rstack, rule)
+	if p.maxDepth > 0 && len(p.rstack) > p.maxDepth {
+		panic(errMaxDepth)
+	}
+	ruleName := p.cur.ruleName
+	p.cur.ruleName = rule.name
 	p.pushV()
 	val, ok := p.parseExpr(rule.expr)
 	p.popV()
+	p.cur.ruleName = ruleName
 	p.rstack = p.rstack[:len(p.rstack)-1]
+	// ==template== {{ if .CST }}
+	if ok {
+		val = p.cstNode(rule, cstStart, val)
+	}
+	// {{ end }} ==template==
 	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not .Lib }}
 	if ok && p.debug {
 		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
 	}
+	// {{ end }} ==template==
 
-	if p.memoize {
+	if !ok && rule.displayName != "" {
+		p.failRule(rule, start.position, failPos, failLen)
+	}
+
+	if memoize {
 		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
 	}
 	// {{ end }} ==template==
@@ -915,7 +2025,8 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	// ==template== {{ if not .Optimize }}
 	var pt savepoint
 
-	if p.memoize {
+	memoize := p.memoize && !p.memoizeRules
+	if memoize {
 		res, ok := p.getMemoized(expr)
 		if ok {
 			p.restore(res.end)
@@ -935,21 +2046,36 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	var ok bool
 	switch expr := expr.(type) {
 	case *actionExpr:
+		// ==template== {{ if .CST }}
+		// the actions are not run when building the concrete syntax tree
+		val, ok = p.parseExpr(expr.expr)
+		// ==template== {{ else }}
 		val, ok = p.parseActionExpr(expr)
+		// {{ end }} ==template==
 	case *andCodeExpr:
 		val, ok = p.parseAndCodeExpr(expr)
 	case *andExpr:
 		val, ok = p.parseAndExpr(expr)
 	case *anyMatcher:
 		val, ok = p.parseAnyMatcher(expr)
+	case *bolMatcher:
+		val, ok = p.parseBOLMatcher(expr)
 	case *charClassMatcher:
 		val, ok = p.parseCharClassMatcher(expr)
 	case *choiceExpr:
 		val, ok = p.parseChoiceExpr(expr)
+	// ==template== {{ if .Keyword }}
+	case *keywordMatcher:
+		val, ok = p.parseKeywordMatcher(expr)
+	// {{ end }} ==template==
 	case *labeledExpr:
 		val, ok = p.parseLabeledExpr(expr)
 	case *litMatcher:
 		val, ok = p.parseLitMatcher(expr)
+	// ==template== {{ if .Lookbehind }}
+	case *lookbehindExpr:
+		val, ok = p.parseLookbehindExpr(expr)
+	// {{ end }} ==template==
 	case *notCodeExpr:
 		val, ok = p.parseNotCodeExpr(expr)
 	case *notExpr:
@@ -958,6 +2084,10 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 		val, ok = p.parseOneOrMoreExpr(expr)
 	case *recoveryExpr:
 		val, ok = p.parseRecoveryExpr(expr)
+	// ==template== {{ if .Regexp }}
+	case *regexpMatcher:
+		val, ok = p.parseRegexpMatcher(expr)
+	// {{ end }} ==template==
 	case *ruleRefExpr:
 		val, ok = p.parseRuleRefExpr(expr)
 	case *seqExpr:
@@ -976,7 +2106,7 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 		panic(fmt.Sprintf("unknown expression type %T", expr))
 	}
 	// ==template== {{ if not .Optimize }}
-	if p.memoize {
+	if memoize {
 		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
 	}
 	// {{ end }} ==template==
@@ -984,7 +2114,7 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 }
 
 func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseActionExpr"))
 	}
@@ -994,13 +2124,17 @@ func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 	val, ok := p.parseExpr(act.expr)
 	if ok {
 		p.cur.pos = start.position
-		p.cur.text = p.sliceFrom(start)
+		p.cur.end = p.pt.position
+		// the capacity is limited so that appending to c.text does not
+		// overwrite the input that follows the match
+		text := p.sliceFrom(start)
+		p.cur.text = text[:len(text):len(text)]
 		// ==template== {{ if or .GlobalState (not .Optimize) }}
 		state := p.cloneState()
 		// {{ end }} ==template==
 		actVal, err := act.run(p)
 		if err != nil {
-			p.addErrAt(err, start.position, []string{})
+			p.addErrSpan(err, Span{Start: start.position, End: p.pt.position}, []string{})
 		}
 		// ==template== {{ if or .GlobalState (not .Optimize) }}
 		p.restoreState(state)
@@ -1008,7 +2142,7 @@ func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 
 		val = actVal
 	}
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if ok && p.debug {
 		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
 	}
@@ -1017,7 +2151,7 @@ func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseAndCodeExpr"))
 	}
@@ -1039,7 +2173,7 @@ func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseAndExpr"))
 	}
@@ -1060,13 +2194,23 @@ func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
 	return nil, ok
 }
 
+// step counts an attempt to match the input with a matcher, and stops
+// the parsing if the maximum number of steps is exceeded.
+func (p *parser) step() {
+	p.Steps++
+	if p.maxSteps > 0 && p.Steps > uint64(p.maxSteps) {
+		panic(errMaxSteps)
+	}
+}
+
 func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseAnyMatcher"))
 	}
 
 	// {{ end }} ==template==
+	p.step()
 	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
 		// EOF - see utf8.DecodeRune
 		p.failAt(false, p.pt.position, ".")
@@ -1078,15 +2222,32 @@ func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
+func (p *parser) parseBOLMatcher(bol *bolMatcher) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
+	if p.debug {
+		defer p.out(p.in("parseBOLMatcher"))
+	}
+
+	// {{ end }} ==template==
+	p.step()
+	// the previous byte is always available, even when parsing from a rune
+	// reader, as the runes read are kept in data for backtracking.
+	if p.pt.offset == 0 || p.data[p.pt.offset-1] == '\n' {
+		return nil, true
+	}
+	p.failAt(false, p.pt.position, "^")
+	return nil, false
+}
 
 //{{ if .Nolint }} nolint: gocyclo {{else}} ==template== {{ end }}
 func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseCharClassMatcher"))
 	}
 
 	// {{ end }} ==template==
+	p.step()
 	cur := p.pt.rn
 	start := p.pt
 
@@ -1180,12 +2341,15 @@ func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
 // {{ end }} ==template==
 
 func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseChoiceExpr"))
 	}
 
 	// {{ end }} ==template==
+	if ch.longest {
+		return p.parseLongestChoiceExpr(ch)
+	}
 	for altI, alt := range ch.alternatives {
 		// dummy assignment to prevent compile error if optimized
 		_ = altI
@@ -1193,6 +2357,10 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 		// ==template== {{ if or .GlobalState (not .Optimize) }}
 		state := p.cloneState()
 		// {{ end }} ==template==
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
 
 		if !ch.skipVals {
 			p.pushV()
@@ -1210,6 +2378,9 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 		// ==template== {{ if or .GlobalState (not .Optimize) }}
 		p.restoreState(state)
 		// {{ end }} ==template==
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
 	}
 	// ==template== {{ if not .Optimize }}
 	p.incChoiceAltCnt(ch, choiceNoMatch)
@@ -1217,8 +2388,88 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 	return nil, false
 }
 
-func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
+// parseLongestChoiceExpr tries all the alternatives of the choice, from
+// the same starting point, and keeps the one that consumes the most input,
+// the first one in case of a tie. The state and the global store are
+// those left by the kept alternative.
+func (p *parser) parseLongestChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
+	if p.debug {
+		defer p.out(p.in("parseLongestChoiceExpr"))
+	}
+
+	// {{ end }} ==template==
+	start := p.pt
+	var (
+		bestVal interface{}
+		bestPt  savepoint
+		bestAlt = -1
+
+		bestSnapshot storeDict
+	)
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	var bestState storeDict
+	// {{ end }} ==template==
+
+	for altI, alt := range ch.alternatives {
+		// ==template== {{ if or .GlobalState (not .Optimize) }}
+		state := p.cloneState()
+		// {{ end }} ==template==
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
+
+		if !ch.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(alt)
+		if !ch.skipVals {
+			p.popV()
+		}
+		if ok && (bestAlt < 0 || p.pt.offset > bestPt.offset) {
+			bestVal, bestPt, bestAlt = val, p.pt, altI
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			if bestState != nil {
+				bestState.Discard()
+			}
+			bestState, p.cur.state = p.cur.state, state
+			// {{ end }} ==template==
+			if snapshot != nil {
+				bestSnapshot = p.snapshotGlobalStore()
+			}
+		} else {
+			// ==template== {{ if or .GlobalState (not .Optimize) }}
+			p.restoreState(state)
+			// {{ end }} ==template==
+		}
+		p.restore(start)
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
+	}
+
+	if bestAlt < 0 {
+		// ==template== {{ if not .Optimize }}
+		p.incChoiceAltCnt(ch, choiceNoMatch)
+		// {{ end }} ==template==
+		return nil, false
+	}
 	// ==template== {{ if not .Optimize }}
+	p.incChoiceAltCnt(ch, bestAlt)
+	// {{ end }} ==template==
+	// ==template== {{ if or .GlobalState (not .Optimize) }}
+	p.restoreState(bestState)
+	// {{ end }} ==template==
+	if bestSnapshot != nil {
+		p.restoreGlobalStore(bestSnapshot)
+	}
+	p.restore(bestPt)
+	return bestVal, true
+}
+
+func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseLabeledExpr"))
 	}
@@ -1235,12 +2486,13 @@ func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
 	// {{ end }} ==template==
+	p.step()
 	if lit.invert {
 		p.maxFailInvertExpected = !p.maxFailInvertExpected
 	}
@@ -1248,10 +2500,7 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
-		if lit.ignoreCase {
-			cur = unicode.ToLower(cur)
-		}
-		if cur != want {
+		if cur != want && !(lit.ignoreCase && equalFold(cur, want)) {
 			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
@@ -1266,8 +2515,124 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
+// equalFold returns true if the runes r and s are equal under simple
+// Unicode case folding, as in strings.EqualFold.
+func equalFold(r, s rune) bool {
+	if r == s {
+		return true
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ==template== {{ if .Regexp }}
+func (p *parser) parseRegexpMatcher(re *regexpMatcher) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
+	if p.debug {
+		defer p.out(p.in("parseRegexpMatcher"))
+	}
+
+	// {{ end }} ==template==
+	p.step()
+	// the regular expression is matched against the rest of the input,
+	// so the input of a rune reader is read entirely.
+	for p.rr != nil {
+		p.readRune()
+	}
+	loc := re.re.FindIndex(p.data[p.pt.offset:])
+	if loc == nil {
+		p.failAt(false, p.pt.position, re.want)
+		return nil, false
+	}
+
+	start := p.pt
+	for end := start.offset + loc[1]; p.pt.offset < end; {
+		p.read()
+	}
+	p.failAt(true, start.position, re.want)
+	return p.sliceFrom(start), true
+}
+
+// {{ end }} ==template==
+// ==template== {{ if .Keyword }}
+func (p *parser) parseKeywordMatcher(kw *keywordMatcher) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
+	if p.debug {
+		defer p.out(p.in("parseKeywordMatcher"))
+	}
+
+	// {{ end }} ==template==
+	p.step()
+	start := p.pt
+	for p.pt.w > 0 && isKeywordRune(p.pt.rn, p.pt.offset > start.offset) {
+		p.read()
+	}
+	keywords := kw.keywords
+	if keywords == nil {
+		keywords = p.keywords
+	}
+	if p.pt.offset == start.offset || !keywords[string(p.sliceFrom(start))] {
+		p.failAt(false, start.position, kw.want)
+		p.restore(start)
+		return nil, false
+	}
+	p.failAt(true, start.position, kw.want)
+	return p.sliceFrom(start), true
+}
+
+// isKeywordRune returns true if rn may be part of the identifier matched
+// by a keyword matcher, after the first rune of the identifier if inner
+// is true.
+func isKeywordRune(rn rune, inner bool) bool {
+	return rn == '_' || unicode.IsLetter(rn) || (inner && unicode.IsDigit(rn))
+}
+
+// {{ end }} ==template==
+// ==template== {{ if .Lookbehind }}
+func (p *parser) parseLookbehindExpr(lb *lookbehindExpr) (interface{}, bool) {
+	// ==template== {{ if not (or .Optimize .Lib) }}
+	if p.debug {
+		defer p.out(p.in("parseLookbehindExpr"))
+	}
+
+	// {{ end }} ==template==
+	pt := p.pt
+	start := pt.offset
+	for i := 0; i < lb.n; i++ {
+		if start == 0 {
+			// not enough input before the current position
+			return nil, lb.negate
+		}
+		_, w := utf8.DecodeLastRune(p.data[:start])
+		start -= w
+	}
+
+	// The expression is matched from its start with the line and column of
+	// the current position, which are not known there. Its failures are
+	// not reported, as they are before the current position, and neither
+	// are the invalid encodings, which were reported when first read.
+	failPos, allowInvalidUTF8 := p.maxFailPos, p.allowInvalidUTF8
+	p.maxFailPos.offset = len(p.data) + 1
+	p.allowInvalidUTF8 = true
+	rn, w := utf8.DecodeRune(p.data[start:])
+	p.pt = savepoint{position: position{line: pt.line, col: pt.col, offset: start}, rn: rn, w: w}
+	p.pushV()
+	_, ok := p.parseExpr(lb.expr)
+	p.popV()
+	ok = ok && p.pt.offset == pt.offset
+	p.maxFailPos, p.allowInvalidUTF8 = failPos, allowInvalidUTF8
+	p.pt = pt
+
+	return nil, ok != lb.negate
+}
+
+// {{ end }} ==template==
 func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseNotCodeExpr"))
 	}
@@ -1289,7 +2654,7 @@ func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseNotExpr"))
 	}
@@ -1313,7 +2678,7 @@ func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseOneOrMoreExpr"))
 	}
@@ -1341,7 +2706,7 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
 	}
@@ -1356,7 +2721,7 @@ func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseRuleRefExpr " + ref.name))
 	}
@@ -1375,7 +2740,7 @@ func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseSeqExpr"))
 	}
@@ -1386,14 +2751,17 @@ func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
 	state := p.cloneState()
 	// {{ end }} ==template==
 	var vals []interface{}
-	if seq.vals != nil {
-		vals = seq.vals
+	if seq.valsIx > 0 {
+		vals = p.reusedVals(seq)
 	} else {
 		vals = make([]interface{}, len(seq.exprs))
 	}
 	for i, expr := range seq.exprs {
 		val, ok := p.parseExpr(expr)
 		if !ok {
+			if p.partialResult && i > 0 {
+				p.setPartial(vals[:i])
+			}
 			// ==template== {{ if or .GlobalState (not .Optimize) }}
 			p.restoreState(state)
 			// {{ end }} ==template==
@@ -1405,10 +2773,24 @@ func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
 	return vals, true
 }
 
+// reusedVals returns the values buffer of the parser that is reused for
+// the values of the sequence seq, which is allocated on first use.
+func (p *parser) reusedVals(seq *seqExpr) []interface{} {
+	for len(p.seqVals) < seq.valsIx {
+		p.seqVals = append(p.seqVals, nil)
+	}
+	vals := p.seqVals[seq.valsIx-1]
+	if vals == nil {
+		vals = make([]interface{}, len(seq.exprs))
+		p.seqVals[seq.valsIx-1] = vals
+	}
+	return vals
+}
+
 // ==template== {{ if or .GlobalState (not .Optimize) }}
 
 func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseStateCodeExpr"))
 	}
@@ -1424,13 +2806,18 @@ func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
 // {{ end }} ==template==
 
 func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseThrowExpr"))
 	}
 
 	// {{ end }} ==template==
 
+	p.recoveryFailPos = append(p.recoveryFailPos, p.maxFailPos)
+	defer func() {
+		p.recoveryFailPos = p.recoveryFailPos[:len(p.recoveryFailPos)-1]
+	}()
+
 	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
 		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
 			if val, ok := p.parseExpr(recoverExpr); ok {
@@ -1443,7 +2830,7 @@ func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseZeroOrMoreExpr"))
 	}
@@ -1467,7 +2854,7 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
 }
 
 func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (interface{}, bool) {
-	// ==template== {{ if not .Optimize }}
+	// ==template== {{ if not (or .Optimize .Lib) }}
 	if p.debug {
 		defer p.out(p.in("parseZeroOrOneExpr"))
 	}
@@ -19,6 +19,25 @@ func compareGrammars(t *testing.T, src string, exp, got *ast.Grammar) bool {
 		}
 	}
 
+	if (exp.Package != nil) != (got.Package != nil) {
+		t.Errorf("%q: want Package? %t, got %t", src, exp.Package != nil, got.Package != nil)
+		return false
+	}
+	if exp.Package != nil && exp.Package.Val != got.Package.Val {
+		t.Errorf("%q: want Package %q, got %q", src, exp.Package.Val, got.Package.Val)
+		return false
+	}
+	if len(exp.GoImports) != len(got.GoImports) {
+		t.Errorf("%q: want %d Go imports, got %d", src, len(exp.GoImports), len(got.GoImports))
+		return false
+	}
+	for i, imp := range got.GoImports {
+		if exp.GoImports[i].Val != imp.Val {
+			t.Errorf("%q: want Go import %q, got %q", src, exp.GoImports[i].Val, imp.Val)
+			return false
+		}
+	}
+
 	rn, rm := len(exp.Rules), len(got.Rules)
 	if rn != rm {
 		t.Errorf("%q: want %d rules, got %d", src, rn, rm)
@@ -31,6 +50,23 @@ func compareGrammars(t *testing.T, src string, exp, got *ast.Grammar) bool {
 		}
 	}
 
+	cn, cm := len(exp.Charsets), len(got.Charsets)
+	if cn != cm {
+		t.Errorf("%q: want %d charsets, got %d", src, cn, cm)
+		return false
+	}
+
+	for i, cs := range got.Charsets {
+		prefix := src + ": " + exp.Charsets[i].Name.Val
+		if exp.Charsets[i].Name.Val != cs.Name.Val {
+			t.Errorf("%q: want charset name %q, got %q", prefix, exp.Charsets[i].Name.Val, cs.Name.Val)
+			return false
+		}
+		if !compareExpr(t, prefix, 0, exp.Charsets[i].Class, cs.Class) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -49,6 +85,14 @@ func compareRule(t *testing.T, prefix string, exp, got *ast.Rule) bool {
 			return false
 		}
 	}
+	if exp.Longest != got.Longest {
+		t.Errorf("%q: want Longest %t, got %t", prefix, exp.Longest, got.Longest)
+		return false
+	}
+	if exp.ResultType != got.ResultType {
+		t.Errorf("%q: want ResultType %q, got %q", prefix, exp.ResultType, got.ResultType)
+		return false
+	}
 	return compareExpr(t, prefix, 0, exp.Expr, got.Expr)
 }
 
@@ -110,6 +154,16 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			t.Errorf("%q: want value %q, got %q", ixPrefix, exp.Val, got.Val)
 		}
 
+	case *ast.BOLMatcher:
+		got, ok := got.(*ast.BOLMatcher)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Val != got.Val {
+			t.Errorf("%q: want value %q, got %q", ixPrefix, exp.Val, got.Val)
+		}
+
 	case *ast.CharClassMatcher:
 		got, ok := got.(*ast.CharClassMatcher)
 		if !ok {
@@ -161,12 +215,28 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+		ne, ng = len(exp.Charsets), len(got.Charsets)
+		if ne != ng {
+			t.Errorf("%q: want %d Charsets, got %d", ixPrefix, ne, ng)
+			return false
+		}
+		for i, s := range exp.Charsets {
+			if s != got.Charsets[i] {
+				t.Errorf("%q: want Charsets[%d] %q, got %q", ixPrefix, i, s, got.Charsets[i])
+				return false
+			}
+		}
+
 	case *ast.ChoiceExpr:
 		got, ok := got.(*ast.ChoiceExpr)
 		if !ok {
 			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
 			return false
 		}
+		if exp.Longest != got.Longest {
+			t.Errorf("%q: want Longest %t, got %t", ixPrefix, exp.Longest, got.Longest)
+			return false
+		}
 		ne, ng := len(exp.Alternatives), len(got.Alternatives)
 		if ne != ng {
 			t.Errorf("%q: want %d Alternatives, got %d", ixPrefix, ne, ng)
@@ -230,6 +300,18 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+	case *ast.LookbehindExpr:
+		got, ok := got.(*ast.LookbehindExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Negate != got.Negate {
+			t.Errorf("%q: want Negate %t, got %t", ixPrefix, exp.Negate, got.Negate)
+			return false
+		}
+		return compareExpr(t, prefix, ix+1, exp.Expr, got.Expr)
+
 	case *ast.NotExpr:
 		got, ok := got.(*ast.NotExpr)
 		if !ok {
@@ -246,6 +328,35 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 		}
 		return compareExpr(t, prefix, ix+1, exp.Expr, got.Expr)
 
+	case *ast.RegexpMatcher:
+		got, ok := got.(*ast.RegexpMatcher)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Expr != got.Expr {
+			t.Errorf("%q: want regexp %q, got %q", ixPrefix, exp.Expr, got.Expr)
+			return false
+		}
+
+	case *ast.KeywordMatcher:
+		got, ok := got.(*ast.KeywordMatcher)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		ne, ng := len(exp.Keywords), len(got.Keywords)
+		if ne != ng {
+			t.Errorf("%q: want %d Keywords, got %d (%q)", ixPrefix, ne, ng, got.Keywords)
+			return false
+		}
+		for i, kw := range exp.Keywords {
+			if kw != got.Keywords[i] {
+				t.Errorf("%q: want Keywords[%d] %q, got %q", ixPrefix, i, kw, got.Keywords[i])
+				return false
+			}
+		}
+
 	case *ast.RuleRefExpr:
 		got, ok := got.(*ast.RuleRefExpr)
 		if !ok {
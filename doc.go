@@ -29,12 +29,60 @@ by default.
 
 The following options can be specified:
 
+	-allow-duplicate-labels : boolean, if set, the labels that shadow a
+	previous label in the scope of the same code block (e.g. "a:X a:Y") are
+	reported as warnings instead of errors, and only the last binding of such
+	labels is visible in the code block (default: false).
+
+	-bench TEST_FILE : string, if set, a test file with a benchmark for each rule
+	of the grammar that has a sample input is written to TEST_FILE. The sample
+	input of a rule is read from the testdata directory of TEST_FILE, in a file
+	named after the rule with a .txt extension (e.g. testdata/Expr.txt). The
+	benchmark of a rule, e.g. BenchmarkExpr, parses its sample input with the
+	rule as entrypoint, using a ParserPool to measure the steady-state cost.
+	The rules without a sample input are skipped (default: "").
+
 	-cache : cache parser results to avoid exponential parsing time in
 	pathological cases. Can make the parsing slower for typical
 	cases and uses more memory (default: false).
 
+	-coverage : boolean, if set, the generated parser records the number of
+	times each rule is executed, in a map[string]int stored in the parser
+	state under the "pigeon.coverage" key (see ast.InstrumentForCoverage and
+	ast.CoverageReport) (default: false).
+
+	-cst : boolean, if set, the actions are not run and the generated parser
+	returns a concrete syntax tree of *CSTNode instead of the values of the
+	actions, e.g. to implement a formatter (default: false).
+
 	-debug : boolean, print debugging info to stdout (default: false).
 
+	-extract-duplicates=N : int, if set, the sub-expressions that appear at
+	least N times in the grammar are moved to new rules, named _dup1, _dup2,
+	etc., that are referenced from each occurrence, after the optimizations of
+	-optimize-grammar if it is set. This shrinks the generated parser and lets
+	the occurrences share the memoized results. The sub-expressions that
+	contain an action, a label or a code block are never moved (see
+	ast.ExtractDuplicates) (default: 0, disabled).
+
+	-iface : boolean, if set, a Parser interface that declares the Parse*
+	functions as methods, and a DefaultParser type that implements it by
+	calling them, are generated, so that the code that uses the parser may
+	depend on the interface and stub the parsing in its tests (see below,
+	section "Using the generated parser") (default: false).
+
+	-lib : boolean, if set, the parser is generated to be embedded as a library:
+	the Debug option and the code that prints debugging information while parsing
+	are not generated. As for every generated parser, the imports are trimmed to
+	the ones used by the generated code (default: false).
+
+	-lint : boolean, if set, all the static checks are run on the grammar
+	instead of generating the parser (undefined, unused and left recursive
+	rules, duplicate rules and labels, repetitions of expressions that may
+	match the empty input, unreachable alternatives, etc.). The diagnostics
+	are printed to stdout sorted by position, with their severity, and the
+	command exits with a non-zero code if an error is found (default: false).
+
 	-nolint: add '// nolint: ...' comments for generated parser to suppress
 	warnings by gometalinter (https://github.com/alecthomas/gometalinter).
 
@@ -62,6 +110,8 @@ The following options can be specified:
 		* resolve nested sequences expression
 		* resolve sequence expressions with only one element
 		* combine character class matcher and literal matcher, where possible
+		* remove the and predicates of matchers that are guaranteed by the
+		  expression that follows them, e.g. &[0-9] [0-9]
 	The resulting grammar is usually more memory consuming, but faster for parsing.
 	The optimization of the grammar is done in multiple rounds (optimize until no
 	more optimizations have applied). This process takes some time, depending on the
@@ -77,9 +127,37 @@ The following options can be specified:
 	-x : boolean, if set, do not build the parser, just parse the input grammar
 	(default: false).
 
+	-package=NAME : string, name of the package of the generated parser. It
+	overrides the @package directive of the grammar and the package clause of
+	the initializer (default: "").
+
 	-receiver-name=NAME : string, name of the receiver variable for the generated
 	code blocks. Non-initializer code blocks in the grammar end up as methods on the
 	*current type, and this option sets the name of the receiver (default: c).
+	The name must be a valid Go identifier and must not be used as a label in
+	an expression that has a code block, otherwise generating the parser fails.
+
+	-spacing=RULE : string, name of a rule (e.g. a whitespace rule such as
+	`_ = [ \t\r\n]*`) that is automatically matched after each literal,
+	character class and any matcher of the grammar, and after each reference to
+	a lexical rule, so that the grammar doesn't have to reference it explicitly
+	between the tokens. The rule is not inserted in itself and in the rules
+	listed in -lexical-rules. Whitespace that precedes the first token is not
+	skipped, so the start rule should begin with a reference to RULE. Note that
+	this adds elements to the values of the sequence expressions, so code
+	blocks should access the values using labels (default: none).
+
+	-tokens=RULE : string, name of a rule of the shape Token*, optionally
+	followed by the end of input (e.g. !. or a reference to an EOF rule). If
+	set, a Tokens function is generated to parse the input lazily, one match of
+	the repeated rule (Token) at a time (see below, section "Using the generated
+	parser"). If the grammar is optimized with -optimize-grammar, the repeated
+	rule must be listed in -alternate-entrypoints so that it is kept as is
+	(default: none).
+
+	-visitor : boolean, if set, a ResultNode interface and a WalkResult function
+	are generated to traverse the values returned by the code blocks (see below,
+	section "Using the generated parser") (default: false).
 
 	-alternate-entrypoints=RULE[,RULE...] : string, comma-separated list of rule names
 	that may be used as alternate entrypoints for the parser, in addition to the
@@ -89,6 +167,16 @@ The following options can be specified:
 	necessary if the -optimize-parser flag is set, as some rules may be optimized
 	out of the resulting parser.
 
+	-lexical-rules=RULE[,RULE...] : string, comma-separated list of rule names
+	where whitespace is significant, e.g. the rules that match a number or an
+	identifier. The -spacing rule is not inserted in those rules, but after
+	the references to them instead (default: none).
+
+	-memoize-rules=RULE[,RULE...] : string, comma-separated list of rule names
+	whose results are memoized when the parser is run with the MemoizeRules
+	option (default: the rules referenced from at least two places in the
+	grammar).
+
 If the code blocks in the grammar (see below, section "Code block") are golint-
 and go vet-compliant, then the resulting generated code will also be golint-
 and go vet-compliant.
@@ -126,6 +214,12 @@ a string literal used in error messages instead of the rule identifier - can
 be specified after the rule identifier. E.g.:
 	RuleA "friendly name" = 'a'+ // RuleA is one or more lowercase 'a's
 
+When a rule with a display name fails to match at its starting position,
+the "no match found" error lists its display name in the expected values
+instead of the values expected by its expression, e.g. "expected: friendly
+name" instead of "expected: "a"". The values expected past the starting
+position of the rule are still reported as such.
+
 The rule definition operator can be any one of those:
 	=, <-, ← (U+2190), ⟵ (U+27F5)
 
@@ -147,6 +241,33 @@ of expressions. For example, in this rule, "<=" would never be used because
 the "<" expression comes first:
 	BadChoiceExpr = "<" / "<="
 
+A rule can be annotated with @longest, before its identifier, to use the
+longest match instead: all the alternatives of the choice expressions of
+the rule are tested, and the one that consumes the most input is used. In
+case of a tie, the first one that matches is used. E.g.:
+	@longest
+	GoodChoiceExpr = "<" / "<="
+
+The annotation only applies to the choice expressions of the rule itself,
+not to those of the rules that it references. It defeats the short-circuit
+of the choice expressions, so all the alternatives are always tested, and
+the code blocks of the alternatives that are not used are still executed.
+It should be reserved to the rules that need it, e.g. to the tokens of a
+language with overlapping operators.
+
+The Go type of the value of a rule can be declared by a "// type: T"
+comment at the end of the rule, on the same line as its last expression.
+E.g.:
+	Expr = lhs:Term op:Op rhs:Term { return &BinaryExpr{lhs, op, rhs}, nil } // type: *BinaryExpr
+The declared types are exported by the generated parser in the
+ResultTypes map, and pigeon checks that they are consistent: the return
+type of the action of a rule, or of the actions and rule references of its
+choice expression, must be assignable to the declared type, and the rules
+referenced by the choice expression of the entry rule must declare
+compatible types. As the code is not type-checked, two types are only
+reported as incompatible if they are known, different and cannot be
+interfaces (e.g. string and *BinaryExpr).
+
 Sequence expression
 
 The sequence expression is a list of expressions that must all match in
@@ -173,7 +294,7 @@ For terminals (character and string literals, character classes and
 the any matcher), the value is []byte. E.g.:
     Rule = label:'a' { // label is []byte }
 
-For predicates (& and !), the value is always nil. E.g.:
+For predicates (&, !, <& and <!), the value is always nil. E.g.:
 	Rule = label:&'a' { // label is nil }
 
 For a sequence, the value is a slice of empty interfaces, one for each
@@ -222,6 +343,22 @@ defined in its scope. E.g.:
 		return true, nil
 	}
 
+Lookbehind expressions
+
+An expression prefixed with "<&" is the "lookbehind" predicate expression:
+it is considered a match if the following expression matches the input
+that ends at the current position, and "<!" is its negation. Like the and
+and not predicates, it does not consume any input. E.g.:
+	Sep = <!'\\' ','        // matches a comma not preceded by a backslash
+	End = [a-z]+ <&( 's' / 'é' ) // matches a word that ends with "s" or "é"
+
+As the input is scanned backward, the expression must match a fixed number
+of characters: it may only be made of literals, character classes and the
+any matcher, in sequences and in choices whose alternatives match the same
+number of characters. Otherwise the generation of the parser fails. The
+lookbehind is not a match (and its negation is) if there are not enough
+characters before the current position.
+
 Repeating expressions
 
 An expression followed by "*", "?" or "+" is a match if the expression
@@ -241,6 +378,13 @@ The literal may be followed by a lowercase "i" (outside the ending quote)
 to indicate that the match is case-insensitive. E.g.:
 	LiteralMatch = "Awesome\n"i // matches "awesome" followed by a newline
 
+The case-insensitive match compares each character of the input with the
+character of the literal using the simple Unicode case folding, as
+strings.EqualFold does, e.g. "straße"i matches "STRAßE" and "STRAẞE" and
+"k"i matches the Kelvin sign "K". The one-to-many folds are not supported,
+so "straße"i doesn't match "STRASSE", and neither are the language-specific
+foldings, so "İstanbul"i doesn't match "ISTANBUL" nor "istanbul".
+
 Character class matcher
 
 A character class matcher tries to match the input against a class of characters
@@ -261,6 +405,22 @@ is inverted (it is a match if the input does not match the character class
 matcher). E.g.:
 	NotAZ = [^a-z]i
 
+A character class can be given a name, to be reused in the rules, with
+the @charset keyword in place of a rule:
+	@charset IdentStart = [a-zA-Z_]
+	@charset IdentChar = [<IdentStart>0-9]
+
+A named character set can be referenced by its name in place of an
+expression, e.g. "Ident = IdentStart IdentChar*", and inside the square
+brackets of another character class with the "<Name>" notation, e.g.
+"[<IdentChar>-]". The references are expanded when the parser is generated,
+and an error is reported for a reference to an undefined or inverted named
+set, or for a cycle of references. A character set cannot have the same
+name as a rule. Note that a "<" followed by an identifier and a ">" inside
+square brackets is always a reference, so to match those characters the
+"<" must not be immediately followed by the identifier, e.g. "[a><]"
+instead of "[<a>]".
+
 Any matcher
 
 The any matcher is represented by the dot ".". It matches any character
@@ -269,6 +429,54 @@ the end of file". E.g.:
 	AnyChar = . // match a single character
 	EOF = !.
 
+Beginning of line matcher
+
+The beginning of line matcher is represented by the caret "^". It matches
+without consuming any input at the start of the input and right after a
+newline character "\n", which is useful for line-oriented and
+indentation-sensitive grammars. E.g.:
+	Heading = ^ '#' [^\n]*
+
+Regexp matcher
+
+The regexp matcher is an escape hatch for character-heavy rules. It is
+represented by "@regex" followed by a string literal that holds a regular
+expression in the syntax of the regexp package, usually a raw string
+literal. The regular expression is compiled once, in a package-level
+variable of the generated parser, and matched at the current position of
+the input. Its value is the matched text, as a []byte. E.g.:
+	Number = @regex `[0-9]+(\.[0-9]+)?` {
+		return strconv.ParseFloat(string(c.text), 64)
+	}
+
+Note that the regexp matcher bypasses the backtracking semantics of the
+PEG: the regular expression is matched at once, with the leftmost-first
+semantics of the regexp package, and the parser never tries a shorter
+match, e.g. @regex "a*" "a" never matches. When parsing from an
+io.RuneReader, the rest of the input is read entirely by the first regexp
+matcher.
+
+Keyword matcher
+
+The keyword matcher matches the identifier at the current position if it
+is one of a set of keywords, so that the keywords of a language are
+written once instead of as one alternative per keyword. The identifier is
+the longest sequence of letters, decimal digits and underscores that does
+not start with a digit, so that a keyword never matches the start of a
+longer identifier, e.g. "if" does not match "iffy". It is represented by
+"@keyword", optionally followed by a parenthesized, comma-separated list
+of string literals, the keywords, with no space before the opening
+parenthesis. Its value is the matched keyword, as a []byte. E.g.:
+	Control = kw:@keyword("if", "else", "for", "return") {
+		return string(kw.([]byte)), nil
+	}
+
+Without a list of keywords, the keywords are provided when parsing, with
+the Keywords option of the generated parser, which takes a
+map[string]bool. The keywords listed in the grammar are looked up in a
+map generated as a package-level variable. In both cases, a failed match
+is reported as a single "keyword" in the expected list of the errors.
+
 Code block
 
 Code blocks can be added to generate custom Go code. There are three kinds
@@ -290,6 +498,21 @@ clause). E.g.:
 		}
 	}
 
+The package clause and the imports needed by the code blocks can also be
+declared by directives at the very start of the grammar, before the
+initializer, so that the grammar alone determines the generated file:
+the @package directive sets the name of the package of the generated
+parser, replacing the package clause of the initializer if there is one,
+and each @import_go directive adds an import of its path after the package
+clause. The -package flag overrides the @package directive. E.g.:
+	@package calc
+	@import_go "math/big"
+
+	Number = [0-9]+ {
+		n, _ := new(big.Int).SetString(string(c.text), 10)
+		return n, nil
+	}
+
 Action code blocks are code blocks declared after an expression in a rule.
 Those code blocks are turned into a method on the "*current" type in the
 generated source code. The method receives any labeled expression's value
@@ -314,6 +537,17 @@ parser will return. E.g.:
 		return true, nil
 	}
 
+An action or predicate code block that cannot fail may end with a bare
+expression instead of a return statement, in which case the expression is
+returned with a nil error. The explicit return statements are still
+supported, and a code block may mix both forms. E.g.:
+	RuleA = "A"+ {
+		string(c.text)
+	}
+	RuleAB = [ab]i+ &{
+		len(c.text) < 10
+	}
+
 State change code blocks are code blocks starting with "#". In contrast to
 action and predicate code blocks, state change code blocks are allowed to
 modify values in the global "state" store (see below).
@@ -332,9 +566,9 @@ E.g:
         }
         return nil
     }
-The "*current" type is a struct that provides four useful fields that can be
+The "*current" type is a struct that provides five useful fields that can be
 accessed in action, state change, and predicate code blocks: "pos", "text",
-"state" and "globalStore".
+"ruleName", "state" and "globalStore".
 
 The "pos" field indicates the current position of the parser in the source
 input. It is itself a struct with three fields: "line", "col" and "offset".
@@ -342,7 +576,69 @@ Line is a 1-based line number, col is a 1-based column number that counts
 runes from the start of the line, and offset is a 0-based byte offset.
 
 The "text" field is the slice of bytes of the current match. It is empty
-in a predicate code block.
+in a predicate code block. It is not a copy of the input, but its capacity
+is limited to the match so that appending to it does not modify the input.
+In action code blocks, the "TextUnsafe" method returns the same bytes as a
+subslice of the input, even if the text field was modified: it must be
+treated as read-only and not retained after the parse if the input may be
+modified or reused.
+
+In action code blocks, the "Span" method returns the span of the current
+match, from its start position to the position right after its last rune.
+
+The "Warn" and "Diag" methods record a diagnostic, e.g. to report that a
+deprecated syntax is used, without failing the parse: Warn records a
+diagnostic of severity "warning" and Diag a diagnostic of any severity,
+with the start position of the current match. E.g.:
+	Decl = "var" _ name:Ident {
+		c.Warn("var is deprecated, use let")
+		return name, nil
+	}
+The diagnostics are collected by the Diagnostics option, even if the
+parsing fails, and they are not removed if the parser backtracks after
+the code block ran.
+
+The "Mark" method returns a checkpoint of the current position of the
+parser and of the "state" store, and the "Restore" method moves the parser
+back to a mark, e.g. to implement a custom lookahead in an action code
+block: the parser continues from the position of the mark once the code
+block returns. The state is only restored by state change code blocks, as
+the changes made by the other code blocks are always rolled back. Restore
+returns an error if the mark was not created by the same parser, and the
+code blocks of a rule are not run again when its memoized result is used.
+E.g.:
+	Peek = #{
+		c.state["start"] = c.Mark()
+		return nil
+	} w:Word {
+		return w, c.Restore(c.state["start"].(Mark))
+	}
+
+For off-side rule grammars (e.g. Python- or YAML-like languages), the
+"LineIndent" method returns the width of the leading whitespace of the line
+of the current position of the parser, and the parser keeps a stack of
+indentation levels in the "state" store. The "PushIndent" and "PopIndent"
+methods update the stack from state change code blocks, and the
+"IndentLevel", "SameIndent" and "MoreIndented" methods compare the
+indentation of the current line with the top of the stack in predicate code
+blocks. E.g.:
+	Block = &{ return c.MoreIndented(), nil } #{
+		c.PushIndent(c.LineIndent())
+		return nil
+	} Statement+ #{
+		c.PopIndent()
+		return nil
+	}
+	Statement = &{ return c.SameIndent(), nil } _ Simple NL
+See the test/offside grammar for a complete example. As the indentation
+stack is kept in the "state" store, the methods that use it are only
+generated with the -optimize-parser flag if the grammar has state change
+code blocks.
+
+The "ruleName" field is the name of the innermost rule being parsed, e.g. to
+build context-sensitive error messages. Note that when the grammar is
+optimized (see -optimize-grammar), rules that are inlined in other rules
+report the name of the rule they are inlined in.
 
 The "state" field is a global store, with backtrack support, of type
 "map[string]interface{}". The values in the store are tied to the parser's
@@ -374,7 +670,10 @@ which allows to store arbitrary values, which are available in action and
 predicate code blocks for read as well as write access.
 It is important to notice, that the global store is completely independent from
 the backtrack mechanism of PEG and is therefore not set back to its old state
-during backtrack.
+during backtrack, unless the key is listed in the TransactionalStore option:
+the values of those keys are saved before each alternative of a choice
+expression is tried and restored if the alternative fails to match. The
+"Cloner" interface is used to copy the values that implement it.
 The initialization of the global store may be achieved by using the GlobalStore
 function (http://godoc.org/github.com/mna/pigeon/test/predicates#GlobalStore).
 Be aware, that all keys starting with "_pigeon" are reserved for internal use
@@ -433,21 +732,105 @@ as a package with public functions to parse input text. The exported API is:
 	- Parse(string, []byte, ...Option) (interface{}, error)
 	- ParseFile(string, ...Option) (interface{}, error)
 	- ParseReader(string, io.Reader, ...Option) (interface{}, error)
+	- ParseRuneReader(string, io.RuneReader, ...Option) (interface{}, error)
 	- AllowInvalidUTF8(bool) Option
 	- Debug(bool) Option
+	- Diagnostics(*[]Diagnostic) Option
 	- Entrypoint(string) Option
 	- GlobalStore(string, interface{}) Option
+	- MaxDepth(int) Option
 	- MaxExpressions(uint64) Option
+	- MaxSteps(int) Option
 	- Memoize(bool) Option
+	- MemoizeRules(bool) Option
+	- OnProgress(func(offset, total int)) Option
+	- PartialResult(bool) Option
+	- ProgressInterval(int) Option
 	- Recover(bool) Option
 	- Statistics(*Stats) Option
+	- StripBOM(bool) Option
+	- TransactionalStore(...string) Option
+	- ValidEntrypoints() []string
+	- (*Stats) SortedRules() []ChoiceStat
+	- Span struct { Start, End position }
+	- LimitError struct { Kind LimitKind }
+	- LimitKind int (LimitExpressions, LimitDepth, LimitSteps)
+	- Diagnostic struct { Pos position; Severity, Msg string }
+	- ParserPool struct
+	- (*ParserPool) Parse(string, []byte, ...Option) (interface{}, error)
+
+It also exports a string constant named after each rule of the grammar,
+e.g. RuleExpr for the rule Expr, so that a misspelled entrypoint such as
+Entrypoint(RuleEpxr) is caught at compile time, and ValidEntrypoints
+returns the names of all the rules that may be used as entrypoint. The
+generation fails if the initializer already declares such a constant.
+
+If a rule declares the type of its value, the exported API also includes:
+	- ResultTypes map[string]string
+
+If the grammar has a keyword matcher, the exported API also includes:
+	- Keywords(map[string]bool) Option
+
+If the -lib flag is set, the Debug option is not part of the exported API.
+
+If the -visitor flag is set, the exported API also includes:
+	- ResultNode interface { Children() []interface{} }
+	- WalkResult(interface{}, func(interface{}) bool)
+
+WalkResult traverses the tree of values returned by the code blocks, e.g.
+to collect all the nodes of a given type. The values that have children
+must implement ResultNode, and the []interface{} values (returned by the
+sequence and repetition expressions) are traversed as well.
+
+If the -iface flag is set, the exported API also includes:
+	- Parser interface { Parse, ParseFile, ParseReader, ParseRuneReader }
+	- DefaultParser struct
+
+The methods of Parser have the signatures of the Parse* functions, and
+those of DefaultParser call them. The code that uses the parser may then
+depend on a Parser, set to a DefaultParser{}, and use a stub instead in
+its tests, e.g. to return a fixed result or error without any input.
+
+If the -tokens flag is set, the exported API also includes:
+	- Tokens(string, []byte, ...Option) (func() (interface{}, error), error)
+
+Tokens parses the input lazily, one match of the repeated rule at a time:
+each call of the returned function parses the next match and returns its
+value, or io.EOF at the end of the input, so that a stream of tokens can be
+processed without keeping all of them in memory. The parser, including its
+memoized results, is kept between the calls. The first error ends the
+iteration and is returned by all the subsequent calls, including the
+"no match found" error if the rest of the input doesn't match.
+
+If the -cst flag is set, the exported API also includes:
+	- CSTNode struct { Rule string; Span Span; Children []*CSTNode }
+	- (*CSTNode) Text() string
+
+The actions are then not run, and the Parse* functions return the
+*CSTNode of the match of the start rule. There is a node for each match of
+a rule, and its children are the nodes of the rules matched in it,
+interleaved with leaves, which have an empty Rule, for the text between
+them, e.g. the literals and the whitespace matched by the rule itself. The
+input matched by a node, including the whitespace and the comments, is
+the concatenation of the text of its leaves, i.e. of its descendants that
+have no children, so that it can be reconstructed byte for byte. The
+predicates and the state change code blocks are still run. Note that the
+rules inlined by the -optimize-grammar flag have no node.
 
 See the godoc page of the generated parser for the test/predicates grammar
 for an example documentation page of the exported API:
 http://godoc.org/github.com/mna/pigeon/test/predicates.
 
 Like the grammar used to generate the parser, the input text must be
-UTF-8-encoded Unicode.
+UTF-8-encoded Unicode. Input in any other encoding can be parsed with
+ParseRuneReader, which consumes the runes produced by an io.RuneReader
+(e.g. a decoder) directly. Offsets in positions are then byte offsets in
+the UTF-8 encoding of the runes read.
+
+A UTF-8 byte order mark at the start of the input (e.g. in files saved by
+some Windows editors) is parsed like any other rune by default, so that
+the offsets match the raw bytes. With the StripBOM option, it is skipped
+and the positions are relative to the content that follows it.
 
 The start rule of the parser is the first rule in the PEG grammar used
 to generate the parser. A call to any of the Parse* functions returns
@@ -459,6 +842,37 @@ but for simple grammars it may evaluate the result immediately, such as in
 the examples/calculator example. There are no constraints imposed on the
 author of the grammar, it can return whatever is needed.
 
+When the parsing fails, the returned value is nil unless the PartialResult
+option is set. In that case, the values of the elements that matched in
+the sequence of the start rule that went the furthest are returned along
+with the error, e.g. to show what was parsed so far in an editor. This is
+best-effort: the partial result is nil if the parsing fails before any
+element of such a sequence matched.
+
+When large inputs are parsed, the OnProgress option sets a function that
+the parser calls as it advances through the input, e.g. to render a
+progress bar. Since the parser backtracks, it is called with the furthest
+offset reached rather than the current one, along with the size of the
+input (-1 while unknown with ParseRuneReader). It is called each time that
+offset advances by the number of bytes set by the ProgressInterval option,
+4096 by default, and once more when the parsing completes.
+
+When a lot of small inputs are parsed, a ParserPool can be used instead of
+the Parse function to reuse the memory allocated by the parsers. Each call
+to its Parse method starts with a parser that is fully reset, so that no
+state, error or memoized result is shared between parses.
+
+The Parse* functions are safe for concurrent use: all the mutable state of
+a parse, including the statistics, the debug flag and the values buffers
+reused by the sequences of an optimized grammar, is held by the parser
+created for that parse. The package-level variables of the generated
+parser (the grammar, the compiled regular expressions and the sentinel
+errors) are never modified once initialized, and the pool of the state
+snapshots is a sync.Pool. Note that the values passed to the options, e.g.
+the *Stats of the Statistics option, the slice of the Diagnostics option or
+the values of the GlobalStore option, must not be shared between concurrent
+parses.
+
 Error reporting
 
 When the parser returns a non-nil error, the error is always of type errList,
@@ -471,6 +885,18 @@ So if a code block returns some well-known error like:
 		return nil, io.EOF
 	}
 
+The *parserError type also has a "Span" method that returns the span of
+the input text that caused the error, e.g. to render carets under the
+offending text. Columns are counted in runes, not bytes.
+
+Both the errList and the *parserError types have a "FurthestPos" method
+that returns the furthest position where the parser failed to match, e.g.
+to place a squiggle where the parsing gave up. For the errors that occur
+while a labeled failure is recovered, e.g. the errors returned by the
+state code blocks of the recovery expression, it is the furthest position
+when the failure was thrown, so that each recovered failure can be
+located even if the parsing eventually succeeds.
+
 The original error can be accessed this way:
 	_, err := ParseFile("some_file")
 	if err != nil {
@@ -483,6 +909,17 @@ The original error can be accessed this way:
 		}
 	}
 
+As errList implements the As method and *parserError the Unwrap method,
+errors.As can be used too on the error returned by the Parse* functions.
+In particular, when the parsing is stopped because a limit set by the
+MaxExpressions, MaxDepth or MaxSteps options is exceeded, the original
+error is a *LimitError, whose Kind tells which limit it is, so that such
+a resource-limit abort can be told apart from a syntax error:
+	var le *LimitError
+	if errors.As(err, &le) && le.Kind == LimitDepth {
+		// ...
+	}
+
 By defaut the parser will continue after an error is returned and will
 cumulate all errors found during parsing. If the grammar reaches a point
 where it shouldn't continue, a panic statement can be used to terminate
@@ -134,6 +134,26 @@ func TestChoiceAltStatistics(t *testing.T) {
 	}
 }
 
+func TestStatisticsSortedRules(t *testing.T) {
+	stats := Stats{}
+	_, err := Parse("TestStatisticsSortedRules", []byte(`{ "string": "string", "number": 123 }`), Statistics(&stats, "no match"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChoiceStat{
+		{Choice: "Integer 60:11", Alternative: "2", Count: 1},
+		{Choice: "Integer 60:11", Alternative: "no match", Count: 1},
+		{Choice: "String 64:16", Alternative: "1", Count: 18},
+		{Choice: "String 64:16", Alternative: "no match", Count: 3},
+		{Choice: "Value 21:15", Alternative: "1", Count: 1},
+		{Choice: "Value 21:15", Alternative: "3", Count: 1},
+		{Choice: "Value 21:15", Alternative: "4", Count: 1},
+	}
+	if got := stats.SortedRules(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
 func TestZeroZero(t *testing.T) {
 	_, err := Parse(`fuzz`, []byte(`00`))
 	if err == nil {
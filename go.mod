@@ -3,6 +3,8 @@ module github.com/mna/pigeon
 go 1.14
 
 require (
+	github.com/golang/protobuf v1.4.3
 	golang.org/x/tools v0.0.0-20200822203824-307de81be3f4
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+	google.golang.org/protobuf v1.25.0
 )
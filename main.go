@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -39,23 +40,39 @@ func main() {
 
 	// define command-line flags
 	var (
+		allowDupLabelsFlag     = fs.Bool("allow-duplicate-labels", false, "report duplicate labels in a code block's scope as warnings instead of errors")
+		benchFlag              = fs.String("bench", "", "write benchmarks of the rules that have a sample input in the testdata directory to this test file")
 		cacheFlag              = fs.Bool("cache", false, "cache parsing results")
+		coverageFlag           = fs.Bool("coverage", false, "instrument the parser to record the number of executions of each rule")
+		cstFlag                = fs.Bool("cst", false, "generate a parser that returns a concrete syntax tree instead of running the actions")
 		dbgFlag                = fs.Bool("debug", false, "set debug mode")
+		extractDupsFlag        = fs.Int("extract-duplicates", 0, "move the sub-expressions repeated at least this many times to shared rules")
 		shortHelpFlag          = fs.Bool("h", false, "show help page")
 		longHelpFlag           = fs.Bool("help", false, "show help page")
+		ifaceFlag              = fs.Bool("iface", false, "generate a Parser interface of the Parse functions and a DefaultParser implementing it")
+		libFlag                = fs.Bool("lib", false, "generate the parser without the Debug option and debugging code")
+		lintFlag               = fs.Bool("lint", false, "run all the static checks on the grammar and print the diagnostics")
 		nolint                 = fs.Bool("nolint", false, "add '// nolint: ...' comments to suppress warnings by gometalinter")
 		noRecoverFlag          = fs.Bool("no-recover", false, "do not recover from panic")
 		outputFlag             = fs.String("o", "", "output file, defaults to stdout")
 		optimizeBasicLatinFlag = fs.Bool("optimize-basic-latin", false, "generate optimized parser for Unicode Basic Latin character sets")
 		optimizeGrammar        = fs.Bool("optimize-grammar", false, "optimize the given grammar (EXPERIMENTAL FEATURE)")
 		optimizeParserFlag     = fs.Bool("optimize-parser", false, "generate optimized parser without Debug and Memoize options")
+		packageFlag            = fs.String("package", "", "name of the package of the generated parser, overrides the @package directive")
 		recvrNmFlag            = fs.String("receiver-name", "c", "receiver name for the generated methods")
+		spacingFlag            = fs.String("spacing", "", "name of the rule to match automatically after each token")
+		tokensFlag             = fs.String("tokens", "", "name of a rule of the shape Token* to generate a Tokens function that returns its matches one at a time")
+		visitorFlag            = fs.Bool("visitor", false, "generate a WalkResult function to traverse the parse result")
 		_                      = fs.String("whatever", "", "a useless example command")
 		noBuildFlag            = fs.Bool("x", false, "do not build, only parse")
 
 		altEntrypointsFlag ruleNamesFlag
+		lexicalRulesFlag   ruleNamesFlag
+		memoizeRulesFlag   ruleNamesFlag
 	)
 	fs.Var(&altEntrypointsFlag, "alternate-entrypoints", "comma-separated list of rule names that may be used as entrypoints")
+	fs.Var(&lexicalRulesFlag, "lexical-rules", "comma-separated list of rule names where the -spacing rule is not inserted")
+	fs.Var(&memoizeRulesFlag, "memoize-rules", "comma-separated list of rule names memoized by the MemoizeRules option")
 
 	fs.Usage = usage
 	err := fs.Parse(os.Args[1:])
@@ -99,8 +116,33 @@ func main() {
 		exit(3)
 	}
 
-	// validate alternate entrypoints
 	grammar := g.(*ast.Grammar)
+	if err := ast.ExpandCharsets(grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error(s):\n", err)
+		exit(10)
+	}
+	if *lintFlag {
+		exit(lint(nm, grammar))
+	}
+
+	// validate rules
+	if err := ast.CheckDuplicateRules(grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error(s):\n", err)
+		exit(10)
+	}
+	if err := ast.CheckUndefinedRules(grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error(s):\n", err)
+		exit(10)
+	}
+	if err := ast.CheckDuplicateLabels(grammar); err != nil {
+		if !*allowDupLabelsFlag {
+			fmt.Fprintln(os.Stderr, "grammar error(s):\n", err)
+			exit(10)
+		}
+		fmt.Fprintln(os.Stderr, "grammar warning(s):\n", err)
+	}
+
+	// validate alternate entrypoints
 	rules := make(map[string]struct{}, len(grammar.Rules))
 	for _, rule := range grammar.Rules {
 		rules[rule.Name.Val] = struct{}{}
@@ -116,10 +158,45 @@ func main() {
 		}
 	}
 
+	// validate the memoized rules
+	memoRules := make(map[string]bool, len(memoizeRulesFlag))
+	for _, name := range memoizeRulesFlag {
+		if name == "" {
+			continue
+		}
+
+		if _, ok := rules[name]; !ok {
+			fmt.Fprintf(os.Stderr, "argument error:\nunknown rule name %s used as memoized rule\n", name)
+			exit(9)
+		}
+		memoRules[name] = true
+	}
+
+	// insert the spacing rule, before the grammar is optimized
+	if *spacingFlag != "" {
+		var lexical []string
+		for _, nm := range lexicalRulesFlag {
+			if nm != "" {
+				lexical = append(lexical, nm)
+			}
+		}
+		if err := ast.InsertSpacing(grammar, *spacingFlag, lexical...); err != nil {
+			fmt.Fprintf(os.Stderr, "argument error:\n%v used with -spacing or -lexical-rules\n", err)
+			exit(9)
+		}
+	}
+
+	if *coverageFlag {
+		ast.InstrumentForCoverage(grammar)
+	}
+
 	if !*noBuildFlag {
 		if *optimizeGrammar {
 			ast.Optimize(grammar, altEntrypointsFlag...)
 		}
+		if *extractDupsFlag > 0 {
+			ast.ExtractDuplicates(grammar, *extractDupsFlag)
+		}
 
 		// generate parser
 		out := output(*outputFlag)
@@ -137,7 +214,18 @@ func main() {
 		optimizeParser := builder.Optimize(*optimizeParserFlag)
 		basicLatinOptimize := builder.BasicLatinLookupTable(*optimizeBasicLatinFlag)
 		nolintOpt := builder.Nolint(*nolint)
-		if err := builder.BuildParser(outBuf, grammar, curNmOpt, optimizeParser, basicLatinOptimize, nolintOpt); err != nil {
+		visitorOpt := builder.Visitor(*visitorFlag)
+		dupLabelsOpt := builder.AllowDuplicateLabels(*allowDupLabelsFlag)
+		libOpt := builder.Lib(*libFlag)
+		tokensOpt := builder.Tokens(*tokensFlag)
+		cstOpt := builder.CST(*cstFlag)
+		pkgOpt := builder.Package(*packageFlag)
+		ifaceOpt := builder.Iface(*ifaceFlag)
+		memoizeOpt := builder.MemoizeIf(nil)
+		if len(memoRules) > 0 {
+			memoizeOpt = builder.MemoizeIf(func(ruleName string) bool { return memoRules[ruleName] })
+		}
+		if err := builder.BuildParser(outBuf, grammar, curNmOpt, optimizeParser, basicLatinOptimize, nolintOpt, visitorOpt, dupLabelsOpt, libOpt, tokensOpt, cstOpt, pkgOpt, ifaceOpt, memoizeOpt); err != nil {
 			fmt.Fprintln(os.Stderr, "build error: ", err)
 			exit(5)
 		}
@@ -164,6 +252,31 @@ func main() {
 			fmt.Fprintln(os.Stderr, "write error: ", err)
 			exit(7)
 		}
+
+		if *benchFlag != "" {
+			writeBenchmarks(*benchFlag, grammar)
+		}
+	}
+}
+
+// writeBenchmarks writes the benchmarks of the rules of the grammar that
+// have a sample input in the testdata directory of the test file filename.
+func writeBenchmarks(filename string, grammar *ast.Grammar) {
+	var buf bytes.Buffer
+	dir := filepath.Join(filepath.Dir(filename), "testdata")
+	if err := builder.BuildBenchmarks(&buf, grammar, dir); err != nil {
+		fmt.Fprintln(os.Stderr, "build error: ", err)
+		exit(5)
+	}
+
+	out := output(filename)
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		fmt.Fprintln(os.Stderr, "write error: ", err)
+		exit(7)
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "close file error:\n", err)
+		exit(8)
 	}
 }
 
@@ -176,14 +289,47 @@ generated parser to stdout. If GRAMMAR_FILE is specified, the
 grammar is read from this file instead. If the -o flag is set,
 the generated code is written to this file instead.
 
+	-allow-duplicate-labels
+		report the labels that shadow a previous label in the scope
+		of the same code block as warnings instead of errors. Only
+		the last binding of such labels is visible in the code block.
+	-bench TEST_FILE
+		write a benchmark for each rule that has a sample input in
+		the testdata directory of TEST_FILE, named after the rule with
+		a .txt extension, to TEST_FILE.
 	-cache
 		cache parser results to avoid exponential parsing time in
 		pathological cases. Can make the parsing slower for typical
 		cases and uses more memory.
+	-coverage
+		instrument the generated parser to record the number of times
+		each rule is executed in the parser state, under the key
+		ast.CoverageKey.
+	-cst
+		generate a parser that does not run the actions and returns a
+		concrete syntax tree of *CSTNode instead, with a node for each
+		match of a rule, from which the input can be reconstructed.
 	-debug
 		output debugging information while parsing the grammar.
+	-extract-duplicates N
+		move the sub-expressions that appear at least N times in the
+		grammar to shared rules named _dup1, _dup2, etc., referenced
+		from each occurrence. The sub-expressions that contain an
+		action, a label or a code block are never moved.
 	-h -help
 		display this help message.
+	-iface
+		generate a Parser interface that declares the Parse* functions
+		as methods, and a DefaultParser type that implements it, so
+		that the parsing may be stubbed in the tests of its users.
+	-lib
+		generate a parser meant to be embedded as a library, without
+		the Debug option and the code that prints debugging information.
+		The imports are trimmed to the ones used by the generated code.
+	-lint
+		run all the static checks on the grammar instead of generating
+		the parser, and print the diagnostics sorted by position. Exits
+		with a non-zero code if an error is found.
 	-nolint
 		add '// nolint: ...' comments for generated parser to suppress
 		warnings by gometalinter (https://github.com/alecthomas/gometalinter).
@@ -199,19 +345,57 @@ the generated code is written to this file instead.
 	-optimize-parser
 		generate optimized parser without Debug and Memoize options and
 		with some other optimizations applied.
+	-package NAME
+		use NAME as the name of the package of the generated parser,
+		instead of the one set by the @package directive of the grammar
+		or by the package clause of its initializer.
 	-receiver-name NAME
 		use NAME as for the receiver name of the generated methods
-		for the grammar's code blocks. Defaults to "c".
+		for the grammar's code blocks. Defaults to "c". NAME must be
+		a valid Go identifier.
+	-spacing RULE
+		automatically match RULE (e.g. a whitespace rule) after each
+		literal, character class and any matcher, and after each
+		reference to a lexical rule, except in RULE itself and in
+		the lexical rules.
+	-tokens RULE
+		generate a Tokens function that parses the input lazily, one
+		match at a time of the rule repeated by RULE, which must be of
+		the shape Token*, optionally followed by the end of input.
+	-visitor
+		generate a ResultNode interface and a WalkResult function to
+		traverse the values returned by the grammar's code blocks.
 	-x
 		do not generate the parser, only parse the grammar.
  	-alternate-entrypoints RULE[,RULE...]
 		comma-separated list of rule names that may be used as alternate
 		entrypoints for the parser, in addition to the first rule in the
 		grammar.
+	-lexical-rules RULE[,RULE...]
+		comma-separated list of rule names where whitespace is significant,
+		so that the -spacing rule is not inserted in them.
+	-memoize-rules RULE[,RULE...]
+		comma-separated list of rule names whose results are memoized
+		when the parser is run with the MemoizeRules option. Defaults
+		to the rules referenced from at least two places in the grammar.
 
 See https://godoc.org/github.com/mna/pigeon for more information.
 `
 
+// lint prints the issues reported by all the lint rules on the grammar
+// read from the file nm, and returns the exit code: 10 if an error was
+// reported, 0 otherwise.
+func lint(nm string, grammar *ast.Grammar) int {
+	code := 0
+	for _, li := range ast.Lint(grammar, ast.DefaultLintRules()...) {
+		fmt.Printf("%s:%s\n", nm, li)
+		if li.Severity == ast.LintError {
+			code = 10
+		}
+	}
+	return code
+}
+
 // usage prints the help page of the command-line tool.
 func usage() {
 	fmt.Printf(usagePage, os.Args[0])
@@ -27,6 +27,8 @@ func TestMain(t *testing.T) {
 		{args: "-h", code: 0},          // help
 		{args: "FILE1 FILE2", code: 1}, // want only 1 non-flag arg
 		{args: "-x", code: 3},          // stdin: no match found
+		{args: "-lint grammar/pigeon.peg", code: 0},
+		{args: "-lint testdata/lint.peg", code: 10},
 	}
 
 	for _, tc := range cases {
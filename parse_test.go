@@ -1,21 +1,25 @@
 package main
 
 import (
+	"io"
+	"strings"
 	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/mna/pigeon/ast"
 )
 
 var invalidParseCases = map[string]string{
-	"":           `file:1:1 (0): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
+	"":           `file:1:1 (0): no match found, expected: "/*", "//", "@charset", "@import_go", "@longest", "@package", "\n", "{", [ \t\r] or [\pL_]`,
 	"a":          `file:1:2 (1): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
 	"abc":        `file:1:4 (3): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
-	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
-	`a = +`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	`a = *`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	`a = ?`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	"a ←":        `file:1:4 (5): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	"a ← b\nb ←": `file:2:4 (13): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
+	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "@charset", "@import_go", "@longest", "@package", "\n", "{", [ \t\r] or [\pL_]`,
+	`a = +`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "<!", "<&", "@keyword", "@regex", "[", "\"", "\n", "^", "` + "`" + `", [ \t\r] or [\pL_]`,
+	`a = *`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "<!", "<&", "@keyword", "@regex", "[", "\"", "\n", "^", "` + "`" + `", [ \t\r] or [\pL_]`,
+	`a = ?`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "<!", "<&", "@keyword", "@regex", "[", "\"", "\n", "^", "` + "`" + `", [ \t\r] or [\pL_]`,
+	"a ←":        `file:1:4 (5): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "<!", "<&", "@keyword", "@regex", "[", "\"", "\n", "^", "` + "`" + `", [ \t\r] or [\pL_]`,
+	"a ← b\nb ←": `file:2:4 (13): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "<!", "<&", "@keyword", "@regex", "[", "\"", "\n", "^", "` + "`" + `", [ \t\r] or [\pL_]`,
 	"a ← nil:b":  "file:1:5 (6): rule Identifier: identifier is a reserved word",
 	"\xfe":       "file:1:1 (0): invalid encoding",
 	"{}{}":       `file:1:3 (2): no match found, expected: "/*", "//", ";", "\n", [ \t\r] or EOF`,
@@ -53,6 +57,17 @@ var invalidParseCases = map[string]string{
 	`a = [\p{W]`: `file:1:8 (7): rule UnicodeClassEscape: Unicode class not terminated
 file:1:5 (4): rule CharClassMatcher: character class not terminated`,
 
+	// invalid directives
+	"@package a\n@package b\nc = d": "file:1:1 (0): rule Grammar: 2:10 (20): duplicate @package directive",
+	"@import_go \"\"\na = b":        "file:1:1 (0): rule ImportGoDirective: invalid import path",
+	"@import_go 'a'\na = b":         "file:1:1 (0): rule ImportGoDirective: invalid import path",
+
+	// invalid regular expression
+	`a = @regex "("`: "file:1:5 (4): rule RegexpMatcher: invalid regular expression: error parsing regexp: missing closing ): `(`",
+
+	// invalid keyword
+	`a = @keyword("if", "1x")`: "file:1:5 (4): rule KeywordMatcher: invalid keyword \"1x\": not an identifier",
+
 	// invalid escapes
 	`a ← [\pA]`:    "file:1:8 (9): rule UnicodeClassEscape: invalid Unicode class escape",
 	`a ← [\p{WW}]`: "file:1:8 (9): rule UnicodeClassEscape: invalid Unicode class escape",
@@ -201,6 +216,14 @@ var validParseCases = map[string]*ast.Grammar{
 			},
 		},
 	},
+	"a = \"\U0001F600\"": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: ast.NewLitMatcher(ast.Pos{}, "\U0001F600"),
+			},
+		},
+	},
 	"a ← b\nc=d \n e <- f \ng\u27f5h": {
 		Rules: []*ast.Rule{
 			{
@@ -240,6 +263,97 @@ var validParseCases = map[string]*ast.Grammar{
 			},
 		},
 	},
+	"@longest a = b / c\nd = e / f": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.ChoiceExpr{
+					Alternatives: []ast.Expression{
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "c")},
+					},
+					Longest: true,
+				},
+				Longest: true,
+			},
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "d"),
+				Expr: &ast.ChoiceExpr{
+					Alternatives: []ast.Expression{
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "e")},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "f")},
+					},
+				},
+			},
+		},
+	},
+	"a = @regex `[a-z]+` b": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.RegexpMatcher{Expr: "[a-z]+"},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					},
+				},
+			},
+		},
+	},
+	`a = <& "b" <!( [c] / . ) d`: {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.LookbehindExpr{Expr: ast.NewLitMatcher(ast.Pos{}, "b")},
+						&ast.LookbehindExpr{
+							Expr: &ast.ChoiceExpr{
+								Alternatives: []ast.Expression{
+									ast.NewCharClassMatcher(ast.Pos{}, "[c]"),
+									ast.NewAnyMatcher(ast.Pos{}, "."),
+								},
+							},
+							Negate: true,
+						},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "d")},
+					},
+				},
+			},
+		},
+	},
+	"a = @keyword b @keyword( \"if\", \"else\",\n`for`, )": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.KeywordMatcher{},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+						&ast.KeywordMatcher{Keywords: []string{"if", "else", "for"}},
+					},
+				},
+			},
+		},
+	},
+	"@charset a = [<b>]\nc = [<a>-]\n@charset b = [b]": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "c"),
+				Expr: &ast.CharClassMatcher{Chars: []rune{'-'}, Charsets: []string{"a"}},
+			},
+		},
+		Charsets: []*ast.Charset{
+			{
+				Name:  ast.NewIdentifier(ast.Pos{}, "a"),
+				Class: &ast.CharClassMatcher{Charsets: []string{"b"}},
+			},
+			{
+				Name:  ast.NewIdentifier(ast.Pos{}, "b"),
+				Class: &ast.CharClassMatcher{Chars: []rune{'b'}},
+			},
+		},
+	},
 	"a\n<-\nb": {
 		Rules: []*ast.Rule{
 			{
@@ -420,6 +534,46 @@ var validParseCases = map[string]*ast.Grammar{
 			},
 		},
 	},
+	"@package foo\n@import_go \"a/b\"\n@import_go `c`\n{\npackage bar\n}\na = b": {
+		Package: ast.NewIdentifier(ast.Pos{}, "foo"),
+		GoImports: []*ast.StringLit{
+			ast.NewStringLit(ast.Pos{}, `"a/b"`),
+			ast.NewStringLit(ast.Pos{}, "`c`"),
+		},
+		Init: ast.NewCodeBlock(ast.Pos{}, "{\npackage bar\n}"),
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+			},
+		},
+	},
+	`a = ^ "#"`: {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						ast.NewBOLMatcher(ast.Pos{}, "^"),
+						ast.NewLitMatcher(ast.Pos{}, "#"),
+					},
+				},
+			},
+		},
+	},
+	"a = b // type:  *ast.Node \nb = 'b' // not a type\n": {
+		Rules: []*ast.Rule{
+			{
+				Name:       ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr:       &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+				ResultType: "*ast.Node",
+			},
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "b"),
+				Expr: ast.NewLitMatcher(ast.Pos{}, "b"),
+			},
+		},
+	},
 }
 
 func TestValidParseCases(t *testing.T) {
@@ -443,3 +597,51 @@ again:
 		goto again
 	}
 }
+
+// utf16RuneReader is an io.RuneReader that decodes UTF-16 input.
+type utf16RuneReader []uint16
+
+func (r *utf16RuneReader) ReadRune() (rune, int, error) {
+	if len(*r) == 0 {
+		return 0, 0, io.EOF
+	}
+	n := 1
+	if utf16.IsSurrogate(rune((*r)[0])) && len(*r) > 1 {
+		n = 2
+	}
+	rn := utf16.Decode((*r)[:n])[0]
+	*r = (*r)[n:]
+	return rn, n * 2, nil
+}
+
+func TestParseRuneReader(t *testing.T) {
+	for tc, exp := range invalidParseCases {
+		if !utf8.ValidString(tc) {
+			// the rune reader is responsible for the decoding
+			continue
+		}
+		_, err := ParseRuneReader("file", strings.NewReader(tc))
+		if err == nil {
+			t.Errorf("%q: want error, got none", tc)
+			continue
+		}
+		if err.Error() != exp {
+			t.Errorf("%q: want \n%s\n, got \n%s\n", tc, exp, err)
+		}
+	}
+
+	for tc, exp := range validParseCases {
+		r := utf16RuneReader(utf16.Encode([]rune(tc)))
+		got, err := ParseRuneReader("", &r)
+		if err != nil {
+			t.Errorf("%q: got error %v", tc, err)
+			continue
+		}
+		gotg, ok := got.(*ast.Grammar)
+		if !ok {
+			t.Errorf("%q: want grammar type %T, got %T", tc, exp, got)
+			continue
+		}
+		compareGrammars(t, tc, exp, gotg)
+	}
+}
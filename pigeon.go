@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -37,18 +38,38 @@ var g = &grammar{
 						},
 						&labeledExpr{
 							pos:   position{line: 5, col: 14, offset: 33},
+							label: "directives",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 5, col: 25, offset: 44},
+								expr: &seqExpr{
+									pos: position{line: 5, col: 27, offset: 46},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 27, offset: 46},
+											name: "Directive",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 37, offset: 56},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 5, col: 43, offset: 62},
 							label: "initializer",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 5, col: 26, offset: 45},
+								pos: position{line: 5, col: 55, offset: 74},
 								expr: &seqExpr{
-									pos: position{line: 5, col: 28, offset: 47},
+									pos: position{line: 5, col: 57, offset: 76},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 28, offset: 47},
+											pos:  position{line: 5, col: 57, offset: 76},
 											name: "Initializer",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 40, offset: 59},
+											pos:  position{line: 5, col: 69, offset: 88},
 											name: "__",
 										},
 									},
@@ -56,19 +77,28 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 5, col: 46, offset: 65},
+							pos:   position{line: 5, col: 75, offset: 94},
 							label: "rules",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 5, col: 52, offset: 71},
+								pos: position{line: 5, col: 81, offset: 100},
 								expr: &seqExpr{
-									pos: position{line: 5, col: 54, offset: 73},
+									pos: position{line: 5, col: 83, offset: 102},
 									exprs: []interface{}{
-										&ruleRefExpr{
-											pos:  position{line: 5, col: 54, offset: 73},
-											name: "Rule",
+										&choiceExpr{
+											pos: position{line: 5, col: 85, offset: 104},
+											alternatives: []interface{}{
+												&ruleRefExpr{
+													pos:  position{line: 5, col: 85, offset: 104},
+													name: "Charset",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 5, col: 95, offset: 114},
+													name: "Rule",
+												},
+											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 59, offset: 78},
+											pos:  position{line: 5, col: 102, offset: 121},
 											name: "__",
 										},
 									},
@@ -76,32 +106,174 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 5, col: 65, offset: 84},
+							pos:  position{line: 5, col: 108, offset: 127},
 							name: "EOF",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "Directive",
+			pos:  position{line: 40, col: 1, offset: 1114},
+			expr: &choiceExpr{
+				pos: position{line: 40, col: 13, offset: 1128},
+				alternatives: []interface{}{
+					&ruleRefExpr{
+						pos:  position{line: 40, col: 13, offset: 1128},
+						name: "PackageDirective",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 40, col: 32, offset: 1147},
+						name: "ImportGoDirective",
+					},
+				},
+			},
+		},
+		{
+			name: "PackageDirective",
+			pos:  position{line: 42, col: 1, offset: 1166},
+			expr: &actionExpr{
+				pos: position{line: 42, col: 20, offset: 1187},
+				run: (*parser).callonPackageDirective1,
+				expr: &seqExpr{
+					pos: position{line: 42, col: 20, offset: 1187},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 42, col: 20, offset: 1187},
+							val:        "@package",
+							ignoreCase: false,
+							want:       "\"@package\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 42, col: 31, offset: 1198},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 42, col: 34, offset: 1201},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 42, col: 39, offset: 1206},
+								name: "IdentifierName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 42, col: 54, offset: 1221},
+							name: "EOS",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ImportGoDirective",
+			pos:  position{line: 46, col: 1, offset: 1251},
+			expr: &actionExpr{
+				pos: position{line: 46, col: 21, offset: 1273},
+				run: (*parser).callonImportGoDirective1,
+				expr: &seqExpr{
+					pos: position{line: 46, col: 21, offset: 1273},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 46, col: 21, offset: 1273},
+							val:        "@import_go",
+							ignoreCase: false,
+							want:       "\"@import_go\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 46, col: 34, offset: 1286},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 46, col: 37, offset: 1289},
+							label: "path",
+							expr: &ruleRefExpr{
+								pos:  position{line: 46, col: 42, offset: 1294},
+								name: "StringLiteral",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 46, col: 56, offset: 1308},
+							name: "EOS",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Initializer",
-			pos:  position{line: 24, col: 1, offset: 525},
+			pos:  position{line: 56, col: 1, offset: 1557},
 			expr: &actionExpr{
-				pos: position{line: 24, col: 15, offset: 541},
+				pos: position{line: 56, col: 15, offset: 1573},
 				run: (*parser).callonInitializer1,
 				expr: &seqExpr{
-					pos: position{line: 24, col: 15, offset: 541},
+					pos: position{line: 56, col: 15, offset: 1573},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 24, col: 15, offset: 541},
+							pos:   position{line: 56, col: 15, offset: 1573},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 24, col: 20, offset: 546},
+								pos:  position{line: 56, col: 20, offset: 1578},
 								name: "CodeBlock",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 24, col: 30, offset: 556},
+							pos:  position{line: 56, col: 30, offset: 1588},
+							name: "EOS",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Charset",
+			pos:  position{line: 60, col: 1, offset: 1618},
+			expr: &actionExpr{
+				pos: position{line: 60, col: 11, offset: 1630},
+				run: (*parser).callonCharset1,
+				expr: &seqExpr{
+					pos: position{line: 60, col: 11, offset: 1630},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 60, col: 11, offset: 1630},
+							val:        "@charset",
+							ignoreCase: false,
+							want:       "\"@charset\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 60, col: 22, offset: 1641},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 60, col: 25, offset: 1644},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 60, col: 30, offset: 1649},
+								name: "IdentifierName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 60, col: 45, offset: 1664},
+							name: "__",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 60, col: 48, offset: 1667},
+							name: "RuleDefOp",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 60, col: 58, offset: 1677},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 60, col: 61, offset: 1680},
+							label: "class",
+							expr: &ruleRefExpr{
+								pos:  position{line: 60, col: 67, offset: 1686},
+								name: "CharClassMatcher",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 60, col: 84, offset: 1703},
 							name: "EOS",
 						},
 					},
@@ -110,39 +282,61 @@ var g = &grammar{
 		},
 		{
 			name: "Rule",
-			pos:  position{line: 28, col: 1, offset: 586},
+			pos:  position{line: 66, col: 1, offset: 1837},
 			expr: &actionExpr{
-				pos: position{line: 28, col: 8, offset: 595},
+				pos: position{line: 66, col: 8, offset: 1846},
 				run: (*parser).callonRule1,
 				expr: &seqExpr{
-					pos: position{line: 28, col: 8, offset: 595},
+					pos: position{line: 66, col: 8, offset: 1846},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 28, col: 8, offset: 595},
+							pos:   position{line: 66, col: 8, offset: 1846},
+							label: "longest",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 66, col: 16, offset: 1854},
+								expr: &seqExpr{
+									pos: position{line: 66, col: 18, offset: 1856},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 66, col: 18, offset: 1856},
+											val:        "@longest",
+											ignoreCase: false,
+											want:       "\"@longest\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 66, col: 29, offset: 1867},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 66, col: 35, offset: 1873},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 13, offset: 600},
+								pos:  position{line: 66, col: 40, offset: 1878},
 								name: "IdentifierName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 28, offset: 615},
+							pos:  position{line: 66, col: 55, offset: 1893},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 31, offset: 618},
+							pos:   position{line: 66, col: 58, offset: 1896},
 							label: "display",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 28, col: 39, offset: 626},
+								pos: position{line: 66, col: 66, offset: 1904},
 								expr: &seqExpr{
-									pos: position{line: 28, col: 41, offset: 628},
+									pos: position{line: 66, col: 68, offset: 1906},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 41, offset: 628},
+											pos:  position{line: 66, col: 68, offset: 1906},
 											name: "StringLiteral",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 55, offset: 642},
+											pos:  position{line: 66, col: 82, offset: 1920},
 											name: "__",
 										},
 									},
@@ -150,96 +344,192 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 61, offset: 648},
+							pos:  position{line: 66, col: 88, offset: 1926},
 							name: "RuleDefOp",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 71, offset: 658},
+							pos:  position{line: 66, col: 98, offset: 1936},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 74, offset: 661},
+							pos:   position{line: 66, col: 101, offset: 1939},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 79, offset: 666},
+								pos:  position{line: 66, col: 106, offset: 1944},
 								name: "Expression",
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 66, col: 117, offset: 1955},
+							label: "typ",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 66, col: 121, offset: 1959},
+								expr: &ruleRefExpr{
+									pos:  position{line: 66, col: 121, offset: 1959},
+									name: "ResultTypeComment",
+								},
+							},
+						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 90, offset: 677},
+							pos:  position{line: 66, col: 140, offset: 1978},
 							name: "EOS",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "ResultTypeComment",
+			pos:  position{line: 94, col: 1, offset: 2696},
+			expr: &actionExpr{
+				pos: position{line: 94, col: 21, offset: 2718},
+				run: (*parser).callonResultTypeComment1,
+				expr: &seqExpr{
+					pos: position{line: 94, col: 21, offset: 2718},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 94, col: 21, offset: 2718},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 94, col: 23, offset: 2720},
+							val:        "//",
+							ignoreCase: false,
+							want:       "\"//\"",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 94, col: 28, offset: 2725},
+							expr: &charClassMatcher{
+								pos:        position{line: 94, col: 28, offset: 2725},
+								val:        "[ \\t]",
+								chars:      []rune{' ', '\t'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 94, col: 35, offset: 2732},
+							val:        "type:",
+							ignoreCase: false,
+							want:       "\"type:\"",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 94, col: 43, offset: 2740},
+							expr: &charClassMatcher{
+								pos:        position{line: 94, col: 43, offset: 2740},
+								val:        "[ \\t]",
+								chars:      []rune{' ', '\t'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 94, col: 50, offset: 2747},
+							label: "typ",
+							expr: &ruleRefExpr{
+								pos:  position{line: 94, col: 54, offset: 2751},
+								name: "ResultType",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ResultType",
+			pos:  position{line: 98, col: 1, offset: 2787},
+			expr: &actionExpr{
+				pos: position{line: 98, col: 14, offset: 2802},
+				run: (*parser).callonResultType1,
+				expr: &oneOrMoreExpr{
+					pos: position{line: 98, col: 14, offset: 2802},
+					expr: &seqExpr{
+						pos: position{line: 98, col: 16, offset: 2804},
+						exprs: []interface{}{
+							&notExpr{
+								pos: position{line: 98, col: 16, offset: 2804},
+								expr: &ruleRefExpr{
+									pos:  position{line: 98, col: 17, offset: 2805},
+									name: "EOL",
+								},
+							},
+							&ruleRefExpr{
+								pos:  position{line: 98, col: 21, offset: 2809},
+								name: "SourceChar",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Expression",
-			pos:  position{line: 41, col: 1, offset: 961},
+			pos:  position{line: 102, col: 1, offset: 2878},
 			expr: &ruleRefExpr{
-				pos:  position{line: 41, col: 14, offset: 976},
+				pos:  position{line: 102, col: 14, offset: 2893},
 				name: "RecoveryExpr",
 			},
+			memoize: true,
 		},
 		{
 			name: "RecoveryExpr",
-			pos:  position{line: 43, col: 1, offset: 990},
+			pos:  position{line: 104, col: 1, offset: 2907},
 			expr: &actionExpr{
-				pos: position{line: 43, col: 16, offset: 1007},
+				pos: position{line: 104, col: 16, offset: 2924},
 				run: (*parser).callonRecoveryExpr1,
 				expr: &seqExpr{
-					pos: position{line: 43, col: 16, offset: 1007},
+					pos: position{line: 104, col: 16, offset: 2924},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 43, col: 16, offset: 1007},
+							pos:   position{line: 104, col: 16, offset: 2924},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 43, col: 21, offset: 1012},
+								pos:  position{line: 104, col: 21, offset: 2929},
 								name: "ChoiceExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 43, col: 32, offset: 1023},
+							pos:   position{line: 104, col: 32, offset: 2940},
 							label: "recoverExprs",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 43, col: 45, offset: 1036},
+								pos: position{line: 104, col: 45, offset: 2953},
 								expr: &seqExpr{
-									pos: position{line: 43, col: 47, offset: 1038},
+									pos: position{line: 104, col: 47, offset: 2955},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 47, offset: 1038},
+											pos:  position{line: 104, col: 47, offset: 2955},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 50, offset: 1041},
+											pos:        position{line: 104, col: 50, offset: 2958},
 											val:        "//{",
 											ignoreCase: false,
 											want:       "\"//{\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 56, offset: 1047},
+											pos:  position{line: 104, col: 56, offset: 2964},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 59, offset: 1050},
+											pos:  position{line: 104, col: 59, offset: 2967},
 											name: "Labels",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 66, offset: 1057},
+											pos:  position{line: 104, col: 66, offset: 2974},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 69, offset: 1060},
+											pos:        position{line: 104, col: 69, offset: 2977},
 											val:        "}",
 											ignoreCase: false,
 											want:       "\"}\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 73, offset: 1064},
+											pos:  position{line: 104, col: 73, offset: 2981},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 76, offset: 1067},
+											pos:  position{line: 104, col: 76, offset: 2984},
 											name: "ChoiceExpr",
 										},
 									},
@@ -252,45 +542,45 @@ var g = &grammar{
 		},
 		{
 			name: "Labels",
-			pos:  position{line: 58, col: 1, offset: 1481},
+			pos:  position{line: 119, col: 1, offset: 3398},
 			expr: &actionExpr{
-				pos: position{line: 58, col: 10, offset: 1492},
+				pos: position{line: 119, col: 10, offset: 3409},
 				run: (*parser).callonLabels1,
 				expr: &seqExpr{
-					pos: position{line: 58, col: 10, offset: 1492},
+					pos: position{line: 119, col: 10, offset: 3409},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 58, col: 10, offset: 1492},
+							pos:   position{line: 119, col: 10, offset: 3409},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 58, col: 16, offset: 1498},
+								pos:  position{line: 119, col: 16, offset: 3415},
 								name: "IdentifierName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 58, col: 31, offset: 1513},
+							pos:   position{line: 119, col: 31, offset: 3430},
 							label: "labels",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 58, col: 38, offset: 1520},
+								pos: position{line: 119, col: 38, offset: 3437},
 								expr: &seqExpr{
-									pos: position{line: 58, col: 40, offset: 1522},
+									pos: position{line: 119, col: 40, offset: 3439},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 40, offset: 1522},
+											pos:  position{line: 119, col: 40, offset: 3439},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 58, col: 43, offset: 1525},
+											pos:        position{line: 119, col: 43, offset: 3442},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 47, offset: 1529},
+											pos:  position{line: 119, col: 47, offset: 3446},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 50, offset: 1532},
+											pos:  position{line: 119, col: 50, offset: 3449},
 											name: "IdentifierName",
 										},
 									},
@@ -303,45 +593,45 @@ var g = &grammar{
 		},
 		{
 			name: "ChoiceExpr",
-			pos:  position{line: 67, col: 1, offset: 1861},
+			pos:  position{line: 128, col: 1, offset: 3778},
 			expr: &actionExpr{
-				pos: position{line: 67, col: 14, offset: 1876},
+				pos: position{line: 128, col: 14, offset: 3793},
 				run: (*parser).callonChoiceExpr1,
 				expr: &seqExpr{
-					pos: position{line: 67, col: 14, offset: 1876},
+					pos: position{line: 128, col: 14, offset: 3793},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 67, col: 14, offset: 1876},
+							pos:   position{line: 128, col: 14, offset: 3793},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 67, col: 20, offset: 1882},
+								pos:  position{line: 128, col: 20, offset: 3799},
 								name: "ActionExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 67, col: 31, offset: 1893},
+							pos:   position{line: 128, col: 31, offset: 3810},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 67, col: 36, offset: 1898},
+								pos: position{line: 128, col: 36, offset: 3815},
 								expr: &seqExpr{
-									pos: position{line: 67, col: 38, offset: 1900},
+									pos: position{line: 128, col: 38, offset: 3817},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 38, offset: 1900},
+											pos:  position{line: 128, col: 38, offset: 3817},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 67, col: 41, offset: 1903},
+											pos:        position{line: 128, col: 41, offset: 3820},
 											val:        "/",
 											ignoreCase: false,
 											want:       "\"/\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 45, offset: 1907},
+											pos:  position{line: 128, col: 45, offset: 3824},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 48, offset: 1910},
+											pos:  position{line: 128, col: 48, offset: 3827},
 											name: "ActionExpr",
 										},
 									},
@@ -351,38 +641,39 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "ActionExpr",
-			pos:  position{line: 82, col: 1, offset: 2315},
+			pos:  position{line: 143, col: 1, offset: 4232},
 			expr: &actionExpr{
-				pos: position{line: 82, col: 14, offset: 2330},
+				pos: position{line: 143, col: 14, offset: 4247},
 				run: (*parser).callonActionExpr1,
 				expr: &seqExpr{
-					pos: position{line: 82, col: 14, offset: 2330},
+					pos: position{line: 143, col: 14, offset: 4247},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 82, col: 14, offset: 2330},
+							pos:   position{line: 143, col: 14, offset: 4247},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 82, col: 19, offset: 2335},
+								pos:  position{line: 143, col: 19, offset: 4252},
 								name: "SeqExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 82, col: 27, offset: 2343},
+							pos:   position{line: 143, col: 27, offset: 4260},
 							label: "code",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 82, col: 32, offset: 2348},
+								pos: position{line: 143, col: 32, offset: 4265},
 								expr: &seqExpr{
-									pos: position{line: 82, col: 34, offset: 2350},
+									pos: position{line: 143, col: 34, offset: 4267},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 34, offset: 2350},
+											pos:  position{line: 143, col: 34, offset: 4267},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 37, offset: 2353},
+											pos:  position{line: 143, col: 37, offset: 4270},
 											name: "CodeBlock",
 										},
 									},
@@ -392,38 +683,39 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "SeqExpr",
-			pos:  position{line: 96, col: 1, offset: 2619},
+			pos:  position{line: 157, col: 1, offset: 4536},
 			expr: &actionExpr{
-				pos: position{line: 96, col: 11, offset: 2631},
+				pos: position{line: 157, col: 11, offset: 4548},
 				run: (*parser).callonSeqExpr1,
 				expr: &seqExpr{
-					pos: position{line: 96, col: 11, offset: 2631},
+					pos: position{line: 157, col: 11, offset: 4548},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 96, col: 11, offset: 2631},
+							pos:   position{line: 157, col: 11, offset: 4548},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 96, col: 17, offset: 2637},
+								pos:  position{line: 157, col: 17, offset: 4554},
 								name: "LabeledExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 96, col: 29, offset: 2649},
+							pos:   position{line: 157, col: 29, offset: 4566},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 96, col: 34, offset: 2654},
+								pos: position{line: 157, col: 34, offset: 4571},
 								expr: &seqExpr{
-									pos: position{line: 96, col: 36, offset: 2656},
+									pos: position{line: 157, col: 36, offset: 4573},
 									exprs: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 36, offset: 2656},
+											pos:  position{line: 157, col: 36, offset: 4573},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 39, offset: 2659},
+											pos:  position{line: 157, col: 39, offset: 4576},
 											name: "LabeledExpr",
 										},
 									},
@@ -436,43 +728,43 @@ var g = &grammar{
 		},
 		{
 			name: "LabeledExpr",
-			pos:  position{line: 109, col: 1, offset: 3010},
+			pos:  position{line: 170, col: 1, offset: 4927},
 			expr: &choiceExpr{
-				pos: position{line: 109, col: 15, offset: 3026},
+				pos: position{line: 170, col: 15, offset: 4943},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 109, col: 15, offset: 3026},
+						pos: position{line: 170, col: 15, offset: 4943},
 						run: (*parser).callonLabeledExpr2,
 						expr: &seqExpr{
-							pos: position{line: 109, col: 15, offset: 3026},
+							pos: position{line: 170, col: 15, offset: 4943},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 109, col: 15, offset: 3026},
+									pos:   position{line: 170, col: 15, offset: 4943},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 109, col: 21, offset: 3032},
+										pos:  position{line: 170, col: 21, offset: 4949},
 										name: "Identifier",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 109, col: 32, offset: 3043},
+									pos:  position{line: 170, col: 32, offset: 4960},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 109, col: 35, offset: 3046},
+									pos:        position{line: 170, col: 35, offset: 4963},
 									val:        ":",
 									ignoreCase: false,
 									want:       "\":\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 109, col: 39, offset: 3050},
+									pos:  position{line: 170, col: 39, offset: 4967},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 109, col: 42, offset: 3053},
+									pos:   position{line: 170, col: 42, offset: 4970},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 109, col: 47, offset: 3058},
+										pos:  position{line: 170, col: 47, offset: 4975},
 										name: "PrefixedExpr",
 									},
 								},
@@ -480,45 +772,46 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 115, col: 5, offset: 3231},
+						pos:  position{line: 176, col: 5, offset: 5148},
 						name: "PrefixedExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 115, col: 20, offset: 3246},
+						pos:  position{line: 176, col: 20, offset: 5163},
 						name: "ThrowExpr",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "PrefixedExpr",
-			pos:  position{line: 117, col: 1, offset: 3257},
+			pos:  position{line: 178, col: 1, offset: 5174},
 			expr: &choiceExpr{
-				pos: position{line: 117, col: 16, offset: 3274},
+				pos: position{line: 178, col: 16, offset: 5191},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 117, col: 16, offset: 3274},
+						pos: position{line: 178, col: 16, offset: 5191},
 						run: (*parser).callonPrefixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 117, col: 16, offset: 3274},
+							pos: position{line: 178, col: 16, offset: 5191},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 117, col: 16, offset: 3274},
+									pos:   position{line: 178, col: 16, offset: 5191},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 117, col: 19, offset: 3277},
+										pos:  position{line: 178, col: 19, offset: 5194},
 										name: "PrefixedOp",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 117, col: 30, offset: 3288},
+									pos:  position{line: 178, col: 30, offset: 5205},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 117, col: 33, offset: 3291},
+									pos:   position{line: 178, col: 33, offset: 5208},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 117, col: 38, offset: 3296},
+										pos:  position{line: 178, col: 38, offset: 5213},
 										name: "SuffixedExpr",
 									},
 								},
@@ -526,66 +819,79 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 128, col: 5, offset: 3578},
+						pos:  position{line: 194, col: 5, offset: 5646},
 						name: "SuffixedExpr",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "PrefixedOp",
-			pos:  position{line: 130, col: 1, offset: 3592},
+			pos:  position{line: 196, col: 1, offset: 5660},
 			expr: &actionExpr{
-				pos: position{line: 130, col: 14, offset: 3607},
+				pos: position{line: 196, col: 14, offset: 5675},
 				run: (*parser).callonPrefixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 130, col: 16, offset: 3609},
+					pos: position{line: 196, col: 16, offset: 5677},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 130, col: 16, offset: 3609},
+							pos:        position{line: 196, col: 16, offset: 5677},
 							val:        "&",
 							ignoreCase: false,
 							want:       "\"&\"",
 						},
 						&litMatcher{
-							pos:        position{line: 130, col: 22, offset: 3615},
+							pos:        position{line: 196, col: 22, offset: 5683},
 							val:        "!",
 							ignoreCase: false,
 							want:       "\"!\"",
 						},
+						&litMatcher{
+							pos:        position{line: 196, col: 28, offset: 5689},
+							val:        "<&",
+							ignoreCase: false,
+							want:       "\"<&\"",
+						},
+						&litMatcher{
+							pos:        position{line: 196, col: 35, offset: 5696},
+							val:        "<!",
+							ignoreCase: false,
+							want:       "\"<!\"",
+						},
 					},
 				},
 			},
 		},
 		{
 			name: "SuffixedExpr",
-			pos:  position{line: 134, col: 1, offset: 3657},
+			pos:  position{line: 200, col: 1, offset: 5739},
 			expr: &choiceExpr{
-				pos: position{line: 134, col: 16, offset: 3674},
+				pos: position{line: 200, col: 16, offset: 5756},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 134, col: 16, offset: 3674},
+						pos: position{line: 200, col: 16, offset: 5756},
 						run: (*parser).callonSuffixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 134, col: 16, offset: 3674},
+							pos: position{line: 200, col: 16, offset: 5756},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 134, col: 16, offset: 3674},
+									pos:   position{line: 200, col: 16, offset: 5756},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 134, col: 21, offset: 3679},
+										pos:  position{line: 200, col: 21, offset: 5761},
 										name: "PrimaryExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 134, col: 33, offset: 3691},
+									pos:  position{line: 200, col: 33, offset: 5773},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 134, col: 36, offset: 3694},
+									pos:   position{line: 200, col: 36, offset: 5776},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 134, col: 39, offset: 3697},
+										pos:  position{line: 200, col: 39, offset: 5779},
 										name: "SuffixedOp",
 									},
 								},
@@ -593,35 +899,36 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 153, col: 5, offset: 4227},
+						pos:  position{line: 219, col: 5, offset: 6309},
 						name: "PrimaryExpr",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "SuffixedOp",
-			pos:  position{line: 155, col: 1, offset: 4240},
+			pos:  position{line: 221, col: 1, offset: 6322},
 			expr: &actionExpr{
-				pos: position{line: 155, col: 14, offset: 4255},
+				pos: position{line: 221, col: 14, offset: 6337},
 				run: (*parser).callonSuffixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 155, col: 16, offset: 4257},
+					pos: position{line: 221, col: 16, offset: 6339},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 155, col: 16, offset: 4257},
+							pos:        position{line: 221, col: 16, offset: 6339},
 							val:        "?",
 							ignoreCase: false,
 							want:       "\"?\"",
 						},
 						&litMatcher{
-							pos:        position{line: 155, col: 22, offset: 4263},
+							pos:        position{line: 221, col: 22, offset: 6345},
 							val:        "*",
 							ignoreCase: false,
 							want:       "\"*\"",
 						},
 						&litMatcher{
-							pos:        position{line: 155, col: 28, offset: 4269},
+							pos:        position{line: 221, col: 28, offset: 6351},
 							val:        "+",
 							ignoreCase: false,
 							want:       "\"+\"",
@@ -632,60 +939,72 @@ var g = &grammar{
 		},
 		{
 			name: "PrimaryExpr",
-			pos:  position{line: 159, col: 1, offset: 4311},
+			pos:  position{line: 225, col: 1, offset: 6393},
 			expr: &choiceExpr{
-				pos: position{line: 159, col: 15, offset: 4327},
+				pos: position{line: 225, col: 15, offset: 6409},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 15, offset: 4327},
+						pos:  position{line: 225, col: 15, offset: 6409},
+						name: "RegexpMatcher",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 225, col: 31, offset: 6425},
+						name: "KeywordMatcher",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 225, col: 48, offset: 6442},
 						name: "LitMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 28, offset: 4340},
+						pos:  position{line: 225, col: 61, offset: 6455},
 						name: "CharClassMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 47, offset: 4359},
+						pos:  position{line: 225, col: 80, offset: 6474},
 						name: "AnyMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 60, offset: 4372},
+						pos:  position{line: 225, col: 93, offset: 6487},
+						name: "BOLMatcher",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 225, col: 106, offset: 6500},
 						name: "RuleRefExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 159, col: 74, offset: 4386},
+						pos:  position{line: 225, col: 120, offset: 6514},
 						name: "SemanticPredExpr",
 					},
 					&actionExpr{
-						pos: position{line: 159, col: 93, offset: 4405},
-						run: (*parser).callonPrimaryExpr7,
+						pos: position{line: 225, col: 139, offset: 6533},
+						run: (*parser).callonPrimaryExpr10,
 						expr: &seqExpr{
-							pos: position{line: 159, col: 93, offset: 4405},
+							pos: position{line: 225, col: 139, offset: 6533},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 159, col: 93, offset: 4405},
+									pos:        position{line: 225, col: 139, offset: 6533},
 									val:        "(",
 									ignoreCase: false,
 									want:       "\"(\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 159, col: 97, offset: 4409},
+									pos:  position{line: 225, col: 143, offset: 6537},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 159, col: 100, offset: 4412},
+									pos:   position{line: 225, col: 146, offset: 6540},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 159, col: 105, offset: 4417},
+										pos:  position{line: 225, col: 151, offset: 6545},
 										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 159, col: 116, offset: 4428},
+									pos:  position{line: 225, col: 162, offset: 6556},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 159, col: 119, offset: 4431},
+									pos:        position{line: 225, col: 165, offset: 6559},
 									val:        ")",
 									ignoreCase: false,
 									want:       "\")\"",
@@ -695,51 +1014,52 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "RuleRefExpr",
-			pos:  position{line: 162, col: 1, offset: 4460},
+			pos:  position{line: 228, col: 1, offset: 6588},
 			expr: &actionExpr{
-				pos: position{line: 162, col: 15, offset: 4476},
+				pos: position{line: 228, col: 15, offset: 6604},
 				run: (*parser).callonRuleRefExpr1,
 				expr: &seqExpr{
-					pos: position{line: 162, col: 15, offset: 4476},
+					pos: position{line: 228, col: 15, offset: 6604},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 162, col: 15, offset: 4476},
+							pos:   position{line: 228, col: 15, offset: 6604},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 162, col: 20, offset: 4481},
+								pos:  position{line: 228, col: 20, offset: 6609},
 								name: "IdentifierName",
 							},
 						},
 						&notExpr{
-							pos: position{line: 162, col: 35, offset: 4496},
+							pos: position{line: 228, col: 35, offset: 6624},
 							expr: &seqExpr{
-								pos: position{line: 162, col: 38, offset: 4499},
+								pos: position{line: 228, col: 38, offset: 6627},
 								exprs: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 162, col: 38, offset: 4499},
+										pos:  position{line: 228, col: 38, offset: 6627},
 										name: "__",
 									},
 									&zeroOrOneExpr{
-										pos: position{line: 162, col: 41, offset: 4502},
+										pos: position{line: 228, col: 41, offset: 6630},
 										expr: &seqExpr{
-											pos: position{line: 162, col: 43, offset: 4504},
+											pos: position{line: 228, col: 43, offset: 6632},
 											exprs: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 162, col: 43, offset: 4504},
+													pos:  position{line: 228, col: 43, offset: 6632},
 													name: "StringLiteral",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 162, col: 57, offset: 4518},
+													pos:  position{line: 228, col: 57, offset: 6646},
 													name: "__",
 												},
 											},
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 162, col: 63, offset: 4524},
+										pos:  position{line: 228, col: 63, offset: 6652},
 										name: "RuleDefOp",
 									},
 								},
@@ -751,30 +1071,30 @@ var g = &grammar{
 		},
 		{
 			name: "SemanticPredExpr",
-			pos:  position{line: 167, col: 1, offset: 4640},
+			pos:  position{line: 233, col: 1, offset: 6768},
 			expr: &actionExpr{
-				pos: position{line: 167, col: 20, offset: 4661},
+				pos: position{line: 233, col: 20, offset: 6789},
 				run: (*parser).callonSemanticPredExpr1,
 				expr: &seqExpr{
-					pos: position{line: 167, col: 20, offset: 4661},
+					pos: position{line: 233, col: 20, offset: 6789},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 167, col: 20, offset: 4661},
+							pos:   position{line: 233, col: 20, offset: 6789},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 167, col: 23, offset: 4664},
+								pos:  position{line: 233, col: 23, offset: 6792},
 								name: "SemanticPredOp",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 167, col: 38, offset: 4679},
+							pos:  position{line: 233, col: 38, offset: 6807},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 167, col: 41, offset: 4682},
+							pos:   position{line: 233, col: 41, offset: 6810},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 167, col: 46, offset: 4687},
+								pos:  position{line: 233, col: 46, offset: 6815},
 								name: "CodeBlock",
 							},
 						},
@@ -784,27 +1104,27 @@ var g = &grammar{
 		},
 		{
 			name: "SemanticPredOp",
-			pos:  position{line: 187, col: 1, offset: 5134},
+			pos:  position{line: 253, col: 1, offset: 7262},
 			expr: &actionExpr{
-				pos: position{line: 187, col: 18, offset: 5153},
+				pos: position{line: 253, col: 18, offset: 7281},
 				run: (*parser).callonSemanticPredOp1,
 				expr: &choiceExpr{
-					pos: position{line: 187, col: 20, offset: 5155},
+					pos: position{line: 253, col: 20, offset: 7283},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 187, col: 20, offset: 5155},
+							pos:        position{line: 253, col: 20, offset: 7283},
 							val:        "#",
 							ignoreCase: false,
 							want:       "\"#\"",
 						},
 						&litMatcher{
-							pos:        position{line: 187, col: 26, offset: 5161},
+							pos:        position{line: 253, col: 26, offset: 7289},
 							val:        "&",
 							ignoreCase: false,
 							want:       "\"&\"",
 						},
 						&litMatcher{
-							pos:        position{line: 187, col: 32, offset: 5167},
+							pos:        position{line: 253, col: 32, offset: 7295},
 							val:        "!",
 							ignoreCase: false,
 							want:       "\"!\"",
@@ -815,96 +1135,99 @@ var g = &grammar{
 		},
 		{
 			name: "RuleDefOp",
-			pos:  position{line: 191, col: 1, offset: 5209},
+			pos:  position{line: 257, col: 1, offset: 7337},
 			expr: &choiceExpr{
-				pos: position{line: 191, col: 13, offset: 5223},
+				pos: position{line: 257, col: 13, offset: 7351},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 191, col: 13, offset: 5223},
+						pos:        position{line: 257, col: 13, offset: 7351},
 						val:        "=",
 						ignoreCase: false,
 						want:       "\"=\"",
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 19, offset: 5229},
+						pos:        position{line: 257, col: 19, offset: 7357},
 						val:        "<-",
 						ignoreCase: false,
 						want:       "\"<-\"",
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 26, offset: 5236},
+						pos:        position{line: 257, col: 26, offset: 7364},
 						val:        "←",
 						ignoreCase: false,
 						want:       "\"←\"",
 					},
 					&litMatcher{
-						pos:        position{line: 191, col: 37, offset: 5247},
+						pos:        position{line: 257, col: 37, offset: 7375},
 						val:        "⟵",
 						ignoreCase: false,
 						want:       "\"⟵\"",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "SourceChar",
-			pos:  position{line: 193, col: 1, offset: 5257},
+			pos:  position{line: 259, col: 1, offset: 7385},
 			expr: &anyMatcher{
-				line: 193, col: 14, offset: 5272,
+				line: 259, col: 14, offset: 7400,
 			},
+			memoize: true,
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 194, col: 1, offset: 5274},
+			pos:  position{line: 260, col: 1, offset: 7402},
 			expr: &choiceExpr{
-				pos: position{line: 194, col: 11, offset: 5286},
+				pos: position{line: 260, col: 11, offset: 7414},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 194, col: 11, offset: 5286},
+						pos:  position{line: 260, col: 11, offset: 7414},
 						name: "MultiLineComment",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 194, col: 30, offset: 5305},
+						pos:  position{line: 260, col: 30, offset: 7433},
 						name: "SingleLineComment",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "MultiLineComment",
-			pos:  position{line: 195, col: 1, offset: 5323},
+			pos:  position{line: 261, col: 1, offset: 7451},
 			expr: &seqExpr{
-				pos: position{line: 195, col: 20, offset: 5344},
+				pos: position{line: 261, col: 20, offset: 7472},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 195, col: 20, offset: 5344},
+						pos:        position{line: 261, col: 20, offset: 7472},
 						val:        "/*",
 						ignoreCase: false,
 						want:       "\"/*\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 195, col: 25, offset: 5349},
+						pos: position{line: 261, col: 25, offset: 7477},
 						expr: &seqExpr{
-							pos: position{line: 195, col: 27, offset: 5351},
+							pos: position{line: 261, col: 27, offset: 7479},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 195, col: 27, offset: 5351},
+									pos: position{line: 261, col: 27, offset: 7479},
 									expr: &litMatcher{
-										pos:        position{line: 195, col: 28, offset: 5352},
+										pos:        position{line: 261, col: 28, offset: 7480},
 										val:        "*/",
 										ignoreCase: false,
 										want:       "\"*/\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 195, col: 33, offset: 5357},
+									pos:  position{line: 261, col: 33, offset: 7485},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 195, col: 47, offset: 5371},
+						pos:        position{line: 261, col: 47, offset: 7499},
 						val:        "*/",
 						ignoreCase: false,
 						want:       "\"*/\"",
@@ -914,48 +1237,48 @@ var g = &grammar{
 		},
 		{
 			name: "MultiLineCommentNoLineTerminator",
-			pos:  position{line: 196, col: 1, offset: 5376},
+			pos:  position{line: 262, col: 1, offset: 7504},
 			expr: &seqExpr{
-				pos: position{line: 196, col: 36, offset: 5413},
+				pos: position{line: 262, col: 36, offset: 7541},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 196, col: 36, offset: 5413},
+						pos:        position{line: 262, col: 36, offset: 7541},
 						val:        "/*",
 						ignoreCase: false,
 						want:       "\"/*\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 196, col: 41, offset: 5418},
+						pos: position{line: 262, col: 41, offset: 7546},
 						expr: &seqExpr{
-							pos: position{line: 196, col: 43, offset: 5420},
+							pos: position{line: 262, col: 43, offset: 7548},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 196, col: 43, offset: 5420},
+									pos: position{line: 262, col: 43, offset: 7548},
 									expr: &choiceExpr{
-										pos: position{line: 196, col: 46, offset: 5423},
+										pos: position{line: 262, col: 46, offset: 7551},
 										alternatives: []interface{}{
 											&litMatcher{
-												pos:        position{line: 196, col: 46, offset: 5423},
+												pos:        position{line: 262, col: 46, offset: 7551},
 												val:        "*/",
 												ignoreCase: false,
 												want:       "\"*/\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 196, col: 53, offset: 5430},
+												pos:  position{line: 262, col: 53, offset: 7558},
 												name: "EOL",
 											},
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 196, col: 59, offset: 5436},
+									pos:  position{line: 262, col: 59, offset: 7564},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 196, col: 73, offset: 5450},
+						pos:        position{line: 262, col: 73, offset: 7578},
 						val:        "*/",
 						ignoreCase: false,
 						want:       "\"*/\"",
@@ -965,39 +1288,39 @@ var g = &grammar{
 		},
 		{
 			name: "SingleLineComment",
-			pos:  position{line: 197, col: 1, offset: 5455},
+			pos:  position{line: 263, col: 1, offset: 7583},
 			expr: &seqExpr{
-				pos: position{line: 197, col: 21, offset: 5477},
+				pos: position{line: 263, col: 21, offset: 7605},
 				exprs: []interface{}{
 					&notExpr{
-						pos: position{line: 197, col: 21, offset: 5477},
+						pos: position{line: 263, col: 21, offset: 7605},
 						expr: &litMatcher{
-							pos:        position{line: 197, col: 23, offset: 5479},
+							pos:        position{line: 263, col: 23, offset: 7607},
 							val:        "//{",
 							ignoreCase: false,
 							want:       "\"//{\"",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 197, col: 30, offset: 5486},
+						pos:        position{line: 263, col: 30, offset: 7614},
 						val:        "//",
 						ignoreCase: false,
 						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 197, col: 35, offset: 5491},
+						pos: position{line: 263, col: 35, offset: 7619},
 						expr: &seqExpr{
-							pos: position{line: 197, col: 37, offset: 5493},
+							pos: position{line: 263, col: 37, offset: 7621},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 197, col: 37, offset: 5493},
+									pos: position{line: 263, col: 37, offset: 7621},
 									expr: &ruleRefExpr{
-										pos:  position{line: 197, col: 38, offset: 5494},
+										pos:  position{line: 263, col: 38, offset: 7622},
 										name: "EOL",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 197, col: 42, offset: 5498},
+									pos:  position{line: 263, col: 42, offset: 7626},
 									name: "SourceChar",
 								},
 							},
@@ -1005,18 +1328,19 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 199, col: 1, offset: 5513},
+			pos:  position{line: 265, col: 1, offset: 7641},
 			expr: &actionExpr{
-				pos: position{line: 199, col: 14, offset: 5528},
+				pos: position{line: 265, col: 14, offset: 7656},
 				run: (*parser).callonIdentifier1,
 				expr: &labeledExpr{
-					pos:   position{line: 199, col: 14, offset: 5528},
+					pos:   position{line: 265, col: 14, offset: 7656},
 					label: "ident",
 					expr: &ruleRefExpr{
-						pos:  position{line: 199, col: 20, offset: 5534},
+						pos:  position{line: 265, col: 20, offset: 7662},
 						name: "IdentifierName",
 					},
 				},
@@ -1024,52 +1348,54 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierName",
-			pos:  position{line: 207, col: 1, offset: 5753},
+			pos:  position{line: 273, col: 1, offset: 7881},
 			expr: &actionExpr{
-				pos: position{line: 207, col: 18, offset: 5772},
+				pos: position{line: 273, col: 18, offset: 7900},
 				run: (*parser).callonIdentifierName1,
 				expr: &seqExpr{
-					pos: position{line: 207, col: 18, offset: 5772},
+					pos: position{line: 273, col: 18, offset: 7900},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 207, col: 18, offset: 5772},
+							pos:  position{line: 273, col: 18, offset: 7900},
 							name: "IdentifierStart",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 207, col: 34, offset: 5788},
+							pos: position{line: 273, col: 34, offset: 7916},
 							expr: &ruleRefExpr{
-								pos:  position{line: 207, col: 34, offset: 5788},
+								pos:  position{line: 273, col: 34, offset: 7916},
 								name: "IdentifierPart",
 							},
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "IdentifierStart",
-			pos:  position{line: 210, col: 1, offset: 5870},
+			pos:  position{line: 276, col: 1, offset: 7998},
 			expr: &charClassMatcher{
-				pos:        position{line: 210, col: 19, offset: 5890},
+				pos:        position{line: 276, col: 19, offset: 8018},
 				val:        "[\\pL_]",
 				chars:      []rune{'_'},
 				classes:    []*unicode.RangeTable{rangeTable("L")},
 				ignoreCase: false,
 				inverted:   false,
 			},
+			memoize: true,
 		},
 		{
 			name: "IdentifierPart",
-			pos:  position{line: 211, col: 1, offset: 5897},
+			pos:  position{line: 277, col: 1, offset: 8025},
 			expr: &choiceExpr{
-				pos: position{line: 211, col: 18, offset: 5916},
+				pos: position{line: 277, col: 18, offset: 8044},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 211, col: 18, offset: 5916},
+						pos:  position{line: 277, col: 18, offset: 8044},
 						name: "IdentifierStart",
 					},
 					&charClassMatcher{
-						pos:        position{line: 211, col: 36, offset: 5934},
+						pos:        position{line: 277, col: 36, offset: 8062},
 						val:        "[\\p{Nd}]",
 						classes:    []*unicode.RangeTable{rangeTable("Nd")},
 						ignoreCase: false,
@@ -1080,28 +1406,28 @@ var g = &grammar{
 		},
 		{
 			name: "LitMatcher",
-			pos:  position{line: 213, col: 1, offset: 5944},
+			pos:  position{line: 279, col: 1, offset: 8072},
 			expr: &actionExpr{
-				pos: position{line: 213, col: 14, offset: 5959},
+				pos: position{line: 279, col: 14, offset: 8087},
 				run: (*parser).callonLitMatcher1,
 				expr: &seqExpr{
-					pos: position{line: 213, col: 14, offset: 5959},
+					pos: position{line: 279, col: 14, offset: 8087},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 213, col: 14, offset: 5959},
+							pos:   position{line: 279, col: 14, offset: 8087},
 							label: "lit",
 							expr: &ruleRefExpr{
-								pos:  position{line: 213, col: 18, offset: 5963},
+								pos:  position{line: 279, col: 18, offset: 8091},
 								name: "StringLiteral",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 213, col: 32, offset: 5977},
+							pos:   position{line: 279, col: 32, offset: 8105},
 							label: "ignore",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 213, col: 39, offset: 5984},
+								pos: position{line: 279, col: 39, offset: 8112},
 								expr: &litMatcher{
-									pos:        position{line: 213, col: 39, offset: 5984},
+									pos:        position{line: 279, col: 39, offset: 8112},
 									val:        "i",
 									ignoreCase: false,
 									want:       "\"i\"",
@@ -1114,34 +1440,34 @@ var g = &grammar{
 		},
 		{
 			name: "StringLiteral",
-			pos:  position{line: 226, col: 1, offset: 6383},
+			pos:  position{line: 292, col: 1, offset: 8511},
 			expr: &choiceExpr{
-				pos: position{line: 226, col: 17, offset: 6401},
+				pos: position{line: 292, col: 17, offset: 8529},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 226, col: 17, offset: 6401},
+						pos: position{line: 292, col: 17, offset: 8529},
 						run: (*parser).callonStringLiteral2,
 						expr: &choiceExpr{
-							pos: position{line: 226, col: 19, offset: 6403},
+							pos: position{line: 292, col: 19, offset: 8531},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 226, col: 19, offset: 6403},
+									pos: position{line: 292, col: 19, offset: 8531},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 19, offset: 6403},
+											pos:        position{line: 292, col: 19, offset: 8531},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 226, col: 23, offset: 6407},
+											pos: position{line: 292, col: 23, offset: 8535},
 											expr: &ruleRefExpr{
-												pos:  position{line: 226, col: 23, offset: 6407},
+												pos:  position{line: 292, col: 23, offset: 8535},
 												name: "DoubleStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 41, offset: 6425},
+											pos:        position{line: 292, col: 41, offset: 8553},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
@@ -1149,20 +1475,20 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 226, col: 47, offset: 6431},
+									pos: position{line: 292, col: 47, offset: 8559},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 47, offset: 6431},
+											pos:        position{line: 292, col: 47, offset: 8559},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 226, col: 51, offset: 6435},
+											pos:  position{line: 292, col: 51, offset: 8563},
 											name: "SingleStringChar",
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 68, offset: 6452},
+											pos:        position{line: 292, col: 68, offset: 8580},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
@@ -1170,23 +1496,23 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 226, col: 74, offset: 6458},
+									pos: position{line: 292, col: 74, offset: 8586},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 226, col: 74, offset: 6458},
+											pos:        position{line: 292, col: 74, offset: 8586},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 226, col: 78, offset: 6462},
+											pos: position{line: 292, col: 78, offset: 8590},
 											expr: &ruleRefExpr{
-												pos:  position{line: 226, col: 78, offset: 6462},
+												pos:  position{line: 292, col: 78, offset: 8590},
 												name: "RawStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 226, col: 93, offset: 6477},
+											pos:        position{line: 292, col: 93, offset: 8605},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
@@ -1197,36 +1523,36 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 228, col: 5, offset: 6550},
+						pos: position{line: 294, col: 5, offset: 8678},
 						run: (*parser).callonStringLiteral18,
 						expr: &choiceExpr{
-							pos: position{line: 228, col: 7, offset: 6552},
+							pos: position{line: 294, col: 7, offset: 8680},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 228, col: 9, offset: 6554},
+									pos: position{line: 294, col: 9, offset: 8682},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 9, offset: 6554},
+											pos:        position{line: 294, col: 9, offset: 8682},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 228, col: 13, offset: 6558},
+											pos: position{line: 294, col: 13, offset: 8686},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 13, offset: 6558},
+												pos:  position{line: 294, col: 13, offset: 8686},
 												name: "DoubleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 228, col: 33, offset: 6578},
+											pos: position{line: 294, col: 33, offset: 8706},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 33, offset: 6578},
+													pos:  position{line: 294, col: 33, offset: 8706},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 39, offset: 6584},
+													pos:  position{line: 294, col: 39, offset: 8712},
 													name: "EOF",
 												},
 											},
@@ -1234,30 +1560,30 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 228, col: 51, offset: 6596},
+									pos: position{line: 294, col: 51, offset: 8724},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 51, offset: 6596},
+											pos:        position{line: 294, col: 51, offset: 8724},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&zeroOrOneExpr{
-											pos: position{line: 228, col: 55, offset: 6600},
+											pos: position{line: 294, col: 55, offset: 8728},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 55, offset: 6600},
+												pos:  position{line: 294, col: 55, offset: 8728},
 												name: "SingleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 228, col: 75, offset: 6620},
+											pos: position{line: 294, col: 75, offset: 8748},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 75, offset: 6620},
+													pos:  position{line: 294, col: 75, offset: 8748},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 228, col: 81, offset: 6626},
+													pos:  position{line: 294, col: 81, offset: 8754},
 													name: "EOF",
 												},
 											},
@@ -1265,23 +1591,23 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 228, col: 91, offset: 6636},
+									pos: position{line: 294, col: 91, offset: 8764},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 228, col: 91, offset: 6636},
+											pos:        position{line: 294, col: 91, offset: 8764},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 228, col: 95, offset: 6640},
+											pos: position{line: 294, col: 95, offset: 8768},
 											expr: &ruleRefExpr{
-												pos:  position{line: 228, col: 95, offset: 6640},
+												pos:  position{line: 294, col: 95, offset: 8768},
 												name: "RawStringChar",
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 228, col: 110, offset: 6655},
+											pos:  position{line: 294, col: 110, offset: 8783},
 											name: "EOF",
 										},
 									},
@@ -1291,180 +1617,184 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "DoubleStringChar",
-			pos:  position{line: 232, col: 1, offset: 6757},
+			pos:  position{line: 298, col: 1, offset: 8885},
 			expr: &choiceExpr{
-				pos: position{line: 232, col: 20, offset: 6778},
+				pos: position{line: 298, col: 20, offset: 8906},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 232, col: 20, offset: 6778},
+						pos: position{line: 298, col: 20, offset: 8906},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 232, col: 20, offset: 6778},
+								pos: position{line: 298, col: 20, offset: 8906},
 								expr: &choiceExpr{
-									pos: position{line: 232, col: 23, offset: 6781},
+									pos: position{line: 298, col: 23, offset: 8909},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 232, col: 23, offset: 6781},
+											pos:        position{line: 298, col: 23, offset: 8909},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&litMatcher{
-											pos:        position{line: 232, col: 29, offset: 6787},
+											pos:        position{line: 298, col: 29, offset: 8915},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 232, col: 36, offset: 6794},
+											pos:  position{line: 298, col: 36, offset: 8922},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 232, col: 42, offset: 6800},
+								pos:  position{line: 298, col: 42, offset: 8928},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 232, col: 55, offset: 6813},
+						pos: position{line: 298, col: 55, offset: 8941},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 232, col: 55, offset: 6813},
+								pos:        position{line: 298, col: 55, offset: 8941},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 232, col: 60, offset: 6818},
+								pos:  position{line: 298, col: 60, offset: 8946},
 								name: "DoubleStringEscape",
 							},
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "SingleStringChar",
-			pos:  position{line: 233, col: 1, offset: 6837},
+			pos:  position{line: 299, col: 1, offset: 8965},
 			expr: &choiceExpr{
-				pos: position{line: 233, col: 20, offset: 6858},
+				pos: position{line: 299, col: 20, offset: 8986},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 233, col: 20, offset: 6858},
+						pos: position{line: 299, col: 20, offset: 8986},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 233, col: 20, offset: 6858},
+								pos: position{line: 299, col: 20, offset: 8986},
 								expr: &choiceExpr{
-									pos: position{line: 233, col: 23, offset: 6861},
+									pos: position{line: 299, col: 23, offset: 8989},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 233, col: 23, offset: 6861},
+											pos:        position{line: 299, col: 23, offset: 8989},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&litMatcher{
-											pos:        position{line: 233, col: 29, offset: 6867},
+											pos:        position{line: 299, col: 29, offset: 8995},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 233, col: 36, offset: 6874},
+											pos:  position{line: 299, col: 36, offset: 9002},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 233, col: 42, offset: 6880},
+								pos:  position{line: 299, col: 42, offset: 9008},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 233, col: 55, offset: 6893},
+						pos: position{line: 299, col: 55, offset: 9021},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 233, col: 55, offset: 6893},
+								pos:        position{line: 299, col: 55, offset: 9021},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 233, col: 60, offset: 6898},
+								pos:  position{line: 299, col: 60, offset: 9026},
 								name: "SingleStringEscape",
 							},
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "RawStringChar",
-			pos:  position{line: 234, col: 1, offset: 6917},
+			pos:  position{line: 300, col: 1, offset: 9045},
 			expr: &seqExpr{
-				pos: position{line: 234, col: 17, offset: 6935},
+				pos: position{line: 300, col: 17, offset: 9063},
 				exprs: []interface{}{
 					&notExpr{
-						pos: position{line: 234, col: 17, offset: 6935},
+						pos: position{line: 300, col: 17, offset: 9063},
 						expr: &litMatcher{
-							pos:        position{line: 234, col: 18, offset: 6936},
+							pos:        position{line: 300, col: 18, offset: 9064},
 							val:        "`",
 							ignoreCase: false,
 							want:       "\"`\"",
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 234, col: 22, offset: 6940},
+						pos:  position{line: 300, col: 22, offset: 9068},
 						name: "SourceChar",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "DoubleStringEscape",
-			pos:  position{line: 236, col: 1, offset: 6952},
+			pos:  position{line: 302, col: 1, offset: 9080},
 			expr: &choiceExpr{
-				pos: position{line: 236, col: 22, offset: 6975},
+				pos: position{line: 302, col: 22, offset: 9103},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 236, col: 24, offset: 6977},
+						pos: position{line: 302, col: 24, offset: 9105},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 236, col: 24, offset: 6977},
+								pos:        position{line: 302, col: 24, offset: 9105},
 								val:        "\"",
 								ignoreCase: false,
 								want:       "\"\\\"\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 236, col: 30, offset: 6983},
+								pos:  position{line: 302, col: 30, offset: 9111},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 237, col: 7, offset: 7012},
+						pos: position{line: 303, col: 7, offset: 9140},
 						run: (*parser).callonDoubleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 237, col: 9, offset: 7014},
+							pos: position{line: 303, col: 9, offset: 9142},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 9, offset: 7014},
+									pos:  position{line: 303, col: 9, offset: 9142},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 22, offset: 7027},
+									pos:  position{line: 303, col: 22, offset: 9155},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 237, col: 28, offset: 7033},
+									pos:  position{line: 303, col: 28, offset: 9161},
 									name: "EOF",
 								},
 							},
@@ -1475,41 +1805,41 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringEscape",
-			pos:  position{line: 240, col: 1, offset: 7098},
+			pos:  position{line: 306, col: 1, offset: 9226},
 			expr: &choiceExpr{
-				pos: position{line: 240, col: 22, offset: 7121},
+				pos: position{line: 306, col: 22, offset: 9249},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 240, col: 24, offset: 7123},
+						pos: position{line: 306, col: 24, offset: 9251},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 240, col: 24, offset: 7123},
+								pos:        position{line: 306, col: 24, offset: 9251},
 								val:        "'",
 								ignoreCase: false,
 								want:       "\"'\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 240, col: 30, offset: 7129},
+								pos:  position{line: 306, col: 30, offset: 9257},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 241, col: 7, offset: 7158},
+						pos: position{line: 307, col: 7, offset: 9286},
 						run: (*parser).callonSingleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 241, col: 9, offset: 7160},
+							pos: position{line: 307, col: 9, offset: 9288},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 9, offset: 7160},
+									pos:  position{line: 307, col: 9, offset: 9288},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 22, offset: 7173},
+									pos:  position{line: 307, col: 22, offset: 9301},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 241, col: 28, offset: 7179},
+									pos:  position{line: 307, col: 28, offset: 9307},
 									name: "EOF",
 								},
 							},
@@ -1520,83 +1850,84 @@ var g = &grammar{
 		},
 		{
 			name: "CommonEscapeSequence",
-			pos:  position{line: 245, col: 1, offset: 7245},
+			pos:  position{line: 311, col: 1, offset: 9373},
 			expr: &choiceExpr{
-				pos: position{line: 245, col: 24, offset: 7270},
+				pos: position{line: 311, col: 24, offset: 9398},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 24, offset: 7270},
+						pos:  position{line: 311, col: 24, offset: 9398},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 43, offset: 7289},
+						pos:  position{line: 311, col: 43, offset: 9417},
 						name: "OctalEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 57, offset: 7303},
+						pos:  position{line: 311, col: 57, offset: 9431},
 						name: "HexEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 69, offset: 7315},
+						pos:  position{line: 311, col: 69, offset: 9443},
 						name: "LongUnicodeEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 89, offset: 7335},
+						pos:  position{line: 311, col: 89, offset: 9463},
 						name: "ShortUnicodeEscape",
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 246, col: 1, offset: 7354},
+			pos:  position{line: 312, col: 1, offset: 9482},
 			expr: &choiceExpr{
-				pos: position{line: 246, col: 20, offset: 7375},
+				pos: position{line: 312, col: 20, offset: 9503},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 246, col: 20, offset: 7375},
+						pos:        position{line: 312, col: 20, offset: 9503},
 						val:        "a",
 						ignoreCase: false,
 						want:       "\"a\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 26, offset: 7381},
+						pos:        position{line: 312, col: 26, offset: 9509},
 						val:        "b",
 						ignoreCase: false,
 						want:       "\"b\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 32, offset: 7387},
+						pos:        position{line: 312, col: 32, offset: 9515},
 						val:        "n",
 						ignoreCase: false,
 						want:       "\"n\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 38, offset: 7393},
+						pos:        position{line: 312, col: 38, offset: 9521},
 						val:        "f",
 						ignoreCase: false,
 						want:       "\"f\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 44, offset: 7399},
+						pos:        position{line: 312, col: 44, offset: 9527},
 						val:        "r",
 						ignoreCase: false,
 						want:       "\"r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 50, offset: 7405},
+						pos:        position{line: 312, col: 50, offset: 9533},
 						val:        "t",
 						ignoreCase: false,
 						want:       "\"t\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 56, offset: 7411},
+						pos:        position{line: 312, col: 56, offset: 9539},
 						val:        "v",
 						ignoreCase: false,
 						want:       "\"v\"",
 					},
 					&litMatcher{
-						pos:        position{line: 246, col: 62, offset: 7417},
+						pos:        position{line: 312, col: 62, offset: 9545},
 						val:        "\\",
 						ignoreCase: false,
 						want:       "\"\\\\\"",
@@ -1606,50 +1937,50 @@ var g = &grammar{
 		},
 		{
 			name: "OctalEscape",
-			pos:  position{line: 247, col: 1, offset: 7422},
+			pos:  position{line: 313, col: 1, offset: 9550},
 			expr: &choiceExpr{
-				pos: position{line: 247, col: 15, offset: 7438},
+				pos: position{line: 313, col: 15, offset: 9566},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 247, col: 15, offset: 7438},
+						pos: position{line: 313, col: 15, offset: 9566},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 15, offset: 7438},
+								pos:  position{line: 313, col: 15, offset: 9566},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 26, offset: 7449},
+								pos:  position{line: 313, col: 26, offset: 9577},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 247, col: 37, offset: 7460},
+								pos:  position{line: 313, col: 37, offset: 9588},
 								name: "OctalDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 248, col: 7, offset: 7477},
+						pos: position{line: 314, col: 7, offset: 9605},
 						run: (*parser).callonOctalEscape6,
 						expr: &seqExpr{
-							pos: position{line: 248, col: 7, offset: 7477},
+							pos: position{line: 314, col: 7, offset: 9605},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 248, col: 7, offset: 7477},
+									pos:  position{line: 314, col: 7, offset: 9605},
 									name: "OctalDigit",
 								},
 								&choiceExpr{
-									pos: position{line: 248, col: 20, offset: 7490},
+									pos: position{line: 314, col: 20, offset: 9618},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 20, offset: 7490},
+											pos:  position{line: 314, col: 20, offset: 9618},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 33, offset: 7503},
+											pos:  position{line: 314, col: 33, offset: 9631},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 248, col: 39, offset: 7509},
+											pos:  position{line: 314, col: 39, offset: 9637},
 											name: "EOF",
 										},
 									},
@@ -1662,54 +1993,54 @@ var g = &grammar{
 		},
 		{
 			name: "HexEscape",
-			pos:  position{line: 251, col: 1, offset: 7570},
+			pos:  position{line: 317, col: 1, offset: 9698},
 			expr: &choiceExpr{
-				pos: position{line: 251, col: 13, offset: 7584},
+				pos: position{line: 317, col: 13, offset: 9712},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 251, col: 13, offset: 7584},
+						pos: position{line: 317, col: 13, offset: 9712},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 251, col: 13, offset: 7584},
+								pos:        position{line: 317, col: 13, offset: 9712},
 								val:        "x",
 								ignoreCase: false,
 								want:       "\"x\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 251, col: 17, offset: 7588},
+								pos:  position{line: 317, col: 17, offset: 9716},
 								name: "HexDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 251, col: 26, offset: 7597},
+								pos:  position{line: 317, col: 26, offset: 9725},
 								name: "HexDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 252, col: 7, offset: 7612},
+						pos: position{line: 318, col: 7, offset: 9740},
 						run: (*parser).callonHexEscape6,
 						expr: &seqExpr{
-							pos: position{line: 252, col: 7, offset: 7612},
+							pos: position{line: 318, col: 7, offset: 9740},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 252, col: 7, offset: 7612},
+									pos:        position{line: 318, col: 7, offset: 9740},
 									val:        "x",
 									ignoreCase: false,
 									want:       "\"x\"",
 								},
 								&choiceExpr{
-									pos: position{line: 252, col: 13, offset: 7618},
+									pos: position{line: 318, col: 13, offset: 9746},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 13, offset: 7618},
+											pos:  position{line: 318, col: 13, offset: 9746},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 26, offset: 7631},
+											pos:  position{line: 318, col: 26, offset: 9759},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 252, col: 32, offset: 7637},
+											pos:  position{line: 318, col: 32, offset: 9765},
 											name: "EOF",
 										},
 									},
@@ -1722,82 +2053,82 @@ var g = &grammar{
 		},
 		{
 			name: "LongUnicodeEscape",
-			pos:  position{line: 255, col: 1, offset: 7704},
+			pos:  position{line: 321, col: 1, offset: 9832},
 			expr: &choiceExpr{
-				pos: position{line: 256, col: 5, offset: 7730},
+				pos: position{line: 322, col: 5, offset: 9858},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 256, col: 5, offset: 7730},
+						pos: position{line: 322, col: 5, offset: 9858},
 						run: (*parser).callonLongUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 256, col: 5, offset: 7730},
+							pos: position{line: 322, col: 5, offset: 9858},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 256, col: 5, offset: 7730},
+									pos:        position{line: 322, col: 5, offset: 9858},
 									val:        "U",
 									ignoreCase: false,
 									want:       "\"U\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 9, offset: 7734},
+									pos:  position{line: 322, col: 9, offset: 9862},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 18, offset: 7743},
+									pos:  position{line: 322, col: 18, offset: 9871},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 27, offset: 7752},
+									pos:  position{line: 322, col: 27, offset: 9880},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 36, offset: 7761},
+									pos:  position{line: 322, col: 36, offset: 9889},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 45, offset: 7770},
+									pos:  position{line: 322, col: 45, offset: 9898},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 54, offset: 7779},
+									pos:  position{line: 322, col: 54, offset: 9907},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 63, offset: 7788},
+									pos:  position{line: 322, col: 63, offset: 9916},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 72, offset: 7797},
+									pos:  position{line: 322, col: 72, offset: 9925},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 259, col: 7, offset: 7899},
+						pos: position{line: 325, col: 7, offset: 10027},
 						run: (*parser).callonLongUnicodeEscape13,
 						expr: &seqExpr{
-							pos: position{line: 259, col: 7, offset: 7899},
+							pos: position{line: 325, col: 7, offset: 10027},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 259, col: 7, offset: 7899},
+									pos:        position{line: 325, col: 7, offset: 10027},
 									val:        "U",
 									ignoreCase: false,
 									want:       "\"U\"",
 								},
 								&choiceExpr{
-									pos: position{line: 259, col: 13, offset: 7905},
+									pos: position{line: 325, col: 13, offset: 10033},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 13, offset: 7905},
+											pos:  position{line: 325, col: 13, offset: 10033},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 26, offset: 7918},
+											pos:  position{line: 325, col: 26, offset: 10046},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 259, col: 32, offset: 7924},
+											pos:  position{line: 325, col: 32, offset: 10052},
 											name: "EOF",
 										},
 									},
@@ -1810,66 +2141,66 @@ var g = &grammar{
 		},
 		{
 			name: "ShortUnicodeEscape",
-			pos:  position{line: 262, col: 1, offset: 7987},
+			pos:  position{line: 328, col: 1, offset: 10115},
 			expr: &choiceExpr{
-				pos: position{line: 263, col: 5, offset: 8014},
+				pos: position{line: 329, col: 5, offset: 10142},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 263, col: 5, offset: 8014},
+						pos: position{line: 329, col: 5, offset: 10142},
 						run: (*parser).callonShortUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 263, col: 5, offset: 8014},
+							pos: position{line: 329, col: 5, offset: 10142},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 263, col: 5, offset: 8014},
+									pos:        position{line: 329, col: 5, offset: 10142},
 									val:        "u",
 									ignoreCase: false,
 									want:       "\"u\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 9, offset: 8018},
+									pos:  position{line: 329, col: 9, offset: 10146},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 18, offset: 8027},
+									pos:  position{line: 329, col: 18, offset: 10155},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 27, offset: 8036},
+									pos:  position{line: 329, col: 27, offset: 10164},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 263, col: 36, offset: 8045},
+									pos:  position{line: 329, col: 36, offset: 10173},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 266, col: 7, offset: 8147},
+						pos: position{line: 332, col: 7, offset: 10275},
 						run: (*parser).callonShortUnicodeEscape9,
 						expr: &seqExpr{
-							pos: position{line: 266, col: 7, offset: 8147},
+							pos: position{line: 332, col: 7, offset: 10275},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 266, col: 7, offset: 8147},
+									pos:        position{line: 332, col: 7, offset: 10275},
 									val:        "u",
 									ignoreCase: false,
 									want:       "\"u\"",
 								},
 								&choiceExpr{
-									pos: position{line: 266, col: 13, offset: 8153},
+									pos: position{line: 332, col: 13, offset: 10281},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 13, offset: 8153},
+											pos:  position{line: 332, col: 13, offset: 10281},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 26, offset: 8166},
+											pos:  position{line: 332, col: 26, offset: 10294},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 32, offset: 8172},
+											pos:  position{line: 332, col: 32, offset: 10300},
 											name: "EOF",
 										},
 									},
@@ -1882,20 +2213,21 @@ var g = &grammar{
 		},
 		{
 			name: "OctalDigit",
-			pos:  position{line: 270, col: 1, offset: 8236},
+			pos:  position{line: 336, col: 1, offset: 10364},
 			expr: &charClassMatcher{
-				pos:        position{line: 270, col: 14, offset: 8251},
+				pos:        position{line: 336, col: 14, offset: 10379},
 				val:        "[0-7]",
 				ranges:     []rune{'0', '7'},
 				ignoreCase: false,
 				inverted:   false,
 			},
+			memoize: true,
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 271, col: 1, offset: 8257},
+			pos:  position{line: 337, col: 1, offset: 10385},
 			expr: &charClassMatcher{
-				pos:        position{line: 271, col: 16, offset: 8274},
+				pos:        position{line: 337, col: 16, offset: 10402},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1904,122 +2236,277 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 272, col: 1, offset: 8280},
+			pos:  position{line: 338, col: 1, offset: 10408},
 			expr: &charClassMatcher{
-				pos:        position{line: 272, col: 12, offset: 8293},
+				pos:        position{line: 338, col: 12, offset: 10421},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
 				inverted:   false,
 			},
+			memoize: true,
 		},
 		{
-			name: "CharClassMatcher",
-			pos:  position{line: 274, col: 1, offset: 8304},
-			expr: &choiceExpr{
-				pos: position{line: 274, col: 20, offset: 8325},
-				alternatives: []interface{}{
-					&actionExpr{
-						pos: position{line: 274, col: 20, offset: 8325},
-						run: (*parser).callonCharClassMatcher2,
-						expr: &seqExpr{
-							pos: position{line: 274, col: 20, offset: 8325},
-							exprs: []interface{}{
-								&litMatcher{
-									pos:        position{line: 274, col: 20, offset: 8325},
-									val:        "[",
-									ignoreCase: false,
-									want:       "\"[\"",
-								},
-								&zeroOrMoreExpr{
-									pos: position{line: 274, col: 24, offset: 8329},
-									expr: &choiceExpr{
-										pos: position{line: 274, col: 26, offset: 8331},
-										alternatives: []interface{}{
-											&ruleRefExpr{
-												pos:  position{line: 274, col: 26, offset: 8331},
-												name: "ClassCharRange",
-											},
-											&ruleRefExpr{
-												pos:  position{line: 274, col: 43, offset: 8348},
-												name: "ClassChar",
-											},
-											&seqExpr{
-												pos: position{line: 274, col: 55, offset: 8360},
-												exprs: []interface{}{
-													&litMatcher{
-														pos:        position{line: 274, col: 55, offset: 8360},
-														val:        "\\",
-														ignoreCase: false,
-														want:       "\"\\\\\"",
-													},
-													&ruleRefExpr{
-														pos:  position{line: 274, col: 60, offset: 8365},
-														name: "UnicodeClassEscape",
-													},
-												},
-											},
-										},
-									},
-								},
-								&litMatcher{
-									pos:        position{line: 274, col: 82, offset: 8387},
-									val:        "]",
-									ignoreCase: false,
-									want:       "\"]\"",
-								},
-								&zeroOrOneExpr{
-									pos: position{line: 274, col: 86, offset: 8391},
-									expr: &litMatcher{
-										pos:        position{line: 274, col: 86, offset: 8391},
-										val:        "i",
-										ignoreCase: false,
-										want:       "\"i\"",
-									},
-								},
+			name: "RegexpMatcher",
+			pos:  position{line: 340, col: 1, offset: 10432},
+			expr: &actionExpr{
+				pos: position{line: 340, col: 17, offset: 10450},
+				run: (*parser).callonRegexpMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 340, col: 17, offset: 10450},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 340, col: 17, offset: 10450},
+							val:        "@regex",
+							ignoreCase: false,
+							want:       "\"@regex\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 340, col: 26, offset: 10459},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 340, col: 29, offset: 10462},
+							label: "lit",
+							expr: &ruleRefExpr{
+								pos:  position{line: 340, col: 33, offset: 10466},
+								name: "StringLiteral",
 							},
 						},
 					},
-					&actionExpr{
-						pos: position{line: 278, col: 5, offset: 8498},
-						run: (*parser).callonCharClassMatcher15,
-						expr: &seqExpr{
-							pos: position{line: 278, col: 5, offset: 8498},
-							exprs: []interface{}{
-								&litMatcher{
-									pos:        position{line: 278, col: 5, offset: 8498},
-									val:        "[",
-									ignoreCase: false,
-									want:       "\"[\"",
+				},
+			},
+		},
+		{
+			name: "KeywordMatcher",
+			pos:  position{line: 353, col: 1, offset: 10842},
+			expr: &actionExpr{
+				pos: position{line: 353, col: 18, offset: 10861},
+				run: (*parser).callonKeywordMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 353, col: 18, offset: 10861},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 353, col: 18, offset: 10861},
+							val:        "@keyword",
+							ignoreCase: false,
+							want:       "\"@keyword\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 353, col: 29, offset: 10872},
+							label: "list",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 353, col: 34, offset: 10877},
+								expr: &ruleRefExpr{
+									pos:  position{line: 353, col: 34, offset: 10877},
+									name: "KeywordList",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "KeywordList",
+			pos:  position{line: 369, col: 1, offset: 11411},
+			expr: &actionExpr{
+				pos: position{line: 369, col: 15, offset: 11427},
+				run: (*parser).callonKeywordList1,
+				expr: &seqExpr{
+					pos: position{line: 369, col: 15, offset: 11427},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 369, col: 15, offset: 11427},
+							val:        "(",
+							ignoreCase: false,
+							want:       "\"(\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 369, col: 19, offset: 11431},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 369, col: 22, offset: 11434},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 369, col: 28, offset: 11440},
+								name: "StringLiteral",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 369, col: 42, offset: 11454},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 369, col: 47, offset: 11459},
+								expr: &seqExpr{
+									pos: position{line: 369, col: 49, offset: 11461},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 369, col: 49, offset: 11461},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 369, col: 52, offset: 11464},
+											val:        ",",
+											ignoreCase: false,
+											want:       "\",\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 369, col: 56, offset: 11468},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 369, col: 59, offset: 11471},
+											name: "StringLiteral",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 369, col: 76, offset: 11488},
+							name: "__",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 369, col: 79, offset: 11491},
+							expr: &seqExpr{
+								pos: position{line: 369, col: 81, offset: 11493},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 369, col: 81, offset: 11493},
+										val:        ",",
+										ignoreCase: false,
+										want:       "\",\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 369, col: 85, offset: 11497},
+										name: "__",
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 369, col: 91, offset: 11503},
+							val:        ")",
+							ignoreCase: false,
+							want:       "\")\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "CharClassMatcher",
+			pos:  position{line: 377, col: 1, offset: 11665},
+			expr: &choiceExpr{
+				pos: position{line: 377, col: 20, offset: 11686},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 377, col: 20, offset: 11686},
+						run: (*parser).callonCharClassMatcher2,
+						expr: &seqExpr{
+							pos: position{line: 377, col: 20, offset: 11686},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 377, col: 20, offset: 11686},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 278, col: 9, offset: 8502},
+									pos: position{line: 377, col: 24, offset: 11690},
+									expr: &choiceExpr{
+										pos: position{line: 377, col: 26, offset: 11692},
+										alternatives: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 377, col: 26, offset: 11692},
+												name: "CharsetRef",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 377, col: 39, offset: 11705},
+												name: "ClassCharRange",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 377, col: 56, offset: 11722},
+												name: "ClassChar",
+											},
+											&seqExpr{
+												pos: position{line: 377, col: 68, offset: 11734},
+												exprs: []interface{}{
+													&litMatcher{
+														pos:        position{line: 377, col: 68, offset: 11734},
+														val:        "\\",
+														ignoreCase: false,
+														want:       "\"\\\\\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 377, col: 73, offset: 11739},
+														name: "UnicodeClassEscape",
+													},
+												},
+											},
+										},
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 377, col: 95, offset: 11761},
+									val:        "]",
+									ignoreCase: false,
+									want:       "\"]\"",
+								},
+								&zeroOrOneExpr{
+									pos: position{line: 377, col: 99, offset: 11765},
+									expr: &litMatcher{
+										pos:        position{line: 377, col: 99, offset: 11765},
+										val:        "i",
+										ignoreCase: false,
+										want:       "\"i\"",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 381, col: 5, offset: 11872},
+						run: (*parser).callonCharClassMatcher16,
+						expr: &seqExpr{
+							pos: position{line: 381, col: 5, offset: 11872},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 381, col: 5, offset: 11872},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 381, col: 9, offset: 11876},
 									expr: &seqExpr{
-										pos: position{line: 278, col: 11, offset: 8504},
+										pos: position{line: 381, col: 11, offset: 11878},
 										exprs: []interface{}{
 											&notExpr{
-												pos: position{line: 278, col: 11, offset: 8504},
+												pos: position{line: 381, col: 11, offset: 11878},
 												expr: &ruleRefExpr{
-													pos:  position{line: 278, col: 14, offset: 8507},
+													pos:  position{line: 381, col: 14, offset: 11881},
 													name: "EOL",
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 278, col: 20, offset: 8513},
+												pos:  position{line: 381, col: 20, offset: 11887},
 												name: "SourceChar",
 											},
 										},
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 278, col: 36, offset: 8529},
+									pos: position{line: 381, col: 36, offset: 11903},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 278, col: 36, offset: 8529},
+											pos:  position{line: 381, col: 36, offset: 11903},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 278, col: 42, offset: 8535},
+											pos:  position{line: 381, col: 42, offset: 11909},
 											name: "EOF",
 										},
 									},
@@ -2029,25 +2516,51 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
+		},
+		{
+			name: "CharsetRef",
+			pos:  position{line: 385, col: 1, offset: 12019},
+			expr: &seqExpr{
+				pos: position{line: 385, col: 14, offset: 12034},
+				exprs: []interface{}{
+					&litMatcher{
+						pos:        position{line: 385, col: 14, offset: 12034},
+						val:        "<",
+						ignoreCase: false,
+						want:       "\"<\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 385, col: 18, offset: 12038},
+						name: "IdentifierName",
+					},
+					&litMatcher{
+						pos:        position{line: 385, col: 33, offset: 12053},
+						val:        ">",
+						ignoreCase: false,
+						want:       "\">\"",
+					},
+				},
+			},
 		},
 		{
 			name: "ClassCharRange",
-			pos:  position{line: 282, col: 1, offset: 8645},
+			pos:  position{line: 386, col: 1, offset: 12057},
 			expr: &seqExpr{
-				pos: position{line: 282, col: 18, offset: 8664},
+				pos: position{line: 386, col: 18, offset: 12076},
 				exprs: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 18, offset: 8664},
+						pos:  position{line: 386, col: 18, offset: 12076},
 						name: "ClassChar",
 					},
 					&litMatcher{
-						pos:        position{line: 282, col: 28, offset: 8674},
+						pos:        position{line: 386, col: 28, offset: 12086},
 						val:        "-",
 						ignoreCase: false,
 						want:       "\"-\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 32, offset: 8678},
+						pos:  position{line: 386, col: 32, offset: 12090},
 						name: "ClassChar",
 					},
 				},
@@ -2055,110 +2568,111 @@ var g = &grammar{
 		},
 		{
 			name: "ClassChar",
-			pos:  position{line: 283, col: 1, offset: 8688},
+			pos:  position{line: 387, col: 1, offset: 12100},
 			expr: &choiceExpr{
-				pos: position{line: 283, col: 13, offset: 8702},
+				pos: position{line: 387, col: 13, offset: 12114},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 283, col: 13, offset: 8702},
+						pos: position{line: 387, col: 13, offset: 12114},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 283, col: 13, offset: 8702},
+								pos: position{line: 387, col: 13, offset: 12114},
 								expr: &choiceExpr{
-									pos: position{line: 283, col: 16, offset: 8705},
+									pos: position{line: 387, col: 16, offset: 12117},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 283, col: 16, offset: 8705},
+											pos:        position{line: 387, col: 16, offset: 12117},
 											val:        "]",
 											ignoreCase: false,
 											want:       "\"]\"",
 										},
 										&litMatcher{
-											pos:        position{line: 283, col: 22, offset: 8711},
+											pos:        position{line: 387, col: 22, offset: 12123},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 283, col: 29, offset: 8718},
+											pos:  position{line: 387, col: 29, offset: 12130},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 283, col: 35, offset: 8724},
+								pos:  position{line: 387, col: 35, offset: 12136},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 283, col: 48, offset: 8737},
+						pos: position{line: 387, col: 48, offset: 12149},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 283, col: 48, offset: 8737},
+								pos:        position{line: 387, col: 48, offset: 12149},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 283, col: 53, offset: 8742},
+								pos:  position{line: 387, col: 53, offset: 12154},
 								name: "CharClassEscape",
 							},
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "CharClassEscape",
-			pos:  position{line: 284, col: 1, offset: 8758},
+			pos:  position{line: 388, col: 1, offset: 12170},
 			expr: &choiceExpr{
-				pos: position{line: 284, col: 19, offset: 8778},
+				pos: position{line: 388, col: 19, offset: 12190},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 284, col: 21, offset: 8780},
+						pos: position{line: 388, col: 21, offset: 12192},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 284, col: 21, offset: 8780},
+								pos:        position{line: 388, col: 21, offset: 12192},
 								val:        "]",
 								ignoreCase: false,
 								want:       "\"]\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 284, col: 27, offset: 8786},
+								pos:  position{line: 388, col: 27, offset: 12198},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 285, col: 7, offset: 8815},
+						pos: position{line: 389, col: 7, offset: 12227},
 						run: (*parser).callonCharClassEscape5,
 						expr: &seqExpr{
-							pos: position{line: 285, col: 7, offset: 8815},
+							pos: position{line: 389, col: 7, offset: 12227},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 285, col: 7, offset: 8815},
+									pos: position{line: 389, col: 7, offset: 12227},
 									expr: &litMatcher{
-										pos:        position{line: 285, col: 8, offset: 8816},
+										pos:        position{line: 389, col: 8, offset: 12228},
 										val:        "p",
 										ignoreCase: false,
 										want:       "\"p\"",
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 285, col: 14, offset: 8822},
+									pos: position{line: 389, col: 14, offset: 12234},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 14, offset: 8822},
+											pos:  position{line: 389, col: 14, offset: 12234},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 27, offset: 8835},
+											pos:  position{line: 389, col: 27, offset: 12247},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 285, col: 33, offset: 8841},
+											pos:  position{line: 389, col: 33, offset: 12253},
 											name: "EOF",
 										},
 									},
@@ -2171,51 +2685,51 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeClassEscape",
-			pos:  position{line: 289, col: 1, offset: 8907},
+			pos:  position{line: 393, col: 1, offset: 12319},
 			expr: &seqExpr{
-				pos: position{line: 289, col: 22, offset: 8930},
+				pos: position{line: 393, col: 22, offset: 12342},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 289, col: 22, offset: 8930},
+						pos:        position{line: 393, col: 22, offset: 12342},
 						val:        "p",
 						ignoreCase: false,
 						want:       "\"p\"",
 					},
 					&choiceExpr{
-						pos: position{line: 290, col: 7, offset: 8942},
+						pos: position{line: 394, col: 7, offset: 12354},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 290, col: 7, offset: 8942},
+								pos:  position{line: 394, col: 7, offset: 12354},
 								name: "SingleCharUnicodeClass",
 							},
 							&actionExpr{
-								pos: position{line: 291, col: 7, offset: 8971},
+								pos: position{line: 395, col: 7, offset: 12383},
 								run: (*parser).callonUnicodeClassEscape5,
 								expr: &seqExpr{
-									pos: position{line: 291, col: 7, offset: 8971},
+									pos: position{line: 395, col: 7, offset: 12383},
 									exprs: []interface{}{
 										&notExpr{
-											pos: position{line: 291, col: 7, offset: 8971},
+											pos: position{line: 395, col: 7, offset: 12383},
 											expr: &litMatcher{
-												pos:        position{line: 291, col: 8, offset: 8972},
+												pos:        position{line: 395, col: 8, offset: 12384},
 												val:        "{",
 												ignoreCase: false,
 												want:       "\"{\"",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 291, col: 14, offset: 8978},
+											pos: position{line: 395, col: 14, offset: 12390},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 14, offset: 8978},
+													pos:  position{line: 395, col: 14, offset: 12390},
 													name: "SourceChar",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 27, offset: 8991},
+													pos:  position{line: 395, col: 27, offset: 12403},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 291, col: 33, offset: 8997},
+													pos:  position{line: 395, col: 33, offset: 12409},
 													name: "EOF",
 												},
 											},
@@ -2224,27 +2738,27 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 292, col: 7, offset: 9068},
+								pos: position{line: 396, col: 7, offset: 12480},
 								run: (*parser).callonUnicodeClassEscape13,
 								expr: &seqExpr{
-									pos: position{line: 292, col: 7, offset: 9068},
+									pos: position{line: 396, col: 7, offset: 12480},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 292, col: 7, offset: 9068},
+											pos:        position{line: 396, col: 7, offset: 12480},
 											val:        "{",
 											ignoreCase: false,
 											want:       "\"{\"",
 										},
 										&labeledExpr{
-											pos:   position{line: 292, col: 11, offset: 9072},
+											pos:   position{line: 396, col: 11, offset: 12484},
 											label: "ident",
 											expr: &ruleRefExpr{
-												pos:  position{line: 292, col: 17, offset: 9078},
+												pos:  position{line: 396, col: 17, offset: 12490},
 												name: "IdentifierName",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 292, col: 32, offset: 9093},
+											pos:        position{line: 396, col: 32, offset: 12505},
 											val:        "}",
 											ignoreCase: false,
 											want:       "\"}\"",
@@ -2253,36 +2767,36 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 298, col: 7, offset: 9270},
+								pos: position{line: 402, col: 7, offset: 12682},
 								run: (*parser).callonUnicodeClassEscape19,
 								expr: &seqExpr{
-									pos: position{line: 298, col: 7, offset: 9270},
+									pos: position{line: 402, col: 7, offset: 12682},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 298, col: 7, offset: 9270},
+											pos:        position{line: 402, col: 7, offset: 12682},
 											val:        "{",
 											ignoreCase: false,
 											want:       "\"{\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 298, col: 11, offset: 9274},
+											pos:  position{line: 402, col: 11, offset: 12686},
 											name: "IdentifierName",
 										},
 										&choiceExpr{
-											pos: position{line: 298, col: 28, offset: 9291},
+											pos: position{line: 402, col: 28, offset: 12703},
 											alternatives: []interface{}{
 												&litMatcher{
-													pos:        position{line: 298, col: 28, offset: 9291},
+													pos:        position{line: 402, col: 28, offset: 12703},
 													val:        "]",
 													ignoreCase: false,
 													want:       "\"]\"",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 298, col: 34, offset: 9297},
+													pos:  position{line: 402, col: 34, offset: 12709},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 298, col: 40, offset: 9303},
+													pos:  position{line: 402, col: 40, offset: 12715},
 													name: "EOF",
 												},
 											},
@@ -2297,9 +2811,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharUnicodeClass",
-			pos:  position{line: 302, col: 1, offset: 9386},
+			pos:  position{line: 406, col: 1, offset: 12798},
 			expr: &charClassMatcher{
-				pos:        position{line: 302, col: 26, offset: 9413},
+				pos:        position{line: 406, col: 26, offset: 12825},
 				val:        "[LMNCPZS]",
 				chars:      []rune{'L', 'M', 'N', 'C', 'P', 'Z', 'S'},
 				ignoreCase: false,
@@ -2308,52 +2822,66 @@ var g = &grammar{
 		},
 		{
 			name: "AnyMatcher",
-			pos:  position{line: 304, col: 1, offset: 9424},
+			pos:  position{line: 408, col: 1, offset: 12836},
 			expr: &actionExpr{
-				pos: position{line: 304, col: 14, offset: 9439},
+				pos: position{line: 408, col: 14, offset: 12851},
 				run: (*parser).callonAnyMatcher1,
 				expr: &litMatcher{
-					pos:        position{line: 304, col: 14, offset: 9439},
+					pos:        position{line: 408, col: 14, offset: 12851},
 					val:        ".",
 					ignoreCase: false,
 					want:       "\".\"",
 				},
 			},
 		},
+		{
+			name: "BOLMatcher",
+			pos:  position{line: 413, col: 1, offset: 12926},
+			expr: &actionExpr{
+				pos: position{line: 413, col: 14, offset: 12941},
+				run: (*parser).callonBOLMatcher1,
+				expr: &litMatcher{
+					pos:        position{line: 413, col: 14, offset: 12941},
+					val:        "^",
+					ignoreCase: false,
+					want:       "\"^\"",
+				},
+			},
+		},
 		{
 			name: "ThrowExpr",
-			pos:  position{line: 309, col: 1, offset: 9514},
+			pos:  position{line: 418, col: 1, offset: 13016},
 			expr: &choiceExpr{
-				pos: position{line: 309, col: 13, offset: 9528},
+				pos: position{line: 418, col: 13, offset: 13030},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 309, col: 13, offset: 9528},
+						pos: position{line: 418, col: 13, offset: 13030},
 						run: (*parser).callonThrowExpr2,
 						expr: &seqExpr{
-							pos: position{line: 309, col: 13, offset: 9528},
+							pos: position{line: 418, col: 13, offset: 13030},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 309, col: 13, offset: 9528},
+									pos:        position{line: 418, col: 13, offset: 13030},
 									val:        "%",
 									ignoreCase: false,
 									want:       "\"%\"",
 								},
 								&litMatcher{
-									pos:        position{line: 309, col: 17, offset: 9532},
+									pos:        position{line: 418, col: 17, offset: 13034},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 309, col: 21, offset: 9536},
+									pos:   position{line: 418, col: 21, offset: 13038},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 309, col: 27, offset: 9542},
+										pos:  position{line: 418, col: 27, offset: 13044},
 										name: "IdentifierName",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 309, col: 42, offset: 9557},
+									pos:        position{line: 418, col: 42, offset: 13059},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2362,29 +2890,29 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 313, col: 5, offset: 9665},
+						pos: position{line: 422, col: 5, offset: 13167},
 						run: (*parser).callonThrowExpr9,
 						expr: &seqExpr{
-							pos: position{line: 313, col: 5, offset: 9665},
+							pos: position{line: 422, col: 5, offset: 13167},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 313, col: 5, offset: 9665},
+									pos:        position{line: 422, col: 5, offset: 13167},
 									val:        "%",
 									ignoreCase: false,
 									want:       "\"%\"",
 								},
 								&litMatcher{
-									pos:        position{line: 313, col: 9, offset: 9669},
+									pos:        position{line: 422, col: 9, offset: 13171},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 313, col: 13, offset: 9673},
+									pos:  position{line: 422, col: 13, offset: 13175},
 									name: "IdentifierName",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 313, col: 28, offset: 9688},
+									pos:  position{line: 422, col: 28, offset: 13190},
 									name: "EOF",
 								},
 							},
@@ -2395,28 +2923,28 @@ var g = &grammar{
 		},
 		{
 			name: "CodeBlock",
-			pos:  position{line: 317, col: 1, offset: 9759},
+			pos:  position{line: 426, col: 1, offset: 13261},
 			expr: &choiceExpr{
-				pos: position{line: 317, col: 13, offset: 9773},
+				pos: position{line: 426, col: 13, offset: 13275},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 317, col: 13, offset: 9773},
+						pos: position{line: 426, col: 13, offset: 13275},
 						run: (*parser).callonCodeBlock2,
 						expr: &seqExpr{
-							pos: position{line: 317, col: 13, offset: 9773},
+							pos: position{line: 426, col: 13, offset: 13275},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 317, col: 13, offset: 9773},
+									pos:        position{line: 426, col: 13, offset: 13275},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 317, col: 17, offset: 9777},
+									pos:  position{line: 426, col: 17, offset: 13279},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 317, col: 22, offset: 9782},
+									pos:        position{line: 426, col: 22, offset: 13284},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2425,23 +2953,23 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 321, col: 5, offset: 9881},
+						pos: position{line: 430, col: 5, offset: 13383},
 						run: (*parser).callonCodeBlock7,
 						expr: &seqExpr{
-							pos: position{line: 321, col: 5, offset: 9881},
+							pos: position{line: 430, col: 5, offset: 13383},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 321, col: 5, offset: 9881},
+									pos:        position{line: 430, col: 5, offset: 13383},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 9, offset: 9885},
+									pos:  position{line: 430, col: 9, offset: 13387},
 									name: "Code",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 14, offset: 9890},
+									pos:  position{line: 430, col: 14, offset: 13392},
 									name: "EOF",
 								},
 							},
@@ -2449,31 +2977,32 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "Code",
-			pos:  position{line: 325, col: 1, offset: 9955},
+			pos:  position{line: 434, col: 1, offset: 13457},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 325, col: 8, offset: 9964},
+				pos: position{line: 434, col: 8, offset: 13466},
 				expr: &choiceExpr{
-					pos: position{line: 325, col: 10, offset: 9966},
+					pos: position{line: 434, col: 10, offset: 13468},
 					alternatives: []interface{}{
 						&oneOrMoreExpr{
-							pos: position{line: 325, col: 10, offset: 9966},
+							pos: position{line: 434, col: 10, offset: 13468},
 							expr: &choiceExpr{
-								pos: position{line: 325, col: 12, offset: 9968},
+								pos: position{line: 434, col: 12, offset: 13470},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 325, col: 12, offset: 9968},
+										pos:  position{line: 434, col: 12, offset: 13470},
 										name: "Comment",
 									},
 									&seqExpr{
-										pos: position{line: 325, col: 22, offset: 9978},
+										pos: position{line: 434, col: 22, offset: 13480},
 										exprs: []interface{}{
 											&notExpr{
-												pos: position{line: 325, col: 22, offset: 9978},
+												pos: position{line: 434, col: 22, offset: 13480},
 												expr: &charClassMatcher{
-													pos:        position{line: 325, col: 23, offset: 9979},
+													pos:        position{line: 434, col: 23, offset: 13481},
 													val:        "[{}]",
 													chars:      []rune{'{', '}'},
 													ignoreCase: false,
@@ -2481,7 +3010,7 @@ var g = &grammar{
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 325, col: 28, offset: 9984},
+												pos:  position{line: 434, col: 28, offset: 13486},
 												name: "SourceChar",
 											},
 										},
@@ -2490,20 +3019,20 @@ var g = &grammar{
 							},
 						},
 						&seqExpr{
-							pos: position{line: 325, col: 44, offset: 10000},
+							pos: position{line: 434, col: 44, offset: 13502},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 325, col: 44, offset: 10000},
+									pos:        position{line: 434, col: 44, offset: 13502},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 325, col: 48, offset: 10004},
+									pos:  position{line: 434, col: 48, offset: 13506},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 325, col: 53, offset: 10009},
+									pos:        position{line: 434, col: 53, offset: 13511},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2513,87 +3042,92 @@ var g = &grammar{
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "__",
-			pos:  position{line: 327, col: 1, offset: 10017},
+			pos:  position{line: 436, col: 1, offset: 13519},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 327, col: 6, offset: 10024},
+				pos: position{line: 436, col: 6, offset: 13526},
 				expr: &choiceExpr{
-					pos: position{line: 327, col: 8, offset: 10026},
+					pos: position{line: 436, col: 8, offset: 13528},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 8, offset: 10026},
+							pos:  position{line: 436, col: 8, offset: 13528},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 21, offset: 10039},
+							pos:  position{line: 436, col: 21, offset: 13541},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 327, col: 27, offset: 10045},
+							pos:  position{line: 436, col: 27, offset: 13547},
 							name: "Comment",
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "_",
-			pos:  position{line: 328, col: 1, offset: 10056},
+			pos:  position{line: 437, col: 1, offset: 13558},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 328, col: 5, offset: 10062},
+				pos: position{line: 437, col: 5, offset: 13564},
 				expr: &choiceExpr{
-					pos: position{line: 328, col: 7, offset: 10064},
+					pos: position{line: 437, col: 7, offset: 13566},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 7, offset: 10064},
+							pos:  position{line: 437, col: 7, offset: 13566},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 20, offset: 10077},
+							pos:  position{line: 437, col: 20, offset: 13579},
 							name: "MultiLineCommentNoLineTerminator",
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 330, col: 1, offset: 10114},
+			pos:  position{line: 439, col: 1, offset: 13616},
 			expr: &charClassMatcher{
-				pos:        position{line: 330, col: 14, offset: 10129},
+				pos:        position{line: 439, col: 14, offset: 13631},
 				val:        "[ \\t\\r]",
 				chars:      []rune{' ', '\t', '\r'},
 				ignoreCase: false,
 				inverted:   false,
 			},
+			memoize: true,
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 331, col: 1, offset: 10137},
+			pos:  position{line: 440, col: 1, offset: 13639},
 			expr: &litMatcher{
-				pos:        position{line: 331, col: 7, offset: 10145},
+				pos:        position{line: 440, col: 7, offset: 13647},
 				val:        "\n",
 				ignoreCase: false,
 				want:       "\"\\n\"",
 			},
+			memoize: true,
 		},
 		{
 			name: "EOS",
-			pos:  position{line: 332, col: 1, offset: 10150},
+			pos:  position{line: 441, col: 1, offset: 13652},
 			expr: &choiceExpr{
-				pos: position{line: 332, col: 7, offset: 10158},
+				pos: position{line: 441, col: 7, offset: 13660},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 332, col: 7, offset: 10158},
+						pos: position{line: 441, col: 7, offset: 13660},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 7, offset: 10158},
+								pos:  position{line: 441, col: 7, offset: 13660},
 								name: "__",
 							},
 							&litMatcher{
-								pos:        position{line: 332, col: 10, offset: 10161},
+								pos:        position{line: 441, col: 10, offset: 13663},
 								val:        ";",
 								ignoreCase: false,
 								want:       "\";\"",
@@ -2601,68 +3135,161 @@ var g = &grammar{
 						},
 					},
 					&seqExpr{
-						pos: position{line: 332, col: 16, offset: 10167},
+						pos: position{line: 441, col: 16, offset: 13669},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 16, offset: 10167},
+								pos:  position{line: 441, col: 16, offset: 13669},
 								name: "_",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 332, col: 18, offset: 10169},
+								pos: position{line: 441, col: 18, offset: 13671},
 								expr: &ruleRefExpr{
-									pos:  position{line: 332, col: 18, offset: 10169},
+									pos:  position{line: 441, col: 18, offset: 13671},
 									name: "SingleLineComment",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 37, offset: 10188},
+								pos:  position{line: 441, col: 37, offset: 13690},
 								name: "EOL",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 332, col: 43, offset: 10194},
+						pos: position{line: 441, col: 43, offset: 13696},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 43, offset: 10194},
+								pos:  position{line: 441, col: 43, offset: 13696},
 								name: "__",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 332, col: 46, offset: 10197},
+								pos:  position{line: 441, col: 46, offset: 13699},
 								name: "EOF",
 							},
 						},
 					},
 				},
 			},
+			memoize: true,
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 334, col: 1, offset: 10202},
+			pos:  position{line: 443, col: 1, offset: 13704},
 			expr: &notExpr{
-				pos: position{line: 334, col: 7, offset: 10210},
+				pos: position{line: 443, col: 7, offset: 13712},
 				expr: &anyMatcher{
-					line: 334, col: 8, offset: 10211,
+					line: 443, col: 8, offset: 13713,
 				},
 			},
+			memoize: true,
 		},
 	},
 }
 
-func (c *current) onGrammar1(initializer, rules interface{}) (interface{}, error) {
+// The names of the rules of the grammar, e.g. for the Entrypoint option.
+const (
+	RuleGrammar                          = "Grammar"
+	RuleDirective                        = "Directive"
+	RulePackageDirective                 = "PackageDirective"
+	RuleImportGoDirective                = "ImportGoDirective"
+	RuleInitializer                      = "Initializer"
+	RuleCharset                          = "Charset"
+	RuleRule                             = "Rule"
+	RuleResultTypeComment                = "ResultTypeComment"
+	RuleResultType                       = "ResultType"
+	RuleExpression                       = "Expression"
+	RuleRecoveryExpr                     = "RecoveryExpr"
+	RuleLabels                           = "Labels"
+	RuleChoiceExpr                       = "ChoiceExpr"
+	RuleActionExpr                       = "ActionExpr"
+	RuleSeqExpr                          = "SeqExpr"
+	RuleLabeledExpr                      = "LabeledExpr"
+	RulePrefixedExpr                     = "PrefixedExpr"
+	RulePrefixedOp                       = "PrefixedOp"
+	RuleSuffixedExpr                     = "SuffixedExpr"
+	RuleSuffixedOp                       = "SuffixedOp"
+	RulePrimaryExpr                      = "PrimaryExpr"
+	RuleRuleRefExpr                      = "RuleRefExpr"
+	RuleSemanticPredExpr                 = "SemanticPredExpr"
+	RuleSemanticPredOp                   = "SemanticPredOp"
+	RuleRuleDefOp                        = "RuleDefOp"
+	RuleSourceChar                       = "SourceChar"
+	RuleComment                          = "Comment"
+	RuleMultiLineComment                 = "MultiLineComment"
+	RuleMultiLineCommentNoLineTerminator = "MultiLineCommentNoLineTerminator"
+	RuleSingleLineComment                = "SingleLineComment"
+	RuleIdentifier                       = "Identifier"
+	RuleIdentifierName                   = "IdentifierName"
+	RuleIdentifierStart                  = "IdentifierStart"
+	RuleIdentifierPart                   = "IdentifierPart"
+	RuleLitMatcher                       = "LitMatcher"
+	RuleStringLiteral                    = "StringLiteral"
+	RuleDoubleStringChar                 = "DoubleStringChar"
+	RuleSingleStringChar                 = "SingleStringChar"
+	RuleRawStringChar                    = "RawStringChar"
+	RuleDoubleStringEscape               = "DoubleStringEscape"
+	RuleSingleStringEscape               = "SingleStringEscape"
+	RuleCommonEscapeSequence             = "CommonEscapeSequence"
+	RuleSingleCharEscape                 = "SingleCharEscape"
+	RuleOctalEscape                      = "OctalEscape"
+	RuleHexEscape                        = "HexEscape"
+	RuleLongUnicodeEscape                = "LongUnicodeEscape"
+	RuleShortUnicodeEscape               = "ShortUnicodeEscape"
+	RuleOctalDigit                       = "OctalDigit"
+	RuleDecimalDigit                     = "DecimalDigit"
+	RuleHexDigit                         = "HexDigit"
+	RuleRegexpMatcher                    = "RegexpMatcher"
+	RuleKeywordMatcher                   = "KeywordMatcher"
+	RuleKeywordList                      = "KeywordList"
+	RuleCharClassMatcher                 = "CharClassMatcher"
+	RuleCharsetRef                       = "CharsetRef"
+	RuleClassCharRange                   = "ClassCharRange"
+	RuleClassChar                        = "ClassChar"
+	RuleCharClassEscape                  = "CharClassEscape"
+	RuleUnicodeClassEscape               = "UnicodeClassEscape"
+	RuleSingleCharUnicodeClass           = "SingleCharUnicodeClass"
+	RuleAnyMatcher                       = "AnyMatcher"
+	RuleBOLMatcher                       = "BOLMatcher"
+	RuleThrowExpr                        = "ThrowExpr"
+	RuleCodeBlock                        = "CodeBlock"
+	RuleCode                             = "Code"
+	Rule__                               = "__"
+	Rule_                                = "_"
+	RuleWhitespace                       = "Whitespace"
+	RuleEOL                              = "EOL"
+	RuleEOS                              = "EOS"
+	RuleEOF                              = "EOF"
+)
+
+func (c *current) onGrammar1(directives, initializer, rules interface{}) (interface{}, error) {
 	pos := c.astPos()
 
-	// create the grammar, assign its initializer
+	// create the grammar, assign its directives and initializer
 	g := ast.NewGrammar(pos)
+	for _, duo := range toIfaceSlice(directives) {
+		switch d := duo.([]interface{})[0].(type) {
+		case *ast.Identifier:
+			if g.Package != nil {
+				return g, fmt.Errorf("%s: duplicate @package directive", d.Pos())
+			}
+			g.Package = d
+		case *ast.StringLit:
+			g.GoImports = append(g.GoImports, d)
+		}
+	}
 	initSlice := toIfaceSlice(initializer)
 	if len(initSlice) > 0 {
 		g.Init = initSlice[0].(*ast.CodeBlock)
 	}
 
 	rulesSlice := toIfaceSlice(rules)
-	g.Rules = make([]*ast.Rule, len(rulesSlice))
-	for i, duo := range rulesSlice {
-		g.Rules[i] = duo.([]interface{})[0].(*ast.Rule)
+	g.Rules = make([]*ast.Rule, 0, len(rulesSlice))
+	for _, duo := range rulesSlice {
+		switch r := duo.([]interface{})[0].(type) {
+		case *ast.Rule:
+			g.Rules = append(g.Rules, r)
+		case *ast.Charset:
+			g.Charsets = append(g.Charsets, r)
+		}
 	}
 
 	return g, nil
@@ -2671,7 +3298,33 @@ func (c *current) onGrammar1(initializer, rules interface{}) (interface{}, error
 func (p *parser) callonGrammar1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onGrammar1(stack["initializer"], stack["rules"])
+	return p.cur.onGrammar1(stack["directives"], stack["initializer"], stack["rules"])
+}
+
+func (c *current) onPackageDirective1(name interface{}) (interface{}, error) {
+	return name, nil
+}
+
+func (p *parser) callonPackageDirective1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPackageDirective1(stack["name"])
+}
+
+func (c *current) onImportGoDirective1(path interface{}) (interface{}, error) {
+	lit := path.(*ast.StringLit)
+	if !strings.HasPrefix(lit.Val, "'") {
+		if p, err := strconv.Unquote(lit.Val); err == nil && p != "" {
+			return lit, nil
+		}
+	}
+	return lit, errors.New("invalid import path")
+}
+
+func (p *parser) callonImportGoDirective1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImportGoDirective1(stack["path"])
 }
 
 func (c *current) onInitializer1(code interface{}) (interface{}, error) {
@@ -2684,7 +3337,19 @@ func (p *parser) callonInitializer1() (interface{}, error) {
 	return p.cur.onInitializer1(stack["code"])
 }
 
-func (c *current) onRule1(name, display, expr interface{}) (interface{}, error) {
+func (c *current) onCharset1(name, class interface{}) (interface{}, error) {
+	cs := ast.NewCharset(c.astPos(), name.(*ast.Identifier))
+	cs.Class = class.(*ast.CharClassMatcher)
+	return cs, nil
+}
+
+func (p *parser) callonCharset1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharset1(stack["name"], stack["class"])
+}
+
+func (c *current) onRule1(longest, name, display, expr, typ interface{}) (interface{}, error) {
 	pos := c.astPos()
 
 	rule := ast.NewRule(pos, name.(*ast.Identifier))
@@ -2693,6 +3358,19 @@ func (c *current) onRule1(name, display, expr interface{}) (interface{}, error)
 		rule.DisplayName = displaySlice[0].(*ast.StringLit)
 	}
 	rule.Expr = expr.(ast.Expression)
+	if typ != nil {
+		rule.ResultType = typ.(string)
+	}
+
+	if longest != nil {
+		rule.Longest = true
+		ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+			if ch, ok := expr.(*ast.ChoiceExpr); ok {
+				ch.Longest = true
+			}
+			return true
+		})
+	}
 
 	return rule, nil
 }
@@ -2700,7 +3378,27 @@ func (c *current) onRule1(name, display, expr interface{}) (interface{}, error)
 func (p *parser) callonRule1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onRule1(stack["name"], stack["display"], stack["expr"])
+	return p.cur.onRule1(stack["longest"], stack["name"], stack["display"], stack["expr"], stack["typ"])
+}
+
+func (c *current) onResultTypeComment1(typ interface{}) (interface{}, error) {
+	return typ, nil
+}
+
+func (p *parser) callonResultTypeComment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onResultTypeComment1(stack["typ"])
+}
+
+func (c *current) onResultType1() (interface{}, error) {
+	return strings.TrimSpace(string(c.text)), nil
+}
+
+func (p *parser) callonResultType1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onResultType1()
 }
 
 func (c *current) onRecoveryExpr1(expr, recoverExprs interface{}) (interface{}, error) {
@@ -2816,10 +3514,15 @@ func (p *parser) callonLabeledExpr2() (interface{}, error) {
 func (c *current) onPrefixedExpr2(op, expr interface{}) (interface{}, error) {
 	pos := c.astPos()
 	opStr := op.(string)
-	if opStr == "&" {
+	switch opStr {
+	case "&":
 		and := ast.NewAndExpr(pos)
 		and.Expr = expr.(ast.Expression)
 		return and, nil
+	case "<&", "<!":
+		lb := ast.NewLookbehindExpr(pos, opStr == "<!")
+		lb.Expr = expr.(ast.Expression)
+		return lb, nil
 	}
 	not := ast.NewNotExpr(pos)
 	not.Expr = expr.(ast.Expression)
@@ -2879,14 +3582,14 @@ func (p *parser) callonSuffixedOp1() (interface{}, error) {
 	return p.cur.onSuffixedOp1()
 }
 
-func (c *current) onPrimaryExpr7(expr interface{}) (interface{}, error) {
+func (c *current) onPrimaryExpr10(expr interface{}) (interface{}, error) {
 	return expr, nil
 }
 
-func (p *parser) callonPrimaryExpr7() (interface{}, error) {
+func (p *parser) callonPrimaryExpr10() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onPrimaryExpr7(stack["expr"])
+	return p.cur.onPrimaryExpr10(stack["expr"])
 }
 
 func (c *current) onRuleRefExpr1(name interface{}) (interface{}, error) {
@@ -3084,6 +3787,62 @@ func (p *parser) callonShortUnicodeEscape9() (interface{}, error) {
 	return p.cur.onShortUnicodeEscape9()
 }
 
+func (c *current) onRegexpMatcher1(lit interface{}) (interface{}, error) {
+	s, err := strconv.Unquote(lit.(*ast.StringLit).Val)
+	if err != nil {
+		// an invalid string literal raises an error in the escape rules.
+		s = ""
+	}
+	m := ast.NewRegexpMatcher(c.astPos(), s)
+	if _, err := regexp.Compile(s); err != nil {
+		return m, fmt.Errorf("invalid regular expression: %v", err)
+	}
+	return m, nil
+}
+
+func (p *parser) callonRegexpMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRegexpMatcher1(stack["lit"])
+}
+
+func (c *current) onKeywordMatcher1(list interface{}) (interface{}, error) {
+	var keywords []string
+	var err error
+	for _, lit := range toIfaceSlice(list) {
+		s, uerr := strconv.Unquote(lit.(*ast.StringLit).Val)
+		if uerr != nil {
+			// an invalid string literal raises an error in the escape rules.
+			continue
+		}
+		if !ast.IsKeyword(s) && err == nil {
+			err = fmt.Errorf("invalid keyword %q: not an identifier", s)
+		}
+		keywords = append(keywords, s)
+	}
+	return ast.NewKeywordMatcher(c.astPos(), keywords), err
+}
+
+func (p *parser) callonKeywordMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onKeywordMatcher1(stack["list"])
+}
+
+func (c *current) onKeywordList1(first, rest interface{}) (interface{}, error) {
+	list := []interface{}{first}
+	for _, v := range toIfaceSlice(rest) {
+		list = append(list, v.([]interface{})[3])
+	}
+	return list, nil
+}
+
+func (p *parser) callonKeywordList1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onKeywordList1(stack["first"], stack["rest"])
+}
+
 func (c *current) onCharClassMatcher2() (interface{}, error) {
 	pos := c.astPos()
 	cc := ast.NewCharClassMatcher(pos, string(c.text))
@@ -3096,14 +3855,14 @@ func (p *parser) callonCharClassMatcher2() (interface{}, error) {
 	return p.cur.onCharClassMatcher2()
 }
 
-func (c *current) onCharClassMatcher15() (interface{}, error) {
+func (c *current) onCharClassMatcher16() (interface{}, error) {
 	return ast.NewCharClassMatcher(c.astPos(), "[]"), errors.New("character class not terminated")
 }
 
-func (p *parser) callonCharClassMatcher15() (interface{}, error) {
+func (p *parser) callonCharClassMatcher16() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onCharClassMatcher15()
+	return p.cur.onCharClassMatcher16()
 }
 
 func (c *current) onCharClassEscape5() (interface{}, error) {
@@ -3162,6 +3921,17 @@ func (p *parser) callonAnyMatcher1() (interface{}, error) {
 	return p.cur.onAnyMatcher1()
 }
 
+func (c *current) onBOLMatcher1() (interface{}, error) {
+	bol := ast.NewBOLMatcher(c.astPos(), "^")
+	return bol, nil
+}
+
+func (p *parser) callonBOLMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBOLMatcher1()
+}
+
 func (c *current) onThrowExpr2(label interface{}) (interface{}, error) {
 	t := ast.NewThrowExpr(c.astPos())
 	t.Label = label.(*ast.Identifier).Val
@@ -3220,9 +3990,56 @@ var (
 
 	// errMaxExprCnt is used to signal that the maximum number of
 	// expressions have been parsed.
-	errMaxExprCnt = errors.New("max number of expresssions parsed")
+	errMaxExprCnt = &LimitError{Kind: LimitExpressions, msg: "max number of expresssions parsed"}
+
+	// errMaxSteps is used to signal that the maximum number of attempts
+	// to match an input with a matcher has been exceeded.
+	errMaxSteps = &LimitError{Kind: LimitSteps, msg: "max number of matcher steps exceeded"}
+
+	// errMaxDepth is used to signal that the maximum depth of nested
+	// rules has been exceeded.
+	errMaxDepth = &LimitError{Kind: LimitDepth, msg: "max depth of nested rules exceeded"}
 )
 
+// LimitKind is the kind of limit that stopped the parsing, see LimitError.
+type LimitKind int
+
+// List of the kinds of limits.
+const (
+	// LimitExpressions is the limit set by the MaxExpressions option.
+	LimitExpressions LimitKind = iota + 1
+	// LimitDepth is the limit set by the MaxDepth option.
+	LimitDepth
+	// LimitSteps is the limit set by the MaxSteps option.
+	LimitSteps
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case LimitExpressions:
+		return "expressions"
+	case LimitDepth:
+		return "depth"
+	case LimitSteps:
+		return "steps"
+	}
+	return "LimitKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// LimitError is the error that stops the parsing when one of the limits
+// set by the MaxExpressions, MaxDepth and MaxSteps options is exceeded, so
+// that this resource-limit abort can be told apart from a syntax error,
+// e.g. with errors.As on the error returned by the Parse* functions.
+type LimitError struct {
+	Kind LimitKind
+	msg  string
+}
+
+// Error returns the error message.
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
 // Option is a function that can set an option on the parser. It returns
 // the previous setting as an Option.
 type Option func(*parser) Option
@@ -3240,6 +4057,69 @@ func MaxExpressions(maxExprCnt uint64) Option {
 	}
 }
 
+// MaxSteps creates an Option to stop parsing with an error once the
+// matchers (literal, character class, any and beginning of line matchers)
+// have been tried more than n times in total. Unlike MaxExpressions, which
+// limits the number of expressions parsed, it accounts for every attempt
+// to match the input, including the ones that fail and the ones repeated
+// after a backtrack, so it bounds the work done on adversarial inputs
+// that force exponential backtracking. If n <= 0, the number of steps is
+// not limited.
+//
+// The default for n is 0.
+func MaxSteps(n int) Option {
+	return func(p *parser) Option {
+		oldMaxSteps := p.maxSteps
+		p.maxSteps = n
+		return MaxSteps(oldMaxSteps)
+	}
+}
+
+// MaxDepth creates an Option to stop parsing with an error once more than
+// n rules are nested, e.g. to bound the recursion on deeply nested inputs.
+// If n <= 0, the depth is not limited.
+//
+// The default for n is 0.
+func MaxDepth(n int) Option {
+	return func(p *parser) Option {
+		oldMaxDepth := p.maxDepth
+		p.maxDepth = n
+		return MaxDepth(oldMaxDepth)
+	}
+}
+
+// OnProgress creates an Option to set a function that is called as the
+// parser advances through the input, e.g. to render a progress bar. It
+// is called with the furthest offset reached in the input, in bytes,
+// since the parser may backtrack, and with the size of the input, or -1
+// while it is unknown when parsing from an io.RuneReader. It is called
+// each time the furthest offset advances by at least the interval set by
+// the ProgressInterval option, and once more when the parsing completes.
+//
+// The default is to not report the progress.
+func OnProgress(f func(offset, total int)) Option {
+	return func(p *parser) Option {
+		oldOnProgress := p.onProgress
+		p.onProgress = f
+		return OnProgress(oldOnProgress)
+	}
+}
+
+// ProgressInterval creates an Option to set the number of bytes by which
+// the furthest offset reached in the input must advance between two calls
+// of the function set by the OnProgress option, so that its overhead
+// stays negligible. If n <= 0, it is called each time the furthest
+// offset advances.
+//
+// The default for n is 4096.
+func ProgressInterval(n int) Option {
+	return func(p *parser) Option {
+		oldInterval := p.progressInterval
+		p.progressInterval = n
+		return ProgressInterval(oldInterval)
+	}
+}
+
 // Entrypoint creates an Option to set the rule name to use as entrypoint.
 // The rule name must have been specified in the -alternate-entrypoints
 // if generating the parser with the -optimize-grammar flag, otherwise
@@ -3258,24 +4138,33 @@ func Entrypoint(ruleName string) Option {
 	}
 }
 
+// ValidEntrypoints returns the names of the rules that may be used as
+// entrypoint with the Entrypoint option, in the order of the grammar.
+func ValidEntrypoints() []string {
+	names := make([]string, 0, len(g.rules))
+	for _, r := range g.rules {
+		names = append(names, r.name)
+	}
+	return names
+}
+
 // Statistics adds a user provided Stats struct to the parser to allow
 // the user to process the results after the parsing has finished.
 // Also the key for the "no match" counter is set.
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -3306,12 +4195,34 @@ func Debug(b bool) Option {
 // once. This guarantees linear parsing time even for pathological cases,
 // at the expense of more memory and slower times for typical cases.
 //
+// The memoized results are never shared across calls to the Parse*
+// functions: the memoization table is empty when the parsing starts, so
+// the results obtained with an entrypoint (see Entrypoint) can't be used
+// when parsing with another one, even if the same input is parsed.
+//
 // The default is false.
 func Memoize(b bool) Option {
+	return memoize(b, false)
+}
+
+// MemoizeRules creates an Option to memoize only the results of the rules
+// selected when the parser was generated. As opposed to Memoize, the
+// results of the other rules and of the expressions are not cached, which
+// trades the linear parsing time guarantee for less memory.
+//
+// By default, the rules that are referenced from at least two places in
+// the grammar are selected, as the rules referenced once never benefit
+// from memoization.
+func MemoizeRules(b bool) Option {
+	return memoize(b, b)
+}
+
+func memoize(b, rules bool) Option {
 	return func(p *parser) Option {
-		old := p.memoize
+		old, oldRules := p.memoize, p.memoizeRules
 		p.memoize = b
-		return Memoize(old)
+		p.memoizeRules = rules
+		return memoize(old, oldRules)
 	}
 }
 
@@ -3321,11 +4232,27 @@ func Memoize(b bool) Option {
 // The returned matched value, c.text and c.offset are NOT affected.
 //
 // The default is false.
-func AllowInvalidUTF8(b bool) Option {
+func AllowInvalidUTF8(b bool) Option {
+	return func(p *parser) Option {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return AllowInvalidUTF8(old)
+	}
+}
+
+// StripBOM creates an Option to skip the UTF-8 byte order mark (U+FEFF)
+// at the start of the input, if any, e.g. for the files saved by some
+// Windows editors. The reported positions are then relative to the content
+// that follows the byte order mark, and it is not part of the matched
+// text. If it is not set, the byte order mark is parsed like any other
+// rune, so that the offsets match the raw bytes of the input.
+//
+// The default is false.
+func StripBOM(b bool) Option {
 	return func(p *parser) Option {
-		old := p.allowInvalidUTF8
-		p.allowInvalidUTF8 = b
-		return AllowInvalidUTF8(old)
+		old := p.stripBOM
+		p.stripBOM = b
+		return StripBOM(old)
 	}
 }
 
@@ -3343,6 +4270,40 @@ func Recover(b bool) Option {
 	}
 }
 
+// PartialResult creates an Option to set the partialResult flag to b.
+// When set to true, the parser returns a partial result along with the
+// error when the entry rule fails, instead of a nil value. The partial
+// result is the list of the values of the elements that matched in the
+// sequence of the entry rule that went the furthest in the input before
+// failing, e.g. the values of the statements that were parsed before the
+// failing one. It is not the value of an action, as the action code is
+// not executed for a failed match.
+//
+// The partial result is best-effort: it is nil if the entry rule fails
+// before any element of a sequence matched, if the entry rule has no
+// sequence, or if the parsing fails because of a panic.
+//
+// The default is false.
+func PartialResult(b bool) Option {
+	return func(p *parser) Option {
+		old := p.partialResult
+		p.partialResult = b
+		return PartialResult(old)
+	}
+}
+
+// Diagnostics creates an Option to collect the diagnostics recorded by the
+// code blocks with current.Warn and current.Diag in diags. The diagnostics
+// are appended to diags as they are recorded, and they do not fail the
+// parse. If this option is not set, the diagnostics are discarded.
+func Diagnostics(diags *[]Diagnostic) Option {
+	return func(p *parser) Option {
+		old := p.diags
+		p.diags = diags
+		return Diagnostics(old)
+	}
+}
+
 // GlobalStore creates an Option to set a key to a certain value in
 // the globalStore.
 func GlobalStore(key string, value interface{}) Option {
@@ -3353,6 +4314,24 @@ func GlobalStore(key string, value interface{}) Option {
 	}
 }
 
+// TransactionalStore creates an Option to tie the values stored under
+// the provided keys in the globalStore to the backtracking of the parser.
+// When an alternative of a choice expression is tried, the values of
+// those keys are saved and, if the alternative does not match, they are
+// restored before the next alternative is tried. Values that implement
+// the Cloner interface are cloned when saved, other values are copied
+// as-is. Only the listed keys are saved, as copying the whole globalStore
+// for every alternative would be expensive.
+//
+// The default is to not restore any key of the globalStore.
+func TransactionalStore(keys ...string) Option {
+	return func(p *parser) Option {
+		old := p.txKeys
+		p.txKeys = keys
+		return TransactionalStore(old...)
+	}
+}
+
 // InitState creates an Option to set a key to a certain value in
 // the global "state" store.
 func InitState(key string, value interface{}) Option {
@@ -3394,6 +4373,56 @@ func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
 	return newParser(filename, b, opts...).parse(g)
 }
 
+// ParseRuneReader parses the runes read from r using filename as information
+// in the error messages. The runes are consumed as the parser advances,
+// so any decoder that produces runes (e.g. from UTF-16 or another encoding)
+// can be used without a separate transcoding pass. The runes read so far
+// are buffered to allow backtracking, and the offsets reported in positions
+// and errors are byte offsets in the UTF-8 encoding of those runes.
+func ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error) { // nolint: deadcode
+	p := newParser(filename, nil, opts...)
+	p.rr = r
+	return p.parse(g)
+}
+
+// ParserPool is a pool of parsers that reuses the memory allocated by a
+// parser for the following parses, which is useful when parsing a lot of
+// small inputs. Each parse starts with a parser that is reset, so that no
+// state, error or memoized result is shared between parses. The zero
+// value is ready to use, and a ParserPool is safe for concurrent use.
+type ParserPool struct {
+	pool sync.Pool
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages, like Parse, with a parser taken from the pool.
+func (pp *ParserPool) Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	p := pp.get(filename, b, opts...)
+	defer pp.put(p)
+	return p.parse(g)
+}
+
+// get returns a parser of the pool, reset to parse the data from b using
+// filename as information in the error messages and configured with the
+// options. The parser must be returned to the pool with put once the
+// parse is done.
+func (pp *ParserPool) get(filename string, b []byte, opts ...Option) *parser {
+	p, ok := pp.pool.Get().(*parser)
+	if !ok {
+		return newParser(filename, b, opts...)
+	}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// put returns the parser p to the pool. It must not be used after that.
+func (pp *ParserPool) put(p *parser) {
+	// do not keep the input and the results alive while in the pool.
+	p.Reset("", nil)
+	pp.pool.Put(p)
+}
+
 // position records a position in the text.
 type position struct {
 	line, col, offset int
@@ -3403,6 +4432,32 @@ func (p position) String() string {
 	return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col) + " [" + strconv.Itoa(p.offset) + "]"
 }
 
+// Span is the range of the input text matched by an expression, e.g. to
+// underline it in error messages. Start is the position of the first rune
+// of the match and End is the position right after the last rune, the
+// columns are counted in runes so multibyte characters count as one column.
+type Span struct {
+	Start position
+	End   position
+}
+
+func (s Span) String() string {
+	return s.Start.String() + "-" + s.End.String()
+}
+
+// Diagnostic is a message recorded by a code block with current.Warn or
+// current.Diag, e.g. to report the use of a deprecated syntax, without
+// failing the parse.
+type Diagnostic struct {
+	Pos      position
+	Severity string
+	Msg      string
+}
+
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Severity + ": " + d.Msg
+}
+
 // savepoint stores all state required to go back to this point in the
 // parser.
 type savepoint struct {
@@ -3413,8 +4468,12 @@ type savepoint struct {
 
 type current struct {
 	pos  position // start position of the match
+	end  position // end position of the match
 	text []byte   // raw text of the match
 
+	// ruleName is the name of the innermost rule being parsed.
+	ruleName string
+
 	// state is a store for arbitrary key,value pairs that the user wants to be
 	// tied to the backtracking of the parser.
 	// This is always rolled back if a parsing rule fails.
@@ -3426,6 +4485,186 @@ type current struct {
 	// rolled back by the parser. It is always up to the user to keep this in a
 	// consistent state.
 	globalStore storeDict
+
+	// parser is the parser that runs the code blocks, used by the helpers
+	// that inspect the input.
+	parser *parser
+}
+
+// Span returns the span of the current match. It is only meaningful in
+// action code blocks.
+func (c *current) Span() Span {
+	return Span{Start: c.pos, End: c.end}
+}
+
+// TextUnsafe returns the raw text of the current match as a subslice of
+// the input, like c.text, but even if c.text was modified by the code
+// block. Neither is a copy of the input: the returned slice must be
+// treated as read-only, and it must not be retained after the parse if
+// the input may be modified or reused. It is only meaningful in action
+// code blocks.
+func (c *current) TextUnsafe() []byte {
+	return c.parser.data[c.pos.offset:c.end.offset]
+}
+
+// Warn records a diagnostic of severity "warning" with the message msg,
+// as Diag does.
+func (c *current) Warn(msg string) {
+	c.Diag("warning", msg)
+}
+
+// Diag records a diagnostic of the given severity with the message msg at
+// the start position of the current match, which is only meaningful in
+// action code blocks. Unlike an error returned by a code block, it does not
+// fail the parse. The diagnostics are collected by the Diagnostics option,
+// and they are not removed if the parser backtracks after the code block
+// ran.
+func (c *current) Diag(severity, msg string) {
+	p := c.parser
+	if p.diags == nil {
+		return
+	}
+	*p.diags = append(*p.diags, Diagnostic{Pos: c.pos, Severity: severity, Msg: msg})
+}
+
+// Mark is a checkpoint of the parser, as returned by c.Mark, that a code
+// block may restore later with c.Restore.
+type Mark struct {
+	parser *parser
+	pt     savepoint
+	state  storeDict
+}
+
+// Offset returns the byte offset of the position of the mark.
+func (m Mark) Offset() int {
+	return m.pt.offset
+}
+
+// Mark returns a checkpoint of the current position of the parser and of
+// the state store, e.g. to implement a custom lookahead or commit logic
+// not expressible in the grammar. In an action code block, the position is
+// the end of the match.
+func (c *current) Mark() Mark {
+	p := c.parser
+	m := Mark{parser: p, pt: p.pt}
+	m.state = p.cloneState()
+	return m
+}
+
+// Restore moves the parser back (or forward) to the position of the mark
+// m, and restores the state store as it was when the mark was created. The
+// parser then continues from that position after the code block returns.
+//
+// The mark must have been created during the same parse. It returns an
+// error if it was created by another parser or beyond the input read so
+// far, but the marks of a previous parse of a parser reused by a
+// ParserPool are not detected. As the changes to the state store made by
+// action and predicate code blocks are always rolled back, the state is
+// only restored by a state change code block. Note that with memoization, the result of
+// a rule whose code blocks call Restore is memoized with the position
+// where the parser ended up, and the code blocks are not run again when
+// the memoized result is used.
+func (c *current) Restore(m Mark) error {
+	p := c.parser
+	if m.parser != p {
+		return errors.New("invalid mark: not created by this parser")
+	}
+	if m.pt.offset > len(p.data) {
+		return errors.New("invalid mark: beyond the input read so far")
+	}
+	p.restoreMark(m)
+	return nil
+}
+
+// restoreMark restores the parser to the mark m.
+func (p *parser) restoreMark(m Mark) {
+	p.restore(m.pt)
+	p.cur.state.Discard()
+	// keep the state of the mark intact so that it may be restored again
+	p.cur.state = m.state
+	p.cur.state = p.cloneState()
+}
+
+// LineIndent returns the width of the leading whitespace of the line of
+// the current position of the parser, where each space and each tab counts
+// for one column. It may be used in predicate and state change code blocks
+// of off-side rule grammars, even before the leading whitespace is matched.
+func (c *current) LineIndent() int {
+	p := c.parser
+	start := p.pt.offset
+	for start > 0 && p.data[start-1] != '\n' {
+		start--
+	}
+	n := 0
+	for {
+		if start+n >= len(p.data) {
+			if p.rr == nil {
+				break
+			}
+			// read ahead from the rune reader, without moving the parser.
+			p.readRune()
+			continue
+		}
+		if b := p.data[start+n]; b != ' ' && b != '\t' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// indentKey is the key of the indentation stack in the state store.
+const indentKey = "pigeon.indent"
+
+// indentStack returns the indentation stack stored in the state.
+func (c *current) indentStack() []int {
+	stack, _ := c.state[indentKey].([]int)
+	return stack
+}
+
+// PushIndent pushes the indentation level n on the indentation stack. As
+// the stack is kept in the state store, it must be called from a state
+// change code block, and it is rolled back if the rule fails.
+func (c *current) PushIndent(n int) {
+	stack := c.indentStack()
+	// copy the stack so that the saved states are not modified.
+	c.state[indentKey] = append(stack[:len(stack):len(stack)], n)
+}
+
+// PopIndent pops the indentation level at the top of the indentation
+// stack and returns it, or returns 0 if the stack is empty. Like
+// PushIndent, it must be called from a state change code block.
+func (c *current) PopIndent() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	c.state[indentKey] = stack[: len(stack)-1 : len(stack)-1]
+	return stack[len(stack)-1]
+}
+
+// IndentLevel returns the indentation level at the top of the
+// indentation stack, or 0 if the stack is empty.
+func (c *current) IndentLevel() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	return stack[len(stack)-1]
+}
+
+// SameIndent returns true if the line of the current position is indented
+// at the level at the top of the indentation stack, e.g. for a predicate
+// such as &{ return c.SameIndent(), nil }.
+func (c *current) SameIndent() bool {
+	return c.LineIndent() == c.IndentLevel()
+}
+
+// MoreIndented returns true if the line of the current position is
+// indented more than the level at the top of the indentation stack, i.e.
+// if it starts a new indented block.
+func (c *current) MoreIndented() bool {
+	return c.LineIndent() > c.IndentLevel()
 }
 
 type storeDict map[string]interface{}
@@ -3444,6 +4683,9 @@ type rule struct {
 	name        string
 	displayName string
 	expr        interface{}
+	// memoize is set if the results of the rule are memoized with the
+	// MemoizeRules option.
+	memoize bool
 }
 
 // nolint: structcheck
@@ -3451,6 +4693,7 @@ type choiceExpr struct {
 	pos          position
 	alternatives []interface{}
 	skipVals     bool
+	longest      bool
 }
 
 // nolint: structcheck
@@ -3472,7 +4715,9 @@ type recoveryExpr struct {
 type seqExpr struct {
 	pos   position
 	exprs []interface{}
-	vals  []interface{}
+	// 1-based index of the values buffer of the parser that is reused
+	// for the values of the sequence, 0 if the buffer is not reused.
+	valsIx int
 }
 
 // nolint: structcheck
@@ -3548,6 +4793,8 @@ type charClassMatcher struct {
 
 type anyMatcher position // nolint: structcheck
 
+type bolMatcher position // nolint: structcheck
+
 // errList cumulates the errors found by the parser.
 type errList []error
 
@@ -3575,6 +4822,19 @@ func (e *errList) dedupe() {
 	*e = cleaned
 }
 
+// FurthestPos returns the furthest position where the parser failed to
+// match, among the errors of the list, e.g. to show where the parsing gave
+// up.
+func (e errList) FurthestPos() position {
+	var pos position
+	for _, err := range e {
+		if pe, ok := err.(*parserError); ok && pe.furthest.offset >= pos.offset {
+			pos = pe.furthest
+		}
+	}
+	return pos
+}
+
 func (e errList) Error() string {
 	switch len(e) {
 	case 0:
@@ -3594,11 +4854,24 @@ func (e errList) Error() string {
 	}
 }
 
+// As finds the first error in the list that matches target, as defined by
+// errors.As, and sets target to that error.
+func (e errList) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // parserError wraps an error with a prefix indicating the rule in which
 // the error occurred. The original error is stored in the Inner field.
 type parserError struct {
 	Inner    error
 	pos      position
+	span     Span
+	furthest position
 	prefix   string
 	expected []string
 }
@@ -3608,35 +4881,86 @@ func (p *parserError) Error() string {
 	return p.prefix + ": " + p.Inner.Error()
 }
 
+// Unwrap returns the original error.
+func (p *parserError) Unwrap() error {
+	return p.Inner
+}
+
+// Span returns the span of the input text that caused the error. For
+// errors returned by action code blocks, it is the span matched by the
+// action's expression, and for a failed match it is the rune where the
+// parser failed to match (empty at the end of the input).
+func (p *parserError) Span() Span {
+	return p.span
+}
+
+// FurthestPos returns the furthest position where the parser failed to
+// match when the error occurred. For the errors that occur while a
+// failure is recovered, it is the furthest position when the failure was
+// thrown.
+func (p *parserError) FurthestPos() position {
+	return p.furthest
+}
+
 // newParser creates a parser with the specified input source and options.
 func newParser(filename string, b []byte, opts ...Option) *parser {
-	stats := Stats{
-		ChoiceAltCnt: make(map[string]map[string]int),
+	p := &parser{}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// Reset resets the parser so that it parses the data from b using
+// filename as information in the error messages, as if it was newly
+// created without any option. The errors, the statistics, the state,
+// the global store and the memoization table of the previous parse are
+// all cleared, but the memory allocated for them is reused when it is
+// safe to do so. The memoization table is always rebuilt by parse.
+func (p *parser) Reset(filename string, b []byte) {
+	state := p.cur.state
+	if state == nil {
+		state = make(storeDict)
+	}
+	for k := range state {
+		delete(state, k)
+	}
+	globalStore := p.cur.globalStore
+	if globalStore == nil {
+		globalStore = make(storeDict)
+	}
+	for k := range globalStore {
+		delete(globalStore, k)
 	}
 
-	p := &parser{
+	*p = parser{
 		filename: filename,
-		errs:     new(errList),
-		data:     b,
-		pt:       savepoint{position: position{line: 1}},
-		recover:  true,
+		// the errors are returned to the caller, so they are never reused.
+		errs: new(errList),
+		data: b,
+		pt:   savepoint{position: position{line: 1}},
 		cur: current{
-			state:       make(storeDict),
-			globalStore: make(storeDict),
+			state:       state,
+			globalStore: globalStore,
+		},
+		recover:          true,
+		vstack:           p.vstack[:0],
+		rstack:           p.rstack[:0],
+		maxFailPos:       position{col: 1, line: 1},
+		maxFailExpected:  p.maxFailExpected[:0],
+		maxExprCnt:       math.MaxUint64,
+		progressInterval: 4096,
+		Stats: &Stats{
+			ChoiceAltCnt: make(map[string]map[string]int),
 		},
-		maxFailPos:      position{col: 1, line: 1},
-		maxFailExpected: make([]string, 0, 20),
-		Stats:           &stats,
 		// start rule is rule [0] unless an alternate entrypoint is specified
-		entrypoint: g.rules[0].name,
+		entrypoint:      g.rules[0].name,
+		recoveryStack:   p.recoveryStack[:0],
+		recoveryFailPos: p.recoveryFailPos[:0],
 	}
-	p.setOptions(opts)
-
-	if p.maxExprCnt == 0 {
-		p.maxExprCnt = math.MaxUint64
+	p.cur.parser = p
+	if p.maxFailExpected == nil {
+		p.maxFailExpected = make([]string, 0, 20)
 	}
-
-	return p
 }
 
 // setOptions applies the options to the parser.
@@ -3644,6 +4968,10 @@ func (p *parser) setOptions(opts []Option) {
 	for _, opt := range opts {
 		opt(p)
 	}
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
 }
 
 // nolint: structcheck,deadcode
@@ -3663,6 +4991,11 @@ type Stats struct {
 	// (set by the MaxExpressions option).
 	ExprCnt uint64
 
+	// Steps counts the number of attempts to match the input with a
+	// matcher, including the failed ones. This value is compared to the
+	// maximum number of steps allowed (set by the MaxSteps option).
+	Steps uint64
+
 	// ChoiceAltCnt is used to count for each ordered choice expression,
 	// which alternative is used how may times.
 	// These numbers allow to optimize the order of the ordered choice expression
@@ -3678,6 +5011,40 @@ type Stats struct {
 	ChoiceAltCnt map[string]map[string]int
 }
 
+// ChoiceStat is the number of matches of an alternative of an ordered
+// choice expression, as counted in Stats.ChoiceAltCnt.
+type ChoiceStat struct {
+	// Choice is the outer key of ChoiceAltCnt, composed of the name of the
+	// rule and the line and column of the ordered choice.
+	Choice string
+	// Alternative is the inner key of ChoiceAltCnt, the number (one-based)
+	// of the alternative or the name of the "no match" counter.
+	Alternative string
+	// Count is the number of matches of the alternative.
+	Count int
+}
+
+// SortedRules returns the entries of ChoiceAltCnt in a deterministic order:
+// sorted by choice, then by descending count and then by alternative.
+func (s *Stats) SortedRules() []ChoiceStat {
+	var stats []ChoiceStat
+	for choice, alts := range s.ChoiceAltCnt {
+		for alt, cnt := range alts {
+			stats = append(stats, ChoiceStat{Choice: choice, Alternative: alt, Count: cnt})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Choice != stats[j].Choice {
+			return stats[i].Choice < stats[j].Choice
+		}
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Alternative < stats[j].Alternative
+	})
+	return stats
+}
+
 // nolint: structcheck,maligned
 type parser struct {
 	filename string
@@ -3687,11 +5054,19 @@ type parser struct {
 	data []byte
 	errs *errList
 
+	// rune source for ParseRuneReader, the runes read from it are
+	// appended to data as UTF-8 so that the parser can backtrack.
+	// It is set to nil once it is exhausted.
+	rr io.RuneReader
+
 	depth   int
 	recover bool
 	debug   bool
 
 	memoize bool
+	// if set, only the results of the rules with the memoize field set
+	// are memoized
+	memoizeRules bool
 	// memoization table for the packrat algorithm:
 	// map[offset in source] map[expression or rule] {value, match}
 	memo map[int]map[interface{}]resultTuple
@@ -3708,18 +5083,50 @@ type parser struct {
 	maxFailExpected       []string
 	maxFailInvertExpected bool
 
+	// keys of the globalStore tied to the backtracking of the parser
+	txKeys []string
+
 	// max number of expressions to be parsed
 	maxExprCnt uint64
+	// max number of attempts to match with a matcher, if > 0
+	maxSteps int
+	// max number of nested rules, if > 0
+	maxDepth int
+
+	// if set, called as the furthest offset reached advances
+	onProgress func(offset, total int)
+	// min advance of the furthest offset between two calls of onProgress
+	progressInterval int
+	// furthest offset reached, and offset from which onProgress is called
+	progressOffset int
+	progressNext   int
 	// entrypoint for the parser
 	entrypoint string
 
 	allowInvalidUTF8 bool
+	// if set, a leading byte order mark is skipped
+	stripBOM bool
+
+	// if set, the diagnostics recorded by the code blocks are appended to it
+	diags *[]Diagnostic
+
+	// if set, the partial result is returned when the parsing fails
+	partialResult bool
+	// values of the failed sequence of the entry rule that matched the
+	// furthest, and the offset where its last matched element ends
+	partial    []interface{}
+	partialEnd int
 
 	*Stats
 
 	choiceNoMatch string
 	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
 	recoveryStack []map[string]interface{}
+	// furthest failure positions of the failures being recovered
+	recoveryFailPos []position
+	// values buffers reused by the sequences, by index, so that they are
+	// not shared between parsers
+	seqVals [][]interface{}
 }
 
 // push a variable set on the vstack.
@@ -3804,6 +5211,11 @@ func (p *parser) addErr(err error) {
 }
 
 func (p *parser) addErrAt(err error, pos position, expected []string) {
+	p.addErrSpan(err, Span{Start: pos, End: pos}, expected)
+}
+
+func (p *parser) addErrSpan(err error, span Span, expected []string) {
+	pos := span.Start
 	var buf bytes.Buffer
 	if p.filename != "" {
 		buf.WriteString(p.filename)
@@ -3823,7 +5235,11 @@ func (p *parser) addErrAt(err error, pos position, expected []string) {
 			buf.WriteString("rule " + rule.name)
 		}
 	}
-	pe := &parserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	furthest := p.maxFailPos
+	if n := len(p.recoveryFailPos); n > 0 {
+		furthest = p.recoveryFailPos[n-1]
+	}
+	pe := &parserError{Inner: err, pos: pos, span: span, furthest: furthest, prefix: buf.String(), expected: expected}
 	p.errs.add(pe)
 }
 
@@ -3847,9 +5263,34 @@ func (p *parser) failAt(fail bool, pos position, want string) {
 	}
 }
 
+// failRule reports the failure of the rule that started at pos using its
+// display name, instead of the expected values of its expression, if the
+// rule did not match past its starting position. The failPos and failLen
+// are the farthest failure position and the number of expected values
+// when the rule started.
+func (p *parser) failRule(rule *rule, pos, failPos position, failLen int) {
+	if p.maxFailInvertExpected || p.maxFailPos.offset > pos.offset {
+		return
+	}
+	if p.maxFailPos.offset == pos.offset {
+		if failPos.offset != pos.offset {
+			failLen = 0
+		}
+		p.maxFailExpected = p.maxFailExpected[:failLen]
+	}
+	// the display name is the raw string literal of the grammar
+	p.failAt(false, pos, rule.displayName[1:len(rule.displayName)-1])
+}
+
 // read advances the parser to the next rune.
 func (p *parser) read() {
 	p.pt.offset += p.pt.w
+	if p.rr != nil && p.pt.offset >= len(p.data) {
+		p.readRune()
+	}
+	if p.onProgress != nil && p.pt.offset > p.progressOffset {
+		p.progress()
+	}
 	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
 	p.pt.rn = rn
 	p.pt.w = n
@@ -3866,6 +5307,52 @@ func (p *parser) read() {
 	}
 }
 
+// progress records the current offset as the furthest offset reached,
+// and calls onProgress if it advanced enough since the previous call.
+func (p *parser) progress() {
+	p.progressOffset = p.pt.offset
+	if p.progressOffset >= p.progressNext {
+		p.onProgress(p.progressOffset, p.progressTotal())
+		p.progressNext = p.progressOffset + p.progressInterval
+	}
+}
+
+// progressTotal returns the size of the input, or -1 if it is not known
+// yet.
+func (p *parser) progressTotal() int {
+	if p.rr != nil {
+		return -1
+	}
+	return len(p.data)
+}
+
+// readRune reads the next rune from the rune reader and appends it to
+// the buffered data.
+func (p *parser) readRune() {
+	rn, _, err := p.rr.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			p.addErr(err)
+		}
+		p.rr = nil
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], rn)
+	p.data = append(p.data, buf[:n]...)
+}
+
+// skipBOM removes the UTF-8 byte order mark at the start of the input, if
+// any, so that the positions are relative to the content that follows it.
+func (p *parser) skipBOM() {
+	if p.rr != nil && len(p.data) == 0 {
+		p.readRune()
+	}
+	if rn, n := utf8.DecodeRune(p.data); rn == '\uFEFF' {
+		p.data = p.data[n:]
+	}
+}
+
 // restore parser position to the savepoint pt.
 func (p *parser) restore(pt savepoint) {
 	if p.debug {
@@ -3881,13 +5368,43 @@ func (p *parser) restore(pt savepoint) {
 // copy of the value. This is mainly used for types which are not passed by
 // value (e.g map, slice, chan) or structs that contain such types.
 //
-// This is used in conjunction with the global state feature to create proper
-// copies of the state to allow the parser to properly restore the state in
-// the case of backtracking.
+// This is used in conjunction with the global state feature and the
+// TransactionalStore option to create proper copies of the state to allow
+// the parser to properly restore the state in the case of backtracking.
 type Cloner interface {
 	Clone() interface{}
 }
 
+// snapshotGlobalStore returns a copy of the transactional keys of the
+// globalStore.
+func (p *parser) snapshotGlobalStore() storeDict {
+	snapshot := make(storeDict, len(p.txKeys))
+	for _, k := range p.txKeys {
+		v, ok := p.cur.globalStore[k]
+		if !ok {
+			continue
+		}
+		if c, ok := v.(Cloner); ok {
+			snapshot[k] = c.Clone()
+		} else {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// restoreGlobalStore restores the transactional keys of the globalStore
+// to the values of the snapshot.
+func (p *parser) restoreGlobalStore(snapshot storeDict) {
+	for _, k := range p.txKeys {
+		if v, ok := snapshot[k]; ok {
+			p.cur.globalStore[k] = v
+		} else {
+			delete(p.cur.globalStore, k)
+		}
+	}
+}
+
 var statePool = &sync.Pool{
 	New: func() interface{} { return make(storeDict) },
 }
@@ -3926,6 +5443,18 @@ func (p *parser) restoreState(state storeDict) {
 	p.cur.state = state
 }
 
+// runeSpan returns the span of the rune at pos, or an empty span at pos
+// if it is at the end of the input.
+func (p *parser) runeSpan(pos position) Span {
+	end := pos
+	if pos.offset < len(p.data) {
+		_, n := utf8.DecodeRune(p.data[pos.offset:])
+		end.offset += n
+		end.col++
+	}
+	return Span{Start: pos, End: end}
+}
+
 // get the slice of bytes from the savepoint start to the current position.
 func (p *parser) sliceFrom(start savepoint) []byte {
 	return p.data[start.position.offset:p.pt.position.offset]
@@ -3972,6 +5501,11 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 	// TODO : not super critical but this could be generated
 	p.buildRulesTable(g)
 
+	// the memoized results depend on the entrypoint, as the rules may
+	// behave differently depending on the state set by the entry rule,
+	// so they are never reused across parses.
+	p.memo = nil
+
 	if p.recover {
 		// panic can be used in action code to stop parsing immediately
 		// and return the panic as an error.
@@ -3998,37 +5532,63 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 		return nil, p.errs.err()
 	}
 
+	if p.stripBOM {
+		p.skipBOM()
+	}
 	p.read() // advance to first rune
 	val, ok = p.parseRule(startRule)
+	if p.onProgress != nil {
+		p.onProgress(p.progressOffset, p.progressTotal())
+	}
 	if !ok {
 		if len(*p.errs) == 0 {
 			// If parsing fails, but no errors have been recorded, the expected values
 			// for the farthest parser position are returned as error.
-			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
-			for _, v := range p.maxFailExpected {
-				maxFailExpectedMap[v] = struct{}{}
-			}
-			expected := make([]string, 0, len(maxFailExpectedMap))
-			eof := false
-			if _, ok := maxFailExpectedMap["!."]; ok {
-				delete(maxFailExpectedMap, "!.")
-				eof = true
-			}
-			for k := range maxFailExpectedMap {
-				expected = append(expected, k)
-			}
-			sort.Strings(expected)
-			if eof {
-				expected = append(expected, "EOF")
-			}
-			p.addErrAt(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.maxFailPos, expected)
+			p.addNoMatchErr()
 		}
 
+		if p.partial != nil {
+			return p.partial, p.errs.err()
+		}
 		return nil, p.errs.err()
 	}
 	return val, p.errs.err()
 }
 
+// addNoMatchErr adds the "no match found" error, with the values expected
+// at the farthest position where the parser failed.
+func (p *parser) addNoMatchErr() {
+	maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+	for _, v := range p.maxFailExpected {
+		maxFailExpectedMap[v] = struct{}{}
+	}
+	expected := make([]string, 0, len(maxFailExpectedMap))
+	eof := false
+	if _, ok := maxFailExpectedMap["!."]; ok {
+		delete(maxFailExpectedMap, "!.")
+		eof = true
+	}
+	for k := range maxFailExpectedMap {
+		expected = append(expected, k)
+	}
+	sort.Strings(expected)
+	if eof {
+		expected = append(expected, "EOF")
+	}
+	p.addErrSpan(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.runeSpan(p.maxFailPos), expected)
+}
+
+// setPartial records the values of the elements of a sequence of the
+// entry rule that matched before the sequence failed, if it matched
+// further than the previously recorded ones.
+func (p *parser) setPartial(vals []interface{}) {
+	if len(p.rstack) != 1 || p.pt.offset <= p.partialEnd {
+		return
+	}
+	p.partial = append([]interface{}(nil), vals...)
+	p.partialEnd = p.pt.offset
+}
+
 func listJoin(list []string, sep string, lastSep string) string {
 	switch len(list) {
 	case 0:
@@ -4045,7 +5605,8 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 		defer p.out(p.in("parseRule " + rule.name))
 	}
 
-	if p.memoize {
+	memoize := p.memoize && (!p.memoizeRules || rule.memoize)
+	if memoize {
 		res, ok := p.getMemoized(rule)
 		if ok {
 			p.restore(res.end)
@@ -4054,16 +5615,27 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 	}
 
 	start := p.pt
+	failPos, failLen := p.maxFailPos, len(p.maxFailExpected)
 	p.rstack = append(p.rstack, rule)
+	if p.maxDepth > 0 && len(p.rstack) > p.maxDepth {
+		panic(errMaxDepth)
+	}
+	ruleName := p.cur.ruleName
+	p.cur.ruleName = rule.name
 	p.pushV()
 	val, ok := p.parseExpr(rule.expr)
 	p.popV()
+	p.cur.ruleName = ruleName
 	p.rstack = p.rstack[:len(p.rstack)-1]
 	if ok && p.debug {
 		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
 	}
 
-	if p.memoize {
+	if !ok && rule.displayName != "" {
+		p.failRule(rule, start.position, failPos, failLen)
+	}
+
+	if memoize {
 		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
 	}
 	return val, ok
@@ -4073,7 +5645,8 @@ func (p *parser) parseRule(rule *rule) (interface{}, bool) {
 func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	var pt savepoint
 
-	if p.memoize {
+	memoize := p.memoize && !p.memoizeRules
+	if memoize {
 		res, ok := p.getMemoized(expr)
 		if ok {
 			p.restore(res.end)
@@ -4098,6 +5671,8 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 		val, ok = p.parseAndExpr(expr)
 	case *anyMatcher:
 		val, ok = p.parseAnyMatcher(expr)
+	case *bolMatcher:
+		val, ok = p.parseBOLMatcher(expr)
 	case *charClassMatcher:
 		val, ok = p.parseCharClassMatcher(expr)
 	case *choiceExpr:
@@ -4129,7 +5704,7 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	default:
 		panic(fmt.Sprintf("unknown expression type %T", expr))
 	}
-	if p.memoize {
+	if memoize {
 		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
 	}
 	return val, ok
@@ -4144,11 +5719,15 @@ func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 	val, ok := p.parseExpr(act.expr)
 	if ok {
 		p.cur.pos = start.position
-		p.cur.text = p.sliceFrom(start)
+		p.cur.end = p.pt.position
+		// the capacity is limited so that appending to c.text does not
+		// overwrite the input that follows the match
+		text := p.sliceFrom(start)
+		p.cur.text = text[:len(text):len(text)]
 		state := p.cloneState()
 		actVal, err := act.run(p)
 		if err != nil {
-			p.addErrAt(err, start.position, []string{})
+			p.addErrSpan(err, Span{Start: start.position, End: p.pt.position}, []string{})
 		}
 		p.restoreState(state)
 
@@ -4192,11 +5771,21 @@ func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
 	return nil, ok
 }
 
+// step counts an attempt to match the input with a matcher, and stops
+// the parsing if the maximum number of steps is exceeded.
+func (p *parser) step() {
+	p.Steps++
+	if p.maxSteps > 0 && p.Steps > uint64(p.maxSteps) {
+		panic(errMaxSteps)
+	}
+}
+
 func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseAnyMatcher"))
 	}
 
+	p.step()
 	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
 		// EOF - see utf8.DecodeRune
 		p.failAt(false, p.pt.position, ".")
@@ -4208,12 +5797,28 @@ func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
+func (p *parser) parseBOLMatcher(bol *bolMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseBOLMatcher"))
+	}
+
+	p.step()
+	// the previous byte is always available, even when parsing from a rune
+	// reader, as the runes read are kept in data for backtracking.
+	if p.pt.offset == 0 || p.data[p.pt.offset-1] == '\n' {
+		return nil, true
+	}
+	p.failAt(false, p.pt.position, "^")
+	return nil, false
+}
+
 // nolint: gocyclo
 func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseCharClassMatcher"))
 	}
 
+	p.step()
 	cur := p.pt.rn
 	start := p.pt
 
@@ -4295,11 +5900,18 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 		defer p.out(p.in("parseChoiceExpr"))
 	}
 
+	if ch.longest {
+		return p.parseLongestChoiceExpr(ch)
+	}
 	for altI, alt := range ch.alternatives {
 		// dummy assignment to prevent compile error if optimized
 		_ = altI
 
 		state := p.cloneState()
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
 
 		if !ch.skipVals {
 			p.pushV()
@@ -4313,11 +5925,78 @@ func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
 			return val, ok
 		}
 		p.restoreState(state)
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
 	}
 	p.incChoiceAltCnt(ch, choiceNoMatch)
 	return nil, false
 }
 
+// parseLongestChoiceExpr tries all the alternatives of the choice, from
+// the same starting point, and keeps the one that consumes the most input,
+// the first one in case of a tie. The state and the global store are
+// those left by the kept alternative.
+func (p *parser) parseLongestChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLongestChoiceExpr"))
+	}
+
+	start := p.pt
+	var (
+		bestVal interface{}
+		bestPt  savepoint
+		bestAlt = -1
+
+		bestSnapshot storeDict
+	)
+	var bestState storeDict
+
+	for altI, alt := range ch.alternatives {
+		state := p.cloneState()
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
+
+		if !ch.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(alt)
+		if !ch.skipVals {
+			p.popV()
+		}
+		if ok && (bestAlt < 0 || p.pt.offset > bestPt.offset) {
+			bestVal, bestPt, bestAlt = val, p.pt, altI
+			if bestState != nil {
+				bestState.Discard()
+			}
+			bestState, p.cur.state = p.cur.state, state
+			if snapshot != nil {
+				bestSnapshot = p.snapshotGlobalStore()
+			}
+		} else {
+			p.restoreState(state)
+		}
+		p.restore(start)
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
+	}
+
+	if bestAlt < 0 {
+		p.incChoiceAltCnt(ch, choiceNoMatch)
+		return nil, false
+	}
+	p.incChoiceAltCnt(ch, bestAlt)
+	p.restoreState(bestState)
+	if bestSnapshot != nil {
+		p.restoreGlobalStore(bestSnapshot)
+	}
+	p.restore(bestPt)
+	return bestVal, true
+}
+
 func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseLabeledExpr"))
@@ -4338,6 +6017,7 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
+	p.step()
 	if lit.invert {
 		p.maxFailInvertExpected = !p.maxFailInvertExpected
 	}
@@ -4345,10 +6025,7 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
-		if lit.ignoreCase {
-			cur = unicode.ToLower(cur)
-		}
-		if cur != want {
+		if cur != want && !(lit.ignoreCase && equalFold(cur, want)) {
 			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
@@ -4363,6 +6040,20 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 	return p.sliceFrom(start), true
 }
 
+// equalFold returns true if the runes r and s are equal under simple
+// Unicode case folding, as in strings.EqualFold.
+func equalFold(r, s rune) bool {
+	if r == s {
+		return true
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseNotCodeExpr"))
@@ -4460,14 +6151,17 @@ func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
 	pt := p.pt
 	state := p.cloneState()
 	var vals []interface{}
-	if seq.vals != nil {
-		vals = seq.vals
+	if seq.valsIx > 0 {
+		vals = p.reusedVals(seq)
 	} else {
 		vals = make([]interface{}, len(seq.exprs))
 	}
 	for i, expr := range seq.exprs {
 		val, ok := p.parseExpr(expr)
 		if !ok {
+			if p.partialResult && i > 0 {
+				p.setPartial(vals[:i])
+			}
 			p.restoreState(state)
 			p.restore(pt)
 			return nil, false
@@ -4477,6 +6171,20 @@ func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
 	return vals, true
 }
 
+// reusedVals returns the values buffer of the parser that is reused for
+// the values of the sequence seq, which is allocated on first use.
+func (p *parser) reusedVals(seq *seqExpr) []interface{} {
+	for len(p.seqVals) < seq.valsIx {
+		p.seqVals = append(p.seqVals, nil)
+	}
+	vals := p.seqVals[seq.valsIx-1]
+	if vals == nil {
+		vals = make([]interface{}, len(seq.exprs))
+		p.seqVals[seq.valsIx-1] = vals
+	}
+	return vals
+}
+
 func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseStateCodeExpr"))
@@ -4494,6 +6202,11 @@ func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
 		defer p.out(p.in("parseThrowExpr"))
 	}
 
+	p.recoveryFailPos = append(p.recoveryFailPos, p.maxFailPos)
+	defer func() {
+		p.recoveryFailPos = p.recoveryFailPos[:len(p.recoveryFailPos)-1]
+	}()
+
 	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
 		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
 			if val, ok := p.parseExpr(recoverExpr); ok {
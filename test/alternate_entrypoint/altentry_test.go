@@ -1,6 +1,7 @@
 package altentry
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -11,9 +12,9 @@ func TestValidEntrypoints(t *testing.T) {
 		entrypoint string
 	}{
 		{"aacc", ""},
-		{"bbbcc", "Entry2"},
-		{"cc", "Entry3"},
-		{"cc", "C"},
+		{"bbbcc", RuleEntry2},
+		{"cc", RuleEntry3},
+		{"cc", RuleC},
 	}
 
 	for _, c := range cases {
@@ -29,6 +30,14 @@ func TestValidEntrypoints(t *testing.T) {
 	}
 }
 
+func TestValidEntrypointsList(t *testing.T) {
+	// rules A and B are optimized away
+	want := []string{RuleEntry1, RuleEntry2, RuleEntry3, RuleC}
+	if got := ValidEntrypoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
 func TestInvalidEntrypoints(t *testing.T) {
 	cases := []struct {
 		in         string
@@ -0,0 +1,38 @@
+package astral
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAstralRanges(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []interface{}
+	}{
+		{in: "😀🙏", want: []interface{}{"emoji:😀🙏"}},
+		{in: "\U00020000𠀁\U0002A6DF", want: []interface{}{"cjk:\U00020000𠀁\U0002A6DF"}},
+		{in: "＀\U00010000\U00010100", want: []interface{}{"crossing:＀", "crossing:\U00010000", "crossing:\U00010100"}},
+		{in: "\U00010400\U00010428", want: []interface{}{"deseret:\U00010400", "deseret:\U00010428"}},
+		{in: "a😀", want: []interface{}{"bmp:a", "emoji:😀"}},
+		{in: "\U0001F650"},
+		{in: "\U0002A6E0"},
+		{in: "\U00010101"},
+	}
+	for _, tc := range cases {
+		got, err := Parse("", []byte(tc.in))
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%q: want error, got %v", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.([]interface{})[0], tc.want) {
+			t.Errorf("%q: want %v, got %v", tc.in, tc.want, got)
+		}
+	}
+}
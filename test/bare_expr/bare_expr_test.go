@@ -0,0 +1,29 @@
+package bareexpr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBareExpr(t *testing.T) {
+	cases := []struct {
+		in   string
+		want interface{}
+		err  bool
+	}{
+		{in: "a", want: []interface{}{"a"}},
+		{in: "ab cde fghij", want: []interface{}{"ab", "cde", "fghij"}},
+		{in: "abcdef", err: true},
+		{in: "a error b", err: true},
+	}
+	for _, tc := range cases {
+		got, err := Parse("", []byte(tc.in))
+		if (err != nil) != tc.err {
+			t.Errorf("%q: want error %t, got %v", tc.in, tc.err, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: want %#v, got %#v", tc.in, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package bol
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBOLMatcher(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "# a", want: []string{"a"}},
+		{in: "# a\nb # c\n#d", want: []string{"a", "d"}},
+		{in: "x# a\n\n# b\n", want: []string{"b"}},
+		{in: "\r\n# a", want: []string{"a"}},
+		{in: " # a", want: nil},
+	}
+	for _, tc := range cases {
+		got, err := Parse("", []byte(tc.in))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.([]string), tc.want) {
+			t.Errorf("%q: want %q, got %q", tc.in, tc.want, got)
+		}
+
+		// the previous byte is available when parsing from a rune reader
+		got, err = ParseRuneReader("", strings.NewReader(tc.in))
+		if err != nil {
+			t.Errorf("%q: want no error from rune reader, got %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.([]string), tc.want) {
+			t.Errorf("%q: want %q from rune reader, got %q", tc.in, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package bom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripBOM(t *testing.T) {
+	cases := []struct {
+		in    string
+		strip bool
+		want  []interface{}
+	}{
+		{"ab\ncd", false, []interface{}{"1:1:0", "2:1:3"}},
+		{"ab\ncd", true, []interface{}{"1:1:0", "2:1:3"}},
+		// without the option, the BOM is matched like any other rune
+		{"\uFEFFab\ncd", false, []interface{}{"1:2:3", "2:1:6"}},
+		{"\uFEFFab\ncd", true, []interface{}{"1:1:0", "2:1:3"}},
+		// only a leading BOM is skipped
+		{"\uFEFF\uFEFFab", true, []interface{}{"1:2:3"}},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.in), StripBOM(c.strip))
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: want %v, got %v", c.in, c.want, got)
+		}
+
+		got, err = ParseRuneReader("", strings.NewReader(c.in), StripBOM(c.strip))
+		if err != nil {
+			t.Errorf("%q: rune reader: %v", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: rune reader: want %v, got %v", c.in, c.want, got)
+		}
+	}
+}
+
+func TestStripBOMError(t *testing.T) {
+	cases := []struct {
+		strip bool
+		want  string
+	}{
+		{false, "1:4 (5): no match found"},
+		{true, "1:3 (2): no match found"},
+	}
+	for _, c := range cases {
+		_, err := Parse("", []byte("\uFEFFab1"), StripBOM(c.strip))
+		if err == nil {
+			t.Fatalf("strip %t: want error", c.strip)
+		}
+		if !strings.HasPrefix(err.Error(), c.want) {
+			t.Errorf("strip %t: want error %q, got %q", c.strip, c.want, err)
+		}
+	}
+}
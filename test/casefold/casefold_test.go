@@ -0,0 +1,43 @@
+package casefold
+
+import "testing"
+
+func TestCaseFold(t *testing.T) {
+	cases := []struct {
+		rule string
+		in   string
+		ok   bool
+	}{
+		{"German", "straße", true},
+		{"German", "STRAßE", true},
+		{"German", "Straße", true},
+		{"German", "STRAẞE", true}, // capital sharp s
+		// one-to-many folds are not supported
+		{"German", "STRASSE", false},
+		{"German", "strasse", false},
+
+		{"Turkish", "İstanbul", true},
+		{"Turkish", "İSTANBUL", true},
+		// the Turkish-specific mappings of the dotted and dotless i are not
+		// part of the simple case folding.
+		{"Turkish", "istanbul", false},
+		{"Turkish", "ISTANBUL", false},
+		{"Turkish", "ıstanbul", false},
+
+		{"Accented", "crème brûlée", true},
+		{"Accented", "CRÈME BRÛLÉE", true},
+		{"Accented", "Crème BRÛLÉE", true},
+		{"Accented", "CREME BRULEE", false},
+		{"Accented", "crème brûlé", false},
+
+		{"Kelvin", "k", true},
+		{"Kelvin", "K", true},
+		{"Kelvin", "K", true}, // Kelvin sign
+	}
+	for _, c := range cases {
+		_, err := Parse("", []byte(c.in), Entrypoint(c.rule))
+		if (err == nil) != c.ok {
+			t.Errorf("%s: %q: want match %t, got error %v", c.rule, c.in, c.ok, err)
+		}
+	}
+}
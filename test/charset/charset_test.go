@@ -0,0 +1,32 @@
+package charset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCharset(t *testing.T) {
+	got, err := Parse("", []byte("a,_b-1,Z9-x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "_b-1", "Z9-x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestCharsetError(t *testing.T) {
+	_, err := Parse("", []byte("a,1"))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	// the expanded classes are used in the error messages
+	if want := "expected: [a-zA-Z_]"; !strings.Contains(err.Error(), want) {
+		t.Errorf("want %q in error, got %v", want, err)
+	}
+	_, err = Parse("", []byte("a!"))
+	if want := `expected: ",", [a-zA-Z_0-9-] or EOF`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("want %q in error, got %v", want, err)
+	}
+}
@@ -0,0 +1,48 @@
+package concurrent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentParse runs many parses concurrently, it is meant to be run
+// with the race detector.
+func TestConcurrentParse(t *testing.T) {
+	const n = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var in, want []string
+			for j := 0; j <= i%7; j++ {
+				in = append(in, fmt.Sprintf("k%d=v%d", i, j))
+				want = append(want, fmt.Sprintf("k%d:v%d", i, j))
+			}
+			for k := 0; k < 20; k++ {
+				var stats Stats
+				got, err := Parse("", []byte(strings.Join(in, ",")), Statistics(&stats, "no match"), Memoize(k%2 == 0))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !reflect.DeepEqual(got, want) {
+					errs <- fmt.Errorf("%d: want %v, got %v", i, want, got)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
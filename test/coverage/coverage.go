@@ -0,0 +1,7098 @@
+// Code generated by pigeon; DO NOT EDIT.
+
+package coverage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mna/pigeon/ast"
+)
+
+var g = &grammar{
+	rules: []*rule{
+		{
+			name: "Grammar",
+			pos:  position{line: 5, col: 1, offset: 18},
+			expr: &actionExpr{
+				pos: position{line: 5, col: 11, offset: 30},
+				run: (*parser).callonGrammar1,
+				expr: &seqExpr{
+					pos: position{line: 5, col: 11, offset: 30},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 5, col: 11, offset: 30},
+							run: (*parser).callonGrammar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 5, col: 11, offset: 30},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 5, col: 11, offset: 30},
+								run: (*parser).callonGrammar5,
+								expr: &seqExpr{
+									pos: position{line: 5, col: 11, offset: 30},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 11, offset: 30},
+											name: "__",
+										},
+										&labeledExpr{
+											pos:   position{line: 5, col: 14, offset: 33},
+											label: "initializer",
+											expr: &zeroOrOneExpr{
+												pos: position{line: 5, col: 26, offset: 45},
+												expr: &seqExpr{
+													pos: position{line: 5, col: 28, offset: 47},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 5, col: 28, offset: 47},
+															name: "Initializer",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 5, col: 40, offset: 59},
+															name: "__",
+														},
+													},
+												},
+											},
+										},
+										&labeledExpr{
+											pos:   position{line: 5, col: 46, offset: 65},
+											label: "rules",
+											expr: &oneOrMoreExpr{
+												pos: position{line: 5, col: 52, offset: 71},
+												expr: &seqExpr{
+													pos: position{line: 5, col: 54, offset: 73},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 5, col: 54, offset: 73},
+															name: "Rule",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 5, col: 59, offset: 78},
+															name: "__",
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Initializer",
+			pos:  position{line: 24, col: 1, offset: 521},
+			expr: &actionExpr{
+				pos: position{line: 24, col: 15, offset: 537},
+				run: (*parser).callonInitializer1,
+				expr: &seqExpr{
+					pos: position{line: 24, col: 15, offset: 537},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 24, col: 15, offset: 537},
+							run: (*parser).callonInitializer3,
+						},
+						&labeledExpr{
+							pos:   position{line: 24, col: 15, offset: 537},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 24, col: 15, offset: 537},
+								run: (*parser).callonInitializer5,
+								expr: &seqExpr{
+									pos: position{line: 24, col: 15, offset: 537},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 24, col: 15, offset: 537},
+											label: "code",
+											expr: &ruleRefExpr{
+												pos:  position{line: 24, col: 20, offset: 542},
+												name: "CodeBlock",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 24, col: 30, offset: 552},
+											name: "EOS",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Rule",
+			pos:  position{line: 28, col: 1, offset: 582},
+			expr: &actionExpr{
+				pos: position{line: 28, col: 8, offset: 591},
+				run: (*parser).callonRule1,
+				expr: &seqExpr{
+					pos: position{line: 28, col: 8, offset: 591},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 28, col: 8, offset: 591},
+							run: (*parser).callonRule3,
+						},
+						&labeledExpr{
+							pos:   position{line: 28, col: 8, offset: 591},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 28, col: 8, offset: 591},
+								run: (*parser).callonRule5,
+								expr: &seqExpr{
+									pos: position{line: 28, col: 8, offset: 591},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 28, col: 8, offset: 591},
+											label: "name",
+											expr: &ruleRefExpr{
+												pos:  position{line: 28, col: 13, offset: 596},
+												name: "IdentifierName",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 28, col: 28, offset: 611},
+											name: "__",
+										},
+										&labeledExpr{
+											pos:   position{line: 28, col: 31, offset: 614},
+											label: "display",
+											expr: &zeroOrOneExpr{
+												pos: position{line: 28, col: 39, offset: 622},
+												expr: &seqExpr{
+													pos: position{line: 28, col: 41, offset: 624},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 28, col: 41, offset: 624},
+															name: "StringLiteral",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 28, col: 55, offset: 638},
+															name: "__",
+														},
+													},
+												},
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 28, col: 61, offset: 644},
+											name: "RuleDefOp",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 28, col: 71, offset: 654},
+											name: "__",
+										},
+										&labeledExpr{
+											pos:   position{line: 28, col: 74, offset: 657},
+											label: "expr",
+											expr: &ruleRefExpr{
+												pos:  position{line: 28, col: 79, offset: 662},
+												name: "Expression",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 28, col: 90, offset: 673},
+											name: "EOS",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Expression",
+			pos:  position{line: 41, col: 1, offset: 957},
+			expr: &actionExpr{
+				pos: position{line: 41, col: 14, offset: 972},
+				run: (*parser).callonExpression1,
+				expr: &seqExpr{
+					pos: position{line: 41, col: 14, offset: 972},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 41, col: 14, offset: 972},
+							run: (*parser).callonExpression3,
+						},
+						&labeledExpr{
+							pos:   position{line: 41, col: 14, offset: 972},
+							label: "pigeonCoverage",
+							expr: &ruleRefExpr{
+								pos:  position{line: 41, col: 14, offset: 972},
+								name: "ChoiceExpr",
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "ChoiceExpr",
+			pos:  position{line: 43, col: 1, offset: 984},
+			expr: &actionExpr{
+				pos: position{line: 43, col: 14, offset: 999},
+				run: (*parser).callonChoiceExpr1,
+				expr: &seqExpr{
+					pos: position{line: 43, col: 14, offset: 999},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 43, col: 14, offset: 999},
+							run: (*parser).callonChoiceExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 43, col: 14, offset: 999},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 43, col: 14, offset: 999},
+								run: (*parser).callonChoiceExpr5,
+								expr: &seqExpr{
+									pos: position{line: 43, col: 14, offset: 999},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 43, col: 14, offset: 999},
+											label: "first",
+											expr: &ruleRefExpr{
+												pos:  position{line: 43, col: 20, offset: 1005},
+												name: "ActionExpr",
+											},
+										},
+										&labeledExpr{
+											pos:   position{line: 43, col: 31, offset: 1016},
+											label: "rest",
+											expr: &zeroOrMoreExpr{
+												pos: position{line: 43, col: 36, offset: 1021},
+												expr: &seqExpr{
+													pos: position{line: 43, col: 38, offset: 1023},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 43, col: 38, offset: 1023},
+															name: "__",
+														},
+														&litMatcher{
+															pos:        position{line: 43, col: 41, offset: 1026},
+															val:        "/",
+															ignoreCase: false,
+															want:       "\"/\"",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 43, col: 45, offset: 1030},
+															name: "__",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 43, col: 48, offset: 1033},
+															name: "ActionExpr",
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ActionExpr",
+			pos:  position{line: 58, col: 1, offset: 1438},
+			expr: &actionExpr{
+				pos: position{line: 58, col: 14, offset: 1453},
+				run: (*parser).callonActionExpr1,
+				expr: &seqExpr{
+					pos: position{line: 58, col: 14, offset: 1453},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 58, col: 14, offset: 1453},
+							run: (*parser).callonActionExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 58, col: 14, offset: 1453},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 58, col: 14, offset: 1453},
+								run: (*parser).callonActionExpr5,
+								expr: &seqExpr{
+									pos: position{line: 58, col: 14, offset: 1453},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 58, col: 14, offset: 1453},
+											label: "expr",
+											expr: &ruleRefExpr{
+												pos:  position{line: 58, col: 19, offset: 1458},
+												name: "SeqExpr",
+											},
+										},
+										&labeledExpr{
+											pos:   position{line: 58, col: 27, offset: 1466},
+											label: "code",
+											expr: &zeroOrOneExpr{
+												pos: position{line: 58, col: 32, offset: 1471},
+												expr: &seqExpr{
+													pos: position{line: 58, col: 34, offset: 1473},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 58, col: 34, offset: 1473},
+															name: "__",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 58, col: 37, offset: 1476},
+															name: "CodeBlock",
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "SeqExpr",
+			pos:  position{line: 72, col: 1, offset: 1742},
+			expr: &actionExpr{
+				pos: position{line: 72, col: 11, offset: 1754},
+				run: (*parser).callonSeqExpr1,
+				expr: &seqExpr{
+					pos: position{line: 72, col: 11, offset: 1754},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 72, col: 11, offset: 1754},
+							run: (*parser).callonSeqExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 72, col: 11, offset: 1754},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 72, col: 11, offset: 1754},
+								run: (*parser).callonSeqExpr5,
+								expr: &seqExpr{
+									pos: position{line: 72, col: 11, offset: 1754},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 72, col: 11, offset: 1754},
+											label: "first",
+											expr: &ruleRefExpr{
+												pos:  position{line: 72, col: 17, offset: 1760},
+												name: "LabeledExpr",
+											},
+										},
+										&labeledExpr{
+											pos:   position{line: 72, col: 29, offset: 1772},
+											label: "rest",
+											expr: &zeroOrMoreExpr{
+												pos: position{line: 72, col: 34, offset: 1777},
+												expr: &seqExpr{
+													pos: position{line: 72, col: 36, offset: 1779},
+													exprs: []interface{}{
+														&ruleRefExpr{
+															pos:  position{line: 72, col: 36, offset: 1779},
+															name: "__",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 72, col: 39, offset: 1782},
+															name: "LabeledExpr",
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "LabeledExpr",
+			pos:  position{line: 85, col: 1, offset: 2133},
+			expr: &actionExpr{
+				pos: position{line: 85, col: 15, offset: 2149},
+				run: (*parser).callonLabeledExpr1,
+				expr: &seqExpr{
+					pos: position{line: 85, col: 15, offset: 2149},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 85, col: 15, offset: 2149},
+							run: (*parser).callonLabeledExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 85, col: 15, offset: 2149},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 85, col: 15, offset: 2149},
+								alternatives: []interface{}{
+									&actionExpr{
+										pos: position{line: 85, col: 15, offset: 2149},
+										run: (*parser).callonLabeledExpr6,
+										expr: &seqExpr{
+											pos: position{line: 85, col: 15, offset: 2149},
+											exprs: []interface{}{
+												&labeledExpr{
+													pos:   position{line: 85, col: 15, offset: 2149},
+													label: "label",
+													expr: &ruleRefExpr{
+														pos:  position{line: 85, col: 21, offset: 2155},
+														name: "Identifier",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 85, col: 32, offset: 2166},
+													name: "__",
+												},
+												&litMatcher{
+													pos:        position{line: 85, col: 35, offset: 2169},
+													val:        ":",
+													ignoreCase: false,
+													want:       "\":\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 85, col: 39, offset: 2173},
+													name: "__",
+												},
+												&labeledExpr{
+													pos:   position{line: 85, col: 42, offset: 2176},
+													label: "expr",
+													expr: &ruleRefExpr{
+														pos:  position{line: 85, col: 47, offset: 2181},
+														name: "PrefixedExpr",
+													},
+												},
+											},
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 91, col: 5, offset: 2354},
+										name: "PrefixedExpr",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "PrefixedExpr",
+			pos:  position{line: 93, col: 1, offset: 2368},
+			expr: &actionExpr{
+				pos: position{line: 93, col: 16, offset: 2385},
+				run: (*parser).callonPrefixedExpr1,
+				expr: &seqExpr{
+					pos: position{line: 93, col: 16, offset: 2385},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 93, col: 16, offset: 2385},
+							run: (*parser).callonPrefixedExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 93, col: 16, offset: 2385},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 93, col: 16, offset: 2385},
+								alternatives: []interface{}{
+									&actionExpr{
+										pos: position{line: 93, col: 16, offset: 2385},
+										run: (*parser).callonPrefixedExpr6,
+										expr: &seqExpr{
+											pos: position{line: 93, col: 16, offset: 2385},
+											exprs: []interface{}{
+												&labeledExpr{
+													pos:   position{line: 93, col: 16, offset: 2385},
+													label: "op",
+													expr: &ruleRefExpr{
+														pos:  position{line: 93, col: 19, offset: 2388},
+														name: "PrefixedOp",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 93, col: 30, offset: 2399},
+													name: "__",
+												},
+												&labeledExpr{
+													pos:   position{line: 93, col: 33, offset: 2402},
+													label: "expr",
+													expr: &ruleRefExpr{
+														pos:  position{line: 93, col: 38, offset: 2407},
+														name: "SuffixedExpr",
+													},
+												},
+											},
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 104, col: 5, offset: 2689},
+										name: "SuffixedExpr",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "PrefixedOp",
+			pos:  position{line: 106, col: 1, offset: 2703},
+			expr: &actionExpr{
+				pos: position{line: 106, col: 14, offset: 2718},
+				run: (*parser).callonPrefixedOp1,
+				expr: &seqExpr{
+					pos: position{line: 106, col: 14, offset: 2718},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 106, col: 14, offset: 2718},
+							run: (*parser).callonPrefixedOp3,
+						},
+						&labeledExpr{
+							pos:   position{line: 106, col: 14, offset: 2718},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 106, col: 14, offset: 2718},
+								run: (*parser).callonPrefixedOp5,
+								expr: &choiceExpr{
+									pos: position{line: 106, col: 16, offset: 2720},
+									alternatives: []interface{}{
+										&litMatcher{
+											pos:        position{line: 106, col: 16, offset: 2720},
+											val:        "&",
+											ignoreCase: false,
+											want:       "\"&\"",
+										},
+										&litMatcher{
+											pos:        position{line: 106, col: 22, offset: 2726},
+											val:        "!",
+											ignoreCase: false,
+											want:       "\"!\"",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SuffixedExpr",
+			pos:  position{line: 110, col: 1, offset: 2768},
+			expr: &actionExpr{
+				pos: position{line: 110, col: 16, offset: 2785},
+				run: (*parser).callonSuffixedExpr1,
+				expr: &seqExpr{
+					pos: position{line: 110, col: 16, offset: 2785},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 110, col: 16, offset: 2785},
+							run: (*parser).callonSuffixedExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 110, col: 16, offset: 2785},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 110, col: 16, offset: 2785},
+								alternatives: []interface{}{
+									&actionExpr{
+										pos: position{line: 110, col: 16, offset: 2785},
+										run: (*parser).callonSuffixedExpr6,
+										expr: &seqExpr{
+											pos: position{line: 110, col: 16, offset: 2785},
+											exprs: []interface{}{
+												&labeledExpr{
+													pos:   position{line: 110, col: 16, offset: 2785},
+													label: "expr",
+													expr: &ruleRefExpr{
+														pos:  position{line: 110, col: 21, offset: 2790},
+														name: "PrimaryExpr",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 110, col: 33, offset: 2802},
+													name: "__",
+												},
+												&labeledExpr{
+													pos:   position{line: 110, col: 36, offset: 2805},
+													label: "op",
+													expr: &ruleRefExpr{
+														pos:  position{line: 110, col: 39, offset: 2808},
+														name: "SuffixedOp",
+													},
+												},
+											},
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 129, col: 5, offset: 3338},
+										name: "PrimaryExpr",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "SuffixedOp",
+			pos:  position{line: 131, col: 1, offset: 3352},
+			expr: &actionExpr{
+				pos: position{line: 131, col: 14, offset: 3367},
+				run: (*parser).callonSuffixedOp1,
+				expr: &seqExpr{
+					pos: position{line: 131, col: 14, offset: 3367},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 131, col: 14, offset: 3367},
+							run: (*parser).callonSuffixedOp3,
+						},
+						&labeledExpr{
+							pos:   position{line: 131, col: 14, offset: 3367},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 131, col: 14, offset: 3367},
+								run: (*parser).callonSuffixedOp5,
+								expr: &choiceExpr{
+									pos: position{line: 131, col: 16, offset: 3369},
+									alternatives: []interface{}{
+										&litMatcher{
+											pos:        position{line: 131, col: 16, offset: 3369},
+											val:        "?",
+											ignoreCase: false,
+											want:       "\"?\"",
+										},
+										&litMatcher{
+											pos:        position{line: 131, col: 22, offset: 3375},
+											val:        "*",
+											ignoreCase: false,
+											want:       "\"*\"",
+										},
+										&litMatcher{
+											pos:        position{line: 131, col: 28, offset: 3381},
+											val:        "+",
+											ignoreCase: false,
+											want:       "\"+\"",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PrimaryExpr",
+			pos:  position{line: 135, col: 1, offset: 3423},
+			expr: &actionExpr{
+				pos: position{line: 135, col: 15, offset: 3439},
+				run: (*parser).callonPrimaryExpr1,
+				expr: &seqExpr{
+					pos: position{line: 135, col: 15, offset: 3439},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 135, col: 15, offset: 3439},
+							run: (*parser).callonPrimaryExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 135, col: 15, offset: 3439},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 135, col: 15, offset: 3439},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 135, col: 15, offset: 3439},
+										name: "LitMatcher",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 135, col: 28, offset: 3452},
+										name: "CharClassMatcher",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 135, col: 47, offset: 3471},
+										name: "AnyMatcher",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 135, col: 60, offset: 3484},
+										name: "RuleRefExpr",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 135, col: 74, offset: 3498},
+										name: "SemanticPredExpr",
+									},
+									&actionExpr{
+										pos: position{line: 135, col: 93, offset: 3517},
+										run: (*parser).callonPrimaryExpr11,
+										expr: &seqExpr{
+											pos: position{line: 135, col: 93, offset: 3517},
+											exprs: []interface{}{
+												&litMatcher{
+													pos:        position{line: 135, col: 93, offset: 3517},
+													val:        "(",
+													ignoreCase: false,
+													want:       "\"(\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 135, col: 97, offset: 3521},
+													name: "__",
+												},
+												&labeledExpr{
+													pos:   position{line: 135, col: 100, offset: 3524},
+													label: "expr",
+													expr: &ruleRefExpr{
+														pos:  position{line: 135, col: 105, offset: 3529},
+														name: "Expression",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 135, col: 116, offset: 3540},
+													name: "__",
+												},
+												&litMatcher{
+													pos:        position{line: 135, col: 119, offset: 3543},
+													val:        ")",
+													ignoreCase: false,
+													want:       "\")\"",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "RuleRefExpr",
+			pos:  position{line: 138, col: 1, offset: 3572},
+			expr: &actionExpr{
+				pos: position{line: 138, col: 15, offset: 3588},
+				run: (*parser).callonRuleRefExpr1,
+				expr: &seqExpr{
+					pos: position{line: 138, col: 15, offset: 3588},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 138, col: 15, offset: 3588},
+							run: (*parser).callonRuleRefExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 138, col: 15, offset: 3588},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 138, col: 15, offset: 3588},
+								run: (*parser).callonRuleRefExpr5,
+								expr: &seqExpr{
+									pos: position{line: 138, col: 15, offset: 3588},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 138, col: 15, offset: 3588},
+											label: "name",
+											expr: &ruleRefExpr{
+												pos:  position{line: 138, col: 20, offset: 3593},
+												name: "IdentifierName",
+											},
+										},
+										&notExpr{
+											pos: position{line: 138, col: 35, offset: 3608},
+											expr: &seqExpr{
+												pos: position{line: 138, col: 38, offset: 3611},
+												exprs: []interface{}{
+													&ruleRefExpr{
+														pos:  position{line: 138, col: 38, offset: 3611},
+														name: "__",
+													},
+													&zeroOrOneExpr{
+														pos: position{line: 138, col: 41, offset: 3614},
+														expr: &seqExpr{
+															pos: position{line: 138, col: 43, offset: 3616},
+															exprs: []interface{}{
+																&ruleRefExpr{
+																	pos:  position{line: 138, col: 43, offset: 3616},
+																	name: "StringLiteral",
+																},
+																&ruleRefExpr{
+																	pos:  position{line: 138, col: 57, offset: 3630},
+																	name: "__",
+																},
+															},
+														},
+													},
+													&ruleRefExpr{
+														pos:  position{line: 138, col: 63, offset: 3636},
+														name: "RuleDefOp",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SemanticPredExpr",
+			pos:  position{line: 143, col: 1, offset: 3752},
+			expr: &actionExpr{
+				pos: position{line: 143, col: 20, offset: 3773},
+				run: (*parser).callonSemanticPredExpr1,
+				expr: &seqExpr{
+					pos: position{line: 143, col: 20, offset: 3773},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 143, col: 20, offset: 3773},
+							run: (*parser).callonSemanticPredExpr3,
+						},
+						&labeledExpr{
+							pos:   position{line: 143, col: 20, offset: 3773},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 143, col: 20, offset: 3773},
+								run: (*parser).callonSemanticPredExpr5,
+								expr: &seqExpr{
+									pos: position{line: 143, col: 20, offset: 3773},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 143, col: 20, offset: 3773},
+											label: "op",
+											expr: &ruleRefExpr{
+												pos:  position{line: 143, col: 23, offset: 3776},
+												name: "SemanticPredOp",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 143, col: 38, offset: 3791},
+											name: "__",
+										},
+										&labeledExpr{
+											pos:   position{line: 143, col: 41, offset: 3794},
+											label: "code",
+											expr: &ruleRefExpr{
+												pos:  position{line: 143, col: 46, offset: 3799},
+												name: "CodeBlock",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SemanticPredOp",
+			pos:  position{line: 154, col: 1, offset: 4076},
+			expr: &actionExpr{
+				pos: position{line: 154, col: 18, offset: 4095},
+				run: (*parser).callonSemanticPredOp1,
+				expr: &seqExpr{
+					pos: position{line: 154, col: 18, offset: 4095},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 154, col: 18, offset: 4095},
+							run: (*parser).callonSemanticPredOp3,
+						},
+						&labeledExpr{
+							pos:   position{line: 154, col: 18, offset: 4095},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 154, col: 18, offset: 4095},
+								run: (*parser).callonSemanticPredOp5,
+								expr: &choiceExpr{
+									pos: position{line: 154, col: 20, offset: 4097},
+									alternatives: []interface{}{
+										&litMatcher{
+											pos:        position{line: 154, col: 20, offset: 4097},
+											val:        "&",
+											ignoreCase: false,
+											want:       "\"&\"",
+										},
+										&litMatcher{
+											pos:        position{line: 154, col: 26, offset: 4103},
+											val:        "!",
+											ignoreCase: false,
+											want:       "\"!\"",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RuleDefOp",
+			pos:  position{line: 158, col: 1, offset: 4145},
+			expr: &actionExpr{
+				pos: position{line: 158, col: 13, offset: 4159},
+				run: (*parser).callonRuleDefOp1,
+				expr: &seqExpr{
+					pos: position{line: 158, col: 13, offset: 4159},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 158, col: 13, offset: 4159},
+							run: (*parser).callonRuleDefOp3,
+						},
+						&labeledExpr{
+							pos:   position{line: 158, col: 13, offset: 4159},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 158, col: 13, offset: 4159},
+								alternatives: []interface{}{
+									&litMatcher{
+										pos:        position{line: 158, col: 13, offset: 4159},
+										val:        "=",
+										ignoreCase: false,
+										want:       "\"=\"",
+									},
+									&litMatcher{
+										pos:        position{line: 158, col: 19, offset: 4165},
+										val:        "<-",
+										ignoreCase: false,
+										want:       "\"<-\"",
+									},
+									&litMatcher{
+										pos:        position{line: 158, col: 26, offset: 4172},
+										val:        "←",
+										ignoreCase: false,
+										want:       "\"←\"",
+									},
+									&litMatcher{
+										pos:        position{line: 158, col: 37, offset: 4183},
+										val:        "⟵",
+										ignoreCase: false,
+										want:       "\"⟵\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "SourceChar",
+			pos:  position{line: 160, col: 1, offset: 4193},
+			expr: &actionExpr{
+				pos: position{line: 160, col: 14, offset: 4208},
+				run: (*parser).callonSourceChar1,
+				expr: &seqExpr{
+					pos: position{line: 160, col: 14, offset: 4208},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 160, col: 14, offset: 4208},
+							run: (*parser).callonSourceChar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 160, col: 14, offset: 4208},
+							label: "pigeonCoverage",
+							expr: &anyMatcher{
+								line: 160, col: 14, offset: 4208,
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "Comment",
+			pos:  position{line: 161, col: 1, offset: 4210},
+			expr: &actionExpr{
+				pos: position{line: 161, col: 11, offset: 4222},
+				run: (*parser).callonComment1,
+				expr: &seqExpr{
+					pos: position{line: 161, col: 11, offset: 4222},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 161, col: 11, offset: 4222},
+							run: (*parser).callonComment3,
+						},
+						&labeledExpr{
+							pos:   position{line: 161, col: 11, offset: 4222},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 161, col: 11, offset: 4222},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 161, col: 11, offset: 4222},
+										name: "MultiLineComment",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 161, col: 30, offset: 4241},
+										name: "SingleLineComment",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MultiLineComment",
+			pos:  position{line: 162, col: 1, offset: 4259},
+			expr: &actionExpr{
+				pos: position{line: 162, col: 20, offset: 4280},
+				run: (*parser).callonMultiLineComment1,
+				expr: &seqExpr{
+					pos: position{line: 162, col: 20, offset: 4280},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 162, col: 20, offset: 4280},
+							run: (*parser).callonMultiLineComment3,
+						},
+						&labeledExpr{
+							pos:   position{line: 162, col: 20, offset: 4280},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 162, col: 20, offset: 4280},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 162, col: 20, offset: 4280},
+										val:        "/*",
+										ignoreCase: false,
+										want:       "\"/*\"",
+									},
+									&zeroOrMoreExpr{
+										pos: position{line: 162, col: 25, offset: 4285},
+										expr: &seqExpr{
+											pos: position{line: 162, col: 27, offset: 4287},
+											exprs: []interface{}{
+												&notExpr{
+													pos: position{line: 162, col: 27, offset: 4287},
+													expr: &litMatcher{
+														pos:        position{line: 162, col: 28, offset: 4288},
+														val:        "*/",
+														ignoreCase: false,
+														want:       "\"*/\"",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 162, col: 33, offset: 4293},
+													name: "SourceChar",
+												},
+											},
+										},
+									},
+									&litMatcher{
+										pos:        position{line: 162, col: 47, offset: 4307},
+										val:        "*/",
+										ignoreCase: false,
+										want:       "\"*/\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MultiLineCommentNoLineTerminator",
+			pos:  position{line: 163, col: 1, offset: 4312},
+			expr: &actionExpr{
+				pos: position{line: 163, col: 36, offset: 4349},
+				run: (*parser).callonMultiLineCommentNoLineTerminator1,
+				expr: &seqExpr{
+					pos: position{line: 163, col: 36, offset: 4349},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 163, col: 36, offset: 4349},
+							run: (*parser).callonMultiLineCommentNoLineTerminator3,
+						},
+						&labeledExpr{
+							pos:   position{line: 163, col: 36, offset: 4349},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 163, col: 36, offset: 4349},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 163, col: 36, offset: 4349},
+										val:        "/*",
+										ignoreCase: false,
+										want:       "\"/*\"",
+									},
+									&zeroOrMoreExpr{
+										pos: position{line: 163, col: 41, offset: 4354},
+										expr: &seqExpr{
+											pos: position{line: 163, col: 43, offset: 4356},
+											exprs: []interface{}{
+												&notExpr{
+													pos: position{line: 163, col: 43, offset: 4356},
+													expr: &choiceExpr{
+														pos: position{line: 163, col: 46, offset: 4359},
+														alternatives: []interface{}{
+															&litMatcher{
+																pos:        position{line: 163, col: 46, offset: 4359},
+																val:        "*/",
+																ignoreCase: false,
+																want:       "\"*/\"",
+															},
+															&ruleRefExpr{
+																pos:  position{line: 163, col: 53, offset: 4366},
+																name: "EOL",
+															},
+														},
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 163, col: 59, offset: 4372},
+													name: "SourceChar",
+												},
+											},
+										},
+									},
+									&litMatcher{
+										pos:        position{line: 163, col: 73, offset: 4386},
+										val:        "*/",
+										ignoreCase: false,
+										want:       "\"*/\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SingleLineComment",
+			pos:  position{line: 164, col: 1, offset: 4391},
+			expr: &actionExpr{
+				pos: position{line: 164, col: 21, offset: 4413},
+				run: (*parser).callonSingleLineComment1,
+				expr: &seqExpr{
+					pos: position{line: 164, col: 21, offset: 4413},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 164, col: 21, offset: 4413},
+							run: (*parser).callonSingleLineComment3,
+						},
+						&labeledExpr{
+							pos:   position{line: 164, col: 21, offset: 4413},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 164, col: 21, offset: 4413},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 164, col: 21, offset: 4413},
+										val:        "//",
+										ignoreCase: false,
+										want:       "\"//\"",
+									},
+									&zeroOrMoreExpr{
+										pos: position{line: 164, col: 26, offset: 4418},
+										expr: &seqExpr{
+											pos: position{line: 164, col: 28, offset: 4420},
+											exprs: []interface{}{
+												&notExpr{
+													pos: position{line: 164, col: 28, offset: 4420},
+													expr: &ruleRefExpr{
+														pos:  position{line: 164, col: 29, offset: 4421},
+														name: "EOL",
+													},
+												},
+												&ruleRefExpr{
+													pos:  position{line: 164, col: 33, offset: 4425},
+													name: "SourceChar",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "Identifier",
+			pos:  position{line: 166, col: 1, offset: 4440},
+			expr: &actionExpr{
+				pos: position{line: 166, col: 14, offset: 4455},
+				run: (*parser).callonIdentifier1,
+				expr: &seqExpr{
+					pos: position{line: 166, col: 14, offset: 4455},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 166, col: 14, offset: 4455},
+							run: (*parser).callonIdentifier3,
+						},
+						&labeledExpr{
+							pos:   position{line: 166, col: 14, offset: 4455},
+							label: "pigeonCoverage",
+							expr: &ruleRefExpr{
+								pos:  position{line: 166, col: 14, offset: 4455},
+								name: "IdentifierName",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IdentifierName",
+			pos:  position{line: 167, col: 1, offset: 4470},
+			expr: &actionExpr{
+				pos: position{line: 167, col: 18, offset: 4489},
+				run: (*parser).callonIdentifierName1,
+				expr: &seqExpr{
+					pos: position{line: 167, col: 18, offset: 4489},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 167, col: 18, offset: 4489},
+							run: (*parser).callonIdentifierName3,
+						},
+						&labeledExpr{
+							pos:   position{line: 167, col: 18, offset: 4489},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 167, col: 18, offset: 4489},
+								run: (*parser).callonIdentifierName5,
+								expr: &seqExpr{
+									pos: position{line: 167, col: 18, offset: 4489},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 167, col: 18, offset: 4489},
+											name: "IdentifierStart",
+										},
+										&zeroOrMoreExpr{
+											pos: position{line: 167, col: 34, offset: 4505},
+											expr: &ruleRefExpr{
+												pos:  position{line: 167, col: 34, offset: 4505},
+												name: "IdentifierPart",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "IdentifierStart",
+			pos:  position{line: 170, col: 1, offset: 4587},
+			expr: &actionExpr{
+				pos: position{line: 170, col: 19, offset: 4607},
+				run: (*parser).callonIdentifierStart1,
+				expr: &seqExpr{
+					pos: position{line: 170, col: 19, offset: 4607},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 170, col: 19, offset: 4607},
+							run: (*parser).callonIdentifierStart3,
+						},
+						&labeledExpr{
+							pos:   position{line: 170, col: 19, offset: 4607},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 170, col: 19, offset: 4607},
+								val:        "[a-z_]i",
+								chars:      []rune{'_'},
+								ranges:     []rune{'a', 'z'},
+								ignoreCase: true,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "IdentifierPart",
+			pos:  position{line: 171, col: 1, offset: 4615},
+			expr: &actionExpr{
+				pos: position{line: 171, col: 18, offset: 4634},
+				run: (*parser).callonIdentifierPart1,
+				expr: &seqExpr{
+					pos: position{line: 171, col: 18, offset: 4634},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 171, col: 18, offset: 4634},
+							run: (*parser).callonIdentifierPart3,
+						},
+						&labeledExpr{
+							pos:   position{line: 171, col: 18, offset: 4634},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 171, col: 18, offset: 4634},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 171, col: 18, offset: 4634},
+										name: "IdentifierStart",
+									},
+									&charClassMatcher{
+										pos:        position{line: 171, col: 36, offset: 4652},
+										val:        "[0-9]",
+										ranges:     []rune{'0', '9'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "LitMatcher",
+			pos:  position{line: 173, col: 1, offset: 4659},
+			expr: &actionExpr{
+				pos: position{line: 173, col: 14, offset: 4674},
+				run: (*parser).callonLitMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 173, col: 14, offset: 4674},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 173, col: 14, offset: 4674},
+							run: (*parser).callonLitMatcher3,
+						},
+						&labeledExpr{
+							pos:   position{line: 173, col: 14, offset: 4674},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 173, col: 14, offset: 4674},
+								run: (*parser).callonLitMatcher5,
+								expr: &seqExpr{
+									pos: position{line: 173, col: 14, offset: 4674},
+									exprs: []interface{}{
+										&labeledExpr{
+											pos:   position{line: 173, col: 14, offset: 4674},
+											label: "lit",
+											expr: &ruleRefExpr{
+												pos:  position{line: 173, col: 18, offset: 4678},
+												name: "StringLiteral",
+											},
+										},
+										&labeledExpr{
+											pos:   position{line: 173, col: 32, offset: 4692},
+											label: "ignore",
+											expr: &zeroOrOneExpr{
+												pos: position{line: 173, col: 39, offset: 4699},
+												expr: &litMatcher{
+													pos:        position{line: 173, col: 39, offset: 4699},
+													val:        "i",
+													ignoreCase: false,
+													want:       "\"i\"",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "StringLiteral",
+			pos:  position{line: 183, col: 1, offset: 4925},
+			expr: &actionExpr{
+				pos: position{line: 183, col: 17, offset: 4943},
+				run: (*parser).callonStringLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 183, col: 17, offset: 4943},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 183, col: 17, offset: 4943},
+							run: (*parser).callonStringLiteral3,
+						},
+						&labeledExpr{
+							pos:   position{line: 183, col: 17, offset: 4943},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 183, col: 17, offset: 4943},
+								run: (*parser).callonStringLiteral5,
+								expr: &choiceExpr{
+									pos: position{line: 183, col: 19, offset: 4945},
+									alternatives: []interface{}{
+										&seqExpr{
+											pos: position{line: 183, col: 19, offset: 4945},
+											exprs: []interface{}{
+												&litMatcher{
+													pos:        position{line: 183, col: 19, offset: 4945},
+													val:        "\"",
+													ignoreCase: false,
+													want:       "\"\\\"\"",
+												},
+												&zeroOrMoreExpr{
+													pos: position{line: 183, col: 23, offset: 4949},
+													expr: &ruleRefExpr{
+														pos:  position{line: 183, col: 23, offset: 4949},
+														name: "DoubleStringChar",
+													},
+												},
+												&litMatcher{
+													pos:        position{line: 183, col: 41, offset: 4967},
+													val:        "\"",
+													ignoreCase: false,
+													want:       "\"\\\"\"",
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 183, col: 47, offset: 4973},
+											exprs: []interface{}{
+												&litMatcher{
+													pos:        position{line: 183, col: 47, offset: 4973},
+													val:        "'",
+													ignoreCase: false,
+													want:       "\"'\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 183, col: 51, offset: 4977},
+													name: "SingleStringChar",
+												},
+												&litMatcher{
+													pos:        position{line: 183, col: 68, offset: 4994},
+													val:        "'",
+													ignoreCase: false,
+													want:       "\"'\"",
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 183, col: 74, offset: 5000},
+											exprs: []interface{}{
+												&litMatcher{
+													pos:        position{line: 183, col: 74, offset: 5000},
+													val:        "`",
+													ignoreCase: false,
+													want:       "\"`\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 183, col: 78, offset: 5004},
+													name: "RawStringChar",
+												},
+												&litMatcher{
+													pos:        position{line: 183, col: 92, offset: 5018},
+													val:        "`",
+													ignoreCase: false,
+													want:       "\"`\"",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "DoubleStringChar",
+			pos:  position{line: 186, col: 1, offset: 5089},
+			expr: &actionExpr{
+				pos: position{line: 186, col: 20, offset: 5110},
+				run: (*parser).callonDoubleStringChar1,
+				expr: &seqExpr{
+					pos: position{line: 186, col: 20, offset: 5110},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 186, col: 20, offset: 5110},
+							run: (*parser).callonDoubleStringChar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 186, col: 20, offset: 5110},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 186, col: 20, offset: 5110},
+								alternatives: []interface{}{
+									&seqExpr{
+										pos: position{line: 186, col: 20, offset: 5110},
+										exprs: []interface{}{
+											&notExpr{
+												pos: position{line: 186, col: 20, offset: 5110},
+												expr: &choiceExpr{
+													pos: position{line: 186, col: 23, offset: 5113},
+													alternatives: []interface{}{
+														&litMatcher{
+															pos:        position{line: 186, col: 23, offset: 5113},
+															val:        "\"",
+															ignoreCase: false,
+															want:       "\"\\\"\"",
+														},
+														&litMatcher{
+															pos:        position{line: 186, col: 29, offset: 5119},
+															val:        "\\",
+															ignoreCase: false,
+															want:       "\"\\\\\"",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 186, col: 36, offset: 5126},
+															name: "EOL",
+														},
+													},
+												},
+											},
+											&ruleRefExpr{
+												pos:  position{line: 186, col: 42, offset: 5132},
+												name: "SourceChar",
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 186, col: 55, offset: 5145},
+										exprs: []interface{}{
+											&litMatcher{
+												pos:        position{line: 186, col: 55, offset: 5145},
+												val:        "\\",
+												ignoreCase: false,
+												want:       "\"\\\\\"",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 186, col: 60, offset: 5150},
+												name: "DoubleStringEscape",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SingleStringChar",
+			pos:  position{line: 187, col: 1, offset: 5169},
+			expr: &actionExpr{
+				pos: position{line: 187, col: 20, offset: 5190},
+				run: (*parser).callonSingleStringChar1,
+				expr: &seqExpr{
+					pos: position{line: 187, col: 20, offset: 5190},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 187, col: 20, offset: 5190},
+							run: (*parser).callonSingleStringChar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 187, col: 20, offset: 5190},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 187, col: 20, offset: 5190},
+								alternatives: []interface{}{
+									&seqExpr{
+										pos: position{line: 187, col: 20, offset: 5190},
+										exprs: []interface{}{
+											&notExpr{
+												pos: position{line: 187, col: 20, offset: 5190},
+												expr: &choiceExpr{
+													pos: position{line: 187, col: 23, offset: 5193},
+													alternatives: []interface{}{
+														&litMatcher{
+															pos:        position{line: 187, col: 23, offset: 5193},
+															val:        "'",
+															ignoreCase: false,
+															want:       "\"'\"",
+														},
+														&litMatcher{
+															pos:        position{line: 187, col: 29, offset: 5199},
+															val:        "\\",
+															ignoreCase: false,
+															want:       "\"\\\\\"",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 187, col: 36, offset: 5206},
+															name: "EOL",
+														},
+													},
+												},
+											},
+											&ruleRefExpr{
+												pos:  position{line: 187, col: 42, offset: 5212},
+												name: "SourceChar",
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 187, col: 55, offset: 5225},
+										exprs: []interface{}{
+											&litMatcher{
+												pos:        position{line: 187, col: 55, offset: 5225},
+												val:        "\\",
+												ignoreCase: false,
+												want:       "\"\\\\\"",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 187, col: 60, offset: 5230},
+												name: "SingleStringEscape",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RawStringChar",
+			pos:  position{line: 188, col: 1, offset: 5249},
+			expr: &actionExpr{
+				pos: position{line: 188, col: 17, offset: 5267},
+				run: (*parser).callonRawStringChar1,
+				expr: &seqExpr{
+					pos: position{line: 188, col: 17, offset: 5267},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 188, col: 17, offset: 5267},
+							run: (*parser).callonRawStringChar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 188, col: 17, offset: 5267},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 188, col: 17, offset: 5267},
+								exprs: []interface{}{
+									&notExpr{
+										pos: position{line: 188, col: 17, offset: 5267},
+										expr: &litMatcher{
+											pos:        position{line: 188, col: 18, offset: 5268},
+											val:        "`",
+											ignoreCase: false,
+											want:       "\"`\"",
+										},
+									},
+									&ruleRefExpr{
+										pos:  position{line: 188, col: 22, offset: 5272},
+										name: "SourceChar",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "DoubleStringEscape",
+			pos:  position{line: 190, col: 1, offset: 5284},
+			expr: &actionExpr{
+				pos: position{line: 190, col: 22, offset: 5307},
+				run: (*parser).callonDoubleStringEscape1,
+				expr: &seqExpr{
+					pos: position{line: 190, col: 22, offset: 5307},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 190, col: 22, offset: 5307},
+							run: (*parser).callonDoubleStringEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 190, col: 22, offset: 5307},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 190, col: 22, offset: 5307},
+								alternatives: []interface{}{
+									&litMatcher{
+										pos:        position{line: 190, col: 22, offset: 5307},
+										val:        "'",
+										ignoreCase: false,
+										want:       "\"'\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 190, col: 28, offset: 5313},
+										name: "CommonEscapeSequence",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SingleStringEscape",
+			pos:  position{line: 191, col: 1, offset: 5334},
+			expr: &actionExpr{
+				pos: position{line: 191, col: 22, offset: 5357},
+				run: (*parser).callonSingleStringEscape1,
+				expr: &seqExpr{
+					pos: position{line: 191, col: 22, offset: 5357},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 191, col: 22, offset: 5357},
+							run: (*parser).callonSingleStringEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 191, col: 22, offset: 5357},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 191, col: 22, offset: 5357},
+								alternatives: []interface{}{
+									&litMatcher{
+										pos:        position{line: 191, col: 22, offset: 5357},
+										val:        "\"",
+										ignoreCase: false,
+										want:       "\"\\\"\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 191, col: 28, offset: 5363},
+										name: "CommonEscapeSequence",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "CommonEscapeSequence",
+			pos:  position{line: 193, col: 1, offset: 5385},
+			expr: &actionExpr{
+				pos: position{line: 193, col: 24, offset: 5410},
+				run: (*parser).callonCommonEscapeSequence1,
+				expr: &seqExpr{
+					pos: position{line: 193, col: 24, offset: 5410},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 193, col: 24, offset: 5410},
+							run: (*parser).callonCommonEscapeSequence3,
+						},
+						&labeledExpr{
+							pos:   position{line: 193, col: 24, offset: 5410},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 193, col: 24, offset: 5410},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 193, col: 24, offset: 5410},
+										name: "SingleCharEscape",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 193, col: 43, offset: 5429},
+										name: "OctalEscape",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 193, col: 57, offset: 5443},
+										name: "HexEscape",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 193, col: 69, offset: 5455},
+										name: "LongUnicodeEscape",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 193, col: 89, offset: 5475},
+										name: "ShortUnicodeEscape",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "SingleCharEscape",
+			pos:  position{line: 194, col: 1, offset: 5494},
+			expr: &actionExpr{
+				pos: position{line: 194, col: 20, offset: 5515},
+				run: (*parser).callonSingleCharEscape1,
+				expr: &seqExpr{
+					pos: position{line: 194, col: 20, offset: 5515},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 194, col: 20, offset: 5515},
+							run: (*parser).callonSingleCharEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 194, col: 20, offset: 5515},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 194, col: 20, offset: 5515},
+								alternatives: []interface{}{
+									&litMatcher{
+										pos:        position{line: 194, col: 20, offset: 5515},
+										val:        "a",
+										ignoreCase: false,
+										want:       "\"a\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 26, offset: 5521},
+										val:        "b",
+										ignoreCase: false,
+										want:       "\"b\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 32, offset: 5527},
+										val:        "n",
+										ignoreCase: false,
+										want:       "\"n\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 38, offset: 5533},
+										val:        "f",
+										ignoreCase: false,
+										want:       "\"f\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 44, offset: 5539},
+										val:        "r",
+										ignoreCase: false,
+										want:       "\"r\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 50, offset: 5545},
+										val:        "t",
+										ignoreCase: false,
+										want:       "\"t\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 56, offset: 5551},
+										val:        "v",
+										ignoreCase: false,
+										want:       "\"v\"",
+									},
+									&litMatcher{
+										pos:        position{line: 194, col: 62, offset: 5557},
+										val:        "\\",
+										ignoreCase: false,
+										want:       "\"\\\\\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "OctalEscape",
+			pos:  position{line: 195, col: 1, offset: 5562},
+			expr: &actionExpr{
+				pos: position{line: 195, col: 15, offset: 5578},
+				run: (*parser).callonOctalEscape1,
+				expr: &seqExpr{
+					pos: position{line: 195, col: 15, offset: 5578},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 195, col: 15, offset: 5578},
+							run: (*parser).callonOctalEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 195, col: 15, offset: 5578},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 195, col: 15, offset: 5578},
+								exprs: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 195, col: 15, offset: 5578},
+										name: "OctalDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 195, col: 26, offset: 5589},
+										name: "OctalDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 195, col: 37, offset: 5600},
+										name: "OctalDigit",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "HexEscape",
+			pos:  position{line: 196, col: 1, offset: 5611},
+			expr: &actionExpr{
+				pos: position{line: 196, col: 13, offset: 5625},
+				run: (*parser).callonHexEscape1,
+				expr: &seqExpr{
+					pos: position{line: 196, col: 13, offset: 5625},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 196, col: 13, offset: 5625},
+							run: (*parser).callonHexEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 196, col: 13, offset: 5625},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 196, col: 13, offset: 5625},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 196, col: 13, offset: 5625},
+										val:        "x",
+										ignoreCase: false,
+										want:       "\"x\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 196, col: 17, offset: 5629},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 196, col: 26, offset: 5638},
+										name: "HexDigit",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "LongUnicodeEscape",
+			pos:  position{line: 197, col: 1, offset: 5647},
+			expr: &actionExpr{
+				pos: position{line: 197, col: 21, offset: 5669},
+				run: (*parser).callonLongUnicodeEscape1,
+				expr: &seqExpr{
+					pos: position{line: 197, col: 21, offset: 5669},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 197, col: 21, offset: 5669},
+							run: (*parser).callonLongUnicodeEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 197, col: 21, offset: 5669},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 197, col: 21, offset: 5669},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 197, col: 21, offset: 5669},
+										val:        "U",
+										ignoreCase: false,
+										want:       "\"U\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 25, offset: 5673},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 34, offset: 5682},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 43, offset: 5691},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 52, offset: 5700},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 61, offset: 5709},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 70, offset: 5718},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 79, offset: 5727},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 88, offset: 5736},
+										name: "HexDigit",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ShortUnicodeEscape",
+			pos:  position{line: 198, col: 1, offset: 5745},
+			expr: &actionExpr{
+				pos: position{line: 198, col: 22, offset: 5768},
+				run: (*parser).callonShortUnicodeEscape1,
+				expr: &seqExpr{
+					pos: position{line: 198, col: 22, offset: 5768},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 198, col: 22, offset: 5768},
+							run: (*parser).callonShortUnicodeEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 198, col: 22, offset: 5768},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 198, col: 22, offset: 5768},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 198, col: 22, offset: 5768},
+										val:        "u",
+										ignoreCase: false,
+										want:       "\"u\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 198, col: 26, offset: 5772},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 198, col: 35, offset: 5781},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 198, col: 44, offset: 5790},
+										name: "HexDigit",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 198, col: 53, offset: 5799},
+										name: "HexDigit",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "OctalDigit",
+			pos:  position{line: 200, col: 1, offset: 5809},
+			expr: &actionExpr{
+				pos: position{line: 200, col: 14, offset: 5824},
+				run: (*parser).callonOctalDigit1,
+				expr: &seqExpr{
+					pos: position{line: 200, col: 14, offset: 5824},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 200, col: 14, offset: 5824},
+							run: (*parser).callonOctalDigit3,
+						},
+						&labeledExpr{
+							pos:   position{line: 200, col: 14, offset: 5824},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 200, col: 14, offset: 5824},
+								val:        "[0-7]",
+								ranges:     []rune{'0', '7'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "DecimalDigit",
+			pos:  position{line: 201, col: 1, offset: 5830},
+			expr: &actionExpr{
+				pos: position{line: 201, col: 16, offset: 5847},
+				run: (*parser).callonDecimalDigit1,
+				expr: &seqExpr{
+					pos: position{line: 201, col: 16, offset: 5847},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 201, col: 16, offset: 5847},
+							run: (*parser).callonDecimalDigit3,
+						},
+						&labeledExpr{
+							pos:   position{line: 201, col: 16, offset: 5847},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 201, col: 16, offset: 5847},
+								val:        "[0-9]",
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "HexDigit",
+			pos:  position{line: 202, col: 1, offset: 5853},
+			expr: &actionExpr{
+				pos: position{line: 202, col: 12, offset: 5866},
+				run: (*parser).callonHexDigit1,
+				expr: &seqExpr{
+					pos: position{line: 202, col: 12, offset: 5866},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 202, col: 12, offset: 5866},
+							run: (*parser).callonHexDigit3,
+						},
+						&labeledExpr{
+							pos:   position{line: 202, col: 12, offset: 5866},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 202, col: 12, offset: 5866},
+								val:        "[0-9a-f]i",
+								ranges:     []rune{'0', '9', 'a', 'f'},
+								ignoreCase: true,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "CharClassMatcher",
+			pos:  position{line: 204, col: 1, offset: 5877},
+			expr: &actionExpr{
+				pos: position{line: 204, col: 20, offset: 5898},
+				run: (*parser).callonCharClassMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 204, col: 20, offset: 5898},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 204, col: 20, offset: 5898},
+							run: (*parser).callonCharClassMatcher3,
+						},
+						&labeledExpr{
+							pos:   position{line: 204, col: 20, offset: 5898},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 204, col: 20, offset: 5898},
+								run: (*parser).callonCharClassMatcher5,
+								expr: &seqExpr{
+									pos: position{line: 204, col: 20, offset: 5898},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 204, col: 20, offset: 5898},
+											val:        "[",
+											ignoreCase: false,
+											want:       "\"[\"",
+										},
+										&zeroOrMoreExpr{
+											pos: position{line: 204, col: 24, offset: 5902},
+											expr: &choiceExpr{
+												pos: position{line: 204, col: 26, offset: 5904},
+												alternatives: []interface{}{
+													&ruleRefExpr{
+														pos:  position{line: 204, col: 26, offset: 5904},
+														name: "ClassCharRange",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 204, col: 43, offset: 5921},
+														name: "ClassChar",
+													},
+													&seqExpr{
+														pos: position{line: 204, col: 55, offset: 5933},
+														exprs: []interface{}{
+															&litMatcher{
+																pos:        position{line: 204, col: 55, offset: 5933},
+																val:        "\\",
+																ignoreCase: false,
+																want:       "\"\\\\\"",
+															},
+															&ruleRefExpr{
+																pos:  position{line: 204, col: 60, offset: 5938},
+																name: "UnicodeClassEscape",
+															},
+														},
+													},
+												},
+											},
+										},
+										&litMatcher{
+											pos:        position{line: 204, col: 82, offset: 5960},
+											val:        "]",
+											ignoreCase: false,
+											want:       "\"]\"",
+										},
+										&zeroOrOneExpr{
+											pos: position{line: 204, col: 86, offset: 5964},
+											expr: &litMatcher{
+												pos:        position{line: 204, col: 86, offset: 5964},
+												val:        "i",
+												ignoreCase: false,
+												want:       "\"i\"",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ClassCharRange",
+			pos:  position{line: 209, col: 1, offset: 6069},
+			expr: &actionExpr{
+				pos: position{line: 209, col: 18, offset: 6088},
+				run: (*parser).callonClassCharRange1,
+				expr: &seqExpr{
+					pos: position{line: 209, col: 18, offset: 6088},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 209, col: 18, offset: 6088},
+							run: (*parser).callonClassCharRange3,
+						},
+						&labeledExpr{
+							pos:   position{line: 209, col: 18, offset: 6088},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 209, col: 18, offset: 6088},
+								exprs: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 209, col: 18, offset: 6088},
+										name: "ClassChar",
+									},
+									&litMatcher{
+										pos:        position{line: 209, col: 28, offset: 6098},
+										val:        "-",
+										ignoreCase: false,
+										want:       "\"-\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 209, col: 32, offset: 6102},
+										name: "ClassChar",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ClassChar",
+			pos:  position{line: 210, col: 1, offset: 6112},
+			expr: &actionExpr{
+				pos: position{line: 210, col: 13, offset: 6126},
+				run: (*parser).callonClassChar1,
+				expr: &seqExpr{
+					pos: position{line: 210, col: 13, offset: 6126},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 210, col: 13, offset: 6126},
+							run: (*parser).callonClassChar3,
+						},
+						&labeledExpr{
+							pos:   position{line: 210, col: 13, offset: 6126},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 210, col: 13, offset: 6126},
+								alternatives: []interface{}{
+									&seqExpr{
+										pos: position{line: 210, col: 13, offset: 6126},
+										exprs: []interface{}{
+											&notExpr{
+												pos: position{line: 210, col: 13, offset: 6126},
+												expr: &choiceExpr{
+													pos: position{line: 210, col: 16, offset: 6129},
+													alternatives: []interface{}{
+														&litMatcher{
+															pos:        position{line: 210, col: 16, offset: 6129},
+															val:        "]",
+															ignoreCase: false,
+															want:       "\"]\"",
+														},
+														&litMatcher{
+															pos:        position{line: 210, col: 22, offset: 6135},
+															val:        "\\",
+															ignoreCase: false,
+															want:       "\"\\\\\"",
+														},
+														&ruleRefExpr{
+															pos:  position{line: 210, col: 29, offset: 6142},
+															name: "EOL",
+														},
+													},
+												},
+											},
+											&ruleRefExpr{
+												pos:  position{line: 210, col: 35, offset: 6148},
+												name: "SourceChar",
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 210, col: 48, offset: 6161},
+										exprs: []interface{}{
+											&litMatcher{
+												pos:        position{line: 210, col: 48, offset: 6161},
+												val:        "\\",
+												ignoreCase: false,
+												want:       "\"\\\\\"",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 210, col: 53, offset: 6166},
+												name: "CharClassEscape",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "CharClassEscape",
+			pos:  position{line: 211, col: 1, offset: 6182},
+			expr: &actionExpr{
+				pos: position{line: 211, col: 19, offset: 6202},
+				run: (*parser).callonCharClassEscape1,
+				expr: &seqExpr{
+					pos: position{line: 211, col: 19, offset: 6202},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 211, col: 19, offset: 6202},
+							run: (*parser).callonCharClassEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 211, col: 19, offset: 6202},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 211, col: 19, offset: 6202},
+								alternatives: []interface{}{
+									&litMatcher{
+										pos:        position{line: 211, col: 19, offset: 6202},
+										val:        "]",
+										ignoreCase: false,
+										want:       "\"]\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 211, col: 25, offset: 6208},
+										name: "CommonEscapeSequence",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "UnicodeClassEscape",
+			pos:  position{line: 213, col: 1, offset: 6230},
+			expr: &actionExpr{
+				pos: position{line: 213, col: 22, offset: 6253},
+				run: (*parser).callonUnicodeClassEscape1,
+				expr: &seqExpr{
+					pos: position{line: 213, col: 22, offset: 6253},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 213, col: 22, offset: 6253},
+							run: (*parser).callonUnicodeClassEscape3,
+						},
+						&labeledExpr{
+							pos:   position{line: 213, col: 22, offset: 6253},
+							label: "pigeonCoverage",
+							expr: &seqExpr{
+								pos: position{line: 213, col: 22, offset: 6253},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 213, col: 22, offset: 6253},
+										val:        "p",
+										ignoreCase: false,
+										want:       "\"p\"",
+									},
+									&choiceExpr{
+										pos: position{line: 213, col: 28, offset: 6259},
+										alternatives: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 213, col: 28, offset: 6259},
+												name: "SingleCharUnicodeClass",
+											},
+											&seqExpr{
+												pos: position{line: 213, col: 53, offset: 6284},
+												exprs: []interface{}{
+													&litMatcher{
+														pos:        position{line: 213, col: 53, offset: 6284},
+														val:        "{",
+														ignoreCase: false,
+														want:       "\"{\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 213, col: 57, offset: 6288},
+														name: "UnicodeClass",
+													},
+													&litMatcher{
+														pos:        position{line: 213, col: 70, offset: 6301},
+														val:        "}",
+														ignoreCase: false,
+														want:       "\"}\"",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SingleCharUnicodeClass",
+			pos:  position{line: 214, col: 1, offset: 6307},
+			expr: &actionExpr{
+				pos: position{line: 214, col: 26, offset: 6334},
+				run: (*parser).callonSingleCharUnicodeClass1,
+				expr: &seqExpr{
+					pos: position{line: 214, col: 26, offset: 6334},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 214, col: 26, offset: 6334},
+							run: (*parser).callonSingleCharUnicodeClass3,
+						},
+						&labeledExpr{
+							pos:   position{line: 214, col: 26, offset: 6334},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 214, col: 26, offset: 6334},
+								val:        "[LMNCPZS]",
+								chars:      []rune{'L', 'M', 'N', 'C', 'P', 'Z', 'S'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "UnicodeClass",
+			pos:  position{line: 215, col: 1, offset: 6344},
+			expr: &actionExpr{
+				pos: position{line: 215, col: 16, offset: 6361},
+				run: (*parser).callonUnicodeClass1,
+				expr: &seqExpr{
+					pos: position{line: 215, col: 16, offset: 6361},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 215, col: 16, offset: 6361},
+							run: (*parser).callonUnicodeClass3,
+						},
+						&labeledExpr{
+							pos:   position{line: 215, col: 16, offset: 6361},
+							label: "pigeonCoverage",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 215, col: 16, offset: 6361},
+								expr: &charClassMatcher{
+									pos:        position{line: 215, col: 16, offset: 6361},
+									val:        "[a-z_]i",
+									chars:      []rune{'_'},
+									ranges:     []rune{'a', 'z'},
+									ignoreCase: true,
+									inverted:   false,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AnyMatcher",
+			pos:  position{line: 217, col: 1, offset: 6371},
+			expr: &actionExpr{
+				pos: position{line: 217, col: 14, offset: 6386},
+				run: (*parser).callonAnyMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 217, col: 14, offset: 6386},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 217, col: 14, offset: 6386},
+							run: (*parser).callonAnyMatcher3,
+						},
+						&labeledExpr{
+							pos:   position{line: 217, col: 14, offset: 6386},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 217, col: 14, offset: 6386},
+								run: (*parser).callonAnyMatcher5,
+								expr: &litMatcher{
+									pos:        position{line: 217, col: 14, offset: 6386},
+									val:        ".",
+									ignoreCase: false,
+									want:       "\".\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "CodeBlock",
+			pos:  position{line: 222, col: 1, offset: 6461},
+			expr: &actionExpr{
+				pos: position{line: 222, col: 13, offset: 6475},
+				run: (*parser).callonCodeBlock1,
+				expr: &seqExpr{
+					pos: position{line: 222, col: 13, offset: 6475},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 222, col: 13, offset: 6475},
+							run: (*parser).callonCodeBlock3,
+						},
+						&labeledExpr{
+							pos:   position{line: 222, col: 13, offset: 6475},
+							label: "pigeonCoverage",
+							expr: &actionExpr{
+								pos: position{line: 222, col: 13, offset: 6475},
+								run: (*parser).callonCodeBlock5,
+								expr: &seqExpr{
+									pos: position{line: 222, col: 13, offset: 6475},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 222, col: 13, offset: 6475},
+											val:        "{",
+											ignoreCase: false,
+											want:       "\"{\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 222, col: 17, offset: 6479},
+											name: "Code",
+										},
+										&litMatcher{
+											pos:        position{line: 222, col: 22, offset: 6484},
+											val:        "}",
+											ignoreCase: false,
+											want:       "\"}\"",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "Code",
+			pos:  position{line: 228, col: 1, offset: 6582},
+			expr: &actionExpr{
+				pos: position{line: 228, col: 8, offset: 6591},
+				run: (*parser).callonCode1,
+				expr: &seqExpr{
+					pos: position{line: 228, col: 8, offset: 6591},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 228, col: 8, offset: 6591},
+							run: (*parser).callonCode3,
+						},
+						&labeledExpr{
+							pos:   position{line: 228, col: 8, offset: 6591},
+							label: "pigeonCoverage",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 228, col: 8, offset: 6591},
+								expr: &choiceExpr{
+									pos: position{line: 228, col: 10, offset: 6593},
+									alternatives: []interface{}{
+										&oneOrMoreExpr{
+											pos: position{line: 228, col: 10, offset: 6593},
+											expr: &seqExpr{
+												pos: position{line: 228, col: 12, offset: 6595},
+												exprs: []interface{}{
+													&notExpr{
+														pos: position{line: 228, col: 12, offset: 6595},
+														expr: &charClassMatcher{
+															pos:        position{line: 228, col: 13, offset: 6596},
+															val:        "[{}]",
+															chars:      []rune{'{', '}'},
+															ignoreCase: false,
+															inverted:   false,
+														},
+													},
+													&ruleRefExpr{
+														pos:  position{line: 228, col: 18, offset: 6601},
+														name: "SourceChar",
+													},
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 228, col: 34, offset: 6617},
+											exprs: []interface{}{
+												&litMatcher{
+													pos:        position{line: 228, col: 34, offset: 6617},
+													val:        "{",
+													ignoreCase: false,
+													want:       "\"{\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 228, col: 38, offset: 6621},
+													name: "Code",
+												},
+												&litMatcher{
+													pos:        position{line: 228, col: 43, offset: 6626},
+													val:        "}",
+													ignoreCase: false,
+													want:       "\"}\"",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "__",
+			pos:  position{line: 230, col: 1, offset: 6634},
+			expr: &actionExpr{
+				pos: position{line: 230, col: 6, offset: 6641},
+				run: (*parser).callon__1,
+				expr: &seqExpr{
+					pos: position{line: 230, col: 6, offset: 6641},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 230, col: 6, offset: 6641},
+							run: (*parser).callon__3,
+						},
+						&labeledExpr{
+							pos:   position{line: 230, col: 6, offset: 6641},
+							label: "pigeonCoverage",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 230, col: 6, offset: 6641},
+								expr: &choiceExpr{
+									pos: position{line: 230, col: 8, offset: 6643},
+									alternatives: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 230, col: 8, offset: 6643},
+											name: "Whitespace",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 230, col: 21, offset: 6656},
+											name: "EOL",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 230, col: 27, offset: 6662},
+											name: "Comment",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "_",
+			pos:  position{line: 231, col: 1, offset: 6673},
+			expr: &actionExpr{
+				pos: position{line: 231, col: 5, offset: 6679},
+				run: (*parser).callon_1,
+				expr: &seqExpr{
+					pos: position{line: 231, col: 5, offset: 6679},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 231, col: 5, offset: 6679},
+							run: (*parser).callon_3,
+						},
+						&labeledExpr{
+							pos:   position{line: 231, col: 5, offset: 6679},
+							label: "pigeonCoverage",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 231, col: 5, offset: 6679},
+								expr: &choiceExpr{
+									pos: position{line: 231, col: 7, offset: 6681},
+									alternatives: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 231, col: 7, offset: 6681},
+											name: "Whitespace",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 231, col: 20, offset: 6694},
+											name: "MultiLineCommentNoLineTerminator",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Whitespace",
+			pos:  position{line: 233, col: 1, offset: 6731},
+			expr: &actionExpr{
+				pos: position{line: 233, col: 14, offset: 6746},
+				run: (*parser).callonWhitespace1,
+				expr: &seqExpr{
+					pos: position{line: 233, col: 14, offset: 6746},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 233, col: 14, offset: 6746},
+							run: (*parser).callonWhitespace3,
+						},
+						&labeledExpr{
+							pos:   position{line: 233, col: 14, offset: 6746},
+							label: "pigeonCoverage",
+							expr: &charClassMatcher{
+								pos:        position{line: 233, col: 14, offset: 6746},
+								val:        "[ \\t\\r]",
+								chars:      []rune{' ', '\t', '\r'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "EOL",
+			pos:  position{line: 234, col: 1, offset: 6754},
+			expr: &actionExpr{
+				pos: position{line: 234, col: 7, offset: 6762},
+				run: (*parser).callonEOL1,
+				expr: &seqExpr{
+					pos: position{line: 234, col: 7, offset: 6762},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 234, col: 7, offset: 6762},
+							run: (*parser).callonEOL3,
+						},
+						&labeledExpr{
+							pos:   position{line: 234, col: 7, offset: 6762},
+							label: "pigeonCoverage",
+							expr: &litMatcher{
+								pos:        position{line: 234, col: 7, offset: 6762},
+								val:        "\n",
+								ignoreCase: false,
+								want:       "\"\\n\"",
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "EOS",
+			pos:  position{line: 235, col: 1, offset: 6767},
+			expr: &actionExpr{
+				pos: position{line: 235, col: 7, offset: 6775},
+				run: (*parser).callonEOS1,
+				expr: &seqExpr{
+					pos: position{line: 235, col: 7, offset: 6775},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 235, col: 7, offset: 6775},
+							run: (*parser).callonEOS3,
+						},
+						&labeledExpr{
+							pos:   position{line: 235, col: 7, offset: 6775},
+							label: "pigeonCoverage",
+							expr: &choiceExpr{
+								pos: position{line: 235, col: 7, offset: 6775},
+								alternatives: []interface{}{
+									&seqExpr{
+										pos: position{line: 235, col: 7, offset: 6775},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 235, col: 7, offset: 6775},
+												name: "__",
+											},
+											&litMatcher{
+												pos:        position{line: 235, col: 10, offset: 6778},
+												val:        ";",
+												ignoreCase: false,
+												want:       "\";\"",
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 235, col: 16, offset: 6784},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 235, col: 16, offset: 6784},
+												name: "_",
+											},
+											&zeroOrOneExpr{
+												pos: position{line: 235, col: 18, offset: 6786},
+												expr: &ruleRefExpr{
+													pos:  position{line: 235, col: 18, offset: 6786},
+													name: "SingleLineComment",
+												},
+											},
+											&ruleRefExpr{
+												pos:  position{line: 235, col: 37, offset: 6805},
+												name: "EOL",
+											},
+										},
+									},
+									&seqExpr{
+										pos: position{line: 235, col: 43, offset: 6811},
+										exprs: []interface{}{
+											&ruleRefExpr{
+												pos:  position{line: 235, col: 43, offset: 6811},
+												name: "__",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 235, col: 46, offset: 6814},
+												name: "EOF",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			memoize: true,
+		},
+		{
+			name: "EOF",
+			pos:  position{line: 237, col: 1, offset: 6819},
+			expr: &actionExpr{
+				pos: position{line: 237, col: 7, offset: 6827},
+				run: (*parser).callonEOF1,
+				expr: &seqExpr{
+					pos: position{line: 237, col: 7, offset: 6827},
+					exprs: []interface{}{
+						&stateCodeExpr{
+							pos: position{line: 237, col: 7, offset: 6827},
+							run: (*parser).callonEOF3,
+						},
+						&labeledExpr{
+							pos:   position{line: 237, col: 7, offset: 6827},
+							label: "pigeonCoverage",
+							expr: &notExpr{
+								pos: position{line: 237, col: 7, offset: 6827},
+								expr: &anyMatcher{
+									line: 237, col: 8, offset: 6828,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// The names of the rules of the grammar, e.g. for the Entrypoint option.
+const (
+	RuleGrammar                          = "Grammar"
+	RuleInitializer                      = "Initializer"
+	RuleRule                             = "Rule"
+	RuleExpression                       = "Expression"
+	RuleChoiceExpr                       = "ChoiceExpr"
+	RuleActionExpr                       = "ActionExpr"
+	RuleSeqExpr                          = "SeqExpr"
+	RuleLabeledExpr                      = "LabeledExpr"
+	RulePrefixedExpr                     = "PrefixedExpr"
+	RulePrefixedOp                       = "PrefixedOp"
+	RuleSuffixedExpr                     = "SuffixedExpr"
+	RuleSuffixedOp                       = "SuffixedOp"
+	RulePrimaryExpr                      = "PrimaryExpr"
+	RuleRuleRefExpr                      = "RuleRefExpr"
+	RuleSemanticPredExpr                 = "SemanticPredExpr"
+	RuleSemanticPredOp                   = "SemanticPredOp"
+	RuleRuleDefOp                        = "RuleDefOp"
+	RuleSourceChar                       = "SourceChar"
+	RuleComment                          = "Comment"
+	RuleMultiLineComment                 = "MultiLineComment"
+	RuleMultiLineCommentNoLineTerminator = "MultiLineCommentNoLineTerminator"
+	RuleSingleLineComment                = "SingleLineComment"
+	RuleIdentifier                       = "Identifier"
+	RuleIdentifierName                   = "IdentifierName"
+	RuleIdentifierStart                  = "IdentifierStart"
+	RuleIdentifierPart                   = "IdentifierPart"
+	RuleLitMatcher                       = "LitMatcher"
+	RuleStringLiteral                    = "StringLiteral"
+	RuleDoubleStringChar                 = "DoubleStringChar"
+	RuleSingleStringChar                 = "SingleStringChar"
+	RuleRawStringChar                    = "RawStringChar"
+	RuleDoubleStringEscape               = "DoubleStringEscape"
+	RuleSingleStringEscape               = "SingleStringEscape"
+	RuleCommonEscapeSequence             = "CommonEscapeSequence"
+	RuleSingleCharEscape                 = "SingleCharEscape"
+	RuleOctalEscape                      = "OctalEscape"
+	RuleHexEscape                        = "HexEscape"
+	RuleLongUnicodeEscape                = "LongUnicodeEscape"
+	RuleShortUnicodeEscape               = "ShortUnicodeEscape"
+	RuleOctalDigit                       = "OctalDigit"
+	RuleDecimalDigit                     = "DecimalDigit"
+	RuleHexDigit                         = "HexDigit"
+	RuleCharClassMatcher                 = "CharClassMatcher"
+	RuleClassCharRange                   = "ClassCharRange"
+	RuleClassChar                        = "ClassChar"
+	RuleCharClassEscape                  = "CharClassEscape"
+	RuleUnicodeClassEscape               = "UnicodeClassEscape"
+	RuleSingleCharUnicodeClass           = "SingleCharUnicodeClass"
+	RuleUnicodeClass                     = "UnicodeClass"
+	RuleAnyMatcher                       = "AnyMatcher"
+	RuleCodeBlock                        = "CodeBlock"
+	RuleCode                             = "Code"
+	Rule__                               = "__"
+	Rule_                                = "_"
+	RuleWhitespace                       = "Whitespace"
+	RuleEOL                              = "EOL"
+	RuleEOS                              = "EOS"
+	RuleEOF                              = "EOF"
+)
+
+func (c *current) onGrammar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Grammar"]++
+	return nil
+}
+
+func (p *parser) callonGrammar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onGrammar3()
+}
+
+func (c *current) onGrammar5(initializer, rules interface{}) (interface{}, error) {
+	pos := c.astPos()
+
+	// create the grammar, assign its initializer
+	g := ast.NewGrammar(pos)
+	initSlice := toIfaceSlice(initializer)
+	if len(initSlice) > 0 {
+		g.Init = initSlice[0].(*ast.CodeBlock)
+	}
+
+	rulesSlice := toIfaceSlice(rules)
+	g.Rules = make([]*ast.Rule, len(rulesSlice))
+	for i, duo := range rulesSlice {
+		g.Rules[i] = duo.([]interface{})[0].(*ast.Rule)
+	}
+
+	return g, nil
+}
+
+func (p *parser) callonGrammar5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onGrammar5(stack["initializer"], stack["rules"])
+}
+
+func (c *current) onGrammar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonGrammar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onGrammar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onInitializer3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Initializer"]++
+	return nil
+}
+
+func (p *parser) callonInitializer3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onInitializer3()
+}
+
+func (c *current) onInitializer5(code interface{}) (interface{}, error) {
+	return code, nil
+}
+
+func (p *parser) callonInitializer5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onInitializer5(stack["code"])
+}
+
+func (c *current) onInitializer1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonInitializer1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onInitializer1(stack["pigeonCoverage"])
+}
+
+func (c *current) onRule3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Rule"]++
+	return nil
+}
+
+func (p *parser) callonRule3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRule3()
+}
+
+func (c *current) onRule5(name, display, expr interface{}) (interface{}, error) {
+	pos := c.astPos()
+
+	rule := ast.NewRule(pos, name.(*ast.Identifier))
+	displaySlice := toIfaceSlice(display)
+	if len(displaySlice) > 0 {
+		rule.DisplayName = displaySlice[0].(*ast.StringLit)
+	}
+	rule.Expr = expr.(ast.Expression)
+
+	return rule, nil
+}
+
+func (p *parser) callonRule5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRule5(stack["name"], stack["display"], stack["expr"])
+}
+
+func (c *current) onRule1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonRule1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRule1(stack["pigeonCoverage"])
+}
+
+func (c *current) onExpression3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Expression"]++
+	return nil
+}
+
+func (p *parser) callonExpression3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onExpression3()
+}
+
+func (c *current) onExpression1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonExpression1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onExpression1(stack["pigeonCoverage"])
+}
+
+func (c *current) onChoiceExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["ChoiceExpr"]++
+	return nil
+}
+
+func (p *parser) callonChoiceExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onChoiceExpr3()
+}
+
+func (c *current) onChoiceExpr5(first, rest interface{}) (interface{}, error) {
+	restSlice := toIfaceSlice(rest)
+	if len(restSlice) == 0 {
+		return first, nil
+	}
+
+	pos := c.astPos()
+	choice := ast.NewChoiceExpr(pos)
+	choice.Alternatives = []ast.Expression{first.(ast.Expression)}
+	for _, sl := range restSlice {
+		choice.Alternatives = append(choice.Alternatives, sl.([]interface{})[3].(ast.Expression))
+	}
+	return choice, nil
+}
+
+func (p *parser) callonChoiceExpr5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onChoiceExpr5(stack["first"], stack["rest"])
+}
+
+func (c *current) onChoiceExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonChoiceExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onChoiceExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onActionExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["ActionExpr"]++
+	return nil
+}
+
+func (p *parser) callonActionExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onActionExpr3()
+}
+
+func (c *current) onActionExpr5(expr, code interface{}) (interface{}, error) {
+	if code == nil {
+		return expr, nil
+	}
+
+	pos := c.astPos()
+	act := ast.NewActionExpr(pos)
+	act.Expr = expr.(ast.Expression)
+	codeSlice := toIfaceSlice(code)
+	act.Code = codeSlice[1].(*ast.CodeBlock)
+
+	return act, nil
+}
+
+func (p *parser) callonActionExpr5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onActionExpr5(stack["expr"], stack["code"])
+}
+
+func (c *current) onActionExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonActionExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onActionExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSeqExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SeqExpr"]++
+	return nil
+}
+
+func (p *parser) callonSeqExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSeqExpr3()
+}
+
+func (c *current) onSeqExpr5(first, rest interface{}) (interface{}, error) {
+	restSlice := toIfaceSlice(rest)
+	if len(restSlice) == 0 {
+		return first, nil
+	}
+	seq := ast.NewSeqExpr(c.astPos())
+	seq.Exprs = []ast.Expression{first.(ast.Expression)}
+	for _, sl := range restSlice {
+		seq.Exprs = append(seq.Exprs, sl.([]interface{})[1].(ast.Expression))
+	}
+	return seq, nil
+}
+
+func (p *parser) callonSeqExpr5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSeqExpr5(stack["first"], stack["rest"])
+}
+
+func (c *current) onSeqExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSeqExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSeqExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onLabeledExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["LabeledExpr"]++
+	return nil
+}
+
+func (p *parser) callonLabeledExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLabeledExpr3()
+}
+
+func (c *current) onLabeledExpr6(label, expr interface{}) (interface{}, error) {
+	pos := c.astPos()
+	lab := ast.NewLabeledExpr(pos)
+	lab.Label = label.(*ast.Identifier)
+	lab.Expr = expr.(ast.Expression)
+	return lab, nil
+}
+
+func (p *parser) callonLabeledExpr6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLabeledExpr6(stack["label"], stack["expr"])
+}
+
+func (c *current) onLabeledExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonLabeledExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLabeledExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onPrefixedExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["PrefixedExpr"]++
+	return nil
+}
+
+func (p *parser) callonPrefixedExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedExpr3()
+}
+
+func (c *current) onPrefixedExpr6(op, expr interface{}) (interface{}, error) {
+	pos := c.astPos()
+	opStr := op.(string)
+	if opStr == "&" {
+		and := ast.NewAndExpr(pos)
+		and.Expr = expr.(ast.Expression)
+		return and, nil
+	}
+	not := ast.NewNotExpr(pos)
+	not.Expr = expr.(ast.Expression)
+	return not, nil
+}
+
+func (p *parser) callonPrefixedExpr6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedExpr6(stack["op"], stack["expr"])
+}
+
+func (c *current) onPrefixedExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonPrefixedExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onPrefixedOp3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["PrefixedOp"]++
+	return nil
+}
+
+func (p *parser) callonPrefixedOp3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedOp3()
+}
+
+func (c *current) onPrefixedOp5() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonPrefixedOp5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedOp5()
+}
+
+func (c *current) onPrefixedOp1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonPrefixedOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrefixedOp1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSuffixedExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SuffixedExpr"]++
+	return nil
+}
+
+func (p *parser) callonSuffixedExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedExpr3()
+}
+
+func (c *current) onSuffixedExpr6(expr, op interface{}) (interface{}, error) {
+	pos := c.astPos()
+	opStr := op.(string)
+	switch opStr {
+	case "?":
+		zero := ast.NewZeroOrOneExpr(pos)
+		zero.Expr = expr.(ast.Expression)
+		return zero, nil
+	case "*":
+		zero := ast.NewZeroOrMoreExpr(pos)
+		zero.Expr = expr.(ast.Expression)
+		return zero, nil
+	case "+":
+		one := ast.NewOneOrMoreExpr(pos)
+		one.Expr = expr.(ast.Expression)
+		return one, nil
+	default:
+		return nil, errors.New("unknown operator: " + opStr)
+	}
+}
+
+func (p *parser) callonSuffixedExpr6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedExpr6(stack["expr"], stack["op"])
+}
+
+func (c *current) onSuffixedExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSuffixedExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSuffixedOp3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SuffixedOp"]++
+	return nil
+}
+
+func (p *parser) callonSuffixedOp3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedOp3()
+}
+
+func (c *current) onSuffixedOp5() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonSuffixedOp5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedOp5()
+}
+
+func (c *current) onSuffixedOp1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSuffixedOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedOp1(stack["pigeonCoverage"])
+}
+
+func (c *current) onPrimaryExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["PrimaryExpr"]++
+	return nil
+}
+
+func (p *parser) callonPrimaryExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrimaryExpr3()
+}
+
+func (c *current) onPrimaryExpr11(expr interface{}) (interface{}, error) {
+	return expr, nil
+}
+
+func (p *parser) callonPrimaryExpr11() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrimaryExpr11(stack["expr"])
+}
+
+func (c *current) onPrimaryExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonPrimaryExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrimaryExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onRuleRefExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["RuleRefExpr"]++
+	return nil
+}
+
+func (p *parser) callonRuleRefExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleRefExpr3()
+}
+
+func (c *current) onRuleRefExpr5(name interface{}) (interface{}, error) {
+	ref := ast.NewRuleRefExpr(c.astPos())
+	ref.Name = name.(*ast.Identifier)
+	return ref, nil
+}
+
+func (p *parser) callonRuleRefExpr5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleRefExpr5(stack["name"])
+}
+
+func (c *current) onRuleRefExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonRuleRefExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleRefExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSemanticPredExpr3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SemanticPredExpr"]++
+	return nil
+}
+
+func (p *parser) callonSemanticPredExpr3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredExpr3()
+}
+
+func (c *current) onSemanticPredExpr5(op, code interface{}) (interface{}, error) {
+	opStr := op.(string)
+	if opStr == "&" {
+		and := ast.NewAndCodeExpr(c.astPos())
+		and.Code = code.(*ast.CodeBlock)
+		return and, nil
+	}
+	not := ast.NewNotCodeExpr(c.astPos())
+	not.Code = code.(*ast.CodeBlock)
+	return not, nil
+}
+
+func (p *parser) callonSemanticPredExpr5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredExpr5(stack["op"], stack["code"])
+}
+
+func (c *current) onSemanticPredExpr1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSemanticPredExpr1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredExpr1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSemanticPredOp3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SemanticPredOp"]++
+	return nil
+}
+
+func (p *parser) callonSemanticPredOp3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredOp3()
+}
+
+func (c *current) onSemanticPredOp5() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonSemanticPredOp5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredOp5()
+}
+
+func (c *current) onSemanticPredOp1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSemanticPredOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSemanticPredOp1(stack["pigeonCoverage"])
+}
+
+func (c *current) onRuleDefOp3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["RuleDefOp"]++
+	return nil
+}
+
+func (p *parser) callonRuleDefOp3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleDefOp3()
+}
+
+func (c *current) onRuleDefOp1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonRuleDefOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleDefOp1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSourceChar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SourceChar"]++
+	return nil
+}
+
+func (p *parser) callonSourceChar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSourceChar3()
+}
+
+func (c *current) onSourceChar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSourceChar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSourceChar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onComment3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Comment"]++
+	return nil
+}
+
+func (p *parser) callonComment3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComment3()
+}
+
+func (c *current) onComment1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonComment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComment1(stack["pigeonCoverage"])
+}
+
+func (c *current) onMultiLineComment3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["MultiLineComment"]++
+	return nil
+}
+
+func (p *parser) callonMultiLineComment3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiLineComment3()
+}
+
+func (c *current) onMultiLineComment1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonMultiLineComment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiLineComment1(stack["pigeonCoverage"])
+}
+
+func (c *current) onMultiLineCommentNoLineTerminator3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["MultiLineCommentNoLineTerminator"]++
+	return nil
+}
+
+func (p *parser) callonMultiLineCommentNoLineTerminator3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiLineCommentNoLineTerminator3()
+}
+
+func (c *current) onMultiLineCommentNoLineTerminator1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonMultiLineCommentNoLineTerminator1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiLineCommentNoLineTerminator1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSingleLineComment3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SingleLineComment"]++
+	return nil
+}
+
+func (p *parser) callonSingleLineComment3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleLineComment3()
+}
+
+func (c *current) onSingleLineComment1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSingleLineComment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleLineComment1(stack["pigeonCoverage"])
+}
+
+func (c *current) onIdentifier3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Identifier"]++
+	return nil
+}
+
+func (p *parser) callonIdentifier3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifier3()
+}
+
+func (c *current) onIdentifier1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonIdentifier1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifier1(stack["pigeonCoverage"])
+}
+
+func (c *current) onIdentifierName3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["IdentifierName"]++
+	return nil
+}
+
+func (p *parser) callonIdentifierName3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierName3()
+}
+
+func (c *current) onIdentifierName5() (interface{}, error) {
+	return ast.NewIdentifier(c.astPos(), string(c.text)), nil
+}
+
+func (p *parser) callonIdentifierName5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierName5()
+}
+
+func (c *current) onIdentifierName1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonIdentifierName1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierName1(stack["pigeonCoverage"])
+}
+
+func (c *current) onIdentifierStart3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["IdentifierStart"]++
+	return nil
+}
+
+func (p *parser) callonIdentifierStart3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierStart3()
+}
+
+func (c *current) onIdentifierStart1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonIdentifierStart1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierStart1(stack["pigeonCoverage"])
+}
+
+func (c *current) onIdentifierPart3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["IdentifierPart"]++
+	return nil
+}
+
+func (p *parser) callonIdentifierPart3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierPart3()
+}
+
+func (c *current) onIdentifierPart1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonIdentifierPart1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIdentifierPart1(stack["pigeonCoverage"])
+}
+
+func (c *current) onLitMatcher3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["LitMatcher"]++
+	return nil
+}
+
+func (p *parser) callonLitMatcher3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLitMatcher3()
+}
+
+func (c *current) onLitMatcher5(lit, ignore interface{}) (interface{}, error) {
+	rawStr := lit.(*ast.StringLit).Val
+	s, err := strconv.Unquote(rawStr)
+	if err != nil {
+		return nil, err
+	}
+	m := ast.NewLitMatcher(c.astPos(), s)
+	m.IgnoreCase = ignore != nil
+	return m, nil
+}
+
+func (p *parser) callonLitMatcher5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLitMatcher5(stack["lit"], stack["ignore"])
+}
+
+func (c *current) onLitMatcher1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonLitMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLitMatcher1(stack["pigeonCoverage"])
+}
+
+func (c *current) onStringLiteral3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["StringLiteral"]++
+	return nil
+}
+
+func (p *parser) callonStringLiteral3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStringLiteral3()
+}
+
+func (c *current) onStringLiteral5() (interface{}, error) {
+	return ast.NewStringLit(c.astPos(), string(c.text)), nil
+}
+
+func (p *parser) callonStringLiteral5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStringLiteral5()
+}
+
+func (c *current) onStringLiteral1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonStringLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStringLiteral1(stack["pigeonCoverage"])
+}
+
+func (c *current) onDoubleStringChar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["DoubleStringChar"]++
+	return nil
+}
+
+func (p *parser) callonDoubleStringChar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDoubleStringChar3()
+}
+
+func (c *current) onDoubleStringChar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonDoubleStringChar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDoubleStringChar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSingleStringChar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SingleStringChar"]++
+	return nil
+}
+
+func (p *parser) callonSingleStringChar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleStringChar3()
+}
+
+func (c *current) onSingleStringChar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSingleStringChar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleStringChar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onRawStringChar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["RawStringChar"]++
+	return nil
+}
+
+func (p *parser) callonRawStringChar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRawStringChar3()
+}
+
+func (c *current) onRawStringChar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonRawStringChar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRawStringChar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onDoubleStringEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["DoubleStringEscape"]++
+	return nil
+}
+
+func (p *parser) callonDoubleStringEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDoubleStringEscape3()
+}
+
+func (c *current) onDoubleStringEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonDoubleStringEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDoubleStringEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSingleStringEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SingleStringEscape"]++
+	return nil
+}
+
+func (p *parser) callonSingleStringEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleStringEscape3()
+}
+
+func (c *current) onSingleStringEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSingleStringEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleStringEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onCommonEscapeSequence3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["CommonEscapeSequence"]++
+	return nil
+}
+
+func (p *parser) callonCommonEscapeSequence3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCommonEscapeSequence3()
+}
+
+func (c *current) onCommonEscapeSequence1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonCommonEscapeSequence1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCommonEscapeSequence1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSingleCharEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SingleCharEscape"]++
+	return nil
+}
+
+func (p *parser) callonSingleCharEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleCharEscape3()
+}
+
+func (c *current) onSingleCharEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSingleCharEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleCharEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onOctalEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["OctalEscape"]++
+	return nil
+}
+
+func (p *parser) callonOctalEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOctalEscape3()
+}
+
+func (c *current) onOctalEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonOctalEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOctalEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onHexEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["HexEscape"]++
+	return nil
+}
+
+func (p *parser) callonHexEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHexEscape3()
+}
+
+func (c *current) onHexEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonHexEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHexEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onLongUnicodeEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["LongUnicodeEscape"]++
+	return nil
+}
+
+func (p *parser) callonLongUnicodeEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLongUnicodeEscape3()
+}
+
+func (c *current) onLongUnicodeEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonLongUnicodeEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLongUnicodeEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onShortUnicodeEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["ShortUnicodeEscape"]++
+	return nil
+}
+
+func (p *parser) callonShortUnicodeEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onShortUnicodeEscape3()
+}
+
+func (c *current) onShortUnicodeEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonShortUnicodeEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onShortUnicodeEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onOctalDigit3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["OctalDigit"]++
+	return nil
+}
+
+func (p *parser) callonOctalDigit3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOctalDigit3()
+}
+
+func (c *current) onOctalDigit1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonOctalDigit1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOctalDigit1(stack["pigeonCoverage"])
+}
+
+func (c *current) onDecimalDigit3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["DecimalDigit"]++
+	return nil
+}
+
+func (p *parser) callonDecimalDigit3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDecimalDigit3()
+}
+
+func (c *current) onDecimalDigit1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonDecimalDigit1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDecimalDigit1(stack["pigeonCoverage"])
+}
+
+func (c *current) onHexDigit3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["HexDigit"]++
+	return nil
+}
+
+func (p *parser) callonHexDigit3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHexDigit3()
+}
+
+func (c *current) onHexDigit1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonHexDigit1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHexDigit1(stack["pigeonCoverage"])
+}
+
+func (c *current) onCharClassMatcher3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["CharClassMatcher"]++
+	return nil
+}
+
+func (p *parser) callonCharClassMatcher3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharClassMatcher3()
+}
+
+func (c *current) onCharClassMatcher5() (interface{}, error) {
+	pos := c.astPos()
+	cc := ast.NewCharClassMatcher(pos, string(c.text))
+	return cc, nil
+}
+
+func (p *parser) callonCharClassMatcher5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharClassMatcher5()
+}
+
+func (c *current) onCharClassMatcher1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonCharClassMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharClassMatcher1(stack["pigeonCoverage"])
+}
+
+func (c *current) onClassCharRange3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["ClassCharRange"]++
+	return nil
+}
+
+func (p *parser) callonClassCharRange3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onClassCharRange3()
+}
+
+func (c *current) onClassCharRange1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonClassCharRange1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onClassCharRange1(stack["pigeonCoverage"])
+}
+
+func (c *current) onClassChar3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["ClassChar"]++
+	return nil
+}
+
+func (p *parser) callonClassChar3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onClassChar3()
+}
+
+func (c *current) onClassChar1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonClassChar1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onClassChar1(stack["pigeonCoverage"])
+}
+
+func (c *current) onCharClassEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["CharClassEscape"]++
+	return nil
+}
+
+func (p *parser) callonCharClassEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharClassEscape3()
+}
+
+func (c *current) onCharClassEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonCharClassEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCharClassEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onUnicodeClassEscape3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["UnicodeClassEscape"]++
+	return nil
+}
+
+func (p *parser) callonUnicodeClassEscape3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnicodeClassEscape3()
+}
+
+func (c *current) onUnicodeClassEscape1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonUnicodeClassEscape1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnicodeClassEscape1(stack["pigeonCoverage"])
+}
+
+func (c *current) onSingleCharUnicodeClass3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["SingleCharUnicodeClass"]++
+	return nil
+}
+
+func (p *parser) callonSingleCharUnicodeClass3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleCharUnicodeClass3()
+}
+
+func (c *current) onSingleCharUnicodeClass1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonSingleCharUnicodeClass1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSingleCharUnicodeClass1(stack["pigeonCoverage"])
+}
+
+func (c *current) onUnicodeClass3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["UnicodeClass"]++
+	return nil
+}
+
+func (p *parser) callonUnicodeClass3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnicodeClass3()
+}
+
+func (c *current) onUnicodeClass1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonUnicodeClass1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUnicodeClass1(stack["pigeonCoverage"])
+}
+
+func (c *current) onAnyMatcher3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["AnyMatcher"]++
+	return nil
+}
+
+func (p *parser) callonAnyMatcher3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAnyMatcher3()
+}
+
+func (c *current) onAnyMatcher5() (interface{}, error) {
+	any := ast.NewAnyMatcher(c.astPos(), ".")
+	return any, nil
+}
+
+func (p *parser) callonAnyMatcher5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAnyMatcher5()
+}
+
+func (c *current) onAnyMatcher1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonAnyMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAnyMatcher1(stack["pigeonCoverage"])
+}
+
+func (c *current) onCodeBlock3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["CodeBlock"]++
+	return nil
+}
+
+func (p *parser) callonCodeBlock3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCodeBlock3()
+}
+
+func (c *current) onCodeBlock5() (interface{}, error) {
+	pos := c.astPos()
+	cb := ast.NewCodeBlock(pos, string(c.text))
+	return cb, nil
+}
+
+func (p *parser) callonCodeBlock5() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCodeBlock5()
+}
+
+func (c *current) onCodeBlock1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonCodeBlock1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCodeBlock1(stack["pigeonCoverage"])
+}
+
+func (c *current) onCode3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Code"]++
+	return nil
+}
+
+func (p *parser) callonCode3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCode3()
+}
+
+func (c *current) onCode1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonCode1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCode1(stack["pigeonCoverage"])
+}
+
+func (c *current) on__3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["__"]++
+	return nil
+}
+
+func (p *parser) callon__3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.on__3()
+}
+
+func (c *current) on__1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callon__1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.on__1(stack["pigeonCoverage"])
+}
+
+func (c *current) on_3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["_"]++
+	return nil
+}
+
+func (p *parser) callon_3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.on_3()
+}
+
+func (c *current) on_1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callon_1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.on_1(stack["pigeonCoverage"])
+}
+
+func (c *current) onWhitespace3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["Whitespace"]++
+	return nil
+}
+
+func (p *parser) callonWhitespace3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onWhitespace3()
+}
+
+func (c *current) onWhitespace1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonWhitespace1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onWhitespace1(stack["pigeonCoverage"])
+}
+
+func (c *current) onEOL3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["EOL"]++
+	return nil
+}
+
+func (p *parser) callonEOL3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOL3()
+}
+
+func (c *current) onEOL1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonEOL1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOL1(stack["pigeonCoverage"])
+}
+
+func (c *current) onEOS3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["EOS"]++
+	return nil
+}
+
+func (p *parser) callonEOS3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOS3()
+}
+
+func (c *current) onEOS1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonEOS1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOS1(stack["pigeonCoverage"])
+}
+
+func (c *current) onEOF3() error {
+	hits, _ := c.state["pigeon.coverage"].(map[string]int)
+	if hits == nil {
+		hits = make(map[string]int)
+		c.state["pigeon.coverage"] = hits
+	}
+	hits["EOF"]++
+	return nil
+}
+
+func (p *parser) callonEOF3() error {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOF3()
+}
+
+func (c *current) onEOF1(pigeonCoverage interface{}) (interface{}, error) {
+	return pigeonCoverage, nil
+}
+
+func (p *parser) callonEOF1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEOF1(stack["pigeonCoverage"])
+}
+
+var (
+	// errNoRule is returned when the grammar to parse has no rule.
+	errNoRule = errors.New("grammar has no rule")
+
+	// errInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	errInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// errInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	errInvalidEncoding = errors.New("invalid encoding")
+
+	// errMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	errMaxExprCnt = &LimitError{Kind: LimitExpressions, msg: "max number of expresssions parsed"}
+
+	// errMaxSteps is used to signal that the maximum number of attempts
+	// to match an input with a matcher has been exceeded.
+	errMaxSteps = &LimitError{Kind: LimitSteps, msg: "max number of matcher steps exceeded"}
+
+	// errMaxDepth is used to signal that the maximum depth of nested
+	// rules has been exceeded.
+	errMaxDepth = &LimitError{Kind: LimitDepth, msg: "max depth of nested rules exceeded"}
+)
+
+// LimitKind is the kind of limit that stopped the parsing, see LimitError.
+type LimitKind int
+
+// List of the kinds of limits.
+const (
+	// LimitExpressions is the limit set by the MaxExpressions option.
+	LimitExpressions LimitKind = iota + 1
+	// LimitDepth is the limit set by the MaxDepth option.
+	LimitDepth
+	// LimitSteps is the limit set by the MaxSteps option.
+	LimitSteps
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case LimitExpressions:
+		return "expressions"
+	case LimitDepth:
+		return "depth"
+	case LimitSteps:
+		return "steps"
+	}
+	return "LimitKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// LimitError is the error that stops the parsing when one of the limits
+// set by the MaxExpressions, MaxDepth and MaxSteps options is exceeded, so
+// that this resource-limit abort can be told apart from a syntax error,
+// e.g. with errors.As on the error returned by the Parse* functions.
+type LimitError struct {
+	Kind LimitKind
+	msg  string
+}
+
+// Error returns the error message.
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option func(*parser) Option
+
+// MaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func MaxExpressions(maxExprCnt uint64) Option {
+	return func(p *parser) Option {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return MaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// MaxSteps creates an Option to stop parsing with an error once the
+// matchers (literal, character class, any and beginning of line matchers)
+// have been tried more than n times in total. Unlike MaxExpressions, which
+// limits the number of expressions parsed, it accounts for every attempt
+// to match the input, including the ones that fail and the ones repeated
+// after a backtrack, so it bounds the work done on adversarial inputs
+// that force exponential backtracking. If n <= 0, the number of steps is
+// not limited.
+//
+// The default for n is 0.
+func MaxSteps(n int) Option {
+	return func(p *parser) Option {
+		oldMaxSteps := p.maxSteps
+		p.maxSteps = n
+		return MaxSteps(oldMaxSteps)
+	}
+}
+
+// MaxDepth creates an Option to stop parsing with an error once more than
+// n rules are nested, e.g. to bound the recursion on deeply nested inputs.
+// If n <= 0, the depth is not limited.
+//
+// The default for n is 0.
+func MaxDepth(n int) Option {
+	return func(p *parser) Option {
+		oldMaxDepth := p.maxDepth
+		p.maxDepth = n
+		return MaxDepth(oldMaxDepth)
+	}
+}
+
+// OnProgress creates an Option to set a function that is called as the
+// parser advances through the input, e.g. to render a progress bar. It
+// is called with the furthest offset reached in the input, in bytes,
+// since the parser may backtrack, and with the size of the input, or -1
+// while it is unknown when parsing from an io.RuneReader. It is called
+// each time the furthest offset advances by at least the interval set by
+// the ProgressInterval option, and once more when the parsing completes.
+//
+// The default is to not report the progress.
+func OnProgress(f func(offset, total int)) Option {
+	return func(p *parser) Option {
+		oldOnProgress := p.onProgress
+		p.onProgress = f
+		return OnProgress(oldOnProgress)
+	}
+}
+
+// ProgressInterval creates an Option to set the number of bytes by which
+// the furthest offset reached in the input must advance between two calls
+// of the function set by the OnProgress option, so that its overhead
+// stays negligible. If n <= 0, it is called each time the furthest
+// offset advances.
+//
+// The default for n is 4096.
+func ProgressInterval(n int) Option {
+	return func(p *parser) Option {
+		oldInterval := p.progressInterval
+		p.progressInterval = n
+		return ProgressInterval(oldInterval)
+	}
+}
+
+// Entrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func Entrypoint(ruleName string) Option {
+	return func(p *parser) Option {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		if ruleName == "" {
+			p.entrypoint = g.rules[0].name
+		}
+		return Entrypoint(oldEntrypoint)
+	}
+}
+
+// ValidEntrypoints returns the names of the rules that may be used as
+// entrypoint with the Entrypoint option, in the order of the grammar.
+func ValidEntrypoints() []string {
+	names := make([]string, 0, len(g.rules))
+	for _, r := range g.rules {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// Statistics adds a user provided Stats struct to the parser to allow
+// the user to process the results after the parsing has finished.
+// Also the key for the "no match" counter is set.
+//
+// Example usage:
+//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
+func Statistics(stats *Stats, choiceNoMatch string) Option {
+	return func(p *parser) Option {
+		oldStats := p.Stats
+		p.Stats = stats
+		oldChoiceNoMatch := p.choiceNoMatch
+		p.choiceNoMatch = choiceNoMatch
+		if p.Stats.ChoiceAltCnt == nil {
+			p.Stats.ChoiceAltCnt = make(map[string]map[string]int)
+		}
+		return Statistics(oldStats, oldChoiceNoMatch)
+	}
+}
+
+// Debug creates an Option to set the debug flag to b. When set to true,
+// debugging information is printed to stdout while parsing.
+//
+// The default is false.
+func Debug(b bool) Option {
+	return func(p *parser) Option {
+		old := p.debug
+		p.debug = b
+		return Debug(old)
+	}
+}
+
+// Memoize creates an Option to set the memoize flag to b. When set to true,
+// the parser will cache all results so each expression is evaluated only
+// once. This guarantees linear parsing time even for pathological cases,
+// at the expense of more memory and slower times for typical cases.
+//
+// The memoized results are never shared across calls to the Parse*
+// functions: the memoization table is empty when the parsing starts, so
+// the results obtained with an entrypoint (see Entrypoint) can't be used
+// when parsing with another one, even if the same input is parsed.
+//
+// The default is false.
+func Memoize(b bool) Option {
+	return memoize(b, false)
+}
+
+// MemoizeRules creates an Option to memoize only the results of the rules
+// selected when the parser was generated. As opposed to Memoize, the
+// results of the other rules and of the expressions are not cached, which
+// trades the linear parsing time guarantee for less memory.
+//
+// By default, the rules that are referenced from at least two places in
+// the grammar are selected, as the rules referenced once never benefit
+// from memoization.
+func MemoizeRules(b bool) Option {
+	return memoize(b, b)
+}
+
+func memoize(b, rules bool) Option {
+	return func(p *parser) Option {
+		old, oldRules := p.memoize, p.memoizeRules
+		p.memoize = b
+		p.memoizeRules = rules
+		return memoize(old, oldRules)
+	}
+}
+
+// AllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func AllowInvalidUTF8(b bool) Option {
+	return func(p *parser) Option {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return AllowInvalidUTF8(old)
+	}
+}
+
+// StripBOM creates an Option to skip the UTF-8 byte order mark (U+FEFF)
+// at the start of the input, if any, e.g. for the files saved by some
+// Windows editors. The reported positions are then relative to the content
+// that follows the byte order mark, and it is not part of the matched
+// text. If it is not set, the byte order mark is parsed like any other
+// rune, so that the offsets match the raw bytes of the input.
+//
+// The default is false.
+func StripBOM(b bool) Option {
+	return func(p *parser) Option {
+		old := p.stripBOM
+		p.stripBOM = b
+		return StripBOM(old)
+	}
+}
+
+// Recover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func Recover(b bool) Option {
+	return func(p *parser) Option {
+		old := p.recover
+		p.recover = b
+		return Recover(old)
+	}
+}
+
+// PartialResult creates an Option to set the partialResult flag to b.
+// When set to true, the parser returns a partial result along with the
+// error when the entry rule fails, instead of a nil value. The partial
+// result is the list of the values of the elements that matched in the
+// sequence of the entry rule that went the furthest in the input before
+// failing, e.g. the values of the statements that were parsed before the
+// failing one. It is not the value of an action, as the action code is
+// not executed for a failed match.
+//
+// The partial result is best-effort: it is nil if the entry rule fails
+// before any element of a sequence matched, if the entry rule has no
+// sequence, or if the parsing fails because of a panic.
+//
+// The default is false.
+func PartialResult(b bool) Option {
+	return func(p *parser) Option {
+		old := p.partialResult
+		p.partialResult = b
+		return PartialResult(old)
+	}
+}
+
+// Diagnostics creates an Option to collect the diagnostics recorded by the
+// code blocks with current.Warn and current.Diag in diags. The diagnostics
+// are appended to diags as they are recorded, and they do not fail the
+// parse. If this option is not set, the diagnostics are discarded.
+func Diagnostics(diags *[]Diagnostic) Option {
+	return func(p *parser) Option {
+		old := p.diags
+		p.diags = diags
+		return Diagnostics(old)
+	}
+}
+
+// GlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func GlobalStore(key string, value interface{}) Option {
+	return func(p *parser) Option {
+		old := p.cur.globalStore[key]
+		p.cur.globalStore[key] = value
+		return GlobalStore(key, old)
+	}
+}
+
+// TransactionalStore creates an Option to tie the values stored under
+// the provided keys in the globalStore to the backtracking of the parser.
+// When an alternative of a choice expression is tried, the values of
+// those keys are saved and, if the alternative does not match, they are
+// restored before the next alternative is tried. Values that implement
+// the Cloner interface are cloned when saved, other values are copied
+// as-is. Only the listed keys are saved, as copying the whole globalStore
+// for every alternative would be expensive.
+//
+// The default is to not restore any key of the globalStore.
+func TransactionalStore(keys ...string) Option {
+	return func(p *parser) Option {
+		old := p.txKeys
+		p.txKeys = keys
+		return TransactionalStore(old...)
+	}
+}
+
+// InitState creates an Option to set a key to a certain value in
+// the global "state" store.
+func InitState(key string, value interface{}) Option {
+	return func(p *parser) Option {
+		old := p.cur.state[key]
+		p.cur.state[key] = value
+		return InitState(key, old)
+	}
+}
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (i interface{}, err error) { // nolint: deadcode
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return ParseReader(filename, f, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) { // nolint: deadcode
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(filename, b, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return newParser(filename, b, opts...).parse(g)
+}
+
+// ParseRuneReader parses the runes read from r using filename as information
+// in the error messages. The runes are consumed as the parser advances,
+// so any decoder that produces runes (e.g. from UTF-16 or another encoding)
+// can be used without a separate transcoding pass. The runes read so far
+// are buffered to allow backtracking, and the offsets reported in positions
+// and errors are byte offsets in the UTF-8 encoding of those runes.
+func ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error) { // nolint: deadcode
+	p := newParser(filename, nil, opts...)
+	p.rr = r
+	return p.parse(g)
+}
+
+// ParserPool is a pool of parsers that reuses the memory allocated by a
+// parser for the following parses, which is useful when parsing a lot of
+// small inputs. Each parse starts with a parser that is reset, so that no
+// state, error or memoized result is shared between parses. The zero
+// value is ready to use, and a ParserPool is safe for concurrent use.
+type ParserPool struct {
+	pool sync.Pool
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages, like Parse, with a parser taken from the pool.
+func (pp *ParserPool) Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	p := pp.get(filename, b, opts...)
+	defer pp.put(p)
+	return p.parse(g)
+}
+
+// get returns a parser of the pool, reset to parse the data from b using
+// filename as information in the error messages and configured with the
+// options. The parser must be returned to the pool with put once the
+// parse is done.
+func (pp *ParserPool) get(filename string, b []byte, opts ...Option) *parser {
+	p, ok := pp.pool.Get().(*parser)
+	if !ok {
+		return newParser(filename, b, opts...)
+	}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// put returns the parser p to the pool. It must not be used after that.
+func (pp *ParserPool) put(p *parser) {
+	// do not keep the input and the results alive while in the pool.
+	p.Reset("", nil)
+	pp.pool.Put(p)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col) + " [" + strconv.Itoa(p.offset) + "]"
+}
+
+// Span is the range of the input text matched by an expression, e.g. to
+// underline it in error messages. Start is the position of the first rune
+// of the match and End is the position right after the last rune, the
+// columns are counted in runes so multibyte characters count as one column.
+type Span struct {
+	Start position
+	End   position
+}
+
+func (s Span) String() string {
+	return s.Start.String() + "-" + s.End.String()
+}
+
+// Diagnostic is a message recorded by a code block with current.Warn or
+// current.Diag, e.g. to report the use of a deprecated syntax, without
+// failing the parse.
+type Diagnostic struct {
+	Pos      position
+	Severity string
+	Msg      string
+}
+
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Severity + ": " + d.Msg
+}
+
+// savepoint stores all state required to go back to this point in the
+// parser.
+type savepoint struct {
+	position
+	rn rune
+	w  int
+}
+
+type current struct {
+	pos  position // start position of the match
+	end  position // end position of the match
+	text []byte   // raw text of the match
+
+	// ruleName is the name of the innermost rule being parsed.
+	ruleName string
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+
+	// parser is the parser that runs the code blocks, used by the helpers
+	// that inspect the input.
+	parser *parser
+}
+
+// Span returns the span of the current match. It is only meaningful in
+// action code blocks.
+func (c *current) Span() Span {
+	return Span{Start: c.pos, End: c.end}
+}
+
+// TextUnsafe returns the raw text of the current match as a subslice of
+// the input, like c.text, but even if c.text was modified by the code
+// block. Neither is a copy of the input: the returned slice must be
+// treated as read-only, and it must not be retained after the parse if
+// the input may be modified or reused. It is only meaningful in action
+// code blocks.
+func (c *current) TextUnsafe() []byte {
+	return c.parser.data[c.pos.offset:c.end.offset]
+}
+
+// Warn records a diagnostic of severity "warning" with the message msg,
+// as Diag does.
+func (c *current) Warn(msg string) {
+	c.Diag("warning", msg)
+}
+
+// Diag records a diagnostic of the given severity with the message msg at
+// the start position of the current match, which is only meaningful in
+// action code blocks. Unlike an error returned by a code block, it does not
+// fail the parse. The diagnostics are collected by the Diagnostics option,
+// and they are not removed if the parser backtracks after the code block
+// ran.
+func (c *current) Diag(severity, msg string) {
+	p := c.parser
+	if p.diags == nil {
+		return
+	}
+	*p.diags = append(*p.diags, Diagnostic{Pos: c.pos, Severity: severity, Msg: msg})
+}
+
+// Mark is a checkpoint of the parser, as returned by c.Mark, that a code
+// block may restore later with c.Restore.
+type Mark struct {
+	parser *parser
+	pt     savepoint
+	state  storeDict
+}
+
+// Offset returns the byte offset of the position of the mark.
+func (m Mark) Offset() int {
+	return m.pt.offset
+}
+
+// Mark returns a checkpoint of the current position of the parser and of
+// the state store, e.g. to implement a custom lookahead or commit logic
+// not expressible in the grammar. In an action code block, the position is
+// the end of the match.
+func (c *current) Mark() Mark {
+	p := c.parser
+	m := Mark{parser: p, pt: p.pt}
+	m.state = p.cloneState()
+	return m
+}
+
+// Restore moves the parser back (or forward) to the position of the mark
+// m, and restores the state store as it was when the mark was created. The
+// parser then continues from that position after the code block returns.
+//
+// The mark must have been created during the same parse. It returns an
+// error if it was created by another parser or beyond the input read so
+// far, but the marks of a previous parse of a parser reused by a
+// ParserPool are not detected. As the changes to the state store made by
+// action and predicate code blocks are always rolled back, the state is
+// only restored by a state change code block. Note that with memoization, the result of
+// a rule whose code blocks call Restore is memoized with the position
+// where the parser ended up, and the code blocks are not run again when
+// the memoized result is used.
+func (c *current) Restore(m Mark) error {
+	p := c.parser
+	if m.parser != p {
+		return errors.New("invalid mark: not created by this parser")
+	}
+	if m.pt.offset > len(p.data) {
+		return errors.New("invalid mark: beyond the input read so far")
+	}
+	p.restoreMark(m)
+	return nil
+}
+
+// restoreMark restores the parser to the mark m.
+func (p *parser) restoreMark(m Mark) {
+	p.restore(m.pt)
+	p.cur.state.Discard()
+	// keep the state of the mark intact so that it may be restored again
+	p.cur.state = m.state
+	p.cur.state = p.cloneState()
+}
+
+// LineIndent returns the width of the leading whitespace of the line of
+// the current position of the parser, where each space and each tab counts
+// for one column. It may be used in predicate and state change code blocks
+// of off-side rule grammars, even before the leading whitespace is matched.
+func (c *current) LineIndent() int {
+	p := c.parser
+	start := p.pt.offset
+	for start > 0 && p.data[start-1] != '\n' {
+		start--
+	}
+	n := 0
+	for {
+		if start+n >= len(p.data) {
+			if p.rr == nil {
+				break
+			}
+			// read ahead from the rune reader, without moving the parser.
+			p.readRune()
+			continue
+		}
+		if b := p.data[start+n]; b != ' ' && b != '\t' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// indentKey is the key of the indentation stack in the state store.
+const indentKey = "pigeon.indent"
+
+// indentStack returns the indentation stack stored in the state.
+func (c *current) indentStack() []int {
+	stack, _ := c.state[indentKey].([]int)
+	return stack
+}
+
+// PushIndent pushes the indentation level n on the indentation stack. As
+// the stack is kept in the state store, it must be called from a state
+// change code block, and it is rolled back if the rule fails.
+func (c *current) PushIndent(n int) {
+	stack := c.indentStack()
+	// copy the stack so that the saved states are not modified.
+	c.state[indentKey] = append(stack[:len(stack):len(stack)], n)
+}
+
+// PopIndent pops the indentation level at the top of the indentation
+// stack and returns it, or returns 0 if the stack is empty. Like
+// PushIndent, it must be called from a state change code block.
+func (c *current) PopIndent() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	c.state[indentKey] = stack[: len(stack)-1 : len(stack)-1]
+	return stack[len(stack)-1]
+}
+
+// IndentLevel returns the indentation level at the top of the
+// indentation stack, or 0 if the stack is empty.
+func (c *current) IndentLevel() int {
+	stack := c.indentStack()
+	if len(stack) == 0 {
+		return 0
+	}
+	return stack[len(stack)-1]
+}
+
+// SameIndent returns true if the line of the current position is indented
+// at the level at the top of the indentation stack, e.g. for a predicate
+// such as &{ return c.SameIndent(), nil }.
+func (c *current) SameIndent() bool {
+	return c.LineIndent() == c.IndentLevel()
+}
+
+// MoreIndented returns true if the line of the current position is
+// indented more than the level at the top of the indentation stack, i.e.
+// if it starts a new indented block.
+func (c *current) MoreIndented() bool {
+	return c.LineIndent() > c.IndentLevel()
+}
+
+type storeDict map[string]interface{}
+
+// the AST types...
+
+// nolint: structcheck
+type grammar struct {
+	pos   position
+	rules []*rule
+}
+
+// nolint: structcheck
+type rule struct {
+	pos         position
+	name        string
+	displayName string
+	expr        interface{}
+	// memoize is set if the results of the rule are memoized with the
+	// MemoizeRules option.
+	memoize bool
+}
+
+// nolint: structcheck
+type choiceExpr struct {
+	pos          position
+	alternatives []interface{}
+	skipVals     bool
+	longest      bool
+}
+
+// nolint: structcheck
+type actionExpr struct {
+	pos  position
+	expr interface{}
+	run  func(*parser) (interface{}, error)
+}
+
+// nolint: structcheck
+type recoveryExpr struct {
+	pos          position
+	expr         interface{}
+	recoverExpr  interface{}
+	failureLabel []string
+}
+
+// nolint: structcheck
+type seqExpr struct {
+	pos   position
+	exprs []interface{}
+	// 1-based index of the values buffer of the parser that is reused
+	// for the values of the sequence, 0 if the buffer is not reused.
+	valsIx int
+}
+
+// nolint: structcheck
+type throwExpr struct {
+	pos   position
+	label string
+}
+
+// nolint: structcheck
+type labeledExpr struct {
+	pos   position
+	label string
+	expr  interface{}
+}
+
+// nolint: structcheck
+type expr struct {
+	pos      position
+	expr     interface{}
+	skipVals bool
+}
+
+type andExpr expr        // nolint: structcheck
+type notExpr expr        // nolint: structcheck
+type zeroOrOneExpr expr  // nolint: structcheck
+type zeroOrMoreExpr expr // nolint: structcheck
+type oneOrMoreExpr expr  // nolint: structcheck
+
+// nolint: structcheck
+type ruleRefExpr struct {
+	pos  position
+	name string
+}
+
+// nolint: structcheck
+type stateCodeExpr struct {
+	pos position
+	run func(*parser) error
+}
+
+// nolint: structcheck
+type andCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+// nolint: structcheck
+type notCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+// nolint: structcheck
+type litMatcher struct {
+	pos        position
+	val        string
+	ignoreCase bool
+	want       string
+	invert     bool
+}
+
+// nolint: structcheck
+type charClassMatcher struct {
+	pos             position
+	val             string
+	basicLatinChars [128]bool
+	chars           []rune
+	ranges          []rune
+	classes         []*unicode.RangeTable
+	ignoreCase      bool
+	inverted        bool
+}
+
+type anyMatcher position // nolint: structcheck
+
+type bolMatcher position // nolint: structcheck
+
+// errList cumulates the errors found by the parser.
+type errList []error
+
+func (e *errList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e errList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *errList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+// FurthestPos returns the furthest position where the parser failed to
+// match, among the errors of the list, e.g. to show where the parsing gave
+// up.
+func (e errList) FurthestPos() position {
+	var pos position
+	for _, err := range e {
+		if pe, ok := err.(*parserError); ok && pe.furthest.offset >= pos.offset {
+			pos = pe.furthest
+		}
+	}
+	return pos
+}
+
+func (e errList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// As finds the first error in the list that matches target, as defined by
+// errors.As, and sets target to that error.
+func (e errList) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// parserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type parserError struct {
+	Inner    error
+	pos      position
+	span     Span
+	furthest position
+	prefix   string
+	expected []string
+}
+
+// Error returns the error message.
+func (p *parserError) Error() string {
+	return p.prefix + ": " + p.Inner.Error()
+}
+
+// Unwrap returns the original error.
+func (p *parserError) Unwrap() error {
+	return p.Inner
+}
+
+// Span returns the span of the input text that caused the error. For
+// errors returned by action code blocks, it is the span matched by the
+// action's expression, and for a failed match it is the rune where the
+// parser failed to match (empty at the end of the input).
+func (p *parserError) Span() Span {
+	return p.span
+}
+
+// FurthestPos returns the furthest position where the parser failed to
+// match when the error occurred. For the errors that occur while a
+// failure is recovered, it is the furthest position when the failure was
+// thrown.
+func (p *parserError) FurthestPos() position {
+	return p.furthest
+}
+
+// newParser creates a parser with the specified input source and options.
+func newParser(filename string, b []byte, opts ...Option) *parser {
+	p := &parser{}
+	p.Reset(filename, b)
+	p.setOptions(opts)
+	return p
+}
+
+// Reset resets the parser so that it parses the data from b using
+// filename as information in the error messages, as if it was newly
+// created without any option. The errors, the statistics, the state,
+// the global store and the memoization table of the previous parse are
+// all cleared, but the memory allocated for them is reused when it is
+// safe to do so. The memoization table is always rebuilt by parse.
+func (p *parser) Reset(filename string, b []byte) {
+	state := p.cur.state
+	if state == nil {
+		state = make(storeDict)
+	}
+	for k := range state {
+		delete(state, k)
+	}
+	globalStore := p.cur.globalStore
+	if globalStore == nil {
+		globalStore = make(storeDict)
+	}
+	for k := range globalStore {
+		delete(globalStore, k)
+	}
+
+	*p = parser{
+		filename: filename,
+		// the errors are returned to the caller, so they are never reused.
+		errs: new(errList),
+		data: b,
+		pt:   savepoint{position: position{line: 1}},
+		cur: current{
+			state:       state,
+			globalStore: globalStore,
+		},
+		recover:          true,
+		vstack:           p.vstack[:0],
+		rstack:           p.rstack[:0],
+		maxFailPos:       position{col: 1, line: 1},
+		maxFailExpected:  p.maxFailExpected[:0],
+		maxExprCnt:       math.MaxUint64,
+		progressInterval: 4096,
+		Stats: &Stats{
+			ChoiceAltCnt: make(map[string]map[string]int),
+		},
+		// start rule is rule [0] unless an alternate entrypoint is specified
+		entrypoint:      g.rules[0].name,
+		recoveryStack:   p.recoveryStack[:0],
+		recoveryFailPos: p.recoveryFailPos[:0],
+	}
+	p.cur.parser = p
+	if p.maxFailExpected == nil {
+		p.maxFailExpected = make([]string, 0, 20)
+	}
+}
+
+// setOptions applies the options to the parser.
+func (p *parser) setOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+}
+
+// nolint: structcheck,deadcode
+type resultTuple struct {
+	v   interface{}
+	b   bool
+	end savepoint
+}
+
+// nolint: varcheck
+const choiceNoMatch = -1
+
+// Stats stores some statistics, gathered during parsing
+type Stats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// Steps counts the number of attempts to match the input with a
+	// matcher, including the failed ones. This value is compared to the
+	// maximum number of steps allowed (set by the MaxSteps option).
+	Steps uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+// ChoiceStat is the number of matches of an alternative of an ordered
+// choice expression, as counted in Stats.ChoiceAltCnt.
+type ChoiceStat struct {
+	// Choice is the outer key of ChoiceAltCnt, composed of the name of the
+	// rule and the line and column of the ordered choice.
+	Choice string
+	// Alternative is the inner key of ChoiceAltCnt, the number (one-based)
+	// of the alternative or the name of the "no match" counter.
+	Alternative string
+	// Count is the number of matches of the alternative.
+	Count int
+}
+
+// SortedRules returns the entries of ChoiceAltCnt in a deterministic order:
+// sorted by choice, then by descending count and then by alternative.
+func (s *Stats) SortedRules() []ChoiceStat {
+	var stats []ChoiceStat
+	for choice, alts := range s.ChoiceAltCnt {
+		for alt, cnt := range alts {
+			stats = append(stats, ChoiceStat{Choice: choice, Alternative: alt, Count: cnt})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Choice != stats[j].Choice {
+			return stats[i].Choice < stats[j].Choice
+		}
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Alternative < stats[j].Alternative
+	})
+	return stats
+}
+
+// nolint: structcheck,maligned
+type parser struct {
+	filename string
+	pt       savepoint
+	cur      current
+
+	data []byte
+	errs *errList
+
+	// rune source for ParseRuneReader, the runes read from it are
+	// appended to data as UTF-8 so that the parser can backtrack.
+	// It is set to nil once it is exhausted.
+	rr io.RuneReader
+
+	depth   int
+	recover bool
+	debug   bool
+
+	memoize bool
+	// if set, only the results of the rules with the memoize field set
+	// are memoized
+	memoizeRules bool
+	// memoization table for the packrat algorithm:
+	// map[offset in source] map[expression or rule] {value, match}
+	memo map[int]map[interface{}]resultTuple
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*rule
+	// variables stack, map of label to value
+	vstack []map[string]interface{}
+	// rule stack, allows identification of the current rule in errors
+	rstack []*rule
+
+	// parse fail
+	maxFailPos            position
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// keys of the globalStore tied to the backtracking of the parser
+	txKeys []string
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// max number of attempts to match with a matcher, if > 0
+	maxSteps int
+	// max number of nested rules, if > 0
+	maxDepth int
+
+	// if set, called as the furthest offset reached advances
+	onProgress func(offset, total int)
+	// min advance of the furthest offset between two calls of onProgress
+	progressInterval int
+	// furthest offset reached, and offset from which onProgress is called
+	progressOffset int
+	progressNext   int
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+	// if set, a leading byte order mark is skipped
+	stripBOM bool
+
+	// if set, the diagnostics recorded by the code blocks are appended to it
+	diags *[]Diagnostic
+
+	// if set, the partial result is returned when the parsing fails
+	partialResult bool
+	// values of the failed sequence of the entry rule that matched the
+	// furthest, and the offset where its last matched element ends
+	partial    []interface{}
+	partialEnd int
+
+	*Stats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]interface{}
+	// furthest failure positions of the failures being recovered
+	recoveryFailPos []position
+	// values buffers reused by the sequences, by index, so that they are
+	// not shared between parsers
+	seqVals [][]interface{}
+}
+
+// push a variable set on the vstack.
+func (p *parser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]interface{})
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *parser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *parser) pushRecovery(labels []string, expr interface{}) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]interface{}, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *parser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *parser) print(prefix, s string) string {
+	if !p.debug {
+		return s
+	}
+
+	fmt.Printf("%s %d:%d:%d: %s [%#U]\n",
+		prefix, p.pt.line, p.pt.col, p.pt.offset, s, p.pt.rn)
+	return s
+}
+
+func (p *parser) in(s string) string {
+	p.depth++
+	return p.print(strings.Repeat(" ", p.depth)+">", s)
+}
+
+func (p *parser) out(s string) string {
+	p.depth--
+	return p.print(strings.Repeat(" ", p.depth)+"<", s)
+}
+
+func (p *parser) addErr(err error) {
+	p.addErrAt(err, p.pt.position, []string{})
+}
+
+func (p *parser) addErrAt(err error, pos position, expected []string) {
+	p.addErrSpan(err, Span{Start: pos, End: pos}, expected)
+}
+
+func (p *parser) addErrSpan(err error, span Span, expected []string) {
+	pos := span.Start
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.line, pos.col, pos.offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.displayName != "" {
+			buf.WriteString("rule " + rule.displayName)
+		} else {
+			buf.WriteString("rule " + rule.name)
+		}
+	}
+	furthest := p.maxFailPos
+	if n := len(p.recoveryFailPos); n > 0 {
+		furthest = p.recoveryFailPos[n-1]
+	}
+	pe := &parserError{Inner: err, pos: pos, span: span, furthest: furthest, prefix: buf.String(), expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *parser) failAt(fail bool, pos position, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.offset < p.maxFailPos.offset {
+			return
+		}
+
+		if pos.offset > p.maxFailPos.offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+
+	}
+}
+
+// failRule reports the failure of the rule that started at pos using its
+// display name, instead of the expected values of its expression, if the
+// rule did not match past its starting position. The failPos and failLen
+// are the farthest failure position and the number of expected values
+// when the rule started.
+func (p *parser) failRule(rule *rule, pos, failPos position, failLen int) {
+	if p.maxFailInvertExpected || p.maxFailPos.offset > pos.offset {
+		return
+	}
+	if p.maxFailPos.offset == pos.offset {
+		if failPos.offset != pos.offset {
+			failLen = 0
+		}
+		p.maxFailExpected = p.maxFailExpected[:failLen]
+	}
+	// the display name is the raw string literal of the grammar
+	p.failAt(false, pos, rule.displayName[1:len(rule.displayName)-1])
+}
+
+// read advances the parser to the next rune.
+func (p *parser) read() {
+	p.pt.offset += p.pt.w
+	if p.rr != nil && p.pt.offset >= len(p.data) {
+		p.readRune()
+	}
+	if p.onProgress != nil && p.pt.offset > p.progressOffset {
+		p.progress()
+	}
+	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.col++
+	if rn == '\n' {
+		p.pt.line++
+		p.pt.col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(errInvalidEncoding)
+		}
+	}
+}
+
+// progress records the current offset as the furthest offset reached,
+// and calls onProgress if it advanced enough since the previous call.
+func (p *parser) progress() {
+	p.progressOffset = p.pt.offset
+	if p.progressOffset >= p.progressNext {
+		p.onProgress(p.progressOffset, p.progressTotal())
+		p.progressNext = p.progressOffset + p.progressInterval
+	}
+}
+
+// progressTotal returns the size of the input, or -1 if it is not known
+// yet.
+func (p *parser) progressTotal() int {
+	if p.rr != nil {
+		return -1
+	}
+	return len(p.data)
+}
+
+// readRune reads the next rune from the rune reader and appends it to
+// the buffered data.
+func (p *parser) readRune() {
+	rn, _, err := p.rr.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			p.addErr(err)
+		}
+		p.rr = nil
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], rn)
+	p.data = append(p.data, buf[:n]...)
+}
+
+// skipBOM removes the UTF-8 byte order mark at the start of the input, if
+// any, so that the positions are relative to the content that follows it.
+func (p *parser) skipBOM() {
+	if p.rr != nil && len(p.data) == 0 {
+		p.readRune()
+	}
+	if rn, n := utf8.DecodeRune(p.data); rn == '\uFEFF' {
+		p.data = p.data[n:]
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *parser) restore(pt savepoint) {
+	if p.debug {
+		defer p.out(p.in("restore"))
+	}
+	if pt.offset == p.pt.offset {
+		return
+	}
+	p.pt = pt
+}
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+//
+// This is used in conjunction with the global state feature and the
+// TransactionalStore option to create proper copies of the state to allow
+// the parser to properly restore the state in the case of backtracking.
+type Cloner interface {
+	Clone() interface{}
+}
+
+// snapshotGlobalStore returns a copy of the transactional keys of the
+// globalStore.
+func (p *parser) snapshotGlobalStore() storeDict {
+	snapshot := make(storeDict, len(p.txKeys))
+	for _, k := range p.txKeys {
+		v, ok := p.cur.globalStore[k]
+		if !ok {
+			continue
+		}
+		if c, ok := v.(Cloner); ok {
+			snapshot[k] = c.Clone()
+		} else {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// restoreGlobalStore restores the transactional keys of the globalStore
+// to the values of the snapshot.
+func (p *parser) restoreGlobalStore(snapshot storeDict) {
+	for _, k := range p.txKeys {
+		if v, ok := snapshot[k]; ok {
+			p.cur.globalStore[k] = v
+		} else {
+			delete(p.cur.globalStore, k)
+		}
+	}
+}
+
+var statePool = &sync.Pool{
+	New: func() interface{} { return make(storeDict) },
+}
+
+func (sd storeDict) Discard() {
+	for k := range sd {
+		delete(sd, k)
+	}
+	statePool.Put(sd)
+}
+
+// clone and return parser current state.
+func (p *parser) cloneState() storeDict {
+	if p.debug {
+		defer p.out(p.in("cloneState"))
+	}
+
+	state := statePool.Get().(storeDict)
+	for k, v := range p.cur.state {
+		if c, ok := v.(Cloner); ok {
+			state[k] = c.Clone()
+		} else {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+// restore parser current state to the state storeDict.
+// every restoreState should applied only one time for every cloned state
+func (p *parser) restoreState(state storeDict) {
+	if p.debug {
+		defer p.out(p.in("restoreState"))
+	}
+	p.cur.state.Discard()
+	p.cur.state = state
+}
+
+// runeSpan returns the span of the rune at pos, or an empty span at pos
+// if it is at the end of the input.
+func (p *parser) runeSpan(pos position) Span {
+	end := pos
+	if pos.offset < len(p.data) {
+		_, n := utf8.DecodeRune(p.data[pos.offset:])
+		end.offset += n
+		end.col++
+	}
+	return Span{Start: pos, End: end}
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *parser) sliceFrom(start savepoint) []byte {
+	return p.data[start.position.offset:p.pt.position.offset]
+}
+
+func (p *parser) getMemoized(node interface{}) (resultTuple, bool) {
+	if len(p.memo) == 0 {
+		return resultTuple{}, false
+	}
+	m := p.memo[p.pt.offset]
+	if len(m) == 0 {
+		return resultTuple{}, false
+	}
+	res, ok := m[node]
+	return res, ok
+}
+
+func (p *parser) setMemoized(pt savepoint, node interface{}, tuple resultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[interface{}]resultTuple)
+	}
+	m := p.memo[pt.offset]
+	if m == nil {
+		m = make(map[interface{}]resultTuple)
+		p.memo[pt.offset] = m
+	}
+	m[node] = tuple
+}
+
+func (p *parser) buildRulesTable(g *grammar) {
+	p.rules = make(map[string]*rule, len(g.rules))
+	for _, r := range g.rules {
+		p.rules[r.name] = r
+	}
+}
+
+// nolint: gocyclo
+func (p *parser) parse(g *grammar) (val interface{}, err error) {
+	if len(g.rules) == 0 {
+		p.addErr(errNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+
+	// the memoized results depend on the entrypoint, as the rules may
+	// behave differently depending on the state set by the entry rule,
+	// so they are never reused across parses.
+	p.memo = nil
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				if p.debug {
+					defer p.out(p.in("panic handler"))
+				}
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(errInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	if p.stripBOM {
+		p.skipBOM()
+	}
+	p.read() // advance to first rune
+	val, ok = p.parseRule(startRule)
+	if p.onProgress != nil {
+		p.onProgress(p.progressOffset, p.progressTotal())
+	}
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			p.addNoMatchErr()
+		}
+
+		if p.partial != nil {
+			return p.partial, p.errs.err()
+		}
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+// addNoMatchErr adds the "no match found" error, with the values expected
+// at the farthest position where the parser failed.
+func (p *parser) addNoMatchErr() {
+	maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+	for _, v := range p.maxFailExpected {
+		maxFailExpectedMap[v] = struct{}{}
+	}
+	expected := make([]string, 0, len(maxFailExpectedMap))
+	eof := false
+	if _, ok := maxFailExpectedMap["!."]; ok {
+		delete(maxFailExpectedMap, "!.")
+		eof = true
+	}
+	for k := range maxFailExpectedMap {
+		expected = append(expected, k)
+	}
+	sort.Strings(expected)
+	if eof {
+		expected = append(expected, "EOF")
+	}
+	p.addErrSpan(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.runeSpan(p.maxFailPos), expected)
+}
+
+// setPartial records the values of the elements of a sequence of the
+// entry rule that matched before the sequence failed, if it matched
+// further than the previously recorded ones.
+func (p *parser) setPartial(vals []interface{}) {
+	if len(p.rstack) != 1 || p.pt.offset <= p.partialEnd {
+		return
+	}
+	p.partial = append([]interface{}(nil), vals...)
+	p.partialEnd = p.pt.offset
+}
+
+func listJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return strings.Join(list[:len(list)-1], sep) + " " + lastSep + " " + list[len(list)-1]
+	}
+}
+
+func (p *parser) parseRule(rule *rule) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRule " + rule.name))
+	}
+
+	memoize := p.memoize && (!p.memoizeRules || rule.memoize)
+	if memoize {
+		res, ok := p.getMemoized(rule)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+	}
+
+	start := p.pt
+	failPos, failLen := p.maxFailPos, len(p.maxFailExpected)
+	p.rstack = append(p.rstack, rule)
+	if p.maxDepth > 0 && len(p.rstack) > p.maxDepth {
+		panic(errMaxDepth)
+	}
+	ruleName := p.cur.ruleName
+	p.cur.ruleName = rule.name
+	p.pushV()
+	val, ok := p.parseExpr(rule.expr)
+	p.popV()
+	p.cur.ruleName = ruleName
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+
+	if !ok && rule.displayName != "" {
+		p.failRule(rule, start.position, failPos, failLen)
+	}
+
+	if memoize {
+		p.setMemoized(start, rule, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+// nolint: gocyclo
+func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
+	var pt savepoint
+
+	memoize := p.memoize && !p.memoizeRules
+	if memoize {
+		res, ok := p.getMemoized(expr)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+		pt = p.pt
+	}
+
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(errMaxExprCnt)
+	}
+
+	var val interface{}
+	var ok bool
+	switch expr := expr.(type) {
+	case *actionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *andCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *andExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *anyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *bolMatcher:
+		val, ok = p.parseBOLMatcher(expr)
+	case *charClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *choiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *labeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *litMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *notCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *notExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *oneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *recoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *ruleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *seqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *stateCodeExpr:
+		val, ok = p.parseStateCodeExpr(expr)
+	case *throwExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *zeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *zeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	if memoize {
+		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseActionExpr"))
+	}
+
+	start := p.pt
+	val, ok := p.parseExpr(act.expr)
+	if ok {
+		p.cur.pos = start.position
+		p.cur.end = p.pt.position
+		// the capacity is limited so that appending to c.text does not
+		// overwrite the input that follows the match
+		text := p.sliceFrom(start)
+		p.cur.text = text[:len(text):len(text)]
+		state := p.cloneState()
+		actVal, err := act.run(p)
+		if err != nil {
+			p.addErrSpan(err, Span{Start: start.position, End: p.pt.position}, []string{})
+		}
+		p.restoreState(state)
+
+		val = actVal
+	}
+	if ok && p.debug {
+		p.print(strings.Repeat(" ", p.depth)+"MATCH", string(p.sliceFrom(start)))
+	}
+	return val, ok
+}
+
+func (p *parser) parseAndCodeExpr(and *andCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := and.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, ok
+}
+
+func (p *parser) parseAndExpr(and *andExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	_, ok := p.parseExpr(and.expr)
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, ok
+}
+
+// step counts an attempt to match the input with a matcher, and stops
+// the parsing if the maximum number of steps is exceeded.
+func (p *parser) step() {
+	p.Steps++
+	if p.maxSteps > 0 && p.Steps > uint64(p.maxSteps) {
+		panic(errMaxSteps)
+	}
+}
+
+func (p *parser) parseAnyMatcher(any *anyMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAnyMatcher"))
+	}
+
+	p.step()
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.position, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.position, ".")
+	return p.sliceFrom(start), true
+}
+
+func (p *parser) parseBOLMatcher(bol *bolMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseBOLMatcher"))
+	}
+
+	p.step()
+	// the previous byte is always available, even when parsing from a rune
+	// reader, as the runes read are kept in data for backtracking.
+	if p.pt.offset == 0 || p.data[p.pt.offset-1] == '\n' {
+		return nil, true
+	}
+	p.failAt(false, p.pt.position, "^")
+	return nil, false
+}
+
+// nolint: gocyclo
+func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseCharClassMatcher"))
+	}
+
+	p.step()
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.position, chr.val)
+		return nil, false
+	}
+
+	if chr.ignoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.chars {
+		if rn == cur {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.ranges); i += 2 {
+		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.classes {
+		if unicode.Is(cl, cur) {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.inverted {
+		p.read()
+		p.failAt(true, start.position, chr.val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.position, chr.val)
+	return nil, false
+}
+
+func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
+	choiceIdent := fmt.Sprintf("%s %d:%d", p.rstack[len(p.rstack)-1].name, ch.pos.line, ch.pos.col)
+	m := p.ChoiceAltCnt[choiceIdent]
+	if m == nil {
+		m = make(map[string]int)
+		p.ChoiceAltCnt[choiceIdent] = m
+	}
+	// We increment altI by 1, so the keys do not start at 0
+	alt := strconv.Itoa(altI + 1)
+	if altI == choiceNoMatch {
+		alt = p.choiceNoMatch
+	}
+	m[alt]++
+}
+
+func (p *parser) parseChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseChoiceExpr"))
+	}
+
+	if ch.longest {
+		return p.parseLongestChoiceExpr(ch)
+	}
+	for altI, alt := range ch.alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		state := p.cloneState()
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
+
+		if !ch.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(alt)
+		if !ch.skipVals {
+			p.popV()
+		}
+		if ok {
+			p.incChoiceAltCnt(ch, altI)
+			return val, ok
+		}
+		p.restoreState(state)
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
+	}
+	p.incChoiceAltCnt(ch, choiceNoMatch)
+	return nil, false
+}
+
+// parseLongestChoiceExpr tries all the alternatives of the choice, from
+// the same starting point, and keeps the one that consumes the most input,
+// the first one in case of a tie. The state and the global store are
+// those left by the kept alternative.
+func (p *parser) parseLongestChoiceExpr(ch *choiceExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLongestChoiceExpr"))
+	}
+
+	start := p.pt
+	var (
+		bestVal interface{}
+		bestPt  savepoint
+		bestAlt = -1
+
+		bestSnapshot storeDict
+	)
+	var bestState storeDict
+
+	for altI, alt := range ch.alternatives {
+		state := p.cloneState()
+		var snapshot storeDict
+		if len(p.txKeys) > 0 {
+			snapshot = p.snapshotGlobalStore()
+		}
+
+		if !ch.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(alt)
+		if !ch.skipVals {
+			p.popV()
+		}
+		if ok && (bestAlt < 0 || p.pt.offset > bestPt.offset) {
+			bestVal, bestPt, bestAlt = val, p.pt, altI
+			if bestState != nil {
+				bestState.Discard()
+			}
+			bestState, p.cur.state = p.cur.state, state
+			if snapshot != nil {
+				bestSnapshot = p.snapshotGlobalStore()
+			}
+		} else {
+			p.restoreState(state)
+		}
+		p.restore(start)
+		if snapshot != nil {
+			p.restoreGlobalStore(snapshot)
+		}
+	}
+
+	if bestAlt < 0 {
+		p.incChoiceAltCnt(ch, choiceNoMatch)
+		return nil, false
+	}
+	p.incChoiceAltCnt(ch, bestAlt)
+	p.restoreState(bestState)
+	if bestSnapshot != nil {
+		p.restoreGlobalStore(bestSnapshot)
+	}
+	p.restore(bestPt)
+	return bestVal, true
+}
+
+func (p *parser) parseLabeledExpr(lab *labeledExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLabeledExpr"))
+	}
+
+	p.pushV()
+	val, ok := p.parseExpr(lab.expr)
+	p.popV()
+	if ok && lab.label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.label] = val
+	}
+	return val, ok
+}
+
+func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLitMatcher"))
+	}
+
+	p.step()
+	if lit.invert {
+		p.maxFailInvertExpected = !p.maxFailInvertExpected
+	}
+
+	start := p.pt
+	for _, want := range lit.val {
+		cur := p.pt.rn
+		if cur != want && !(lit.ignoreCase && equalFold(cur, want)) {
+			p.failAt(false, start.position, lit.want)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.position, lit.want)
+
+	if lit.invert {
+		p.maxFailInvertExpected = !p.maxFailInvertExpected
+	}
+	return p.sliceFrom(start), true
+}
+
+// equalFold returns true if the runes r and s are equal under simple
+// Unicode case folding, as in strings.EqualFold.
+func equalFold(r, s rune) bool {
+	if r == s {
+		return true
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseNotCodeExpr(not *notCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := not.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, !ok
+}
+
+func (p *parser) parseNotExpr(not *notExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExpr(not.expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseOneOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		if !expr.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(expr.expr)
+		if !expr.skipVals {
+			p.popV()
+		}
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
+	}
+
+	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
+	val, ok := p.parseExpr(recover.expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRefExpr " + ref.name))
+	}
+
+	if ref.name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.pos))
+	}
+
+	rule := p.rules[ref.name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
+		return nil, false
+	}
+	return p.parseRule(rule)
+}
+
+func (p *parser) parseSeqExpr(seq *seqExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseSeqExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	var vals []interface{}
+	if seq.valsIx > 0 {
+		vals = p.reusedVals(seq)
+	} else {
+		vals = make([]interface{}, len(seq.exprs))
+	}
+	for i, expr := range seq.exprs {
+		val, ok := p.parseExpr(expr)
+		if !ok {
+			if p.partialResult && i > 0 {
+				p.setPartial(vals[:i])
+			}
+			p.restoreState(state)
+			p.restore(pt)
+			return nil, false
+		}
+		vals[i] = val
+	}
+	return vals, true
+}
+
+// reusedVals returns the values buffer of the parser that is reused for
+// the values of the sequence seq, which is allocated on first use.
+func (p *parser) reusedVals(seq *seqExpr) []interface{} {
+	for len(p.seqVals) < seq.valsIx {
+		p.seqVals = append(p.seqVals, nil)
+	}
+	vals := p.seqVals[seq.valsIx-1]
+	if vals == nil {
+		vals = make([]interface{}, len(seq.exprs))
+		p.seqVals[seq.valsIx-1] = vals
+	}
+	return vals
+}
+
+func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseStateCodeExpr"))
+	}
+
+	err := state.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	return nil, true
+}
+
+func (p *parser) parseThrowExpr(expr *throwExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseThrowExpr"))
+	}
+
+	p.recoveryFailPos = append(p.recoveryFailPos, p.maxFailPos)
+	defer func() {
+		p.recoveryFailPos = p.recoveryFailPos[:len(p.recoveryFailPos)-1]
+	}()
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
+			if val, ok := p.parseExpr(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrMoreExpr"))
+	}
+
+	var vals []interface{}
+
+	for {
+		if !expr.skipVals {
+			p.pushV()
+		}
+		val, ok := p.parseExpr(expr.expr)
+		if !expr.skipVals {
+			p.popV()
+		}
+		if !ok {
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (interface{}, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrOneExpr"))
+	}
+
+	if !expr.skipVals {
+		p.pushV()
+	}
+	val, _ := p.parseExpr(expr.expr)
+	if !expr.skipVals {
+		p.popV()
+	}
+	// whether it matched or not, consider it a match
+	return val, true
+}
@@ -0,0 +1,67 @@
+package coverage
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mna/pigeon/ast"
+	"github.com/mna/pigeon/bootstrap"
+)
+
+func TestBootstrapGrammarCoverage(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("..", "..", "grammar", "bootstrap.peg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := bootstrap.NewParser().Parse("", strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bootstrap grammar itself, and a grammar that exercises the
+	// features that it doesn't use
+	inputs := []string{
+		string(b),
+		"A \"a\" = &{ return true, nil } !{ return false, nil } &B !. B?\n" +
+			"B = 'b'i [^a-z\\n\\]\\pL\\p{Greek}]i `raw` \"\\x41\\u0042\\U00000043\\101\" / ( . )+ ;\n",
+	}
+
+	hits := make(map[string]int)
+	for i, in := range inputs {
+		got, err := Parse("", []byte(in), InitState(ast.CoverageKey, hits))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the instrumentation preserves the values of the rules
+		if i == 0 && ast.Hash(got.(*ast.Grammar)) != ast.Hash(want) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	}
+
+	// DecimalDigit is not referenced by the bootstrap grammar, exclude the
+	// unreferenced rules other than the start rule
+	refs := map[string]bool{want.Rules[0].Name.Val: true}
+	ast.Inspect(want, func(expr ast.Expression) bool {
+		if ref, ok := expr.(*ast.RuleRefExpr); ok {
+			refs[ref.Name.Val] = true
+		}
+		return true
+	})
+	rules := want.Rules[:0:0]
+	for _, r := range want.Rules {
+		if refs[r.Name.Val] {
+			rules = append(rules, r)
+		}
+	}
+	want.Rules = rules
+
+	cov := ast.CoverageReport(map[string]interface{}{ast.CoverageKey: hits})
+	if r := cov.Ratio(want); r != 1 {
+		t.Errorf("want 100%% rule coverage, got %.1f%%, uncovered: %v", r*100, cov.Uncovered(want))
+	}
+	if n := cov.Hits["Grammar"]; n != len(inputs) {
+		t.Errorf("want %d hits for the start rule, got %d", len(inputs), n)
+	}
+}
@@ -0,0 +1,19 @@
+// Package coverage tests the rule coverage instrumentation with a parser
+// generated from the bootstrap grammar of pigeon.
+package coverage
+
+import "github.com/mna/pigeon/ast"
+
+// The helpers used by the code blocks of the bootstrap grammar, as defined
+// by the bootstrap-pigeon command.
+
+func (c *current) astPos() ast.Pos {
+	return ast.Pos{Line: c.pos.line, Col: c.pos.col, Off: c.pos.offset}
+}
+
+func toIfaceSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.([]interface{})
+}
@@ -0,0 +1,111 @@
+package cst
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// leaves returns the concatenation of the text of the leaves of n.
+func leaves(n *CSTNode) string {
+	if len(n.Children) == 0 {
+		return n.Text()
+	}
+	var buf strings.Builder
+	for _, child := range n.Children {
+		buf.WriteString(leaves(child))
+	}
+	return buf.String()
+}
+
+// rules returns the rules of the nodes of n, in depth-first order, with
+// the nodes of rules _ and EOF omitted.
+func rules(n *CSTNode) []string {
+	var names []string
+	if n.Rule != "" && n.Rule != "_" && n.Rule != "EOF" {
+		names = append(names, n.Rule)
+	}
+	for _, child := range n.Children {
+		names = append(names, rules(child)...)
+	}
+	return names
+}
+
+func TestCST(t *testing.T) {
+	cases := []struct {
+		in    string
+		rules string
+	}{
+		{"", "File"},
+		{"  \n", "File"},
+		{"a=1;", "File Stmt Ident Expr Term Number"},
+		{"// comment\na = b + (1 - c) ; // end", "File Comment Stmt Ident Expr Term Ident Term Expr Term Number Term Ident Comment"},
+		{"x=1;\ny = x;\n", "File Stmt Ident Expr Term Number Stmt Ident Expr Term Ident"},
+	}
+	for _, c := range cases {
+		got, err := Parse("", []byte(c.in))
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		node, ok := got.(*CSTNode)
+		if !ok {
+			t.Errorf("%q: want *CSTNode, got %T", c.in, got)
+			continue
+		}
+		if s := leaves(node); s != c.in {
+			t.Errorf("%q: want reconstructed input, got %q", c.in, s)
+		}
+		if s := node.Text(); s != c.in {
+			t.Errorf("%q: want text of the root, got %q", c.in, s)
+		}
+		if s := strings.Join(rules(node), " "); s != c.rules {
+			t.Errorf("%q: want rules %q, got %q", c.in, c.rules, s)
+		}
+	}
+}
+
+func TestCSTSpans(t *testing.T) {
+	in := "a = 12;\nb=a;"
+	got, err := Parse("", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spans []string
+	var walk func(*CSTNode)
+	walk = func(n *CSTNode) {
+		if n.Rule == "Ident" || n.Rule == "Number" {
+			spans = append(spans, fmt.Sprintf("%s %q %s", n.Rule, n.Text(), n.Span))
+		}
+		if len(n.Children) > 0 {
+			// the children cover the span of their parent
+			first, last := n.Children[0], n.Children[len(n.Children)-1]
+			if first.Span.Start != n.Span.Start || last.Span.End != n.Span.End {
+				t.Errorf("%s: want children from %s to %s, got %s to %s", n.Rule,
+					n.Span.Start, n.Span.End, first.Span.Start, last.Span.End)
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(got.(*CSTNode))
+
+	want := []string{
+		`Ident "a" 1:1 [0]-1:2 [1]`,
+		`Number "12" 1:5 [4]-1:7 [6]`,
+		`Ident "b" 2:1 [8]-2:2 [9]`,
+		`Ident "a" 2:3 [10]-2:4 [11]`,
+	}
+	if strings.Join(spans, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want spans\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(spans, "\n"))
+	}
+}
+
+func TestCSTError(t *testing.T) {
+	got, err := Parse("", []byte("a = ;"))
+	if err == nil {
+		t.Fatalf("want error, got %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package diagnostics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	cases := []struct {
+		in    string
+		ok    bool
+		diags []string
+	}{
+		{"", true, nil},
+		{"let a;", true, nil},
+		{"var a;", true, []string{"1:1 [0]: warning: var is deprecated, use let"}},
+		{"let a;\nvar b;\nprint b;", true, []string{
+			"2:1 [7]: warning: var is deprecated, use let",
+			"3:1 [14]: info: print b",
+		}},
+		// the diagnostics are kept when the parse fails
+		{"var a;\nlet 1;", false, []string{"1:1 [0]: warning: var is deprecated, use let"}},
+	}
+	for _, c := range cases {
+		var diags []Diagnostic
+		_, err := Parse("", []byte(c.in), Diagnostics(&diags))
+		if (err == nil) != c.ok {
+			t.Errorf("%q: want ok %t, got error %v", c.in, c.ok, err)
+		}
+		var got []string
+		for _, d := range diags {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, c.diags) {
+			t.Errorf("%q: want diagnostics %q, got %q", c.in, c.diags, got)
+		}
+	}
+}
+
+func TestDiagnosticsNotSet(t *testing.T) {
+	if _, err := Parse("", []byte("var a;")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+package directives
+
+import "testing"
+
+func TestDirectives(t *testing.T) {
+	cases := map[string]int{
+		"":    0,
+		"abc": 3,
+		"é":   1,
+		"a😀b": 4,
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in))
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: want %d UTF-16 code units, got %v", in, want, got)
+		}
+	}
+}
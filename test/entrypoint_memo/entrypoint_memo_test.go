@@ -0,0 +1,42 @@
+package entrypointmemo
+
+import "testing"
+
+func TestMemoizeEntrypoints(t *testing.T) {
+	in := []byte("foo bar")
+	for _, memo := range []bool{false, true} {
+		for _, entry := range []string{"A", "B", "A"} {
+			got, err := Parse("", in, Memoize(memo), Entrypoint(entry))
+			if err != nil {
+				t.Errorf("memoize %t, entrypoint %s: want no error, got %v", memo, entry, err)
+				continue
+			}
+			if want := entry + ":foo " + entry + ":bar"; got != want {
+				t.Errorf("memoize %t, entrypoint %s: want %q, got %q", memo, entry, want, got)
+			}
+		}
+	}
+}
+
+func TestMemoizeEntrypointsSameParser(t *testing.T) {
+	p := newParser("", []byte("foo"), Memoize(true), Entrypoint("A"))
+	if _, err := p.parse(g); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.memo) == 0 {
+		t.Fatal("want memoized results")
+	}
+
+	// parsing again with another entrypoint starts with an empty
+	// memoization table.
+	Entrypoint("B")(p)
+	p.pt = savepoint{position: position{line: 1}}
+	p.cur.state = make(storeDict)
+	got, err := p.parse(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "B:foo"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
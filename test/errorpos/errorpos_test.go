@@ -52,3 +52,15 @@ func TestErrorPos(t *testing.T) {
 		}
 	}
 }
+
+func TestFurthestPos(t *testing.T) {
+	_, err := Parse("", []byte("case01 zero ink"))
+	list, ok := err.(errList)
+	if !ok {
+		t.Fatalf("want errList, got %v", err)
+	}
+	want := position{line: 1, col: 13, offset: 12}
+	if got := list.FurthestPos(); got.line != want.line || got.col != want.col || got.offset != want.offset {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,71 @@
+package iface
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stubParser is a Parser that returns the same result for any input.
+type stubParser struct {
+	val interface{}
+	err error
+}
+
+func (s stubParser) Parse(filename string, b []byte, opts ...Option) (interface{}, error) {
+	return s.val, s.err
+}
+
+func (s stubParser) ParseFile(filename string, opts ...Option) (interface{}, error) {
+	return s.val, s.err
+}
+
+func (s stubParser) ParseReader(filename string, r io.Reader, opts ...Option) (interface{}, error) {
+	return s.val, s.err
+}
+
+func (s stubParser) ParseRuneReader(filename string, r io.RuneReader, opts ...Option) (interface{}, error) {
+	return s.val, s.err
+}
+
+// total is the code under test, which depends on a Parser.
+func total(p Parser, in string) (int, error) {
+	v, err := p.ParseReader("", strings.NewReader(in))
+	if err != nil {
+		return 0, err
+	}
+	return v.(int), nil
+}
+
+func TestDefaultParser(t *testing.T) {
+	var p Parser = DefaultParser{}
+	if got, err := total(p, "1+2+39"); err != nil || got != 42 {
+		t.Errorf("want 42, got %d, %v", got, err)
+	}
+	if _, err := total(p, "1+"); err == nil {
+		t.Errorf("want error, got nil")
+	}
+
+	got, err := p.Parse("", []byte("3+4"))
+	if err != nil || got != 7 {
+		t.Errorf("Parse: want 7, got %v, %v", got, err)
+	}
+	got, err = p.ParseRuneReader("", strings.NewReader("5"))
+	if err != nil || got != 5 {
+		t.Errorf("ParseRuneReader: want 5, got %v, %v", got, err)
+	}
+	if _, err := p.ParseFile("does-not-exist"); err == nil {
+		t.Errorf("ParseFile: want error, got nil")
+	}
+}
+
+func TestStubParser(t *testing.T) {
+	if got, err := total(stubParser{val: 12}, "anything"); err != nil || got != 12 {
+		t.Errorf("want 12, got %d, %v", got, err)
+	}
+	fail := errors.New("fail")
+	if _, err := total(stubParser{err: fail}, "1+2"); err != fail {
+		t.Errorf("want %v, got %v", fail, err)
+	}
+}
@@ -0,0 +1,55 @@
+package keyword
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyword(t *testing.T) {
+	builtins := map[string]bool{"len": true, "cap": true, "append": false}
+	cases := map[string][]string{
+		"":                  nil,
+		"if":                {"kw:if"},
+		"iffy else_ for2":   {"id:iffy", "id:else_", "id:for2"},
+		"for x return":      {"kw:for", "id:x", "kw:return"},
+		"len cap append":    {"builtin:len", "builtin:cap", "id:append"},
+		"lenient\tif\nélse": {"id:lenient", "kw:if", "id:élse"},
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in), Keywords(builtins))
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		var out []string
+		if got != nil {
+			out = got.([]string)
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("%q: want %q, got %q", in, want, out)
+		}
+	}
+}
+
+func TestKeywordNoOption(t *testing.T) {
+	got, err := Parse("", []byte("len if"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"id:len", "kw:if"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestKeywordExpected(t *testing.T) {
+	_, err := Parse("", []byte("if ?"))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	// the keywords are reported once, not one by one
+	if msg := err.Error(); strings.Count(msg, "keyword") != 1 || strings.Contains(msg, `"else"`) {
+		t.Errorf("want a single keyword expected, got %v", err)
+	}
+}
@@ -100,3 +100,26 @@ func TestLabeledFailures(t *testing.T) {
 		}
 	}
 }
+
+func TestFurthestPos(t *testing.T) {
+	cases := map[string][]int{
+		"one two three":  {4, 8},
+		"1,\n two, \n3,": {0, 10, 12},
+	}
+	for in, want := range cases {
+		_, err := Parse("", []byte(in))
+		list, ok := err.(errList)
+		if !ok || len(list) != len(want) {
+			t.Errorf("%q: want %d error(s), got %v", in, len(want), err)
+			continue
+		}
+		for i, err := range list {
+			if got := err.(*parserError).FurthestPos().offset; got != want[i] {
+				t.Errorf("%q: want %dth error furthest offset %d, got %d", in, i+1, want[i], got)
+			}
+		}
+		if got := list.FurthestPos().offset; got != want[len(want)-1] {
+			t.Errorf("%q: want furthest offset %d, got %d", in, want[len(want)-1], got)
+		}
+	}
+}
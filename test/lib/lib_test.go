@@ -0,0 +1,19 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLib(t *testing.T) {
+	got, err := Parse("", []byte("a,bc,d"), Memoize(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "bc", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if _, err := Parse("", []byte("a,")); err == nil {
+		t.Error("want error, got nil")
+	}
+}
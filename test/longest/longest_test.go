@@ -0,0 +1,48 @@
+package longest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLongest(t *testing.T) {
+	cases := map[string][]string{
+		"":                nil,
+		"<":               {"<"},
+		"<= <":            {"<=", "<"},
+		"<<=<<<":          {"<<=", "<<", "<"},
+		"if iffy else x":  {"kw:if", "id:iffy", "kw:else", "id:x"},
+		"a<=b<<c":         {"id:a", "<=", "id:b", "<<", "id:c"},
+		"elsewhere <<= y": {"id:elsewhere", "<<=", "id:y"},
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in))
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		var toks []string
+		if got != nil {
+			toks = got.([]string)
+		}
+		if !reflect.DeepEqual(toks, want) {
+			t.Errorf("%q: want %q, got %q", in, want, toks)
+		}
+	}
+}
+
+func TestFirstMatch(t *testing.T) {
+	got, err := Parse("", []byte("ab"), Entrypoint("Tie"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a" {
+		t.Errorf("want %q, got %q", "a", got)
+	}
+}
+
+func TestNoMatch(t *testing.T) {
+	if _, err := Parse("", []byte("<>")); err == nil {
+		t.Error("want error")
+	}
+}
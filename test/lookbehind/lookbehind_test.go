@@ -0,0 +1,28 @@
+package lookbehind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookbehind(t *testing.T) {
+	cases := map[string][]string{
+		"":              {""},
+		"a,b":           {"a", "b"},
+		`a\,b,c`:        {`a\,b`, "c"},
+		`,a\,`:          {"", `a\,`},
+		"café,garçon,ç": {"accented:café", "garçon", "accented:ç"},
+		`déjà\,voilà`:   {`déjà\,voilà`},
+		"là,":           {"accented:là", ""},
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in))
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %q, got %q", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package mark
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMark(t *testing.T) {
+	for _, memo := range []bool{false, true} {
+		got, err := Parse("", []byte("ab cd"), Memoize(memo))
+		if err != nil {
+			t.Fatalf("memoize %t: %v", memo, err)
+		}
+		want := []interface{}{
+			[]string{"peek:ab", "ab"},
+			[]string{"peek:cd", "cd"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("memoize %t: want %v, got %v", memo, want, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package maxdepth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func nested(n int) []byte {
+	return []byte(strings.Repeat("(", n) + "x" + strings.Repeat(")", n))
+}
+
+func TestMaxDepth(t *testing.T) {
+	// the innermost Expr is at depth n+1
+	if _, err := Parse("", nested(9), MaxDepth(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Parse("", nested(10), MaxDepth(10))
+	var le *LimitError
+	if !errors.As(err, &le) {
+		t.Fatalf("want a *LimitError in %v", err)
+	}
+	if le.Kind != LimitDepth {
+		t.Errorf("want kind %s, got %s", LimitDepth, le.Kind)
+	}
+	if !strings.Contains(err.Error(), "max depth of nested rules exceeded") {
+		t.Errorf("want max depth error, got %v", err)
+	}
+}
+
+func TestMaxDepthDisabled(t *testing.T) {
+	if _, err := Parse("", nested(100), MaxDepth(0)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Parse("", []byte("(x"), MaxDepth(0))
+	var le *LimitError
+	if err == nil || errors.As(err, &le) {
+		t.Errorf("want a syntax error, got %v", err)
+	}
+}
+
+func TestLimitKindString(t *testing.T) {
+	cases := map[LimitKind]string{
+		LimitExpressions: "expressions",
+		LimitDepth:       "depth",
+		LimitSteps:       "steps",
+		LimitKind(0):     "LimitKind(0)",
+	}
+	for k, want := range cases {
+		if got := k.String(); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package maxexprcnt
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestMaxExprCnt(t *testing.T) {
 	_, err := Parse("", []byte("infinite parse"), MaxExpressions(5))
@@ -30,3 +33,14 @@ func TestMaxExprCnt(t *testing.T) {
 		t.Fatalf("expected to find errMaxExprCnt %v in error list %v", errMaxExprCnt, errs)
 	}
 }
+
+func TestMaxExprCntLimitError(t *testing.T) {
+	_, err := Parse("", []byte("infinite parse"), MaxExpressions(5))
+	var le *LimitError
+	if !errors.As(err, &le) {
+		t.Fatalf("want a *LimitError in %v", err)
+	}
+	if le.Kind != LimitExpressions {
+		t.Errorf("want kind %s, got %s", LimitExpressions, le.Kind)
+	}
+}
@@ -0,0 +1,80 @@
+package maxsteps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// catastrophic is an input that forces an exponential backtracking.
+var catastrophic = []byte(strings.Repeat("a", 30) + "b")
+
+func TestMaxSteps(t *testing.T) {
+	var stats Stats
+	_, err := Parse("", catastrophic, MaxSteps(10000), Statistics(&stats, "no match"))
+	if err == nil {
+		t.Fatal("want error")
+	}
+
+	errs, ok := err.(errList)
+	if !ok {
+		t.Fatalf("want error of type errList, got %T", err)
+	}
+	var found bool
+	for _, err := range errs {
+		if pe, ok := err.(*parserError); ok && pe.Inner == errMaxSteps {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("want errMaxSteps in error list %v", errs)
+	}
+	if stats.Steps != 10001 {
+		t.Errorf("want 10001 steps, got %d", stats.Steps)
+	}
+}
+
+func TestMaxStepsNotExceeded(t *testing.T) {
+	var stats Stats
+	got, err := Parse("", []byte("aaxz"), MaxSteps(1000), Statistics(&stats, "no match"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Error("want a result")
+	}
+	if stats.Steps == 0 || stats.Steps > 1000 {
+		t.Errorf("want steps in (0, 1000], got %d", stats.Steps)
+	}
+
+	// the memoization makes the backtracking linear
+	_, err = Parse("", catastrophic, MaxSteps(1000), Memoize(true))
+	if err == nil || strings.Contains(err.Error(), errMaxSteps.Error()) {
+		t.Errorf("want no match error, got %v", err)
+	}
+}
+
+func TestMaxStepsDisabled(t *testing.T) {
+	in := []byte(strings.Repeat("a", 10) + "b")
+	_, err := Parse("", in, MaxSteps(0))
+	if err == nil || strings.Contains(err.Error(), errMaxSteps.Error()) {
+		t.Errorf("want no match error, got %v", err)
+	}
+}
+
+func TestMaxStepsLimitError(t *testing.T) {
+	_, err := Parse("", catastrophic, MaxSteps(10000))
+	var le *LimitError
+	if !errors.As(err, &le) {
+		t.Fatalf("want a *LimitError in %v", err)
+	}
+	if le.Kind != LimitSteps {
+		t.Errorf("want kind %s, got %s", LimitSteps, le.Kind)
+	}
+
+	// a syntax error is not a limit error
+	_, err = Parse("", []byte("ab"), MaxSteps(10000))
+	if err == nil || errors.As(err, &le) {
+		t.Errorf("want a syntax error, got %v", err)
+	}
+}
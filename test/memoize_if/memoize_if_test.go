@@ -0,0 +1,36 @@
+package memoizeif
+
+import "testing"
+
+func TestMemoizeIf(t *testing.T) {
+	cases := []struct {
+		name string
+		opt  Option
+		want int
+	}{
+		{name: "none", opt: Memoize(false), want: 2},
+		{name: "all", opt: Memoize(true), want: 1},
+		{name: "rules", opt: MemoizeRules(true), want: 1},
+		{name: "no rules", opt: MemoizeRules(false), want: 2},
+	}
+
+	for _, tc := range cases {
+		calls := make(map[string]int)
+		if _, err := Parse("", []byte("ay"), tc.opt, GlobalStore("calls", calls)); err != nil {
+			t.Errorf("%s: want no error, got %v", tc.name, err)
+			continue
+		}
+		if calls["Twice"] != tc.want {
+			t.Errorf("%s: want %d calls, got %d", tc.name, tc.want, calls["Twice"])
+		}
+	}
+}
+
+func TestMemoizeIfGenerated(t *testing.T) {
+	for _, r := range g.rules {
+		want := r.name == "Twice"
+		if r.memoize != want {
+			t.Errorf("%s: want memoize %t, got %t", r.name, want, r.memoize)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package offside
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndentation(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "a", want: "a"},
+		{in: "a\nb\n", want: "a b"},
+		{in: "a:\n  b\n", want: "a[b]"},
+		{in: "a\nb:\n  c\n  d:\n    e\nf\n", want: "a b[c d[e]] f"},
+		{in: "a:\n\tb:\n\t\tc\n\td\n", want: "a[b[c] d]"},
+		{in: "a:\n    b:\n        c\n", want: "a[b[c]]"},
+		{in: "a\n  b\n"},
+		{in: "a:\nb\n"},
+		{in: "a:\n    b\n  c\n"},
+	}
+	for _, tc := range cases {
+		for _, fromReader := range []bool{false, true} {
+			var got interface{}
+			var err error
+			if fromReader {
+				got, err = ParseRuneReader("", strings.NewReader(tc.in))
+			} else {
+				got, err = Parse("", []byte(tc.in))
+			}
+			// an empty want is an indentation error
+			if tc.want == "" {
+				if err == nil {
+					t.Errorf("%q: want error, got %v", tc.in, got)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%q (reader %t): want no error, got %v", tc.in, fromReader, err)
+				continue
+			}
+			if got != tc.want {
+				t.Errorf("%q (reader %t): want %q, got %q", tc.in, fromReader, tc.want, got)
+			}
+		}
+	}
+}
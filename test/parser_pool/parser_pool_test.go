@@ -0,0 +1,82 @@
+package parserpool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserPool(t *testing.T) {
+	cases := []struct {
+		in      string
+		opts    []Option
+		want    int
+		wantErr string
+	}{
+		{in: "a,b,c,a", want: 3},
+		{in: "a,b", opts: []Option{Memoize(true)}, want: 2},
+		{in: "a,", wantErr: "1:3 (2): no match found"},
+		{in: "x,y,z,w", want: 4},
+		{in: "a", want: 1},
+	}
+
+	var pool ParserPool
+	for i := 0; i < 3; i++ {
+		for _, tc := range cases {
+			got, err := pool.Parse("", []byte(tc.in), tc.opts...)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("%q: want error %q, got %v", tc.in, tc.wantErr, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", tc.in, err)
+				continue
+			}
+			if got != tc.want {
+				t.Errorf("%q: want %d, got %v", tc.in, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestParserReset(t *testing.T) {
+	stats := &Stats{}
+	p := newParser("a", []byte("a,b,"), Memoize(true), Statistics(stats, "no match"))
+	if _, err := p.parse(g); err == nil {
+		t.Fatal("want error")
+	}
+
+	p.Reset("b", []byte("c,d"))
+	if len(p.cur.globalStore) != 0 || len(*p.errs) != 0 || p.memoize || p.Stats == stats {
+		t.Fatalf("want a reset parser, got %+v", p)
+	}
+	got, err := p.parse(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 {
+		t.Errorf("want 2, got %v", got)
+	}
+}
+
+var benchInput = []byte("alpha,beta,gamma,delta,epsilon")
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse("", benchInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserPool(b *testing.B) {
+	var pool ParserPool
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := pool.Parse("", benchInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package partialresult
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartialResult(t *testing.T) {
+	cases := []struct {
+		in   string
+		want interface{}
+		err  bool
+	}{
+		{in: "", want: []interface{}(nil)},
+		{in: "a;bc;", want: []interface{}{"a", "bc"}},
+		{in: "a;bc;d", want: []interface{}{[]interface{}{"a", "bc"}}, err: true},
+		{in: "a;bc;;", want: []interface{}{[]interface{}{"a", "bc"}}, err: true},
+		{in: "1", want: nil, err: true},
+	}
+	for _, tc := range cases {
+		got, err := Parse("", []byte(tc.in), PartialResult(true))
+		if (err != nil) != tc.err {
+			t.Errorf("%q: want error %t, got %v", tc.in, tc.err, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: want %#v, got %#v", tc.in, tc.want, got)
+		}
+
+		// without the option, a failure returns no value
+		got, err = Parse("", []byte(tc.in))
+		if err != nil && got != nil {
+			t.Errorf("%q: want nil value without PartialResult, got %#v", tc.in, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package progress
+
+import (
+	"strings"
+	"testing"
+)
+
+type progressCall struct {
+	offset, total int
+}
+
+func TestOnProgress(t *testing.T) {
+	in := strings.Repeat("12+34;\n1+2+3+4\nabc\n", 100)
+
+	var calls []progressCall
+	fn := func(offset, total int) {
+		calls = append(calls, progressCall{offset, total})
+	}
+	if _, err := Parse("", []byte(in), OnProgress(fn), ProgressInterval(100)); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) < len(in)/100 || len(calls) > len(in)/100+2 {
+		t.Errorf("want about %d calls, got %d", len(in)/100, len(calls))
+	}
+	for i, c := range calls {
+		if c.total != len(in) {
+			t.Errorf("%d: want total %d, got %d", i, len(in), c.total)
+		}
+		if i > 0 && c.offset < calls[i-1].offset {
+			t.Errorf("%d: want increasing offsets, got %d after %d", i, c.offset, calls[i-1].offset)
+		}
+		if i > 0 && i < len(calls)-1 && c.offset-calls[i-1].offset < 100 {
+			t.Errorf("%d: want offsets at least 100 bytes apart, got %d after %d", i, c.offset, calls[i-1].offset)
+		}
+	}
+	if last := calls[len(calls)-1]; last.offset != len(in) {
+		t.Errorf("want last call at offset %d, got %d", len(in), last.offset)
+	}
+}
+
+func TestOnProgressFailure(t *testing.T) {
+	in := "1+2;\nabc\n1+2+\n!\nabc\n"
+
+	var calls []progressCall
+	fn := func(offset, total int) {
+		calls = append(calls, progressCall{offset, total})
+	}
+	if _, err := Parse("", []byte(in), OnProgress(fn), ProgressInterval(0)); err == nil {
+		t.Fatal("want error")
+	}
+	// the furthest offset is reported, i.e. after the "!" read by the !.
+	// predicate, not the position of the failure
+	want := strings.Index(in, "!") + 1
+	if last := calls[len(calls)-1]; last.offset != want {
+		t.Errorf("want last call at offset %d, got %d", want, last.offset)
+	}
+	// every advance of the furthest offset is reported, plus the completion
+	if len(calls) != want+1 {
+		t.Errorf("want %d calls, got %d", want+1, len(calls))
+	}
+}
+
+func TestOnProgressRuneReader(t *testing.T) {
+	in := strings.Repeat("abc\n", 10)
+
+	var calls []progressCall
+	fn := func(offset, total int) {
+		calls = append(calls, progressCall{offset, total})
+	}
+	if _, err := ParseRuneReader("", strings.NewReader(in), OnProgress(fn), ProgressInterval(8)); err != nil {
+		t.Fatal(err)
+	}
+	if first := calls[0]; first.total != -1 {
+		t.Errorf("want unknown total, got %d", first.total)
+	}
+	if last := calls[len(calls)-1]; last.offset != len(in) || last.total != len(in) {
+		t.Errorf("want last call at offset %d of %d, got %d of %d", len(in), len(in), last.offset, last.total)
+	}
+}
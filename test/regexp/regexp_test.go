@@ -0,0 +1,66 @@
+package regexp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegexp(t *testing.T) {
+	cases := map[string][]string{
+		"":               nil,
+		"x":              {"id:x"},
+		"12 3.5e-2":      {"num:12", "num:3.5e-2"},
+		"été_1 2x":       {"id:été_1", "num:2", "id:x"},
+		"  a1\tb2  ":     {"id:a1", "id:b2"},
+		"1.x":            {"num:1"},
+		"héhé 1e9 Σ_π  ": {"id:héhé", "num:1e9", "id:Σ_π"},
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in))
+		if in == "1.x" {
+			if err == nil {
+				t.Errorf("%q: want error", in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		var toks []string
+		if got != nil {
+			toks = got.([]string)
+		}
+		if !reflect.DeepEqual(toks, want) {
+			t.Errorf("%q: want %q, got %q", in, want, toks)
+		}
+	}
+}
+
+func TestRegexpRuneReader(t *testing.T) {
+	got, err := ParseRuneReader("", strings.NewReader("ab 12 c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"id:ab", "num:12", "id:c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRegexpPosition(t *testing.T) {
+	_, err := Parse("", []byte("ab\ncd ?"))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if !strings.HasPrefix(err.Error(), "2:4 (6):") {
+		t.Errorf("want error at 2:4 (6), got %v", err)
+	}
+}
+
+func TestRegexpNoBacktracking(t *testing.T) {
+	if _, err := Parse("", []byte("aaa"), Entrypoint("Greedy")); err == nil {
+		t.Error("want error")
+	}
+}
@@ -0,0 +1,19 @@
+package rulebench
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSamples(t *testing.T) {
+	for _, rule := range []string{"Expr", "Term", "number"} {
+		input, err := ioutil.ReadFile(filepath.Join("testdata", rule+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Parse("", input, Entrypoint(rule)); err != nil {
+			t.Errorf("%s: %v", rule, err)
+		}
+	}
+}
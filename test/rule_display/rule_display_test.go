@@ -0,0 +1,27 @@
+package ruledisplay
+
+import "testing"
+
+func TestRuleDisplayName(t *testing.T) {
+	cases := map[string]string{
+		"":    `1:1 (0): no match found, expected: expression`,
+		"1,":  `1:3 (2): no match found, expected: expression`,
+		"1+":  `1:3 (2): no match found, expected: number`,
+		"1+x": `1:3 (2): no match found, expected: number`,
+		"1x":  `1:2 (1): no match found, expected: "+", ",", [0-9] or EOF`,
+	}
+	for in, want := range cases {
+		_, err := Parse("", []byte(in))
+		if err == nil {
+			t.Errorf("%q: want error, got nil", in)
+			continue
+		}
+		if err.Error() != want {
+			t.Errorf("%q: want error %q, got %q", in, want, err)
+		}
+	}
+
+	if _, err := Parse("", []byte("1+2,3")); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
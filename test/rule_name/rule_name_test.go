@@ -0,0 +1,17 @@
+package rulename
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleName(t *testing.T) {
+	got, err := Parse("", []byte("abc 12 d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Word:abc ", "Number:12 ", "Word:d", "Input"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
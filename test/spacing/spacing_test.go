@@ -0,0 +1,29 @@
+package spacing
+
+import "testing"
+
+func TestSpacing(t *testing.T) {
+	cases := map[string]int{
+		"1":              1,
+		" 1 ":            1,
+		"1+2":            3,
+		"\t10 +\n2 - 3 ": 9,
+	}
+	for in, want := range cases {
+		got, err := Parse("", []byte(in))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: want %d, got %v", in, want, got)
+		}
+	}
+
+	// whitespace is significant in the lexical rules
+	for _, in := range []string{"1 2", "1 + 2 3", "+"} {
+		if _, err := Parse("", []byte(in)); err == nil {
+			t.Errorf("%q: want error, got nil", in)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package span
+
+import (
+	"testing"
+)
+
+func TestSpan(t *testing.T) {
+	got, err := Parse("", []byte("héllo wörld"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Span{
+		{Start: position{line: 1, col: 1, offset: 0}, End: position{line: 1, col: 6, offset: 6}},
+		{Start: position{line: 1, col: 7, offset: 7}, End: position{line: 1, col: 12, offset: 13}},
+	}
+	items := got.([]interface{})
+	if len(items) != len(want) {
+		t.Fatalf("want %d items, got %d", len(want), len(items))
+	}
+	for i, item := range items {
+		if item.(Span) != want[i] {
+			t.Errorf("%d: want %v, got %v", i, want[i], item)
+		}
+	}
+}
+
+func TestErrorSpan(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Span
+	}{
+		{
+			// action error spans the whole match
+			in:   "ab !ça",
+			want: Span{Start: position{line: 1, col: 4, offset: 3}, End: position{line: 1, col: 7, offset: 7}},
+		},
+		{
+			// failed match spans the unexpected multibyte rune
+			in:   "ab €",
+			want: Span{Start: position{line: 1, col: 4, offset: 3}, End: position{line: 1, col: 5, offset: 6}},
+		},
+		{
+			// failed match at the end of the input is empty
+			in:   "ab !",
+			want: Span{Start: position{line: 1, col: 5, offset: 4}, End: position{line: 1, col: 5, offset: 4}},
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := Parse("", []byte(tc.in))
+		if err == nil {
+			t.Errorf("%q: want error, got nil", tc.in)
+			continue
+		}
+		list := err.(errList)
+		if len(list) != 1 {
+			t.Errorf("%q: want 1 error, got %v", tc.in, err)
+			continue
+		}
+		if got := list[0].(*parserError).Span(); got != tc.want {
+			t.Errorf("%q: want span %v, got %v", tc.in, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package textunsafe
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/mna/pigeon/examples/json"
+	"github.com/mna/pigeon/test/text_unsafe/jsonunsafe"
+)
+
+func TestTextUnsafe(t *testing.T) {
+	in := []byte("ab cd ef")
+	got, err := Parse("", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(in) != "ab cd ef" {
+		t.Fatalf("want input unchanged, got %q", in)
+	}
+
+	want := []string{"ab ", "cd ", "ef"}
+	words := got.([]interface{})
+	if len(words) != len(want) {
+		t.Fatalf("want %d words, got %d", len(want), len(words))
+	}
+	offset := 0
+	for i, w := range words {
+		texts := w.([][]byte)
+		if got := string(texts[0]); got != want[i]+"!" {
+			t.Errorf("%d: want text %q, got %q", i, want[i]+"!", got)
+		}
+		if got := string(texts[1]); got != want[i] {
+			t.Errorf("%d: want unsafe text %q, got %q", i, want[i], got)
+		}
+		// the unsafe text is a subslice of the input
+		if &texts[1][0] != &in[offset] {
+			t.Errorf("%d: want a subslice of the input at offset %d", i, offset)
+		}
+		offset += len(want[i])
+	}
+}
+
+const jsonFile = "../../examples/json/testdata/github-octokit-repos.json"
+
+func TestJSONTextUnsafe(t *testing.T) {
+	d, err := ioutil.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Parse("", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jsonunsafe.Parse("", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want the same result with c.text and c.TextUnsafe()")
+	}
+}
+
+func BenchmarkJSONText(b *testing.B) {
+	d, err := ioutil.ReadFile(jsonFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Parse("", d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONTextUnsafe(b *testing.B) {
+	d, err := ioutil.ReadFile(jsonFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := jsonunsafe.Parse("", d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
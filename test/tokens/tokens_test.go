@@ -0,0 +1,74 @@
+package tokens
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	next, err := Tokens("", []byte(" x + 12*(y - 3)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interface{}
+	for {
+		v, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	want := []interface{}{"x", "+", 12, "*", "(", "y", "-", 3, ")"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if _, err := next(); err != io.EOF {
+		t.Errorf("want io.EOF after the end, got %v", err)
+	}
+}
+
+func TestTokensEmpty(t *testing.T) {
+	next, err := Tokens("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := next(); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
+
+func TestTokensError(t *testing.T) {
+	next, err := Tokens("", []byte("a b ! c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a", "b"} {
+		v, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Errorf("want %q, got %v", want, v)
+		}
+	}
+
+	_, err = next()
+	if err == nil || !strings.Contains(err.Error(), "1:5 (4): no match found") {
+		t.Fatalf("want no match error at 1:5, got %v", err)
+	}
+	if _, err2 := next(); err2 == nil || err2.Error() != err.Error() {
+		t.Errorf("want the same error on the next call, got %v", err2)
+	}
+
+	// the whole input is parsed to the same error
+	_, perr := Parse("", []byte("a b ! c"))
+	if perr == nil || perr.Error() != err.Error() {
+		t.Errorf("want Parse error %v, got %v", err, perr)
+	}
+}
@@ -0,0 +1,42 @@
+package transactionalstore
+
+import "testing"
+
+func TestTransactionalStore(t *testing.T) {
+	cases := []struct {
+		in        string
+		opts      []Option
+		wantSyms  []string
+		wantCount int
+	}{
+		{in: "let a;let b?", wantSyms: []string{"a", "b"}, wantCount: 2},
+		{in: "let a;let b?", opts: []Option{TransactionalStore("count")}, wantSyms: []string{"a", "b"}, wantCount: 1},
+		{in: "let a;let b?", opts: []Option{TransactionalStore("symbols")}, wantSyms: []string{"a"}, wantCount: 2},
+		{in: "let a;let b?let c;", opts: []Option{TransactionalStore("count", "symbols")}, wantSyms: []string{"a", "c"}, wantCount: 2},
+	}
+
+	for _, tc := range cases {
+		opts := append([]Option{
+			GlobalStore("symbols", symbols{}),
+			GlobalStore("count", 0),
+		}, tc.opts...)
+		got, err := Parse("", []byte(tc.in), opts...)
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc.in, err)
+			continue
+		}
+		res := got.(result)
+		if res.count != tc.wantCount {
+			t.Errorf("%q: want count %d, got %d", tc.in, tc.wantCount, res.count)
+		}
+		if len(res.symbols) != len(tc.wantSyms) {
+			t.Errorf("%q: want symbols %v, got %v", tc.in, tc.wantSyms, res.symbols)
+			continue
+		}
+		for _, sym := range tc.wantSyms {
+			if !res.symbols[sym] {
+				t.Errorf("%q: want symbol %q, got %v", tc.in, sym, res.symbols)
+			}
+		}
+	}
+}
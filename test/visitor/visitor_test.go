@@ -0,0 +1,52 @@
+package visitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkResult(t *testing.T) {
+	got, err := Parse("", []byte("1+2*(3-4)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ops []string
+	var nums []num
+	WalkResult(got, func(v interface{}) bool {
+		switch v := v.(type) {
+		case *binOp:
+			ops = append(ops, v.op)
+		case num:
+			nums = append(nums, v)
+		}
+		return true
+	})
+	if want := []string{"+", "*", "-"}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("want ops %v, got %v", want, ops)
+	}
+	if want := []num{"1", "2", "3", "4"}; !reflect.DeepEqual(nums, want) {
+		t.Errorf("want nums %v, got %v", want, nums)
+	}
+
+	// returning false skips the children
+	var n int
+	WalkResult(got, func(v interface{}) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("want 1 visited value, got %d", n)
+	}
+
+	var vals []interface{}
+	WalkResult([]interface{}{num("1"), nil, []interface{}{num("2")}}, func(v interface{}) bool {
+		if _, ok := v.(num); ok {
+			vals = append(vals, v)
+		}
+		return true
+	})
+	if want := []interface{}{num("1"), num("2")}; !reflect.DeepEqual(vals, want) {
+		t.Errorf("want %v, got %v", want, vals)
+	}
+}